@@ -0,0 +1,380 @@
+// Package client runs tasks and reads the results and the nodes of a Jackadi manager, to drive it from a Go
+// program like jack does.
+//
+// The client connects to the local socket of the manager by default, or to its remote access with WithAddress
+// and WithMTLS:
+//
+//	c, err := client.New(client.WithAddress("manager:40082"), client.WithMTLS("jack.crt", "jack.key", "ca.crt"))
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//
+//	results, err := c.Run(ctx, "web-*", client.Glob, "cmd.run", []string{"uptime"}, client.RunOptions{})
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/helper"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
+	"github.com/jackadi-io/jackadi/internal/parser"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// TargetMode is the way the target of a run selects the nodes.
+type TargetMode int
+
+const (
+	Exact TargetMode = iota // A node ID.
+	List                    // Node IDs separated by commas.
+	Glob                    // A Glob pattern.
+	Regex                   // A regular expression.
+	Query                   // A filter expression, e.g. specs.os==linux and id=~web-*.
+)
+
+func (m TargetMode) toProto() proto.TargetMode {
+	switch m {
+	case Exact:
+		return proto.TargetMode_EXACT
+	case List:
+		return proto.TargetMode_LIST
+	case Glob:
+		return proto.TargetMode_GLOB
+	case Regex:
+		return proto.TargetMode_REGEX
+	case Query:
+		return proto.TargetMode_QUERY
+	default:
+		return proto.TargetMode_UNKNOWN
+	}
+}
+
+// LockMode is the lock a task takes on the nodes.
+type LockMode int
+
+const (
+	DefaultLock   LockMode = iota // The lock mode of the task.
+	NoLock                        // Concurrent execution.
+	WriteLock                     // One write task at a time.
+	ExclusiveLock                 // One task at a time.
+)
+
+func (m LockMode) toProto() proto.LockMode {
+	switch m {
+	case NoLock:
+		return proto.LockMode_NO_LOCK
+	case WriteLock:
+		return proto.LockMode_WRITE
+	case ExclusiveLock:
+		return proto.LockMode_EXCLUSIVE
+	default:
+		return proto.LockMode_UNSPECIFIED
+	}
+}
+
+// RunOptions are the options of a run.
+type RunOptions struct {
+	LockMode LockMode
+	Timeout  time.Duration     // Timeout of the task on the nodes, config.TaskTimeout if zero.
+	Metadata map[string]string // Labels of the run stored with its results, e.g. a CI build number.
+
+	// Rolling runs the task on one node at a time, in the order of their IDs, and skips the remaining nodes once
+	// the failures exceed MaxFailures.
+	Rolling     bool
+	MaxFailures int
+	FailFast    bool // Stop the run at the first failure, the nodes not done being skipped or cancelled.
+}
+
+// Result is the response of a node to a run.
+type Result struct {
+	ID            int64
+	GroupID       int64
+	Output        json.RawMessage // Output of the task, serialized in JSON.
+	Error         string          // Error returned by the task.
+	Retcode       int32
+	InternalError string // Failure of Jackadi to run the task, e.g. TIMEOUT or DISCONNECTED, empty if none.
+	Status        string // success, error or internal error.
+}
+
+// Results are the responses of the nodes to a run, by node ID.
+type Results map[string]Result
+
+// Node is a node known by the manager.
+type Node struct {
+	ID          string
+	Status      string // accepted, candidate or rejected.
+	Address     string
+	Certificate string
+	Connected   bool
+	Since       time.Time // Connection or disconnection time.
+	LastMsg     time.Time
+	Version     string
+}
+
+type options struct {
+	address  string
+	tls      *tls.Config
+	mtls     []string // Certificate, key and CA files.
+	dialOpts []grpc.DialOption
+}
+
+// Option configures the connection to the manager.
+type Option func(*options)
+
+// WithAddress connects to the remote access of the manager (host:port) instead of its local socket.
+func WithAddress(address string) Option {
+	return func(o *options) { o.address = address }
+}
+
+// WithTLS sets the TLS configuration of the connection.
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) { o.tls = cfg }
+}
+
+// WithMTLS loads the certificate and the key of the client, and the CA of the manager, like jack --remote.
+func WithMTLS(cert, key, ca string) Option {
+	return func(o *options) { o.mtls = []string{cert, key, ca} }
+}
+
+// WithDialOptions adds gRPC options to the connection.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) { o.dialOpts = append(o.dialOpts, opts...) }
+}
+
+// Client is a client of the manager, safe for concurrent use.
+type Client struct {
+	conn      *grpc.ClientConn
+	forwarder proto.ForwarderClient
+	api       proto.APIClient
+}
+
+// New connects to the manager.
+//
+// The connection is established on the first call, New only fails on an invalid configuration.
+func New(opts ...Option) (*Client, error) {
+	o := options{address: "unix:" + config.CLISocket}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.mtls != nil {
+		certs, ca, err := config.GetMTLSCertificate(o.mtls[0], o.mtls[1], o.mtls[2])
+		if err != nil {
+			return nil, fmt.Errorf("remote access: %w", err)
+		}
+		o.tls = &tls.Config{MinVersion: tls.VersionTLS12, Certificates: certs, RootCAs: ca}
+	}
+
+	creds := insecure.NewCredentials()
+	if o.tls != nil {
+		creds = credentials.NewTLS(o.tls)
+	}
+
+	conn, err := grpc.NewClient(o.address, append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, o.dialOpts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("did not connect: %w", err)
+	}
+
+	return &Client{
+		conn:      conn,
+		forwarder: proto.NewForwarderClient(conn),
+		api:       proto.NewAPIClient(conn),
+	}, nil
+}
+
+// Close closes the connection to the manager.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Run runs a task (plugin.task) on the nodes matching the target, and returns their responses.
+//
+// The arguments are the positional arguments and the options (key=value) of the task, like the arguments of jack
+// run. Without deadline, the context is given the time for the nodes to time out and answer, unless the run is
+// rolling.
+func (c *Client) Run(ctx context.Context, target string, mode TargetMode, task string, args []string, opts RunOptions) (Results, error) {
+	arguments, err := parser.ParseArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	return c.run(ctx, target, mode, task, arguments.Positional, arguments.Options, opts)
+}
+
+// RunInput runs a task like Run, with the values of the positional arguments and of the options instead of the
+// arguments of jack run, e.g. for the clients generated by jack gen client.
+//
+// The values are sent as their JSON encoding, the struct fields being named after their jackadi tag like the
+// results of the tasks. The options are a struct or a map, nil if the task has none.
+func (c *Client) RunInput(ctx context.Context, target string, mode TargetMode, task string, args []any, options any, opts RunOptions) (Results, error) {
+	var positional []any
+	if err := reencode(args, &positional); err != nil {
+		return nil, fmt.Errorf("failed to encode the arguments: %w", err)
+	}
+	var named map[string]any
+	if options != nil {
+		if err := reencode(options, &named); err != nil {
+			return nil, fmt.Errorf("failed to encode the options: %w", err)
+		}
+	}
+	return c.run(ctx, target, mode, task, positional, named, opts)
+}
+
+// reencode converts the value to its JSON representation, decoded in out.
+func reencode(value any, out any) error {
+	data, err := serializer.JSON.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return serializer.JSON.Unmarshal(data, out)
+}
+
+func (c *Client) run(ctx context.Context, target string, mode TargetMode, task string, args []any, options map[string]any, opts RunOptions) (Results, error) {
+	req, err := newTaskRequest(target, mode, task, args, options, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := ctx.Deadline(); !ok && !opts.Rolling {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.GetTimeout()+1)*time.Second+config.NodeResponseGrace)
+		defer cancel()
+	}
+
+	resp, err := c.forwarder.ExecTask(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("not sent: %s", status.Convert(err).Message())
+	}
+
+	results := make(Results, len(resp.GetResponses()))
+	for nd, r := range resp.GetResponses() {
+		results[nd] = toResult(r)
+	}
+	return results, nil
+}
+
+func newTaskRequest(target string, mode TargetMode, task string, args []any, namedOptions map[string]any, opts RunOptions) (*proto.TaskRequest, error) {
+	if target == "" {
+		return nil, errors.New("target must not be empty")
+	}
+
+	argList, err := structpb.NewList(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert arguments to protobuf list: %w", err)
+	}
+	options, err := structpb.NewStruct(namedOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert options to protobuf struct: %w", err)
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = config.TaskTimeout
+	}
+
+	plugin, name, ok := strings.Cut(task, config.PluginSeparator)
+	if !ok {
+		name = plugin // a plugin and its task share the same name
+	}
+	return &proto.TaskRequest{
+		Target:      target,
+		TargetMode:  mode.toProto(),
+		LockMode:    opts.LockMode.toProto(),
+		Plugin:      plugin,
+		Task:        name,
+		Input:       &proto.Input{Args: argList, Options: options},
+		Timeout:     helper.IntToUint32(int(timeout.Seconds())),
+		Metadata:    opts.Metadata,
+		FailFast:    opts.FailFast,
+		Rolling:     opts.Rolling,
+		MaxFailures: helper.IntToUint32(opts.MaxFailures),
+	}, nil
+}
+
+func toResult(r *proto.TaskResponse) Result {
+	result := Result{
+		ID:      r.GetId(),
+		GroupID: r.GetGroupID(),
+		Output:  r.GetOutput(),
+		Error:   r.GetError(),
+		Retcode: r.GetRetcode(),
+		Status:  database.ResultStatus(r),
+	}
+	if r.GetInternalError() != proto.InternalError_OK {
+		result.InternalError = r.GetInternalError().String()
+	}
+	return result
+}
+
+// Result returns a result stored by the manager, serialized in JSON. The result of a group ID is the list of
+// the results of the group.
+func (c *Client) Result(ctx context.Context, id string) (json.RawMessage, error) {
+	resp, err := c.api.GetResults(ctx, &proto.ResultsRequest{ResultID: id})
+	if err != nil {
+		return nil, fmt.Errorf("result %s: %s", id, status.Convert(err).Message())
+	}
+
+	ids, grouped := database.CutGroupPrefix(resp.GetResult())
+	if !grouped {
+		return json.RawMessage(resp.GetResult()), nil
+	}
+
+	results := []json.RawMessage{}
+	for subID := range strings.SplitSeq(ids, ",") {
+		if subID == "" {
+			continue
+		}
+		sub, err := c.api.GetResults(ctx, &proto.ResultsRequest{ResultID: subID})
+		if err != nil {
+			return nil, fmt.Errorf("result %s of group %s: %s", subID, id, status.Convert(err).Message())
+		}
+		results = append(results, json.RawMessage(sub.GetResult()))
+	}
+	return json.Marshal(results)
+}
+
+// Nodes returns the nodes known by the manager: the accepted ones, the candidates and the rejected ones.
+func (c *Client) Nodes(ctx context.Context) ([]Node, error) {
+	resp, err := c.api.ListNodes(ctx, &proto.ListNodesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the nodes: %s", status.Convert(err).Message())
+	}
+
+	var nodes []Node
+	add := func(status string, infos []*proto.NodeInfo) {
+		for _, info := range infos {
+			nd := Node{
+				ID:          info.GetId(),
+				Status:      status,
+				Address:     info.GetAddress(),
+				Certificate: info.GetCertificate(),
+				Connected:   info.GetIsConnected(),
+				Version:     info.GetVersion(),
+			}
+			if info.Since != nil {
+				nd.Since = info.GetSince().AsTime()
+			}
+			if info.LastMsg != nil {
+				nd.LastMsg = info.GetLastMsg().AsTime()
+			}
+			nodes = append(nodes, nd)
+		}
+	}
+	add("accepted", resp.GetAccepted())
+	add("candidate", resp.GetCandidates())
+	add("rejected", resp.GetRejected())
+	return nodes, nil
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeManager serves the forwarder and the API of a manager with fixed nodes and results.
+type fakeManager struct {
+	proto.UnimplementedForwarderServer
+	proto.UnimplementedAPIServer
+
+	requests chan *proto.TaskRequest
+}
+
+func (m *fakeManager) ExecTask(_ context.Context, req *proto.TaskRequest) (*proto.FwdResponse, error) {
+	m.requests <- req
+	groupID := int64(100)
+	return &proto.FwdResponse{Responses: map[string]*proto.TaskResponse{
+		"web-1": {Id: 101, GroupID: &groupID, Output: []byte(`"up 3 days"`)},
+		"web-2": {Id: 102, GroupID: &groupID, InternalError: proto.InternalError_TIMEOUT},
+	}}, nil
+}
+
+func (m *fakeManager) GetResults(_ context.Context, req *proto.ResultsRequest) (*proto.ResultsResponse, error) {
+	switch req.GetResultID() {
+	case "100":
+		return &proto.ResultsResponse{Result: "grouped:101,102"}, nil
+	case "101":
+		return &proto.ResultsResponse{Result: `{"id":101,"output":"up 3 days"}`}, nil
+	case "102":
+		return &proto.ResultsResponse{Result: `{"id":102,"internalError":"TIMEOUT"}`}, nil
+	}
+	return nil, status.Error(codes.NotFound, "Key not found")
+}
+
+func (m *fakeManager) ListNodes(context.Context, *proto.ListNodesRequest) (*proto.ListNodesResponse, error) {
+	connected := true
+	address := "10.0.0.1"
+	return &proto.ListNodesResponse{
+		Accepted:   []*proto.NodeInfo{{Id: "web-1", Address: &address, IsConnected: &connected, Since: timestamppb.New(time.Unix(1700000000, 0))}},
+		Candidates: []*proto.NodeInfo{{Id: "web-3"}},
+	}, nil
+}
+
+func newTestClient(t *testing.T) (*Client, *fakeManager) {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	manager := &fakeManager{requests: make(chan *proto.TaskRequest, 1)}
+	proto.RegisterForwarderServer(srv, manager)
+	proto.RegisterAPIServer(srv, manager)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	c, err := New(
+		WithAddress("passthrough:///bufnet"),
+		WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) })),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+	return c, manager
+}
+
+func TestRun(t *testing.T) {
+	c, manager := newTestClient(t)
+
+	results, err := c.Run(context.Background(), "web-*", Glob, "cmd.run", []string{"uptime", "shell=bash"}, RunOptions{
+		LockMode:    WriteLock,
+		Timeout:     10 * time.Second,
+		Metadata:    map[string]string{"build": "1234"},
+		Rolling:     true,
+		MaxFailures: 2,
+	})
+	require.NoError(t, err)
+
+	req := <-manager.requests
+	assert.Equal(t, "web-*", req.GetTarget())
+	assert.Equal(t, proto.TargetMode_GLOB, req.GetTargetMode())
+	assert.Equal(t, "cmd.run", req.FullTask())
+	assert.Equal(t, proto.LockMode_WRITE, req.GetLockMode())
+	assert.Equal(t, uint32(10), req.GetTimeout())
+	assert.Equal(t, "uptime", req.GetInput().GetArgs().GetValues()[0].GetStringValue())
+	assert.Equal(t, "bash", req.GetInput().GetOptions().GetFields()["shell"].GetStringValue())
+	assert.Equal(t, map[string]string{"build": "1234"}, req.GetMetadata())
+	assert.True(t, req.GetRolling())
+	assert.Equal(t, uint32(2), req.GetMaxFailures())
+
+	assert.Equal(t, Results{
+		"web-1": {ID: 101, GroupID: 100, Output: json.RawMessage(`"up 3 days"`), Status: "success"},
+		"web-2": {ID: 102, GroupID: 100, InternalError: "TIMEOUT", Status: "internal error"},
+	}, results)
+
+	_, err = c.Run(context.Background(), "", Glob, "cmd.run", nil, RunOptions{})
+	assert.Error(t, err, "an empty target must be refused")
+}
+
+func TestRunInput(t *testing.T) {
+	c, manager := newTestClient(t)
+
+	type server struct {
+		Hostname string `jackadi:"hostname"`
+		CPUCores int    `jackadi:"cpu_cores"`
+	}
+	type options struct {
+		DryRun bool `jackadi:"dry-run"`
+	}
+
+	_, err := c.RunInput(context.Background(), "web-*", Glob, "demo.create_user",
+		[]any{int64(12345), []string{"read"}, server{Hostname: "web-01", CPUCores: 4}}, options{DryRun: true}, RunOptions{})
+	require.NoError(t, err)
+
+	req := <-manager.requests
+	assert.Equal(t, "demo.create_user", req.FullTask())
+	args := req.GetInput().GetArgs().AsSlice()
+	assert.Equal(t, []any{float64(12345), []any{"read"}, map[string]any{"hostname": "web-01", "cpu_cores": float64(4)}}, args)
+	assert.Equal(t, map[string]any{"dry-run": true}, req.GetInput().GetOptions().AsMap())
+}
+
+func TestResult(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	result, err := c.Result(context.Background(), "101")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":101,"output":"up 3 days"}`, string(result))
+
+	group, err := c.Result(context.Background(), "100")
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"id":101,"output":"up 3 days"},{"id":102,"internalError":"TIMEOUT"}]`, string(group))
+
+	_, err = c.Result(context.Background(), "42")
+	assert.ErrorContains(t, err, "Key not found")
+}
+
+func TestNodes(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	nodes, err := c.Nodes(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Node{
+		{ID: "web-1", Status: "accepted", Address: "10.0.0.1", Connected: true, Since: time.Unix(1700000000, 0).UTC()},
+		{ID: "web-3", Status: "candidate"},
+	}, nodes)
+}
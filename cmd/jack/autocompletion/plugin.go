@@ -8,13 +8,18 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/jackadi-io/jackadi/internal/config"
 	"github.com/jackadi-io/jackadi/internal/node"
 	"github.com/jackadi-io/jackadi/internal/plugin/inventory"
+	"github.com/jackadi-io/jackadi/internal/serializer"
 	"github.com/spf13/cobra"
 )
 
+// loadBuiltins registers the builtin plugins once, registering them twice is fatal.
+var loadBuiltins = sync.OnceFunc(func() { node.LoadBuiltins(nil) })
+
 type TaskInfo struct {
 	Name    string
 	Summary string
@@ -42,7 +47,7 @@ func GetAvailablePlugins() map[string]*PluginInfo {
 func getBuiltinPlugins() map[string]*PluginInfo {
 	plugins := make(map[string]*PluginInfo)
 
-	node.LoadBuiltins(nil)
+	loadBuiltins()
 	pluginNames := inventory.Registry.Names()
 
 	for _, name := range pluginNames {
@@ -75,27 +80,37 @@ func getBuiltinPlugins() map[string]*PluginInfo {
 
 // getExternalPlugins returns plugins from external plugin files.
 func getExternalPlugins() map[string]*PluginInfo {
-	pluginDir := config.DefaultPluginDir // TODO: add config file for CLI
 	plugins := make(map[string]*PluginInfo)
+	for _, pluginPath := range externalPluginFiles() {
+		plugin := getInfoFromPlugin(pluginPath)
+		if plugin != nil {
+			plugins[plugin.Name] = plugin
+		}
+	}
+
+	return plugins
+}
 
+// externalPluginFiles returns the executable files of the plugin directory.
+func externalPluginFiles() []string {
+	pluginDir := config.DefaultPluginDir // TODO: add config file for CLI
 	if _, err := os.Stat(pluginDir); os.IsNotExist(err) {
 		slog.Debug("plugin directory does not exist", "path", pluginDir)
-		return plugins
+		return nil
 	}
 
 	entries, err := os.ReadDir(pluginDir)
 	if err != nil {
 		slog.Debug("failed to read plugin directory", "path", pluginDir, "error", err)
-		return plugins
+		return nil
 	}
 
+	var files []string
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
 
-		pluginPath := filepath.Join(pluginDir, entry.Name())
-
 		// Make sure the file is executable
 		info, err := entry.Info()
 		if err != nil {
@@ -105,13 +120,55 @@ func getExternalPlugins() map[string]*PluginInfo {
 			continue // Not executable
 		}
 
-		plugin := getInfoFromPlugin(pluginPath)
-		if plugin != nil {
-			plugins[plugin.Name] = plugin
+		files = append(files, filepath.Join(pluginDir, entry.Name()))
+	}
+
+	return files
+}
+
+// inputSchemaDescriber is implemented by the plugins built with the SDK.
+type inputSchemaDescriber interface {
+	InputSchemas() (map[string]any, error)
+}
+
+// GetInputSchemas returns the JSON Schema of the inputs of the tasks of the manager's plugins and built-ins, by
+// plugin and task.
+func GetInputSchemas() map[string]map[string]any {
+	schemas := make(map[string]map[string]any)
+
+	loadBuiltins()
+	for _, name := range inventory.Registry.Names() {
+		coll, err := inventory.Registry.Get(name)
+		if err != nil {
+			continue
+		}
+		described, ok := coll.(inputSchemaDescriber)
+		if !ok {
+			continue
+		}
+		if schema, err := described.InputSchemas(); err == nil {
+			schemas[name] = schema
 		}
 	}
 
-	return plugins
+	for _, pluginPath := range externalPluginFiles() {
+		cmd := exec.Command(pluginPath, "--schema")
+		cmd.Env = os.Environ()
+		output, err := cmd.Output()
+		if err != nil {
+			slog.Debug("failed to execute plugin schema", "plugin", pluginPath, "error", err)
+			continue
+		}
+
+		schema := make(map[string]any)
+		if err := serializer.JSON.Unmarshal(output, &schema); err != nil {
+			slog.Debug("invalid plugin schema, plugin built with an older SDK?", "plugin", pluginPath, "error", err)
+			continue
+		}
+		schemas[filepath.Base(pluginPath)] = schema
+	}
+
+	return schemas
 }
 
 // getInfoFromPlugin executes a plugin with --describe to get its information.
@@ -1,21 +1,147 @@
 package connection
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"syscall"
+	"time"
 
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
 	"github.com/jackadi-io/jackadi/internal/config"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
+// The failures to connect the manager, jack exits with a distinct code for each of them (see ExitCode).
+var (
+	ErrManagerNotRunning = errors.New("manager not running") // No socket, or the connection is refused.
+	ErrPermissionDenied  = errors.New("permission denied")   // The socket cannot be used by the user.
+	ErrUnreachable       = errors.New("manager unreachable") // The connection failed or timed out.
+)
+
+// Exit codes of jack when the manager cannot be connected.
+const (
+	ExitManagerNotRunning = 3
+	ExitPermissionDenied  = 4
+	ExitUnreachable       = 5
+)
+
+// ExitCode returns the exit code of jack for a failure to connect the manager, 0 if err is not one.
+func ExitCode(err error) int {
+	switch {
+	case errors.Is(err, ErrManagerNotRunning):
+		return ExitManagerNotRunning
+	case errors.Is(err, ErrPermissionDenied):
+		return ExitPermissionDenied
+	case errors.Is(err, ErrUnreachable):
+		return ExitUnreachable
+	}
+	return 0
+}
+
+// Exit prints the failure of a request to the manager, the message alone for an RPC error, and exits jack with the
+// code of the connection failure (see ExitCode), 1 for the other errors.
+func Exit(err error) {
+	msg := err.Error()
+	if s, ok := status.FromError(err); ok {
+		msg = s.Message()
+	}
+	fmt.Fprintln(os.Stderr, style.RenderError(msg))
+	if code := ExitCode(err); code != 0 {
+		os.Exit(code)
+	}
+	os.Exit(1)
+}
+
+// DialCLI connects to the manager, using the local socket or the remote access if set.
+//
+// It fails if the manager is not reachable within the connect timeout.
 func DialCLI() (*grpc.ClientConn, error) {
-	conn, err := grpc.NewClient(
-		fmt.Sprintf("unix:%s", config.CLISocket),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	if remote := option.GetRemote(); remote != "" {
+		return dialRemote(remote, *option.RemoteCert, *option.RemoteKey, *option.RemoteCA)
+	}
+
+	return dial("unix", config.CLISocket, insecure.NewCredentials(), option.GetConnectTimeout())
+}
+
+// RequestContext returns the context of a request to the manager, bounded by the request timeout.
+func RequestContext() (context.Context, context.CancelFunc) {
+	if timeout := option.GetRequestTimeout(); timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+func dialRemote(remote, cert, key, managerCA string) (*grpc.ClientConn, error) {
+	certs, ca, err := config.GetMTLSCertificate(cert, key, managerCA)
+	if err != nil {
+		return nil, fmt.Errorf("remote access: %w", err)
+	}
+	creds := credentials.NewTLS(&tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: certs,
+		RootCAs:      ca,
+	})
+
+	return dial("tcp", remote, creds, option.GetConnectTimeout())
+}
+
+// dial connects to the manager listening on the address, and waits for the connection to be ready at most timeout.
+// The connection is established by the first request if timeout is 0.
+func dial(network, address string, creds credentials.TransportCredentials, timeout time.Duration) (*grpc.ClientConn, error) {
+	target := address
+	if network == "unix" {
+		target = "unix:" + address
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
 	if err != nil {
 		return nil, fmt.Errorf("did not connect: %w", err)
 	}
+	if timeout <= 0 {
+		return conn, nil
+	}
+
+	if err := probe(network, address, timeout); err != nil {
+		conn.Close()
+		return nil, err
+	}
 
-	return conn, err
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return conn, nil
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			conn.Close()
+			return nil, fmt.Errorf("%w after %s", ErrUnreachable, timeout)
+		}
+	}
+}
+
+// probe checks that the manager listens on the address, to report why it cannot be connected otherwise.
+func probe(network, address string, timeout time.Duration) error {
+	d := net.Dialer{Timeout: timeout}
+	conn, err := d.Dial(network, address)
+	switch {
+	case err == nil:
+		return conn.Close()
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Errorf("%w: %w", ErrManagerNotRunning, err)
+	case errors.Is(err, fs.ErrPermission):
+		return fmt.Errorf("%w: %w", ErrPermissionDenied, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
 }
@@ -0,0 +1,112 @@
+package connection
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestDial_ConnectTimeout(t *testing.T) {
+	// a listener never accepting: the connection is not refused, but the manager does not answer
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer lis.Close()
+
+	start := time.Now()
+	conn, err := dial("tcp", lis.Addr().String(), insecure.NewCredentials(), 200*time.Millisecond)
+	if err == nil {
+		conn.Close()
+		t.Fatal("expected an error for an unresponsive manager")
+	}
+	if !errors.Is(err, ErrUnreachable) || ExitCode(err) != ExitUnreachable {
+		t.Errorf("expected the manager to be unreachable, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected to fail after the connect timeout, failed after %s", elapsed)
+	}
+}
+
+func TestDial_Failures(t *testing.T) {
+	dir := t.TempDir()
+
+	// a socket left by a stopped manager: the connection is refused
+	stale := filepath.Join(dir, "stale.sock")
+	lis, err := net.Listen("unix", stale)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lis.(*net.UnixListener).SetUnlinkOnClose(false)
+	lis.Close()
+
+	// a socket the user cannot use
+	denied := filepath.Join(dir, "denied", "manager.sock")
+	if err := os.Mkdir(filepath.Dir(denied), 0o000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(filepath.Dir(denied), 0o700) })
+
+	tests := map[string]struct {
+		socket   string
+		wantErr  error
+		wantCode int
+	}{
+		"missing socket": {filepath.Join(dir, "none.sock"), ErrManagerNotRunning, ExitManagerNotRunning},
+		"stale socket":   {stale, ErrManagerNotRunning, ExitManagerNotRunning},
+		"denied socket":  {denied, ErrPermissionDenied, ExitPermissionDenied},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if name == "denied socket" && os.Geteuid() == 0 {
+				t.Skip("the permissions do not apply to root")
+			}
+			conn, err := dial("unix", tt.socket, insecure.NewCredentials(), time.Second)
+			if err == nil {
+				conn.Close()
+				t.Fatal("expected an error")
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+			if code := ExitCode(err); code != tt.wantCode {
+				t.Errorf("expected the exit code %d, got %d", tt.wantCode, code)
+			}
+		})
+	}
+
+	if code := ExitCode(errors.New("other")); code != 0 {
+		t.Errorf("expected no exit code for other errors, got %d", code)
+	}
+}
+
+func TestDial(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "manager.sock")
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	srv := grpc.NewServer()
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := dial("unix", socket, insecure.NewCredentials(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conn.Close()
+
+	// no connect timeout: the connection is established by the first request
+	conn, err = dial("unix", filepath.Join(t.TempDir(), "none.sock"), insecure.NewCredentials(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error without connect timeout: %v", err)
+	}
+	conn.Close()
+}
@@ -3,11 +3,16 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
 	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/subcommand/admin"
 	"github.com/jackadi-io/jackadi/cmd/jack/subcommand/job/result"
 	"github.com/jackadi-io/jackadi/cmd/jack/subcommand/job/task"
 	"github.com/jackadi-io/jackadi/cmd/jack/subcommand/node"
+	"github.com/jackadi-io/jackadi/cmd/jack/subcommand/plugin"
+	"github.com/jackadi-io/jackadi/internal/config"
 	_ "github.com/jackadi-io/jackadi/internal/plugin/builtin"
 	"github.com/spf13/cobra"
 )
@@ -16,13 +21,6 @@ var version = "dev"
 var commit = "N/A"
 var date = "N/A"
 
-func sprintVersion() string {
-	if version != "dev" {
-		version = fmt.Sprintf("v%s", version)
-	}
-	return fmt.Sprintf("%s (commit: %s, build date: %s)\n", version, commit, date)
-}
-
 func main() {
 	var completionCmd = &cobra.Command{
 		Use:       "completion [bash|zsh|fish]",
@@ -59,15 +57,44 @@ func main() {
 	)
 
 	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(versionCommand())
 	rootCmd.AddCommand(task.RunCommand())
+	rootCmd.AddCommand(task.HistoryCommand())
 	rootCmd.AddCommand(node.Root())
+	rootCmd.AddCommand(plugin.Root())
+	rootCmd.AddCommand(plugin.CatalogCommand())
+	rootCmd.AddCommand(plugin.GenCommand())
 	rootCmd.AddCommand(result.ResultsCmd())
+	rootCmd.AddCommand(admin.Root())
 
 	option.JSONFormat = rootCmd.PersistentFlags().Bool("json", false, "display result in JSON")
 	option.SortOutput = rootCmd.PersistentFlags().Bool("sort", true, "sort output (default: true)")
+	option.Remote = rootCmd.PersistentFlags().String("remote", os.Getenv("JACK_REMOTE"), "manager remote CLI access (host:port), instead of the local socket (env: JACK_REMOTE)")
+	option.RemoteCert = rootCmd.PersistentFlags().String("remote-cert", os.Getenv("JACK_REMOTE_CERT"), "client certificate of the remote access (env: JACK_REMOTE_CERT)")
+	option.RemoteKey = rootCmd.PersistentFlags().String("remote-key", os.Getenv("JACK_REMOTE_KEY"), "client key of the remote access (env: JACK_REMOTE_KEY)")
+	option.RemoteCA = rootCmd.PersistentFlags().String("remote-ca-cert", os.Getenv("JACK_REMOTE_CA_CERT"), "manager CA certificate of the remote access (env: JACK_REMOTE_CA_CERT)")
+	option.ConnectTimeout = rootCmd.PersistentFlags().Duration("connect-timeout", envDuration("JACK_CONNECT_TIMEOUT", config.CLIConnectTimeout), "timeout to connect the manager, 0 to wait for the request (env: JACK_CONNECT_TIMEOUT)")
+	option.RequestTimeout = rootCmd.PersistentFlags().Duration("request-timeout", envDuration("JACK_REQUEST_TIMEOUT", config.CLIRequestTimeout), "timeout of the requests, except the runs bounded by the task timeout (env: JACK_REQUEST_TIMEOUT)")
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		if code := connection.ExitCode(err); code != 0 {
+			os.Exit(code)
+		}
 		os.Exit(1)
 	}
 }
+
+// envDuration returns the duration set in the environment variable, def if unset or invalid.
+func envDuration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid %s, %s used: %s\n", key, def, err)
+		return def
+	}
+	return d
+}
@@ -1,5 +1,11 @@
 package option
 
+import (
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+)
+
 var JSONFormat *bool
 var SortOutput *bool
 
@@ -16,3 +22,33 @@ func GetSortOutput() bool {
 	}
 	return *SortOutput
 }
+
+// Remote is the address (host:port) of a manager remote CLI access, the local socket is used if empty.
+var Remote *string
+
+// RemoteCert, RemoteKey and RemoteCA are the client certificate, its key and the manager CA of the remote access.
+var RemoteCert, RemoteKey, RemoteCA *string
+
+func GetRemote() string {
+	if Remote == nil {
+		return ""
+	}
+	return *Remote
+}
+
+// ConnectTimeout is the timeout to connect the manager, RequestTimeout the timeout of the requests sent to it.
+var ConnectTimeout, RequestTimeout *time.Duration
+
+func GetConnectTimeout() time.Duration {
+	if ConnectTimeout == nil {
+		return config.CLIConnectTimeout
+	}
+	return *ConnectTimeout
+}
+
+func GetRequestTimeout() time.Duration {
+	if RequestTimeout == nil {
+		return config.CLIRequestTimeout
+	}
+	return *RequestTimeout
+}
@@ -0,0 +1,9 @@
+package style
+
+import "os"
+
+// IsTerminal returns whether the file is a terminal.
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
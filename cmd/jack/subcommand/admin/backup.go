@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+func backupCommand() *cobra.Command {
+	since := uint64(0)
+
+	cmd := &cobra.Command{
+		Use:   "backup FILE",
+		Short: "backup the manager database",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := backup(args[0], since); err != nil {
+				connection.Exit(err)
+			}
+			fmt.Printf("backup written to %s\n", args[0])
+		},
+	}
+	cmd.Flags().Uint64Var(&since, "since", 0, "incremental backup: only entries more recent than this version")
+
+	return cmd
+}
+
+func restoreCommand() *cobra.Command {
+	force := false
+
+	cmd := &cobra.Command{
+		Use:   "restore FILE",
+		Short: "restore the manager database from a backup",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := restore(args[0], force); err != nil {
+				connection.Exit(err)
+			}
+			fmt.Printf("database restored from %s\n", args[0])
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "replace the content of a non-empty database")
+
+	return cmd
+}
+
+func backup(file string, since uint64) error {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewAPIClient(conn)
+
+	fd, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	stream, err := client.Backup(context.Background(), &proto.BackupRequest{Since: since})
+	if err != nil {
+		return errors.New(status.Convert(err).Message())
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return fd.Sync()
+		}
+		if err != nil {
+			_ = os.Remove(file)
+			return fmt.Errorf("backup failed: %s", status.Convert(err).Message())
+		}
+		if _, err := fd.Write(chunk.GetData()); err != nil {
+			return err
+		}
+	}
+}
+
+func restore(file string, force bool) error {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewAPIClient(conn)
+
+	fd, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	stream, err := client.Restore(context.Background())
+	if err != nil {
+		return errors.New(status.Convert(err).Message())
+	}
+
+	buf := make([]byte, config.BackupChunkSize)
+	first := true
+	for {
+		n, err := fd.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&proto.RestoreChunk{Data: buf[:n], Force: first && force}); err != nil {
+				break // the actual error is returned by CloseAndRecv
+			}
+			first = false
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := stream.CloseAndRecv(); err != nil {
+		return fmt.Errorf("restore failed: %s", status.Convert(err).Message())
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func lockStatsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock-stats",
+		Short: "show the time the tasks waited for their lock on each node",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			resp, err := lockStats()
+			if err != nil {
+				connection.Exit(err)
+			}
+
+			if option.GetJSONFormat() {
+				result, err := serializer.JSON.MarshalIndent(resp, "", "   ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "failed to serialize response in JSON: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(result))
+				return
+			}
+
+			style.PrettyPrint(prettyLockStatsSprint(resp))
+		},
+	}
+
+	return cmd
+}
+
+func lockStats() (*proto.LockStatsResponse, error) {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewAPIClient(conn)
+
+	ctxReq, cancel := connection.RequestContext()
+	defer cancel()
+
+	resp, err := client.LockStats(ctxReq, &emptypb.Empty{})
+	if err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+	return resp, nil
+}
+
+func prettyLockStatsSprint(resp *proto.LockStatsResponse) string {
+	if len(resp.GetNodes()) == 0 {
+		return style.Item(style.RenderUnknown("no task reported its lock wait yet"))
+	}
+
+	out := ""
+	for _, nd := range slices.Sorted(maps.Keys(resp.GetNodes())) {
+		stats := resp.GetNodes()[nd]
+		out += style.Title(nd)
+		conflicts := fmt.Sprintf("%d", stats.GetConflicts())
+		if stats.GetConflicts() > 0 {
+			conflicts = style.RenderError(conflicts)
+		}
+		out += style.Item(fmt.Sprintf("%s %s", style.Emph("conflicting lock modes:"), conflicts))
+
+		for _, mode := range slices.Sorted(maps.Keys(stats.GetModes())) {
+			wait := stats.GetModes()[mode]
+			avg := time.Duration(0)
+			if wait.GetTasks() > 0 {
+				avg = time.Duration(wait.GetTotalWait()/int64(wait.GetTasks())) * time.Millisecond
+			}
+			out += style.Item(fmt.Sprintf("%s %d tasks, %d contended, average wait %s, max wait %s",
+				style.Emph(mode+":"), wait.GetTasks(), wait.GetContended(), avg, time.Duration(wait.GetMaxWait())*time.Millisecond))
+		}
+	}
+	return out
+}
@@ -0,0 +1,18 @@
+package admin
+
+import "github.com/spf13/cobra"
+
+func Root() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "admin [OPTION] ...",
+		Short:   "administrate the manager",
+		GroupID: "operations",
+	}
+
+	cmd.AddCommand(backupCommand())
+	cmd.AddCommand(restoreCommand())
+	cmd.AddCommand(dbStatsCommand())
+	cmd.AddCommand(lockStatsCommand())
+
+	return cmd
+}
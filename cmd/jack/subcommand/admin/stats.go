@@ -0,0 +1,101 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func dbStatsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db-stats",
+		Short: "show the manager database size and garbage collection status",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			resp, err := dbStats()
+			if err != nil {
+				connection.Exit(err)
+			}
+
+			if option.GetJSONFormat() {
+				result, err := serializer.JSON.MarshalIndent(resp, "", "   ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "failed to serialize response in JSON: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(result))
+				return
+			}
+
+			style.PrettyPrint(prettyDBStatsSprint(resp))
+		},
+	}
+
+	return cmd
+}
+
+func dbStats() (*proto.DatabaseStatsResponse, error) {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewAPIClient(conn)
+
+	ctxReq, cancel := connection.RequestContext()
+	defer cancel()
+
+	resp, err := client.DatabaseStats(ctxReq, &emptypb.Empty{})
+	if err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+	return resp, nil
+}
+
+func prettyDBStatsSprint(resp *proto.DatabaseStatsResponse) string {
+	out := style.Title("Database")
+	out += style.Item(fmt.Sprintf("%s %s", style.Emph("LSM size:"), formatBytes(resp.GetLsmSize())))
+	out += style.Item(fmt.Sprintf("%s %s", style.Emph("value log size:"), formatBytes(resp.GetVlogSize())))
+	out += style.Item(fmt.Sprintf("%s ~%d", style.Emph("keys:"), resp.GetKeys()))
+
+	out += style.Title("Last garbage collection")
+	if resp.GetLastGc() == nil {
+		out += style.Item(style.RenderUnknown("not run yet"))
+		return out
+	}
+	out += style.Item(fmt.Sprintf("%s %s", style.Emph("time:"), resp.GetLastGc().AsTime().Local().Format(time.DateTime)))
+	out += style.Item(fmt.Sprintf("%s %s", style.Emph("reclaimed:"), formatBytes(resp.GetLastGcReclaimed())))
+	switch {
+	case resp.GetLastGcError() != "":
+		out += style.Item(fmt.Sprintf("%s %s", style.Emph("outcome:"), style.RenderError(resp.GetLastGcError())))
+	case resp.GetLastGcRewrites() > 0:
+		out += style.Item(fmt.Sprintf("%s %s", style.Emph("outcome:"), style.RenderSuccess(fmt.Sprintf("%d files rewritten", resp.GetLastGcRewrites()))))
+	default:
+		out += style.Item(fmt.Sprintf("%s %s", style.Emph("outcome:"), "nothing to reclaim"))
+	}
+	return out
+}
+
+// formatBytes returns a human readable size, in binary units.
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,65 @@
+package result
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/manager/database"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+var csvHeader = []string{"id", "timestamp", "node", "plugin:task", "status", "retcode", "error"}
+
+// csvWriter renders the results as CSV as they are received, one row per node result.
+//
+// Grouped results are flattened: the group entry itself is skipped since each of its
+// results is listed as an individual entry.
+type csvWriter struct {
+	cw *csv.Writer
+}
+
+// newCSVWriter writes the header of the CSV.
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	return &csvWriter{cw: cw}, nil
+}
+
+func (c *csvWriter) write(result *proto.ResultEntry) error {
+	if _, grouped := database.CutGroupPrefix(result.GetNode()); grouped {
+		return nil
+	}
+
+	return c.cw.Write([]string{
+		strconv.FormatInt(result.GetId(), 10),
+		time.Unix(0, result.GetId()).UTC().Format(time.RFC3339),
+		result.GetNode(),
+		result.GetTask(),
+		result.GetStatus(),
+		strconv.FormatInt(int64(result.GetRetcode()), 10),
+		result.GetError(),
+	})
+}
+
+func (c *csvWriter) flush() error {
+	c.cw.Flush()
+	return c.cw.Error()
+}
+
+// writeCSV renders the results as CSV.
+func writeCSV(w io.Writer, results []*proto.ResultEntry) error {
+	c, err := newCSVWriter(w)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if err := c.write(result); err != nil {
+			return err
+		}
+	}
+	return c.flush()
+}
@@ -0,0 +1,89 @@
+package result
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCSV(t *testing.T) {
+	id := time.Date(2025, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)).UnixNano()
+	timestamp := "2025-01-02T02:04:05Z"
+	idStr := func(offset int64) string { return strconv.FormatInt(id+offset, 10) }
+
+	tests := []struct {
+		name     string
+		results  []*proto.ResultEntry
+		expected [][]string
+	}{
+		{
+			name:     "empty",
+			results:  nil,
+			expected: [][]string{csvHeader},
+		},
+		{
+			name: "single result",
+			results: []*proto.ResultEntry{
+				{Id: id, Node: "node1", Task: "cmd.run", Status: "success", Retcode: 0},
+			},
+			expected: [][]string{
+				csvHeader,
+				{idStr(0), timestamp, "node1", "cmd.run", "success", "0", ""},
+			},
+		},
+		{
+			name: "grouped result is flattened",
+			results: []*proto.ResultEntry{
+				{Id: id + 2, Node: "node2", Task: "cmd.run", Status: "error", Retcode: 1, Error: "exit status 1"},
+				{Id: id + 1, Node: "node1", Task: "cmd.run", Status: "success"},
+				{Id: id, Node: "grouped:1,2", Status: "success"},
+			},
+			expected: [][]string{
+				csvHeader,
+				{idStr(2), timestamp, "node2", "cmd.run", "error", "1", "exit status 1"},
+				{idStr(1), timestamp, "node1", "cmd.run", "success", "0", ""},
+			},
+		},
+		{
+			name: "escaping",
+			results: []*proto.ResultEntry{
+				{Id: id, Node: "node1", Task: "cmd.run", Status: "error", Retcode: 2, Error: `bad "value", see logs`},
+			},
+			expected: [][]string{
+				csvHeader,
+				{idStr(0), timestamp, "node1", "cmd.run", "error", "2", `bad "value", see logs`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeCSV(&buf, tt.results))
+
+			records, err := csv.NewReader(&buf).ReadAll()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, records)
+		})
+	}
+}
+
+func TestWriteCSV_RawEscaping(t *testing.T) {
+	var buf bytes.Buffer
+	results := []*proto.ResultEntry{
+		{Id: 1, Node: "node1", Task: "cmd.run", Status: "error", Error: `a,b "c"`},
+	}
+	require.NoError(t, writeCSV(&buf, results))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "id,timestamp,node,plugin:task,status,retcode,error", lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], `,"a,b ""c"""`), lines[1])
+}
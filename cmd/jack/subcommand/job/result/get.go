@@ -1,12 +1,9 @@
 package result
 
 import (
-	"context"
 	"errors"
 	"fmt"
-	"os"
 	"strings"
-	"time"
 
 	"github.com/jackadi-io/jackadi/cmd/jack/connection"
 	"github.com/jackadi-io/jackadi/cmd/jack/style"
@@ -25,16 +22,14 @@ func getCommand() *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			conn, err := connection.DialCLI()
 			if err != nil {
-				fmt.Fprintln(os.Stderr, "failed to connect the manager")
-				os.Exit(1)
+				connection.Exit(fmt.Errorf("failed to connect the manager: %w", err))
 			}
 			defer conn.Close()
 			client := proto.NewAPIClient(conn)
 
 			res, err := getResult(client, args[0])
 			if err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				os.Exit(1)
+				connection.Exit(err)
 			}
 
 			out := getRequest(client, args)
@@ -48,7 +43,7 @@ func getCommand() *cobra.Command {
 }
 
 func fetchResult(client proto.APIClient, id string) (*proto.ResultsResponse, error) {
-	ctxReq, cancel := context.WithTimeout(context.Background(), time.Minute)
+	ctxReq, cancel := connection.RequestContext()
 	defer cancel()
 
 	return client.GetResults(ctxReq, &proto.ResultsRequest{ResultID: id})
@@ -83,7 +78,7 @@ func getResult(client proto.APIClient, id string) (string, error) {
 }
 
 func fetchRequest(client proto.APIClient, requestID string) (string, error) {
-	ctxReq, cancel := context.WithTimeout(context.Background(), time.Minute)
+	ctxReq, cancel := connection.RequestContext()
 	defer cancel()
 
 	resp, err := client.GetRequest(ctxReq, &proto.RequestRequest{RequestID: requestID})
@@ -1,10 +1,11 @@
 package result
 
 import (
-	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,6 +14,8 @@ import (
 	"github.com/jackadi-io/jackadi/internal/config"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func formatResultItem(id int64, date, nodes, status string) string {
@@ -45,8 +48,13 @@ func listCommand() *cobra.Command {
 	fromDate := int64(0)
 	toDate := int64(0)
 	targets := []string{}
+	metadata := map[string]string{}
 	fromStr := ""
 	toStr := ""
+	csvFormat := false
+	statuses := []string{}
+	failed := false
+	retcode := int32(0)
 
 	cmd := &cobra.Command{
 		Use:   "list",
@@ -54,9 +62,9 @@ func listCommand() *cobra.Command {
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			// Validate limit does not exceed maximum
-			if limit > config.ResultsPageLimit {
-				limit = config.ResultsPageLimit
-				fmt.Printf("Warning: limit exceeded maximum (%d), using maximum value\n", config.ResultsPageLimit)
+			if limit > config.MaxResultsLimit {
+				limit = config.MaxResultsLimit
+				fmt.Printf("Warning: limit exceeded maximum (%d), using maximum value\n", config.MaxResultsLimit)
 			}
 
 			// Parse date strings if provided
@@ -83,19 +91,55 @@ func listCommand() *cobra.Command {
 				os.Exit(1)
 			}
 
-			res, err := list(limit, offset, fromDate, toDate, targets)
-			if err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				os.Exit(1)
+			if failed {
+				statuses = append(statuses, failedStatuses...)
+			}
+			var retcodeFilter *int32
+			if cmd.Flags().Changed("retcode") {
+				retcodeFilter = &retcode
+			}
+
+			req := &proto.ListResultsRequest{
+				Limit:    limit,
+				Offset:   offset,
+				FromDate: &fromDate,
+				ToDate:   &toDate,
+				Targets:  targets,
+				Metadata: metadata,
+				Statuses: statuses,
+				Retcode:  retcodeFilter,
+			}
+
+			if csvFormat {
+				w, err := newCSVWriter(os.Stdout)
+				if err == nil {
+					err = streamResults(req, w.write)
+				}
+				if err == nil {
+					err = w.flush()
+				}
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
+				return
+			}
+
+			if err := list(req); err != nil {
+				connection.Exit(err)
 			}
-			style.PrettyPrint(res)
 		},
 	}
-	cmd.Flags().Int32VarP(&limit, "limit", "l", 100, fmt.Sprintf("maximum number of results to return (max: %d)", config.ResultsPageLimit))
+	cmd.Flags().Int32VarP(&limit, "limit", "l", 100, fmt.Sprintf("maximum number of results to return (max: %d)", config.MaxResultsLimit))
 	cmd.Flags().Int32VarP(&offset, "offset", "o", 0, "starting position for pagination")
 	cmd.Flags().StringVar(&fromStr, "from", "", "filter results from this date (format: 2006-01-01 or 2006-01-01 15:04:05)")
 	cmd.Flags().StringVar(&toStr, "to", "", "filter results up to this date (format: 2006-01-01 or 2006-01-01 15:04:05)")
 	cmd.Flags().StringSliceVarP(&targets, "targets", "t", []string{}, "filter results by node IDs (comma separated)")
+	cmd.Flags().StringToStringVar(&metadata, "meta", nil, "filter results by metadata of the request, e.g. --meta build=1234 (repeatable)")
+	cmd.Flags().StringSliceVar(&statuses, "status", nil, "filter results by status: success, error, timeout, internal error or unknown (comma separated)")
+	cmd.Flags().BoolVar(&failed, "failed", false, "filter the failed results, same as --status error,timeout,'internal error'")
+	cmd.Flags().Int32Var(&retcode, "retcode", 0, "filter results by return code")
+	cmd.Flags().BoolVar(&csvFormat, "csv", false, "output results as CSV")
 
 	return cmd
 }
@@ -116,29 +160,57 @@ func parseTimeString(timeStr string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unsupported time format: %s", timeStr)
 }
 
-func list(limit, offset int32, fromDate, toDate int64, targets []string) (string, error) {
+// failedStatuses are the statuses of the failed results.
+var failedStatuses = []string{"error", "timeout", "internal error"}
+
+// streamResults passes the results matching the request to emit as soon as they are received, or all at once if
+// the manager does not support streaming (its page limit then applies).
+func streamResults(req *proto.ListResultsRequest, emit func(*proto.ResultEntry) error) error {
 	conn, err := connection.DialCLI()
 	if err != nil {
-		return "", errors.New("failed to connect the manager")
+		return fmt.Errorf("failed to connect the manager: %w", err)
 	}
 	defer conn.Close()
 	client := proto.NewAPIClient(conn)
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	ctx, cancel := connection.RequestContext()
 	defer cancel()
 
-	req := &proto.ListResultsRequest{
-		Limit:    limit,
-		Offset:   offset,
-		FromDate: &fromDate,
-		ToDate:   &toDate,
-		Targets:  targets,
-	}
-
-	resp, err := client.ListResults(ctx, req)
+	stream, err := client.StreamResults(ctx, req)
 	if err != nil {
-		return "", err
+		return err
 	}
+	for {
+		entry, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if status.Code(err) == codes.Unimplemented {
+			resp, err := client.ListResults(ctx, req)
+			if err != nil {
+				return err
+			}
+			for _, entry := range resp.GetResults() {
+				if err := emit(entry); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := emit(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// list displays the results matching the request as they are received.
+func list(req *proto.ListResultsRequest) error {
+	fromDate, toDate := req.GetFromDate(), req.GetToDate()
+	targets, metadata, statuses := req.GetTargets(), req.GetMetadata(), req.GetStatuses()
+	limit, offset := req.GetLimit(), req.GetOffset()
 
 	out := style.Title("Task results")
 
@@ -158,6 +230,23 @@ func list(limit, offset int32, fromDate, toDate int64, targets []string) (string
 		filters = append(filters, fmt.Sprintf("targets: %s", strings.Join(targets, ", ")))
 	}
 
+	if len(metadata) > 0 {
+		pairs := make([]string, 0, len(metadata))
+		for key, value := range metadata {
+			pairs = append(pairs, key+"="+value)
+		}
+		slices.Sort(pairs)
+		filters = append(filters, fmt.Sprintf("metadata: %s", strings.Join(pairs, ", ")))
+	}
+
+	if len(statuses) > 0 {
+		filters = append(filters, fmt.Sprintf("status: %s", strings.Join(statuses, ", ")))
+	}
+
+	if req.Retcode != nil {
+		filters = append(filters, fmt.Sprintf("retcode: %d", req.GetRetcode()))
+	}
+
 	if offset > 0 {
 		filters = append(filters, fmt.Sprintf("offset: %d", offset))
 	}
@@ -166,14 +255,11 @@ func list(limit, offset int32, fromDate, toDate int64, targets []string) (string
 		out += style.Subtitle(fmt.Sprintf("Filters: %s", strings.Join(filters, ", ")))
 	}
 
-	results := resp.GetResults()
-	if len(results) == 0 {
-		out += style.SpacedBlock(style.Item("No results found"))
-		return out, nil
-	}
+	fmt.Printf("\n%s\n\n", strings.Trim(out, "\n"))
 
-	var items strings.Builder
-	for _, result := range results {
+	count := 0
+	err := streamResults(req, func(result *proto.ResultEntry) error {
+		count++
 		id := result.GetId()
 		timestamp := time.Unix(0, id)
 		date := timestamp.Format("2006-01-02 15:04:05")
@@ -188,11 +274,18 @@ func list(limit, offset int32, fromDate, toDate int64, targets []string) (string
 			targets = result.GetNode()
 		}
 
-		status := result.GetStatus()
-		items.WriteString(formatResultItem(id, date, targets, status))
+		fmt.Print(formatResultItem(id, date, targets, result.GetStatus()))
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	paginationInfo := fmt.Sprintf("Showing %d results (limit: %d, offset: %d)", len(results), limit, offset)
-
-	return fmt.Sprintf("%s\n%s%s", out, items.String(), style.Subtitle(paginationInfo)), nil
+	if count == 0 {
+		style.PrettyPrint(style.Item("No results found"))
+		return nil
+	}
+	paginationInfo := fmt.Sprintf("Showing %d results (limit: %d, offset: %d)", count, limit, offset)
+	style.PrettyPrint(style.Subtitle(paginationInfo))
+	return nil
 }
@@ -0,0 +1,123 @@
+package result
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func pendingCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pending",
+		Short: "list the runs scheduled by jack run --at, the earliest first",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			resp, err := pendingTasks()
+			if err != nil {
+				connection.Exit(err)
+			}
+
+			if option.GetJSONFormat() {
+				result, err := serializer.JSON.MarshalIndent(resp, "", "   ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "failed to serialize response in JSON: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(result))
+				return
+			}
+
+			style.PrettyPrint(prettyPendingSprint(resp))
+		},
+	}
+
+	return cmd
+}
+
+func cancelPendingCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel-pending ID ...",
+		Short: "cancel runs scheduled by jack run --at",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			req := &proto.CancelPendingRequest{}
+			for _, arg := range args {
+				id, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, style.RenderError(fmt.Sprintf("invalid scheduled run ID: %s", arg)))
+					os.Exit(1)
+				}
+				req.Ids = append(req.Ids, id)
+			}
+
+			cancelled, err := cancelPending(req)
+			if err != nil {
+				connection.Exit(err)
+			}
+			fmt.Printf("%d scheduled run(s) cancelled\n", len(cancelled))
+		},
+	}
+
+	return cmd
+}
+
+func pendingTasks() (*proto.ListPendingResponse, error) {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewForwarderClient(conn)
+
+	ctxReq, cancel := connection.RequestContext()
+	defer cancel()
+
+	resp, err := client.ListPending(ctxReq, &emptypb.Empty{})
+	if err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+	return resp, nil
+}
+
+func cancelPending(req *proto.CancelPendingRequest) ([]int64, error) {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewForwarderClient(conn)
+
+	ctxReq, cancel := connection.RequestContext()
+	defer cancel()
+
+	resp, err := client.CancelPending(ctxReq, req)
+	if err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+	return resp.GetCancelled(), nil
+}
+
+func prettyPendingSprint(resp *proto.ListPendingResponse) string {
+	if len(resp.GetTasks()) == 0 {
+		return style.Item(style.RenderUnknown("no scheduled run"))
+	}
+
+	out := ""
+	for _, p := range resp.GetTasks() {
+		req := p.GetRequest()
+		at := p.GetAt().AsTime().Local().Format(time.RFC3339)
+		out += style.Item(fmt.Sprintf("%s %s on %s %s", style.Emph(at+":"), req.FullTask(), req.TargetsString(), style.RenderID(fmt.Sprintf("%d", p.GetId()))))
+	}
+	return out
+}
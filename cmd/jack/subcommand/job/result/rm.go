@@ -0,0 +1,102 @@
+package result
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+func rmCommand() *cobra.Command {
+	targets := []string{}
+	fromStr := ""
+	toStr := ""
+	since := time.Duration(0)
+
+	cmd := &cobra.Command{
+		Use:   "rm [ID ...]",
+		Short: "delete results by ID or by filters",
+		Long:  "Delete results by ID (a group ID deletes all the results of the group), or all the results matching the filters.",
+		Run: func(cmd *cobra.Command, args []string) {
+			req := &proto.DeleteResultsRequest{Targets: targets}
+
+			for _, arg := range args {
+				id, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, style.RenderError(fmt.Sprintf("invalid result ID: %s", arg)))
+					os.Exit(1)
+				}
+				req.Ids = append(req.Ids, id)
+			}
+
+			if fromStr != "" {
+				t, err := parseTimeString(fromStr)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid from-date format: %s\n", err)
+					os.Exit(1)
+				}
+				fromDate := t.UnixNano()
+				req.FromDate = &fromDate
+			}
+			if since > 0 {
+				fromDate := time.Now().Add(-since).UnixNano()
+				req.FromDate = &fromDate
+			}
+			if toStr != "" {
+				t, err := parseTimeString(toStr)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid to-date format: %s\n", err)
+					os.Exit(1)
+				}
+				toDate := t.UnixNano()
+				req.ToDate = &toDate
+			}
+
+			if len(req.GetIds()) > 0 && (req.FromDate != nil || req.ToDate != nil || len(targets) > 0) {
+				fmt.Fprintln(os.Stderr, style.RenderError("IDs and filters are mutually exclusive"))
+				os.Exit(1)
+			}
+			if len(req.GetIds()) == 0 && req.FromDate == nil && req.ToDate == nil && len(targets) == 0 {
+				fmt.Fprintln(os.Stderr, style.RenderError("at least one ID or filter is required"))
+				_ = cmd.Help()
+				os.Exit(1)
+			}
+
+			deleted, err := deleteResults(req)
+			if err != nil {
+				connection.Exit(err)
+			}
+			fmt.Printf("%d result(s) deleted\n", len(deleted))
+		},
+	}
+	cmd.Flags().StringVar(&fromStr, "from", "", "delete results from this date (format: 2006-01-01 or 2006-01-01 15:04:05)")
+	cmd.Flags().StringVar(&toStr, "to", "", "delete results up to this date (format: 2006-01-01 or 2006-01-01 15:04:05)")
+	cmd.Flags().DurationVar(&since, "since", 0, "delete results more recent than this duration (e.g. 1h)")
+	cmd.Flags().StringSliceVarP(&targets, "targets", "t", []string{}, "delete results of these node IDs (comma separated)")
+	cmd.MarkFlagsMutuallyExclusive("from", "since")
+
+	return cmd
+}
+
+func deleteResults(req *proto.DeleteResultsRequest) ([]int64, error) {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewAPIClient(conn)
+
+	ctx, cancel := connection.RequestContext()
+	defer cancel()
+
+	resp, err := client.DeleteResults(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetDeleted(), nil
+}
@@ -11,6 +11,11 @@ func ResultsCmd() *cobra.Command {
 
 	cmd.AddCommand(getCommand())
 	cmd.AddCommand(listCommand())
+	cmd.AddCommand(rmCommand())
+	cmd.AddCommand(statsCommand())
+	cmd.AddCommand(runningCommand())
+	cmd.AddCommand(pendingCommand())
+	cmd.AddCommand(cancelPendingCommand())
 
 	return cmd
 }
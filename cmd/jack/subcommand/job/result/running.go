@@ -0,0 +1,90 @@
+package result
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+func runningCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "running [plugin.task]",
+		Short: "list the tasks running on the nodes, the longest running first",
+		Long: `List the tasks running on the nodes, the longest running first, optionally only the ones of a task.
+
+The tasks whose timeout expired on the manager are listed as orphaned once reported by their node, if running for
+longer than a minute.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			task := ""
+			if len(args) > 0 {
+				task = args[0]
+			}
+			resp, err := runningTasks(task)
+			if err != nil {
+				connection.Exit(err)
+			}
+
+			if option.GetJSONFormat() {
+				result, err := serializer.JSON.MarshalIndent(resp, "", "   ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "failed to serialize response in JSON: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(result))
+				return
+			}
+
+			style.PrettyPrint(prettyRunningSprint(resp))
+		},
+	}
+
+	return cmd
+}
+
+func runningTasks(task string) (*proto.RunningTasksResponse, error) {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewAPIClient(conn)
+
+	ctxReq, cancel := connection.RequestContext()
+	defer cancel()
+
+	resp, err := client.RunningTasks(ctxReq, &proto.RunningTasksRequest{Task: task})
+	if err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+	return resp, nil
+}
+
+func prettyRunningSprint(resp *proto.RunningTasksResponse) string {
+	if len(resp.GetTasks()) == 0 {
+		return style.Item(style.RenderUnknown("no running task"))
+	}
+
+	out := ""
+	for _, t := range resp.GetTasks() {
+		elapsed := (time.Duration(t.GetElapsed()) * time.Millisecond).Round(time.Second)
+		line := fmt.Sprintf("%s %s %s for %s", style.Emph(t.GetNode()+":"), t.GetTask(), style.RenderID(fmt.Sprintf("%d", t.GetId())), elapsed)
+		if t.GroupID != nil {
+			line += fmt.Sprintf(" (group %d)", t.GetGroupID())
+		}
+		if t.GetOrphaned() {
+			line += " " + style.RenderError("orphaned")
+		}
+		out += style.Item(line)
+	}
+	return out
+}
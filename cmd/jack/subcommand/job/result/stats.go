@@ -0,0 +1,146 @@
+package result
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+func statsCommand() *cobra.Command {
+	targets := []string{}
+	metadata := map[string]string{}
+	fromStr := ""
+	toStr := ""
+	since := time.Duration(0)
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "count the results by status, in total and by plugin",
+		Long: `Count the results by status (success, error, timeout, internal error), in total and by plugin, and their
+failure rate, optionally over a time window: jack results stats --since 1h --meta build=1234`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			req := &proto.ResultsStatsRequest{Targets: targets, Metadata: metadata}
+			if fromStr != "" {
+				t, err := parseTimeString(fromStr)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid from-date format: %s\n", err)
+					os.Exit(1)
+				}
+				fromDate := t.UnixNano()
+				req.FromDate = &fromDate
+			}
+			if since > 0 {
+				fromDate := time.Now().Add(-since).UnixNano()
+				req.FromDate = &fromDate
+			}
+			if toStr != "" {
+				t, err := parseTimeString(toStr)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Invalid to-date format: %s\n", err)
+					os.Exit(1)
+				}
+				toDate := t.UnixNano()
+				req.ToDate = &toDate
+			}
+
+			resp, err := resultsStats(req)
+			if err != nil {
+				connection.Exit(err)
+			}
+
+			if option.GetJSONFormat() {
+				result, err := serializer.JSON.MarshalIndent(resp, "", "   ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "failed to serialize response in JSON: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(result))
+				return
+			}
+
+			style.PrettyPrint(prettyStatsSprint(resp))
+		},
+	}
+	cmd.Flags().StringVar(&fromStr, "from", "", "count results from this date (format: 2006-01-01 or 2006-01-01 15:04:05)")
+	cmd.Flags().StringVar(&toStr, "to", "", "count results up to this date (format: 2006-01-01 or 2006-01-01 15:04:05)")
+	cmd.Flags().DurationVar(&since, "since", 0, "count results more recent than this duration (e.g. 1h)")
+	cmd.Flags().StringSliceVarP(&targets, "targets", "t", []string{}, "count results of these node IDs (comma separated)")
+	cmd.Flags().StringToStringVar(&metadata, "meta", nil, "count results by metadata of the request, e.g. --meta build=1234 (repeatable)")
+	cmd.MarkFlagsMutuallyExclusive("from", "since")
+
+	return cmd
+}
+
+func resultsStats(req *proto.ResultsStatsRequest) (*proto.ResultsStatsResponse, error) {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewAPIClient(conn)
+
+	ctx, cancel := connection.RequestContext()
+	defer cancel()
+
+	resp, err := client.ResultsStats(ctx, req)
+	if err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+	return resp, nil
+}
+
+// countsSprint renders the counts of a status line, the failure rate first.
+func countsSprint(counts *proto.ResultsCounts) string {
+	rate := fmt.Sprintf("%.1f%% failed", 100*counts.FailureRate())
+	if counts.GetSuccess() < counts.GetTotal() {
+		rate = style.RenderError(rate)
+	} else {
+		rate = style.RenderSuccess(rate)
+	}
+
+	parts := []string{fmt.Sprintf("%d result(s)", counts.GetTotal()), rate, fmt.Sprintf("%d success", counts.GetSuccess())}
+	for _, c := range []struct {
+		name  string
+		count int64
+	}{
+		{"error", counts.GetError()},
+		{"timeout", counts.GetTimeout()},
+		{"internal error", counts.GetInternalError()},
+		{"unknown", counts.GetUnknown()},
+	} {
+		if c.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.count, c.name))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func prettyStatsSprint(resp *proto.ResultsStatsResponse) string {
+	if resp.GetAll().GetTotal() == 0 {
+		return style.Item(style.RenderUnknown("no result"))
+	}
+
+	out := style.Title("Results") + style.Item(countsSprint(resp.GetAll()))
+	out += style.Title("By plugin")
+	for _, plugin := range slices.Sorted(maps.Keys(resp.GetPlugins())) {
+		name := plugin
+		if name == "" {
+			name = "(unknown request)"
+		}
+		out += style.Item(fmt.Sprintf("%s %s", style.Emph(name+":"), countsSprint(resp.GetPlugins()[plugin])))
+	}
+	return out
+}
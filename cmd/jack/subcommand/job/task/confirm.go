@@ -0,0 +1,90 @@
+package task
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/config"
+)
+
+// ConfirmConfig sets the runs confirmed before being sent: the runs targeting many nodes, and the runs of
+// destructive tasks.
+type ConfirmConfig struct {
+	Threshold   int      `yaml:"threshold"`   // Targeted nodes above which a run is confirmed, 0 disables it.
+	Destructive []string `yaml:"destructive"` // Glob patterns of the destructive tasks (plugin.task), always confirmed.
+}
+
+// reason returns why the run of the task on the nodes must be confirmed, empty if it must not.
+func (c ConfirmConfig) reason(task string, nodes int) string {
+	for _, pattern := range c.Destructive {
+		if ok, _ := path.Match(pattern, task); ok {
+			return fmt.Sprintf("%s is destructive", task)
+		}
+	}
+	if c.Threshold > 0 && nodes > c.Threshold {
+		return fmt.Sprintf("more than %d nodes are targeted", c.Threshold)
+	}
+	return ""
+}
+
+// confirmRun asks the user to confirm the run if the configuration requires it, unless yes is set.
+//
+// resolve returns the nodes targeted by the run, it is only called if a confirmation may be required. The answer
+// is read from in, nil if the user cannot answer (e.g. stdin is not a terminal): the run is refused then.
+func confirmRun(cfg ConfirmConfig, yes bool, run taskRun, resolve func() (map[string]bool, error), in io.Reader, out io.Writer) error {
+	if yes || (cfg.Threshold <= 0 && len(cfg.Destructive) == 0) {
+		return nil
+	}
+
+	nodes, err := resolve()
+	if err != nil {
+		return err
+	}
+	reason := cfg.reason(run.task, len(nodes))
+	if reason == "" {
+		return nil
+	}
+	if in == nil {
+		return fmt.Errorf("confirmation required (%s): run with --yes to skip it", reason)
+	}
+
+	ids := slices.Sorted(maps.Keys(nodes))
+	sample := strings.Join(ids[:min(len(ids), config.CLIConfirmSample)], ", ")
+	if len(ids) > config.CLIConfirmSample {
+		sample += ", ..."
+	}
+	fmt.Fprintf(out, "%s: run %s on %d node(s) (%s)? [y/N] ", reason, run.task, len(ids), sample)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read the confirmation: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return errors.New("run cancelled")
+	}
+}
+
+// confirmTerminalRun asks the user to confirm the run on the terminal, see confirmRun. The run is refused if it must
+// be confirmed while stdin is not a terminal.
+func confirmTerminalRun(cfg ConfirmConfig, yes bool, run taskRun) error {
+	var stdin io.Reader
+	if style.IsTerminal(os.Stdin) {
+		stdin = os.Stdin
+	}
+	resolve := func() (map[string]bool, error) {
+		_, nodes, err := resolveNodes(run)
+		return nodes, err
+	}
+	return confirmRun(cfg, yes, run, resolve, stdin, os.Stderr)
+}
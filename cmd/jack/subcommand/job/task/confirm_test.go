@@ -0,0 +1,113 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+// testNodes returns n connected nodes, web-01 to web-n.
+func testNodes(n int) map[string]bool {
+	nodes := make(map[string]bool, n)
+	for i := range n {
+		nodes[fmt.Sprintf("web-%02d", i+1)] = true
+	}
+	return nodes
+}
+
+func TestConfirmReason(t *testing.T) {
+	cfg := ConfirmConfig{Threshold: 10, Destructive: []string{"pkg.remove", "disk.*"}}
+
+	tests := []struct {
+		task    string
+		nodes   int
+		confirm bool
+	}{
+		{task: "cmd.run", nodes: 10},
+		{task: "cmd.run", nodes: 11, confirm: true},
+		{task: "pkg.remove", nodes: 1, confirm: true},
+		{task: "disk.format", nodes: 1, confirm: true},
+		{task: "pkg.install", nodes: 1},
+	}
+	for _, tt := range tests {
+		if got := cfg.reason(tt.task, tt.nodes); (got != "") != tt.confirm {
+			t.Errorf("reason(%s, %d) = %q, want confirmation: %t", tt.task, tt.nodes, got, tt.confirm)
+		}
+	}
+
+	if got := (ConfirmConfig{}).reason("cmd.run", 1000); got != "" {
+		t.Errorf("a zero threshold must disable the confirmation, got %q", got)
+	}
+}
+
+func TestConfirmRun(t *testing.T) {
+	cfg := ConfirmConfig{Threshold: 3, Destructive: []string{"pkg.remove"}}
+
+	tests := []struct {
+		name    string
+		task    string
+		nodes   int
+		yes     bool
+		answer  string // No terminal if empty.
+		prompt  bool
+		wantErr bool
+	}{
+		{name: "below threshold", task: "cmd.run", nodes: 3},
+		{name: "confirmed", task: "cmd.run", nodes: 8, answer: "y\n", prompt: true},
+		{name: "declined", task: "cmd.run", nodes: 8, answer: "n\n", prompt: true, wantErr: true},
+		{name: "no answer", task: "cmd.run", nodes: 8, answer: "\n", prompt: true, wantErr: true},
+		{name: "destructive", task: "pkg.remove", nodes: 1, answer: "yes\n", prompt: true},
+		{name: "no terminal", task: "pkg.remove", nodes: 1, wantErr: true},
+		{name: "yes", task: "pkg.remove", nodes: 8, yes: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved := false
+			resolve := func() (map[string]bool, error) {
+				resolved = true
+				return testNodes(tt.nodes), nil
+			}
+			var out strings.Builder
+			run := taskRun{targets: tg("*", proto.TargetMode_GLOB), task: tt.task}
+
+			var err error
+			if tt.answer != "" {
+				err = confirmRun(cfg, tt.yes, run, resolve, strings.NewReader(tt.answer), &out)
+			} else {
+				err = confirmRun(cfg, tt.yes, run, resolve, nil, &out)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if (out.Len() > 0) != tt.prompt {
+				t.Errorf("unexpected prompt: %q", out.String())
+			}
+			if tt.yes && resolved {
+				t.Error("--yes must not resolve the targets")
+			}
+		})
+	}
+}
+
+func TestConfirmRunPrompt(t *testing.T) {
+	var out strings.Builder
+	run := taskRun{targets: tg("web-*", proto.TargetMode_GLOB), task: "cmd.run"}
+	resolve := func() (map[string]bool, error) { return testNodes(8), nil }
+
+	if err := confirmRun(ConfirmConfig{Threshold: 3}, false, run, resolve, strings.NewReader("y\n"), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "more than 3 nodes are targeted: run cmd.run on 8 node(s) (web-01, web-02, web-03, web-04, web-05, ...)? [y/N] "
+	if out.String() != want {
+		t.Errorf("unexpected prompt:\n got: %q\nwant: %q", out.String(), want)
+	}
+
+	failing := func() (map[string]bool, error) { return nil, errors.New("failed to connect to the manager") }
+	if err := confirmRun(ConfirmConfig{Threshold: 3}, false, run, failing, strings.NewReader("y\n"), &out); err == nil {
+		t.Error("expected the error of the resolution")
+	}
+}
@@ -0,0 +1,105 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+// exitMixedErrors is the exit code of jack run if the responses failed with different internal errors.
+const exitMixedErrors = 2
+
+// internalError describes an internal error of the task responses.
+type internalError struct {
+	message  string
+	exitCode int // Exit code of jack run if all the failed responses have this error.
+}
+
+// internalErrors documents the semantics of each internal error, and maps it to the exit code of jack run so that
+// automation can branch on the cause of a failure.
+var internalErrors = map[proto.InternalError]internalError{
+	proto.InternalError_OK:                {"success", 0},
+	proto.InternalError_TIMEOUT:           {"the task did not start before its timeout, waiting for a slot or its lock", 10},
+	proto.InternalError_STARTED_TIMEOUT:   {"the task is still running at its timeout, its result will be stored by the manager", 11},
+	proto.InternalError_BUSY_QUEUE:        {"the node is busy, the task was not queued", 12},
+	proto.InternalError_FULL_QUEUE:        {"the queue of the node is full, the task was not queued", 13},
+	proto.InternalError_UNKNOWN_TASK:      {"the task is unknown to the node", 14},
+	proto.InternalError_MODULE_ERROR:      {"the task failed", 15},
+	proto.InternalError_DISCONNECTING:     {"the node is disconnecting or shutting down, the task was not run to completion", 16},
+	proto.InternalError_DISCONNECTED:      {"the node is disconnected, the task was not sent", 17},
+	proto.InternalError_UNKNOWN_ERROR:     {"unexpected error", 18},
+	proto.InternalError_MODULE_PANIC:      {"the task panicked", 19},
+	proto.InternalError_DOWNSTREAM_ERROR:  {"the syndic failed to dispatch the task to its nodes", 20},
+	proto.InternalError_NODE_UNRESPONSIVE: {"no response from the node within the timeout of the task", 21},
+	proto.InternalError_SKIPPED:           {"the task was not sent, the run stopped before the node", 22},
+	proto.InternalError_CANCELLED:         {"the run stopped waiting for the node at a failure, its result will be stored by the manager", 23},
+}
+
+// describe returns the message and the exit code of an internal error, the ones of UNKNOWN_ERROR if it is not
+// known by jack, e.g. sent by a newer manager.
+func describe(e proto.InternalError) internalError {
+	if d, ok := internalErrors[e]; ok {
+		return d
+	}
+	return internalErrors[proto.InternalError_UNKNOWN_ERROR]
+}
+
+// exitCodesHelp lists the exit codes of jack run, by internal error.
+func exitCodesHelp() string {
+	var sb strings.Builder
+	for _, e := range slices.Sorted(maps.Keys(internalErrors)) {
+		d := internalErrors[e]
+		if d.exitCode == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "  %d  %s: %s\n", d.exitCode, e, d.message)
+	}
+	fmt.Fprintf(&sb, "  %d   several internal errors\n", exitMixedErrors)
+	fmt.Fprintf(&sb, "  %d   the manager is not running\n", connection.ExitManagerNotRunning)
+	fmt.Fprintf(&sb, "  %d   permission denied on the manager socket\n", connection.ExitPermissionDenied)
+	fmt.Fprintf(&sb, "  %d   the manager is unreachable", connection.ExitUnreachable)
+	return sb.String()
+}
+
+// exitStatus is the error of a run whose responses failed with an internal error, jack exits with its code.
+type exitStatus int
+
+func (e exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// responsesStatus returns the exit status of the responses of a run: nil if none failed with an internal error, the
+// exit code of their internal error if they share it, exitMixedErrors otherwise.
+func responsesStatus(responses map[string]*proto.TaskResponse) error {
+	code := 0
+	for _, resp := range responses {
+		c := describe(resp.GetInternalError()).exitCode
+		switch {
+		case c == 0:
+		case code == 0:
+			code = c
+		case code != c:
+			code = exitMixedErrors
+		}
+	}
+	if code == 0 {
+		return nil
+	}
+	return exitStatus(code)
+}
+
+// exitWithError exits with the exit status of the failed responses of a run, or prints the error and exits with the
+// code of the connection failure, 1 for the other errors.
+func exitWithError(err error) {
+	var status exitStatus
+	if errors.As(err, &status) {
+		os.Exit(int(status))
+	}
+	connection.Exit(err)
+}
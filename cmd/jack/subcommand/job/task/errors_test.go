@@ -0,0 +1,72 @@
+package task
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+func TestInternalErrors(t *testing.T) {
+	codes := make(map[int]proto.InternalError)
+	for v := range proto.InternalError_name {
+		e := proto.InternalError(v)
+		d, ok := internalErrors[e]
+		if !ok {
+			t.Errorf("%s: no message nor exit code", e)
+			continue
+		}
+		if d.message == "" {
+			t.Errorf("%s: empty message", e)
+		}
+		if e == proto.InternalError_OK {
+			if d.exitCode != 0 {
+				t.Errorf("OK: expected exit code 0, got %d", d.exitCode)
+			}
+			continue
+		}
+		if d.exitCode <= exitMixedErrors {
+			t.Errorf("%s: exit code %d reserved", e, d.exitCode)
+		}
+		if other, ok := codes[d.exitCode]; ok {
+			t.Errorf("%s: exit code %d already used by %s", e, d.exitCode, other)
+		}
+		codes[d.exitCode] = e
+	}
+
+	if got := describe(proto.InternalError(1000)); got != internalErrors[proto.InternalError_UNKNOWN_ERROR] {
+		t.Errorf("expected an unknown internal error to be described as UNKNOWN_ERROR, got %v", got)
+	}
+}
+
+func TestResponsesStatus(t *testing.T) {
+	ok := &proto.TaskResponse{}
+	timeout := &proto.TaskResponse{InternalError: proto.InternalError_TIMEOUT}
+	panicked := &proto.TaskResponse{InternalError: proto.InternalError_MODULE_PANIC}
+
+	tests := []struct {
+		name      string
+		responses map[string]*proto.TaskResponse
+		want      int
+	}{
+		{"no response", nil, 0},
+		{"success", map[string]*proto.TaskResponse{"n1": ok, "n2": ok}, 0},
+		{"same error", map[string]*proto.TaskResponse{"n1": ok, "n2": timeout, "n3": timeout}, internalErrors[proto.InternalError_TIMEOUT].exitCode},
+		{"mixed errors", map[string]*proto.TaskResponse{"n1": timeout, "n2": panicked}, exitMixedErrors},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := responsesStatus(tt.responses)
+			if tt.want == 0 {
+				if err != nil {
+					t.Errorf("expected no exit status, got %v", err)
+				}
+				return
+			}
+			var status exitStatus
+			if !errors.As(err, &status) || int(status) != tt.want {
+				t.Errorf("expected exit status %d, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package task
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+// HistoryConfig is the history section of the jack configuration file:
+//
+//	history:
+//	  disabled: false
+//	  file: ~/.config/jackadi/history.jsonl
+//	  size: 100
+//
+// The runs are recorded as sent, nothing is redacted: the history must be disabled on the workstations where
+// the arguments may contain secrets.
+type HistoryConfig struct {
+	Disabled bool   `yaml:"disabled"`
+	File     string `yaml:"file"` // jackadi/history.jsonl in the user configuration directory if empty, ~/ is the home directory.
+	Size     int    `yaml:"size"` // Number of runs kept, the oldest are removed first.
+}
+
+// HistoryEntry is a run recorded in the history, one JSON object per line of the history file.
+type HistoryEntry struct {
+	Time        time.Time         `json:"time"`
+	Targets     []TargetPreset    `json:"targets"`
+	Excludes    []TargetPreset    `json:"excludes,omitempty"`
+	Syndics     []string          `json:"syndics,omitempty"`
+	Task        string            `json:"task"`
+	Args        []string          `json:"args,omitempty"`
+	LockMode    string            `json:"lockMode"`
+	Priority    string            `json:"priority,omitempty"`
+	FailFast    bool              `json:"failFast,omitempty"`
+	Rolling     bool              `json:"rolling,omitempty"`
+	MaxFailures int               `json:"maxFailures,omitempty"`
+	Timeout     int               `json:"timeout"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Notify      string            `json:"notify,omitempty"`
+	Outcome     HistoryOutcome    `json:"outcome"`
+}
+
+type HistoryOutcome struct {
+	Nodes  int    `json:"nodes"`           // Number of nodes which responded.
+	Failed int    `json:"failed"`          // Number of nodes which responded with an error.
+	Error  string `json:"error,omitempty"` // The run failed as a whole, e.g. it could not be sent.
+}
+
+func (h HistoryConfig) path() string {
+	if rel, ok := strings.CutPrefix(h.File, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		return filepath.Join(home, rel)
+	}
+	if h.File != "" {
+		return h.File
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, config.CLIHistoryFile)
+}
+
+// record appends the entry to the history, unless disabled.
+func (h HistoryConfig) record(entry HistoryEntry) error {
+	if h.Disabled {
+		return nil
+	}
+	file := h.path()
+	if file == "" {
+		return errors.New("no user configuration directory")
+	}
+	return appendHistory(file, h.Size, entry)
+}
+
+// newHistoryEntry returns the history entry of a run, err being the error of the run.
+func newHistoryEntry(at time.Time, run taskRun, opts runOptions, out *proto.FwdResponse, err error) HistoryEntry {
+	entry := HistoryEntry{
+		Time:        at,
+		Targets:     historyTargets(run.targets),
+		Excludes:    historyTargets(run.excludes),
+		Syndics:     run.syndics,
+		Task:        run.task,
+		Args:        run.args,
+		LockMode:    opts.lockMode.String(),
+		Priority:    opts.priority.String(),
+		FailFast:    opts.failFast,
+		Rolling:     opts.rolling,
+		MaxFailures: opts.maxFailures,
+		Timeout:     opts.timeout,
+		Metadata:    opts.metadata,
+		Notify:      opts.notifyURL,
+	}
+	if err != nil {
+		entry.Outcome.Error = status.Convert(err).Message()
+		return entry
+	}
+
+	for _, res := range out.GetResponses() {
+		entry.Outcome.Nodes++
+		if database.ResultStatus(res) != "success" {
+			entry.Outcome.Failed++
+		}
+	}
+	return entry
+}
+
+func historyTargets(targets []*proto.Target) []TargetPreset {
+	if len(targets) == 0 {
+		return nil
+	}
+	list := make([]TargetPreset, 0, len(targets))
+	for _, t := range targets {
+		list = append(list, TargetPreset{Target: t.GetTarget(), Mode: strings.ToLower(t.GetMode().String())})
+	}
+	return list
+}
+
+// taskRun returns the run and the options of the entry, to run it again.
+func (e HistoryEntry) taskRun() (taskRun, runOptions, error) {
+	run := taskRun{syndics: e.Syndics, task: e.Task, args: e.Args}
+	for _, list := range []struct {
+		from []TargetPreset
+		to   *[]*proto.Target
+	}{{e.Targets, &run.targets}, {e.Excludes, &run.excludes}} {
+		for _, t := range list.from {
+			mode, err := parseTargetMode(t.Mode)
+			if err != nil {
+				return run, runOptions{}, err
+			}
+			*list.to = append(*list.to, &proto.Target{Target: t.Target, Mode: mode})
+		}
+	}
+	if len(run.targets) == 0 || run.task == "" {
+		return run, runOptions{}, errors.New("incomplete history entry: no target or no task")
+	}
+
+	lockMode, ok := proto.LockMode_value[e.LockMode]
+	if !ok {
+		return run, runOptions{}, fmt.Errorf("unknown lock mode '%s'", e.LockMode)
+	}
+	priority := proto.Priority_PRIORITY_NORMAL
+	if e.Priority != "" { // recorded before the priorities
+		value, ok := proto.Priority_value[e.Priority]
+		if !ok {
+			return run, runOptions{}, fmt.Errorf("unknown priority '%s'", e.Priority)
+		}
+		priority = proto.Priority(value)
+	}
+	opts := runOptions{
+		lockMode:    proto.LockMode(lockMode),
+		priority:    priority,
+		failFast:    e.FailFast,
+		rolling:     e.Rolling,
+		maxFailures: e.MaxFailures,
+		timeout:     e.Timeout,
+		metadata:    e.Metadata,
+		notifyURL:   e.Notify,
+	}
+	return run, opts, nil
+}
+
+// readHistory returns the entries of the history file, the oldest first, none if it does not exist.
+func readHistory(file string) ([]HistoryEntry, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", file, err)
+	}
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1) // a line can be as long as the arguments of the run
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse '%s' line %d: %w", file, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", file, err)
+	}
+	return entries, nil
+}
+
+// appendHistory adds the entry to the history file, keeping the size last entries.
+//
+// The file is replaced atomically and readable only by its owner.
+func appendHistory(file string, size int, entry HistoryEntry) error {
+	entries, err := readHistory(file)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+	if len(entries) > size {
+		entries = entries[len(entries)-size:]
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to serialize the history: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return fmt.Errorf("failed to create the history directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write the history: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write the history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("failed to write the history: %w", err)
+	}
+	return nil
+}
+
+// historyEntryAt returns the entry numbered n in the listing, starting from 1.
+func historyEntryAt(entries []HistoryEntry, n int) (HistoryEntry, error) {
+	if n < 1 || n > len(entries) {
+		return HistoryEntry{}, fmt.Errorf("no run #%d in the history (%d runs)", n, len(entries))
+	}
+	return entries[n-1], nil
+}
+
+func formatHistoryEntry(n int, e HistoryEntry) string {
+	run, _, err := e.taskRun()
+	targets := "invalid entry"
+	if err == nil {
+		req := &proto.TaskRequest{Targets: run.targets, Excludes: run.excludes}
+		viaSyndics(req, run.syndics)
+		targets = req.TargetsString()
+	}
+
+	var statusSymbol, outcome string
+	switch {
+	case e.Outcome.Error != "":
+		statusSymbol = style.RenderError("✗")
+		outcome = e.Outcome.Error
+	case e.Outcome.Failed > 0:
+		statusSymbol = style.RenderError("✗")
+		outcome = fmt.Sprintf("%d node(s), %d failed", e.Outcome.Nodes, e.Outcome.Failed)
+	default:
+		statusSymbol = style.RenderSuccess("✓")
+		outcome = fmt.Sprintf("%d node(s)", e.Outcome.Nodes)
+	}
+
+	command := strings.Join(append([]string{e.Task}, e.Args...), " ")
+	return fmt.Sprintf("[%s] %s - %s - %s\n    %s %s\n\n", statusSymbol, style.RenderID(strconv.Itoa(n)),
+		e.Time.Local().Format(time.DateTime), outcome, targets, command)
+}
+
+func HistoryCommand() *cobra.Command {
+	rerun := 0
+	limit := 20
+	yes := false
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List or run again the runs of this workstation",
+		Long: `List or run again the runs of this workstation, the latest last.
+
+The history is local to jack, distinct from the results stored by the manager. It is configured in the history
+section of the jack configuration file ($JACK_CONFIG or ~/.config/` + config.CLIConfigFile + `), where it can be
+disabled. Nothing is redacted from the recorded arguments. A run of the history is run again after the same
+confirmation as jack run, skipped with --yes.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadJackConfig(jackConfigFile())
+			if err != nil {
+				fmt.Fprintln(os.Stderr, style.RenderError(err.Error()))
+				os.Exit(1)
+			}
+			file := cfg.History.path()
+			if file == "" {
+				fmt.Fprintln(os.Stderr, style.RenderError("no user configuration directory"))
+				os.Exit(1)
+			}
+			entries, err := readHistory(file)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, style.RenderError(err.Error()))
+				os.Exit(1)
+			}
+
+			if cmd.Flags().Changed("rerun") {
+				if err := rerunHistory(entries, rerun, cfg, yes); err != nil {
+					exitWithError(err)
+				}
+				return
+			}
+
+			first := max(len(entries)-limit, 0)
+			if option.GetJSONFormat() {
+				result, err := json.MarshalIndent(entries[first:], "", "  ")
+				if err != nil {
+					fmt.Fprintln(os.Stderr, style.RenderError(fmt.Sprintf("failed to serialize response in JSON: %s", err)))
+					os.Exit(1)
+				}
+				fmt.Println(string(result))
+				return
+			}
+
+			if len(entries) == 0 {
+				fmt.Println("The history is empty.")
+				return
+			}
+			var sb strings.Builder
+			for i := first; i < len(entries); i++ {
+				sb.WriteString(formatHistoryEntry(i+1, entries[i]))
+			}
+			fmt.Print(sb.String())
+		},
+		GroupID: "operations",
+	}
+
+	cmd.Flags().IntVar(&rerun, "rerun", 0, "run again the run numbered N in the history")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "run again without confirmation, even a destructive task or on many nodes")
+	cmd.Flags().IntVar(&limit, "limit", 20, "number of runs listed, the latest ones")
+
+	return cmd
+}
+
+// rerunHistory runs again the entry numbered n, recording it as a new run. It is confirmed like a run of jack run.
+func rerunHistory(entries []HistoryEntry, n int, cfg jackConfig, yes bool) error {
+	entry, err := historyEntryAt(entries, n)
+	if err != nil {
+		return err
+	}
+	run, opts, err := entry.taskRun()
+	if err != nil {
+		return fmt.Errorf("cannot run #%d again: %w", n, err)
+	}
+	if err := confirmTerminalRun(cfg.Confirm, yes, run); err != nil {
+		return err
+	}
+	return execute(run, opts, cfg.History)
+}
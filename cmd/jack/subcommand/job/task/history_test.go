@@ -0,0 +1,186 @@
+package task
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+func testHistoryEntry(task string) HistoryEntry {
+	run := taskRun{
+		targets:  []*proto.Target{{Target: "web-*", Mode: proto.TargetMode_GLOB}, {Target: "env == 'prod'", Mode: proto.TargetMode_QUERY}},
+		excludes: tg("web-canary", proto.TargetMode_EXACT),
+		task:     task,
+		args:     []string{"uptime", "password=secret"},
+	}
+	opts := runOptions{lockMode: proto.LockMode_WRITE, priority: proto.Priority_PRIORITY_HIGH, failFast: true, rolling: true, maxFailures: 2, timeout: 60, metadata: map[string]string{"ticket": "INC-1"}}
+	out := &proto.FwdResponse{Responses: map[string]*proto.TaskResponse{
+		"web-1": {},
+		"web-2": {Error: "exit status 1", Retcode: 1},
+	}}
+	return newHistoryEntry(time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC), run, opts, out, nil)
+}
+
+func TestNewHistoryEntry(t *testing.T) {
+	entry := testHistoryEntry("cmd.run")
+	if entry.Outcome.Nodes != 2 || entry.Outcome.Failed != 1 || entry.Outcome.Error != "" {
+		t.Errorf("unexpected outcome: %+v", entry.Outcome)
+	}
+	if !slices.Contains(entry.Args, "password=secret") {
+		t.Errorf("the arguments must be recorded as sent, got %v", entry.Args)
+	}
+
+	failed := newHistoryEntry(time.Now(), taskRun{targets: tg("*", proto.TargetMode_GLOB), task: "cmd.run"}, runOptions{}, nil, errors.New("not sent"))
+	if failed.Outcome.Error != "not sent" {
+		t.Errorf("expected the error of the run, got %+v", failed.Outcome)
+	}
+}
+
+func TestAppendHistory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "jackadi", "history.jsonl")
+
+	for _, task := range []string{"cmd.run", "git.pull", "pkg.install"} {
+		if err := appendHistory(file, 2, testHistoryEntry(task)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	entries, err := readHistory(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tasks := []string{}
+	for _, e := range entries {
+		tasks = append(tasks, e.Task)
+	}
+	if !slices.Equal(tasks, []string{"git.pull", "pkg.install"}) {
+		t.Errorf("expected the 2 latest runs, got %v", tasks)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the history to be readable only by its owner, got %v", info.Mode().Perm())
+	}
+}
+
+func TestHistoryDisabled(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history.jsonl")
+	history := HistoryConfig{Disabled: true, File: file, Size: 10}
+
+	if err := history.record(testHistoryEntry("cmd.run")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("expected no history file, got: %v", err)
+	}
+}
+
+func TestHistoryPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := map[string]string{
+		"~/jack/history.jsonl":    filepath.Join(home, "jack", "history.jsonl"),
+		"/var/jack/history.jsonl": "/var/jack/history.jsonl",
+		"~other/history.jsonl":    "~other/history.jsonl",
+		"history/~/history.jsonl": "history/~/history.jsonl",
+	}
+	for file, want := range tests {
+		if got := (HistoryConfig{File: file}).path(); got != want {
+			t.Errorf("path of %q: expected %q, got %q", file, want, got)
+		}
+	}
+}
+
+func TestReadHistory(t *testing.T) {
+	entries, err := readHistory(filepath.Join(t.TempDir(), "missing.jsonl"))
+	if err != nil || len(entries) != 0 {
+		t.Errorf("a missing history must be empty, got %v, %v", entries, err)
+	}
+
+	file := filepath.Join(t.TempDir(), "history.jsonl")
+	if err := os.WriteFile(file, []byte("{\"task\": \"cmd.run\"}\nnot json\n"), 0600); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+	if _, err := readHistory(file); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected a parsing error on line 2, got: %v", err)
+	}
+}
+
+func TestFormatHistoryEntry(t *testing.T) {
+	got := formatHistoryEntry(3, testHistoryEntry("cmd.run"))
+	for _, want := range []string{
+		"3",
+		"2 node(s), 1 failed",
+		"glob:web-* + query:env == 'prod' - exact:web-canary",
+		"cmd.run uptime password=secret",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestHistoryRerun(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history.jsonl")
+	for _, task := range []string{"cmd.run", "git.pull"} {
+		if err := appendHistory(file, 10, testHistoryEntry(task)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	entries, err := readHistory(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, n := range []int{0, 3} {
+		if _, err := historyEntryAt(entries, n); err == nil {
+			t.Errorf("expected an error for the run #%d", n)
+		}
+	}
+
+	entry, err := historyEntryAt(entries, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	run, opts, err := entry.taskRun()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := testHistoryEntry("git.pull")
+	if run.task != "git.pull" || !slices.Equal(run.args, want.Args) {
+		t.Errorf("unexpected task: %s %v", run.task, run.args)
+	}
+	if !slices.EqualFunc(run.targets, []*proto.Target{{Target: "web-*", Mode: proto.TargetMode_GLOB}, {Target: "env == 'prod'", Mode: proto.TargetMode_QUERY}}, sameTarget) {
+		t.Errorf("unexpected targets: %v", run.targets)
+	}
+	if !slices.EqualFunc(run.excludes, tg("web-canary", proto.TargetMode_EXACT), sameTarget) {
+		t.Errorf("unexpected exclusions: %v", run.excludes)
+	}
+	if opts.lockMode != proto.LockMode_WRITE || opts.priority != proto.Priority_PRIORITY_HIGH || !opts.failFast || !opts.rolling || opts.maxFailures != 2 || opts.timeout != 60 || opts.metadata["ticket"] != "INC-1" {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+
+	// the entry is sent as the original run
+	req, err := newTaskRequest(run.targets, run.excludes, opts.lockMode, opts.timeout, opts.metadata, run.task, run.args...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.FullTask() != "git.pull" || req.TargetsString() != "glob:web-* + query:env == 'prod' - exact:web-canary" {
+		t.Errorf("unexpected request: %s on %s", req.FullTask(), req.TargetsString())
+	}
+
+	if _, _, err := (HistoryEntry{Targets: []TargetPreset{{Target: "*", Mode: "fuzzy"}}, Task: "cmd.run"}).taskRun(); err == nil {
+		t.Error("expected an error for an unknown target mode")
+	}
+}
@@ -0,0 +1,61 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/goccy/go-yaml"
+	"github.com/jackadi-io/jackadi/internal/config"
+)
+
+// jackConfig is the jack configuration file.
+type jackConfig struct {
+	Presets Presets       `yaml:"presets"`
+	History HistoryConfig `yaml:"history"`
+	Confirm ConfirmConfig `yaml:"confirm"`
+}
+
+// jackConfigFile returns the path of the jack configuration file: $JACK_CONFIG, or jackadi/jack.yaml in the user
+// configuration directory.
+func jackConfigFile() string {
+	if file := os.Getenv("JACK_CONFIG"); file != "" {
+		return file
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, config.CLIConfigFile)
+}
+
+// loadJackConfig reads the jack configuration file, the defaults are used if it does not exist.
+func loadJackConfig(file string) (jackConfig, error) {
+	cfg := jackConfig{
+		History: HistoryConfig{Size: config.CLIHistorySize},
+		Confirm: ConfirmConfig{Threshold: config.CLIConfirmThreshold},
+	}
+	if file == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read '%s': %w", file, err)
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse '%s': %w", file, err)
+	}
+	if cfg.History.Size <= 0 {
+		return cfg, fmt.Errorf("invalid history size in '%s': must be positive", file)
+	}
+	if cfg.Confirm.Threshold < 0 {
+		return cfg, fmt.Errorf("invalid confirmation threshold in '%s': must not be negative", file)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,145 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackadi-io/jackadi/internal/parser"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+// PresetPrefix marks a preset name on the command line, e.g. jack run @web-prod @pull.
+const PresetPrefix = "@"
+
+// Presets are the shortcuts of the jack configuration file:
+//
+//	presets:
+//	  targets:
+//	    web-prod:
+//	      target: "role == 'web' && env == 'prod'"
+//	      mode: query
+//	  tasks:
+//	    pull:
+//	      task: git.pull
+//	      args: ["dir=/srv/app"]
+type Presets struct {
+	Targets map[string]TargetPreset `yaml:"targets"`
+	Tasks   map[string]TaskPreset   `yaml:"tasks"`
+}
+
+type TargetPreset struct {
+	Target string `yaml:"target" json:"target"`
+	Mode   string `yaml:"mode" json:"mode"` // exact, list, glob, regexp or query, glob if empty.
+}
+
+type TaskPreset struct {
+	Task string   `yaml:"task"` // plugin.task
+	Args []string `yaml:"args"` // Default arguments, the options of the command line override them.
+}
+
+// taskRun is what the run command sends.
+type taskRun struct {
+	targets  []*proto.Target
+	excludes []*proto.Target
+	syndics  []string // Glob patterns of the syndics the targets are the nodes of, none for the nodes of the manager.
+	task     string
+	args     []string
+}
+
+// parseTargetMode converts the mode of a target preset.
+func parseTargetMode(mode string) (proto.TargetMode, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "exact":
+		return proto.TargetMode_EXACT, nil
+	case "list":
+		return proto.TargetMode_LIST, nil
+	case "glob", "":
+		return proto.TargetMode_GLOB, nil
+	case "regexp", "regex":
+		return proto.TargetMode_REGEX, nil
+	case "query":
+		return proto.TargetMode_QUERY, nil
+	}
+	return proto.TargetMode_GLOB, fmt.Errorf("unknown target mode '%s': exact, list, glob, regexp or query expected", mode)
+}
+
+// expand replaces the target and the task presets of the run.
+//
+// A target preset defines the targeting mode, it is only expanded from the TARGET argument and cannot be
+// combined with the targeting flags. The arguments of a task preset are merged with the ones of the command
+// line: positional arguments first, and options overridden by the command line.
+func (p Presets) expand(run taskRun, modeFlag bool) (taskRun, error) {
+	targets := make([]*proto.Target, 0, len(run.targets))
+	for _, target := range run.targets {
+		name, ok := strings.CutPrefix(target.GetTarget(), PresetPrefix)
+		if !ok {
+			targets = append(targets, target)
+			continue
+		}
+
+		preset, found := p.Targets[name]
+		if !found {
+			return run, fmt.Errorf("unknown target preset '%s', available: %s", name, presetNames(p.Targets))
+		}
+		if modeFlag {
+			return run, fmt.Errorf("the target preset '%s' defines the targeting mode, it cannot be combined with the targeting flags", name)
+		}
+		mode, err := parseTargetMode(preset.Mode)
+		if err != nil {
+			return run, fmt.Errorf("target preset '%s': %w", name, err)
+		}
+		if preset.Target == "" {
+			return run, fmt.Errorf("target preset '%s': empty target", name)
+		}
+		targets = append(targets, &proto.Target{Target: preset.Target, Mode: mode})
+	}
+	run.targets = targets
+
+	if name, ok := strings.CutPrefix(run.task, PresetPrefix); ok {
+		preset, found := p.Tasks[name]
+		if !found {
+			return run, fmt.Errorf("unknown task preset '%s', available: %s", name, presetNames(p.Tasks))
+		}
+		if preset.Task == "" {
+			return run, fmt.Errorf("task preset '%s': empty task", name)
+		}
+		run.task = preset.Task
+		run.args = mergeArgs(preset.Args, run.args)
+	}
+
+	return run, nil
+}
+
+// mergeArgs returns the positional arguments followed by the options, the ones of args being after the
+// defaults so that they take precedence.
+func mergeArgs(defaults, args []string) []string {
+	var positional, options []string
+	for _, list := range [][]string{defaults, args} {
+		for _, arg := range list {
+			if parser.IsKeyValue(arg) {
+				options = append(options, arg)
+				continue
+			}
+			positional = append(positional, arg)
+		}
+	}
+	return append(positional, options...)
+}
+
+func presetCompletions[T any](presets map[string]T) []string {
+	completions := make([]string, 0, len(presets))
+	for name := range presets {
+		completions = append(completions, PresetPrefix+name)
+	}
+	return completions
+}
+
+func presetNames[T any](presets map[string]T) string {
+	if len(presets) == 0 {
+		return "none"
+	}
+	names := presetCompletions(presets)
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
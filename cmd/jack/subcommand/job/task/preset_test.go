@@ -0,0 +1,166 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+const testPresets = `presets:
+  targets:
+    web-prod:
+      target: "role == 'web' && env == 'prod'"
+      mode: query
+    db:
+      target: "db-1,db-2"
+      mode: list
+    all:
+      target: "*"
+  tasks:
+    pull:
+      task: git.pull
+      args: ["/srv/app", "branch=main"]
+`
+
+// tg returns a single target.
+func tg(target string, mode proto.TargetMode) []*proto.Target {
+	return []*proto.Target{{Target: target, Mode: mode}}
+}
+
+func sameTarget(a, b *proto.Target) bool {
+	return a.GetTarget() == b.GetTarget() && a.GetMode() == b.GetMode()
+}
+
+func loadTestPresets(t *testing.T) Presets {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "jack.yaml")
+	if err := os.WriteFile(file, []byte(testPresets), 0600); err != nil {
+		t.Fatalf("failed to write presets: %v", err)
+	}
+	cfg, err := loadJackConfig(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return cfg.Presets
+}
+
+func TestPresetsExpand(t *testing.T) {
+	presets := loadTestPresets(t)
+
+	tests := []struct {
+		name string
+		run  taskRun
+		want taskRun
+	}{
+		{
+			name: "query target",
+			run:  taskRun{targets: tg("@web-prod", proto.TargetMode_GLOB), task: "cmd.run", args: []string{"uptime"}},
+			want: taskRun{targets: tg("role == 'web' && env == 'prod'", proto.TargetMode_QUERY), task: "cmd.run", args: []string{"uptime"}},
+		},
+		{
+			name: "list target",
+			run:  taskRun{targets: tg("@db", proto.TargetMode_GLOB), task: "health.ping"},
+			want: taskRun{targets: tg("db-1,db-2", proto.TargetMode_LIST), task: "health.ping"},
+		},
+		{
+			name: "default mode",
+			run:  taskRun{targets: tg("@all", proto.TargetMode_GLOB), task: "health.ping"},
+			want: taskRun{targets: tg("*", proto.TargetMode_GLOB), task: "health.ping"},
+		},
+		{
+			name: "task with default args",
+			run:  taskRun{targets: tg("web-*", proto.TargetMode_GLOB), task: "@pull"},
+			want: taskRun{targets: tg("web-*", proto.TargetMode_GLOB), task: "git.pull", args: []string{"/srv/app", "branch=main"}},
+		},
+		{
+			name: "task args merged",
+			run:  taskRun{targets: tg("@web-prod", proto.TargetMode_GLOB), task: "@pull", args: []string{"origin", "branch=dev"}},
+			want: taskRun{targets: tg("role == 'web' && env == 'prod'", proto.TargetMode_QUERY), task: "git.pull", args: []string{"/srv/app", "origin", "branch=main", "branch=dev"}},
+		},
+		{
+			name: "no preset",
+			run:  taskRun{targets: tg("node1", proto.TargetMode_EXACT), task: "cmd.run", args: []string{"ls"}},
+			want: taskRun{targets: tg("node1", proto.TargetMode_EXACT), task: "cmd.run", args: []string{"ls"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := presets.expand(tt.run, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.EqualFunc(got.targets, tt.want.targets, sameTarget) || got.task != tt.want.task || !slices.Equal(got.args, tt.want.args) {
+				t.Errorf("expand() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPresetsExpandArgsRequest(t *testing.T) {
+	presets := loadTestPresets(t)
+
+	run, err := presets.expand(taskRun{targets: tg("@web-prod", proto.TargetMode_GLOB), task: "@pull", args: []string{"branch=dev"}}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req, err := newTaskRequest(run.targets, nil, proto.LockMode_UNSPECIFIED, 10, nil, run.task, run.args...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.GetTargetMode() != proto.TargetMode_QUERY || req.GetPlugin() != "git" || req.GetTask() != "pull" {
+		t.Errorf("unexpected request: %v", req)
+	}
+	if got := req.GetInput().GetOptions().GetFields()["branch"].GetStringValue(); got != "dev" {
+		t.Errorf("expected the command line option to override the preset, got branch=%q", got)
+	}
+	if got := req.GetInput().GetArgs().GetValues(); len(got) != 1 || got[0].GetStringValue() != "/srv/app" {
+		t.Errorf("expected the preset positional argument, got %v", got)
+	}
+}
+
+func TestPresetsExpandErrors(t *testing.T) {
+	presets := loadTestPresets(t)
+
+	tests := []struct {
+		name     string
+		run      taskRun
+		modeFlag bool
+	}{
+		{name: "unknown target", run: taskRun{targets: tg("@unknown", proto.TargetMode_GLOB), task: "cmd.run"}},
+		{name: "unknown task", run: taskRun{targets: tg("node1", proto.TargetMode_GLOB), task: "@unknown"}},
+		{name: "target with mode flag", run: taskRun{targets: tg("@web-prod", proto.TargetMode_GLOB), task: "cmd.run"}, modeFlag: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := presets.expand(tt.run, tt.modeFlag); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	invalid := Presets{Targets: map[string]TargetPreset{"bad": {Target: "*", Mode: "fuzzy"}}}
+	if _, err := invalid.expand(taskRun{targets: tg("@bad", proto.TargetMode_GLOB)}, false); err == nil {
+		t.Error("expected an error for an unknown target mode")
+	}
+}
+
+func TestLoadPresetsMissingFile(t *testing.T) {
+	cfg, err := loadJackConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("a missing configuration must not be an error: %v", err)
+	}
+	if cfg.History.Disabled || cfg.History.Size != config.CLIHistorySize {
+		t.Errorf("unexpected default history configuration: %+v", cfg.History)
+	}
+	if cfg.Confirm.Threshold != config.CLIConfirmThreshold {
+		t.Errorf("unexpected default confirmation threshold: %d", cfg.Confirm.Threshold)
+	}
+	if _, err := cfg.Presets.expand(taskRun{targets: tg("@web-prod", proto.TargetMode_GLOB), task: "cmd.run"}, false); err == nil {
+		t.Error("expected an error for an unknown preset")
+	}
+}
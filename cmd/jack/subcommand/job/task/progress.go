@@ -0,0 +1,113 @@
+package task
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+const progressBarWidth = 30
+
+// progressView renders the latest progress of each node still running the task, redrawn in place, and the
+// nodes done by a rolling run.
+//
+// A nil progressView renders nothing.
+type progressView struct {
+	out     io.Writer
+	nodes   []string // by order of first update
+	percent map[string]int32
+	lines   int // lines drawn by the last render
+
+	rolling bool
+	done    int // Nodes which were sent the task, not the disconnected nor the skipped ones.
+	failed  int
+}
+
+// newProgressView returns a progressView writing to stderr, or nil if stderr is not a terminal.
+func newProgressView(rolling bool) *progressView {
+	if !style.IsTerminal(os.Stderr) {
+		return nil
+	}
+	return &progressView{
+		out:     os.Stderr,
+		percent: make(map[string]int32),
+		rolling: rolling,
+	}
+}
+
+// report updates the progress of a node. A final response removes the node from the view.
+func (v *progressView) report(nd string, resp *proto.TaskResponse) {
+	if v == nil {
+		return
+	}
+
+	if resp.Progress == nil {
+		changed := false
+		ran := resp.GetInternalError() != proto.InternalError_SKIPPED && resp.GetInternalError() != proto.InternalError_DISCONNECTED
+		if v.rolling && ran {
+			v.done++
+			if database.ResultStatus(resp) != "success" {
+				v.failed++
+			}
+			changed = true
+		}
+		if i := slices.Index(v.nodes, nd); i >= 0 {
+			v.nodes = slices.Delete(v.nodes, i, i+1)
+			delete(v.percent, nd)
+			changed = true
+		}
+		if changed {
+			v.render()
+		}
+		return
+	}
+
+	if _, ok := v.percent[nd]; !ok {
+		v.nodes = append(v.nodes, nd)
+	}
+	v.percent[nd] = resp.GetProgress()
+	v.render()
+}
+
+// clear removes the rendered progress from the terminal.
+func (v *progressView) clear() {
+	if v == nil {
+		return
+	}
+	v.nodes = nil
+	v.rolling = false
+	v.render()
+}
+
+func (v *progressView) render() {
+	var sb strings.Builder
+	if v.lines > 0 {
+		fmt.Fprintf(&sb, "\033[%dA", v.lines) // back to the first line of the previous render
+	}
+	sb.WriteString("\033[J") // clear the previous render
+
+	v.lines = len(v.nodes)
+	if v.rolling && v.done > 0 {
+		fmt.Fprintf(&sb, "rolling run: %d node(s) done, %d failed\n", v.done, v.failed)
+		v.lines++
+	}
+	for _, nd := range v.nodes {
+		fmt.Fprintf(&sb, "%s %s\n", progressBar(v.percent[nd]), nd)
+	}
+
+	fmt.Fprint(v.out, sb.String())
+}
+
+// progressBar renders a percentage as a bar, e.g. [#####-----]  50%.
+func progressBar(percent int32) string {
+	percent = min(max(percent, 0), 100)
+	filled := int(percent) * progressBarWidth / 100
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+	return fmt.Sprintf("[%s] %3d%%", style.H2Style.Render(bar), percent)
+}
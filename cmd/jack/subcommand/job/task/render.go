@@ -5,11 +5,13 @@ import (
 	"maps"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/goccy/go-yaml"
 
 	"github.com/jackadi-io/jackadi/cmd/jack/option"
 	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/config"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"github.com/jackadi-io/jackadi/internal/serializer"
 )
@@ -33,6 +35,7 @@ func printTaskResult(responses *proto.FwdResponse) {
 			sb.WriteString(style.InlineBlockTitle("id") + fmt.Sprintf("%d", res.GetId()))
 			sb.WriteString(style.InlineBlockTitle("groupID") + fmt.Sprintf("%d", res.GetGroupID()))
 			sb.WriteString(style.InlineBlockTitle("internal error") + style.ErrorStyle.Render(res.GetInternalError().String()))
+			sb.WriteString(" " + style.Emph("("+describe(res.GetInternalError()).message+")"))
 			if res.GetModuleError() != "" {
 				sb.WriteString(style.Block(res.GetModuleError()))
 			}
@@ -63,8 +66,64 @@ func printTaskResult(responses *proto.FwdResponse) {
 			fmt.Fprintf(&sb, "%d", res.GetRetcode())
 		}
 
+		if wait := time.Duration(res.GetLockWait()) * time.Millisecond; wait >= config.LockWaitThreshold {
+			sb.WriteString(style.InlineBlockTitle("lock wait"))
+			fmt.Fprintf(&sb, "%s (%s)", wait, res.GetLockMode())
+		}
+
 		sb.WriteString("\n")
 	}
 
+	if summary := targetsSummary(responses.GetTargets()); summary != "" {
+		sb.WriteString(style.Subtitle(summary))
+	}
+	if stopped := responses.GetStoppedAt(); stopped != "" {
+		sb.WriteString(style.Subtitle(fmt.Sprintf("run stopped after the failure of %s", stopped)))
+	}
+
 	style.PrettyPrint(sb.String())
 }
+
+// targetsSummary returns the number of nodes matched by the targets of a run, by dispatch status, e.g.
+// "matched 12, dispatched 10, 2 disconnected". It is empty if the manager did not report them.
+func targetsSummary(targets map[string]proto.DispatchStatus) string {
+	if len(targets) == 0 {
+		return ""
+	}
+	count := make(map[proto.DispatchStatus]int)
+	for _, status := range targets {
+		count[status]++
+	}
+
+	summary := fmt.Sprintf("matched %d, dispatched %d", len(targets), count[proto.DispatchStatus_DISPATCH_SENT])
+	if n := count[proto.DispatchStatus_DISPATCH_DISCONNECTED]; n > 0 {
+		summary += fmt.Sprintf(", %d disconnected", n)
+	}
+	if n := count[proto.DispatchStatus_DISPATCH_REJECTED]; n > 0 {
+		summary += fmt.Sprintf(", %d rejected", n)
+	}
+	if n := count[proto.DispatchStatus_DISPATCH_SKIPPED]; n > 0 {
+		summary += fmt.Sprintf(", %d skipped", n)
+	}
+	return summary
+}
+
+// printTargets displays the nodes targeted by a dry run.
+func printTargets(targets string, nodes map[string]bool) {
+	var items strings.Builder
+	ids := maps.Keys(nodes)
+	if option.GetSortOutput() {
+		ids = slices.Values(slices.Sorted(ids))
+	}
+
+	for id := range ids {
+		connectedState := "connected"
+		if !nodes[id] {
+			connectedState = "disconnected"
+		}
+		items.WriteString(style.Item(fmt.Sprintf("%s: %s", id, connectedState)))
+	}
+
+	fmt.Print(style.Subtitle(fmt.Sprintf("%d node(s) targeted by %s (dry run)", len(nodes), targets)))
+	fmt.Print(style.SpacedBlock(items.String()))
+}
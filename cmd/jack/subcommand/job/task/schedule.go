@@ -0,0 +1,72 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// parseAt parses the time of a scheduled run: with a time zone (e.g. 2024-06-01T02:00Z), or in the local time
+// (e.g. 2024-06-01 02:00). The time must be after now.
+func parseAt(at string, now time.Time) (time.Time, error) {
+	var t time.Time
+	var err error
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00"} {
+		if t, err = time.Parse(layout, at); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04"} {
+			if t, err = time.ParseInLocation(layout, at, time.Local); err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --at '%s': 2006-01-02T15:04Z07:00 or 2006-01-02 15:04 expected", at)
+	}
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("invalid --at '%s': the time is passed", at)
+	}
+	return t, nil
+}
+
+// scheduleRun sends the run to the manager, which runs it at the given time.
+func scheduleRun(run taskRun, opts runOptions, at time.Time) error {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the manager: %w", err)
+	}
+	defer conn.Close()
+
+	req, err := runRequest(run, opts)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := connection.RequestContext()
+	defer cancel()
+	pending, err := proto.NewForwarderClient(conn).ScheduleTask(ctx, &proto.ScheduleTaskRequest{Request: req, At: timestamppb.New(at)})
+	if err != nil {
+		return fmt.Errorf("not scheduled: %s", status.Convert(err).Message())
+	}
+
+	if option.GetJSONFormat() {
+		result, err := serializer.JSON.MarshalIndent(pending, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize response in JSON: %w", err)
+		}
+		fmt.Println(string(result))
+		return nil
+	}
+	fmt.Printf("%s scheduled at %s: %s\n", run.task, at.Format(time.RFC3339), style.RenderID(fmt.Sprintf("%d", pending.GetId())))
+	return nil
+}
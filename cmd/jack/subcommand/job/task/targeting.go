@@ -4,27 +4,64 @@ import (
 	"github.com/jackadi-io/jackadi/internal/proto"
 )
 
+// Target is the targeting of the run command, each flag is repeatable and the task is sent to the union of the
+// targets.
 type Target struct {
-	Exact  bool
-	List   bool
-	File   bool
-	Glob   bool
-	Regexp bool
-	Query  bool
+	Exact  []string
+	List   []string
+	File   []string
+	Glob   []string
+	Regexp []string
+	Query  []string
+
+	// The nodes matching the exclusions are removed from the targeted ones.
+	Exclude       []string // Glob patterns.
+	ExcludeList   []string
+	ExcludeRegexp []string
+	ExcludeQuery  []string
+}
+
+// IsSet reports whether a targeting flag is set.
+func (t Target) IsSet() bool {
+	return len(t.Exact)+len(t.List)+len(t.File)+len(t.Glob)+len(t.Regexp)+len(t.Query) > 0
 }
 
-func (t Target) Mode() proto.TargetMode {
-	switch {
-	case t.Exact:
-		return proto.TargetMode_EXACT
-	case t.List, t.File:
-		return proto.TargetMode_LIST
-	case t.Glob:
-		return proto.TargetMode_GLOB
-	case t.Regexp:
-		return proto.TargetMode_REGEX
-	case t.Query:
-		return proto.TargetMode_QUERY
+// Targets returns the targets of the flags, the files being read as lists of nodes.
+func (t Target) Targets() ([]*proto.Target, error) {
+	var targets []*proto.Target
+	add := func(mode proto.TargetMode, exprs ...string) {
+		for _, expr := range exprs {
+			targets = append(targets, &proto.Target{Target: expr, Mode: mode})
+		}
+	}
+
+	add(proto.TargetMode_EXACT, t.Exact...)
+	add(proto.TargetMode_LIST, t.List...)
+	for _, file := range t.File {
+		list, err := targetsFromFile(file)
+		if err != nil {
+			return nil, err
+		}
+		add(proto.TargetMode_LIST, list)
 	}
-	return proto.TargetMode_GLOB
+	add(proto.TargetMode_GLOB, t.Glob...)
+	add(proto.TargetMode_REGEX, t.Regexp...)
+	add(proto.TargetMode_QUERY, t.Query...)
+	return targets, nil
+}
+
+// Excludes returns the exclusions of the flags.
+func (t Target) Excludes() []*proto.Target {
+	var excludes []*proto.Target
+	add := func(mode proto.TargetMode, exprs ...string) {
+		for _, expr := range exprs {
+			excludes = append(excludes, &proto.Target{Target: expr, Mode: mode})
+		}
+	}
+
+	add(proto.TargetMode_GLOB, t.Exclude...)
+	add(proto.TargetMode_LIST, t.ExcludeList...)
+	add(proto.TargetMode_REGEX, t.ExcludeRegexp...)
+	add(proto.TargetMode_QUERY, t.ExcludeQuery...)
+	return excludes
 }
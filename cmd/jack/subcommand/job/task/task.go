@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"slices"
 	"strings"
@@ -17,11 +18,15 @@ import (
 	"github.com/jackadi-io/jackadi/cmd/jack/subcommand/node"
 	"github.com/jackadi-io/jackadi/internal/config"
 	"github.com/jackadi-io/jackadi/internal/helper"
+	"github.com/jackadi-io/jackadi/internal/manager/notification"
 	"github.com/jackadi-io/jackadi/internal/parser"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"github.com/jackadi-io/jackadi/internal/serializer"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -41,22 +46,94 @@ func parseLockMode(lockMode string) proto.LockMode {
 	}
 }
 
+// parsePriority converts a string priority to proto.Priority.
+func parsePriority(priority string) (proto.Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(priority)) {
+	case "high":
+		return proto.Priority_PRIORITY_HIGH, nil
+	case "normal", "":
+		return proto.Priority_PRIORITY_NORMAL, nil
+	case "low":
+		return proto.Priority_PRIORITY_LOW, nil
+	default:
+		return proto.Priority_PRIORITY_NORMAL, fmt.Errorf("unknown priority '%s': high, normal or low expected", priority)
+	}
+}
+
+// checkRolling checks the failures tolerated by a run, only a rolling run tolerating some.
+func checkRolling(rolling bool, maxFailures int) error {
+	if maxFailures < 0 {
+		return fmt.Errorf("invalid --max-failures: positive number expected, got %d", maxFailures)
+	}
+	if maxFailures > 0 && !rolling {
+		return errors.New("--max-failures requires --rolling")
+	}
+	return nil
+}
+
 type proxyResponse struct {
 	*proto.TaskResponse
-	Output string `json:"output"` // in the original TaskResponse, Output is a []byte
+	Output               string `json:"output"` // in the original TaskResponse, Output is a []byte
+	InternalErrorMessage string `jackadi:",omitempty"`
 }
 
 func RunCommand() *cobra.Command {
 	target := Target{}
 	timeout := int(config.TaskTimeout.Seconds())
 	lockMode := "no-lock"
+	priority := "normal"
+	failFast := false
+	rolling := false
+	maxFailures := 0
+	notifyURL := ""
+	metadata := map[string]string{}
+	syndics := []string{}
+	dryRun := false
+	yes := false
+	at := ""
 
 	cmd := &cobra.Command{
-		Use:   "run [ -t | -l | -g | -e | -f ] TARGET PLUGIN:TASK -- ARGS...",
+		Use:   "run { TARGET | -t | -l | -g | -e | -q | -f TARGET... } PLUGIN:TASK -- ARGS...",
 		Short: "Run a task on one or multiple nodes",
+		Long: `Run a task on one or multiple nodes.
+
+The TARGET argument is a Glob pattern. The targeting flags replace it, they are repeatable and the task is
+sent to the union of their nodes: jack run -t web-1 -g 'db-*' -q 'specs.env==prod' cmd.run -- uptime
+
+The queries combine conditions on the ID and the specs of the nodes (id==web-1, id=~'web-*', specs.<path>==value,
+specs.<path>=~/regexp/, specs.<path> in [value1, value2]) with "and" and "or", "and" taking precedence over "or": parentheses group them explicitly, and the values
+with keywords are quoted: -q '(specs.os==linux or specs.os==darwin) and specs.owner=="ops and dev"'
+
+The exclusion flags remove nodes from the targeted ones: jack run 'web-*' --exclude 'web-canary-*' cmd.run
+
+The --syndic flag sends the task to the nodes of the syndics (managers connected to this one as nodes) matching
+the Glob pattern, the targets select their nodes: jack run --syndic 'eu-*' 'web-*' cmd.run
+
+The --rolling flag runs the task on one node at a time, in the order of their IDs, and skips the remaining nodes
+once the failures exceed --max-failures: jack run --rolling --max-failures 1 'web-*' cmd.run -- ./restart.sh
+The --fail-fast flag runs the task on all the nodes at once, and stops the run at the first failure: the nodes
+not dispatched yet are skipped, and the manager stops waiting for the running ones (cancelled), their results are
+stored once done: jack run --fail-fast 'web-*' cmd.run -- ./deploy.sh
+
+The --at flag schedules the run instead: the manager stores it and runs it at the given time, e.g. at the opening
+of a maintenance window: jack run --at 2024-06-01T02:00Z 'web-*' pkg.upgrade
+The scheduled runs are listed by jack results pending, and removed by jack results cancel-pending.
+
+The runs targeting more nodes than the confirmation threshold of the jack configuration file, or running one of
+its destructive tasks, are confirmed first, unless --yes is set.
+
+TARGET and PLUGIN:TASK can be presets of the jack configuration file ($JACK_CONFIG or
+~/.config/` + config.CLIConfigFile + `), prefixed by ` + PresetPrefix + `: jack run @web-prod @pull
+
+jack exits with 1 if the run failed, or with the code of the internal error of the failed responses:
+` + exitCodesHelp(),
 		Args: func(cmd *cobra.Command, args []string) error {
-			if len(args) < 2 {
-				err := fmt.Errorf("requires at least %d arg(s), only received %d", 2, len(args))
+			minArgs := 2 // TARGET PLUGIN:TASK
+			if target.IsSet() {
+				minArgs = 1
+			}
+			if len(args) < minArgs {
+				err := fmt.Errorf("requires at least %d arg(s), only received %d", minArgs, len(args))
 				fmt.Fprintln(os.Stderr, style.RenderError(err.Error()))
 				_ = cmd.Help()
 				os.Exit(1)
@@ -70,71 +147,135 @@ func RunCommand() *cobra.Command {
 			return nil
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if target.IsSet() {
+				args = append([]string{""}, args...) // no TARGET argument
+			}
 			switch len(args) {
 			case 0:
 				// node name completion
-				return node.ListNode(), cobra.ShellCompDirectiveNoFileComp
+				cfg, _ := loadJackConfig(jackConfigFile())
+				return append(node.ListNode(), presetCompletions(cfg.Presets.Targets)...), cobra.ShellCompDirectiveNoFileComp
 			case 1:
 				// plugin:task completion using plugin in plugin directory + built-ins
+				if strings.HasPrefix(toComplete, PresetPrefix) {
+					cfg, _ := loadJackConfig(jackConfigFile())
+					return presetCompletions(cfg.Presets.Tasks), cobra.ShellCompDirectiveNoFileComp
+				}
 				return autocompletion.GetTaskCompletions(toComplete)
 			default:
 				return []string{}, cobra.ShellCompDirectiveNoFileComp
 			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			targets := args[0]
-			if target.File {
-				var err error
-				targets, err = targetsFromFile(args[0])
-				if err != nil {
+			cfg, err := loadJackConfig(jackConfigFile())
+			if err != nil {
+				fmt.Fprintln(os.Stderr, style.RenderError(err.Error()))
+				os.Exit(1)
+			}
+			run, err := newTaskRun(target, args)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, style.RenderError(err.Error()))
+				os.Exit(1)
+			}
+			run, err = cfg.Presets.expand(run, target.IsSet())
+			if err != nil {
+				fmt.Fprintln(os.Stderr, style.RenderError(err.Error()))
+				os.Exit(1)
+			}
+			run.syndics = syndics
+
+			if dryRun {
+				if err := resolveTargets(run); err != nil {
 					fmt.Fprintln(os.Stderr, style.RenderError(err.Error()))
 					os.Exit(1)
 				}
+				return
 			}
 
-			protoLockMode := parseLockMode(lockMode)
-			out, err := sendTask(targets, target.Mode(), protoLockMode, timeout, args[1], args[2:]...)
+			prio, err := parsePriority(priority)
 			if err != nil {
-				e := status.Convert(err)
-				fmt.Fprintln(os.Stderr, style.RenderError(e.Message()))
+				fmt.Fprintln(os.Stderr, style.RenderError(err.Error()))
 				os.Exit(1)
 			}
 
-			if option.GetJSONFormat() {
-				decodedResponses := make(map[string]*proxyResponse)
+			if err := checkRolling(rolling, maxFailures); err != nil {
+				fmt.Fprintln(os.Stderr, style.RenderError(err.Error()))
+				os.Exit(1)
+			}
 
-				for nodeName, response := range out.GetResponses() {
-					decodedResponse := proxyResponse{
-						TaskResponse: response,
-						Output:       string(response.Output), // Decode bytes to string
-					}
-					decodedResponses[nodeName] = &decodedResponse
+			var scheduled time.Time
+			if at != "" {
+				scheduled, err = parseAt(at, time.Now())
+				if err != nil {
+					fmt.Fprintln(os.Stderr, style.RenderError(err.Error()))
+					os.Exit(1)
+				}
+				if notifyURL != "" {
+					fmt.Fprintln(os.Stderr, style.RenderError("--notify cannot be used with --at: the manager runs the task, not jack"))
+					os.Exit(1)
 				}
+			}
 
-				result, err := serializer.JSON.MarshalIndent(decodedResponses, "", "  ")
-				if err != nil {
-					fmt.Fprintln(os.Stderr, style.RenderError(fmt.Sprintf("failed to serialize response in JSON: %s", err)))
+			if err := confirmTerminalRun(cfg.Confirm, yes, run); err != nil {
+				fmt.Fprintln(os.Stderr, style.RenderError(err.Error()))
+				os.Exit(1)
+			}
+
+			opts := runOptions{
+				lockMode:    parseLockMode(lockMode),
+				priority:    prio,
+				failFast:    failFast,
+				rolling:     rolling,
+				maxFailures: maxFailures,
+				timeout:     timeout,
+				metadata:    metadata,
+				notifyURL:   notifyURL,
+			}
+			if !scheduled.IsZero() {
+				if err := scheduleRun(run, opts, scheduled); err != nil {
+					fmt.Fprintln(os.Stderr, style.RenderError(err.Error()))
 					os.Exit(1)
 				}
-				fmt.Println(string(result))
-			} else {
-				printTaskResult(out)
+				return
+			}
+			if err := execute(run, opts, cfg.History); err != nil {
+				exitWithError(err)
 			}
 		},
 		GroupID: "operations",
 	}
 
-	cmd.Flags().BoolVarP(&target.Exact, "target", "t", false, "target a specific node")
-	cmd.Flags().BoolVarP(&target.List, "list", "l", false, "target a list of nodes, separator: ','")
-	cmd.Flags().BoolVarP(&target.File, "file", "f", false, "target a list of nodes from a file (one node per line)")
-	cmd.Flags().BoolVarP(&target.Glob, "glob", "g", false, "target nodes matching the Glob pattern")
-	cmd.Flags().BoolVarP(&target.Regexp, "regexp", "e", false, "target nodes matching the regular expression")
-	cmd.Flags().BoolVarP(&target.Query, "query", "q", false, "target nodes using a query")
-	cmd.MarkFlagsMutuallyExclusive("target", "list", "glob", "regexp", "query", "file")
+	cmd.Flags().StringArrayVarP(&target.Exact, "target", "t", nil, "target a specific node (repeatable)")
+	cmd.Flags().StringArrayVarP(&target.List, "list", "l", nil, "target a list of nodes, separator: ',' (repeatable)")
+	cmd.Flags().StringArrayVarP(&target.File, "file", "f", nil, "target a list of nodes from a file, one node per line (repeatable)")
+	cmd.Flags().StringArrayVarP(&target.Glob, "glob", "g", nil, "target nodes matching the Glob pattern, or a spec with specs:<path>:<pattern> (repeatable)")
+	cmd.Flags().StringArrayVarP(&target.Regexp, "regexp", "e", nil, "target nodes matching the regular expression, or a spec with specs:<path>:<regexp> (repeatable)")
+	cmd.Flags().StringArrayVarP(&target.Query, "query", "q", nil, "target nodes using a query (repeatable)")
+	cmd.Flags().StringArrayVar(&target.Exclude, "exclude", nil, "exclude the nodes matching the Glob pattern (repeatable)")
+	cmd.Flags().StringArrayVar(&target.ExcludeList, "exclude-list", nil, "exclude a list of nodes, separator: ',' (repeatable)")
+	cmd.Flags().StringArrayVar(&target.ExcludeRegexp, "exclude-regexp", nil, "exclude the nodes matching the regular expression (repeatable)")
+	cmd.Flags().StringArrayVar(&target.ExcludeQuery, "exclude-query", nil, "exclude the nodes matching the query (repeatable)")
+	cmd.Flags().StringArrayVar(&syndics, "syndic", nil, "target the nodes of the syndics matching the Glob pattern (repeatable)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "display the targeted nodes without running the task")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "run without confirmation, even a destructive task or on many nodes")
+	cmd.Flags().StringVar(&at, "at", "", "schedule the run at this time, run by the manager (format: 2006-01-02T15:04Z07:00, or 2006-01-02 15:04 local time)")
+	_ = cmd.RegisterFlagCompletionFunc("target", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return node.ListNode(), cobra.ShellCompDirectiveNoFileComp
+	})
 
 	cmd.Flags().IntVar(&timeout, "timeout", 30, "task timeout in second")
+	cmd.Flags().StringVar(&notifyURL, "notify", "", "send a summary of the run to a chat webhook URL (Slack, Mattermost...)")
+	cmd.Flags().StringToStringVar(&metadata, "meta", nil, "metadata stored with the results, e.g. --meta build=1234 (repeatable)")
 	cmd.Flags().StringVar(&lockMode, "lock-mode", "default", "task lock mode: none (concurrent), write (single writer, allows concurrent readers), exclusive (exclusive lock)")
 
+	cmd.Flags().StringVar(&priority, "priority", "normal", "task priority on the nodes busy with other tasks: high, normal or low")
+	_ = cmd.RegisterFlagCompletionFunc("priority", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"high", "normal", "low"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	cmd.Flags().BoolVar(&rolling, "rolling", false, "run the task on one node at a time, in the order of their IDs, and skip the remaining nodes once the failures exceed --max-failures")
+	cmd.Flags().IntVar(&maxFailures, "max-failures", 0, "failures tolerated by a rolling run")
+	cmd.Flags().BoolVar(&failFast, "fail-fast", false, "stop the run at the first failure: skip the nodes not dispatched yet, and stop waiting for the running ones")
+
 	// Add shell completion for lock mode flag
 	_ = cmd.RegisterFlagCompletionFunc("lock-mode", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return []string{
@@ -171,20 +312,29 @@ func targetsFromFile(file string) (string, error) {
 	return strings.Join(nodes, ","), nil
 }
 
-func sendTask(target string, targetMode proto.TargetMode, lockMode proto.LockMode, timeout int, task string, args ...string) (*proto.FwdResponse, error) {
-	conn, err := connection.DialCLI()
-	if err != nil {
-		return nil, errors.New("failed to connect to the manager")
+// newTaskRun returns the run of the command line: the targets of the flags if set, the TARGET argument (a Glob
+// pattern) otherwise.
+func newTaskRun(target Target, args []string) (taskRun, error) {
+	if !target.IsSet() {
+		return taskRun{
+			targets:  []*proto.Target{{Target: args[0], Mode: proto.TargetMode_GLOB}},
+			excludes: target.Excludes(),
+			task:     args[1],
+			args:     args[2:],
+		}, nil
 	}
-	defer conn.Close()
-
-	client := proto.NewForwarderClient(conn)
 
-	// ctxReq timeout is 1 second more than expected timeout to give time to the manager or node to
-	// send a timeout response with the IDs of the task.
-	ctxReq, cancel := context.WithTimeout(context.Background(), time.Duration(timeout+1)*time.Second)
-	defer cancel()
+	targets, err := target.Targets()
+	if err != nil {
+		return taskRun{}, err
+	}
+	return taskRun{targets: targets, excludes: target.Excludes(), task: args[0], args: args[1:]}, nil
+}
 
+// newTaskRequest builds the request of the task, given in the plugin.task form.
+//
+// The plugin name cannot contain the separator, the task name is what follows the first one.
+func newTaskRequest(targets, excludes []*proto.Target, lockMode proto.LockMode, timeout int, metadata map[string]string, task string, args ...string) (*proto.TaskRequest, error) {
 	arguments, err := parser.ParseArgs(args)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse arguments: %w", err)
@@ -197,26 +347,257 @@ func sendTask(target string, targetMode proto.TargetMode, lockMode proto.LockMod
 
 	opts, err := structpb.NewStruct(arguments.Options)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to convert options to protobuf struct: %w", err)
+	}
+
+	plugin, name, ok := strings.Cut(task, config.PluginSeparator)
+	if !ok {
+		name = plugin // a plugin and its task share the same name
 	}
+	req := &proto.TaskRequest{
+		LockMode: lockMode,
+		Plugin:   plugin,
+		Task:     name,
+		Input: &proto.Input{
+			Args:    argList,
+			Options: opts,
+		},
+		Timeout:  helper.IntToUint32(timeout), // the request context timeout should always be superior to this value
+		Metadata: metadata,
+	}
+	if len(targets) == 1 && len(excludes) == 0 {
+		req.Target, req.TargetMode = targets[0].GetTarget(), targets[0].GetMode()
+	} else {
+		// the target is left empty, so that the managers not supporting several targets nor exclusions refuse
+		// the request
+		req.Targets = targets
+		req.Excludes = excludes
+	}
+	return req, nil
+}
 
-	input := proto.Input{
-		Args:    argList,
-		Options: opts,
+// viaSyndics sends the request to the nodes of the syndics matching the Glob patterns: the targets and the
+// exclusions of the request select the nodes of the syndics.
+func viaSyndics(req *proto.TaskRequest, syndics []string) {
+	if len(syndics) == 0 {
+		return
 	}
+	req.DownstreamTargets = req.AllTargets()
+	req.DownstreamExcludes = req.GetExcludes()
 
-	responses, err := client.ExecTask(ctxReq, &proto.TaskRequest{
-		Target:     target,
-		TargetMode: targetMode,
-		LockMode:   lockMode,
-		Task:       task,
-		Input:      &input,
-		Timeout:    helper.IntToUint32(timeout), // ctxReq should always be superior to this value
-	})
+	// the target is left empty, so that the managers not supporting several targets refuse the request
+	req.Target, req.TargetMode = "", proto.TargetMode_UNKNOWN
+	req.Targets = make([]*proto.Target, 0, len(syndics))
+	for _, syndic := range syndics {
+		req.Targets = append(req.Targets, &proto.Target{Target: syndic, Mode: proto.TargetMode_GLOB})
+	}
+	req.Excludes = nil
+}
+
+// runOptions are the options of a run, besides its targets and its task.
+type runOptions struct {
+	lockMode    proto.LockMode
+	priority    proto.Priority
+	failFast    bool
+	rolling     bool
+	maxFailures int
+	timeout     int
+	metadata    map[string]string
+	notifyURL   string
+}
 
+// execute sends the run, displays the responses and records the run in the history.
+func execute(run taskRun, opts runOptions, history HistoryConfig) error {
+	var progress *progressView
+	if !option.GetJSONFormat() {
+		progress = newProgressView(opts.rolling)
+	}
+
+	out, err := sendTask(run, opts, progress.report)
+	progress.clear()
+	if recordErr := history.record(newHistoryEntry(time.Now(), run, opts, out, err)); recordErr != nil {
+		fmt.Fprintln(os.Stderr, style.RenderError(fmt.Sprintf("failed to record the history: %s", recordErr)))
+	}
+	if err != nil {
+		return errors.New(status.Convert(err).Message())
+	}
+
+	if option.GetJSONFormat() {
+		decodedResponses := make(map[string]*proxyResponse)
+
+		for nodeName, response := range out.GetResponses() {
+			decodedResponse := proxyResponse{
+				TaskResponse: response,
+				Output:       string(response.Output), // Decode bytes to string
+			}
+			if response.GetInternalError() != proto.InternalError_OK {
+				decodedResponse.InternalErrorMessage = describe(response.GetInternalError()).message
+			}
+			decodedResponses[nodeName] = &decodedResponse
+		}
+
+		result, err := serializer.JSON.MarshalIndent(decodedResponses, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize response in JSON: %w", err)
+		}
+		fmt.Println(string(result))
+	} else {
+		printTaskResult(out)
+	}
+
+	if opts.notifyURL != "" {
+		summary := notification.Run{Task: run.task, Responses: out.GetResponses()}
+		if err := notification.PostSummary(context.Background(), opts.notifyURL, summary); err != nil {
+			return fmt.Errorf("failed to send notification: %w", err)
+		}
+	}
+	return responsesStatus(out.GetResponses())
+}
+
+// resolveTargets displays the nodes targeted by the run.
+func resolveTargets(run taskRun) error {
+	req, nodes, err := resolveNodes(run)
+	if err != nil {
+		return err
+	}
+
+	if option.GetJSONFormat() {
+		result, err := serializer.JSON.MarshalIndent(nodes, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize response in JSON: %w", err)
+		}
+		fmt.Println(string(result))
+		return nil
+	}
+
+	printTargets(req.TargetsString(), nodes)
+	return nil
+}
+
+// resolveNodes returns the request of the run and the nodes it targets, without sending it.
+func resolveNodes(run taskRun) (*proto.TaskRequest, map[string]bool, error) {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to the manager: %w", err)
+	}
+	defer conn.Close()
+
+	req, err := newTaskRequest(run.targets, run.excludes, proto.LockMode_UNSPECIFIED, 0, nil, run.task, run.args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	viaSyndics(req, run.syndics)
+	ctxReq, cancel := connection.RequestContext()
+	defer cancel()
+	resp, err := proto.NewForwarderClient(conn).ResolveTargets(ctxReq, req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve the targets: %s", status.Convert(err).Message())
+	}
+	return req, resp.GetNodes(), nil
+}
+
+// runRequest builds the request of the run.
+func runRequest(run taskRun, opts runOptions) (*proto.TaskRequest, error) {
+	req, err := newTaskRequest(run.targets, run.excludes, opts.lockMode, opts.timeout, opts.metadata, run.task, run.args...)
+	if err != nil {
+		return nil, err
+	}
+	req.Priority = opts.priority
+	req.FailFast = opts.failFast
+	req.Rolling = opts.rolling
+	req.MaxFailures = helper.IntToUint32(opts.maxFailures)
+	viaSyndics(req, run.syndics)
+	return req, nil
+}
+
+// sendTask sends the task and returns the responses of the targeted nodes.
+//
+// The progress updates and the responses are also passed to report as soon as they are received.
+func sendTask(run taskRun, opts runOptions, report func(node string, resp *proto.TaskResponse)) (*proto.FwdResponse, error) {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the manager: %w", err)
+	}
+	defer conn.Close()
+
+	client := proto.NewForwarderClient(conn)
+
+	req, err := runRequest(run, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctxReq, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.StreamTask(ctxReq, req)
 	if err != nil {
 		return nil, fmt.Errorf("not sent: %s", status.Convert(err).Message())
 	}
 
+	// the run is cancelled 1 second after the timeout and the response grace of the manager, to give time to the node
+	// to send a timeout response with the IDs of the task, or to the manager to report it unresponsive. A rolling run
+	// has no timeout: the nodes run the task one after the other, each of them bounded by the manager.
+	if !opts.rolling {
+		header, _ := stream.Header()
+		deadline := time.AfterFunc(time.Duration(opts.timeout+1)*time.Second+responseGrace(header), cancel)
+		defer deadline.Stop()
+	}
+
+	responses := &proto.FwdResponse{Responses: make(map[string]*proto.TaskResponse)}
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			trailer := trailerResponse(stream.Trailer())
+			responses.Targets, responses.StoppedAt = trailer.GetTargets(), trailer.GetStoppedAt()
+			return responses, nil
+		}
+		if status.Code(err) == codes.Unimplemented {
+			// the manager does not support streaming: no progress, all responses at once
+			return sendTaskUnary(ctxReq, client, req)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("not sent: %s", status.Convert(err).Message())
+		}
+
+		report(msg.GetNode(), msg.GetResponse())
+		if msg.GetResponse().Progress == nil {
+			responses.Responses[msg.GetNode()] = msg.GetResponse()
+		}
+	}
+}
+
+// responseGrace returns the response grace of the manager sent in the header of a streamed run, the default one if
+// the manager does not send it.
+func responseGrace(header metadata.MD) time.Duration {
+	values := header.Get(config.GraceHeaderKey)
+	if len(values) == 0 {
+		return config.NodeResponseGrace
+	}
+	grace, err := time.ParseDuration(values[0])
+	if err != nil {
+		return config.NodeResponseGrace
+	}
+	return grace
+}
+
+// trailerResponse returns the dispatch status of the targeted nodes and the stop of a rolling run, sent in the
+// trailer of a streamed run, nil if the manager does not send them.
+func trailerResponse(trailer metadata.MD) *proto.FwdResponse {
+	values := trailer.Get(config.TargetsTrailerKey)
+	if len(values) == 0 {
+		return nil
+	}
+	var resp proto.FwdResponse
+	if err := protobuf.Unmarshal([]byte(values[0]), &resp); err != nil {
+		return nil
+	}
+	return &resp
+}
+
+func sendTaskUnary(ctx context.Context, client proto.ForwarderClient, req *proto.TaskRequest) (*proto.FwdResponse, error) {
+	responses, err := client.ExecTask(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("not sent: %s", status.Convert(err).Message())
+	}
 	return responses, nil
 }
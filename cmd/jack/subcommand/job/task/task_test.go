@@ -0,0 +1,265 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestNewTaskRequest(t *testing.T) {
+	tests := []struct {
+		task       string
+		wantPlugin string
+		wantTask   string
+	}{
+		{task: "cmd.run", wantPlugin: "cmd", wantTask: "run"},
+		{task: "pkg.v1.install", wantPlugin: "pkg", wantTask: "v1.install"},
+		{task: "health", wantPlugin: "health", wantTask: "health"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.task, func(t *testing.T) {
+			metadata := map[string]string{"build": "1234"}
+			req, err := newTaskRequest(tg("node1", proto.TargetMode_EXACT), nil, proto.LockMode_UNSPECIFIED, 10, metadata, tt.task, "arg", "key=value")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if req.GetPlugin() != tt.wantPlugin || req.GetTask() != tt.wantTask {
+				t.Errorf("got plugin %q and task %q, want %q and %q", req.GetPlugin(), req.GetTask(), tt.wantPlugin, tt.wantTask)
+			}
+			if req.FullTask() != tt.task && tt.wantPlugin != tt.wantTask {
+				t.Errorf("FullTask() = %q, want %q", req.FullTask(), tt.task)
+			}
+			if n := len(req.GetInput().GetArgs().GetValues()); n != 1 {
+				t.Errorf("expected 1 positional argument, got %d", n)
+			}
+			if req.GetInput().GetOptions().GetFields()["key"].GetStringValue() != "value" {
+				t.Errorf("expected the key option, got %v", req.GetInput().GetOptions())
+			}
+			if req.GetMetadata()["build"] != "1234" {
+				t.Errorf("expected the build metadata, got %v", req.GetMetadata())
+			}
+		})
+	}
+}
+
+func TestNewTaskRequestTargets(t *testing.T) {
+	single, err := newTaskRequest(tg("web-*", proto.TargetMode_GLOB), nil, proto.LockMode_UNSPECIFIED, 10, nil, "cmd.run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if single.GetTarget() != "web-*" || single.GetTargetMode() != proto.TargetMode_GLOB || len(single.GetTargets()) != 0 {
+		t.Errorf("a single target must be sent in the target field, got %v", single)
+	}
+
+	targets := []*proto.Target{
+		{Target: "web-1", Mode: proto.TargetMode_EXACT},
+		{Target: "db-*", Mode: proto.TargetMode_GLOB},
+	}
+	several, err := newTaskRequest(targets, nil, proto.LockMode_UNSPECIFIED, 10, nil, "cmd.run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if several.GetTarget() != "" || len(several.GetTargets()) != 2 {
+		t.Errorf("several targets must be sent in the targets field, got %v", several)
+	}
+	if got := several.TargetsString(); got != "exact:web-1 + glob:db-*" {
+		t.Errorf("TargetsString() = %q", got)
+	}
+
+	excluding, err := newTaskRequest(tg("web-*", proto.TargetMode_GLOB), tg("web-canary-*", proto.TargetMode_GLOB), proto.LockMode_UNSPECIFIED, 10, nil, "cmd.run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if excluding.GetTarget() != "" || len(excluding.GetTargets()) != 1 || len(excluding.GetExcludes()) != 1 {
+		t.Errorf("a target with exclusions must be sent in the targets field, got %v", excluding)
+	}
+	if got := excluding.TargetsString(); got != "glob:web-* - glob:web-canary-*" {
+		t.Errorf("TargetsString() = %q", got)
+	}
+}
+
+func TestViaSyndics(t *testing.T) {
+	req, err := newTaskRequest(tg("web-*", proto.TargetMode_GLOB), tg("web-canary", proto.TargetMode_EXACT), proto.LockMode_UNSPECIFIED, 10, nil, "cmd.run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	viaSyndics(req, []string{"eu-*", "us-*"})
+
+	if req.GetTarget() != "" || len(req.GetExcludes()) != 0 {
+		t.Errorf("the syndics must be sent in the targets field, got %v", req)
+	}
+	if got := req.TargetsString(); got != "glob:eu-* + glob:us-* / glob:web-* - exact:web-canary" {
+		t.Errorf("TargetsString() = %q", got)
+	}
+
+	direct, err := newTaskRequest(tg("web-*", proto.TargetMode_GLOB), nil, proto.LockMode_UNSPECIFIED, 10, nil, "cmd.run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	viaSyndics(direct, nil)
+	if direct.GetTarget() != "web-*" || len(direct.GetDownstreamTargets()) != 0 {
+		t.Errorf("the request must be unchanged without syndic, got %v", direct)
+	}
+}
+
+func TestNewTaskRun(t *testing.T) {
+	run, err := newTaskRun(Target{}, []string{"web-*", "cmd.run", "uptime"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.EqualFunc(run.targets, tg("web-*", proto.TargetMode_GLOB), sameTarget) || run.task != "cmd.run" || !slices.Equal(run.args, []string{"uptime"}) {
+		t.Errorf("unexpected run without flag: %+v", run)
+	}
+
+	file := filepath.Join(t.TempDir(), "nodes")
+	if err := os.WriteFile(file, []byte("node1\nnode2\nnode1\n"), 0600); err != nil {
+		t.Fatalf("failed to write nodes: %v", err)
+	}
+	flags := Target{
+		Exact: []string{"web-1", "web-2"},
+		File:  []string{file},
+		Glob:  []string{"db-*"},
+		Query: []string{"specs.env==prod"},
+
+		Exclude:      []string{"web-canary-*"},
+		ExcludeQuery: []string{"specs.env==dev"},
+	}
+	run, err = newTaskRun(flags, []string{"cmd.run", "uptime"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []*proto.Target{
+		{Target: "web-1", Mode: proto.TargetMode_EXACT},
+		{Target: "web-2", Mode: proto.TargetMode_EXACT},
+		{Target: "node1,node2", Mode: proto.TargetMode_LIST},
+		{Target: "db-*", Mode: proto.TargetMode_GLOB},
+		{Target: "specs.env==prod", Mode: proto.TargetMode_QUERY},
+	}
+	if !slices.EqualFunc(run.targets, want, sameTarget) {
+		t.Errorf("unexpected targets: %v", run.targets)
+	}
+	if run.task != "cmd.run" || !slices.Equal(run.args, []string{"uptime"}) {
+		t.Errorf("unexpected task with flags: %+v", run)
+	}
+	wantExcludes := []*proto.Target{
+		{Target: "web-canary-*", Mode: proto.TargetMode_GLOB},
+		{Target: "specs.env==dev", Mode: proto.TargetMode_QUERY},
+	}
+	if !slices.EqualFunc(run.excludes, wantExcludes, sameTarget) {
+		t.Errorf("unexpected exclusions: %v", run.excludes)
+	}
+}
+
+func TestTargetsSummary(t *testing.T) {
+	tests := []struct {
+		targets map[string]proto.DispatchStatus
+		want    string
+	}{
+		{targets: nil, want: ""},
+		{
+			targets: map[string]proto.DispatchStatus{"web-1": proto.DispatchStatus_DISPATCH_SENT, "web-2": proto.DispatchStatus_DISPATCH_SENT},
+			want:    "matched 2, dispatched 2",
+		},
+		{
+			targets: map[string]proto.DispatchStatus{
+				"web-1": proto.DispatchStatus_DISPATCH_SENT,
+				"web-2": proto.DispatchStatus_DISPATCH_DISCONNECTED,
+				"web-3": proto.DispatchStatus_DISPATCH_DISCONNECTED,
+				"web-4": proto.DispatchStatus_DISPATCH_REJECTED,
+			},
+			want: "matched 4, dispatched 1, 2 disconnected, 1 rejected",
+		},
+		{
+			targets: map[string]proto.DispatchStatus{
+				"web-1": proto.DispatchStatus_DISPATCH_SENT,
+				"web-2": proto.DispatchStatus_DISPATCH_SKIPPED,
+				"web-3": proto.DispatchStatus_DISPATCH_SKIPPED,
+			},
+			want: "matched 3, dispatched 1, 2 skipped",
+		},
+	}
+	for _, tt := range tests {
+		if got := targetsSummary(tt.targets); got != tt.want {
+			t.Errorf("targetsSummary(%v) = %q, want %q", tt.targets, got, tt.want)
+		}
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	tests := map[string]proto.Priority{
+		"high":   proto.Priority_PRIORITY_HIGH,
+		"Normal": proto.Priority_PRIORITY_NORMAL,
+		"":       proto.Priority_PRIORITY_NORMAL,
+		" low ":  proto.Priority_PRIORITY_LOW,
+	}
+	for value, want := range tests {
+		if got, err := parsePriority(value); err != nil || got != want {
+			t.Errorf("parsePriority(%q) = %s, %v, want %s", value, got, err, want)
+		}
+	}
+	if _, err := parsePriority("urgent"); err == nil {
+		t.Error("expected an error for an unknown priority")
+	}
+}
+
+func TestCheckRolling(t *testing.T) {
+	tests := []struct {
+		rolling     bool
+		maxFailures int
+		wantErr     bool
+	}{
+		{rolling: false, maxFailures: 0},
+		{rolling: true, maxFailures: 0},
+		{rolling: true, maxFailures: 2},
+		{rolling: true, maxFailures: -1, wantErr: true},
+		{rolling: false, maxFailures: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := checkRolling(tt.rolling, tt.maxFailures); (err != nil) != tt.wantErr {
+			t.Errorf("checkRolling(%t, %d) = %v, want error: %t", tt.rolling, tt.maxFailures, err, tt.wantErr)
+		}
+	}
+}
+
+func TestResponseGrace(t *testing.T) {
+	tests := []struct {
+		header metadata.MD
+		want   time.Duration
+	}{
+		{header: metadata.Pairs(config.GraceHeaderKey, "1m30s"), want: 90 * time.Second},
+		{header: metadata.Pairs(config.GraceHeaderKey, "0s"), want: 0},
+		{header: metadata.Pairs(config.GraceHeaderKey, "soon"), want: config.NodeResponseGrace},
+		{header: nil, want: config.NodeResponseGrace}, // manager not sending it
+	}
+	for _, tt := range tests {
+		if got := responseGrace(tt.header); got != tt.want {
+			t.Errorf("responseGrace(%v) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestParseAt(t *testing.T) {
+	now := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"2024-06-01T02:00Z":         time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC),
+		"2024-06-01T02:00:30+02:00": time.Date(2024, 6, 1, 0, 0, 30, 0, time.UTC),
+		"2024-06-01 02:00":          time.Date(2024, 6, 1, 2, 0, 0, 0, time.Local),
+	}
+	for value, want := range tests {
+		if got, err := parseAt(value, now); err != nil || !got.Equal(want) {
+			t.Errorf("parseAt(%q) = %s, %v, want %s", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"tomorrow", "2024-05-31T11:00Z"} {
+		if _, err := parseAt(value, now); err == nil {
+			t.Errorf("parseAt(%q): expected an error", value)
+		}
+	}
+}
@@ -1,17 +1,12 @@
 package node
 
 import (
-	"context"
-	"errors"
 	"fmt"
-	"os"
-	"time"
 
 	"github.com/jackadi-io/jackadi/cmd/jack/connection"
 	"github.com/jackadi-io/jackadi/cmd/jack/style"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"github.com/spf13/cobra"
-	"google.golang.org/grpc/status"
 )
 
 func acceptCommand() *cobra.Command {
@@ -33,9 +28,7 @@ func acceptCommand() *cobra.Command {
 			}
 			acceptedNode, err := accept(&nd)
 			if err != nil {
-				r := status.Convert(err)
-				fmt.Fprintln(os.Stderr, r.Message())
-				os.Exit(1)
+				connection.Exit(err)
 			}
 
 			style.PrettyPrint(fmt.Sprintf("node registered: %s\n", acceptedNode.String()))
@@ -51,12 +44,12 @@ func acceptCommand() *cobra.Command {
 func accept(nd *proto.NodeInfo) (*proto.NodeInfo, error) {
 	conn, err := connection.DialCLI()
 	if err != nil {
-		return nil, errors.New("failed to connect the manager")
+		return nil, fmt.Errorf("failed to connect the manager: %w", err)
 	}
 	defer conn.Close()
 	client := proto.NewAPIClient(conn)
 
-	ctxReq, cancel := context.WithTimeout(context.Background(), time.Minute)
+	ctxReq, cancel := connection.RequestContext()
 	defer cancel()
 
 	acceptedNode, err := client.AcceptNode(ctxReq, &proto.NodeRequest{Node: nd})
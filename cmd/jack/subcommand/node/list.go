@@ -1,11 +1,9 @@
 package node
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"os"
-	"time"
 
 	"github.com/jackadi-io/jackadi/cmd/jack/connection"
 	"github.com/jackadi-io/jackadi/cmd/jack/option"
@@ -13,7 +11,6 @@ import (
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"github.com/jackadi-io/jackadi/internal/serializer"
 	"github.com/spf13/cobra"
-	"google.golang.org/grpc/status"
 )
 
 func healthCommand() *cobra.Command {
@@ -24,9 +21,7 @@ func healthCommand() *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			resp, err := list(0)
 			if err != nil {
-				r := status.Convert(err)
-				fmt.Fprintln(os.Stderr, r.Message())
-				os.Exit(1)
+				connection.Exit(err)
 			}
 
 			if option.GetJSONFormat() {
@@ -38,7 +33,7 @@ func healthCommand() *cobra.Command {
 				fmt.Println(string(result))
 			} else {
 				in := style.Title("Nodes")
-				in += prettyNodesHealthSprint(resp.Accepted, verbose)
+				in += prettyNodesHealthSprint(resp.Accepted, resp.GetManagerVersion(), verbose)
 
 				style.PrettyPrint(in)
 			}
@@ -50,16 +45,21 @@ func healthCommand() *cobra.Command {
 }
 
 func listCommand() *cobra.Command {
-	var verbose bool
+	var verbose, watchMode bool
 	cmd := &cobra.Command{
 		Use:   "list [OPTION] ...",
 		Short: "list nodes",
 		Run: func(cmd *cobra.Command, args []string) {
+			if watchMode {
+				if err := watch(verbose); err != nil {
+					connection.Exit(err)
+				}
+				return
+			}
+
 			resp, err := list(0)
 			if err != nil {
-				r := status.Convert(err)
-				fmt.Fprintln(os.Stderr, r.Message())
-				os.Exit(1)
+				connection.Exit(err)
 			}
 
 			if option.GetJSONFormat() {
@@ -70,31 +70,36 @@ func listCommand() *cobra.Command {
 				}
 				fmt.Println(string(result))
 			} else {
-				in := style.Title("Accepted")
-				in += prettyNodeListSprint(resp.Accepted, verbose)
-				in += style.Title("Candidates")
-				in += prettyNodeListSprint(resp.Candidates, verbose)
-				in += style.Title("Rejected")
-				in += prettyNodeListSprint(resp.Rejected, verbose)
-
-				style.PrettyPrint(in)
+				style.PrettyPrint(prettyNodesSprint(resp, verbose, nil))
 			}
 		},
 	}
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "show node details")
+	cmd.Flags().BoolVarP(&watchMode, "watch", "w", false, "redraw the list as the nodes connect and disconnect, until interrupted")
 
 	return cmd
 }
 
+// prettyNodesSprint renders the nodes, the candidates in newCandidates being highlighted.
+func prettyNodesSprint(resp *proto.ListNodesResponse, verbose bool, newCandidates map[string]bool) string {
+	in := style.Title("Accepted")
+	in += prettyNodeListSprint(resp.Accepted, verbose, nil)
+	in += style.Title("Candidates")
+	in += prettyNodeListSprint(resp.Candidates, verbose, newCandidates)
+	in += style.Title("Rejected")
+	in += prettyNodeListSprint(resp.Rejected, verbose, nil)
+	return in
+}
+
 func list(filter proto.Filter) (*proto.ListNodesResponse, error) {
 	conn, err := connection.DialCLI()
 	if err != nil {
-		return nil, errors.New("failed to connect the manager")
+		return nil, fmt.Errorf("failed to connect the manager: %w", err)
 	}
 	defer conn.Close()
 	client := proto.NewAPIClient(conn)
 
-	ctxReq, cancel := context.WithTimeout(context.Background(), time.Minute)
+	ctxReq, cancel := connection.RequestContext()
 	defer cancel()
 
 	resp, err := client.ListNodes(ctxReq, &proto.ListNodesRequest{
@@ -0,0 +1,127 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/logs"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func logsCommand() *cobra.Command {
+	var lines int
+	var follow bool
+	cmd := &cobra.Command{
+		Use:   "logs NODE",
+		Short: "show the recent logs of a node",
+		Long: `Show the recent logs of a node, in text format, fetched with the logs.tail builtin task.
+The node keeps its last ` + fmt.Sprint(config.LogRingSize) + ` log lines in memory. As any task, the access to the logs through
+the web API is granted by the logs.tail task permission. The logs are fetched by transient requests, not stored in the
+results.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := tailLogs(args[0], lines, follow); err != nil {
+				connection.Exit(err)
+			}
+		},
+	}
+	cmd.Flags().IntVarP(&lines, "lines", "n", config.LogTailLines, "number of lines, 0 for all the lines kept by the node")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "print the new lines as they are logged, until interrupted")
+
+	return cmd
+}
+
+// tailLogs prints the last lines logged by the node, then the new ones every CLILogsInterval if follow is set.
+func tailLogs(nd string, lines int, follow bool) error {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewForwarderClient(conn)
+
+	var since uint64
+	for {
+		fetched, err := fetchLogs(client, nd, lines, since)
+		if err != nil {
+			return err
+		}
+		for _, line := range fetched {
+			if since > 0 && line.Seq > since+1 {
+				fmt.Println(style.Subtitle(fmt.Sprintf("%d lines skipped", line.Seq-since-1)))
+			}
+			if err := printLine(line); err != nil {
+				return err
+			}
+			since = line.Seq
+		}
+		if !follow {
+			return nil
+		}
+		lines = 0 // all the new lines
+		time.Sleep(config.CLILogsInterval)
+	}
+}
+
+// fetchLogs returns the lines logged by the node after since, at most lines if positive.
+func fetchLogs(client proto.ForwarderClient, nd string, lines int, since uint64) ([]logs.Line, error) {
+	opts, err := structpb.NewStruct(map[string]any{"lines": lines, "since": since})
+	if err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+	req := &proto.TaskRequest{
+		Target:     nd,
+		TargetMode: proto.TargetMode_EXACT,
+		Plugin:     "logs",
+		Task:       "tail",
+		Input:      &proto.Input{Args: &structpb.ListValue{}, Options: opts},
+		Timeout:    uint32(config.TaskTimeout.Seconds()),
+		Transient:  true, // polled every CLILogsInterval with --follow, the lines are not results to keep
+	}
+
+	ctxReq, cancel := connection.RequestContext()
+	defer cancel()
+	resp, err := client.ExecTask(ctxReq, req)
+	if err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+
+	r, ok := resp.GetResponses()[nd]
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("unknown node '%s'", nd)
+	case r.GetInternalError() != proto.InternalError_OK:
+		return nil, fmt.Errorf("failed to fetch the logs: %s %s", r.GetInternalError(), r.GetModuleError())
+	case r.GetError() != "":
+		return nil, fmt.Errorf("failed to fetch the logs: %s", r.GetError())
+	}
+
+	var fetched []logs.Line
+	if err := serializer.JSON.Unmarshal(r.GetOutput(), &fetched); err != nil {
+		return nil, fmt.Errorf("invalid logs: %w", err)
+	}
+	return fetched, nil
+}
+
+// printLine prints the line as logged, or as a JSON object.
+func printLine(line logs.Line) error {
+	if !option.GetJSONFormat() {
+		fmt.Println(line.Text)
+		return nil
+	}
+	out, err := serializer.JSON.Marshal(line)
+	if err != nil {
+		return fmt.Errorf("failed to serialize the line in JSON: %w", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
@@ -1,17 +1,12 @@
 package node
 
 import (
-	"context"
-	"errors"
 	"fmt"
-	"os"
-	"time"
 
 	"github.com/jackadi-io/jackadi/cmd/jack/connection"
 	"github.com/jackadi-io/jackadi/cmd/jack/style"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"github.com/spf13/cobra"
-	"google.golang.org/grpc/status"
 )
 
 func rejectCommand() *cobra.Command {
@@ -25,9 +20,7 @@ func rejectCommand() *cobra.Command {
 				Id: args[0],
 			}
 			if err := reject(&nd); err != nil {
-				r := status.Convert(err)
-				fmt.Fprintln(os.Stderr, r.Message())
-				os.Exit(1)
+				connection.Exit(err)
 			}
 
 			style.PrettyPrint(fmt.Sprintf("node rejected: %s\n", nd.GetId()))
@@ -41,12 +34,12 @@ func rejectCommand() *cobra.Command {
 func reject(nd *proto.NodeInfo) error {
 	conn, err := connection.DialCLI()
 	if err != nil {
-		return errors.New("failed to connect the manager")
+		return fmt.Errorf("failed to connect the manager: %w", err)
 	}
 	defer conn.Close()
 	client := proto.NewAPIClient(conn)
 
-	ctxReq, cancel := context.WithTimeout(context.Background(), time.Minute)
+	ctxReq, cancel := connection.RequestContext()
 	defer cancel()
 
 	_, err = client.RejectNode(ctxReq, &proto.NodeRequest{Node: nd})
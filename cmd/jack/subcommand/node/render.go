@@ -9,6 +9,7 @@ import (
 	"github.com/jackadi-io/jackadi/cmd/jack/option"
 	"github.com/jackadi-io/jackadi/cmd/jack/style"
 	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/version"
 )
 
 func sortNodeFunc(a *proto.NodeInfo, b *proto.NodeInfo) int {
@@ -18,27 +19,43 @@ func sortNodeFunc(a *proto.NodeInfo, b *proto.NodeInfo) int {
 	return strings.Compare(a.Id, b.Id)
 }
 
-func prettyNodeListSprint(nodes []*proto.NodeInfo, showDetails bool) string {
+func prettyNodeListSprint(nodes []*proto.NodeInfo, showDetails bool, highlighted map[string]bool) string {
 	var items strings.Builder
 	if option.GetSortOutput() {
 		slices.SortFunc(nodes, sortNodeFunc)
 	}
 
 	for _, nd := range nodes {
+		id := nd.GetId()
+		if highlighted[id] {
+			id = style.RenderID(id) + " " + style.Emph("new")
+		}
 		if !showDetails {
-			items.WriteString(style.Item(nd.GetId()))
+			items.WriteString(style.Item(id))
 			continue
 		}
 		if nd.GetCertificate() != "" {
-			items.WriteString(style.Item(fmt.Sprintf("%s (%s %s)", nd.GetId(), nd.GetAddress(), nd.GetCertificate())))
+			items.WriteString(style.Item(fmt.Sprintf("%s (%s %s)%s", id, nd.GetAddress(), nd.GetCertificate(), prettyUptime(nd))))
 		} else {
-			items.WriteString(style.Item(fmt.Sprintf("%s (%s)", nd.GetId(), nd.GetAddress())))
+			items.WriteString(style.Item(fmt.Sprintf("%s (%s)%s", id, nd.GetAddress(), prettyUptime(nd))))
 		}
 	}
 
 	return style.SpacedBlock(items.String())
 }
 
+// prettyUptime returns the uptime of a connected node, with its connections if it reconnected.
+func prettyUptime(nd *proto.NodeInfo) string {
+	out := ""
+	if nd.GetIsConnected() {
+		out = fmt.Sprintf(" for %s", time.Duration(nd.GetUptime())*time.Second)
+	}
+	if nd.GetConnections() > 1 {
+		out += fmt.Sprintf(" (%d connections)", nd.GetConnections())
+	}
+	return out
+}
+
 func prettyTime(t time.Time) string {
 	if t.IsZero() {
 		return "never"
@@ -46,7 +63,18 @@ func prettyTime(t time.Time) string {
 	return t.Format("January 2, 2006 at 15:04 UTC")
 }
 
-func prettyNodesHealthSprint(nodes []*proto.NodeInfo, showDetails bool) string {
+// prettyVersion returns the node version, highlighted if its major version differs from the manager one.
+func prettyVersion(nodeVersion, managerVersion string) string {
+	if nodeVersion == "" {
+		return style.RenderUnknown("unknown")
+	}
+	if !version.SameMajor(nodeVersion, managerVersion) {
+		return style.RenderError(fmt.Sprintf("%s (manager: %s)", nodeVersion, managerVersion))
+	}
+	return nodeVersion
+}
+
+func prettyNodesHealthSprint(nodes []*proto.NodeInfo, managerVersion string, showDetails bool) string {
 	var items strings.Builder
 	if option.GetSortOutput() {
 		slices.SortFunc(nodes, sortNodeFunc)
@@ -62,14 +90,19 @@ func prettyNodesHealthSprint(nodes []*proto.NodeInfo, showDetails bool) string {
 		lastActive := nd.GetLastMsg().AsTime()
 
 		if !showDetails {
-			items.WriteString(style.Item(fmt.Sprintf("%s: %s", nd.GetId(), connectedState)))
+			items.WriteString(style.Item(fmt.Sprintf("%s: %s%s", nd.GetId(), connectedState, prettyUptime(nd))))
 			continue
 		}
 
 		items.WriteString(style.Item(nd.GetId()))
 		items.WriteString(style.SubItem(fmt.Sprintf("state: %s", connectedState)))
 		items.WriteString(style.SubItem(fmt.Sprintf("%s since: %s", connectedState, prettyTime(nd.GetSince().AsTime()))))
+		if nd.GetIsConnected() {
+			items.WriteString(style.SubItem(fmt.Sprintf("uptime: %s", time.Duration(nd.GetUptime())*time.Second)))
+		}
+		items.WriteString(style.SubItem(fmt.Sprintf("connections: %d", nd.GetConnections())))
 		items.WriteString(style.SubItem(fmt.Sprintf("last event: %s", prettyTime(lastActive))))
+		items.WriteString(style.SubItem(fmt.Sprintf("version: %s", prettyVersion(nd.GetVersion(), managerVersion))))
 	}
 
 	return style.SpacedBlock(items.String())
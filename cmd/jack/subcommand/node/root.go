@@ -14,6 +14,8 @@ func Root() *cobra.Command {
 	cmd.AddCommand(removeCommand())
 	cmd.AddCommand(rejectCommand())
 	cmd.AddCommand(healthCommand())
+	cmd.AddCommand(specsDiffCommand())
+	cmd.AddCommand(logsCommand())
 
 	return cmd
 }
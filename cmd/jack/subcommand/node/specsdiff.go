@@ -0,0 +1,110 @@
+package node
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+// specChange is the JSON output of a spec change, with its values as JSON rather than bytes.
+type specChange struct {
+	Type string          `jackadi:"type"`
+	Path string          `jackadi:"path"`
+	Old  json.RawMessage `jackadi:"old,omitempty"`
+	New  json.RawMessage `jackadi:"new,omitempty"`
+}
+
+type specsDrift struct {
+	Time    time.Time    `jackadi:"time"`
+	Changes []specChange `jackadi:"changes"`
+}
+
+func specsDiffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "specs-diff NODE",
+		Short: "show the recent changes of the specs of a node",
+		Long: `Show the recent changes of the specs of a node between two collections, the oldest first.
+The changes are kept by the manager since its start.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			resp, err := specsDrifts(args[0])
+			if err != nil {
+				connection.Exit(err)
+			}
+
+			if option.GetJSONFormat() {
+				drifts := []specsDrift{}
+				for _, d := range resp.GetDrifts() {
+					drift := specsDrift{Time: d.GetTime().AsTime(), Changes: []specChange{}}
+					for _, c := range d.GetChanges() {
+						drift.Changes = append(drift.Changes, specChange{Type: c.GetType(), Path: c.GetPath(), Old: c.GetOld(), New: c.GetNew()})
+					}
+					drifts = append(drifts, drift)
+				}
+				result, err := serializer.JSON.MarshalIndent(drifts, "", "   ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "failed to serialize response in JSON: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(result))
+				return
+			}
+
+			style.PrettyPrint(prettySpecsDriftsSprint(resp))
+		},
+	}
+
+	return cmd
+}
+
+func specsDrifts(nd string) (*proto.SpecsDriftsResponse, error) {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewAPIClient(conn)
+
+	ctxReq, cancel := connection.RequestContext()
+	defer cancel()
+
+	resp, err := client.SpecsDrifts(ctxReq, &proto.SpecsDriftsRequest{Node: nd})
+	if err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+	return resp, nil
+}
+
+func prettySpecsDriftsSprint(resp *proto.SpecsDriftsResponse) string {
+	if len(resp.GetDrifts()) == 0 {
+		return style.Item(style.RenderUnknown("no change"))
+	}
+
+	out := ""
+	for _, d := range resp.GetDrifts() {
+		out += style.Title(d.GetTime().AsTime().Local().Format(time.DateTime))
+		for _, c := range d.GetChanges() {
+			var line string
+			switch c.GetType() {
+			case "added":
+				line = fmt.Sprintf("%s %s", style.RenderSuccess("+ "+c.GetPath()+":"), c.GetNew())
+			case "removed":
+				line = fmt.Sprintf("%s %s", style.RenderError("- "+c.GetPath()+":"), c.GetOld())
+			default:
+				line = fmt.Sprintf("%s %s -> %s", style.Emph("~ "+c.GetPath()+":"), c.GetOld(), c.GetNew())
+			}
+			out += style.Item(line)
+		}
+	}
+	return out
+}
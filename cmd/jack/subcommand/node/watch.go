@@ -0,0 +1,141 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// watch redraws the list of the nodes on each change streamed by the manager, until interrupted.
+//
+// The JSON output prints the changes instead, one per line. The list is refreshed every CLIWatchInterval if the
+// manager does not stream the changes. The candidates appearing while watching are highlighted, to be accepted.
+func watch(verbose bool) error {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewAPIClient(conn)
+
+	stream, err := client.WatchNodes(context.Background(), &emptypb.Empty{})
+	if err == nil {
+		// the changes before the headers are not streamed, the list is drawn after them
+		_, err = stream.Header()
+	}
+	candidates := &candidateTracker{}
+	if status.Code(err) == codes.Unimplemented {
+		return poll(verbose, candidates)
+	}
+	if err != nil {
+		return errors.New(status.Convert(err).Message())
+	}
+
+	jsonFormat := option.GetJSONFormat()
+	if !jsonFormat {
+		if err := redraw(verbose, candidates); err != nil {
+			return err
+		}
+	}
+	for {
+		e, err := stream.Recv()
+		switch {
+		case errors.Is(err, io.EOF):
+			return errors.New("the manager closed the stream")
+		case status.Code(err) == codes.Unimplemented:
+			return poll(verbose, candidates)
+		case err != nil:
+			return errors.New(status.Convert(err).Message())
+		}
+
+		if jsonFormat {
+			line, err := serializer.JSON.Marshal(e)
+			if err != nil {
+				return fmt.Errorf("failed to serialize the change in JSON: %w", err)
+			}
+			fmt.Println(string(line))
+			continue
+		}
+		if err := redraw(verbose, candidates); err != nil {
+			return err
+		}
+	}
+}
+
+// poll redraws the list of the nodes every CLIWatchInterval, until interrupted.
+func poll(verbose bool, candidates *candidateTracker) error {
+	t := time.NewTicker(config.CLIWatchInterval)
+	defer t.Stop()
+	for {
+		if err := redraw(verbose, candidates); err != nil {
+			return err
+		}
+		<-t.C
+	}
+}
+
+// redraw prints the list of the nodes, in place of the previous one if stdout is a terminal.
+func redraw(verbose bool, candidates *candidateTracker) error {
+	resp, err := list(proto.Filter_NONE)
+	if err != nil {
+		return err
+	}
+
+	if option.GetJSONFormat() {
+		result, err := serializer.JSON.Marshal(resp)
+		if err != nil {
+			return fmt.Errorf("failed to serialize response in JSON: %w", err)
+		}
+		fmt.Println(string(result))
+		return nil
+	}
+
+	if style.IsTerminal(os.Stdout) {
+		fmt.Print("\033[H\033[2J") // top left, then clear the screen
+	}
+	newCandidates := candidates.update(resp)
+	in := style.Subtitle("updated at " + time.Now().Format(time.TimeOnly))
+	in += prettyNodesSprint(resp, verbose, newCandidates)
+	if len(newCandidates) > 0 {
+		in += style.Subtitle(fmt.Sprintf("%d new node(s) waiting for their acceptance: jack nodes accept NODE", len(newCandidates)))
+	}
+	style.PrettyPrint(in)
+	return nil
+}
+
+// candidateTracker finds the candidates which were not listed when the watch started.
+type candidateTracker struct {
+	initial map[string]bool // nil before the first list
+}
+
+// update returns the candidates of the list which were not in the first one.
+func (c *candidateTracker) update(resp *proto.ListNodesResponse) map[string]bool {
+	if c.initial == nil {
+		c.initial = make(map[string]bool)
+		for _, nd := range resp.GetCandidates() {
+			c.initial[nd.GetId()] = true
+		}
+		return nil
+	}
+
+	newCandidates := make(map[string]bool)
+	for _, nd := range resp.GetCandidates() {
+		if !c.initial[nd.GetId()] {
+			newCandidates[nd.GetId()] = true
+		}
+	}
+	return newCandidates
+}
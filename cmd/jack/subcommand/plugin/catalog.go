@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/autocompletion"
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"github.com/spf13/cobra"
+)
+
+// CatalogCommand lists the tasks of the plugins available on the manager, jack catalog.
+func CatalogCommand() *cobra.Command {
+	var schema bool
+	cmd := &cobra.Command{
+		Use:   "catalog [PLUGIN[.TASK]]",
+		Short: "list the tasks of the plugins available on the manager",
+		Long: `List the tasks of the built-in plugins and of the plugins of the manager plugin directory.
+With --schema, it prints the JSON Schema of the inputs of each task (positional args and options), e.g. to
+generate input forms.`,
+		GroupID: "operations",
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var pluginName, taskName string
+			if len(args) > 0 {
+				pluginName, taskName, _ = strings.Cut(args[0], config.PluginSeparator)
+			}
+
+			if schema {
+				schemas := filterCatalog(autocompletion.GetInputSchemas(), pluginName, taskName)
+				result, err := serializer.JSON.MarshalIndent(schemas, "", "   ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "failed to serialize the schemas in JSON: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(result))
+				return
+			}
+
+			catalog := make(map[string]map[string]string)
+			for name, info := range autocompletion.GetAvailablePlugins() {
+				catalog[name] = make(map[string]string)
+				for task, taskInfo := range info.Tasks {
+					catalog[name][task] = taskInfo.Summary
+				}
+			}
+			catalog = filterCatalog(catalog, pluginName, taskName)
+
+			if option.GetJSONFormat() {
+				result, err := serializer.JSON.MarshalIndent(catalog, "", "   ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "failed to serialize response in JSON: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(result))
+				return
+			}
+
+			style.PrettyPrint(prettyCatalogSprint(catalog))
+		},
+	}
+	cmd.Flags().BoolVar(&schema, "schema", false, "print the JSON Schema of the inputs of the tasks")
+
+	return cmd
+}
+
+// filterCatalog keeps the plugin and the task, if set.
+func filterCatalog[T any](catalog map[string]map[string]T, pluginName, taskName string) map[string]map[string]T {
+	if pluginName == "" {
+		return catalog
+	}
+	tasks, ok := catalog[pluginName]
+	if !ok {
+		return map[string]map[string]T{}
+	}
+	if taskName == "" {
+		return map[string]map[string]T{pluginName: tasks}
+	}
+	if task, ok := tasks[taskName]; ok {
+		return map[string]map[string]T{pluginName: {taskName: task}}
+	}
+	return map[string]map[string]T{}
+}
+
+func prettyCatalogSprint(catalog map[string]map[string]string) string {
+	if len(catalog) == 0 {
+		return style.Item(style.RenderUnknown("no plugin found"))
+	}
+
+	out := ""
+	for _, name := range slices.Sorted(maps.Keys(catalog)) {
+		out += style.Title(name)
+		for _, task := range slices.Sorted(maps.Keys(catalog[name])) {
+			out += style.Item(catalog[name][task])
+		}
+	}
+	return out
+}
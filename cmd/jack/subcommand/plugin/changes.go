@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+)
+
+type pluginChange struct {
+	Type string `jackadi:"type"`
+	Name string `jackadi:"name"`
+	File string `jackadi:"file,omitempty"`
+}
+
+type pluginSync struct {
+	Time    time.Time      `jackadi:"time"`
+	Changes []pluginChange `jackadi:"changes"`
+	Error   string         `jackadi:"error,omitempty"`
+}
+
+func changesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "changes NODE",
+		Short: "show the recent plugin changes of a node",
+		Long: `Show the recent plugin syncs of a node which changed its plugins or failed, the oldest first.
+The syncs, on request or periodic, are reported by the node and kept by the manager since its start.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			resp, err := pluginSyncs(args[0])
+			if err != nil {
+				connection.Exit(err)
+			}
+
+			if option.GetJSONFormat() {
+				syncs := []pluginSync{}
+				for _, s := range resp.GetSyncs() {
+					sync := pluginSync{Time: s.GetTime().AsTime(), Changes: []pluginChange{}, Error: s.GetError()}
+					for _, c := range s.GetChanges() {
+						sync.Changes = append(sync.Changes, pluginChange{Type: c.GetType(), Name: c.GetName(), File: c.GetFile()})
+					}
+					syncs = append(syncs, sync)
+				}
+				result, err := serializer.JSON.MarshalIndent(syncs, "", "   ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "failed to serialize response in JSON: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(result))
+				return
+			}
+
+			style.PrettyPrint(prettyChangesSprint(resp))
+		},
+	}
+
+	return cmd
+}
+
+func pluginSyncs(nd string) (*proto.PluginSyncsResponse, error) {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewAPIClient(conn)
+
+	ctxReq, cancel := connection.RequestContext()
+	defer cancel()
+
+	resp, err := client.PluginSyncs(ctxReq, &proto.PluginSyncsRequest{Node: nd})
+	if err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+	return resp, nil
+}
+
+func prettyChangesSprint(resp *proto.PluginSyncsResponse) string {
+	if len(resp.GetSyncs()) == 0 {
+		return style.Item(style.RenderUnknown("no change"))
+	}
+
+	out := ""
+	for _, s := range resp.GetSyncs() {
+		out += style.Title(s.GetTime().AsTime().Local().Format(time.DateTime))
+		for _, c := range s.GetChanges() {
+			var line string
+			switch c.GetType() {
+			case "added":
+				line = style.RenderSuccess("+ " + c.GetName())
+			case "updated":
+				line = style.Emph("~ " + c.GetName())
+			case "deleted":
+				line = style.RenderError("- " + c.GetName())
+			default:
+				line = style.RenderError("! " + c.GetName() + " (not allowed by the node)")
+			}
+			out += style.Item(line)
+		}
+		if s.GetError() != "" {
+			out += style.Item(style.RenderError(s.GetError()))
+		}
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/autocompletion"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/clientgen"
+	"github.com/spf13/cobra"
+)
+
+// GenCommand generates code from the plugins available on the manager, jack gen.
+func GenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "gen",
+		Short:   "generate code from the plugins available on the manager",
+		GroupID: "operations",
+	}
+	cmd.AddCommand(genClientCommand())
+	return cmd
+}
+
+func genClientCommand() *cobra.Command {
+	var pkg, output string
+	cmd := &cobra.Command{
+		Use:   "client PLUGIN",
+		Short: "generate the typed Go client of a plugin",
+		Long: `Generate a Go client with a method per task of the plugin, taking the typed arguments and options of the
+task instead of the arguments of jack run, from the JSON Schema of their inputs (jack catalog --schema).
+The client runs the tasks with github.com/jackadi-io/jackadi/client:
+
+	c, err := client.New()
+	...
+	results, err := demo.New(c).CreateUser(ctx, "web-*", client.Glob, 12345, "johndoe", ...)`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := genClient(args[0], pkg, output); err != nil {
+				fmt.Fprintln(os.Stderr, style.RenderError(err.Error()))
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&pkg, "package", "", "package of the generated client, the plugin name by default")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file of the generated client, stdout by default")
+
+	return cmd
+}
+
+// genClient writes the client of the plugin to the output file, or to stdout if empty. The package is named after
+// the plugin if not set.
+func genClient(plugin, pkg, output string) error {
+	schemas, ok := autocompletion.GetInputSchemas()[plugin]
+	if !ok {
+		return fmt.Errorf("no input schema for the plugin %s: unknown plugin, or built with an older SDK", plugin)
+	}
+	if pkg == "" {
+		pkg = strings.ToLower(strings.NewReplacer("-", "", "_", "", ".", "").Replace(plugin))
+	}
+
+	src, err := clientgen.Generate(plugin, pkg, schemas)
+	if err != nil {
+		return fmt.Errorf("failed to generate the client: %w", err)
+	}
+	if output == "" {
+		fmt.Print(string(src))
+		return nil
+	}
+	return os.WriteFile(output, src, 0644) //nolint:gosec // source code, not a secret
+}
@@ -0,0 +1,16 @@
+package plugin
+
+import "github.com/spf13/cobra"
+
+func Root() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "plugins [OPTION] ...",
+		Short:   "manage the plugins of the nodes",
+		GroupID: "operations",
+	}
+
+	cmd.AddCommand(syncCommand())
+	cmd.AddCommand(changesCommand())
+
+	return cmd
+}
@@ -0,0 +1,158 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/cmd/jack/style"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type pluginInfo struct {
+	Name string `jackadi:"Name"`
+	File string `jackadi:"File,omitempty"` // Set if different from the name.
+}
+
+// pluginChanges is the output of the plugins.sync task.
+type pluginChanges struct {
+	Added     []pluginInfo `jackadi:"Added,omitempty"`
+	Unchanged []pluginInfo `jackadi:"Unchanged,omitempty"`
+	Deleted   []pluginInfo `jackadi:"Deleted,omitempty"`
+	Updated   []pluginInfo `jackadi:"Updated,omitempty"`
+	Rejected  []pluginInfo `jackadi:"Rejected,omitempty"`
+}
+
+// syncResult is the result of the sync of a node.
+type syncResult struct {
+	Changes pluginChanges `jackadi:"changes"`
+	Error   string        `jackadi:"error,omitempty"`
+}
+
+func syncCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync TARGET",
+		Short: "synchronize the plugins of the nodes with the manager now",
+		Long: `Synchronize the plugins of the nodes matching the TARGET glob with the manager, without waiting for their
+periodic sync (plugin-sync-interval), and show the changes. It runs the plugins.sync builtin task, with an exclusive
+lock on each node.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			results, err := syncPlugins(args[0])
+			if err != nil {
+				connection.Exit(err)
+			}
+
+			if option.GetJSONFormat() {
+				out, err := serializer.JSON.MarshalIndent(results, "", "   ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "failed to serialize response in JSON: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(out))
+			} else {
+				style.PrettyPrint(prettySyncSprint(results))
+			}
+
+			for _, r := range results {
+				if r.Error != "" {
+					os.Exit(1)
+				}
+			}
+		},
+	}
+
+	return cmd
+}
+
+// syncPlugins runs plugins.sync on the nodes matching the target, and returns their results by node.
+func syncPlugins(target string) (map[string]syncResult, error) {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewForwarderClient(conn)
+
+	req := &proto.TaskRequest{
+		Target:     target,
+		TargetMode: proto.TargetMode_GLOB,
+		Plugin:     "plugins",
+		Task:       "sync",
+		Input:      &proto.Input{Args: &structpb.ListValue{}},
+		Timeout:    uint32(config.TaskTimeout.Seconds()),
+	}
+
+	ctxReq, cancel := connection.RequestContext()
+	defer cancel()
+	resp, err := client.ExecTask(ctxReq, req)
+	if err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+	if len(resp.GetResponses()) == 0 {
+		return nil, fmt.Errorf("no node matching '%s'", target)
+	}
+
+	results := make(map[string]syncResult, len(resp.GetResponses()))
+	for nd, r := range resp.GetResponses() {
+		result := syncResult{Error: r.GetError()}
+		if r.GetInternalError() != proto.InternalError_OK {
+			result.Error = fmt.Sprintf("%s %s", r.GetInternalError(), r.GetModuleError())
+		}
+		if len(r.GetOutput()) > 0 {
+			if err := serializer.JSON.Unmarshal(r.GetOutput(), &result.Changes); err != nil {
+				result.Error = fmt.Sprintf("invalid changes: %s", err)
+			}
+		}
+		results[nd] = result
+	}
+	return results, nil
+}
+
+func prettySyncSprint(results map[string]syncResult) string {
+	out := ""
+	for _, nd := range slices.Sorted(maps.Keys(results)) {
+		r := results[nd]
+		out += style.Title(nd)
+
+		lines := []string{}
+		for _, p := range r.Changes.Added {
+			lines = append(lines, style.RenderSuccess("+ "+p.String()))
+		}
+		for _, p := range r.Changes.Updated {
+			lines = append(lines, style.Emph("~ "+p.String()))
+		}
+		for _, p := range r.Changes.Deleted {
+			lines = append(lines, style.RenderError("- "+p.String()))
+		}
+		for _, p := range r.Changes.Rejected {
+			lines = append(lines, style.RenderError("! "+p.String()+" (not allowed by the node)"))
+		}
+		if len(lines) == 0 && r.Error == "" {
+			lines = append(lines, style.RenderUnknown(fmt.Sprintf("no change (%d plugins up to date)", len(r.Changes.Unchanged))))
+		}
+		if r.Error != "" {
+			lines = append(lines, style.RenderError(r.Error))
+		}
+		for _, line := range lines {
+			out += style.Item(line)
+		}
+	}
+	return out
+}
+
+func (p pluginInfo) String() string {
+	if p.File == "" {
+		return p.Name
+	}
+	return fmt.Sprintf("%s (%s)", p.Name, p.File)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jackadi-io/jackadi/cmd/jack/connection"
+	"github.com/jackadi-io/jackadi/cmd/jack/option"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func sprintVersion() string {
+	v := version
+	if v != "dev" {
+		v = fmt.Sprintf("v%s", v)
+	}
+	return fmt.Sprintf("%s (commit: %s, build date: %s)\n", v, commit, date)
+}
+
+func versionCommand() *cobra.Command {
+	var withServer bool
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "print the version",
+		Annotations: map[string]string{
+			"commandType": "main",
+		},
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !withServer {
+				fmt.Print(sprintVersion())
+				return
+			}
+
+			resp, err := serverInfo()
+			if err != nil {
+				connection.Exit(err)
+			}
+
+			if option.GetJSONFormat() {
+				result, err := serializer.JSON.MarshalIndent(resp, "", "   ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "failed to serialize response in JSON: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(result))
+				return
+			}
+
+			serverVersion := resp.GetVersion()
+			if serverVersion != "dev" {
+				serverVersion = fmt.Sprintf("v%s", serverVersion)
+			}
+			uptime := time.Duration(resp.GetUptime()) * time.Second
+			fmt.Printf("client: %s", sprintVersion())
+			fmt.Printf("server: %s (commit: %s, build date: %s, uptime: %s)\n", serverVersion, resp.GetCommit(), resp.GetBuildDate(), uptime)
+		},
+	}
+	cmd.Flags().BoolVar(&withServer, "server", false, "also print the version of the manager")
+
+	return cmd
+}
+
+func serverInfo() (*proto.ServerInfoResponse, error) {
+	conn, err := connection.DialCLI()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect the manager: %w", err)
+	}
+	defer conn.Close()
+	client := proto.NewAPIClient(conn)
+
+	ctxReq, cancel := connection.RequestContext()
+	defer cancel()
+
+	resp, err := client.ServerInfo(ctxReq, &emptypb.Empty{})
+	if err != nil {
+		return nil, errors.New(status.Convert(err).Message())
+	}
+	return resp, nil
+}
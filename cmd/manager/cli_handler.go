@@ -1,20 +1,26 @@
 package main
 
 import (
+	"crypto/tls"
 	"fmt"
+	"io/fs"
 	"net"
 	"os"
+	"os/user"
 	"path/filepath"
+	"strconv"
 
 	"github.com/jackadi-io/jackadi/internal/config"
+	"google.golang.org/grpc/credentials"
 )
 
 type closeFunc func()
 
-func NewCLIListener() (net.Listener, closeFunc, error) {
-	_ = os.MkdirAll(filepath.Dir(config.CLISocket), 0755)
+// NewCLIListener listens on the CLI unix socket, restricted to its owner and, if set, to the members of group.
+func NewCLIListener(socket string, mode fs.FileMode, group string) (net.Listener, closeFunc, error) {
+	_ = os.MkdirAll(filepath.Dir(socket), 0755)
 
-	listener, err := net.Listen("unix", config.CLISocket)
+	listener, err := net.Listen("unix", socket)
 	if err != nil {
 		return nil, func() {}, fmt.Errorf("failed to connect to CLI socket: %w", err)
 	}
@@ -23,10 +29,49 @@ func NewCLIListener() (net.Listener, closeFunc, error) {
 		_ = listener.Close()
 	}
 
-	if err := os.Chmod(config.CLISocket, 0700); err != nil {
+	if err := secureSocket(socket, mode, group); err != nil {
 		closeFunc() // for close to avoid users forgetting to do so because of the error
 		return nil, func() {}, fmt.Errorf("failed to secure CLI socket: %w", err)
 	}
 
 	return listener, closeFunc, nil
 }
+
+// secureSocket sets the group and then the permissions of the socket, so that the group members are never
+// granted access to a socket of another group.
+func secureSocket(socket string, mode fs.FileMode, group string) error {
+	if err := os.Chmod(socket, mode&0o700); err != nil {
+		return err
+	}
+
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			return err
+		}
+		gid, err := strconv.Atoi(g.Gid)
+		if err != nil {
+			return fmt.Errorf("invalid gid of group '%s': %w", group, err)
+		}
+		if err := os.Chown(socket, -1, gid); err != nil {
+			return err
+		}
+	}
+
+	return os.Chmod(socket, mode)
+}
+
+// NewRemoteCLICredentials returns the mTLS credentials of the remote CLI listener, accepting the clients with a
+// certificate issued by the client CA.
+func NewRemoteCLICredentials(cfg config.CLIRemoteConfig) (credentials.TransportCredentials, error) {
+	certs, ca, err := config.GetMTLSCertificate(cfg.Cert, cfg.Key, cfg.ClientCA)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(&tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		Certificates: certs,
+		ClientCAs:    ca,
+	}), nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/fs"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestNewCLIListener_Permissions(t *testing.T) {
+	for _, mode := range []fs.FileMode{0o700, 0o770, 0o750} {
+		t.Run(mode.String(), func(t *testing.T) {
+			socket := filepath.Join(t.TempDir(), "run", "manager.sock")
+
+			_, closeListener, err := NewCLIListener(socket, mode, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer closeListener()
+
+			info, err := os.Stat(socket)
+			if err != nil {
+				t.Fatalf("socket not created: %v", err)
+			}
+			if info.Mode()&fs.ModeSocket == 0 {
+				t.Errorf("expected a socket, got %v", info.Mode())
+			}
+			if got := info.Mode().Perm(); got != mode {
+				t.Errorf("expected permissions %v, got %v", mode, got)
+			}
+
+			conn, err := net.Dial("unix", socket)
+			if err != nil {
+				t.Fatalf("owner cannot connect: %v", err)
+			}
+			_ = conn.Close()
+		})
+	}
+}
+
+func TestNewCLIListener_Group(t *testing.T) {
+	g, err := user.LookupGroupId(strconv.Itoa(os.Getgid()))
+	if err != nil {
+		t.Skipf("unable to find the current group: %v", err)
+	}
+	socket := filepath.Join(t.TempDir(), "manager.sock")
+
+	_, closeListener, err := NewCLIListener(socket, 0o770, g.Name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer closeListener()
+
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatalf("socket not created: %v", err)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		t.Skip("socket owner not available on this platform")
+	}
+	if strconv.Itoa(int(stat.Gid)) != g.Gid {
+		t.Errorf("expected the socket owned by group %s, got gid %d", g.Gid, stat.Gid)
+	}
+}
+
+func TestNewCLIListener_UnknownGroup(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "manager.sock")
+
+	_, closeListener, err := NewCLIListener(socket, 0o770, "jackadi-unknown-group")
+	defer closeListener()
+	if err == nil {
+		t.Fatal("expected an error for an unknown group")
+	}
+	if _, err := net.Dial("unix", socket); err == nil {
+		t.Error("the socket must be closed when it cannot be secured")
+	}
+}
@@ -5,24 +5,28 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
-	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"github.com/dgraph-io/badger/v4"
 	"github.com/jackadi-io/jackadi/internal/api"
 	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/helper"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
 	"github.com/jackadi-io/jackadi/internal/manager/forwarder"
 	"github.com/jackadi-io/jackadi/internal/manager/inventory"
 	"github.com/jackadi-io/jackadi/internal/manager/management"
+	"github.com/jackadi-io/jackadi/internal/manager/notification"
 	"github.com/jackadi-io/jackadi/internal/manager/server"
+	"github.com/jackadi-io/jackadi/internal/manager/syndic"
+	"github.com/jackadi-io/jackadi/internal/node"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	flag "github.com/spf13/pflag"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 
-	_ "github.com/jackadi-io/jackadi/internal/logs"
+	"github.com/jackadi-io/jackadi/internal/logs"
 )
 
 var version = "dev"
@@ -40,49 +44,117 @@ type managerConfig struct {
 	configDir        string
 	listenAddress    string
 	listenPort       string
-	pluginDir        string
+	pluginDirs       []string
 	pluginServerPort string
+	pluginServerTLS  config.ManagerPluginServerTLSConfig
 	autoAcceptNode   bool
+	autoAccept       config.AutoAcceptConfig
+
+	mTLS            bool
+	mTLSRequire     bool
+	mTLSMatchID     bool
+	mTLSCert        string
+	mTLSKey         string
+	mTLSNodeCA      string
+	mTLSSPIFFE      config.SPIFFEConfig
+	keepalive       config.ManagerKeepaliveConfig
+	compression     bool
+	degradedStorage bool
+	apiEnabled      bool
+	apiAddress      string
+	apiPort         string
+	apiTLSEnabled   bool
+	apiTLSCert      string
+	apiTLSKey       string
+
+	maxInflight         int
+	nodeActiveThreshold time.Duration
+	nodeEventDebounce   time.Duration
+	heartbeatInterval   time.Duration
+	heartbeatTimeout    time.Duration
+	responseGrace       time.Duration
+	acceptanceWait      time.Duration
+	nodeLimits          []config.NodeLimitsConfig
+	specsDriftIgnore    []string
+
+	cli      config.CLIConfig
+	webhooks []config.WebhookConfig
+	upstream config.UpstreamConfig
+}
+
+// relay holds the services of the relay gRPC servers, shared by the local and the remote CLI listeners.
+type relay struct {
+	forwarder  *forwarder.GRPCForwarder
+	api        proto.APIServer
+	reflection bool // Register the gRPC reflection service.
+}
+
+func newRelay(clusterServer *server.Server, dis forwarder.Dispatcher[*proto.TaskRequest, *proto.TaskResponse], db database.ResultStore, gc *database.GarbageCollector, notifier *notification.Dispatcher, ids *database.Sequence, responseGrace time.Duration) relay {
+	locks := forwarder.NewLockTracker()
+	fwd := forwarder.New(dis, db)
+	fwd.SetIDSequence(ids)
+	fwd.SetNotifier(notifier)
+	fwd.SetLockTracker(locks)
+	fwd.SetResponseGrace(responseGrace)
+
+	apiServer := management.New(clusterServer, db)
+	apiServer.SetGarbageCollector(gc)
+	apiServer.SetLockTracker(locks)
+	apiServer.SetBuildInfo(management.BuildInfo{Version: version, Commit: commit, Date: date})
+
+	return relay{forwarder: &fwd, api: &apiServer}
+}
 
-	mTLS          bool
-	mTLSCert      string
-	mTLSKey       string
-	mTLSNodeCA    string
-	apiEnabled    bool
-	apiAddress    string
-	apiPort       string
-	apiTLSEnabled bool
-	apiTLSCert    string
-	apiTLSKey     string
+// NewGRPCServer creates a new GRPC server to serve both CLI and Web API.
+func (r relay) NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	grpcServer := grpc.NewServer(opts...)
+	proto.RegisterForwarderServer(grpcServer, r.forwarder)
+	proto.RegisterAPIServer(grpcServer, r.api)
+	if r.reflection {
+		reflection.Register(grpcServer)
+	}
+	return grpcServer
 }
 
-func dbGC(ctx context.Context, db *badger.DB) {
-	ticker := time.NewTicker(config.DatabaseGCInterval)
-	defer ticker.Stop()
+// watchNodeEvents logs the node activity changes, specs drifts, auto-acceptances and new candidates, and forwards them to the webhooks until the context is cancelled.
+func watchNodeEvents(ctx context.Context, events <-chan inventory.NodeEvent, notifier *notification.Dispatcher) {
 	for {
 		select {
-		case <-ticker.C:
-			err := db.RunValueLogGC(config.DBGCThreshold)
-			if err != nil {
-				slog.Warn("database GC failed", "error", err)
+		case e := <-events:
+			switch e.Type {
+			case inventory.NodeStale:
+				slog.Warn("node is stale", "node", e.Node, "last message", e.LastMsg)
+			case inventory.NodeSpecsDrift:
+				slog.Info("node specs changed", "node", e.Node, "changes", len(e.Changes))
+			case inventory.NodeAutoAccepted:
+				slog.Warn("node auto-accepted", "node", e.Node, "address", e.Address, "fingerprint", e.Fingerprint)
+			case inventory.NodeCandidate:
+				slog.Warn("new node waiting for its acceptance", "node", e.Node, "address", e.Address)
+			default:
+				slog.Info("node is active again", "node", e.Node)
 			}
+			notifier.NotifyNode(e)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-// NewRelayGRPCServer creates a new GRPC server to serve both CLI and Web API.
-func NewRelayGRPCServer(clusterServer *server.Server, dis forwarder.Dispatcher[*proto.TaskRequest, *proto.TaskResponse], db *badger.DB) *grpc.Server {
-	var opts []grpc.ServerOption
-	grpcServer := grpc.NewServer(opts...)
-	fwd := forwarder.New(dis, db)
-	proto.RegisterForwarderServer(grpcServer, &fwd)
-
-	apiServer := management.New(clusterServer, db)
-	proto.RegisterAPIServer(grpcServer, &apiServer)
-
-	return grpcServer
+// upstreamNodeConfig returns the configuration of the connection to the upstream manager, as a node.
+func upstreamNodeConfig(upstream config.UpstreamConfig) node.Config {
+	return node.Config{
+		ManagerAddress:     upstream.Address,
+		ManagerPort:        upstream.Port,
+		NodeID:             upstream.ID,
+		MTLSEnabled:        upstream.MTLS.Enabled,
+		MTLSCert:           upstream.MTLS.Cert,
+		MTLSKey:            upstream.MTLS.Key,
+		MTLSManagerCA:      upstream.MTLS.ManagerCA,
+		MTLSSPIFFE:         upstream.MTLS.SPIFFE,
+		MaxConcurrentTasks: upstream.MaxRuns,
+		Keepalive:          upstream.Keepalive.ClientParameters(),
+		Version:            version,
+	}
 }
 
 func run(cfg managerConfig) error {
@@ -90,31 +162,59 @@ func run(cfg managerConfig) error {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM)
 
-	dbOptions := badger.
-		DefaultOptions(config.DatabaseDir).
-		WithLogger(slogBadgerAdapter{})
-
-	db, err := badger.Open(dbOptions)
+	store, closeStore, err := openResultStore(config.DatabaseDir, cfg.degradedStorage)
 	if err != nil {
 		return err
 	}
-	defer db.Close()
+	defer closeStore()
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	go dbGC(ctx, db)
+	var gc *database.GarbageCollector
+	if db, ok := writableDB(store); ok {
+		gc = database.NewGarbageCollector(db)
+		go gc.Run(ctx)
+	} else {
+		go logs.RepeatWarn(ctx, config.DegradedWarningInterval, "degraded storage, the task results are not stored")
+	}
 
 	nodesInventory := inventory.New()
+	if cfg.nodeActiveThreshold > 0 {
+		nodesInventory.SetActiveThreshold(cfg.nodeActiveThreshold)
+	}
+	nodesInventory.SetEventDebounce(cfg.nodeEventDebounce)
+	nodesInventory.SetSpecsDriftIgnore(cfg.specsDriftIgnore)
 	if err := nodesInventory.LoadRegistry(); err != nil {
 		slog.Info("unable to load registry", "error", err)
 	}
 	taskDispatcher := forwarder.NewDispatcher[*proto.TaskRequest, *proto.TaskResponse](&nodesInventory)
 
 	// start manager main instance
-	managerInstance, err := newManager(cfg, &nodesInventory, taskDispatcher, db)
+	managerInstance, err := newManager(cfg, &nodesInventory, taskDispatcher, store)
 	if err != nil {
 		return err
 	}
 	defer managerInstance.Close()
+
+	// the group IDs of the forwarder and the result IDs of the server share the key space of the results
+	ids := &database.Sequence{}
+	managerInstance.ClusterServer.SetIDSequence(ids)
+
+	if !cfg.mTLS {
+		go logs.RepeatWarn(ctx, config.InsecureWarningInterval, "mTLS is disabled, connections to nodes are unsafe")
+	}
+
+	var notifier *notification.Dispatcher
+	if len(cfg.webhooks) > 0 {
+		notifier = notification.NewDispatcher(cfg.webhooks)
+		managerInstance.ClusterServer.SetNotifier(notifier)
+		go notifier.Run(ctx)
+		slog.Info("webhook notifications enabled", "webhooks", len(cfg.webhooks))
+	}
+
+	nodeEvents := nodesInventory.Subscribe(config.NotificationQueueSize)
+	go nodesInventory.WatchActivity(ctx, config.NodeActivityCheckDelay)
+	go watchNodeEvents(ctx, nodeEvents, notifier)
+
 	go func() {
 		if err := managerInstance.Serve(); err != nil {
 			slog.Error("manager failed to start", "error", err)
@@ -134,16 +234,15 @@ func run(cfg managerConfig) error {
 	}()
 
 	// start plugin server
-	pluginDir := http.Dir(cfg.pluginDir)
-	fs := http.FileServer(pluginDir)
-	mux := http.NewServeMux()
-	mux.Handle("GET "+config.PluginServerPath, http.StripPrefix(config.PluginServerPath, fs))
-
-	socket := net.JoinHostPort(cfg.listenAddress, cfg.pluginServerPort)
-	httpServer := http.Server{Addr: socket, Handler: mux, ReadHeaderTimeout: config.HTTPReadHeaderTimeout}
+	socket := helper.JoinHostPort(cfg.listenAddress, cfg.pluginServerPort)
+	httpServer := newPluginServer(socket, cfg.pluginDirs)
+	pluginListener, err := net.Listen("tcp", socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen for the plugin server: %w", err)
+	}
 	go func() {
-		slog.Info("Starting static webserver", "socket", socket)
-		err = httpServer.ListenAndServe()
+		slog.Info("Starting static webserver", "socket", socket, "tls", cfg.pluginServerTLS.Enabled)
+		err = servePlugins(httpServer, pluginListener, cfg.pluginServerTLS)
 		if err != nil {
 			slog.Error("http server stopped", "error", err)
 			closeCh <- struct{}{}
@@ -151,21 +250,44 @@ func run(cfg managerConfig) error {
 	}()
 
 	// GPRC server to handle CLI and API requests
-	relayGRPCServer := NewRelayGRPCServer(managerInstance.ClusterServer, taskDispatcher, db)
+	relayServices := newRelay(managerInstance.ClusterServer, taskDispatcher, store, gc, notifier, ids, cfg.responseGrace)
+	relayServices.reflection = cfg.cli.Reflection
+	relayGRPCServer := relayServices.NewGRPCServer()
 	defer func() {
 		if relayGRPCServer != nil {
 			relayGRPCServer.Stop()
 		}
 	}()
 
-	cliListener, closeCliListener, err := NewCLIListener()
+	// run the scheduled requests once due (jack run --at)
+	go relayServices.forwarder.RunPending(ctx)
+
+	// dispatch the runs of the upstream manager, as a syndic
+	if cfg.upstream.Enabled {
+		if !cfg.upstream.MTLS.Enabled {
+			go logs.RepeatWarn(ctx, config.InsecureWarningInterval, "mTLS is disabled, the connection to the upstream manager is unsafe")
+		}
+		go func() {
+			s := syndic.New(relayServices.forwarder)
+			if err := s.Run(ctx, upstreamNodeConfig(cfg.upstream), time.Duration(cfg.upstream.ReconnectDelay)*time.Second); err != nil {
+				slog.Error("syndic stopped", "error", err)
+				closeCh <- struct{}{}
+			}
+		}()
+	}
+
+	socketMode, err := cfg.cli.SocketFileMode()
+	if err != nil {
+		return err
+	}
+	cliListener, closeCliListener, err := NewCLIListener(config.CLISocket, socketMode, cfg.cli.SocketGroup)
 	defer closeCliListener()
 	if err != nil {
 		return err
 	}
 
 	// server CLI
-	slog.Info("starting local gRPC server for CLI")
+	slog.Info("starting local gRPC server for CLI", "socket", config.CLISocket, "mode", socketMode, "group", cfg.cli.SocketGroup)
 	go func() {
 		if err = relayGRPCServer.Serve(cliListener); err != nil {
 			slog.Error("gRPC local server stopped", "reason", err)
@@ -173,6 +295,29 @@ func run(cfg managerConfig) error {
 		}
 	}()
 
+	// remote CLI, using mTLS
+	if cfg.cli.Remote.Enabled {
+		creds, err := NewRemoteCLICredentials(cfg.cli.Remote)
+		if err != nil {
+			return fmt.Errorf("failed to load the remote CLI certificates: %w", err)
+		}
+		target := helper.JoinHostPort(cfg.cli.Remote.Address, cfg.cli.Remote.Port)
+		remoteListener, err := net.Listen("tcp", target)
+		if err != nil {
+			return fmt.Errorf("failed to start the remote CLI listener: %w", err)
+		}
+		remoteGRPCServer := relayServices.NewGRPCServer(grpc.Creds(creds))
+		defer remoteGRPCServer.Stop()
+
+		slog.Info("starting remote gRPC server for CLI", "address", target)
+		go func() {
+			if err := remoteGRPCServer.Serve(remoteListener); err != nil {
+				slog.Error("gRPC remote server stopped", "reason", err)
+				closeCh <- struct{}{}
+			}
+		}()
+	}
+
 	// start API (HTTP proxy to gRPC)
 	if cfg.apiEnabled {
 		go func() {
@@ -233,23 +378,59 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := logs.Setup(managerCfg.Log.Level, managerCfg.Log.Format); err != nil {
+		slog.Error("failed to configure logs", "error", err)
+		os.Exit(1)
+	}
+	go logs.WatchSignal(context.Background())
+
+	created, err := managerCfg.InitPluginDirs()
+	if err != nil {
+		slog.Error("failed to initialize the plugin directories", "error", err)
+		os.Exit(1)
+	}
+	for _, dir := range created {
+		slog.Warn("missing plugin directory created, it has no plugin to serve", "path", dir)
+	}
+	slog.Info("serving plugins", "plugin_dirs", managerCfg.PluginDirs)
+
 	cfg := managerConfig{
-		listenAddress:    managerCfg.ListenAddress,
-		listenPort:       managerCfg.ListenPort,
-		pluginDir:        managerCfg.PluginDir,
-		pluginServerPort: managerCfg.PluginServerPort,
-		mTLS:             managerCfg.MTLS.Enabled,
-		mTLSKey:          managerCfg.MTLS.Key,
-		mTLSCert:         managerCfg.MTLS.Cert,
-		mTLSNodeCA:       managerCfg.MTLS.NodeCA,
-		autoAcceptNode:   managerCfg.AutoAcceptNode,
-		configDir:        managerCfg.ConfigDir,
-		apiEnabled:       managerCfg.API.Enabled,
-		apiAddress:       managerCfg.API.Address,
-		apiPort:          managerCfg.API.Port,
-		apiTLSEnabled:    managerCfg.API.TLS.Enabled,
-		apiTLSCert:       managerCfg.API.TLS.Cert,
-		apiTLSKey:        managerCfg.API.TLS.Key,
+		listenAddress:       managerCfg.ListenAddress,
+		listenPort:          managerCfg.ListenPort,
+		pluginDirs:          managerCfg.PluginDirs,
+		pluginServerPort:    managerCfg.PluginServerPort,
+		pluginServerTLS:     managerCfg.PluginServerTLS,
+		mTLS:                managerCfg.MTLS.Enabled,
+		mTLSRequire:         managerCfg.MTLS.Require,
+		mTLSMatchID:         managerCfg.MTLS.MatchNodeID,
+		mTLSKey:             managerCfg.MTLS.Key,
+		mTLSCert:            managerCfg.MTLS.Cert,
+		mTLSNodeCA:          managerCfg.MTLS.NodeCA,
+		mTLSSPIFFE:          managerCfg.MTLS.SPIFFE,
+		keepalive:           managerCfg.Keepalive,
+		compression:         managerCfg.Compression,
+		degradedStorage:     managerCfg.DegradedStorage,
+		autoAcceptNode:      managerCfg.AutoAcceptNode,
+		autoAccept:          managerCfg.AutoAccept,
+		configDir:           managerCfg.ConfigDir,
+		apiEnabled:          managerCfg.API.Enabled,
+		apiAddress:          managerCfg.API.Address,
+		apiPort:             managerCfg.API.Port,
+		apiTLSEnabled:       managerCfg.API.TLS.Enabled,
+		apiTLSCert:          managerCfg.API.TLS.Cert,
+		apiTLSKey:           managerCfg.API.TLS.Key,
+		maxInflight:         managerCfg.MaxInflight,
+		nodeActiveThreshold: time.Duration(managerCfg.Node.ActiveThreshold) * time.Second,
+		nodeEventDebounce:   time.Duration(managerCfg.Node.EventDebounce) * time.Second,
+		heartbeatInterval:   time.Duration(managerCfg.Node.HeartbeatInterval) * time.Second,
+		heartbeatTimeout:    time.Duration(managerCfg.Node.HeartbeatTimeout) * time.Second,
+		responseGrace:       time.Duration(managerCfg.Node.ResponseGrace) * time.Second,
+		acceptanceWait:      time.Duration(managerCfg.Node.AcceptanceWait) * time.Second,
+		nodeLimits:          managerCfg.Node.Limits,
+		specsDriftIgnore:    managerCfg.Node.SpecsDriftIgnore,
+		cli:                 managerCfg.CLI,
+		webhooks:            managerCfg.Notifications.Webhooks,
+		upstream:            managerCfg.Upstream,
 	}
 
 	slog.Info("jackadi manager", "version", version, "commit", commit, "build date", date)
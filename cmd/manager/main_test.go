@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/jackadi-io/jackadi/internal/manager/forwarder"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// listServices asks the relay server for its services with the reflection service.
+func listServices(t *testing.T, r relay) ([]string, error) {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	srv := r.NewGRPCServer()
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	var services []string
+	for _, s := range resp.GetListServicesResponse().GetService() {
+		services = append(services, s.GetName())
+	}
+	return services, nil
+}
+
+func TestRelayReflection(t *testing.T) {
+	r := relay{forwarder: &forwarder.GRPCForwarder{}, api: proto.UnimplementedAPIServer{}}
+
+	t.Run("disabled", func(t *testing.T) {
+		_, err := listServices(t, r)
+		if status.Code(err) != codes.Unimplemented {
+			t.Errorf("expected the reflection service to be absent, got %v", err)
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		r.reflection = true
+		services, err := listServices(t, r)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, want := range []string{"proto.Forwarder", "proto.API", "grpc.reflection.v1.ServerReflection"} {
+			if !slices.Contains(services, want) {
+				t.Errorf("expected service %s, got %v", want, services)
+			}
+		}
+	})
+}
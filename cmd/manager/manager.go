@@ -3,20 +3,22 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
 
-	"github.com/dgraph-io/badger/v4"
 	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/helper"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
 	"github.com/jackadi-io/jackadi/internal/manager/forwarder"
 	"github.com/jackadi-io/jackadi/internal/manager/inventory"
 	"github.com/jackadi-io/jackadi/internal/manager/server"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/keepalive"
 )
 
 type ManagerInstance struct {
@@ -24,6 +26,7 @@ type ManagerInstance struct {
 	grpcServer    *grpc.Server
 	listener      net.Listener
 	addr          string
+	svidSource    io.Closer // SPIFFE Workload API source, nil if unused
 }
 
 func (m *ManagerInstance) Serve() error {
@@ -44,6 +47,10 @@ func (m *ManagerInstance) Close() {
 	if m.grpcServer != nil {
 		m.grpcServer.Stop()
 	}
+
+	if m.svidSource != nil {
+		_ = m.svidSource.Close()
+	}
 }
 
 func (m *ManagerInstance) CollectNodesSpecs(ctx context.Context) error {
@@ -55,19 +62,40 @@ func (m *ManagerInstance) CollectNodesSpecs(ctx context.Context) error {
 	return nil
 }
 
-func newManager(cfg managerConfig, nodesInventory *inventory.Nodes, dis forwarder.Dispatcher[*proto.TaskRequest, *proto.TaskResponse], db *badger.DB) (*ManagerInstance, error) {
-	target := fmt.Sprint(cfg.listenAddress, ":", cfg.listenPort)
+func newManager(cfg managerConfig, nodesInventory *inventory.Nodes, dis forwarder.Dispatcher[*proto.TaskRequest, *proto.TaskResponse], db database.ResultStore) (*ManagerInstance, error) {
+	target := helper.JoinHostPort(cfg.listenAddress, cfg.listenPort)
 	lis, err := net.Listen("tcp", target)
 	if err != nil {
 		return nil, fmt.Errorf("failed to start TCP listener: %w", err)
 	}
 
 	var opts []grpc.ServerOption
-	if cfg.mTLS {
+	var svidSource io.Closer
+	var trustDomain string // set when the nodes are identified by their SPIFFE ID
+	switch {
+	case cfg.mTLS && cfg.mTLSSPIFFE.Enabled:
+		source, err := config.NewSPIFFESource(context.Background(), cfg.mTLSSPIFFE.Socket)
+		if err != nil {
+			_ = lis.Close()
+			return nil, err
+		}
+		tlsCfg, err := config.GetSPIFFEServerTLSConfig(source, cfg.mTLSSPIFFE.TrustDomain)
+		if err != nil {
+			_ = source.Close()
+			_ = lis.Close()
+			return nil, err
+		}
+		svidSource = source
+		trustDomain = cfg.mTLSSPIFFE.TrustDomain
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	case cfg.mTLS:
 		certs, ca, err := config.GetMTLSCertificate(cfg.mTLSCert, cfg.mTLSKey, cfg.mTLSNodeCA)
 		if err != nil {
 			return nil, err
 		}
+		if !cfg.mTLSMatchID {
+			slog.Info("the node certificates are not matched with the node IDs, enable it to refuse a node registering with the ID of another one", "setting", "mtls.match-node-id")
+		}
 		tlsCfg := &tls.Config{
 			MinVersion:   tls.VersionTLS12,
 			ClientAuth:   tls.RequireAndVerifyClientCert,
@@ -75,28 +103,44 @@ func newManager(cfg managerConfig, nodesInventory *inventory.Nodes, dis forwarde
 			ClientCAs:    ca,
 		}
 		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
-	} else {
-		slog.Warn("mTLS is disabled, connections to nodes are unsafe")
 	}
 
 	opts = append(opts,
-		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
-			MinTime:             config.KeepaliveMinTime, // If a client pings more than once every 5 seconds, terminate the connection
-			PermitWithoutStream: true,                    // Allow pings even when there are no active streams
-		}),
-		grpc.KeepaliveParams(keepalive.ServerParameters{
-			Time:    config.KeepaliveTime,    // Ping the client if it is idle for 5 seconds to ensure the connection is still active
-			Timeout: config.KeepaliveTimeout, // Wait 1 second for the ping ack before assuming the connection is dead
-		}),
+		grpc.KeepaliveEnforcementPolicy(cfg.keepalive.EnforcementPolicy()), // The connections of the nodes pinging too often are closed.
+		grpc.KeepaliveParams(cfg.keepalive.ServerParameters()),
 	)
+	if cfg.compression {
+		opts = append(opts, config.CompressionServerOptions()...)
+	}
+
+	var autoAcceptCAs *x509.CertPool
+	if cfg.autoAccept.CACert != "" {
+		autoAcceptCAs, err = config.LoadCertPool(cfg.autoAccept.CACert)
+		if err != nil {
+			_ = lis.Close()
+			return nil, fmt.Errorf("auto-accept CA: %w", err)
+		}
+	}
 
 	grpcServer := grpc.NewServer(opts...)
 	clusterServer := server.New(
 		server.ServerConfig{
-			AutoAccept:  cfg.autoAcceptNode,
-			MTLSEnabled: cfg.mTLS,
-			ConfigDir:   cfg.configDir,
-			PluginDir:   cfg.pluginDir,
+			AutoAccept:        cfg.autoAcceptNode,
+			AutoAcceptNodes:   cfg.autoAccept.Nodes,
+			AutoAcceptCAs:     autoAcceptCAs,
+			MTLSEnabled:       cfg.mTLS,
+			MTLSRequired:      cfg.mTLSRequire,
+			MTLSMatchNodeID:   cfg.mTLSMatchID,
+			SPIFFETrustDomain: trustDomain,
+			ConfigDir:         cfg.configDir,
+			PluginDirs:        cfg.pluginDirs,
+			MaxInflight:       cfg.maxInflight,
+			Version:           version,
+			HeartbeatInterval: cfg.heartbeatInterval,
+			HeartbeatTimeout:  cfg.heartbeatTimeout,
+			ResponseGrace:     cfg.responseGrace,
+			AcceptanceWait:    cfg.acceptanceWait,
+			NodeLimits:        cfg.nodeLimits,
 		},
 		nodesInventory,
 		dis,
@@ -109,6 +153,7 @@ func newManager(cfg managerConfig, nodesInventory *inventory.Nodes, dis forwarde
 		grpcServer:    grpcServer,
 		listener:      lis,
 		addr:          target,
+		svidSource:    svidSource,
 	}
 
 	return &m, nil
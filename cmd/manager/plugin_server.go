@@ -0,0 +1,68 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/plugin/loader/hcplugin"
+)
+
+// pluginFS serves the files of several plugin directories: a file is served from the first directory containing it.
+type pluginFS []http.Dir
+
+func (dirs pluginFS) Open(name string) (http.File, error) {
+	for _, dir := range dirs {
+		f, err := dir.Open(name)
+		if !errors.Is(err, fs.ErrNotExist) {
+			return f, err
+		}
+	}
+	return nil, fs.ErrNotExist
+}
+
+// newPluginServer returns the HTTP server of the plugin files, downloaded by the nodes.
+func newPluginServer(socket string, dirs []string) *http.Server {
+	pluginDirs := make(pluginFS, 0, len(dirs))
+	for _, dir := range dirs {
+		pluginDirs = append(pluginDirs, http.Dir(dir))
+	}
+	fileServer := http.FileServer(pluginDirs)
+	mux := http.NewServeMux()
+	mux.Handle("GET "+config.PluginServerPath, http.StripPrefix(config.PluginServerPath, fileServer))
+	mux.Handle("GET "+config.PluginServerPath+config.PluginByHashPath+"{checksum}", pluginByHash(hcplugin.NewIndex(dirs)))
+
+	return &http.Server{Addr: socket, Handler: mux, ReadHeaderTimeout: config.HTTPReadHeaderTimeout}
+}
+
+// pluginByHash serves the plugin files by checksum: the version downloaded by a node is the one listed by the
+// manager, or none if the file was replaced meanwhile.
+func pluginByHash(index *hcplugin.Index) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := index.Read(r.PathValue("checksum"))
+		if errors.Is(err, fs.ErrNotExist) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			slog.Error("failed to read plugin file", "checksum", r.PathValue("checksum"), "error", err)
+			http.Error(w, "failed to read plugin file", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(data)
+	}
+}
+
+// servePlugins serves the plugin files on the listener, over HTTPS if enabled, until the server is shut down.
+func servePlugins(srv *http.Server, lis net.Listener, tlsCfg config.ManagerPluginServerTLSConfig) error {
+	if tlsCfg.Enabled {
+		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		return srv.ServeTLS(lis, tlsCfg.Cert, tlsCfg.Key)
+	}
+	return srv.Serve(lis)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/plugin/loader/hcplugin"
+)
+
+// writeSelfSignedCert writes a self-signed certificate valid for 127.0.0.1, and its key.
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "manager"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "manager.crt"), filepath.Join(dir, "manager.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// startPluginServer serves the plugin directory over HTTPS, and returns its address.
+func startPluginServer(t *testing.T, pluginDir, certFile, keyFile string) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newPluginServer(lis.Addr().String(), []string{pluginDir})
+	tlsCfg := config.ManagerPluginServerTLSConfig{Enabled: true, Cert: certFile, Key: keyFile}
+	go func() {
+		if err := servePlugins(srv, lis, tlsCfg); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("plugin server stopped: %v", err)
+		}
+	}()
+	t.Cleanup(func() { _ = srv.Close() })
+	return lis.Addr().String()
+}
+
+func TestPluginServerTLS(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t)
+	pluginDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(pluginDir, "collector"), []byte("binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	addr := startPluginServer(t, pluginDir, certFile, keyFile)
+	checksum, err := hcplugin.CalculateChecksum(filepath.Join(pluginDir, "collector"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tlsCfg, err := config.GetPluginServerTLSConfig(certFile, "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := hcplugin.New()
+	loader.SetTLSConfig(tlsCfg)
+	tmpDir := t.TempDir()
+	if _, err := loader.DownloadPlugins(map[string]string{"collector": checksum}, addr, t.TempDir(), tmpDir); err != nil {
+		t.Fatalf("download over HTTPS failed: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(tmpDir, "collector"))
+	if err != nil || string(got) != "binary" {
+		t.Errorf("unexpected downloaded plugin: %q (%v)", got, err)
+	}
+
+	// the certificate of the manager is verified
+	untrusted, err := config.GetPluginServerTLSConfig("", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader = hcplugin.New()
+	loader.SetTLSConfig(untrusted)
+	if _, err := loader.DownloadPlugins(map[string]string{"collector": checksum}, addr, t.TempDir(), t.TempDir()); err == nil {
+		t.Error("expected an error for an untrusted plugin server certificate")
+	}
+
+	// plain HTTP is refused
+	loader = hcplugin.New()
+	if _, err := loader.DownloadPlugins(map[string]string{"collector": checksum}, addr, t.TempDir(), t.TempDir()); err == nil {
+		t.Error("expected an error when downloading over plain HTTP")
+	}
+}
+
+func TestPluginServerByHash(t *testing.T) {
+	pluginDir := t.TempDir()
+	path := filepath.Join(pluginDir, "collector")
+	if err := os.WriteFile(path, []byte("v1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	v1, err := hcplugin.CalculateChecksum(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newPluginServer("", []string{pluginDir}).Handler)
+	defer srv.Close()
+
+	get := func(checksum string) (int, string) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + config.PluginServerPath + config.PluginByHashPath + checksum)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	if code, body := get(v1); code != http.StatusOK || body != "v1" {
+		t.Errorf("unexpected response: %d %q", code, body)
+	}
+
+	// the version listed before the update is not served anymore
+	if err := os.WriteFile(path, []byte("v2"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := get(v1); code != http.StatusNotFound {
+		t.Errorf("expected 404 for a replaced version, got %d", code)
+	}
+	v2, err := hcplugin.CalculateChecksum(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, body := get(v2); code != http.StatusOK || body != "v2" {
+		t.Errorf("unexpected response: %d %q", code, body)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
+)
+
+// openResultStore opens the database storing the requests and the results.
+//
+// If it fails and degraded is set, the manager keeps dispatching the tasks: the database is opened read-only, the
+// results stored being still readable, or replaced by a degraded store not storing anything if it fails too.
+// The returned function closes the store.
+func openResultStore(dir string, degraded bool) (database.ResultStore, func(), error) {
+	opts := badger.DefaultOptions(dir).WithLogger(slogBadgerAdapter{})
+	db, err := badger.Open(opts)
+	if err == nil {
+		return db, func() { _ = db.Close() }, nil
+	}
+	if !degraded {
+		return nil, nil, fmt.Errorf("failed to open the database: %w", err)
+	}
+
+	slog.Error("failed to open the database, results not stored (degraded-storage)", "error", err)
+	db, roErr := badger.Open(opts.WithReadOnly(true))
+	if roErr == nil {
+		slog.Warn("database opened read-only, the previous results are readable")
+		return db, func() { _ = db.Close() }, nil
+	}
+	slog.Debug("failed to open the database read-only", "error", roErr)
+	return database.NewDegradedStore(err), func() {}, nil
+}
+
+// writableDB returns the database of the store if it stores the results, for its garbage collection.
+func writableDB(store database.ResultStore) (*badger.DB, bool) {
+	db, ok := store.(*badger.DB)
+	if !ok || db.Opts().ReadOnly {
+		return nil, false
+	}
+	return db, true
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
+)
+
+func TestOpenResultStore(t *testing.T) {
+	store, closeStore, err := openResultStore(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer closeStore()
+	if _, ok := writableDB(store); !ok {
+		t.Errorf("expected a writable database, got %T", store)
+	}
+}
+
+func TestOpenResultStore_Degraded(t *testing.T) {
+	// the database directory cannot be created
+	dir := filepath.Join(t.TempDir(), "db")
+	if err := os.WriteFile(dir, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := openResultStore(dir, false); err == nil {
+		t.Fatal("expected an error without degraded storage")
+	}
+
+	store, closeStore, err := openResultStore(dir, true)
+	if err != nil {
+		t.Fatalf("unexpected error with degraded storage: %v", err)
+	}
+	defer closeStore()
+	if _, ok := writableDB(store); ok {
+		t.Error("expected no database to garbage collect in degraded storage")
+	}
+	err = store.Update(func(txn *badger.Txn) error { return txn.Set([]byte("res:1"), []byte("{}")) })
+	if !errors.Is(err, database.ErrStoreDegraded) {
+		t.Errorf("expected ErrStoreDegraded, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"os"
@@ -11,9 +12,10 @@ import (
 	"time"
 
 	"github.com/jackadi-io/jackadi/internal/config"
-	_ "github.com/jackadi-io/jackadi/internal/logs"
+	"github.com/jackadi-io/jackadi/internal/logs"
 	"github.com/jackadi-io/jackadi/internal/node"
 	_ "github.com/jackadi-io/jackadi/internal/plugin/builtin"
+	"github.com/jackadi-io/jackadi/internal/plugin/loader/hcplugin"
 	flag "github.com/spf13/pflag"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -52,15 +54,23 @@ func run(cfg nodeConfig) error {
 		return err
 	}
 
+	if !cfg.MTLSEnabled {
+		go logs.RepeatWarn(ctx, config.InsecureWarningInterval, "unsecured connection with the manager, you should enable mTLS")
+	}
+
 	slog.Debug("initializing", "node-id", cfg.NodeID)
 
 	wg := sync.WaitGroup{}
 	defer func() {
-		cancel()
-		if err := client.Close(); err != nil {
-			slog.Error("failed to close connection", "error", err)
+		// the connection is kept while draining, to send the responses of the running tasks to the manager
+		slog.Info("draining: waiting gracefully for all tasks to finish")
+		if cutOff := client.Shutdown(config.GracefulShutdownTimeout); len(cutOff) > 0 {
+			slog.Warn("some tasks were still running, killed", "count", len(cutOff))
+		} else {
+			slog.Info("all tasks finished")
 		}
-		slog.Info("waiting gracefully for all tasks to finish")
+
+		cancel()
 		done := make(chan struct{})
 		go func() {
 			wg.Wait()
@@ -69,11 +79,14 @@ func run(cfg nodeConfig) error {
 
 		select {
 		case <-done:
-			slog.Warn("all tasks stopped")
+			slog.Warn("all components stopped")
 		case <-time.After(config.GracefulShutdownTimeout):
-			slog.Warn("some tasks are still pending, force quit")
+			slog.Warn("some components are still pending, force quit")
 			client.KillPlugins()
 		}
+		if err := client.Close(); err != nil {
+			slog.Error("failed to close connection", "error", err)
+		}
 		slog.Warn("bye")
 	}()
 
@@ -136,6 +149,15 @@ func run(cfg nodeConfig) error {
 	return nil
 }
 
+// pluginProcesses indexes the plugin process configurations by plugin file.
+func pluginProcesses(cfgs []config.PluginConfig) map[string]hcplugin.ProcessConfig {
+	procs := make(map[string]hcplugin.ProcessConfig, len(cfgs))
+	for _, c := range cfgs {
+		procs[c.Plugin] = hcplugin.ProcessConfig{Env: c.Env, Workdir: c.Workdir, RunAs: c.RunAs}
+	}
+	return procs
+}
+
 func main() {
 	versionCmd := flag.BoolP("version", "v", false, "print version")
 
@@ -162,21 +184,44 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := logs.Setup(nodeCfg.Log.Level, nodeCfg.Log.Format); err != nil {
+		slog.Error("failed to configure logs", "error", err)
+		os.Exit(1)
+	}
+	go logs.WatchSignal(context.Background())
+
+	var pluginServerTLS *tls.Config
+	if nodeCfg.PluginServerTLS.Enabled {
+		pluginServerTLS, err = config.GetPluginServerTLSConfig(nodeCfg.PluginServerTLS.ManagerCA, nodeCfg.ManagerAddress)
+		if err != nil {
+			slog.Error("failed to configure the plugin server TLS", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	cfg := nodeConfig{
 		reconnectDelay: nodeCfg.ReconnectDelay,
 		Config: node.Config{
 			NodeID:             nodeCfg.NodeID,
 			ManagerAddress:     nodeCfg.ManagerAddress,
 			ManagerPort:        nodeCfg.ManagerPort,
-			PluginDir:          nodeCfg.PluginDir,
+			PluginDirs:         nodeCfg.PluginDirs,
 			PluginServerPort:   nodeCfg.PluginServerPort,
+			PluginServerTLS:    pluginServerTLS,
+			PluginProcesses:    pluginProcesses(nodeCfg.PluginConfig),
+			AllowedPlugins:     nodeCfg.Plugins.Allowed,
+			PluginSyncInterval: time.Duration(nodeCfg.PluginSyncInterval) * time.Second,
 			MTLSEnabled:        nodeCfg.MTLS.Enabled,
 			MTLSKey:            nodeCfg.MTLS.Key,
 			MTLSCert:           nodeCfg.MTLS.Cert,
 			MTLSManagerCA:      nodeCfg.MTLS.ManagerCA,
+			MTLSSPIFFE:         nodeCfg.MTLS.SPIFFE,
 			CustomResolvers:    nodeCfg.CustomResolvers,
 			MaxConcurrentTasks: nodeCfg.MaxConcurrentTasks,
 			MaxWaitingRequests: nodeCfg.MaxWaitingRequests,
+			Keepalive:          nodeCfg.Keepalive.ClientParameters(),
+			Compression:        nodeCfg.Compression,
+			Version:            version,
 		},
 	}
 
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -24,7 +25,7 @@ func (o *DemoOptions) SetDefaults() {
 
 // UpgradeOptions for OS upgrade operations.
 type UpgradeOptions struct {
-	DryRun          bool `jackadi:"dry-run"`
+	DryRun          bool `jackadi:"dry-run,aliases=dry_run|dryRun"`
 	SecurityOnly    bool `jackadi:"securityonly"`
 	ExcludePackages []string
 	RebootRequired  bool
@@ -280,8 +281,19 @@ func FindUserByEmail(email string) (*User, error) {
 }
 
 // OS upgrade task with write lock - no real implementation, just demo.
-func UpgradeSystem(ctx context.Context, options *UpgradeOptions) (map[string]any, error) {
+// The progress of each step is reported, and shown by `jack run` while the task runs.
+func UpgradeSystem(ctx context.Context, progress sdk.Progress, options *UpgradeOptions) (map[string]any, error) {
 	// Simulate OS upgrade process.
+	steps := 4
+	for i := range steps {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+		progress.Report((i + 1) * 100 / steps)
+	}
+
 	result := map[string]any{
 		"status":           "completed",
 		"packages_updated": 47,
@@ -308,6 +320,15 @@ func UpgradeSystem(ctx context.Context, options *UpgradeOptions) (map[string]any
 	return result, nil
 }
 
+// Rejects the invalid combinations of options before the upgrade starts.
+func ValidateUpgradeOptions(opts sdk.Options, _ []any) error {
+	options := opts.(*UpgradeOptions)
+	if options.SecurityOnly && len(options.ExcludePackages) > 0 {
+		return errors.New("securityonly cannot be combined with ExcludePackages")
+	}
+	return nil
+}
+
 // Spec collector examples - these gather system information.
 
 // Simple spec returning basic OS info.
@@ -373,7 +394,8 @@ func GetInstalledPackages() (map[string]any, error) {
 	}, nil
 }
 
-func main() {
+// newPlugin returns the demo plugin with its tasks and spec collectors registered.
+func newPlugin() *sdk.Plugin {
 	plugin := sdk.New("demo")
 
 	// Register tasks with meaningful descriptions and examples.
@@ -386,7 +408,7 @@ func main() {
 		WithSummary("Configure a system service").
 		WithDescription("Configures a named service with regional settings and timeout controls. Demonstrates option usage.").
 		WithArg("serviceName", "string", "webserver-pro").
-		WithLockMode(sdk.WriteLock)
+		WithMinimumLockMode(sdk.WriteLock)
 
 	plugin.MustRegisterTask("monitor_health", MonitorSystemHealth).
 		WithSummary("Monitor system health metrics").
@@ -458,7 +480,8 @@ func main() {
 	plugin.MustRegisterTask("upgrade_system", UpgradeSystem).
 		WithSummary("Upgrade system packages").
 		WithDescription("Performs system package upgrades with various options. Uses write lock to prevent conflicts during upgrades.").
-		WithLockMode(sdk.WriteLock)
+		WithLockMode(sdk.WriteLock).
+		WithValidation(ValidateUpgradeOptions)
 
 	// Register spec collectors - these gather system information for inventory and targeting.
 	plugin.MustRegisterSpecCollector("os", GetOSInfo).
@@ -477,5 +500,9 @@ func main() {
 		WithSummary("Software inventory").
 		WithDescription("Collects information about installed packages, versions, and available updates.")
 
-	sdk.MustServe(plugin)
+	return plugin
+}
+
+func main() {
+	sdk.MustServe(newPlugin())
 }
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackadi-io/jackadi/internal/clientgen"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"github.com/jackadi-io/jackadi/sdk"
+)
+
+// decodeJSON decodes the data as the manager does: the numbers are kept as json.Number.
+func decodeJSON(t *testing.T, data []byte) any {
+	t.Helper()
+	var v any
+	if err := serializer.JSON.Unmarshal(data, &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	return v
+}
+
+func TestDemoTasks(t *testing.T) {
+	plugin := newPlugin()
+	plugin.MustRegisterTask("max_int64", func() (int64, error) { return math.MaxInt64, nil })
+	plugin.MustRegisterTask("max_uint64", func() (uint64, error) { return math.MaxUint64, nil })
+
+	tests := []struct {
+		name string
+		task string
+		opts map[string]any
+		args []any
+		want string // output of the task, in JSON
+	}{
+		{
+			name: "string",
+			task: "hello",
+			want: `"Hello, World! This is Jackadi distributed task execution."`,
+		},
+		{
+			name: "int64",
+			task: "get_connection_count",
+			want: `1247`,
+		},
+		{
+			name: "large int64",
+			task: "max_int64",
+			want: `9223372036854775807`,
+		},
+		{
+			name: "large uint64",
+			task: "max_uint64",
+			want: `18446744073709551615`,
+		},
+		{
+			name: "struct",
+			task: "get_server_info",
+			want: `{
+				"hostname": "prod-web-01.jackadi.io",
+				"ip_addresses": ["192.168.1.100", "10.0.0.50"],
+				"services": ["webserver-pro", "app-engine", "datastore"],
+				"last_reboot": "2024-01-10T08:15:00Z",
+				"disk_usage_percent": 67.5,
+				"cpu_cores": 8,
+				"memory_gb": 32,
+				"is_production": true
+			}`,
+		},
+		{
+			name: "slice",
+			task: "list_services",
+			want: `["webserver-pro", "datastore-engine", "cache-service", "container-runtime", "secure-shell"]`,
+		},
+		{
+			name: "map",
+			task: "get_env_vars",
+			want: `{
+				"NODE_ENV": "production",
+				"DATABASE_URL": "dbstore://localhost:5432/myapp",
+				"CACHE_URL": "cache://localhost:6379",
+				"API_VERSION": "v2.1.0",
+				"LOG_LEVEL": "info"
+			}`,
+		},
+		{
+			name: "pointer",
+			task: "find_user",
+			args: []any{"admin@jackadi.io"},
+			want: `{"id": 999, "username": "admin", "email": "admin@jackadi.io", "metadata": {"role": "administrator"}}`,
+		},
+		{
+			name: "nil pointer",
+			task: "find_user",
+			args: []any{"nobody@jackadi.io"},
+			want: `null`,
+		},
+		{
+			name: "fixed array",
+			task: "get_reboot_history",
+			want: `["2024-01-10T08:15:00Z", "2023-12-15T02:30:00Z", "2023-11-20T14:45:00Z"]`,
+		},
+		{
+			name: "options",
+			task: "configure_service",
+			opts: map[string]any{"Region": "eu-west-3"},
+			args: []any{"nginx"},
+			want: `"Service 'nginx' configured successfully in eu-west-3"`,
+		},
+		{
+			// the arguments as sent by jack: strings, parsed to the types of the task
+			name: "arguments of jack",
+			task: "create_user",
+			args: []any{
+				"9007199254740993", // not representable as a float64
+				"alice",
+				"alice@jackadi.io",
+				"true",
+				`["read", "write"]`,
+				`{"team": "ops"}`,
+				`{"hostname": "web-01", "cpu_cores": 4}`,
+				`[100, 200, 300]`,
+			},
+			want: `{"id": 9007199254740993, "username": "alice", "email": "alice@jackadi.io", "metadata": {"team": "ops"}}`,
+		},
+		{
+			name: "typed arguments",
+			task: "create_user",
+			args: []any{
+				int64(42),
+				"bob",
+				"bob@jackadi.io",
+				false,
+				[]string{"read"},
+				map[string]string{"team": "dev"},
+				ServerInfo{Hostname: "web-02", CPUCores: 2},
+				[3]int{1, 2, 3},
+			},
+			want: `{"id": 42, "username": "bob", "email": "bob@jackadi.io", "metadata": {"team": "dev"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := sdk.TestInvoke(context.Background(), plugin, tt.task, tt.opts, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Error != "" || res.Retcode != 0 {
+				t.Fatalf("unexpected failure of the task: %s (retcode %d)", res.Error, res.Retcode)
+			}
+			if diff := cmp.Diff(decodeJSON(t, []byte(tt.want)), decodeJSON(t, res.Output)); diff != "" {
+				t.Errorf("unexpected output (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDemoTasks_Int64Precision(t *testing.T) {
+	plugin := newPlugin()
+	plugin.MustRegisterTask("max_int64", func() (int64, error) { return math.MaxInt64, nil })
+
+	res, err := sdk.TestInvoke(context.Background(), plugin, "max_int64", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got int64
+	if err := res.Decode(&got); err != nil {
+		t.Fatalf("invalid output: %v", err)
+	}
+	if got != math.MaxInt64 {
+		t.Errorf("expected %d, got %d", int64(math.MaxInt64), got)
+	}
+
+	res, err = sdk.TestInvoke(context.Background(), plugin, "create_user", nil,
+		"9007199254740993", "alice", "alice@jackadi.io", "true", "[]", "{}", "{}", "[0, 0, 0]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var user User
+	if err := res.Decode(&user); err != nil {
+		t.Fatalf("invalid output: %v", err)
+	}
+	if user.ID != 9007199254740993 {
+		t.Errorf("expected the ID 9007199254740993, got %d", user.ID)
+	}
+}
+
+func TestDemoTasks_UpgradeSystem(t *testing.T) {
+	opts := map[string]any{"dry-run": true}
+	res, err := sdk.TestInvoke(context.Background(), newPlugin(), "upgrade_system", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]int{25, 50, 75, 100}, res.Progress); diff != "" {
+		t.Errorf("unexpected progress (-want +got):\n%s", diff)
+	}
+	var out map[string]any
+	if err := res.Decode(&out); err != nil || out["status"] != "dry-run-completed" {
+		t.Errorf("expected a dry run, got %v (%v)", out, err)
+	}
+
+	opts = map[string]any{"securityonly": true, "ExcludePackages": []string{"kernel"}}
+	if _, err := sdk.TestInvoke(context.Background(), newPlugin(), "upgrade_system", opts); err == nil {
+		t.Error("expected the validation to reject securityonly with excluded packages")
+	}
+}
+
+// inputSchema returns the JSON Schema of the inputs of the task, as printed by the plugin with --schema.
+func inputSchema(t *testing.T, task string) any {
+	t.Helper()
+	schema, err := newPlugin().InputSchema(task)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := serializer.JSON.Marshal(schema)
+	if err != nil {
+		t.Fatalf("unable to serialize the schema: %v", err)
+	}
+	return decodeJSON(t, data)
+}
+
+func TestDemoSchema_CreateUser(t *testing.T) {
+	want := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"args": {
+				"type": "array",
+				"minItems": 8,
+				"maxItems": 8,
+				"prefixItems": [
+					{"title": "userID", "type": "integer"},
+					{"title": "username", "type": "string"},
+					{"title": "email", "type": "string"},
+					{"title": "isActive", "type": "boolean"},
+					{"title": "permissions", "type": "array", "items": {"type": "string"}},
+					{"title": "metadata", "type": "object", "additionalProperties": {"type": "string"}},
+					{
+						"title": "serverConfig",
+						"type": "object",
+						"properties": {
+							"hostname": {"type": "string"},
+							"ip_addresses": {"type": "array", "items": {"type": "string"}},
+							"services": {"type": "array", "items": {"type": "string"}},
+							"last_reboot": {"type": "string"},
+							"disk_usage_percent": {"type": "number"},
+							"cpu_cores": {"type": "integer"},
+							"memory_gb": {"type": "integer"},
+							"is_production": {"type": "boolean"}
+						}
+					},
+					{"title": "limits", "type": "array", "items": {"type": "integer"}, "minItems": 3, "maxItems": 3}
+				]
+			}
+		}
+	}`
+	if diff := cmp.Diff(decodeJSON(t, []byte(want)), inputSchema(t, "create_user")); diff != "" {
+		t.Errorf("unexpected schema (-want +got):\n%s", diff)
+	}
+}
+
+func TestDemoSchema_UpgradeSystem(t *testing.T) {
+	// the context and the progress are not inputs, the options are named after their jackadi tag
+	want := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"options": {
+				"type": "object",
+				"additionalProperties": false,
+				"properties": {
+					"dry-run": {"type": "boolean"},
+					"securityonly": {"type": "boolean"},
+					"ExcludePackages": {"type": "array", "items": {"type": "string"}},
+					"RebootRequired": {"type": "boolean"},
+					"BackupBefore": {"type": "boolean", "default": true}
+				}
+			}
+		}
+	}`
+	if diff := cmp.Diff(decodeJSON(t, []byte(want)), inputSchema(t, "upgrade_system")); diff != "" {
+		t.Errorf("unexpected schema (-want +got):\n%s", diff)
+	}
+}
+
+// demoClientUsage calls the tasks of the demo plugin with the generated client, it compiles only if the arguments
+// and the options are typed like the parameters of the tasks.
+const demoClientUsage = `package demo
+
+import (
+	"context"
+
+	"github.com/jackadi-io/jackadi/client"
+)
+
+func usage(ctx context.Context, c *client.Client) {
+	demo := New(c)
+	_, _ = demo.Hello(ctx, "web-*", client.Glob)
+	_, _ = demo.CreateUser(ctx, "web-*", client.Glob, 12345, "johndoe", "john@jackadi.io", true, []string{"read"},
+		map[string]string{"department": "engineering"},
+		CreateUserServerConfig{Hostname: "web-01", CPUCores: 4, IPAddresses: []string{"10.0.0.1"}}, [3]int64{1, 2, 3})
+
+	options := NewUpgradeSystemOptions()
+	options.DryRun = true
+	options.ExcludePackages = []string{"kernel"}
+	_, _ = demo.UpgradeSystem(ctx, "web-*", client.Glob, options)
+
+	configure := NewConfigureServiceOptions()
+	configure.Region = "eu-west-1"
+	_, _ = demo.ConfigureService(ctx, "web-*", client.Glob, configure, "webserver-pro")
+}
+`
+
+// TestDemoClient verifies that the client generated by jack gen client for the demo plugin compiles, with the
+// types of the arguments and of the options of the tasks.
+func TestDemoClient(t *testing.T) {
+	if testing.Short() {
+		t.Skip("client build skipped in short mode")
+	}
+
+	schemas, err := newPlugin().InputSchemas()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := serializer.JSON.Marshal(schemas)
+	if err != nil {
+		t.Fatalf("unable to serialize the schemas: %v", err)
+	}
+	decoded, ok := decodeJSON(t, data).(map[string]any)
+	if !ok {
+		t.Fatalf("unexpected schemas: %s", data)
+	}
+	src, err := clientgen.Generate("demo", "demo", decoded)
+	if err != nil {
+		t.Fatalf("failed to generate the client: %v", err)
+	}
+
+	// the client is built as a package of the module, the overlay adds its files without writing them in the tree
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dir := t.TempDir()
+	overlay := map[string]map[string]string{"Replace": {}}
+	for name, content := range map[string][]byte{"client.go": src, "usage.go": []byte(demoClientUsage)} {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, content, 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		overlay["Replace"][filepath.Join(wd, "democlient", name)] = file
+	}
+	overlayData, err := serializer.JSON.Marshal(overlay)
+	if err != nil {
+		t.Fatalf("unable to serialize the overlay: %v", err)
+	}
+	overlayFile := filepath.Join(dir, "overlay.json")
+	if err := os.WriteFile(overlayFile, overlayData, 0600); err != nil {
+		t.Fatalf("failed to write the overlay: %v", err)
+	}
+
+	if out, err := exec.Command("go", "build", "-overlay", overlayFile, "./democlient").CombinedOutput(); err != nil {
+		t.Fatalf("the generated client does not compile: %v\n%s\n%s", err, out, src)
+	}
+}
@@ -11,7 +11,7 @@ import (
 	"strings"
 
 	"github.com/goccy/go-yaml"
-	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
 	"github.com/jackadi-io/jackadi/internal/serializer"
 )
 
@@ -223,7 +223,8 @@ func (a *Authorizer) handler(next http.Handler) http.Handler {
 			r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 
 			execReq := struct {
-				Task string `json:"task"`
+				Plugin string `json:"plugin"`
+				Task   string `json:"task"`
 			}{}
 			if err := serializer.JSON.Unmarshal(bodyBytes, &execReq); err != nil {
 				w.WriteHeader(http.StatusBadRequest)
@@ -231,14 +232,7 @@ func (a *Authorizer) handler(next http.Handler) http.Handler {
 				return
 			}
 
-			taskParts := strings.Split(execReq.Task, config.PluginSeparator)
-			if len(taskParts) < 2 {
-				w.WriteHeader(http.StatusBadRequest)
-				_, _ = w.Write([]byte(`{"error":"Bad Request","message":"failed to parse plugin.task","status":400}`))
-				return
-			}
-
-			plugin, task := taskParts[0], taskParts[1]
+			plugin, task := (&proto.TaskRequest{Plugin: execReq.Plugin, Task: execReq.Task}).PluginTask()
 			if !a.canAccessTask(username, plugin, task) {
 				w.WriteHeader(http.StatusForbidden)
 				slog.Warn("insufficient permissions", "plugin", plugin, "task", task)
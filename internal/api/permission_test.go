@@ -1,6 +1,9 @@
 package api
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -504,3 +507,49 @@ func TestCanAccessTask(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorizerHandlerTaskExec(t *testing.T) {
+	a := &Authorizer{
+		config: ParsedAuthConfig{
+			Users: map[User][]Role{
+				"user1": {"role1"},
+			},
+			Roles: map[string]Permissions{
+				"role1": {
+					Endpoints: []Permission{
+						{Resource: "task", Action: "exec"},
+					},
+					Tasks: []Permission{
+						{Resource: "git", Action: "clone"},
+					},
+				},
+			},
+		},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "joined form", body: `{"task":"git.clone"}`, want: http.StatusOK},
+		{name: "plugin field", body: `{"plugin":"git","task":"clone"}`, want: http.StatusOK},
+		{name: "task name with separator", body: `{"plugin":"git","task":"clone.all"}`, want: http.StatusForbidden},
+		{name: "plugin field takes precedence", body: `{"plugin":"cmd","task":"git.clone"}`, want: http.StatusForbidden},
+		{name: "plugin without task name", body: `{"task":"git"}`, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/task/exec", strings.NewReader(tt.body))
+			req.SetBasicAuth("user1", "")
+			rec := httptest.NewRecorder()
+
+			a.handler(next).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
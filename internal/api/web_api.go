@@ -16,6 +16,7 @@ import (
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/helper"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc"
@@ -167,7 +168,7 @@ func StartHTTPProxy(ctx context.Context, cfg Config) error {
 	authHandler := htpasswd.basicAuthMiddleware(authorizer.handler(mux))
 
 	// start HTTP server (and proxy calls to gRPC server endpoint)
-	apiAddr := fmt.Sprintf("%s:%s", cfg.APIAddress, cfg.APIPort)
+	apiAddr := helper.JoinHostPort(cfg.APIAddress, cfg.APIPort)
 	httpServer := http.Server{
 		Addr:              apiAddr,
 		Handler:           authHandler,
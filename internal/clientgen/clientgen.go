@@ -0,0 +1,311 @@
+// Package clientgen generates the typed Go clients of the plugins, from the JSON Schema of the inputs of their
+// tasks (see sdk.Plugin.InputSchemas).
+//
+// The generated client has a method per task, with the typed positional arguments of the task and its options
+// struct. The JSON types are mapped to Go types: boolean, integer (uint64 if it has a minimum of 0), number,
+// string, arrays (fixed size if minItems equals maxItems), maps and objects, the objects with properties being
+// generated as structs named after the task and the argument or the field. The values without type are any.
+package clientgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+)
+
+// ClientImport is the package imported by the generated clients to run the tasks.
+const ClientImport = "github.com/jackadi-io/jackadi/client"
+
+// schema is the subset of JSON Schema describing the inputs of the tasks.
+type schema struct {
+	Type                 string             `json:"type"`
+	Title                string             `json:"title"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	PrefixItems          []*schema          `json:"prefixItems"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	Minimum              *json.Number       `json:"minimum"`
+	Default              any                `json:"default"`
+}
+
+// generator writes the client of a plugin, the structs being written after the methods.
+type generator struct {
+	plugin  string
+	methods bytes.Buffer
+	types   bytes.Buffer
+	names   map[string]bool // Exported names already declared.
+}
+
+// Generate returns the Go source of the client of the plugin, in the package pkg, from the input schemas of its
+// tasks by task name.
+func Generate(plugin, pkg string, schemas map[string]any) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package name: %q", pkg)
+	}
+
+	g := &generator{plugin: plugin, names: map[string]bool{"Client": true, "New": true}}
+	for _, task := range slices.Sorted(maps.Keys(schemas)) {
+		var s schema
+		data, err := serializer.JSON.Marshal(schemas[task])
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %w", task, err)
+		}
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("task %s: invalid schema: %w", task, err)
+		}
+		if err := g.task(task, &s); err != nil {
+			return nil, fmt.Errorf("task %s: %w", task, err)
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by jack gen client. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "// Package %s runs the tasks of the %s plugin with typed inputs.\n", pkg, plugin)
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+	fmt.Fprintf(&out, "import (\n\t\"context\"\n\n\t%q\n)\n\n", ClientImport)
+	fmt.Fprintf(&out, "// Client runs the tasks of the %s plugin.\n", plugin)
+	fmt.Fprintf(&out, "type Client struct {\n\tclient *client.Client\n\tOptions client.RunOptions // Options of the runs, e.g. their timeout.\n}\n\n")
+	fmt.Fprintf(&out, "// New returns a client running the tasks of the %s plugin with c.\n", plugin)
+	fmt.Fprintf(&out, "func New(c *client.Client) *Client {\n\treturn &Client{client: c}\n}\n")
+	out.Write(g.methods.Bytes())
+	out.Write(g.types.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("invalid generated code: %w", err)
+	}
+	return src, nil
+}
+
+// task writes the method running the task, and the types of its inputs.
+func (g *generator) task(task string, s *schema) error {
+	method := g.declare(exportedName(task))
+	fullTask := g.plugin + config.PluginSeparator + task
+	params := []string{"ctx context.Context", "target string", "mode client.TargetMode"}
+	used := map[string]bool{"ctx": true, "target": true, "mode": true, "options": true, "args": true, "c": true, "context": true, "client": true}
+
+	optionsArg := "nil"
+	if opts, ok := s.Properties["options"]; ok {
+		name := g.declare(method + "Options")
+		if err := g.structType(name, fmt.Sprintf("are the options of %s, New%s returns them with their defaults.", fullTask, name), opts); err != nil {
+			return err
+		}
+		g.defaults(name, fullTask, opts)
+		params = append(params, "options "+name)
+		optionsArg = "options"
+	}
+
+	var required, optional []string
+	if args, ok := s.Properties["args"]; ok {
+		minItems := len(args.PrefixItems)
+		if args.MinItems != nil {
+			minItems = *args.MinItems
+		}
+		for i, item := range args.PrefixItems {
+			title := item.Title
+			if title == "" {
+				title = fmt.Sprintf("arg%d", i+1)
+			}
+			param := paramName(title, used)
+			typ, err := g.goType(method+exportedName(title), item)
+			if err != nil {
+				return fmt.Errorf("argument %s: %w", title, err)
+			}
+			if i >= minItems {
+				typ = "*" + typ
+				optional = append(optional, param)
+			} else {
+				required = append(required, param)
+			}
+			params = append(params, param+" "+typ)
+		}
+	}
+
+	fmt.Fprintf(&g.methods, "\n// %s runs %s on the nodes matching the target.", method, fullTask)
+	if len(optional) > 0 {
+		fmt.Fprintf(&g.methods, " The optional arguments are sent up to the first nil one.")
+	}
+	fmt.Fprintf(&g.methods, "\nfunc (c *Client) %s(%s) (client.Results, error) {\n", method, strings.Join(params, ", "))
+	fmt.Fprintf(&g.methods, "\targs := []any{%s}\n", strings.Join(required, ", "))
+	for _, param := range optional {
+		fmt.Fprintf(&g.methods, "\tif %s == nil {\n\t\treturn c.client.RunInput(ctx, target, mode, %q, args, %s, c.Options)\n\t}\n", param, fullTask, optionsArg)
+		fmt.Fprintf(&g.methods, "\targs = append(args, %s)\n", param)
+	}
+	fmt.Fprintf(&g.methods, "\treturn c.client.RunInput(ctx, target, mode, %q, args, %s, c.Options)\n}\n", fullTask, optionsArg)
+	return nil
+}
+
+// goType returns the Go type of the schema, the structs being declared with the name.
+func (g *generator) goType(name string, s *schema) (string, error) {
+	switch s.Type {
+	case "boolean":
+		return "bool", nil
+	case "integer":
+		if s.Minimum != nil && *s.Minimum == "0" {
+			return "uint64", nil
+		}
+		return "int64", nil
+	case "number":
+		return "float64", nil
+	case "string":
+		return "string", nil
+	case "array":
+		if s.Items == nil {
+			return "[]any", nil
+		}
+		elem, err := g.goType(name+"Item", s.Items)
+		if err != nil {
+			return "", err
+		}
+		if s.MinItems != nil && s.MaxItems != nil && *s.MinItems == *s.MaxItems {
+			return fmt.Sprintf("[%d]%s", *s.MaxItems, elem), nil
+		}
+		return "[]" + elem, nil
+	case "object":
+		if len(s.Properties) > 0 {
+			name = g.declare(name)
+			return name, g.structType(name, "is generated from the JSON Schema of the task.", s)
+		}
+		var additional schema
+		if len(s.AdditionalProperties) == 0 || json.Unmarshal(s.AdditionalProperties, &additional) != nil {
+			return "map[string]any", nil // no schema of the values, or additionalProperties: false
+		}
+		elem, err := g.goType(name+"Value", &additional)
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + elem, nil
+	case "":
+		return "any", nil
+	default:
+		return "", fmt.Errorf("unsupported type: %s", s.Type)
+	}
+}
+
+// structType declares the struct of the object, its fields being tagged with the property names.
+func (g *generator) structType(name, doc string, s *schema) error {
+	var fields strings.Builder
+	names := fieldNames(s)
+	for _, property := range slices.Sorted(maps.Keys(s.Properties)) {
+		field := names[property]
+		typ, err := g.goType(name+field, s.Properties[property])
+		if err != nil {
+			return fmt.Errorf("field %s: %w", property, err)
+		}
+		fmt.Fprintf(&fields, "\t%s %s `jackadi:%q`\n", field, typ, property)
+	}
+	fmt.Fprintf(&g.types, "\n// %s %s\ntype %s struct {\n%s}\n", name, doc, name, fields.String())
+	return nil
+}
+
+// defaults declares the constructor of the options struct, with the defaults of the primitive fields.
+func (g *generator) defaults(name, fullTask string, s *schema) {
+	var values strings.Builder
+	names := fieldNames(s)
+	for _, property := range slices.Sorted(maps.Keys(s.Properties)) {
+		if value, ok := literal(s.Properties[property]); ok {
+			fmt.Fprintf(&values, "\t\t%s: %s,\n", names[property], value)
+		}
+	}
+	fmt.Fprintf(&g.types, "\n// New%s returns the options of %s with their defaults.\n", name, fullTask)
+	fmt.Fprintf(&g.types, "func New%s() %s {\n\treturn %s{\n%s\t}\n}\n", name, name, name, values.String())
+}
+
+// fieldNames returns the names of the struct fields of the object, by property.
+func fieldNames(s *schema) map[string]string {
+	names := make(map[string]string, len(s.Properties))
+	declared := map[string]bool{}
+	for _, property := range slices.Sorted(maps.Keys(s.Properties)) {
+		field := exportedName(property)
+		for declared[field] {
+			field += "_"
+		}
+		declared[field] = true
+		names[property] = field
+	}
+	return names
+}
+
+// declare returns an exported name not declared yet, based on the name.
+func (g *generator) declare(name string) string {
+	declared := name
+	for i := 2; g.names[declared]; i++ {
+		declared = name + strconv.Itoa(i)
+	}
+	g.names[declared] = true
+	return declared
+}
+
+// literal returns the Go literal of the default value of a primitive property.
+func literal(s *schema) (string, bool) {
+	switch value := s.Default.(type) {
+	case bool:
+		return strconv.FormatBool(value), true
+	case string:
+		return strconv.Quote(value), true
+	case float64:
+		if s.Type == "integer" {
+			return strconv.FormatInt(int64(value), 10), true
+		}
+		return strconv.FormatFloat(value, 'g', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+// initialisms are the words written in upper case in the Go identifiers, e.g. cpu_cores gives CPUCores.
+var initialisms = map[string]bool{
+	"api": true, "cpu": true, "dns": true, "http": true, "https": true, "id": true, "ip": true, "json": true,
+	"os": true, "sql": true, "ssh": true, "tcp": true, "tls": true, "udp": true, "uid": true, "url": true,
+	"uuid": true, "xml": true,
+}
+
+// exportedName returns the exported Go identifier of a name, e.g. create_user or create-user give CreateUser.
+func exportedName(name string) string {
+	var sb strings.Builder
+	words := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for _, word := range words {
+		if initialisms[strings.ToLower(word)] {
+			sb.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	ident := sb.String()
+	if ident == "" || !unicode.IsLetter([]rune(ident)[0]) {
+		ident = "X" + ident
+	}
+	return ident
+}
+
+// paramName returns an unexported Go identifier of the name, not used yet by the other parameters.
+func paramName(name string, used map[string]bool) string {
+	ident := []rune(exportedName(name))
+	for i := 0; i < len(ident) && unicode.IsUpper(ident[i]); i++ {
+		// the initialism at the start is lower case, the next word is not: IPAddress gives ipAddress
+		if i > 0 && i+1 < len(ident) && unicode.IsLower(ident[i+1]) {
+			break
+		}
+		ident[i] = unicode.ToLower(ident[i])
+	}
+	param := string(ident)
+	for used[param] || token.IsKeyword(param) {
+		param += "Arg"
+	}
+	used[param] = true
+	return param
+}
@@ -0,0 +1,90 @@
+package clientgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExportedName(t *testing.T) {
+	tests := map[string]string{
+		"create_user":    "CreateUser",
+		"instant-ping":   "InstantPing",
+		"userID":         "UserID",
+		"cpu_cores":      "CPUCores",
+		"ip_addresses":   "IPAddresses",
+		"ExcludePackage": "ExcludePackage",
+		"3d":             "X3d",
+		"":               "X",
+	}
+	for name, want := range tests {
+		if got := exportedName(name); got != want {
+			t.Errorf("exportedName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParamName(t *testing.T) {
+	used := map[string]bool{"ctx": true}
+	for _, tt := range []struct{ name, want string }{
+		{"userID", "userID"},
+		{"ip_address", "ipAddress"},
+		{"ID", "id"},
+		{"type", "typeArg"},
+		{"ctx", "ctxArg"},
+		{"user_id", "userIDArg"}, // userID is used
+	} {
+		if got := paramName(tt.name, used); got != tt.want {
+			t.Errorf("paramName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	schemas := map[string]any{
+		"patch": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"args": map[string]any{
+					"type": "array",
+					"prefixItems": []any{
+						map[string]any{"type": "string", "title": "name"},
+						map[string]any{"type": "integer", "minimum": 0, "title": "count"},
+						map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "number"}, "title": "weights"},
+						map[string]any{"title": "extra"},
+					},
+					"minItems": 2,
+					"maxItems": 4,
+				},
+				"options": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"dry-run": map[string]any{"type": "boolean", "default": true},
+						"labels":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					},
+				},
+			},
+		},
+	}
+
+	src, err := Generate("pkgs", "pkgs", schemas)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"func (c *Client) Patch(ctx context.Context, target string, mode client.TargetMode, options PatchOptions, name string, count uint64, weights *map[string]float64, extra *any) (client.Results, error) {",
+		"\tif weights == nil {\n\t\treturn c.client.RunInput(ctx, target, mode, \"pkgs.patch\", args, options, c.Options)\n\t}",
+		"\tDryRun bool     `jackadi:\"dry-run\"`\n\tLabels []string `jackadi:\"labels\"`",
+		"\treturn PatchOptions{\n\t\tDryRun: true,\n\t}",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated code without %q:\n%s", want, src)
+		}
+	}
+
+	if _, err := Generate("pkgs", "not a package", schemas); err == nil {
+		t.Error("expected an invalid package name to be refused")
+	}
+	if _, err := Generate("pkgs", "pkgs", map[string]any{"bad": map[string]any{"type": "object", "properties": map[string]any{"args": map[string]any{"type": "array", "prefixItems": []any{map[string]any{"type": "null"}}}}}}); err == nil {
+		t.Error("expected an unsupported type to be refused")
+	}
+}
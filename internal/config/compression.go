@@ -0,0 +1,33 @@
+package config
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip" // registered in the manager and the nodes, the compressed messages are accepted even if the compression is disabled
+)
+
+// The compression of the gRPC messages between the manager and its nodes (compression option), disabled by default.
+//
+// gzip reduces the size of the JSON inputs, outputs and specs at the cost of CPU on both sides: it is worth it on the
+// WAN links of large fleets, rarely on a LAN.
+
+// CompressionDialOption compresses the messages sent to the manager.
+func CompressionDialOption() grpc.DialOption {
+	return grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name))
+}
+
+// CompressionServerOptions compress the messages sent to the nodes, if they accept gzip: the nodes not supporting it
+// receive uncompressed messages.
+func CompressionServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			_ = grpc.SetSendCompressor(ctx, gzip.Name)
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			_ = grpc.SetSendCompressor(ss.Context(), gzip.Name)
+			return handler(srv, ss)
+		}),
+	}
+}
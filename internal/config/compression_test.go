@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// countingCompressor counts the messages compressed and decompressed by gzip.
+type countingCompressor struct {
+	encoding.Compressor
+	compressed, decompressed atomic.Int32
+}
+
+func (c *countingCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	c.compressed.Add(1)
+	return c.Compressor.Compress(w)
+}
+
+func (c *countingCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	c.decompressed.Add(1)
+	return c.Compressor.Decompress(r)
+}
+
+func TestCompression(t *testing.T) {
+	counter := &countingCompressor{Compressor: encoding.GetCompressor(gzip.Name)}
+	encoding.RegisterCompressor(counter)
+	t.Cleanup(func() { encoding.RegisterCompressor(counter.Compressor) })
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(CompressionServerOptions()...)
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus("jackadi", healthpb.HealthCheckResponse_SERVING) // the empty messages are not compressed
+	healthpb.RegisterHealthServer(srv, healthSrv)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	dial := func(opts ...grpc.DialOption) healthpb.HealthClient {
+		opts = append(opts,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		)
+		conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return healthpb.NewHealthClient(conn)
+	}
+
+	// the request and the response are compressed
+	resp, err := dial(CompressionDialOption()).Check(t.Context(), &healthpb.HealthCheckRequest{Service: "jackadi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("expected SERVING, got %s", resp.GetStatus())
+	}
+	if c, d := counter.compressed.Load(), counter.decompressed.Load(); c != 2 || d != 2 {
+		t.Errorf("expected the request and the response to be compressed and decompressed, got %d and %d", c, d)
+	}
+
+	// a client without compression still gets a compressed response, gzip being registered on both sides
+	counter.compressed.Store(0)
+	counter.decompressed.Store(0)
+	if _, err := dial().Check(t.Context(), &healthpb.HealthCheckRequest{Service: "jackadi"}); err != nil {
+		t.Fatalf("unexpected error without client compression: %v", err)
+	}
+	if c, d := counter.compressed.Load(), counter.decompressed.Load(); c != 1 || d != 1 {
+		t.Errorf("expected only the response to be compressed, got %d compressed and %d decompressed", c, d)
+	}
+}
@@ -3,10 +3,15 @@ package config
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/sagikazarmark/locafero"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -14,43 +19,269 @@ import (
 
 var errViperConfigNotFound viper.ConfigFileNotFoundError
 
+// PathList is a list of directories, by order of precedence.
+//
+// It is either set as a YAML list, or as a colon-separated string (like $PATH).
+type PathList []string
+
+// decodeHook is the viper default decode hook, with PathList support.
+var decodeHook = viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+	stringToPathListHookFunc(),
+	mapstructure.StringToTimeDurationHookFunc(),
+	mapstructure.StringToSliceHookFunc(","),
+))
+
+func stringToPathListHookFunc() mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data any) (any, error) {
+		if f.Kind() != reflect.String || t != reflect.TypeFor[PathList]() {
+			return data, nil
+		}
+		return PathList(filepath.SplitList(data.(string))), nil
+	}
+}
+
 type NodeConfig struct {
-	NodeID             string     `mapstructure:"node-id" yaml:"node-id"`
-	ManagerAddress     string     `mapstructure:"manager-address" yaml:"manager-address"`
-	ManagerPort        string     `mapstructure:"manager-port" yaml:"manager-port"`
-	ReconnectDelay     int        `mapstructure:"reconnect-delay" yaml:"reconnect-delay"`
-	PluginDir          string     `mapstructure:"plugin-dir" yaml:"plugin-dir"`
-	PluginServerPort   string     `mapstructure:"plugin-server-port" yaml:"plugin-server-port"`
-	CustomResolvers    []string   `mapstructure:"custom-resolvers" yaml:"custom-resolvers"`
-	MaxConcurrentTasks int        `mapstructure:"max-concurrent-tasks" yaml:"max-concurrent-tasks"`
-	MaxWaitingRequests int        `mapstructure:"max-waiting-requests" yaml:"max-waiting-requests"`
-	MTLS               MTLSConfig `mapstructure:"mtls" yaml:"mtls"`
+	NodeID             string                `mapstructure:"node-id" yaml:"node-id"`
+	ManagerAddress     string                `mapstructure:"manager-address" yaml:"manager-address"`
+	ManagerPort        string                `mapstructure:"manager-port" yaml:"manager-port"`
+	ReconnectDelay     int                   `mapstructure:"reconnect-delay" yaml:"reconnect-delay"`
+	PluginDirs         PathList              `mapstructure:"plugin-dir" yaml:"plugin-dir"` // Plugins are synchronized from the manager in the first one.
+	PluginServerPort   string                `mapstructure:"plugin-server-port" yaml:"plugin-server-port"`
+	PluginServerTLS    PluginServerTLSConfig `mapstructure:"plugin-server-tls" yaml:"plugin-server-tls"`
+	PluginSyncInterval int                   `mapstructure:"plugin-sync-interval" yaml:"plugin-sync-interval"` // In seconds, only on demand (plugins.sync) if 0.
+	PluginConfig       []PluginConfig        `mapstructure:"plugin-config" yaml:"plugin-config"`
+	Plugins            NodePluginsConfig     `mapstructure:"plugins" yaml:"plugins"`
+	CustomResolvers    []string              `mapstructure:"custom-resolvers" yaml:"custom-resolvers"`
+	MaxConcurrentTasks int                   `mapstructure:"max-concurrent-tasks" yaml:"max-concurrent-tasks"`
+	MaxWaitingRequests int                   `mapstructure:"max-waiting-requests" yaml:"max-waiting-requests"`
+	MTLS               MTLSConfig            `mapstructure:"mtls" yaml:"mtls"`
+	Keepalive          KeepaliveConfig       `mapstructure:"keepalive" yaml:"keepalive"`
+	Compression        bool                  `mapstructure:"compression" yaml:"compression"` // gzip the messages sent to the manager.
+	Log                LogConfig             `mapstructure:"log" yaml:"log"`
 }
 
-type MTLSConfig struct {
+// PluginConfig configures the process of a plugin.
+//
+// A plugin with environment variables does not inherit the environment of the node.
+type PluginConfig struct {
+	Plugin  string   `mapstructure:"plugin" yaml:"plugin"`   // Plugin file.
+	Env     []string `mapstructure:"env" yaml:"env"`         // KEY=value, overriding the variables of the plugin manifest.
+	Workdir string   `mapstructure:"workdir" yaml:"workdir"` // Working directory, the one of the node if empty.
+	RunAs   string   `mapstructure:"run-as" yaml:"run-as"`   // User name or uid (Linux only), the user of the node if empty.
+}
+
+// NodePluginsConfig restricts the plugins run by the node.
+type NodePluginsConfig struct {
+	Allowed []string `mapstructure:"allowed" yaml:"allowed"` // Plugin files the node may load, all if empty.
+}
+
+// PluginServerTLSConfig enables the HTTPS download of the plugins, verifying the certificate of the manager.
+type PluginServerTLSConfig struct {
 	Enabled   bool   `mapstructure:"enabled" yaml:"enabled"`
-	Key       string `mapstructure:"key" yaml:"key"`
-	Cert      string `mapstructure:"cert" yaml:"cert"`
-	ManagerCA string `mapstructure:"manager-ca-cert" yaml:"manager-ca-cert"`
+	ManagerCA string `mapstructure:"manager-ca-cert" yaml:"manager-ca-cert"` // The mtls.manager-ca-cert if empty, the system CAs if both are empty.
+}
+
+type MTLSConfig struct {
+	Enabled   bool         `mapstructure:"enabled" yaml:"enabled"`
+	Key       string       `mapstructure:"key" yaml:"key"`
+	Cert      string       `mapstructure:"cert" yaml:"cert"`
+	ManagerCA string       `mapstructure:"manager-ca-cert" yaml:"manager-ca-cert"`
+	SPIFFE    SPIFFEConfig `mapstructure:"spiffe" yaml:"spiffe"` // Replaces the certificate files when enabled.
 }
 
 type ManagerConfig struct {
-	ManagerID        string            `mapstructure:"manager-id" yaml:"manager-id"`
-	ConfigDir        string            `mapstructure:"config-dir" yaml:"config-dir"`
-	ListenAddress    string            `mapstructure:"address" yaml:"address"`
-	ListenPort       string            `mapstructure:"port" yaml:"port"`
-	PluginDir        string            `mapstructure:"plugin-dir" yaml:"plugin-dir"`
-	PluginServerPort string            `mapstructure:"plugin-server-port" yaml:"plugin-server-port"`
-	AutoAcceptNode   bool              `mapstructure:"auto-accept-node" yaml:"auto-accept-node"`
-	MTLS             ManagerMTLSConfig `mapstructure:"mtls" yaml:"mtls"`
-	API              APIConfig         `mapstructure:"api" yaml:"api"`
+	ManagerID        string                       `mapstructure:"manager-id" yaml:"manager-id"`
+	ConfigDir        string                       `mapstructure:"config-dir" yaml:"config-dir"`
+	ListenAddress    string                       `mapstructure:"address" yaml:"address"`
+	ListenPort       string                       `mapstructure:"port" yaml:"port"`
+	PluginDirs       PathList                     `mapstructure:"plugin-dir" yaml:"plugin-dir"`
+	CreatePluginDir  bool                         `mapstructure:"create-plugin-dir" yaml:"create-plugin-dir"` // A missing plugin directory is an error if disabled.
+	PluginServerPort string                       `mapstructure:"plugin-server-port" yaml:"plugin-server-port"`
+	PluginServerTLS  ManagerPluginServerTLSConfig `mapstructure:"plugin-server-tls" yaml:"plugin-server-tls"`
+	AutoAcceptNode   bool                         `mapstructure:"auto-accept-node" yaml:"auto-accept-node"`
+	AutoAccept       AutoAcceptConfig             `mapstructure:"auto-accept" yaml:"auto-accept"` // Restricts auto-accept-node.
+	MaxInflight      int                          `mapstructure:"max-inflight-requests" yaml:"max-inflight-requests"`
+	Node             ManagerNodeConfig            `mapstructure:"node" yaml:"node"`
+	MTLS             ManagerMTLSConfig            `mapstructure:"mtls" yaml:"mtls"`
+	Keepalive        ManagerKeepaliveConfig       `mapstructure:"keepalive" yaml:"keepalive"`
+	Compression      bool                         `mapstructure:"compression" yaml:"compression"`           // gzip the messages sent to the nodes.
+	DegradedStorage  bool                         `mapstructure:"degraded-storage" yaml:"degraded-storage"` // Keep dispatching the tasks if the database cannot be opened.
+	API              APIConfig                    `mapstructure:"api" yaml:"api"`
+	CLI              CLIConfig                    `mapstructure:"cli" yaml:"cli"`
+	Notifications    NotificationsConfig          `mapstructure:"notifications" yaml:"notifications"`
+	Upstream         UpstreamConfig               `mapstructure:"upstream" yaml:"upstream"`
+	Log              LogConfig                    `mapstructure:"log" yaml:"log"`
 }
 
-type ManagerMTLSConfig struct {
+// UpstreamConfig connects the manager to an upstream manager as a node, making it a syndic: the runs of the
+// upstream manager targeting it are dispatched to the nodes of the manager.
+type UpstreamConfig struct {
+	Enabled        bool            `mapstructure:"enabled" yaml:"enabled"`
+	ID             string          `mapstructure:"id" yaml:"id"` // Node ID of the syndic on the upstream manager, the manager ID if empty.
+	Address        string          `mapstructure:"address" yaml:"address"`
+	Port           string          `mapstructure:"port" yaml:"port"`
+	ReconnectDelay int             `mapstructure:"reconnect-delay" yaml:"reconnect-delay"`         // In seconds.
+	MaxRuns        int             `mapstructure:"max-concurrent-runs" yaml:"max-concurrent-runs"` // Runs of the upstream manager dispatched concurrently.
+	MTLS           MTLSConfig      `mapstructure:"mtls" yaml:"mtls"`                               // The manager CA is the one of the upstream manager.
+	Keepalive      KeepaliveConfig `mapstructure:"keepalive" yaml:"keepalive"`
+}
+
+func (c *UpstreamConfig) validate(managerID string) error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.ID == "" {
+		c.ID = managerID
+	}
+	if c.ID == "" {
+		return errors.New("the upstream manager connection (upstream.enabled) requires a node ID (upstream.id or manager-id)")
+	}
+	if err := ValidateNodeID(c.ID); err != nil {
+		return fmt.Errorf("upstream: %w", err)
+	}
+	if c.Address == "" {
+		return errors.New("the upstream manager connection (upstream.enabled) requires its address (upstream.address)")
+	}
+	if c.MaxRuns <= 0 {
+		return fmt.Errorf("invalid maximum of concurrent upstream runs (upstream.max-concurrent-runs): %d", c.MaxRuns)
+	}
+	if err := validateSPIFFE(c.MTLS.Enabled, c.MTLS.SPIFFE); err != nil {
+		return fmt.Errorf("upstream: %w", err)
+	}
+	return c.Keepalive.validate("upstream.keepalive")
+}
+
+// LogConfig is the logging configuration, the debug level can also be toggled at runtime with SIGUSR1.
+type LogConfig struct {
+	Level  string `mapstructure:"level" yaml:"level"`   // debug, info, warn or error.
+	Format string `mapstructure:"format" yaml:"format"` // LogFormatText or LogFormatJSON.
+}
+
+type ManagerNodeConfig struct {
+	ActiveThreshold   int `mapstructure:"active-threshold" yaml:"active-threshold"`     // In seconds.
+	EventDebounce     int `mapstructure:"event-debounce" yaml:"event-debounce"`         // In seconds.
+	HeartbeatInterval int `mapstructure:"heartbeat-interval" yaml:"heartbeat-interval"` // In seconds, 0 disables the heartbeats.
+	HeartbeatTimeout  int `mapstructure:"heartbeat-timeout" yaml:"heartbeat-timeout"`   // In seconds.
+	// ResponseGrace is the wait after the timeout of a task for the response of a node, in seconds. The node
+	// answers by itself once the timeout is reached, it is considered unresponsive after the grace.
+	ResponseGrace int `mapstructure:"response-grace" yaml:"response-grace"`
+	// AcceptanceWait is the wait for a node not accepted yet before closing its task stream, in seconds, 0 to wait
+	// until it is accepted. The node opens a new one after its retry delay.
+	AcceptanceWait int `mapstructure:"acceptance-wait" yaml:"acceptance-wait"`
+	// Limits override the queue limits of the nodes, the first entry matching a node applies.
+	Limits []NodeLimitsConfig `mapstructure:"limits" yaml:"limits"`
+	// SpecsDriftIgnore are the globs of the volatile spec paths (e.g. hardware.memory.free), their changes are not
+	// drifts. A glob matching a path ignores its sub-paths too.
+	SpecsDriftIgnore []string `mapstructure:"specs-drift-ignore" yaml:"specs-drift-ignore"`
+}
+
+// NodeLimitsConfig overrides the max-concurrent-tasks and max-waiting-requests of the matching nodes, sent to them
+// during the handshake.
+type NodeLimitsConfig struct {
+	Nodes              []string `mapstructure:"nodes" yaml:"nodes"`                               // Node ID globs.
+	MaxConcurrentTasks int      `mapstructure:"max-concurrent-tasks" yaml:"max-concurrent-tasks"` // 0 keeps the node configuration.
+	MaxWaitingRequests int      `mapstructure:"max-waiting-requests" yaml:"max-waiting-requests"` // 0 keeps the node configuration.
+}
+
+// Match returns true if one of the globs matches the node ID.
+func (c NodeLimitsConfig) Match(id string) bool {
+	return MatchNodeGlobs(c.Nodes, id)
+}
+
+// MatchNodeGlobs returns true if one of the globs matches the node ID.
+func MatchNodeGlobs(globs []string, id string) bool {
+	for _, pattern := range globs {
+		if ok, err := filepath.Match(pattern, id); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AutoAcceptConfig restricts the auto-acceptance of the new nodes, the other ones become candidates waiting for
+// their manual acceptance.
+type AutoAcceptConfig struct {
+	Nodes  []string `mapstructure:"nodes" yaml:"nodes"`     // Node ID globs, all the nodes if empty.
+	CACert string   `mapstructure:"ca-cert" yaml:"ca-cert"` // Only the nodes whose certificate is issued by this CA, any if empty.
+}
+
+func (c AutoAcceptConfig) validate(mtls ManagerMTLSConfig) error {
+	for _, pattern := range c.Nodes {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid auto-accept node glob (auto-accept.nodes) '%s': %w", pattern, err)
+		}
+	}
+	if c.CACert != "" && !mtls.Enabled {
+		return errors.New("the auto-accept CA (auto-accept.ca-cert) requires mTLS (mtls.enabled)")
+	}
+	return nil
+}
+
+// validate checks the heartbeats detecting the half-open task streams, and the queue limits overrides.
+func (c ManagerNodeConfig) validate() error {
+	if c.HeartbeatInterval < 0 {
+		return fmt.Errorf("invalid heartbeat interval (node.heartbeat-interval): positive delay or 0 expected, got %d", c.HeartbeatInterval)
+	}
+	// a heartbeat is sent once the previous one is answered: a longer timeout would delay the next ones
+	if c.HeartbeatInterval > 0 && (c.HeartbeatTimeout <= 0 || c.HeartbeatTimeout > c.HeartbeatInterval) {
+		return fmt.Errorf("invalid heartbeat timeout (node.heartbeat-timeout): positive delay up to the interval (%d) expected, got %d", c.HeartbeatInterval, c.HeartbeatTimeout)
+	}
+	if c.ResponseGrace < 0 {
+		return fmt.Errorf("invalid response grace (node.response-grace): positive delay or 0 expected, got %d", c.ResponseGrace)
+	}
+	if c.AcceptanceWait < 0 {
+		return fmt.Errorf("invalid acceptance wait (node.acceptance-wait): positive delay or 0 expected, got %d", c.AcceptanceWait)
+	}
+	for i, l := range c.Limits {
+		if len(l.Nodes) == 0 {
+			return fmt.Errorf("invalid limits (node.limits[%d]): at least one node glob expected", i)
+		}
+		for _, pattern := range l.Nodes {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid limits (node.limits[%d]): invalid node glob '%s': %w", i, pattern, err)
+			}
+		}
+		if l.MaxConcurrentTasks < 0 || l.MaxWaitingRequests < 0 {
+			return fmt.Errorf("invalid limits (node.limits[%d]): positive limits or 0 expected", i)
+		}
+	}
+	for _, pattern := range c.SpecsDriftIgnore {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid spec path glob (node.specs-drift-ignore) '%s': %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// ManagerPluginServerTLSConfig serves the plugins over HTTPS.
+type ManagerPluginServerTLSConfig struct {
 	Enabled bool   `mapstructure:"enabled" yaml:"enabled"`
-	Key     string `mapstructure:"key" yaml:"key"`
-	Cert    string `mapstructure:"cert" yaml:"cert"`
-	NodeCA  string `mapstructure:"node-ca-cert" yaml:"node-ca-cert"`
+	Cert    string `mapstructure:"cert" yaml:"cert"` // The mtls.cert if empty.
+	Key     string `mapstructure:"key" yaml:"key"`   // The mtls.key if empty.
+}
+
+// validate defaults the certificate to the one of the nodes connections.
+func (c *ManagerPluginServerTLSConfig) validate(mtls ManagerMTLSConfig) error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Cert == "" && c.Key == "" {
+		c.Cert, c.Key = mtls.Cert, mtls.Key
+	}
+	if c.Cert == "" || c.Key == "" {
+		return errors.New("the plugin server TLS (plugin-server-tls.enabled) requires a certificate and a key (plugin-server-tls.cert, plugin-server-tls.key or mtls.cert, mtls.key)")
+	}
+	return nil
+}
+
+type ManagerMTLSConfig struct {
+	Enabled     bool         `mapstructure:"enabled" yaml:"enabled"`
+	Require     bool         `mapstructure:"require" yaml:"require"`             // Refuse the nodes without certificate, and to start without mTLS.
+	MatchNodeID bool         `mapstructure:"match-node-id" yaml:"match-node-id"` // Refuse the nodes whose certificate is not issued for their node ID, opt-in.
+	Key         string       `mapstructure:"key" yaml:"key"`
+	Cert        string       `mapstructure:"cert" yaml:"cert"`
+	NodeCA      string       `mapstructure:"node-ca-cert" yaml:"node-ca-cert"`
+	SPIFFE      SPIFFEConfig `mapstructure:"spiffe" yaml:"spiffe"` // Replaces the certificate files when enabled.
 }
 
 type APIConfig struct {
@@ -60,19 +291,90 @@ type APIConfig struct {
 	TLS     APITLSConfig `mapstructure:"tls" yaml:"tls"`
 }
 
+// CLIConfig configures the access to the relay gRPC server used by jack and the HTTP API: the local unix socket,
+// and an optional mTLS listener for the remote access.
+//
+// The relay does not check the API permissions: any user allowed by the socket permissions or any client with a
+// certificate of the CA has a full access.
+type CLIConfig struct {
+	SocketMode  string          `mapstructure:"socket-mode" yaml:"socket-mode"`   // Octal permissions of the socket, the others cannot be granted any.
+	SocketGroup string          `mapstructure:"socket-group" yaml:"socket-group"` // Group owning the socket, unchanged if empty.
+	Remote      CLIRemoteConfig `mapstructure:"remote" yaml:"remote"`
+	Reflection  bool            `mapstructure:"reflection" yaml:"reflection"` // Register the gRPC reflection service, e.g. for grpcurl.
+}
+
+type CLIRemoteConfig struct {
+	Enabled  bool   `mapstructure:"enabled" yaml:"enabled"`
+	Address  string `mapstructure:"address" yaml:"address"`
+	Port     string `mapstructure:"port" yaml:"port"`
+	Cert     string `mapstructure:"cert" yaml:"cert"`
+	Key      string `mapstructure:"key" yaml:"key"`
+	ClientCA string `mapstructure:"client-ca-cert" yaml:"client-ca-cert"`
+}
+
+// SocketFileMode returns the permissions of the CLI socket.
+func (c CLIConfig) SocketFileMode() (fs.FileMode, error) {
+	mode, err := strconv.ParseUint(c.SocketMode, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid CLI socket mode (cli.socket-mode) '%s': octal permissions expected", c.SocketMode)
+	}
+	if mode&0o007 != 0 {
+		return 0, fmt.Errorf("invalid CLI socket mode (cli.socket-mode) '%s': the others must not have any permission", c.SocketMode)
+	}
+	return fs.FileMode(mode), nil
+}
+
+func (c CLIConfig) validate() error {
+	if _, err := c.SocketFileMode(); err != nil {
+		return err
+	}
+	if c.Remote.Enabled && (c.Remote.Cert == "" || c.Remote.Key == "" || c.Remote.ClientCA == "") {
+		return errors.New("the remote CLI access (cli.remote.enabled) requires a certificate, a key and a client CA (cli.remote.cert, cli.remote.key, cli.remote.client-ca-cert)")
+	}
+	return nil
+}
+
 type APITLSConfig struct {
 	Enabled bool   `mapstructure:"enabled" yaml:"enabled"`
 	Cert    string `mapstructure:"cert" yaml:"cert"`
 	Key     string `mapstructure:"key" yaml:"key"`
 }
 
+type NotificationsConfig struct {
+	Webhooks []WebhookConfig `mapstructure:"webhooks" yaml:"webhooks"`
+}
+
+type WebhookConfig struct {
+	URL           string   `mapstructure:"url" yaml:"url"`
+	OnFailureOnly bool     `mapstructure:"on-failure-only" yaml:"on-failure-only"`
+	Plugins       []string `mapstructure:"plugins" yaml:"plugins"`         // Plugin name globs, all plugins if empty.
+	Timeout       int      `mapstructure:"timeout" yaml:"timeout"`         // In seconds, DefaultWebhookTimeout if not set.
+	Format        string   `mapstructure:"format" yaml:"format"`           // WebhookFormatEvent (default) or WebhookFormatSummary.
+	NodeEvents    bool     `mapstructure:"node-events" yaml:"node-events"` // Also notify when a node becomes stale or active again, when its specs change, or when a node is auto-accepted or waiting for its acceptance.
+}
+
+func (c NotificationsConfig) validate() error {
+	for i, w := range c.Webhooks {
+		switch w.Format {
+		case "", WebhookFormatEvent, WebhookFormatSummary:
+		default:
+			return fmt.Errorf("invalid webhook format (notifications.webhooks[%d].format) '%s': must be '%s' or '%s'", i, w.Format, WebhookFormatEvent, WebhookFormatSummary)
+		}
+	}
+	return nil
+}
+
 func SetupNodeFlags() {
 	pflag.String("id", "", "set node ID")
 	pflag.String("manager-address", DefaultManagerAddress, "set manager address")
 	pflag.String("manager-port", DefaultManagerPort, "set manager port")
 	pflag.Int("reconnect-delay", int(DefaultReconnectDelay.Seconds()), "delay between reconnect attempts to the manager, in seconds")
-	pflag.String("plugin-dir", DefaultNodePluginDir, "installed plugin directory")
+	pflag.String("plugin-dir", DefaultNodePluginDir, "installed plugin directories (colon-separated, by order of precedence)")
 	pflag.String("plugin-server-port", DefaultPluginServerPort, "manager port used to serve plugins")
+	pflag.Bool("plugin-server-tls.enabled", false, "download the plugins over HTTPS")
+	pflag.String("plugin-server-tls.manager-ca-cert", "", "CA certificate of the plugin server filepath (mtls.manager-ca-cert if empty)")
+	pflag.Int("plugin-sync-interval", 0, "synchronize the plugins with the manager every N seconds, 0 to only synchronize them on demand (plugins.sync)")
+	pflag.StringSlice("plugins.allowed", nil, "only load the plugin files listed, even if synchronized from the manager (comma-separated, all if empty)")
 	pflag.StringSlice("custom-resolvers", []string{}, "custom DNS resolvers for GRPC connections (comma-separated)")
 	pflag.Int("max-concurrent-tasks", DefaultMaxConcurrentTasks, "maximum number of tasks that can run concurrently (0 = use default)")
 	pflag.Int("max-waiting-requests", DefaultMaxWaitingRequests, "maximum number of requests that can wait in queue (0 = use default)")
@@ -80,6 +382,13 @@ func SetupNodeFlags() {
 	pflag.String("mtls.key", "", "node TLS key filepath")
 	pflag.String("mtls.cert", "", "node TLS certificate filepath")
 	pflag.String("mtls.manager-ca-cert", "", "manager TLS certificate filepath")
+	setupSPIFFEFlags("manager")
+	pflag.Int("keepalive.time", int(ClientKeepaliveTime.Seconds()), "idle delay before pinging the manager, in seconds (not lower than the manager keepalive.min-time)")
+	pflag.Int("keepalive.timeout", int(ClientKeepaliveTimeout.Seconds()), "wait for the ping ack before closing the connection to the manager, in seconds")
+	pflag.Bool("keepalive.permit-without-stream", true, "ping the manager even without task stream")
+	pflag.Bool("compression", false, "compress (gzip) the messages sent to the manager, e.g. on WAN links")
+	pflag.String("log.level", DefaultLogLevel, "log level: debug, info, warn or error (SIGUSR1 toggles debug)")
+	pflag.String("log.format", LogFormatText, "log format: text or json")
 	pflag.String("config", "", "config file path")
 }
 
@@ -88,19 +397,60 @@ func SetupManagerFlags() {
 	pflag.String("config-dir", DefaultConfigDir, "configuration directory")
 	pflag.String("address", DefaultManagerAddress, "set manager address")
 	pflag.String("port", DefaultManagerPort, "set manager port")
-	pflag.String("plugin-dir", DefaultPluginDir, "plugin inventory directory")
+	pflag.String("plugin-dir", DefaultPluginDir, "plugin inventory directories (colon-separated, by order of precedence)")
+	pflag.Bool("create-plugin-dir", true, "create the missing plugin directories at startup, fail if disabled")
 	pflag.String("plugin-server-port", DefaultPluginServerPort, "set manager port used to serve plugins")
+	pflag.Bool("plugin-server-tls.enabled", false, "serve the plugins over HTTPS")
+	pflag.String("plugin-server-tls.cert", "", "plugin server TLS certificate filepath (mtls.cert if empty)")
+	pflag.String("plugin-server-tls.key", "", "plugin server TLS key filepath (mtls.key if empty)")
 	pflag.Bool("auto-accept-node", false, "auto accept new nodes")
+	pflag.StringSlice("auto-accept.nodes", nil, "only auto accept the nodes matching one of the node ID globs (comma-separated)")
+	pflag.String("auto-accept.ca-cert", "", "only auto accept the nodes whose certificate is issued by this CA")
+	pflag.Int("max-inflight-requests", DefaultMaxInflightRequests, "maximum number of requests awaiting a response, per node (0 = unlimited)")
+	pflag.Int("node.active-threshold", int(NodeActiveThreshold.Seconds()), "delay without message after which a node is considered inactive, in seconds")
+	pflag.Int("node.event-debounce", int(NodeEventDebounce.Seconds()), "delay during which a node activity change must last before being notified, in seconds")
+	pflag.Int("node.heartbeat-interval", int(HeartbeatInterval.Seconds()), "delay between two pings of the task streams of the nodes, in seconds (0 to disable)")
+	pflag.Int("node.heartbeat-timeout", int(HeartbeatTimeout.Seconds()), "wait for the answer to a ping before closing the task stream of a node, in seconds")
+	pflag.Int("node.response-grace", int(NodeResponseGrace.Seconds()), "wait after the timeout of a task for the response of a node before considering it unresponsive, in seconds")
+	pflag.Int("node.acceptance-wait", int(NodeAcceptanceWait.Seconds()), "wait for a node to be accepted before closing its task stream, in seconds, 0 for unlimited")
 	pflag.Bool("mtls.enabled", true, "secure connections to nodes using mTLS, recommended: true")
+	pflag.Bool("mtls.require", false, "refuse the nodes without mTLS certificate, the manager does not start with mTLS disabled")
+	pflag.Bool("mtls.match-node-id", false, "refuse the nodes whose certificate is not issued for their node ID (common name, DNS name or SPIFFE ID), recommended unless the certificates are not issued per node")
 	pflag.String("mtls.key", "", "manager TLS key filepath")
 	pflag.String("mtls.cert", "", "manager TLS certificate filepath")
 	pflag.String("mtls.node-ca-cert", "", "node TLS certificate filepath")
+	setupSPIFFEFlags("nodes")
+	pflag.Int("keepalive.time", int(KeepaliveTime.Seconds()), "idle delay before pinging a node, in seconds")
+	pflag.Int("keepalive.timeout", int(KeepaliveTimeout.Seconds()), "wait for the ping ack before closing the connection to a node, in seconds")
+	pflag.Int("keepalive.min-time", int(KeepaliveMinTime.Seconds()), "close the connections of the nodes pinging more often, in seconds")
+	pflag.Bool("keepalive.permit-without-stream", true, "accept the pings of the nodes without task stream")
+	pflag.Bool("compression", false, "compress (gzip) the messages sent to the nodes, e.g. on WAN links")
+	pflag.Bool("degraded-storage", false, "keep dispatching the tasks without storing the results if the database cannot be opened")
 	pflag.Bool("api.enabled", true, "enable HTTP REST API")
 	pflag.String("api.address", DefaultAPIAddress, "HTTP API listen address")
 	pflag.String("api.port", DefaultAPIPort, "HTTP API listen port")
 	pflag.Bool("api.tls.enabled", false, "enable TLS for HTTP REST API")
 	pflag.String("api.tls.cert", "", "API TLS certificate filepath")
 	pflag.String("api.tls.key", "", "API TLS key filepath")
+	pflag.String("cli.socket-mode", DefaultCLISocketMode, "octal permissions of the CLI socket, the others cannot be granted any")
+	pflag.String("cli.socket-group", "", "group owning the CLI socket (e.g. to allow its members with socket-mode 0770)")
+	pflag.Bool("cli.remote.enabled", false, "enable the remote CLI access using mTLS")
+	pflag.String("cli.remote.address", DefaultManagerAddress, "remote CLI access listen address")
+	pflag.String("cli.remote.port", DefaultCLIRemotePort, "remote CLI access listen port")
+	pflag.String("cli.remote.cert", "", "remote CLI access TLS certificate filepath")
+	pflag.String("cli.remote.key", "", "remote CLI access TLS key filepath")
+	pflag.String("cli.remote.client-ca-cert", "", "CLI client CA certificate filepath")
+	pflag.Bool("cli.reflection", false, "register the gRPC reflection service on the CLI servers (e.g. for grpcurl)")
+	pflag.Bool("upstream.enabled", false, "connect to an upstream manager as a node (syndic), dispatching its runs to the nodes")
+	pflag.String("upstream.id", "", "node ID on the upstream manager (default: manager ID)")
+	pflag.String("upstream.address", "", "upstream manager address")
+	pflag.String("upstream.port", DefaultManagerPort, "upstream manager port")
+	pflag.Bool("upstream.mtls.enabled", true, "secure the connection to the upstream manager using mTLS, recommended: true")
+	pflag.String("upstream.mtls.key", "", "TLS key filepath of the upstream connection")
+	pflag.String("upstream.mtls.cert", "", "TLS certificate filepath of the upstream connection")
+	pflag.String("upstream.mtls.manager-ca-cert", "", "upstream manager CA certificate filepath")
+	pflag.String("log.level", DefaultLogLevel, "log level: debug, info, warn or error (SIGUSR1 toggles debug)")
+	pflag.String("log.format", LogFormatText, "log format: text or json")
 	pflag.String("config", "", "config file path")
 }
 
@@ -121,6 +471,9 @@ func LoadNodeConfig(configFile string) (*NodeConfig, error) {
 	v.SetDefault("reconnect-delay", int(DefaultReconnectDelay.Seconds()))
 	v.SetDefault("plugin-dir", DefaultNodePluginDir)
 	v.SetDefault("plugin-server-port", DefaultPluginServerPort)
+	v.SetDefault("plugin-server-tls.enabled", false)
+	v.SetDefault("plugin-server-tls.manager-ca-cert", "")
+	v.SetDefault("plugin-sync-interval", 0)
 	v.SetDefault("custom-resolvers", []string{})
 	v.SetDefault("max-concurrent-tasks", DefaultMaxConcurrentTasks)
 	v.SetDefault("max-waiting-requests", DefaultMaxWaitingRequests)
@@ -129,6 +482,12 @@ func LoadNodeConfig(configFile string) (*NodeConfig, error) {
 	v.SetDefault("mtls.key", "")
 	v.SetDefault("mtls.cert", "")
 	v.SetDefault("mtls.manager-ca-cert", "")
+	setSPIFFEDefaults(v)
+	setKeepaliveDefaults(v, "keepalive")
+	v.SetDefault("compression", false)
+
+	v.SetDefault("log.level", DefaultLogLevel)
+	v.SetDefault("log.format", LogFormatText)
 
 	v.SetEnvPrefix("JACKADI_NODE")
 	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
@@ -159,7 +518,7 @@ func LoadNodeConfig(configFile string) (*NodeConfig, error) {
 	v.RegisterAlias("node-id", "id")
 
 	var config NodeConfig
-	if err := v.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config, decodeHook); err != nil {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
@@ -168,16 +527,74 @@ func LoadNodeConfig(configFile string) (*NodeConfig, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to get hostname, please set the node-id")
 		}
-		config.NodeID = hostname
+		// the hostname is not chosen as a node ID by the user: it is fixed instead of rejected
+		config.NodeID, err = NormalizeNodeID(hostname)
+		if err != nil {
+			return nil, fmt.Errorf("unusable hostname, please set the node-id: %w", err)
+		}
+	}
+	if err := ValidateNodeID(config.NodeID); err != nil {
+		return nil, err
+	}
+
+	if err := validateSPIFFE(config.MTLS.Enabled, config.MTLS.SPIFFE); err != nil {
+		return nil, err
+	}
+
+	if err := config.Keepalive.validate("keepalive"); err != nil {
+		return nil, err
+	}
+
+	if config.PluginServerTLS.Enabled && config.PluginServerTLS.ManagerCA == "" {
+		config.PluginServerTLS.ManagerCA = config.MTLS.ManagerCA
 	}
 
-	if err := os.MkdirAll(config.PluginDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to initialize plugin directory '%s': %w", config.PluginDir, err)
+	if len(config.PluginDirs) == 0 {
+		return nil, errors.New("no plugin directory configured")
+	}
+
+	// only the first directory is managed by the node, the other ones are provided by the user
+	if err := os.MkdirAll(config.PluginDirs[0], 0755); err != nil {
+		return nil, fmt.Errorf("failed to initialize plugin directory '%s': %w", config.PluginDirs[0], err)
 	}
 
 	return &config, nil
 }
 
+// InitPluginDirs resolves the plugin directories to absolute paths, and creates the missing ones unless
+// disabled (create-plugin-dir), in which case a missing directory is an error: the plugins of a mistyped
+// directory would silently not be served.
+//
+// It returns the created directories.
+func (c *ManagerConfig) InitPluginDirs() ([]string, error) {
+	var created []string
+	for i, dir := range c.PluginDirs {
+		path, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plugin directory '%s': %w", dir, err)
+		}
+		c.PluginDirs[i] = path
+
+		info, err := os.Stat(path)
+		switch {
+		case err == nil && !info.IsDir():
+			return nil, fmt.Errorf("invalid plugin directory '%s': not a directory", path)
+		case err == nil:
+			continue
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("failed to access plugin directory '%s': %w", path, err)
+		case !c.CreatePluginDir:
+			return nil, fmt.Errorf("plugin directory '%s' does not exist (create-plugin-dir disabled)", path)
+		}
+
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return nil, fmt.Errorf("failed to initialize plugin directory '%s': %w", path, err)
+		}
+		created = append(created, path)
+	}
+	return created, nil
+}
+
 func LoadManagerConfig(configFile string) (*ManagerConfig, error) {
 	finder := locafero.Finder{
 		Paths: []string{".", "/etc/jackadi"},
@@ -198,13 +615,35 @@ func LoadManagerConfig(configFile string) (*ManagerConfig, error) {
 	v.SetDefault("address", DefaultManagerAddress)
 	v.SetDefault("port", DefaultManagerPort)
 	v.SetDefault("plugin-dir", DefaultPluginDir)
+	v.SetDefault("create-plugin-dir", true)
 	v.SetDefault("plugin-server-port", DefaultPluginServerPort)
+	v.SetDefault("plugin-server-tls.enabled", false)
+	v.SetDefault("plugin-server-tls.cert", "")
+	v.SetDefault("plugin-server-tls.key", "")
 	v.SetDefault("auto-accept-node", false)
+	v.SetDefault("auto-accept.ca-cert", "")
+	v.SetDefault("max-inflight-requests", DefaultMaxInflightRequests)
+	v.SetDefault("node.active-threshold", int(NodeActiveThreshold.Seconds()))
+	v.SetDefault("node.event-debounce", int(NodeEventDebounce.Seconds()))
+	v.SetDefault("node.heartbeat-interval", int(HeartbeatInterval.Seconds()))
+	v.SetDefault("node.heartbeat-timeout", int(HeartbeatTimeout.Seconds()))
+	v.SetDefault("node.response-grace", int(NodeResponseGrace.Seconds()))
+	v.SetDefault("node.acceptance-wait", int(NodeAcceptanceWait.Seconds()))
 
 	v.SetDefault("mtls.enabled", true)
+	v.SetDefault("mtls.require", false)
+	v.SetDefault("mtls.match-node-id", false)
 	v.SetDefault("mtls.cert", "")
 	v.SetDefault("mtls.key", "")
 	v.SetDefault("mtls.node-ca-cert", "")
+	setSPIFFEDefaults(v)
+
+	v.SetDefault("keepalive.time", int(KeepaliveTime.Seconds()))
+	v.SetDefault("keepalive.timeout", int(KeepaliveTimeout.Seconds()))
+	v.SetDefault("keepalive.min-time", int(KeepaliveMinTime.Seconds()))
+	v.SetDefault("keepalive.permit-without-stream", true)
+	v.SetDefault("compression", false)
+	v.SetDefault("degraded-storage", false)
 
 	v.SetDefault("api.enabled", true)
 	v.SetDefault("api.address", DefaultAPIAddress)
@@ -213,6 +652,34 @@ func LoadManagerConfig(configFile string) (*ManagerConfig, error) {
 	v.SetDefault("api.tls.cert", "")
 	v.SetDefault("api.tls.key", "")
 
+	v.SetDefault("cli.socket-mode", DefaultCLISocketMode)
+	v.SetDefault("cli.socket-group", "")
+	v.SetDefault("cli.remote.enabled", false)
+	v.SetDefault("cli.remote.address", DefaultManagerAddress)
+	v.SetDefault("cli.remote.port", DefaultCLIRemotePort)
+	v.SetDefault("cli.remote.cert", "")
+	v.SetDefault("cli.remote.key", "")
+	v.SetDefault("cli.remote.client-ca-cert", "")
+	v.SetDefault("cli.reflection", false)
+
+	v.SetDefault("upstream.enabled", false)
+	v.SetDefault("upstream.id", "")
+	v.SetDefault("upstream.address", "")
+	v.SetDefault("upstream.port", DefaultManagerPort)
+	v.SetDefault("upstream.reconnect-delay", int(DefaultReconnectDelay.Seconds()))
+	v.SetDefault("upstream.max-concurrent-runs", DefaultSyndicMaxRuns)
+	v.SetDefault("upstream.mtls.enabled", true)
+	v.SetDefault("upstream.mtls.key", "")
+	v.SetDefault("upstream.mtls.cert", "")
+	v.SetDefault("upstream.mtls.manager-ca-cert", "")
+	v.SetDefault("upstream.mtls.spiffe.enabled", false)
+	v.SetDefault("upstream.mtls.spiffe.socket", "")
+	v.SetDefault("upstream.mtls.spiffe.trust-domain", "")
+	setKeepaliveDefaults(v, "upstream.keepalive")
+
+	v.SetDefault("log.level", DefaultLogLevel)
+	v.SetDefault("log.format", LogFormatText)
+
 	v.SetEnvPrefix("JACKADI_MANAGER")
 	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
 	v.AutomaticEnv()
@@ -230,9 +697,49 @@ func LoadManagerConfig(configFile string) (*ManagerConfig, error) {
 	v.RegisterAlias("manager-id", "id")
 
 	var config ManagerConfig
-	if err := v.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config, decodeHook); err != nil {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
+	if len(config.PluginDirs) == 0 {
+		return nil, errors.New("no plugin directory configured")
+	}
+
+	if config.MTLS.Require && !config.MTLS.Enabled {
+		return nil, errors.New("mTLS is required (mtls.require) but disabled (mtls.enabled)")
+	}
+
+	if err := validateSPIFFE(config.MTLS.Enabled, config.MTLS.SPIFFE); err != nil {
+		return nil, err
+	}
+
+	if err := config.PluginServerTLS.validate(config.MTLS); err != nil {
+		return nil, err
+	}
+
+	if err := config.AutoAccept.validate(config.MTLS); err != nil {
+		return nil, err
+	}
+
+	if err := config.Node.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Keepalive.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.CLI.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Upstream.validate(config.ManagerID); err != nil {
+		return nil, err
+	}
+
+	if err := config.Notifications.validate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
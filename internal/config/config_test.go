@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -102,13 +105,15 @@ func TestLoadNodeConfig_Default(t *testing.T) {
 	}
 
 	hostname, _ := os.Hostname()
+	nodeID, _ := NormalizeNodeID(hostname)
 	expected := &NodeConfig{
-		NodeID:             hostname,
+		NodeID:             nodeID,
 		ManagerAddress:     DefaultManagerAddress,
 		ManagerPort:        DefaultManagerPort,
 		ReconnectDelay:     int(DefaultReconnectDelay.Seconds()),
-		PluginDir:          tempPluginDir,
+		PluginDirs:         PathList{tempPluginDir},
 		PluginServerPort:   DefaultPluginServerPort,
+		Plugins:            NodePluginsConfig{Allowed: []string{}},
 		CustomResolvers:    []string{},
 		MaxConcurrentTasks: DefaultMaxConcurrentTasks,
 		MaxWaitingRequests: DefaultMaxWaitingRequests,
@@ -118,14 +123,16 @@ func TestLoadNodeConfig_Default(t *testing.T) {
 			Cert:      "",
 			ManagerCA: "",
 		},
+		Keepalive: KeepaliveConfig{Time: 10, Timeout: 30, PermitWithoutStream: true},
+		Log:       LogConfig{Level: DefaultLogLevel, Format: LogFormatText},
 	}
 
 	if diff := cmp.Diff(got, expected); diff != "" {
 		t.Errorf("Config mismatch:\n%s", diff)
 	}
 
-	if _, err := os.Stat(got.PluginDir); os.IsNotExist(err) {
-		t.Errorf("Plugin directory %s was not created", got.PluginDir)
+	if _, err := os.Stat(got.PluginDirs[0]); os.IsNotExist(err) {
+		t.Errorf("Plugin directory %s was not created", got.PluginDirs[0])
 	}
 }
 
@@ -137,6 +144,20 @@ manager-port: "8080"
 reconnect-delay: 15
 plugin-dir: "/tmp/node-plugins"
 plugin-server-port: "8081"
+plugin-server-tls:
+  enabled: true
+plugin-sync-interval: 300
+plugin-config:
+  - plugin: aws-collector
+    env:
+      - AWS_PROFILE=prod
+      - AWS_CONFIG_FILE=/etc/jackadi/aws.conf
+    workdir: /var/lib/aws-collector
+    run-as: collector
+plugins:
+  allowed:
+    - aws-collector
+    - git
 custom-resolvers:
   - "8.8.8.8"
   - "1.1.1.1"
@@ -145,6 +166,14 @@ mtls:
   key: "/path/to/node.key"
   cert: "/path/to/node.cert"
   manager-ca-cert: "/path/to/manager-ca.cert"
+keepalive:
+  time: 120
+  timeout: 60
+  permit-without-stream: false
+compression: true
+log:
+  level: debug
+  format: json
 `
 
 	configFile := createTestConfigFile(t, "node-full", content)
@@ -160,8 +189,19 @@ mtls:
 		ManagerAddress:     "192.168.1.1",
 		ManagerPort:        "8080",
 		ReconnectDelay:     15,
-		PluginDir:          "/tmp/node-plugins",
+		PluginDirs:         PathList{"/tmp/node-plugins"},
 		PluginServerPort:   "8081",
+		PluginServerTLS:    PluginServerTLSConfig{Enabled: true, ManagerCA: "/path/to/manager-ca.cert"}, // the mTLS CA by default
+		PluginSyncInterval: 300,
+		PluginConfig: []PluginConfig{
+			{
+				Plugin:  "aws-collector",
+				Env:     []string{"AWS_PROFILE=prod", "AWS_CONFIG_FILE=/etc/jackadi/aws.conf"},
+				Workdir: "/var/lib/aws-collector",
+				RunAs:   "collector",
+			},
+		},
+		Plugins:            NodePluginsConfig{Allowed: []string{"aws-collector", "git"}},
 		CustomResolvers:    []string{"8.8.8.8", "1.1.1.1"},
 		MaxConcurrentTasks: DefaultMaxConcurrentTasks,
 		MaxWaitingRequests: DefaultMaxWaitingRequests,
@@ -171,6 +211,9 @@ mtls:
 			Cert:      "/path/to/node.cert",
 			ManagerCA: "/path/to/manager-ca.cert",
 		},
+		Keepalive:   KeepaliveConfig{Time: 120, Timeout: 60},
+		Compression: true,
+		Log:         LogConfig{Level: "debug", Format: LogFormatJSON},
 	}
 
 	if diff := cmp.Diff(got, expected); diff != "" {
@@ -191,15 +234,27 @@ func TestLoadManagerConfig_Default(t *testing.T) {
 		ConfigDir:        DefaultConfigDir,
 		ListenAddress:    DefaultManagerAddress,
 		ListenPort:       DefaultManagerPort,
-		PluginDir:        DefaultPluginDir,
+		PluginDirs:       PathList{DefaultPluginDir},
+		CreatePluginDir:  true,
 		PluginServerPort: DefaultPluginServerPort,
 		AutoAcceptNode:   false,
+		AutoAccept:       AutoAcceptConfig{Nodes: []string{}},
+		MaxInflight:      DefaultMaxInflightRequests,
+		Node: ManagerNodeConfig{
+			ActiveThreshold:   int(NodeActiveThreshold.Seconds()),
+			EventDebounce:     int(NodeEventDebounce.Seconds()),
+			HeartbeatInterval: int(HeartbeatInterval.Seconds()),
+			HeartbeatTimeout:  int(HeartbeatTimeout.Seconds()),
+			ResponseGrace:     int(NodeResponseGrace.Seconds()),
+			AcceptanceWait:    int(NodeAcceptanceWait.Seconds()),
+		},
 		MTLS: ManagerMTLSConfig{
 			Enabled: true,
 			Key:     "",
 			Cert:    "",
 			NodeCA:  "",
 		},
+		Keepalive: ManagerKeepaliveConfig{Time: 5, Timeout: 1, MinTime: 5, PermitWithoutStream: true},
 		API: APIConfig{
 			Enabled: true,
 			Address: DefaultAPIAddress,
@@ -210,6 +265,18 @@ func TestLoadManagerConfig_Default(t *testing.T) {
 				Key:     "",
 			},
 		},
+		CLI: CLIConfig{
+			SocketMode: DefaultCLISocketMode,
+			Remote:     CLIRemoteConfig{Address: DefaultManagerAddress, Port: DefaultCLIRemotePort},
+		},
+		Upstream: UpstreamConfig{
+			Port:           DefaultManagerPort,
+			ReconnectDelay: int(DefaultReconnectDelay.Seconds()),
+			MaxRuns:        DefaultSyndicMaxRuns,
+			MTLS:           MTLSConfig{Enabled: true},
+			Keepalive:      KeepaliveConfig{Time: 10, Timeout: 30, PermitWithoutStream: true},
+		},
+		Log: LogConfig{Level: DefaultLogLevel, Format: LogFormatText},
 	}
 
 	if diff := cmp.Diff(got, expected); diff != "" {
@@ -224,13 +291,39 @@ config-dir: "/etc/full-config"
 address: "0.0.0.0"
 port: "9090"
 plugin-dir: "/opt/full-plugins"
+create-plugin-dir: false
 plugin-server-port: "9091"
+plugin-server-tls:
+  enabled: true
 auto-accept-node: true
+auto-accept:
+  nodes: ["web-*"]
+  ca-cert: "/path/to/web-ca.cert"
+max-inflight-requests: 50
+node:
+  active-threshold: 300
+  event-debounce: 120
+  heartbeat-interval: 60
+  heartbeat-timeout: 15
+  response-grace: 30
+  acceptance-wait: 600
+  limits:
+    - nodes: ["edge-*", "pi-*"]
+      max-concurrent-tasks: 1
+      max-waiting-requests: 5
 mtls:
   enabled: true
+  require: true
+  match-node-id: true
   key: "/path/to/manager.key"
   cert: "/path/to/manager.cert"
   node-ca-cert: "/path/to/node-ca.cert"
+keepalive:
+  time: 60
+  timeout: 20
+  min-time: 30
+compression: true
+degraded-storage: true
 api:
   enabled: true
   address: "127.0.0.1"
@@ -239,6 +332,41 @@ api:
     enabled: true
     cert: "/path/to/api.cert"
     key: "/path/to/api.key"
+cli:
+  socket-mode: "0770"
+  socket-group: "jackadi"
+  remote:
+    enabled: true
+    address: "0.0.0.0"
+    port: "40090"
+    cert: "/path/to/cli.cert"
+    key: "/path/to/cli.key"
+    client-ca-cert: "/path/to/cli-ca.cert"
+  reflection: true
+notifications:
+  webhooks:
+    - url: "https://chat.example.com/hooks/jackadi"
+      on-failure-only: true
+      plugins: ["cmd", "pkg*"]
+      timeout: 10
+      node-events: true
+upstream:
+  enabled: true
+  address: "upper.example.com"
+  port: "9092"
+  reconnect-delay: 5
+  max-concurrent-runs: 20
+  mtls:
+    enabled: true
+    key: "/path/to/syndic.key"
+    cert: "/path/to/syndic.cert"
+    manager-ca-cert: "/path/to/upper-ca.cert"
+  keepalive:
+    time: 300
+    timeout: 120
+log:
+  level: warn
+  format: text
 `
 
 	configFile := createTestManagerConfigFile(t, content)
@@ -254,15 +382,31 @@ api:
 		ConfigDir:        "/etc/full-config",
 		ListenAddress:    "0.0.0.0",
 		ListenPort:       "9090",
-		PluginDir:        "/opt/full-plugins",
+		PluginDirs:       PathList{"/opt/full-plugins"},
 		PluginServerPort: "9091",
-		AutoAcceptNode:   true,
-		MTLS: ManagerMTLSConfig{
+		PluginServerTLS: ManagerPluginServerTLSConfig{
 			Enabled: true,
+			Cert:    "/path/to/manager.cert", // the mTLS certificate by default
 			Key:     "/path/to/manager.key",
-			Cert:    "/path/to/manager.cert",
-			NodeCA:  "/path/to/node-ca.cert",
 		},
+		AutoAcceptNode: true,
+		AutoAccept:     AutoAcceptConfig{Nodes: []string{"web-*"}, CACert: "/path/to/web-ca.cert"},
+		MaxInflight:    50,
+		Node: ManagerNodeConfig{
+			ActiveThreshold: 300, EventDebounce: 120, HeartbeatInterval: 60, HeartbeatTimeout: 15, ResponseGrace: 30, AcceptanceWait: 600,
+			Limits: []NodeLimitsConfig{{Nodes: []string{"edge-*", "pi-*"}, MaxConcurrentTasks: 1, MaxWaitingRequests: 5}},
+		},
+		MTLS: ManagerMTLSConfig{
+			Enabled:     true,
+			Require:     true,
+			MatchNodeID: true,
+			Key:         "/path/to/manager.key",
+			Cert:        "/path/to/manager.cert",
+			NodeCA:      "/path/to/node-ca.cert",
+		},
+		Keepalive:       ManagerKeepaliveConfig{Time: 60, Timeout: 20, MinTime: 30, PermitWithoutStream: true},
+		Compression:     true,
+		DegradedStorage: true,
 		API: APIConfig{
 			Enabled: true,
 			Address: "127.0.0.1",
@@ -273,6 +417,46 @@ api:
 				Key:     "/path/to/api.key",
 			},
 		},
+		CLI: CLIConfig{
+			SocketMode:  "0770",
+			SocketGroup: "jackadi",
+			Remote: CLIRemoteConfig{
+				Enabled:  true,
+				Address:  "0.0.0.0",
+				Port:     "40090",
+				Cert:     "/path/to/cli.cert",
+				Key:      "/path/to/cli.key",
+				ClientCA: "/path/to/cli-ca.cert",
+			},
+			Reflection: true,
+		},
+		Notifications: NotificationsConfig{
+			Webhooks: []WebhookConfig{
+				{
+					URL:           "https://chat.example.com/hooks/jackadi",
+					OnFailureOnly: true,
+					Plugins:       []string{"cmd", "pkg*"},
+					Timeout:       10,
+					NodeEvents:    true,
+				},
+			},
+		},
+		Upstream: UpstreamConfig{
+			Enabled:        true,
+			ID:             "full-manager", // the manager ID by default
+			Address:        "upper.example.com",
+			Port:           "9092",
+			ReconnectDelay: 5,
+			MaxRuns:        20,
+			MTLS: MTLSConfig{
+				Enabled:   true,
+				Key:       "/path/to/syndic.key",
+				Cert:      "/path/to/syndic.cert",
+				ManagerCA: "/path/to/upper-ca.cert",
+			},
+			Keepalive: KeepaliveConfig{Time: 300, Timeout: 120, PermitWithoutStream: true},
+		},
+		Log: LogConfig{Level: "warn", Format: LogFormatText},
 	}
 
 	if diff := cmp.Diff(got, expected); diff != "" {
@@ -280,14 +464,313 @@ api:
 	}
 }
 
+func TestLoadNodeConfig_PluginDirs(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "local")
+	vendor := filepath.Join(t.TempDir(), "vendor")
+
+	setupNodeTest(t, map[string]string{
+		"plugin-dir": local + ":" + vendor,
+	}, nil)
+
+	got, err := LoadNodeConfig("")
+	if err != nil {
+		t.Fatalf("LoadNodeConfig() error = %v", err)
+	}
+
+	if diff := cmp.Diff(got.PluginDirs, PathList{local, vendor}); diff != "" {
+		t.Errorf("PluginDirs mismatch:\n%s", diff)
+	}
+
+	if _, err := os.Stat(local); os.IsNotExist(err) {
+		t.Errorf("Plugin directory %s was not created", local)
+	}
+	if _, err := os.Stat(vendor); !os.IsNotExist(err) {
+		t.Errorf("Plugin directory %s should only be created if it is the first one", vendor)
+	}
+}
+
+func TestLoadManagerConfig_PluginDirs(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+		want    PathList
+	}{
+		{
+			name:    "yaml list",
+			content: "plugin-dir: [\"/opt/local\", \"/opt/vendor\"]\n",
+			want:    PathList{"/opt/local", "/opt/vendor"},
+		},
+		{
+			name:    "colon-separated",
+			content: "plugin-dir: \"/opt/local:/opt/vendor\"\n",
+			want:    PathList{"/opt/local", "/opt/vendor"},
+		},
+		{
+			name:    "environment",
+			content: "manager-id: \"env\"\n",
+			env:     map[string]string{"JACKADI_MANAGER_PLUGIN_DIR": "/opt/env:/opt/vendor"},
+			want:    PathList{"/opt/env", "/opt/vendor"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := createTestManagerConfigFile(t, tt.content)
+			setupManagerTest(t, nil, tt.env)
+
+			got, err := LoadManagerConfig(configFile)
+			if err != nil {
+				t.Fatalf("LoadManagerConfig() error = %v", err)
+			}
+
+			if diff := cmp.Diff(got.PluginDirs, tt.want); diff != "" {
+				t.Errorf("PluginDirs mismatch:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestManagerConfig_InitPluginDirs(t *testing.T) {
+	existing := t.TempDir()
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("missing directory created", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "plugins")
+		cfg := ManagerConfig{PluginDirs: PathList{existing, missing}, CreatePluginDir: true}
+		created, err := cfg.InitPluginDirs()
+		if err != nil {
+			t.Fatalf("InitPluginDirs() error = %v", err)
+		}
+		if diff := cmp.Diff(created, []string{missing}); diff != "" {
+			t.Errorf("created directories mismatch:\n%s", diff)
+		}
+		if info, err := os.Stat(missing); err != nil || !info.IsDir() {
+			t.Errorf("plugin directory %s was not created", missing)
+		}
+	})
+
+	t.Run("missing directory refused", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "plugins")
+		cfg := ManagerConfig{PluginDirs: PathList{existing, missing}, CreatePluginDir: false}
+		if _, err := cfg.InitPluginDirs(); err == nil || !strings.Contains(err.Error(), missing) {
+			t.Errorf("expected an error naming the missing directory, got %v", err)
+		}
+		if _, err := os.Stat(missing); !os.IsNotExist(err) {
+			t.Errorf("plugin directory %s should not be created", missing)
+		}
+	})
+
+	t.Run("not a directory", func(t *testing.T) {
+		cfg := ManagerConfig{PluginDirs: PathList{file}, CreatePluginDir: true}
+		if _, err := cfg.InitPluginDirs(); err == nil {
+			t.Error("expected an error for a plugin directory which is a file")
+		}
+	})
+
+	t.Run("absolute paths", func(t *testing.T) {
+		t.Chdir(existing)
+		cfg := ManagerConfig{PluginDirs: PathList{"plugins"}, CreatePluginDir: true}
+		if _, err := cfg.InitPluginDirs(); err != nil {
+			t.Fatalf("InitPluginDirs() error = %v", err)
+		}
+		if diff := cmp.Diff(cfg.PluginDirs, PathList{filepath.Join(existing, "plugins")}); diff != "" {
+			t.Errorf("PluginDirs mismatch:\n%s", diff)
+		}
+	})
+}
+
+func TestLoadManagerConfig_MTLSRequiredButDisabled(t *testing.T) {
+	configFile := createTestManagerConfigFile(t, "mtls:\n  enabled: false\n  require: true\n")
+	setupManagerTest(t, nil, nil)
+
+	if _, err := LoadManagerConfig(configFile); err == nil {
+		t.Error("expected an error when mTLS is required but disabled")
+	}
+}
+
+func TestLoadManagerConfig_MatchNodeID(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    bool
+	}{
+		"default":               {content: "mtls:\n  enabled: true\n", want: false},
+		"enabled":               {content: "mtls:\n  enabled: true\n  match-node-id: true\n", want: true},
+		"explicit without mTLS": {content: "mtls:\n  enabled: false\n  match-node-id: true\n", want: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			configFile := createTestManagerConfigFile(t, tt.content)
+			setupManagerTest(t, nil, nil)
+
+			got, err := LoadManagerConfig(configFile)
+			if err != nil {
+				t.Fatalf("LoadManagerConfig() error = %v", err)
+			}
+			if got.MTLS.MatchNodeID != tt.want {
+				t.Errorf("expected match-node-id %v, got %v", tt.want, got.MTLS.MatchNodeID)
+			}
+		})
+	}
+}
+
+func TestLoadManagerConfig_InvalidUpstream(t *testing.T) {
+	tests := map[string]string{
+		"no node ID":     "upstream:\n  enabled: true\n  address: upper\n",
+		"invalid ID":     "upstream:\n  enabled: true\n  id: eu/manager\n  address: upper\n",
+		"no address":     "manager-id: eu-manager\nupstream:\n  enabled: true\n",
+		"no runs":        "manager-id: eu-manager\nupstream:\n  enabled: true\n  address: upper\n  max-concurrent-runs: 0\n",
+		"SPIFFE no mTLS": "manager-id: eu-manager\nupstream:\n  enabled: true\n  address: upper\n  mtls:\n    enabled: false\n    spiffe:\n      enabled: true\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			configFile := createTestManagerConfigFile(t, content)
+			setupManagerTest(t, nil, nil)
+
+			if _, err := LoadManagerConfig(configFile); err == nil {
+				t.Error("expected an error for an invalid upstream connection")
+			}
+		})
+	}
+}
+
+func TestLoadManagerConfig_PluginServerTLSWithoutCertificate(t *testing.T) {
+	configFile := createTestManagerConfigFile(t, "plugin-server-tls:\n  enabled: true\n")
+	setupManagerTest(t, nil, nil)
+
+	if _, err := LoadManagerConfig(configFile); err == nil {
+		t.Error("expected an error when the plugin server TLS is enabled without certificate")
+	}
+}
+
+func TestLoadManagerConfig_InvalidNode(t *testing.T) {
+	tests := map[string]string{
+		"negative interval": "node:\n  heartbeat-interval: -1\n",
+		"no timeout":        "node:\n  heartbeat-timeout: 0\n",
+		"timeout too long":  "node:\n  heartbeat-interval: 10\n  heartbeat-timeout: 20\n",
+		"negative grace":    "node:\n  response-grace: -1\n",
+		"negative wait":     "node:\n  acceptance-wait: -1\n",
+		"limits no node":    "node:\n  limits:\n    - max-concurrent-tasks: 1\n",
+		"limits bad glob":   "node:\n  limits:\n    - nodes: [\"edge-[\"]\n      max-concurrent-tasks: 1\n",
+		"negative limit":    "node:\n  limits:\n    - nodes: [\"edge-*\"]\n      max-waiting-requests: -1\n",
+		"drift bad glob":    "node:\n  specs-drift-ignore: [\"memory[\"]\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			configFile := createTestManagerConfigFile(t, content)
+			setupManagerTest(t, nil, nil)
+
+			if _, err := LoadManagerConfig(configFile); err == nil {
+				t.Error("expected an error for invalid node settings")
+			}
+		})
+	}
+
+	// the timeout is ignored without heartbeats
+	configFile := createTestManagerConfigFile(t, "node:\n  heartbeat-interval: 0\n  heartbeat-timeout: 0\n")
+	setupManagerTest(t, nil, nil)
+	if _, err := LoadManagerConfig(configFile); err != nil {
+		t.Errorf("disabled heartbeats refused: %v", err)
+	}
+
+	// a node waits until it is accepted
+	configFile = createTestManagerConfigFile(t, "node:\n  acceptance-wait: 0\n")
+	setupManagerTest(t, nil, nil)
+	if cfg, err := LoadManagerConfig(configFile); err != nil || cfg.Node.AcceptanceWait != 0 {
+		t.Errorf("unlimited acceptance wait refused: %v", err)
+	}
+}
+
+func TestLoadManagerConfig_InvalidAutoAccept(t *testing.T) {
+	tests := map[string]string{
+		"bad glob":       "auto-accept:\n  nodes: [\"web-[\"]\n",
+		"CA without TLS": "mtls:\n  enabled: false\nauto-accept:\n  ca-cert: /path/to/ca.cert\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			configFile := createTestManagerConfigFile(t, content)
+			setupManagerTest(t, nil, nil)
+
+			if _, err := LoadManagerConfig(configFile); err == nil {
+				t.Error("expected an error for invalid auto-accept settings")
+			}
+		})
+	}
+}
+
+func TestLoadManagerConfig_WebhookFormat(t *testing.T) {
+	tests := map[string]bool{
+		"":                   true,
+		WebhookFormatEvent:   true,
+		WebhookFormatSummary: true,
+		"slack":              false,
+	}
+	for format, valid := range tests {
+		t.Run(format, func(t *testing.T) {
+			content := fmt.Sprintf("notifications:\n  webhooks:\n    - url: http://hooks.example.com\n      format: %q\n", format)
+			configFile := createTestManagerConfigFile(t, content)
+			setupManagerTest(t, nil, nil)
+
+			_, err := LoadManagerConfig(configFile)
+			if valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !valid && err == nil {
+				t.Error("expected an error for an invalid webhook format")
+			}
+		})
+	}
+}
+
+func TestCLIConfigSocketFileMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    fs.FileMode
+		wantErr bool
+	}{
+		{mode: "0700", want: 0o700},
+		{mode: "770", want: 0o770},
+		{mode: "0750", want: 0o750},
+		{mode: "0777", wantErr: true},
+		{mode: "0704", wantErr: true},
+		{mode: "01700", wantErr: true},
+		{mode: "0800", wantErr: true},
+		{mode: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			got, err := CLIConfig{SocketMode: tt.mode}.SocketFileMode()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SocketFileMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SocketFileMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadManagerConfig_CLIRemoteWithoutCertificates(t *testing.T) {
+	configFile := createTestManagerConfigFile(t, "cli:\n  remote:\n    enabled: true\n    cert: /path/to/cert\n")
+	setupManagerTest(t, nil, nil)
+
+	if _, err := LoadManagerConfig(configFile); err == nil {
+		t.Error("expected an error when the remote CLI access is enabled without key and client CA")
+	}
+}
+
 func TestSetupNodeFlags(t *testing.T) {
 	pflag.CommandLine = pflag.NewFlagSet(getProgramName(), pflag.ExitOnError)
 	SetupNodeFlags()
 
 	expectedFlags := []string{
 		"id", "manager-address", "manager-port", "reconnect-delay",
-		"plugin-dir", "plugin-server-port", "custom-resolvers",
+		"plugin-dir", "plugin-server-port", "plugin-server-tls.enabled", "plugin-server-tls.manager-ca-cert", "plugin-sync-interval", "plugins.allowed", "custom-resolvers",
 		"mtls.enabled", "mtls.key", "mtls.cert", "mtls.manager-ca-cert",
+		"keepalive.time", "keepalive.timeout", "keepalive.permit-without-stream", "compression",
 		"config",
 	}
 
@@ -303,10 +786,14 @@ func TestSetupManagerFlags(t *testing.T) {
 	SetupManagerFlags()
 
 	expectedFlags := []string{
-		"id", "config-dir", "address", "port", "plugin-dir", "plugin-server-port",
-		"auto-accept-node", "mtls.enabled", "mtls.key", "mtls.cert",
+		"id", "config-dir", "address", "port", "plugin-dir", "create-plugin-dir", "plugin-server-port",
+		"plugin-server-tls.enabled", "plugin-server-tls.cert", "plugin-server-tls.key",
+		"auto-accept-node", "auto-accept.nodes", "auto-accept.ca-cert", "max-inflight-requests", "node.active-threshold", "node.event-debounce",
+		"node.heartbeat-interval", "node.heartbeat-timeout", "node.response-grace", "node.acceptance-wait", "mtls.enabled", "mtls.key", "mtls.cert",
 		"mtls.node-ca-cert", "api.enabled", "api.address", "api.port",
-		"api.tls.enabled", "api.tls.cert", "api.tls.key", "config",
+		"api.tls.enabled", "api.tls.cert", "api.tls.key", "upstream.enabled", "upstream.id", "upstream.address",
+		"upstream.port", "keepalive.time", "keepalive.timeout", "keepalive.min-time", "keepalive.permit-without-stream",
+		"compression", "degraded-storage", "config",
 	}
 
 	for _, flagName := range expectedFlags {
@@ -6,8 +6,14 @@ const (
 	// Grammar.
 	PluginSeparator   = "."
 	ListSeparator     = ","
-	SpecManagerPrefix = "specs" // Prefix used for specs-related tasks.
+	FieldSeparator    = ":"      // Separates the matched field and the pattern of a glob or regex target (e.g. specs:os.family:debian*).
+	SpecManagerPrefix = "specs"  // Prefix used for specs-related tasks.
+	SpecErrorKey      = "_error" // Key of the marker replacing the specs of a failed collector (e.g. {"_error": "timeout"}).
 	InstantPingName   = "instant-ping"
+	SyndicSeparator   = "/"                 // Separates the syndic and its node in the results of a run (e.g. eu-manager/web-1).
+	UpstreamGroupKey  = "upstream-group-id" // Metadata set by a syndic to the runs of its upstream manager, with their group ID.
+	TargetsTrailerKey = "targets-bin"       // gRPC trailer of a streamed run, with the dispatch status of the targeted nodes.
+	GraceHeaderKey    = "response-grace"    // gRPC header of a streamed run, with the response grace of the manager (e.g. 10s).
 
 	// Network.
 	DefaultManagerAddress   = "127.0.0.1"
@@ -15,20 +21,46 @@ const (
 	DefaultPluginServerPort = "40081"     // Default port for serving plugins.
 	DefaultAPIAddress       = "127.0.0.1" // Default HTTP API address.
 	DefaultAPIPort          = "8081"      // Default HTTP API port.
+	DefaultCLIRemotePort    = "40082"     // Default port of the remote CLI access.
 	HTTPReadHeaderTimeout   = 10 * time.Second
+	ProtocolVersion         = 1 // Version of the manager/node protocol, increased on breaking changes.
 
-	PluginServerPath = "/plugin/"                  // Path prefix for plugin server endpoints.
-	CLISocket        = "/run/jackadi/manager.sock" // Unix socket path for CLI communication.
-	HTPasswordFile   = ".htpasswd"
+	PluginServerPath     = "/plugin/"                  // Path prefix for plugin server endpoints.
+	PluginByHashPath     = "by-hash/"                  // Path prefix of the plugins served by checksum, relative to PluginServerPath.
+	CLISocket            = "/run/jackadi/manager.sock" // Unix socket path for CLI communication.
+	DefaultCLISocketMode = "0700"                      // Default permissions of the CLI socket, only its owner can use jack.
+	HTPasswordFile       = ".htpasswd"
+	CLIConfigFile        = "jackadi/jack.yaml"     // Path of the jack configuration, relative to the user configuration directory.
+	CLIHistoryFile       = "jackadi/history.jsonl" // Path of the jack history, relative to the user configuration directory.
+	CLIHistorySize       = 100                     // Default number of runs kept in the jack history.
+	CLIConfirmThreshold  = 20                      // Default number of targeted nodes above which jack run asks for confirmation.
+	CLIConfirmSample     = 5                       // Targeted nodes listed by the confirmation of jack run.
+	CLIWatchInterval     = 5 * time.Second         // Refresh interval of jack nodes list --watch if the manager does not stream the node changes.
+	CLILogsInterval      = time.Second             // Interval between the fetches of the new log lines by jack nodes logs --follow.
+	CLIConnectTimeout    = 5 * time.Second         // Default timeout of jack to connect the manager.
+	CLIRequestTimeout    = time.Minute             // Default timeout of the requests of jack, the runs are bounded by the task timeout instead.
+
+	PluginManifestSuffix = ".manifest.yaml" // Suffix of the optional manifest file next to a plugin file (e.g. cmd.manifest.yaml).
 
 	// Timing and duration config.
 	TaskTimeout             = 30 * time.Second
 	DefaultReconnectDelay   = 10 * time.Second // The default delay between reconnection to the manager attempts.
-	GracefulShutdownTimeout = 30 * time.Second
+	GracefulShutdownTimeout = 30 * time.Second // Time given to the running tasks to finish at the node shutdown, before being killed.
+	KilledTasksGrace        = 5 * time.Second  // Wait for the responses of the tasks killed at the node shutdown.
 	SpecCollectionInterval  = 1 * time.Minute
-	DatabaseGCInterval      = 5 * time.Minute
+	SpecCollectorTimeout    = 10 * time.Second // Maximum duration of a spec collector of a plugin.
+	SpecCollectionTimeout   = 30 * time.Second // Maximum duration of the spec collection of a plugin, all its collectors.
+	DatabaseGCInterval      = 5 * time.Minute  // Initial delay between database GC runs, adapted to the activity.
+	DatabaseGCMinInterval   = 1 * time.Minute
+	DatabaseGCMaxInterval   = 1 * time.Hour
 	NodeRetryDelay          = 10 * time.Second // The delay before retrying node registration.
 	PluginUpdateTimeout     = 30 * time.Second
+	PluginIndexCooldown     = 5 * time.Second  // Minimum delay between two scans of the plugin files for an unknown checksum.
+	InsecureWarningInterval = 5 * time.Minute  // Delay between the warnings logged while running without mTLS.
+	DegradedWarningInterval = 5 * time.Minute  // Delay between the warnings logged while the task results are not stored.
+	SPIFFEFetchTimeout      = 30 * time.Second // Maximum wait for the first SVID from the Workload API.
+	LockWaitThreshold       = time.Second      // A task waiting longer for its lock is logged, and counted as contended.
+	PendingCheckInterval    = time.Second      // Delay between two checks of the scheduled requests due.
 
 	// gRPC keepalive settings.
 	KeepaliveTime          = 5 * time.Second
@@ -38,8 +70,15 @@ const (
 	ClientKeepaliveTimeout = 30 * time.Second
 
 	// Task limits (can be overridden via node configuration).
-	DefaultMaxConcurrentTasks = 2   // Default maximum number of tasks that can run concurrently.
-	DefaultMaxWaitingRequests = 100 // Default maximum number of requests that can wait in queue.
+	DefaultMaxConcurrentTasks = 2                // Default maximum number of tasks that can run concurrently.
+	DefaultMaxWaitingRequests = 100              // Default maximum number of requests that can wait in queue.
+	PriorityAging             = 30 * time.Second // A request waiting for a slot is not overtaken by the requests received this long after it, per priority level of difference.
+
+	// Manager limits.
+	DefaultMaxInflightRequests = 1000            // Default maximum number of requests awaiting a response, per node.
+	DefaultSyndicMaxRuns       = 100             // Default maximum number of runs of the upstream manager a syndic dispatches concurrently.
+	SyndicTimeoutMargin        = 2 * time.Second // Kept by a syndic from the timeout of a run, to send its response upstream in time.
+	TargetCacheSize            = 256             // Number of compiled regexes, and of parsed queries, kept by the dispatcher.
 
 	// `jack results list` limits.
 	ResultsPageLimit = 100 // Maximum number of results per page for pagination.
@@ -54,11 +93,45 @@ const (
 	RegistryFileName     = "registry.json"             // Name of the node registry file.
 
 	// Database and storage settings.
-	DBTaskRequestTTL = 24 * time.Hour // TTL of task requests in the database.
-	DBTaskResultTTL  = 24 * time.Hour // TTL of task results in the database.
-	DBGCThreshold    = 0.7            // Threshold for database garbage collection.
+	DBTaskRequestTTL  = 24 * time.Hour // TTL of task requests in the database.
+	DBTaskResultTTL   = 24 * time.Hour // TTL of task results in the database.
+	DBGCThreshold     = 0.7            // Threshold for database garbage collection.
+	DBGCWriteVolume   = 10000          // Number of writes since the last GC run above which GC is run more often.
+	DBDeleteBatchSize = 500            // Maximum number of results deleted in a single transaction.
+
+	// Database backup.
+	BackupChunkSize         = 64 * 1024 // Size of the chunks of a streamed backup, in bytes.
+	RestoreMaxPendingWrites = 256       // Maximum number of pending writes while loading a backup.
 
 	// Node activity and health check settings.
-	NodeActiveThreshold    = 60 * time.Second // Time threshold to consider a node active (more than this value means 'inactive').
+	NodeActiveThreshold    = 60 * time.Second // Default time threshold to consider a node active (more than this value means 'inactive').
+	NodeEventDebounce      = 30 * time.Second // Default delay during which a node activity change must last before being notified.
+	NodeActivityCheckDelay = 10 * time.Second // Delay between two checks of the nodes activity.
 	ResponseChannelTimeout = 30 * time.Second // Timeout for sending back responses to requester.
+	HeartbeatInterval      = 30 * time.Second // Default delay between two health:instant-ping sent by the manager on a task stream.
+	HeartbeatTimeout       = 10 * time.Second // Default wait for the answer to a heartbeat before closing the task stream.
+	NodeResponseGrace      = 10 * time.Second // Default wait after the timeout of a task for the response of the node, before considering it unresponsive.
+	NodeAcceptanceWait     = 5 * time.Minute  // Default wait for a node to be accepted before closing its task stream.
+	HeartbeatTolerance     = 3                // Heartbeat intervals without request after which a node re-establishes its task stream.
+	LongRunningTask        = time.Minute      // The tasks running longer are reported by the nodes in the heartbeat responses.
+	SpecsDriftHistory      = 20               // Spec changes kept by node, the oldest are discarded.
+	PluginSyncHistory      = 20               // Plugin syncs with changes or errors kept by node, the oldest are discarded.
+
+	// Logging.
+	DefaultLogLevel = "info"
+	LogFormatText   = "text" // Human-readable logs.
+	LogFormatJSON   = "json" // One JSON object per line.
+	LogRingSize     = 1000   // Last log lines kept in memory, fetched by jack nodes logs.
+	LogTailLines    = 100    // Default number of log lines fetched by jack nodes logs.
+
+	// Notifications.
+	DefaultWebhookTimeout = 5 * time.Second // Timeout of a single webhook delivery attempt.
+	WebhookRetries        = 3               // Number of delivery attempts before dropping a notification.
+	WebhookRetryDelay     = 2 * time.Second // Delay between two delivery attempts.
+	NotificationQueueSize = 1000            // Maximum number of pending notifications, new ones are dropped when full.
+	SummaryMaxFailures    = 10              // Maximum number of failed nodes detailed in a run summary.
+	SummaryMaxErrorLength = 120             // Maximum length of the error snippet of a failed node in a run summary.
+	SummaryMaxChanges     = 10              // Maximum number of spec changes detailed in a specs drift summary.
+	WebhookFormatEvent    = "event"         // Webhook payload: one JSON event per task.
+	WebhookFormatSummary  = "summary"       // Webhook payload: one chat message (`{"text": ...}`) per run.
 )
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc/keepalive"
+)
+
+// KeepaliveConfig configures the gRPC keepalive pings of a node to its manager, e.g. to tolerate the latency of
+// satellite links.
+//
+// The time must not be lower than the minimum time of the manager (keepalive.min-time), which closes the
+// connections of the nodes pinging too often. gRPC raises a time lower than 10s to 10s.
+type KeepaliveConfig struct {
+	Time                int  `mapstructure:"time" yaml:"time"`                                   // Idle delay before pinging the manager, in seconds.
+	Timeout             int  `mapstructure:"timeout" yaml:"timeout"`                             // Wait for the ping ack before closing the connection, in seconds.
+	PermitWithoutStream bool `mapstructure:"permit-without-stream" yaml:"permit-without-stream"` // Ping even without task stream.
+}
+
+// ManagerKeepaliveConfig configures the gRPC keepalive pings of a manager to its nodes, and the pings it
+// accepts from them.
+type ManagerKeepaliveConfig struct {
+	Time                int  `mapstructure:"time" yaml:"time"`                                   // Idle delay before pinging a node, in seconds.
+	Timeout             int  `mapstructure:"timeout" yaml:"timeout"`                             // Wait for the ping ack before closing the connection, in seconds.
+	MinTime             int  `mapstructure:"min-time" yaml:"min-time"`                           // The connections of the nodes pinging more often are closed, in seconds.
+	PermitWithoutStream bool `mapstructure:"permit-without-stream" yaml:"permit-without-stream"` // Accept the pings of the nodes without task stream.
+}
+
+// ClientParameters returns the keepalive parameters of the connection to the manager.
+func (k KeepaliveConfig) ClientParameters() keepalive.ClientParameters {
+	return keepalive.ClientParameters{
+		Time:                time.Duration(k.Time) * time.Second,
+		Timeout:             time.Duration(k.Timeout) * time.Second,
+		PermitWithoutStream: k.PermitWithoutStream,
+	}
+}
+
+// ServerParameters returns the keepalive parameters of the connections of the nodes.
+func (k ManagerKeepaliveConfig) ServerParameters() keepalive.ServerParameters {
+	return keepalive.ServerParameters{
+		Time:    time.Duration(k.Time) * time.Second,
+		Timeout: time.Duration(k.Timeout) * time.Second,
+	}
+}
+
+// EnforcementPolicy returns the policy applied to the keepalive pings of the nodes.
+func (k ManagerKeepaliveConfig) EnforcementPolicy() keepalive.EnforcementPolicy {
+	return keepalive.EnforcementPolicy{
+		MinTime:             time.Duration(k.MinTime) * time.Second,
+		PermitWithoutStream: k.PermitWithoutStream,
+	}
+}
+
+func (k KeepaliveConfig) validate(prefix string) error {
+	if k.Time <= 0 || k.Timeout <= 0 {
+		return fmt.Errorf("invalid keepalive (%s.time, %s.timeout): positive delays expected, got %d and %d", prefix, prefix, k.Time, k.Timeout)
+	}
+	return nil
+}
+
+func (k ManagerKeepaliveConfig) validate() error {
+	if k.Time <= 0 || k.Timeout <= 0 {
+		return fmt.Errorf("invalid keepalive (keepalive.time, keepalive.timeout): positive delays expected, got %d and %d", k.Time, k.Timeout)
+	}
+	// without minimum, gRPC would fall back to its default of 5 minutes and close the connections of most nodes
+	if k.MinTime <= 0 {
+		return fmt.Errorf("invalid keepalive minimum time (keepalive.min-time): positive delay expected, got %d", k.MinTime)
+	}
+	return nil
+}
+
+func setKeepaliveDefaults(v *viper.Viper, prefix string) {
+	v.SetDefault(prefix+".time", int(ClientKeepaliveTime.Seconds()))
+	v.SetDefault(prefix+".timeout", int(ClientKeepaliveTimeout.Seconds()))
+	v.SetDefault(prefix+".permit-without-stream", true)
+}
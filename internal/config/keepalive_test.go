@@ -0,0 +1,67 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/keepalive"
+)
+
+func TestKeepaliveConfigClientParameters(t *testing.T) {
+	got := KeepaliveConfig{Time: 120, Timeout: 60, PermitWithoutStream: true}.ClientParameters()
+	expected := keepalive.ClientParameters{Time: 2 * time.Minute, Timeout: time.Minute, PermitWithoutStream: true}
+
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("ClientParameters() mismatch:\n%s", diff)
+	}
+}
+
+func TestManagerKeepaliveConfigServerOptions(t *testing.T) {
+	cfg := ManagerKeepaliveConfig{Time: 60, Timeout: 20, MinTime: 30, PermitWithoutStream: true}
+
+	params := keepalive.ServerParameters{Time: time.Minute, Timeout: 20 * time.Second}
+	if diff := cmp.Diff(cfg.ServerParameters(), params); diff != "" {
+		t.Errorf("ServerParameters() mismatch:\n%s", diff)
+	}
+
+	policy := keepalive.EnforcementPolicy{MinTime: 30 * time.Second, PermitWithoutStream: true}
+	if diff := cmp.Diff(cfg.EnforcementPolicy(), policy); diff != "" {
+		t.Errorf("EnforcementPolicy() mismatch:\n%s", diff)
+	}
+}
+
+func TestLoadNodeConfig_InvalidKeepalive(t *testing.T) {
+	tests := map[string]string{
+		"no time":    "keepalive:\n  time: 0\n",
+		"no timeout": "keepalive:\n  timeout: -1\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			configFile := createTestConfigFile(t, "node-keepalive", content)
+			setupNodeTest(t, map[string]string{"plugin-dir": t.TempDir()}, nil)
+
+			if _, err := LoadNodeConfig(configFile); err == nil {
+				t.Error("expected an error for invalid keepalive delays")
+			}
+		})
+	}
+}
+
+func TestLoadManagerConfig_InvalidKeepalive(t *testing.T) {
+	tests := map[string]string{
+		"no time":             "keepalive:\n  time: 0\n",
+		"no minimum":          "keepalive:\n  min-time: 0\n",
+		"no upstream timeout": "manager-id: eu-manager\nupstream:\n  enabled: true\n  address: upper\n  keepalive:\n    timeout: 0\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			configFile := createTestManagerConfigFile(t, content)
+			setupManagerTest(t, nil, nil)
+
+			if _, err := LoadManagerConfig(configFile); err == nil {
+				t.Error("expected an error for invalid keepalive delays")
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxNodeIDLength is the maximum length of a node ID, like a hostname.
+const MaxNodeIDLength = 253
+
+// ErrInvalidNodeID is returned for a node ID with reserved characters, which would break the task
+// names, the list separator or the targeting patterns.
+var ErrInvalidNodeID = errors.New("invalid node ID")
+
+func isNodeIDChar(r rune, first bool) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	case r == '.', r == '-', r == '_':
+		return !first
+	}
+	return false
+}
+
+// ValidateNodeID checks that the node ID only contains letters, digits, '.', '-' and '_', and
+// starts with a letter or a digit.
+func ValidateNodeID(id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: empty", ErrInvalidNodeID)
+	}
+	if len(id) > MaxNodeIDLength {
+		return fmt.Errorf("%w: longer than %d characters", ErrInvalidNodeID, MaxNodeIDLength)
+	}
+	for i, r := range id {
+		if !isNodeIDChar(r, i == 0) {
+			return fmt.Errorf("%w: '%s': unexpected character %q, allowed: letters, digits, '.', '-' and '_' (not first)", ErrInvalidNodeID, id, r)
+		}
+	}
+	return nil
+}
+
+// NormalizeNodeID turns a name (e.g. a hostname) into a valid node ID: invalid characters are replaced
+// by '-', and the leading ones are removed.
+func NormalizeNodeID(name string) (string, error) {
+	var sb strings.Builder
+	for _, r := range name {
+		switch {
+		case isNodeIDChar(r, sb.Len() == 0):
+			sb.WriteRune(r)
+		case sb.Len() > 0:
+			sb.WriteByte('-')
+		}
+	}
+
+	id := sb.String()
+	if len(id) > MaxNodeIDLength {
+		id = id[:MaxNodeIDLength]
+	}
+	return id, ValidateNodeID(id)
+}
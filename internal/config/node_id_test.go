@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateNodeID(t *testing.T) {
+	valid := []string{"node1", "web-01", "db_primary", "host.example.com", "A1", strings.Repeat("a", MaxNodeIDLength)}
+	for _, id := range valid {
+		if err := ValidateNodeID(id); err != nil {
+			t.Errorf("ValidateNodeID(%q) unexpected error: %v", id, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"node:1",
+		"node1,node2",
+		"web*",
+		"web?",
+		"node[1]",
+		"my node",
+		"node\t1",
+		"-node",
+		".node",
+		"nœud",
+		strings.Repeat("a", MaxNodeIDLength+1),
+	}
+	for _, id := range invalid {
+		if err := ValidateNodeID(id); !errors.Is(err, ErrInvalidNodeID) {
+			t.Errorf("ValidateNodeID(%q) = %v, want ErrInvalidNodeID", id, err)
+		}
+	}
+}
+
+func TestNormalizeNodeID(t *testing.T) {
+	tests := map[string]string{
+		"node1":            "node1",
+		"host.example.com": "host.example.com",
+		"My Laptop":        "My-Laptop",
+		"--web:01":         "web-01",
+		"nœud":             "n-ud",
+	}
+	for name, want := range tests {
+		got, err := NormalizeNodeID(name)
+		if err != nil {
+			t.Errorf("NormalizeNodeID(%q) unexpected error: %v", name, err)
+		}
+		if got != want {
+			t.Errorf("NormalizeNodeID(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if _, err := NormalizeNodeID("***"); !errors.Is(err, ErrInvalidNodeID) {
+		t.Errorf("expected ErrInvalidNodeID for a name without valid characters, got %v", err)
+	}
+}
+
+func TestLoadNodeConfig_InvalidNodeID(t *testing.T) {
+	setupNodeTest(t, map[string]string{
+		"id":         "web*",
+		"plugin-dir": filepath.Join(t.TempDir(), "plugins"),
+	}, nil)
+
+	if _, err := LoadNodeConfig(""); !errors.Is(err, ErrInvalidNodeID) {
+		t.Errorf("expected ErrInvalidNodeID, got %v", err)
+	}
+}
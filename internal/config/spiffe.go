@@ -0,0 +1,113 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+// SPIFFEConfig enables the SPIFFE X.509 SVIDs, fetched and rotated by the Workload API, instead of the
+// certificate files of mTLS.
+type SPIFFEConfig struct {
+	Enabled     bool   `mapstructure:"enabled" yaml:"enabled"`
+	Socket      string `mapstructure:"socket" yaml:"socket"`             // Workload API address (e.g. unix:///run/spire/agent.sock), SPIFFE_ENDPOINT_SOCKET if empty.
+	TrustDomain string `mapstructure:"trust-domain" yaml:"trust-domain"` // The peer must have an SVID of this trust domain.
+}
+
+// SVIDSource provides the X.509 SVID of the local workload and the bundles verifying the peers.
+//
+// It is implemented by workloadapi.X509Source.
+type SVIDSource interface {
+	x509svid.Source
+	x509bundle.Source
+}
+
+// NewSPIFFESource connects to the Workload API and waits for the first SVID. It must be closed once unused.
+func NewSPIFFESource(ctx context.Context, socket string) (*workloadapi.X509Source, error) {
+	ctx, cancel := context.WithTimeout(ctx, SPIFFEFetchTimeout)
+	defer cancel()
+
+	var opts []workloadapi.X509SourceOption
+	if socket != "" {
+		opts = append(opts, workloadapi.WithClientOptions(workloadapi.WithAddr(socket)))
+	}
+	source, err := workloadapi.NewX509Source(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the SVID from the Workload API: %w", err)
+	}
+	return source, nil
+}
+
+// GetSPIFFEServerTLSConfig returns the TLS configuration of the manager, accepting the nodes with an SVID
+// of the trust domain.
+func GetSPIFFEServerTLSConfig(source SVIDSource, trustDomain string) (*tls.Config, error) {
+	td, err := spiffeid.TrustDomainFromString(trustDomain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust domain: %w", err)
+	}
+	tlsCfg := tlsconfig.MTLSServerConfig(source, source, tlsconfig.AuthorizeMemberOf(td))
+	tlsCfg.MinVersion = tls.VersionTLS12
+	return tlsCfg, nil
+}
+
+// GetSPIFFEClientTLSConfig returns the TLS configuration of the node, accepting a manager with an SVID of
+// the trust domain.
+func GetSPIFFEClientTLSConfig(source SVIDSource, trustDomain string) (*tls.Config, error) {
+	td, err := spiffeid.TrustDomainFromString(trustDomain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust domain: %w", err)
+	}
+	tlsCfg := tlsconfig.MTLSClientConfig(source, source, tlsconfig.AuthorizeMemberOf(td))
+	tlsCfg.MinVersion = tls.VersionTLS12
+	return tlsCfg, nil
+}
+
+// NodeIDFromSPIFFEID returns the node ID of a SPIFFE ID, the last segment of its path.
+//
+// Example: spiffe://example.org/jackadi/node/web-1 is the node web-1.
+func NodeIDFromSPIFFEID(id spiffeid.ID) (string, error) {
+	if id.Path() == "" {
+		return "", errors.New("the SPIFFE ID has no path")
+	}
+	nodeID := path.Base(id.Path())
+	if err := ValidateNodeID(nodeID); err != nil {
+		return "", fmt.Errorf("SPIFFE ID '%s': %w", id, err)
+	}
+	return nodeID, nil
+}
+
+// setupSPIFFEFlags declares the SPIFFE flags, peer being the side verified with the trust domain.
+func setupSPIFFEFlags(peer string) {
+	pflag.Bool("mtls.spiffe.enabled", false, "use the SPIFFE X.509 SVIDs of the Workload API instead of the certificate files")
+	pflag.String("mtls.spiffe.socket", "", "Workload API address, SPIFFE_ENDPOINT_SOCKET if empty")
+	pflag.String("mtls.spiffe.trust-domain", "", fmt.Sprintf("trust domain of the %s SVIDs", peer))
+}
+
+func setSPIFFEDefaults(v *viper.Viper) {
+	v.SetDefault("mtls.spiffe.enabled", false)
+	v.SetDefault("mtls.spiffe.socket", "")
+	v.SetDefault("mtls.spiffe.trust-domain", "")
+}
+
+func validateSPIFFE(mTLSEnabled bool, cfg SPIFFEConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if !mTLSEnabled {
+		return errors.New("SPIFFE (mtls.spiffe.enabled) requires mTLS (mtls.enabled)")
+	}
+	if _, err := spiffeid.TrustDomainFromString(cfg.TrustDomain); err != nil {
+		return fmt.Errorf("invalid SPIFFE trust domain (mtls.spiffe.trust-domain): %w", err)
+	}
+	return nil
+}
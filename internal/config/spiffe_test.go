@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+)
+
+func TestNodeIDFromSPIFFEID(t *testing.T) {
+	tests := []struct {
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{id: "spiffe://example.org/jackadi/node/web-1", want: "web-1"},
+		{id: "spiffe://example.org/web-1", want: "web-1"},
+		{id: "spiffe://example.org", wantErr: true},
+		{id: "spiffe://example.org/nodes/.web-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			id, err := spiffeid.FromString(tt.id)
+			if err != nil {
+				t.Fatalf("invalid SPIFFE ID: %v", err)
+			}
+			got, err := NodeIDFromSPIFFEID(id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NodeIDFromSPIFFEID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NodeIDFromSPIFFEID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSPIFFE(t *testing.T) {
+	tests := []struct {
+		name    string
+		mTLS    bool
+		cfg     SPIFFEConfig
+		wantErr bool
+	}{
+		{name: "disabled", cfg: SPIFFEConfig{}},
+		{name: "enabled", mTLS: true, cfg: SPIFFEConfig{Enabled: true, TrustDomain: "example.org"}},
+		{name: "without mTLS", cfg: SPIFFEConfig{Enabled: true, TrustDomain: "example.org"}, wantErr: true},
+		{name: "without trust domain", mTLS: true, cfg: SPIFFEConfig{Enabled: true}, wantErr: true},
+		{name: "invalid trust domain", mTLS: true, cfg: SPIFFEConfig{Enabled: true, TrustDomain: "Example Org"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSPIFFE(tt.mTLS, tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("validateSPIFFE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
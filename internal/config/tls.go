@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"os"
+	"strings"
 )
 
 // GetMTLSCertificate returns the TLS configuration using the provided certificates for mTLS.
@@ -48,3 +49,41 @@ func GetAPITLSCertificate(certFile, keyFile string) ([]tls.Certificate, error) {
 
 	return []tls.Certificate{cert}, nil
 }
+
+// GetPluginServerTLSConfig returns the TLS configuration of the plugin downloads, verifying the certificate of
+// the plugin server against the CA, or the system CAs if caFile is empty.
+//
+// The plugins are downloaded from the resolved address of the manager, serverName is the name its certificate
+// is verified for.
+func GetPluginServerTLSConfig(caFile, serverName string) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: strings.Trim(serverName, "[]"),
+	}
+	if caFile == "" {
+		return tlsCfg, nil
+	}
+
+	caBytes, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin server CA certificate '%s': %w", caFile, err)
+	}
+	tlsCfg.RootCAs = x509.NewCertPool()
+	if ok := tlsCfg.RootCAs.AppendCertsFromPEM(caBytes); !ok {
+		return nil, fmt.Errorf("failed to parse '%s'", caFile)
+	}
+	return tlsCfg, nil
+}
+
+// LoadCertPool returns the pool of the CA certificates of a PEM file.
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	caBytes, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate '%s': %w", caFile, err)
+	}
+	pool := x509.NewCertPool()
+	if ok := pool.AppendCertsFromPEM(caBytes); !ok {
+		return nil, fmt.Errorf("failed to parse '%s'", caFile)
+	}
+	return pool, nil
+}
@@ -2,6 +2,8 @@ package helper
 
 import (
 	"math"
+	"net"
+	"strings"
 	"time"
 )
 
@@ -31,3 +33,12 @@ func IntToUint32(timeout int) uint32 {
 	}
 	return timeoutInt32
 }
+
+// JoinHostPort combines a host and a port into a "host:port" address, bracketing the IPv6 hosts
+// (e.g. "[::1]:40080"). The host can already be bracketed, as in the configuration files.
+func JoinHostPort(host, port string) string {
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		host = host[1 : len(host)-1]
+	}
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,28 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestContextLogger(t *testing.T) {
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("expected the default logger without logger in the context")
+	}
+
+	var buf bytes.Buffer
+	ctx := WithLogger(context.Background(), slog.New(slog.NewJSONHandler(&buf, nil)))
+	ctx = With(ctx, "group_id", 42)
+	ctx = With(ctx, "request_id", 7)
+
+	FromContext(ctx).Info("task sent")
+	line := buf.String()
+	for _, want := range []string{`"msg":"task sent"`, `"group_id":42`, `"request_id":7`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("%s not found in %s", want, line)
+		}
+	}
+}
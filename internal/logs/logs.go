@@ -1,19 +1,126 @@
 package logs
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/jackadi-io/jackadi/internal/config"
 	"github.com/lmittmann/tint"
 )
 
+var (
+	level      = new(slog.LevelVar) // level of the default logger, changeable at runtime
+	mu         sync.Mutex
+	configured = slog.LevelDebug // level restored when the debug level is toggled off
+)
+
 func init() {
-	w := os.Stderr
-	slog.SetDefault(slog.New(
-		tint.NewHandler(w, &tint.Options{
-			Level:      slog.LevelDebug,
-			TimeFormat: time.Kitchen,
-		}),
-	))
+	level.Set(slog.LevelDebug)
+	h, _ := newHandler(os.Stderr, config.LogFormatText)
+	slog.SetDefault(slog.New(withRecent(h)))
+}
+
+// Setup configures the level and the format (config.LogFormatText or config.LogFormatJSON) of the default logger.
+//
+// The lines logged are also kept in Recent, in text format.
+func Setup(lvl, format string) error {
+	l, err := ParseLevel(lvl)
+	if err != nil {
+		return err
+	}
+	h, err := newHandler(os.Stderr, format)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	configured = l
+	mu.Unlock()
+	SetLevel(l)
+	slog.SetDefault(slog.New(withRecent(h)))
+	return nil
+}
+
+func newHandler(w io.Writer, format string) (slog.Handler, error) {
+	switch format {
+	case config.LogFormatText, "":
+		return tint.NewHandler(w, &tint.Options{Level: level, TimeFormat: time.Kitchen}), nil
+	case config.LogFormatJSON:
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}), nil
+	default:
+		return nil, fmt.Errorf("invalid log format '%s': expected %s or %s", format, config.LogFormatText, config.LogFormatJSON)
+	}
+}
+
+// ParseLevel parses a level name: debug, info, warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return l, fmt.Errorf("invalid log level '%s': expected debug, info, warn or error", s)
+	}
+	return l, nil
+}
+
+// SetLevel changes the level of the default logger, at runtime.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Level returns the current level of the default logger.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// ToggleDebug switches between the debug level and the configured one, and returns the new level.
+func ToggleDebug() slog.Level {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if level.Level() == slog.LevelDebug {
+		level.Set(configured)
+	} else {
+		level.Set(slog.LevelDebug)
+	}
+	return level.Level()
+}
+
+// WatchSignal toggles the debug level each time SIGUSR1 is received, until ctx is done.
+func WatchSignal(ctx context.Context) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGUSR1)
+	defer signal.Stop(sig)
+
+	for {
+		select {
+		case <-sig:
+			slog.Warn("log level changed", "level", ToggleDebug())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RepeatWarn logs the warning now, then again at each interval until ctx is done.
+//
+// It is meant for the conditions which must not go unnoticed in the logs, e.g. running without mTLS.
+func RepeatWarn(ctx context.Context, interval time.Duration, msg string, args ...any) {
+	slog.Warn(msg, args...)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			slog.Warn(msg, args...)
+		case <-ctx.Done():
+			return
+		}
+	}
 }
@@ -0,0 +1,141 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+)
+
+// restoreLevel restores the level of the default logger at the end of the test.
+func restoreLevel(t *testing.T) {
+	t.Helper()
+	prevLevel, prevConfigured := Level(), configured
+	t.Cleanup(func() {
+		SetLevel(prevLevel)
+		configured = prevConfigured
+	})
+}
+
+func TestSetLevel(t *testing.T) {
+	restoreLevel(t)
+
+	var buf bytes.Buffer
+	h, err := newHandler(&buf, config.LogFormatText)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logger := slog.New(h)
+
+	SetLevel(slog.LevelInfo)
+	logger.Debug("hidden")
+	if strings.Contains(buf.String(), "hidden") {
+		t.Errorf("debug message logged at info level: %s", buf.String())
+	}
+
+	SetLevel(slog.LevelDebug)
+	logger.Debug("visible")
+	if !strings.Contains(buf.String(), "visible") {
+		t.Errorf("debug message not logged after the level change: %s", buf.String())
+	}
+}
+
+func TestToggleDebug(t *testing.T) {
+	restoreLevel(t)
+
+	configured = slog.LevelWarn
+	SetLevel(slog.LevelWarn)
+
+	if got := ToggleDebug(); got != slog.LevelDebug {
+		t.Errorf("expected debug level, got %v", got)
+	}
+	if got := ToggleDebug(); got != slog.LevelWarn {
+		t.Errorf("expected the configured level to be restored, got %v", got)
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	restoreLevel(t)
+	SetLevel(slog.LevelInfo)
+
+	var buf bytes.Buffer
+	h, err := newHandler(&buf, config.LogFormatJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	logger := slog.New(h)
+	logger.Info("first", "node", "node1")
+	logger.Warn("second with \"quotes\"\nand a new line", "count", 2)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		if entry["msg"] == nil || entry["level"] == nil {
+			t.Errorf("missing msg or level: %v", entry)
+		}
+	}
+}
+
+func TestSetupInvalid(t *testing.T) {
+	if err := Setup("verbose", config.LogFormatText); err == nil {
+		t.Error("expected an error for an invalid level")
+	}
+	if err := Setup(config.DefaultLogLevel, "xml"); err == nil {
+		t.Error("expected an error for an invalid format")
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) count(s string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Count(b.buf.String(), s)
+}
+
+func TestRepeatWarn(t *testing.T) {
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	buf := &syncBuffer{}
+	slog.SetDefault(slog.New(slog.NewTextHandler(buf, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		RepeatWarn(ctx, 10*time.Millisecond, "insecure", "component", "test")
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for buf.count("msg=insecure") < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if n := buf.count("msg=insecure component=test"); n < 3 {
+		t.Errorf("expected the warning to be repeated, got %d warnings", n)
+	}
+}
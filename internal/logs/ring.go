@@ -0,0 +1,117 @@
+package logs
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+)
+
+// Recent keeps the last lines logged by the default logger, to fetch them remotely (see jack nodes logs).
+var Recent = NewRing(config.LogRingSize)
+
+// Line is a line of the log, numbered from 1 in the order of logging.
+type Line struct {
+	Seq  uint64 `jackadi:"seq"`
+	Text string `jackadi:"text"`
+}
+
+// Ring is a ring buffer of log lines, written by a slog text handler.
+type Ring struct {
+	mu    sync.Mutex
+	lines []Line
+	next  int    // index of the next line to write, once the buffer is full
+	seq   uint64 // sequence number of the last line written
+}
+
+// NewRing returns a ring buffer keeping the last size lines.
+func NewRing(size int) *Ring {
+	return &Ring{lines: make([]Line, 0, size)}
+}
+
+// Write adds the lines written by the handler, a record at a time.
+func (r *Ring) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for text := range strings.SplitSeq(strings.TrimSuffix(string(p), "\n"), "\n") {
+		r.seq++
+		line := Line{Seq: r.seq, Text: text}
+		if len(r.lines) < cap(r.lines) {
+			r.lines = append(r.lines, line)
+			continue
+		}
+		if len(r.lines) == 0 {
+			continue // no capacity
+		}
+		r.lines[r.next] = line
+		r.next = (r.next + 1) % len(r.lines)
+	}
+	return len(p), nil
+}
+
+// Since returns the lines logged after the sequence number since, at most the last limit ones if limit is positive,
+// the oldest first.
+func (r *Ring) Since(since uint64, limit int) []Line {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lines := make([]Line, 0, len(r.lines))
+	for i := range len(r.lines) {
+		line := r.lines[(r.next+i)%len(r.lines)]
+		if line.Seq > since {
+			lines = append(lines, line)
+		}
+	}
+	if limit > 0 && len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	return lines
+}
+
+// withRecent returns a handler passing the records to h and to the Recent buffer.
+func withRecent(h slog.Handler) slog.Handler {
+	return teeHandler{h, slog.NewTextHandler(Recent, &slog.HandlerOptions{Level: level})}
+}
+
+// teeHandler passes the records to each of its handlers.
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, record slog.Record) error {
+	var err error
+	for _, h := range t {
+		if h.Enabled(ctx, record.Level) {
+			if e := h.Handle(ctx, record.Clone()); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
@@ -0,0 +1,72 @@
+package logs
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRing(t *testing.T) {
+	r := NewRing(3)
+	if lines := r.Since(0, 0); len(lines) != 0 {
+		t.Errorf("expected no line, got %v", lines)
+	}
+
+	for i := range 5 {
+		fmt.Fprintf(r, "line %d\n", i+1)
+	}
+	want := []Line{{3, "line 3"}, {4, "line 4"}, {5, "line 5"}}
+	if diff := cmp.Diff(want, r.Since(0, 0)); diff != "" {
+		t.Errorf("expected the last lines (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want[2:], r.Since(0, 1)); diff != "" {
+		t.Errorf("expected the last line (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want[1:], r.Since(3, 0)); diff != "" {
+		t.Errorf("expected the lines after 3 (-want +got):\n%s", diff)
+	}
+	if lines := r.Since(5, 0); len(lines) != 0 {
+		t.Errorf("expected no new line, got %v", lines)
+	}
+
+	fmt.Fprint(r, "first\nsecond\n")
+	if diff := cmp.Diff([]Line{{6, "first"}, {7, "second"}}, r.Since(5, 0)); diff != "" {
+		t.Errorf("expected a line per line written (-want +got):\n%s", diff)
+	}
+}
+
+func TestRecent(t *testing.T) {
+	restoreLevel(t)
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	if err := Setup("info", "json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := Recent.Since(0, 1)
+	var since uint64
+	if len(last) > 0 {
+		since = last[0].Seq
+	}
+
+	slog.Debug("hidden")
+	slog.Info("task started", "task", "cmd.run")
+	slog.With("node", "node1").Warn("slow task")
+
+	lines := Recent.Since(since, 0)
+	if len(lines) != 2 {
+		t.Fatalf("expected the 2 lines logged at info level, got %v", lines)
+	}
+	if !strings.Contains(lines[0].Text, "msg=\"task started\" task=cmd.run") {
+		t.Errorf("expected the first line in text format, got %q", lines[0].Text)
+	}
+	if !strings.Contains(lines[1].Text, "msg=\"slow task\" node=node1") {
+		t.Errorf("expected the attributes of the logger in the second line, got %q", lines[1].Text)
+	}
+	if lines[1].Seq != lines[0].Seq+1 {
+		t.Errorf("expected consecutive sequence numbers, got %d and %d", lines[0].Seq, lines[1].Seq)
+	}
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/config"
+)
+
+// GCOutcome describes the result of a value log garbage collection, over all its passes.
+type GCOutcome struct {
+	Time      time.Time
+	Rewrites  int   // value log files rewritten
+	Reclaimed int64 // bytes, from the value log size before and after each pass
+	Err       error // nil once there is nothing left to collect
+}
+
+// GarbageCollector runs the value log garbage collection and keeps track of its last outcome.
+//
+// Each run of the GC makes passes as long as they reclaim space. The delay between two runs is shortened when
+// space has been reclaimed or when the database had many writes, and lengthened otherwise.
+type GarbageCollector struct {
+	db          *badger.DB
+	lastVersion uint64 // used to estimate the write volume between two runs
+	mutex       sync.Mutex
+	last        GCOutcome
+}
+
+func NewGarbageCollector(db *badger.DB) *GarbageCollector {
+	return &GarbageCollector{
+		db:          db,
+		lastVersion: db.MaxVersion(),
+	}
+}
+
+// Run collects the value log garbage until the context is cancelled.
+func (g *GarbageCollector) Run(ctx context.Context) {
+	interval := config.DatabaseGCInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return
+		}
+
+		outcome := g.Collect(ctx)
+		interval = g.nextInterval(interval, outcome.Rewrites)
+		slog.Debug("database GC run", "rewrites", outcome.Rewrites, "next", interval)
+		timer.Reset(interval)
+	}
+}
+
+// nextInterval returns the delay before the next GC run.
+func (g *GarbageCollector) nextInterval(current time.Duration, rewrites int) time.Duration {
+	version := g.db.MaxVersion()
+	writes := version - g.lastVersion
+	g.lastVersion = version
+
+	if rewrites > 0 || writes >= config.DBGCWriteVolume {
+		return max(current/2, config.DatabaseGCMinInterval)
+	}
+	return min(current*2, config.DatabaseGCMaxInterval)
+}
+
+// Collect runs the value log garbage collection until there is nothing left to reclaim or the context is
+// cancelled, and records its outcome. Nothing is recorded if it is cancelled before the first pass.
+func (g *GarbageCollector) Collect(ctx context.Context) GCOutcome {
+	var outcome GCOutcome
+	passes := 0
+	for ctx.Err() == nil {
+		passes++
+		before := g.valueLogSize()
+		err := g.db.RunValueLogGC(config.DBGCThreshold)
+		outcome.Reclaimed += max(before-g.valueLogSize(), 0)
+		if err != nil {
+			if !errors.Is(err, badger.ErrNoRewrite) {
+				outcome.Err = err
+			}
+			break
+		}
+		outcome.Rewrites++
+	}
+	if passes == 0 {
+		return outcome
+	}
+	outcome.Time = time.Now()
+
+	switch {
+	case outcome.Err != nil:
+		slog.Warn("database GC failed", "rewrites", outcome.Rewrites, "reclaimed", outcome.Reclaimed, "error", outcome.Err)
+	case outcome.Rewrites > 0:
+		slog.Info("database GC done", "rewrites", outcome.Rewrites, "reclaimed", outcome.Reclaimed)
+	default:
+		slog.Debug("database GC: nothing to reclaim")
+	}
+
+	g.mutex.Lock()
+	g.last = outcome
+	g.mutex.Unlock()
+	return outcome
+}
+
+// Last returns the outcome of the last garbage collection, zero if none ran yet.
+func (g *GarbageCollector) Last() GCOutcome {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.last
+}
+
+// valueLogSize returns the size of the value log files.
+//
+// Unlike db.Size(), which is only refreshed every minute, it reflects the files rewritten by the GC.
+func (g *GarbageCollector) valueLogSize() int64 {
+	opts := g.db.Opts()
+	if opts.InMemory {
+		return 0
+	}
+
+	files, err := filepath.Glob(filepath.Join(opts.ValueDir, "*.vlog"))
+	if err != nil {
+		return 0
+	}
+	size := int64(0)
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGarbageDB returns a database whose value log is mostly made of overwritten values.
+//
+// Small tables and memtables force the compactions which let Badger know what can be discarded.
+func newGarbageDB(t *testing.T) *badger.DB {
+	t.Helper()
+	opts := badger.DefaultOptions(t.TempDir()).
+		WithLogger(nil).
+		WithValueLogFileSize(1 << 20).
+		WithValueThreshold(1 << 10).
+		WithMemTableSize(16 << 10).
+		WithBaseTableSize(16 << 10).
+		WithNumLevelZeroTables(1).
+		WithNumLevelZeroTablesStall(2).
+		WithNumVersionsToKeep(1)
+
+	db, err := badger.Open(opts)
+	require.NoError(t, err)
+
+	value := make([]byte, 10<<10)
+	for range 3 {
+		for i := range 300 {
+			err := db.Update(func(txn *badger.Txn) error {
+				return txn.Set(fmt.Appendf(nil, "res:%d", i), value)
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	// reopening flushes the memtable
+	require.NoError(t, db.Close())
+	db, err = badger.Open(opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	require.NoError(t, db.Flatten(1))
+	return db
+}
+
+func TestGarbageCollectorLoopsWhileReclaiming(t *testing.T) {
+	db := newGarbageDB(t)
+	gc := NewGarbageCollector(db)
+	before := gc.valueLogSize()
+
+	outcome := gc.Collect(context.Background())
+	assert.Greater(t, outcome.Rewrites, 1, "GC should run again as long as it reclaims space")
+	assert.Equal(t, before-gc.valueLogSize(), outcome.Reclaimed, "all the passes should be counted")
+	assert.Positive(t, outcome.Reclaimed)
+	assert.NoError(t, outcome.Err, "nothing left to reclaim is the normal end of a run")
+	assert.Equal(t, outcome, gc.Last())
+
+	outcome = gc.Collect(context.Background())
+	assert.NoError(t, outcome.Err)
+	assert.Zero(t, outcome.Rewrites)
+	assert.Zero(t, outcome.Reclaimed)
+	assert.False(t, outcome.Time.IsZero())
+}
+
+func TestGarbageCollectorCancelled(t *testing.T) {
+	db := newGarbageDB(t)
+	gc := NewGarbageCollector(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Zero(t, gc.Collect(ctx).Rewrites)
+	assert.True(t, gc.Last().Time.IsZero(), "no GC should run once cancelled")
+
+	done := make(chan struct{})
+	go func() {
+		gc.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after cancellation")
+	}
+}
+
+func TestGarbageCollectorNextInterval(t *testing.T) {
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	defer db.Close()
+	gc := NewGarbageCollector(db)
+
+	// idle: back off
+	assert.Equal(t, 2*config.DatabaseGCInterval, gc.nextInterval(config.DatabaseGCInterval, 0))
+	assert.Equal(t, config.DatabaseGCMaxInterval, gc.nextInterval(config.DatabaseGCMaxInterval, 0))
+
+	// space reclaimed: more garbage is likely
+	assert.Equal(t, config.DatabaseGCInterval/2, gc.nextInterval(config.DatabaseGCInterval, 1))
+	assert.Equal(t, config.DatabaseGCMinInterval, gc.nextInterval(config.DatabaseGCMinInterval, 3))
+
+	// write burst
+	for i := range config.DBGCWriteVolume {
+		err := db.Update(func(txn *badger.Txn) error {
+			return txn.Set(fmt.Appendf(nil, "res:%d", i), []byte("result"))
+		})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, config.DatabaseGCInterval/2, gc.nextInterval(config.DatabaseGCInterval, 0))
+	assert.Equal(t, 2*config.DatabaseGCInterval, gc.nextInterval(config.DatabaseGCInterval, 0), "write volume is counted since the last run")
+}
@@ -28,3 +28,8 @@ func GenerateRequestKey(id int64) []byte {
 func GenerateRequestKeyFromString(id string) []byte {
 	return fmt.Appendf(nil, "%s:%s", RequestKeyPrefix, id)
 }
+
+// GeneratePendingKey creates a database key for storing a scheduled request until it runs.
+func GeneratePendingKey(id int64) []byte {
+	return fmt.Appendf(nil, "%s:%d", PendingKeyPrefix, id)
+}
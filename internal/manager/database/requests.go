@@ -0,0 +1,39 @@
+package database
+
+import (
+	"github.com/dgraph-io/badger/v4"
+)
+
+// GetRequest returns a stored request.
+func GetRequest(txn *badger.Txn, requestID int64) (*Request, error) {
+	item, err := txn.Get(GenerateRequestKey(requestID))
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return UnmarshalRequest(val)
+}
+
+// GetRequestTask returns the task name of a stored request.
+func GetRequestTask(txn *badger.Txn, requestID int64) (string, error) {
+	request, err := GetRequest(txn, requestID)
+	if err != nil {
+		return "", err
+	}
+	return request.Task, nil
+}
+
+// MatchMetadata returns true if the request has all the wanted metadata.
+func (r *Request) MatchMetadata(wanted map[string]string) bool {
+	for key, value := range wanted {
+		if got, ok := r.Metadata[key]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
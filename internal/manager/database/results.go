@@ -1,8 +1,16 @@
 package database
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/node"
+	"github.com/jackadi-io/jackadi/internal/proto"
 )
 
 // CutGroupPrefix checks if a result string represents a grouped result.
@@ -44,3 +52,147 @@ func ExtractRequestIDFromResult(result string, resultID string) (string, error)
 		return fmt.Sprintf("%s:%s", RequestKeyPrefix, resultID), nil
 	}
 }
+
+// ResultStatus summarizes a task result as a human readable status.
+//
+// Possible values are "success", "error", "internal error" and "unknown".
+func ResultStatus(result *proto.TaskResponse) string {
+	if result == nil {
+		return "unknown"
+	}
+
+	switch {
+	case result.GetInternalError() == proto.InternalError_OK && result.GetError() == "":
+		return "success"
+	case result.GetError() != "":
+		return "error"
+	default:
+		return "internal error"
+	}
+}
+
+// DeleteResults deletes results by ID, and removes them from their group.
+//
+// Deleting a group ID deletes all the results of the group. It returns the IDs of the deleted results.
+func DeleteResults(db ResultStore, ids []int64) ([]int64, error) {
+	deleted := []int64{}
+	for chunk := range slices.Chunk(ids, config.DBDeleteBatchSize) {
+		// the IDs of a chunk are only deleted once its transaction is committed
+		var chunkDeleted []int64
+		err := db.Update(func(txn *badger.Txn) error {
+			chunkDeleted = chunkDeleted[:0]
+			for _, id := range chunk {
+				ids, err := deleteResult(txn, id)
+				if err != nil {
+					return err
+				}
+				chunkDeleted = append(chunkDeleted, ids...)
+			}
+			return nil
+		})
+		if err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, chunkDeleted...)
+	}
+	return deleted, nil
+}
+
+// deleteResult deletes a result or a group of results.
+func deleteResult(txn *badger.Txn, id int64) ([]int64, error) {
+	key := GenerateResultKey(strconv.FormatInt(id, 10))
+	val, err := getValue(txn, key)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if members, ok := CutGroupPrefix(string(val)); ok {
+		deleted := []int64{}
+		for member := range strings.SplitSeq(members, ",") {
+			memberID, err := strconv.ParseInt(member, 10, 64)
+			if err != nil {
+				continue
+			}
+			memberKey := GenerateResultKey(member)
+			if _, err := txn.Get(memberKey); err != nil {
+				continue
+			}
+			if err := txn.Delete(memberKey); err != nil {
+				return nil, err
+			}
+			deleted = append(deleted, memberID)
+		}
+		return deleted, txn.Delete(key)
+	}
+
+	if err := txn.Delete(key); err != nil {
+		return nil, err
+	}
+
+	task, err := UnmarshalTask(val)
+	if err == nil && task.Result.GetGroupID() != 0 {
+		if err := removeFromGroup(txn, task.Result.GetGroupID(), id); err != nil {
+			return nil, err
+		}
+	}
+	return []int64{id}, nil
+}
+
+// removeFromGroup removes a result from its group. The group is deleted once empty.
+func removeFromGroup(txn *badger.Txn, groupID, id int64) error {
+	key := GenerateResultKey(strconv.FormatInt(groupID, 10))
+	item, err := txn.Get(key)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	val, err := item.ValueCopy(nil)
+	if err != nil {
+		return err
+	}
+
+	members, ok := CutGroupPrefix(string(val))
+	if !ok {
+		return nil
+	}
+	remaining := slices.DeleteFunc(strings.Split(members, ","), func(member string) bool {
+		return member == strconv.FormatInt(id, 10)
+	})
+	if len(remaining) == 0 {
+		return txn.Delete(key)
+	}
+
+	entry := badger.NewEntry(key, []byte("grouped:"+strings.Join(remaining, ",")))
+	entry.ExpiresAt = item.ExpiresAt() // keeps the original TTL
+	return txn.SetEntry(entry)
+}
+
+// ResultOwner returns the node of the result stored at key, or an empty ID if the key is the one of a group of
+// results. It returns badger.ErrKeyNotFound if the key is not used.
+func ResultOwner(txn *badger.Txn, key []byte) (node.ID, error) {
+	val, err := getValue(txn, key)
+	if err != nil {
+		return "", err
+	}
+	if _, isGrouped := CutGroupPrefix(string(val)); isGrouped {
+		return "", nil
+	}
+	task, err := UnmarshalTask(val)
+	if err != nil {
+		return "", err
+	}
+	return task.Node, nil
+}
+
+func getValue(txn *badger.Txn, key []byte) ([]byte, error) {
+	item, err := txn.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
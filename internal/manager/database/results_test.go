@@ -0,0 +1,58 @@
+package database
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingCommitStore fails the commit of the transactions after the first ones, once their function succeeded.
+type failingCommitStore struct {
+	*badger.DB
+	commits int // Transactions committed before the failures.
+}
+
+func (s *failingCommitStore) Update(fn func(txn *badger.Txn) error) error {
+	if s.commits > 0 {
+		s.commits--
+		return s.DB.Update(fn)
+	}
+	txn := s.NewTransaction(true)
+	defer txn.Discard()
+	if err := fn(txn); err != nil {
+		return err
+	}
+	return errors.New("commit failed")
+}
+
+func TestDeleteResultsFailedCommit(t *testing.T) {
+	db, err := badger.Open(badger.DefaultOptions(t.TempDir()).WithLogger(nil))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	ids := make([]int64, config.DBDeleteBatchSize+1)
+	require.NoError(t, db.Update(func(txn *badger.Txn) error {
+		for i := range ids {
+			ids[i] = int64(i + 1)
+			if err := txn.Set(GenerateResultKey(strconv.FormatInt(ids[i], 10)), []byte(`{}`)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	deleted, err := DeleteResults(&failingCommitStore{DB: db, commits: 1}, ids)
+	require.Error(t, err)
+	assert.Equal(t, ids[:config.DBDeleteBatchSize], deleted, "the IDs of the failed chunk must not be reported deleted")
+
+	err = db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(GenerateResultKey(strconv.FormatInt(ids[len(ids)-1], 10)))
+		return err
+	})
+	assert.NoError(t, err, "the result of the failed chunk must be kept")
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"sync"
+	"time"
+)
+
+// Sequence generates the IDs of requests and results.
+//
+// IDs are nanosecond timestamps so that they can be filtered by date, and sorted chronologically in
+// the database as keys are ordered. Each ID is strictly greater than the previous one, even when the
+// clock steps backward (e.g. NTP adjustment): the ID is then the previous one plus one nanosecond.
+type Sequence struct {
+	mutex sync.Mutex
+	last  int64
+}
+
+// Next returns a new ID for the given time.
+func (s *Sequence) Next(now time.Time) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := now.UnixNano()
+	if id <= s.last {
+		id = s.last + 1
+	}
+	s.last = id
+	return id
+}
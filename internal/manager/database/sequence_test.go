@@ -0,0 +1,70 @@
+package database
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/clock"
+)
+
+func TestSequenceClockStep(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	seq := Sequence{}
+
+	first := seq.Next(fake.Now())
+	if first != start.UnixNano() {
+		t.Errorf("ID should be the timestamp, got %d, want %d", first, start.UnixNano())
+	}
+
+	// same instant and backward step (NTP adjustment)
+	second := seq.Next(fake.Now())
+	fake.Advance(-time.Hour)
+	third := seq.Next(fake.Now())
+	if second <= first || third <= second {
+		t.Errorf("IDs are not increasing: %d, %d, %d", first, second, third)
+	}
+
+	// keys must be ordered as IDs
+	keys := []string{
+		string(GenerateResultKey(strconv.FormatInt(first, 10))),
+		string(GenerateResultKey(strconv.FormatInt(second, 10))),
+		string(GenerateResultKey(strconv.FormatInt(third, 10))),
+	}
+	for i := 1; i < len(keys); i++ {
+		if keys[i] <= keys[i-1] {
+			t.Errorf("key %q is not after %q", keys[i], keys[i-1])
+		}
+	}
+
+	// once the clock is back ahead, IDs are timestamps again
+	fake.Advance(2 * time.Hour)
+	if got := seq.Next(fake.Now()); got != fake.Now().UnixNano() {
+		t.Errorf("ID should be the timestamp, got %d, want %d", got, fake.Now().UnixNano())
+	}
+}
+
+func TestSequenceConcurrent(t *testing.T) {
+	seq := Sequence{}
+	now := time.Now()
+
+	var mutex sync.Mutex
+	seen := make(map[int64]bool)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			for range 100 {
+				id := seq.Next(now)
+				mutex.Lock()
+				if seen[id] {
+					t.Errorf("duplicate ID %d", id)
+				}
+				seen[id] = true
+				mutex.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+var ErrStoreDegraded = errors.New("result storage unavailable (degraded mode)")
+
+// ResultStore stores the requests, the results and the scheduled requests of the tasks, implemented by *badger.DB.
+type ResultStore interface {
+	Update(fn func(txn *badger.Txn) error) error
+	View(fn func(txn *badger.Txn) error) error
+	Backup(w io.Writer, since uint64) (uint64, error)
+	Load(r io.Reader, maxPendingWrites int) error
+	DropAll() error
+	Size() (lsm, vlog int64)
+	Tables() []badger.TableInfo
+}
+
+// DegradedStore replaces the database when it cannot be opened, so the manager keeps dispatching the tasks.
+//
+// Nothing is stored: the writes are dropped and the reads fail with ErrStoreDegraded, the callers logging the
+// results and requests not recorded.
+type DegradedStore struct {
+	cause error
+}
+
+func NewDegradedStore(cause error) *DegradedStore {
+	return &DegradedStore{cause: cause}
+}
+
+func (s *DegradedStore) err() error {
+	return fmt.Errorf("%w: %w", ErrStoreDegraded, s.cause)
+}
+
+func (s *DegradedStore) Update(func(txn *badger.Txn) error) error { return s.err() }
+func (s *DegradedStore) View(func(txn *badger.Txn) error) error   { return s.err() }
+func (s *DegradedStore) Backup(io.Writer, uint64) (uint64, error) { return 0, s.err() }
+func (s *DegradedStore) Load(io.Reader, int) error                { return s.err() }
+func (s *DegradedStore) DropAll() error                           { return s.err() }
+func (s *DegradedStore) Size() (int64, int64)                     { return 0, 0 }
+func (s *DegradedStore) Tables() []badger.TableInfo               { return nil }
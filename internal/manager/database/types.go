@@ -8,6 +8,7 @@ import (
 const (
 	ResultKeyPrefix  = "res"
 	RequestKeyPrefix = "req"
+	PendingKeyPrefix = "pend" // Scheduled requests not run yet.
 )
 
 type Task struct {
@@ -19,6 +20,7 @@ type Request struct {
 	Task               string
 	ConnectedTarget    []string
 	DisconnectedTarget []string
+	Metadata           map[string]string `json:",omitempty"`
 }
 
 type Key struct {
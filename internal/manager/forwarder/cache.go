@@ -0,0 +1,59 @@
+package forwarder
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru keeps the most recently used values built from a key, e.g. the compiled regexes of the targets.
+type lru[V any] struct {
+	mutex sync.Mutex
+	size  int
+	order *list.List // Most recently used first.
+	items map[string]*list.Element
+}
+
+type lruEntry[V any] struct {
+	key   string
+	value V
+}
+
+func newLRU[V any](size int) *lru[V] {
+	return &lru[V]{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// get returns the value of the key, built and cached if missing. The errors of build are not cached.
+//
+// The value is built without holding the lock: concurrent misses of the same key build it several times.
+func (c *lru[V]) get(key string, build func(string) (V, error)) (V, error) {
+	c.mutex.Lock()
+	if elem, ok := c.items[key]; ok {
+		c.order.MoveToFront(elem)
+		c.mutex.Unlock()
+		return elem.Value.(*lruEntry[V]).value, nil
+	}
+	c.mutex.Unlock()
+
+	value, err := build(key)
+	if err != nil {
+		return value, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*lruEntry[V]).value, nil
+	}
+	c.items[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[V]).key)
+	}
+	return value, nil
+}
@@ -0,0 +1,121 @@
+package forwarder
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/node"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+func TestLRU(t *testing.T) {
+	builds := 0
+	build := func(key string) (string, error) {
+		builds++
+		if key == "invalid" {
+			return "", errors.New("invalid key")
+		}
+		return "value-" + key, nil
+	}
+
+	cache := newLRU[string](2)
+	for _, key := range []string{"a", "b", "a", "c"} { // c evicts b, a being used more recently
+		if _, err := cache.get(key, build); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if builds != 3 {
+		t.Errorf("expected 3 builds, got %d", builds)
+	}
+
+	if v, _ := cache.get("a", build); v != "value-a" || builds != 3 {
+		t.Errorf("expected a cached, got %q after %d builds", v, builds)
+	}
+	_, _ = cache.get("b", build)
+	if builds != 4 {
+		t.Errorf("expected b evicted, got %d builds", builds)
+	}
+
+	for range 2 {
+		if _, err := cache.get("invalid", build); err == nil {
+			t.Error("expected an error")
+		}
+	}
+	if builds != 6 {
+		t.Errorf("the errors must not be cached, got %d builds", builds)
+	}
+}
+
+// newTargetingDispatcher returns a dispatcher with nodes and specs to target.
+func newTargetingDispatcher(tb testing.TB, nodes int) Dispatcher[string, string] {
+	tb.Helper()
+	inv := inventory.New()
+	inv.DisableRegistryFile()
+	dispatcher := NewDispatcher[string, string](&inv)
+
+	for i := range nodes {
+		id := node.ID(fmt.Sprintf("web-%03d", i))
+		_ = dispatcher.RegisterNode(id)
+		inv.MarkNodeStateChange(id, true)
+		_ = inv.SetSpec(id, map[string]any{"os": map[string]any{"family": []string{"debian", "redhat"}[i%2]}, "rack": i % 10})
+	}
+	return dispatcher
+}
+
+func TestTargetedNodesCache(t *testing.T) {
+	cached := newTargetingDispatcher(t, 50)
+	uncached := cached
+	uncached.regexes = newLRU[*regexp.Regexp](0)
+	uncached.queries = newLRU[query](0)
+
+	targets := []*proto.Target{
+		{Target: "web-0[0-4].", Mode: proto.TargetMode_REGEX},
+		{Target: "specs:os.family:deb.*", Mode: proto.TargetMode_REGEX},
+		{Target: "specs.os.family==redhat and id=~/web-00.*/", Mode: proto.TargetMode_QUERY},
+		{Target: "specs.rack=~/[13]/ or id==web-042", Mode: proto.TargetMode_QUERY},
+	}
+	for _, target := range targets {
+		want, err := uncached.TargetedNodes(target.GetTarget(), target.GetMode())
+		if err != nil {
+			t.Fatalf("target %q: unexpected error: %v", target.GetTarget(), err)
+		}
+		for range 2 { // the second time from the cache
+			got, err := cached.TargetedNodes(target.GetTarget(), target.GetMode())
+			if err != nil {
+				t.Fatalf("target %q: unexpected error: %v", target.GetTarget(), err)
+			}
+			if diff := cmp.Diff(got, want); diff != "" {
+				t.Errorf("Mismatch for target %q (-cached +uncached):\n%s", target.GetTarget(), diff)
+			}
+		}
+	}
+}
+
+func BenchmarkTargetedNodes(b *testing.B) {
+	targets := []*proto.Target{
+		{Target: "web-0[0-4].", Mode: proto.TargetMode_REGEX},
+		{Target: "specs.os.family=~/deb.*/ and id=~/web-0.*/", Mode: proto.TargetMode_QUERY},
+	}
+	for _, target := range targets {
+		b.Run(target.GetMode().String()+"/cached", func(b *testing.B) {
+			dispatcher := newTargetingDispatcher(b, 100)
+			b.ReportAllocs()
+			for b.Loop() {
+				_, _ = dispatcher.TargetedNodes(target.GetTarget(), target.GetMode())
+			}
+		})
+		b.Run(target.GetMode().String()+"/uncached", func(b *testing.B) {
+			dispatcher := newTargetingDispatcher(b, 100)
+			dispatcher.regexes = newLRU[*regexp.Regexp](0)
+			dispatcher.queries = newLRU[query](0)
+			b.ReportAllocs()
+			for b.Loop() {
+				_, _ = dispatcher.TargetedNodes(target.GetTarget(), target.GetMode())
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -22,6 +23,8 @@ import (
 var ErrNodeNotFound = errors.New("node not found")
 var ErrClosedTaskChannel = errors.New("closed task channel")
 var ErrTimeout = errors.New("timeout")
+var ErrStopped = errors.New("stopped")
+var ErrNoMatchingNode = errors.New("no connected node is matching")
 
 type Task[R, A any] struct {
 	Request    R
@@ -36,6 +39,8 @@ type Dispatcher[R, A any] struct {
 	dispatch          map[node.ID]chan Task[R, A]
 	dispatchableNodes map[node.ID]bool
 	nodesInventory    *inventory.Nodes
+	regexes           *lru[*regexp.Regexp]
+	queries           *lru[query]
 }
 
 func NewDispatcher[R, A any](nodesInventory *inventory.Nodes) Dispatcher[R, A] {
@@ -44,6 +49,8 @@ func NewDispatcher[R, A any](nodesInventory *inventory.Nodes) Dispatcher[R, A] {
 		dispatch:          make(map[node.ID]chan Task[R, A]),
 		dispatchableNodes: make(map[node.ID]bool),
 		nodesInventory:    nodesInventory,
+		regexes:           newLRU[*regexp.Regexp](config.TargetCacheSize),
+		queries:           newLRU[query](config.TargetCacheSize),
 	}
 }
 
@@ -104,6 +111,12 @@ func (d *Dispatcher[R, A]) isReady(nodeID node.ID) bool {
 }
 
 func (d *Dispatcher[R, A]) Send(nodeID node.ID, task Task[R, A], timeout time.Duration) error {
+	return d.SendUntil(nodeID, task, timeout, nil)
+}
+
+// SendUntil is Send giving up with ErrStopped once stop is closed, e.g. when the run is cancelled while the node
+// is busy dispatching other tasks.
+func (d *Dispatcher[R, A]) SendUntil(nodeID node.ID, task Task[R, A], timeout time.Duration, stop <-chan struct{}) error {
 	d.mutex.RLock()
 	defer d.mutex.RUnlock()
 
@@ -119,6 +132,8 @@ func (d *Dispatcher[R, A]) Send(nodeID node.ID, task Task[R, A], timeout time.Du
 	case ch <- task:
 	case <-time.After(timeout):
 		return ErrTimeout
+	case <-stop:
+		return ErrStopped
 	}
 	return nil
 }
@@ -140,6 +155,7 @@ func (d *Dispatcher[R, A]) GetTasksChannel(nodeID node.ID) (chan Task[R, A], err
 //   - The mode in argument enables to filter target using different methods: exact match, list (sep: ','), glob, regex.
 //   - For glob filter, please check filepath documentation: https://pkg.go.dev/path/filepath#Match
 //   - For regex filter, please check regex documentation: https://pkg.go.dev/regexp
+//   - Glob and regex filters match the ID of the nodes, or a spec with the specs:<path>:<pattern> syntax
 //   - For query filter, check Jackadi documentation
 //
 // Special note for regex filter: '^' and '$' are enforced to only do strict matching.
@@ -147,6 +163,43 @@ func (d *Dispatcher[R, A]) TargetedNodes(target string, mode proto.TargetMode) (
 	d.mutex.RLock()
 	defer d.mutex.RUnlock()
 
+	return d.targetedNodes(target, mode)
+}
+
+// TargetedNodesUnion returns the union of the nodes of several targets, see TargetedNodes.
+//
+// A target matching no node is not an error, unless no target matches any node.
+func (d *Dispatcher[R, A]) TargetedNodesUnion(targets []*proto.Target) (map[string]bool, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	switch len(targets) {
+	case 0:
+		return nil, errors.New("no target")
+	case 1:
+		return d.targetedNodes(targets[0].GetTarget(), targets[0].GetMode())
+	}
+
+	nodes := make(map[string]bool)
+	for _, t := range targets {
+		matched, err := d.targetedNodes(t.GetTarget(), t.GetMode())
+		if errors.Is(err, ErrNoMatchingNode) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("target '%s': %w", t.GetTarget(), err)
+		}
+		maps.Copy(nodes, matched)
+	}
+
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%w with the targets", ErrNoMatchingNode)
+	}
+
+	return nodes, nil
+}
+
+func (d *Dispatcher[R, A]) targetedNodes(target string, mode proto.TargetMode) (map[string]bool, error) {
 	switch mode {
 	case proto.TargetMode_EXACT:
 		return map[string]bool{target: d.isReady(node.ID(target))}, nil
@@ -155,10 +208,18 @@ func (d *Dispatcher[R, A]) TargetedNodes(target string, mode proto.TargetMode) (
 		return d.listMatching(target)
 
 	case proto.TargetMode_GLOB:
-		return d.globMatching(target)
+		value, pattern, err := d.fieldPattern(target)
+		if err != nil {
+			return nil, err
+		}
+		return d.globMatching(pattern, value)
 
 	case proto.TargetMode_REGEX:
-		return d.regexMatching(target)
+		value, pattern, err := d.fieldPattern(target)
+		if err != nil {
+			return nil, err
+		}
+		return d.regexMatching(pattern, value)
 
 	case proto.TargetMode_QUERY:
 		return d.queryMatching(target)
@@ -176,16 +237,58 @@ func (d *Dispatcher[R, A]) listMatching(list string) (map[string]bool, error) {
 	}
 
 	if len(nodes) == 0 {
-		return nil, errors.New("no connected node is matching with the list")
+		return nil, fmt.Errorf("%w with the list", ErrNoMatchingNode)
 	}
 
 	return nodes, nil
 }
 
-func (d *Dispatcher[R, A]) globMatching(pattern string) (map[string]bool, error) {
+// nodeValue returns the value of a node matched by the pattern of a glob or regex target, false if the node
+// has no such value.
+type nodeValue func(id node.ID) (string, bool)
+
+func nodeID(id node.ID) (string, bool) {
+	return string(id), true
+}
+
+// specValue returns the nodeValue of the spec at path, e.g. os.family.
+func (d *Dispatcher[R, A]) specValue(path string) nodeValue {
+	specs := d.nodesInventory.GetAllSpecs()
+	return func(id node.ID) (string, bool) {
+		return specLeaf(specs[id], path)
+	}
+}
+
+// fieldPattern splits a glob or regex target into the value of the nodes it matches and its pattern:
+//   - "web-*" or "id:web-*" matches the ID of the nodes
+//   - "specs:os.family:debian*" matches the spec os.family
+func (d *Dispatcher[R, A]) fieldPattern(target string) (nodeValue, string, error) {
+	field, pattern, found := strings.Cut(target, config.FieldSeparator)
+	switch {
+	case !found:
+		return nodeID, target, nil
+	case field == "id":
+		return nodeID, pattern, nil
+	case field == config.SpecManagerPrefix:
+		path, pattern, found := strings.Cut(pattern, config.FieldSeparator)
+		if !found || path == "" {
+			return nil, "", fmt.Errorf("invalid target %q: specs:<path>:<pattern> expected", target)
+		}
+		return d.specValue(path), pattern, nil
+	default:
+		// not a field: the ID of a node can contain the separator
+		return nodeID, target, nil
+	}
+}
+
+func (d *Dispatcher[R, A]) globMatching(pattern string, value nodeValue) (map[string]bool, error) {
 	nodes := make(map[string]bool)
 	for id, ready := range d.dispatchableNodes {
-		matched, err := filepath.Match(pattern, string(id))
+		v, ok := value(id)
+		if !ok {
+			continue
+		}
+		matched, err := filepath.Match(pattern, v)
 		if err != nil {
 			return nil, err
 		}
@@ -196,149 +299,141 @@ func (d *Dispatcher[R, A]) globMatching(pattern string) (map[string]bool, error)
 	}
 
 	if len(nodes) == 0 {
-		return nil, errors.New("no connected node is matching with the pattern")
+		return nil, fmt.Errorf("%w with the pattern", ErrNoMatchingNode)
 	}
 
 	return nodes, nil
 }
 
-func (d *Dispatcher[R, A]) regexMatching(pattern string) (map[string]bool, error) {
-	regex, err := regexp.Compile(fmt.Sprintf("^%s$", pattern))
+func (d *Dispatcher[R, A]) regexMatching(pattern string, value nodeValue) (map[string]bool, error) {
+	regex, err := d.regexes.get(fmt.Sprintf("^%s$", pattern), regexp.Compile)
 	if err != nil {
 		return nil, err
 	}
 
 	nodes := map[string]bool{}
 	for id, ready := range d.dispatchableNodes {
-		if matched := regex.MatchString(string(id)); matched {
+		v, ok := value(id)
+		if !ok {
+			continue
+		}
+		if matched := regex.MatchString(v); matched {
 			nodes[string(id)] = ready
 		}
 	}
 
 	if len(nodes) == 0 {
-		return nil, errors.New("no connected node is matching with the pattern")
+		return nil, fmt.Errorf("%w with the pattern", ErrNoMatchingNode)
 	}
 
 	return nodes, nil
 }
 
 // queryMatching evaluates a filter expression and returns matching nodes.
+//
+// The parsed expressions are cached, the repeated targets being only parsed once.
 func (d *Dispatcher[R, A]) queryMatching(expr string) (map[string]bool, error) {
-	if strings.TrimSpace(expr) == "" {
-		return nil, errors.New("empty filter expression")
+	q, err := d.queries.get(expr, parseQuery)
+	if err != nil {
+		return nil, err
 	}
 
-	result := make(map[string]bool)
-
-	for orGroup := range strings.SplitSeq(expr, " or ") {
-		orGroup = strings.TrimSpace(orGroup)
-		if orGroup == "" {
-			continue
-		}
-
-		andResult, err := d.evaluateAndGroup(orGroup)
-		if err != nil {
-			return nil, fmt.Errorf("OR group %q: %w", orGroup, err)
-		}
-
-		// Merge results (OR logic - add all matches)
-		maps.Copy(result, andResult)
+	result, err := d.evaluateQuery(q)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(result) == 0 {
-		return nil, errors.New("no connected node matches the filter")
+		return nil, fmt.Errorf("%w the filter", ErrNoMatchingNode)
 	}
 
 	return result, nil
 }
 
-// evaluateAndGroup processes AND conditions within a group.
-func (d *Dispatcher[R, A]) evaluateAndGroup(andGroup string) (map[string]bool, error) {
-	conditions := strings.Split(andGroup, " and ")
-	candidates := make(map[string]bool, len(d.dispatchableNodes))
-	for k, v := range d.dispatchableNodes {
-		candidates[string(k)] = v
-	}
-
-	// Apply each condition (AND logic)
-	for _, condition := range conditions {
-		condition = strings.TrimSpace(condition)
-		if condition == "" {
-			continue
-		}
-
-		matched, err := d.evaluateCondition(condition)
-		if err != nil {
-			return nil, fmt.Errorf("condition %q: %w", condition, err)
+// evaluateQuery returns the dispatchable nodes matching the query.
+func (d *Dispatcher[R, A]) evaluateQuery(q query) (map[string]bool, error) {
+	switch q := q.(type) {
+	case orQuery:
+		result := make(map[string]bool)
+		for _, operand := range q {
+			matched, err := d.evaluateQuery(operand)
+			if err != nil {
+				return nil, err
+			}
+			maps.Copy(result, matched)
 		}
-
-		for id := range candidates {
-			if _, ok := matched[id]; !ok {
-				delete(candidates, id)
+		return result, nil
+
+	case andQuery:
+		var result map[string]bool
+		for _, operand := range q {
+			matched, err := d.evaluateQuery(operand)
+			if err != nil {
+				return nil, err
+			}
+			if result == nil {
+				result = matched
+				continue
+			}
+			for id := range result {
+				if _, ok := matched[id]; !ok {
+					delete(result, id)
+				}
 			}
 		}
-	}
-
-	return candidates, nil
-}
+		return result, nil
 
-// evaluateCondition evaluates a single condition like "id==foo" or "specs.os==linux".
-func (d *Dispatcher[R, A]) evaluateCondition(condition string) (map[string]bool, error) {
-	var field, operator, value string
-	var err error
-	var matched map[string]bool
-
-	switch {
-	case strings.Contains(condition, "=="):
-		parts := strings.SplitN(condition, "==", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid == condition: %q", condition)
-		}
-		field, operator, value = strings.TrimSpace(parts[0]), "==", strings.TrimSpace(parts[1])
-	case strings.Contains(condition, "=~"):
-		parts := strings.SplitN(condition, "=~", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid =~ condition: %q", condition)
+	case condition:
+		matched, err := d.evaluateCondition(q)
+		if errors.Is(err, ErrNoMatchingNode) {
+			return map[string]bool{}, nil
 		}
-		field, operator, value = strings.TrimSpace(parts[0]), "=~", strings.TrimSpace(parts[1])
-	default:
-		return nil, fmt.Errorf("unsupported operator in condition: %q", condition)
-	}
-
-	switch {
-	case field == "id":
-		matched, err = d.evaluateIDCondition(operator, value)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("condition %q: %w", q, err)
 		}
-	case strings.HasPrefix(field, "specs."):
-		matched, err = d.evaluateSpecsCondition(field, operator, value)
-		if err != nil {
-			return nil, err
+		// the specs of the inventory include the nodes never connected
+		result := make(map[string]bool, len(matched))
+		for id := range matched {
+			if ready, ok := d.dispatchableNodes[node.ID(id)]; ok {
+				result[id] = ready
+			}
 		}
-	default:
-		return nil, fmt.Errorf("unsupported field: %q", field)
+		return result, nil
 	}
+	return nil, fmt.Errorf("unsupported query: %T", q)
+}
 
-	return matched, nil
+// evaluateCondition returns the nodes matching a condition.
+func (d *Dispatcher[R, A]) evaluateCondition(cond condition) (map[string]bool, error) {
+	if cond.field == "id" {
+		return d.evaluateIDCondition(cond.operator, cond.value, cond.values)
+	}
+	return d.evaluateSpecsCondition(cond.field, cond.operator, cond.value, cond.values)
 }
 
 // evaluateIDCondition handles ID-specific matching.
-func (d *Dispatcher[R, A]) evaluateIDCondition(operator, value string) (map[string]bool, error) {
+func (d *Dispatcher[R, A]) evaluateIDCondition(operator, value string, values []string) (map[string]bool, error) {
 	switch operator {
 	case "==":
 		// Handle list (comma-separated) or single exact match
 		return d.listMatching(value)
 
+	case "in":
+		nodes := make(map[string]bool, len(values))
+		for _, id := range values {
+			nodes[id] = d.isReady(node.ID(id))
+		}
+		return nodes, nil
+
 	case "=~":
 		// Check if it's regex (enclosed in slashes) or glob
 		if strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") {
 			// Regex pattern
 			pattern := value[1 : len(value)-1]
-			return d.regexMatching(pattern)
+			return d.regexMatching(pattern, nodeID)
 		} else {
 			// Glob pattern
-			return d.globMatching(value)
+			return d.globMatching(value, nodeID)
 		}
 
 	default:
@@ -347,43 +442,34 @@ func (d *Dispatcher[R, A]) evaluateIDCondition(operator, value string) (map[stri
 }
 
 // evaluateSpecsCondition handles specs field matching.
-func (d *Dispatcher[R, A]) evaluateSpecsCondition(field, operator, value string) (map[string]bool, error) {
+func (d *Dispatcher[R, A]) evaluateSpecsCondition(field, operator, value string, values []string) (map[string]bool, error) {
 	matched := make(map[string]bool)
 
 	// Extract specs path (remove "specs." prefix)
 	specPath := strings.TrimPrefix(field, "specs.")
 	for nd, ndSpecs := range d.nodesInventory.GetAllSpecs() {
-		a, err := dotaccess.NewAccessorDot[any, map[string]any](&ndSpecs, specPath)
-		if err != nil {
+		spec, ok := specLeaf(ndSpecs, specPath)
+		if !ok {
 			continue
 		}
 
-		specAny := a.Get()
-		if reflect.ValueOf(specAny).Kind() == reflect.Pointer {
-			specAny = reflect.ValueOf(specAny).Elem()
-		}
-
-		// we only want to compare specs value, so we want only valid leaf.
-		switch reflect.ValueOf(specAny).Kind() { //nolint:exhaustive  // we do not support all types
-		case reflect.Array, reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
-			reflect.Pointer, reflect.Slice, reflect.Struct, reflect.UnsafePointer:
-			slog.Debug("invalid spec", "error", "not a valid leaf", "type", reflect.ValueOf(specAny).Kind())
-			continue
-		}
-
-		spec := fmt.Sprint(a.Get())
 		switch operator {
 		case "==":
 			if spec == value {
 				matched[string(nd)] = d.isReady(nd)
 			}
 
+		case "in":
+			if slices.Contains(values, spec) {
+				matched[string(nd)] = d.isReady(nd)
+			}
+
 		case "=~":
 			// Check if it's regex (enclosed in slashes) or glob
 			if strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") {
 				// Regex pattern
 				pattern := value[1 : len(value)-1]
-				regex, err := regexp.Compile(pattern)
+				regex, err := d.regexes.get(pattern, regexp.Compile)
 				if err != nil {
 					return nil, fmt.Errorf("invalid regex pattern %q: %w", pattern, err)
 				}
@@ -409,3 +495,26 @@ func (d *Dispatcher[R, A]) evaluateSpecsCondition(field, operator, value string)
 
 	return matched, nil
 }
+
+// specLeaf returns the value of the spec at path, false if the spec is missing or not a leaf.
+func specLeaf(specs map[string]any, path string) (string, bool) {
+	a, err := dotaccess.NewAccessorDot[any, map[string]any](&specs, path)
+	if err != nil {
+		return "", false
+	}
+
+	specAny := a.Get()
+	if reflect.ValueOf(specAny).Kind() == reflect.Pointer {
+		specAny = reflect.ValueOf(specAny).Elem()
+	}
+
+	// we only want to compare specs value, so we want only valid leaf.
+	switch reflect.ValueOf(specAny).Kind() { //nolint:exhaustive  // we do not support all types
+	case reflect.Array, reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+		reflect.Pointer, reflect.Slice, reflect.Struct, reflect.UnsafePointer:
+		slog.Debug("invalid spec", "error", "not a valid leaf", "type", reflect.ValueOf(specAny).Kind())
+		return "", false
+	}
+
+	return fmt.Sprint(a.Get()), true
+}
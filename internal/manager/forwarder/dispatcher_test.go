@@ -147,6 +147,87 @@ func TestTargetedNodesRegex(t *testing.T) {
 	}
 }
 
+func TestTargetedNodesFieldPattern(t *testing.T) {
+	inv := inventory.New()
+	inv.DisableRegistryFile()
+	dispatcher := NewDispatcher[string, string](&inv)
+
+	nodes := []node.ID{node.ID("web-1"), node.ID("web-2"), node.ID("db-1"), node.ID("cache:1")}
+	for _, nodeID := range nodes {
+		_ = dispatcher.RegisterNode(nodeID)
+		inv.MarkNodeStateChange(nodeID, true)
+	}
+	_ = inv.SetSpec(node.ID("web-1"), map[string]any{"labels": map[string]any{"env": "prod-eu"}, "os": map[string]any{"family": "debian"}})
+	_ = inv.SetSpec(node.ID("web-2"), map[string]any{"labels": map[string]any{"env": "staging"}, "os": map[string]any{"family": "debian"}})
+	_ = inv.SetSpec(node.ID("db-1"), map[string]any{"labels": map[string]any{"env": "prod-us"}, "os": map[string]any{"family": "redhat"}})
+
+	tests := []struct {
+		name        string
+		target      string
+		mode        proto.TargetMode
+		expected    map[string]bool
+		expectError bool
+	}{
+		{
+			name:     "glob on id field",
+			target:   "id:web-*",
+			mode:     proto.TargetMode_GLOB,
+			expected: map[string]bool{"web-1": true, "web-2": true},
+		},
+		{
+			name:     "glob on spec",
+			target:   "specs:labels.env:prod-*",
+			mode:     proto.TargetMode_GLOB,
+			expected: map[string]bool{"web-1": true, "db-1": true},
+		},
+		{
+			name:     "regex on spec",
+			target:   "specs:os.family:deb.*",
+			mode:     proto.TargetMode_REGEX,
+			expected: map[string]bool{"web-1": true, "web-2": true},
+		},
+		{
+			name:        "spec not being a leaf",
+			target:      "specs:labels:*",
+			mode:        proto.TargetMode_GLOB,
+			expectError: true,
+		},
+		{
+			name:        "spec without pattern",
+			target:      "specs:labels.env",
+			mode:        proto.TargetMode_GLOB,
+			expectError: true,
+		},
+		{
+			name:     "ID with the separator",
+			target:   "cache:*",
+			mode:     proto.TargetMode_GLOB,
+			expected: map[string]bool{"cache:1": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := dispatcher.TargetedNodes(tt.target, tt.mode)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for target %q, got %v", tt.target, result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(result, tt.expected); diff != "" {
+				t.Errorf("Mismatch for target %q (-got +want):\n%s", tt.target, diff)
+			}
+		})
+	}
+}
+
 func TestTargetedNodesQuery(t *testing.T) {
 	inv := inventory.New()
 	inv.DisableRegistryFile()
@@ -322,6 +403,17 @@ func TestDispatcherLifecycle(t *testing.T) {
 		}
 	})
 
+	t.Run("send stopped when channel is not read", func(t *testing.T) {
+		d := NewDispatcher[string, string](inv)
+		_ = d.RegisterNode(nodeID)
+
+		stop := make(chan struct{})
+		close(stop)
+		if err := d.SendUntil(nodeID, Task[string, string]{}, time.Minute, stop); !errors.Is(err, ErrStopped) {
+			t.Errorf("expected ErrStopped, got %v", err)
+		}
+	})
+
 	t.Run("send succeeds when channel is read", func(t *testing.T) {
 		d := NewDispatcher[string, string](inv)
 		_ = d.RegisterNode(nodeID)
@@ -413,3 +505,104 @@ func TestTargetedNodesUnknownMode(t *testing.T) {
 		t.Error("Expected error for unknown target mode")
 	}
 }
+
+func TestTargetedNodesUnion(t *testing.T) {
+	inv := inventory.New()
+	inv.DisableRegistryFile()
+	dispatcher := NewDispatcher[string, string](&inv)
+
+	nodes := []node.ID{node.ID("web-1"), node.ID("web-2"), node.ID("db-1"), node.ID("db-2"), node.ID("cache-1")}
+	for _, nodeID := range nodes {
+		_ = dispatcher.RegisterNode(nodeID)
+		inv.MarkNodeStateChange(nodeID, true)
+	}
+	_ = inv.SetSpec(node.ID("web-2"), map[string]any{"env": "prod"})
+	_ = inv.SetSpec(node.ID("db-1"), map[string]any{"env": "prod"})
+	_ = inv.SetSpec(node.ID("cache-1"), map[string]any{"env": "dev"})
+
+	tests := []struct {
+		name        string
+		targets     []*proto.Target
+		expected    map[string]bool
+		expectError bool
+	}{
+		{
+			name: "exact, glob and query with overlap",
+			targets: []*proto.Target{
+				{Target: "web-1", Mode: proto.TargetMode_EXACT},
+				{Target: "db-*", Mode: proto.TargetMode_GLOB},
+				{Target: "specs.env==prod", Mode: proto.TargetMode_QUERY},
+			},
+			expected: map[string]bool{"web-1": true, "web-2": true, "db-1": true, "db-2": true},
+		},
+		{
+			name: "same node several times",
+			targets: []*proto.Target{
+				{Target: "web-1", Mode: proto.TargetMode_EXACT},
+				{Target: "web-1,web-2", Mode: proto.TargetMode_LIST},
+				{Target: "web-.*", Mode: proto.TargetMode_REGEX},
+			},
+			expected: map[string]bool{"web-1": true, "web-2": true},
+		},
+		{
+			name: "target without match",
+			targets: []*proto.Target{
+				{Target: "app-*", Mode: proto.TargetMode_GLOB},
+				{Target: "cache-1", Mode: proto.TargetMode_EXACT},
+			},
+			expected: map[string]bool{"cache-1": true},
+		},
+		{
+			name: "no match",
+			targets: []*proto.Target{
+				{Target: "app-*", Mode: proto.TargetMode_GLOB},
+				{Target: "specs.env==test", Mode: proto.TargetMode_QUERY},
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid target",
+			targets: []*proto.Target{
+				{Target: "web-1", Mode: proto.TargetMode_EXACT},
+				{Target: "[", Mode: proto.TargetMode_REGEX},
+			},
+			expectError: true,
+		},
+		{
+			name:        "no target",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := dispatcher.TargetedNodesUnion(tt.targets)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(result, tt.expected); diff != "" {
+				t.Errorf("Mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTargetedNodesUnionSingleTarget(t *testing.T) {
+	inv := &inventory.Nodes{}
+	dispatcher := NewDispatcher[string, string](inv)
+	_ = dispatcher.RegisterNode(node.ID("node1"))
+
+	_, err := dispatcher.TargetedNodesUnion([]*proto.Target{{Target: "web-*", Mode: proto.TargetMode_GLOB}})
+	if !errors.Is(err, ErrNoMatchingNode) {
+		t.Errorf("Expected ErrNoMatchingNode, got %v", err)
+	}
+	if err.Error() != "no connected node is matching with the pattern" {
+		t.Errorf("A single target must keep its error message, got %q", err)
+	}
+}
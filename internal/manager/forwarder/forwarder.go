@@ -3,15 +3,23 @@ package forwarder
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/clock"
 	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/logs"
 	"github.com/jackadi-io/jackadi/internal/manager/database"
+	"github.com/jackadi-io/jackadi/internal/manager/notification"
 	"github.com/jackadi-io/jackadi/internal/node"
 	"github.com/jackadi-io/jackadi/internal/proto"
+	"google.golang.org/grpc/metadata"
+	protobuf "google.golang.org/protobuf/proto"
 )
 
 // GRPCForwarder simply forwards tasks received from one component to another component.
@@ -20,18 +28,51 @@ import (
 type GRPCForwarder struct {
 	proto.UnimplementedForwarderServer
 	taskDispatcher Dispatcher[*proto.TaskRequest, *proto.TaskResponse]
-	db             *badger.DB
+	db             database.ResultStore
+	notifier       *notification.Dispatcher
+	locks          *LockTracker
+	groupIDs       *database.Sequence
+	responseGrace  time.Duration // Wait after the timeout of a task for the response of a node.
+	clock          clock.Clock   // Time of the scheduled requests.
 }
 
-func New(taskDispatcher Dispatcher[*proto.TaskRequest, *proto.TaskResponse], db *badger.DB) GRPCForwarder {
+func New(taskDispatcher Dispatcher[*proto.TaskRequest, *proto.TaskResponse], db database.ResultStore) GRPCForwarder {
 	return GRPCForwarder{
 		taskDispatcher: taskDispatcher,
 		db:             db,
+		groupIDs:       &database.Sequence{},
+		responseGrace:  config.NodeResponseGrace,
+		clock:          clock.Real{},
 	}
 }
 
-func (f *GRPCForwarder) storeRequest(req *proto.TaskRequest, targetsStatus map[string]bool) {
-	dbReq := database.Request{Task: req.GetTask()}
+// SetResponseGrace sets the wait after the timeout of a task for the response of a node, before it is reported
+// unresponsive (NODE_UNRESPONSIVE).
+func (f *GRPCForwarder) SetResponseGrace(grace time.Duration) {
+	f.responseGrace = grace
+}
+
+// SetNotifier enables the notifications of completed runs.
+func (f *GRPCForwarder) SetNotifier(notifier *notification.Dispatcher) {
+	f.notifier = notifier
+}
+
+// SetIDSequence sets the sequence generating the group IDs.
+//
+// The group IDs and the result IDs are stored in the same key space: the sequence must be the one of the server,
+// so that a group ID never equals a result ID.
+func (f *GRPCForwarder) SetIDSequence(ids *database.Sequence) {
+	f.groupIDs = ids
+}
+
+// SetLockTracker enables the detection of the runs with conflicting lock modes, and the lock contention stats.
+func (f *GRPCForwarder) SetLockTracker(locks *LockTracker) {
+	f.locks = locks
+}
+
+func (f *GRPCForwarder) storeRequest(ctx context.Context, req *proto.TaskRequest, targetsStatus map[string]bool) {
+	logger := logs.FromContext(ctx)
+	dbReq := database.Request{Task: req.FullTask(), Metadata: req.GetMetadata()}
 	for target, connected := range targetsStatus {
 		if connected {
 			dbReq.ConnectedTarget = append(dbReq.ConnectedTarget, target)
@@ -42,7 +83,7 @@ func (f *GRPCForwarder) storeRequest(req *proto.TaskRequest, targetsStatus map[s
 
 	data, err := database.MarshalRequest(&dbReq)
 	if err != nil {
-		slog.Error("unable to record result", "error", "marshal error")
+		logger.Error("unable to record result", "error", "marshal error")
 		return
 	}
 
@@ -56,14 +97,14 @@ func (f *GRPCForwarder) storeRequest(req *proto.TaskRequest, targetsStatus map[s
 
 		singleEntry := badger.NewEntry(key, data).WithTTL(config.DBTaskRequestTTL)
 		if err := txn.SetEntry(singleEntry); err != nil {
-			slog.Error("unable to record result", "error", err)
+			logger.Error("unable to record result", "error", err)
 			return err
 		}
 
 		return nil
 	})
 	if dbDerr != nil {
-		slog.Warn("failed to store task request", "error", dbDerr)
+		logger.Warn("failed to store task request", "error", dbDerr)
 	}
 }
 
@@ -71,7 +112,89 @@ func (f *GRPCForwarder) storeRequest(req *proto.TaskRequest, targetsStatus map[s
 //
 // The manager's stream is linked to a single node.
 func (f *GRPCForwarder) ExecTask(ctx context.Context, req *proto.TaskRequest) (*proto.FwdResponse, error) {
-	targetsStatus, err := f.taskDispatcher.TargetedNodes(req.GetTarget(), req.GetTargetMode())
+	return f.exec(ctx, req, nil)
+}
+
+// StreamTask is like ExecTask, but it streams the progress updates of the tasks, and the response
+// of each node as soon as it is received.
+//
+// The dispatch status of the targeted nodes, and the node which stopped a rolling run, are sent in the trailer.
+func (f *GRPCForwarder) StreamTask(req *proto.TaskRequest, stream proto.Forwarder_StreamTaskServer) error {
+	// the caller waits for the responses as long as the manager: the timeout of the task and the response grace
+	if err := stream.SendHeader(metadata.Pairs(config.GraceHeaderKey, f.responseGrace.String())); err != nil {
+		slog.Debug("failed to send the response grace", "error", err)
+	}
+
+	// responses are reported concurrently, but a stream does not support concurrent sends
+	lock := sync.Mutex{}
+	out, err := f.exec(stream.Context(), req, func(nd string, resp *proto.TaskResponse) {
+		lock.Lock()
+		defer lock.Unlock()
+		if err := stream.Send(&proto.FwdStreamResponse{Node: nd, Response: resp}); err != nil {
+			slog.Debug("failed to stream response", "node", nd, "error", err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	trailer, err := protobuf.Marshal(&proto.FwdResponse{Targets: out.GetTargets(), StoppedAt: out.GetStoppedAt()})
+	if err != nil {
+		slog.Warn("failed to serialize the dispatch status of the targets", "error", err)
+		return nil
+	}
+	stream.SetTrailer(metadata.Pairs(config.TargetsTrailerKey, string(trailer)))
+	return nil
+}
+
+// ResolveTargets returns the nodes targeted by the request and whether they are connected, without sending it.
+func (f *GRPCForwarder) ResolveTargets(ctx context.Context, req *proto.TaskRequest) (*proto.ResolveTargetsResponse, error) {
+	nodes, err := f.targetedNodes(req)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.ResolveTargetsResponse{Nodes: nodes}, nil
+}
+
+// targetedNodes returns the nodes targeted by the request, without the excluded ones.
+func (f *GRPCForwarder) targetedNodes(req *proto.TaskRequest) (map[string]bool, error) {
+	nodes, err := f.taskDispatcher.TargetedNodesUnion(req.AllTargets())
+	if err != nil || len(req.GetExcludes()) == 0 {
+		return nodes, err
+	}
+
+	excluded, err := f.taskDispatcher.TargetedNodesUnion(req.GetExcludes())
+	switch {
+	case errors.Is(err, ErrNoMatchingNode):
+		return nodes, nil
+	case err != nil:
+		return nil, fmt.Errorf("exclusion: %w", err)
+	}
+
+	for nd := range excluded {
+		delete(nodes, nd)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%w: all the targeted nodes are excluded", ErrNoMatchingNode)
+	}
+	return nodes, nil
+}
+
+// exec sends the request to the targeted nodes and returns their responses, and the dispatch status of the
+// targeted nodes.
+//
+// If report is set, it is called with each progress update and each response as soon as they are received.
+//
+// The nodes of a rolling request run the task one at a time, and the remaining ones are skipped (SKIPPED) once
+// the failures exceed the tolerated ones, none if it is also fail-fast. The nodes of a fail-fast request run the
+// task concurrently, and the first failure stops the run: the nodes not dispatched yet are skipped, and the ones
+// still running are cancelled (CANCELLED), the manager not waiting for them anymore.
+func (f *GRPCForwarder) exec(ctx context.Context, req *proto.TaskRequest, report func(node string, resp *proto.TaskResponse)) (*proto.FwdResponse, error) {
+	if report == nil {
+		report = func(string, *proto.TaskResponse) {}
+	}
+
+	targetsStatus, err := f.targetedNodes(req)
 	if err != nil {
 		return nil, err
 	}
@@ -80,68 +203,240 @@ func (f *GRPCForwarder) ExecTask(ctx context.Context, req *proto.TaskRequest) (*
 	// from the same node for a same request. Better safe than sorry.
 	lock := sync.Mutex{}
 	results := make(map[string]*proto.TaskResponse, len(targetsStatus))
+	dispatched := make(map[string]proto.DispatchStatus, len(targetsStatus))
 
 	// the group ID enables to get all responses when the request is targeting multiple nodes
-	groupID := time.Now().UnixNano()
+	groupID := f.groupIDs.Next(f.clock.Now())
 	req.GroupID = &groupID
+	ctx = logs.With(ctx, "group_id", groupID)
+	logger := logs.FromContext(ctx)
+	logger.Debug("dispatching request", "task", req.FullTask(), "target", req.TargetsString(), "nodes", len(targetsStatus))
+
+	if !req.GetTransient() {
+		f.storeRequest(ctx, req, targetsStatus)
+	}
+
+	var connected []string
+	for nd, ok := range targetsStatus {
+		if ok {
+			connected = append(connected, nd)
+		}
+	}
+	lockMode := f.locks.lockMode(req)
+	if conflicts := f.locks.start(connected, lockMode); len(conflicts) > 0 {
+		logger.Warn("the lock mode of the run conflicts with tasks in flight, the nodes will serialize them",
+			"task", req.FullTask(), "lock_mode", lockMode.String(), "nodes", conflicts)
+	}
 
-	f.storeRequest(req, targetsStatus)
-	wg := sync.WaitGroup{}
 	for nd, connected := range targetsStatus {
-		if !connected {
-			slog.Debug("targeted node disconnected", "node", nd)
-			lock.Lock()
-			results[nd] = &proto.TaskResponse{
+		if connected {
+			continue
+		}
+		logger.Debug("targeted node disconnected", "node", nd)
+		r := &proto.TaskResponse{
+			GroupID:       req.GroupID,
+			InternalError: proto.InternalError_DISCONNECTED,
+		}
+		results[nd] = r
+		dispatched[nd] = proto.DispatchStatus_DISPATCH_DISCONNECTED
+		report(nd, r)
+	}
+
+	// skip reports a connected node as not sent, the run being stopped before it.
+	skip := func(nd string) {
+		f.locks.done(nd, req.FullTask(), lockMode, nil)
+		r := &proto.TaskResponse{
+			GroupID:       req.GroupID,
+			InternalError: proto.InternalError_SKIPPED,
+		}
+		lock.Lock()
+		results[nd] = r
+		dispatched[nd] = proto.DispatchStatus_DISPATCH_SKIPPED
+		lock.Unlock()
+		report(nd, r)
+	}
+
+	// dispatch sends the request to a connected node and waits for its response, it returns whether the node
+	// (or all the nodes of a syndic) succeeded. Once stop is closed, the node is skipped if not dispatched yet,
+	// or cancelled if still running.
+	dispatch := func(nd string, stop <-chan struct{}) bool {
+		select {
+		case <-stop:
+			skip(nd)
+			return false
+		default:
+		}
+
+		resp := make(chan *proto.TaskResponse, 1)
+		task := Task[*proto.TaskRequest, *proto.TaskResponse]{
+			Request:    req,
+			ResponseCh: resp,
+		}
+		timeout := TaskTimeout(req)
+		if err := f.taskDispatcher.SendUntil(node.ID(nd), task, timeout, stop); err != nil {
+			if errors.Is(err, ErrStopped) {
+				skip(nd)
+				return false
+			}
+			internalError := proto.InternalError_UNKNOWN_ERROR
+			switch {
+			case errors.Is(err, ErrNodeNotFound):
+				internalError = proto.InternalError_DISCONNECTING
+			case errors.Is(err, ErrClosedTaskChannel):
+				internalError = proto.InternalError_DISCONNECTING
+			case errors.Is(err, ErrTimeout):
+				internalError = proto.InternalError_TIMEOUT
+			}
+
+			logger.Debug("task not dispatched", "node", nd, "error", err)
+			f.locks.done(nd, req.FullTask(), lockMode, nil)
+			r := &proto.TaskResponse{
 				GroupID:       req.GroupID,
-				InternalError: proto.InternalError_DISCONNECTED,
+				InternalError: internalError,
 			}
+			lock.Lock()
+			results[nd] = r
+			dispatched[nd] = proto.DispatchStatus_DISPATCH_REJECTED
 			lock.Unlock()
-			continue
+			report(nd, r)
+			return false
 		}
 
-		wg.Go(func() {
-			resp := make(chan *proto.TaskResponse, 1)
-			task := Task[*proto.TaskRequest, *proto.TaskResponse]{
-				Request:    req,
-				ResponseCh: resp,
-			}
-			timeout := time.Duration(req.GetTimeout()) * time.Second
-			if err := f.taskDispatcher.Send(node.ID(nd), task, timeout); err != nil {
-				internalError := proto.InternalError_UNKNOWN_ERROR
-				switch {
-				case errors.Is(err, ErrNodeNotFound):
-					internalError = proto.InternalError_DISCONNECTING
-				case errors.Is(err, ErrClosedTaskChannel):
-					internalError = proto.InternalError_DISCONNECTING
-				case errors.Is(err, ErrTimeout):
-					internalError = proto.InternalError_TIMEOUT
+		// the node answers by itself once the timeout is reached, even if the task is still running: without
+		// response after the grace, the node is wedged and the requester must not wait for it.
+		var r *proto.TaskResponse
+		deadline := time.After(timeout + f.responseGrace)
+		for r == nil {
+			select {
+			case r = <-resp:
+				if r.Progress != nil {
+					report(nd, r)
+					r = nil
 				}
-
-				lock.Lock()
-				defer lock.Unlock()
-				results[nd] = &proto.TaskResponse{
+			case <-deadline:
+				logger.Warn("node unresponsive: no response after the timeout and the grace", "node", nd, "task", req.FullTask(), "timeout", timeout, "grace", f.responseGrace)
+				r = &proto.TaskResponse{
 					GroupID:       req.GroupID,
-					InternalError: internalError,
+					InternalError: proto.InternalError_NODE_UNRESPONSIVE,
+				}
+			case <-stop:
+				logger.Debug("task cancelled", "node", nd)
+				r = &proto.TaskResponse{
+					GroupID:       req.GroupID,
+					InternalError: proto.InternalError_CANCELLED,
 				}
-				return
 			}
+		}
+		f.locks.done(nd, req.FullTask(), lockMode, r)
+		flat := flattenDownstream(nd, r)
+		lock.Lock()
+		maps.Copy(results, flat)
+		dispatched[nd] = proto.DispatchStatus_DISPATCH_SENT
+		if r.GetInternalError() == proto.InternalError_FULL_QUEUE {
+			dispatched[nd] = proto.DispatchStatus_DISPATCH_REJECTED
+		}
+		lock.Unlock()
+		succeeded := true
+		for id, r := range flat {
+			report(id, r)
+			succeeded = succeeded && database.ResultStatus(r) == "success"
+		}
+		return succeeded
+	}
 
-			select {
-			case r := <-resp:
+	resp := &proto.FwdResponse{Responses: results, Targets: dispatched}
+	if !req.GetRolling() {
+		// the first failure of a fail-fast run cancels the context shared by the nodes, a normal run never
+		// stops (nil channel).
+		var stop <-chan struct{}
+		cancel := func(string) {}
+		if req.GetFailFast() {
+			failCtx, cancelFail := context.WithCancel(ctx)
+			defer cancelFail()
+			stop = failCtx.Done()
+			cancel = func(nd string) {
 				lock.Lock()
 				defer lock.Unlock()
-				results[nd] = r
-			case <-time.After(timeout):
-				lock.Lock()
-				defer lock.Unlock()
-				results[nd] = &proto.TaskResponse{
-					GroupID:       req.GroupID,
-					InternalError: proto.InternalError_TIMEOUT,
+				// the nodes skipped or cancelled once stopped are not the cause of the stop
+				if failCtx.Err() != nil {
+					return
 				}
+				logger.Info("fail-fast run stopped", "task", req.FullTask(), "node", nd)
+				resp.StoppedAt = nd
+				cancelFail()
 			}
-		})
+		}
+
+		wg := sync.WaitGroup{}
+		for _, nd := range connected {
+			wg.Go(func() {
+				if !dispatch(nd, stop) {
+					cancel(nd)
+				}
+			})
+		}
+		wg.Wait()
+	} else {
+		// one node at a time, in the order of their IDs: the failures on the first nodes (e.g. canaries) stop
+		// the run before the others get the task. The run also stops if the requester is gone.
+		maxFailures := int(req.GetMaxFailures())
+		if req.GetFailFast() {
+			maxFailures = 0
+		}
+		failures := 0
+		slices.Sort(connected)
+		for i, nd := range connected {
+			if !dispatch(nd, nil) {
+				failures++
+			}
+			logger.Debug("rolling run progress", "node", nd, "done", i+1, "nodes", len(connected), "failures", failures)
+			skipped := connected[i+1:]
+			if (failures <= maxFailures && ctx.Err() == nil) || len(skipped) == 0 {
+				continue
+			}
+
+			logger.Info("rolling run stopped", "task", req.FullTask(), "node", nd, "failures", failures, "skipped", len(skipped))
+			resp.StoppedAt = nd
+			for _, nd := range skipped {
+				skip(nd)
+			}
+			break
+		}
 	}
-	wg.Wait()
 
-	return &proto.FwdResponse{Responses: results}, nil
+	if !req.GetTransient() {
+		f.notifier.NotifyRun(notification.Run{Task: req.FullTask(), Responses: results})
+	}
+
+	return resp, nil
+}
+
+// TaskTimeout returns the timeout of the request, the default of the nodes if not set.
+func TaskTimeout(req *proto.TaskRequest) time.Duration {
+	if req.GetTimeout() == 0 {
+		return config.TaskTimeout
+	}
+	return time.Duration(req.GetTimeout()) * time.Second
+}
+
+// flattenDownstream returns the response of a node or, for a syndic, the responses of its nodes keyed
+// syndic/node.
+//
+// The responses of the nodes get the IDs of the syndic response, under which they are stored with it. A syndic
+// response without downstream responses, e.g. if it could not dispatch the task, is kept as it is.
+func flattenDownstream(nd string, resp *proto.TaskResponse) map[string]*proto.TaskResponse {
+	if len(resp.GetDownstream()) == 0 {
+		return map[string]*proto.TaskResponse{nd: resp}
+	}
+
+	flat := make(map[string]*proto.TaskResponse, len(resp.GetDownstream()))
+	for leaf, r := range resp.GetDownstream() {
+		// the responses of the nodes of a lower syndic are flattened recursively
+		for id, r := range flattenDownstream(nd+config.SyndicSeparator+leaf, r) {
+			r.Id = resp.GetId()
+			r.GroupID = resp.GroupID
+			flat[id] = r
+		}
+	}
+	return flat
 }
@@ -0,0 +1,220 @@
+package forwarder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/node"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+func TestTargetedNodesExcludes(t *testing.T) {
+	inv := inventory.New()
+	inv.DisableRegistryFile()
+	dispatcher := NewDispatcher[*proto.TaskRequest, *proto.TaskResponse](&inv)
+
+	nodes := []node.ID{"web-1", "web-2", "web-canary-1", "web-canary-2", "db-1"}
+	for _, nodeID := range nodes {
+		_ = dispatcher.RegisterNode(nodeID)
+		inv.MarkNodeStateChange(nodeID, true)
+	}
+	_ = inv.SetSpec(node.ID("web-2"), map[string]any{"env": "staging"})
+	_ = inv.SetSpec(node.ID("web-canary-2"), map[string]any{"env": "staging"})
+
+	fwd := New(dispatcher, nil)
+	web := []*proto.Target{{Target: "web-*", Mode: proto.TargetMode_GLOB}}
+
+	tests := []struct {
+		name     string
+		targets  []*proto.Target
+		excludes []*proto.Target
+		expected map[string]bool
+		wantErr  error
+	}{
+		{
+			name:     "no exclusion",
+			targets:  web,
+			expected: map[string]bool{"web-1": true, "web-2": true, "web-canary-1": true, "web-canary-2": true},
+		},
+		{
+			name:     "glob",
+			targets:  web,
+			excludes: []*proto.Target{{Target: "web-canary-*", Mode: proto.TargetMode_GLOB}},
+			expected: map[string]bool{"web-1": true, "web-2": true},
+		},
+		{
+			name:     "regex",
+			targets:  web,
+			excludes: []*proto.Target{{Target: "web-(canary-)?1", Mode: proto.TargetMode_REGEX}},
+			expected: map[string]bool{"web-2": true, "web-canary-2": true},
+		},
+		{
+			name:     "list",
+			targets:  web,
+			excludes: []*proto.Target{{Target: "web-1,web-canary-1,db-1", Mode: proto.TargetMode_LIST}},
+			expected: map[string]bool{"web-2": true, "web-canary-2": true},
+		},
+		{
+			name:     "query",
+			targets:  web,
+			excludes: []*proto.Target{{Target: "specs.env==staging", Mode: proto.TargetMode_QUERY}},
+			expected: map[string]bool{"web-1": true, "web-canary-1": true},
+		},
+		{
+			name:    "several exclusions",
+			targets: web,
+			excludes: []*proto.Target{
+				{Target: "web-canary-*", Mode: proto.TargetMode_GLOB},
+				{Target: "specs.env==staging", Mode: proto.TargetMode_QUERY},
+			},
+			expected: map[string]bool{"web-1": true},
+		},
+		{
+			name:     "exclusion without match",
+			targets:  web,
+			excludes: []*proto.Target{{Target: "app-*", Mode: proto.TargetMode_GLOB}},
+			expected: map[string]bool{"web-1": true, "web-2": true, "web-canary-1": true, "web-canary-2": true},
+		},
+		{
+			name:     "empty after exclusion",
+			targets:  []*proto.Target{{Target: "web-canary-*", Mode: proto.TargetMode_GLOB}},
+			excludes: []*proto.Target{{Target: "web-*", Mode: proto.TargetMode_GLOB}},
+			wantErr:  ErrNoMatchingNode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &proto.TaskRequest{Targets: tt.targets, Excludes: tt.excludes}
+			result, err := fwd.targetedNodes(req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected %v, got %v (%v)", tt.wantErr, err, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(result, tt.expected); diff != "" {
+				t.Errorf("Mismatch (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTargetedNodesInvalidExclusion(t *testing.T) {
+	inv := &inventory.Nodes{}
+	dispatcher := NewDispatcher[*proto.TaskRequest, *proto.TaskResponse](inv)
+	_ = dispatcher.RegisterNode(node.ID("node1"))
+	fwd := New(dispatcher, nil)
+
+	req := &proto.TaskRequest{
+		Target:     "node1",
+		TargetMode: proto.TargetMode_EXACT,
+		Excludes:   []*proto.Target{{Target: "[", Mode: proto.TargetMode_REGEX}},
+	}
+	if _, err := fwd.targetedNodes(req); err == nil {
+		t.Error("Expected an error for an invalid exclusion")
+	}
+}
+
+func TestFlattenDownstream(t *testing.T) {
+	groupID := int64(42)
+	resp := &proto.TaskResponse{
+		Id:      7,
+		GroupID: &groupID,
+		Downstream: map[string]*proto.TaskResponse{
+			"web-1": {Id: 100, Output: []byte(`"ok"`)},
+			"eu-manager": {Downstream: map[string]*proto.TaskResponse{
+				"db-1": {Id: 200, Error: "failed"},
+			}},
+			"eu-empty": {InternalError: proto.InternalError_DOWNSTREAM_ERROR},
+		},
+	}
+
+	got := make(map[string]string)
+	for id, r := range flattenDownstream("syndic", resp) {
+		if r.GetId() != 7 || r.GetGroupID() != groupID {
+			t.Errorf("%s: expected the IDs of the syndic response, got %d/%d", id, r.GetId(), r.GetGroupID())
+		}
+		got[id] = string(r.GetOutput()) + r.GetError() + r.GetInternalError().String()
+	}
+	want := map[string]string{
+		"syndic/web-1":           `"ok"OK`,
+		"syndic/eu-manager/db-1": "failedOK",
+		"syndic/eu-empty":        "DOWNSTREAM_ERROR",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected responses (-want +got):\n%s", diff)
+	}
+
+	node := &proto.TaskResponse{Id: 1, Output: []byte(`"ok"`)}
+	if flat := flattenDownstream("web-1", node); len(flat) != 1 || flat["web-1"] != node {
+		t.Errorf("expected the response of the node as it is, got %v", flat)
+	}
+}
+
+func TestExecUnresponsiveNode(t *testing.T) {
+	inv := inventory.New()
+	inv.DisableRegistryFile()
+	dispatcher := NewDispatcher[*proto.TaskRequest, *proto.TaskResponse](&inv)
+	for _, nodeID := range []node.ID{"healthy", "wedged"} {
+		_ = dispatcher.RegisterNode(nodeID)
+		inv.MarkNodeStateChange(nodeID, true)
+	}
+
+	// the healthy node answers, the wedged one never does, not even with a timeout response
+	healthy, _ := dispatcher.GetTasksChannel("healthy")
+	wedged, _ := dispatcher.GetTasksChannel("wedged")
+	go func() {
+		for task := range healthy {
+			task.ResponseCh <- &proto.TaskResponse{GroupID: task.Request.GroupID, Output: []byte(`true`)}
+		}
+	}()
+	go func() {
+		for range wedged {
+		}
+	}()
+	t.Cleanup(func() {
+		dispatcher.Close("healthy")
+		dispatcher.Close("wedged")
+	})
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	fwd := New(dispatcher, db)
+	fwd.SetResponseGrace(100 * time.Millisecond)
+
+	start := time.Now()
+	resp, err := fwd.ExecTask(context.Background(), &proto.TaskRequest{
+		Target:     "healthy,wedged",
+		TargetMode: proto.TargetMode_LIST,
+		Plugin:     "health",
+		Task:       "ping",
+		Timeout:    1,
+	})
+	if err != nil {
+		t.Fatalf("ExecTask() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("the requester waited %s for the wedged node", elapsed)
+	}
+
+	got := make(map[string]string)
+	for nd, r := range resp.GetResponses() {
+		got[nd] = r.GetInternalError().String()
+	}
+	want := map[string]string{"healthy": "OK", "wedged": "NODE_UNRESPONSIVE"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected responses (-want +got):\n%s", diff)
+	}
+}
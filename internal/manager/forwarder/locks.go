@@ -0,0 +1,165 @@
+package forwarder
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+// LockTracker follows the lock modes of the tasks in flight on each node, to detect the runs whose lock mode
+// conflicts with the tasks already sent to the same nodes, and aggregates the time the tasks waited for their
+// lock on the nodes.
+//
+// The lock mode of a request without one is the default of its task, learned from the previous responses: it
+// is unknown until the task ran once. A nil LockTracker tracks nothing.
+type LockTracker struct {
+	mutex    sync.Mutex
+	inFlight map[string]map[proto.LockMode]int // node -> lock mode -> tasks
+	defaults map[string]proto.LockMode         // plugin.task -> default lock mode reported by the nodes
+	stats    map[string]*NodeLockStats
+}
+
+// NodeLockStats is the lock contention of a node.
+type NodeLockStats struct {
+	Modes     map[proto.LockMode]LockWaitStats
+	Conflicts uint64 // Tasks sent while a task with a conflicting lock mode was in flight on the node.
+}
+
+// LockWaitStats is the time waited by the tasks of a lock mode for their slot and their lock.
+type LockWaitStats struct {
+	Tasks     uint64
+	Contended uint64 // Tasks which waited longer than config.LockWaitThreshold.
+	TotalWait time.Duration
+	MaxWait   time.Duration
+}
+
+func NewLockTracker() *LockTracker {
+	return &LockTracker{
+		inFlight: make(map[string]map[proto.LockMode]int),
+		defaults: make(map[string]proto.LockMode),
+		stats:    make(map[string]*NodeLockStats),
+	}
+}
+
+// conflictingLockModes reports whether a task of mode a must wait for a task of mode b, or the opposite.
+//
+// The NO_LOCK and WRITE tasks only wait for the EXCLUSIVE ones, and the WRITE tasks run one at a time.
+func conflictingLockModes(a, b proto.LockMode) bool {
+	switch {
+	case a == proto.LockMode_UNSPECIFIED || b == proto.LockMode_UNSPECIFIED:
+		return false // unknown
+	case a == proto.LockMode_EXCLUSIVE || b == proto.LockMode_EXCLUSIVE:
+		return true
+	default:
+		return a == proto.LockMode_WRITE && b == proto.LockMode_WRITE
+	}
+}
+
+// lockMode returns the lock mode of the request, UNSPECIFIED if unknown.
+func (t *LockTracker) lockMode(req *proto.TaskRequest) proto.LockMode {
+	if t == nil || req.GetLockMode() != proto.LockMode_UNSPECIFIED {
+		return req.GetLockMode()
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.defaults[req.FullTask()]
+}
+
+// start records a task of the lock mode in flight on the nodes, and returns the nodes where a task with a
+// conflicting lock mode is already in flight.
+func (t *LockTracker) start(nodes []string, mode proto.LockMode) []string {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var conflicts []string
+	for _, nd := range nodes {
+		modes, ok := t.inFlight[nd]
+		if !ok {
+			modes = make(map[proto.LockMode]int)
+			t.inFlight[nd] = modes
+		}
+		for other, count := range modes {
+			if count > 0 && conflictingLockModes(mode, other) {
+				conflicts = append(conflicts, nd)
+				t.nodeStats(nd).Conflicts++
+				break
+			}
+		}
+		modes[mode]++
+	}
+	slices.Sort(conflicts)
+	return conflicts
+}
+
+// done records the end of a task started with the lock mode, and the lock wait reported by its response.
+//
+// The response can be nil if the task was not sent. A task timing out on the manager side is considered
+// done, even if it is still running on the node.
+func (t *LockTracker) done(nd string, task string, mode proto.LockMode, resp *proto.TaskResponse) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if modes, ok := t.inFlight[nd]; ok {
+		modes[mode]--
+		if modes[mode] <= 0 {
+			delete(modes, mode)
+		}
+		if len(modes) == 0 {
+			delete(t.inFlight, nd)
+		}
+	}
+
+	// the nodes not reporting the lock mode do not report the lock wait either
+	effective := resp.GetLockMode()
+	if effective == proto.LockMode_UNSPECIFIED {
+		return
+	}
+	if mode == proto.LockMode_UNSPECIFIED {
+		t.defaults[task] = effective
+	}
+
+	stats := t.nodeStats(nd)
+	wait := time.Duration(resp.GetLockWait()) * time.Millisecond
+	s := stats.Modes[effective]
+	s.Tasks++
+	s.TotalWait += wait
+	s.MaxWait = max(s.MaxWait, wait)
+	if wait >= config.LockWaitThreshold {
+		s.Contended++
+	}
+	stats.Modes[effective] = s
+}
+
+func (t *LockTracker) nodeStats(nd string) *NodeLockStats {
+	stats, ok := t.stats[nd]
+	if !ok {
+		stats = &NodeLockStats{Modes: make(map[proto.LockMode]LockWaitStats)}
+		t.stats[nd] = stats
+	}
+	return stats
+}
+
+// Stats returns the lock contention of each node.
+func (t *LockTracker) Stats() map[string]NodeLockStats {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	stats := make(map[string]NodeLockStats, len(t.stats))
+	for nd, s := range t.stats {
+		stats[nd] = NodeLockStats{Modes: maps.Clone(s.Modes), Conflicts: s.Conflicts}
+	}
+	return stats
+}
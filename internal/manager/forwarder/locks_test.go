@@ -0,0 +1,127 @@
+package forwarder
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+func TestLockTrackerConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		inFlight  proto.LockMode
+		mode      proto.LockMode
+		conflicts []string
+	}{
+		{"no-lock during no-lock", proto.LockMode_NO_LOCK, proto.LockMode_NO_LOCK, nil},
+		{"no-lock during write", proto.LockMode_WRITE, proto.LockMode_NO_LOCK, nil},
+		{"write during write", proto.LockMode_WRITE, proto.LockMode_WRITE, []string{"web-1"}},
+		{"no-lock during exclusive", proto.LockMode_EXCLUSIVE, proto.LockMode_NO_LOCK, []string{"web-1"}},
+		{"exclusive during no-lock", proto.LockMode_NO_LOCK, proto.LockMode_EXCLUSIVE, []string{"web-1"}},
+		{"unknown lock mode", proto.LockMode_UNSPECIFIED, proto.LockMode_EXCLUSIVE, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locks := NewLockTracker()
+			locks.start([]string{"web-1"}, tt.inFlight)
+
+			conflicts := locks.start([]string{"web-1", "web-2"}, tt.mode)
+			if diff := cmp.Diff(tt.conflicts, conflicts); diff != "" {
+				t.Errorf("conflicts mismatch (-want +got):\n%s", diff)
+			}
+			if got := locks.Stats()["web-1"].Conflicts; got != uint64(len(tt.conflicts)) {
+				t.Errorf("expected %d conflict(s) counted, got %d", len(tt.conflicts), got)
+			}
+		})
+	}
+}
+
+func TestLockTrackerDone(t *testing.T) {
+	locks := NewLockTracker()
+	locks.start([]string{"web-1"}, proto.LockMode_EXCLUSIVE)
+	locks.done("web-1", "pkg.upgrade", proto.LockMode_EXCLUSIVE, &proto.TaskResponse{LockMode: proto.LockMode_EXCLUSIVE})
+
+	// the exclusive task is done: no conflict anymore
+	if conflicts := locks.start([]string{"web-1"}, proto.LockMode_NO_LOCK); len(conflicts) != 0 {
+		t.Errorf("unexpected conflicts: %v", conflicts)
+	}
+	locks.done("web-1", "cmd.run", proto.LockMode_NO_LOCK, nil) // not sent
+
+	if conflicts := locks.start([]string{"web-1"}, proto.LockMode_EXCLUSIVE); len(conflicts) != 0 {
+		t.Errorf("a task not sent must not be in flight, got conflicts: %v", conflicts)
+	}
+}
+
+func TestLockTrackerWaitAttribution(t *testing.T) {
+	locks := NewLockTracker()
+	nodes := []string{"web-1", "web-2"}
+
+	// mixed lock-mode load: an exclusive task, and no-lock tasks serialized behind it on web-1
+	locks.start(nodes, proto.LockMode_EXCLUSIVE)
+	if conflicts := locks.start(nodes, proto.LockMode_NO_LOCK); !slices.Equal(conflicts, nodes) {
+		t.Errorf("expected conflicts on %v, got %v", nodes, conflicts)
+	}
+	locks.start(nodes, proto.LockMode_UNSPECIFIED) // default lock mode of the task, unknown yet
+
+	contended := config.LockWaitThreshold + 500*time.Millisecond
+	for _, resp := range []struct {
+		node string
+		task string
+		mode proto.LockMode
+		resp *proto.TaskResponse
+	}{
+		{"web-1", "pkg.upgrade", proto.LockMode_EXCLUSIVE, &proto.TaskResponse{LockMode: proto.LockMode_EXCLUSIVE, LockWait: 2}},
+		{"web-2", "pkg.upgrade", proto.LockMode_EXCLUSIVE, &proto.TaskResponse{LockMode: proto.LockMode_EXCLUSIVE, LockWait: 0}},
+		{"web-1", "cmd.run", proto.LockMode_NO_LOCK, &proto.TaskResponse{LockMode: proto.LockMode_NO_LOCK, LockWait: contended.Milliseconds()}},
+		{"web-2", "cmd.run", proto.LockMode_NO_LOCK, &proto.TaskResponse{LockMode: proto.LockMode_NO_LOCK, LockWait: 10}},
+		{"web-1", "git.pull", proto.LockMode_UNSPECIFIED, &proto.TaskResponse{LockMode: proto.LockMode_WRITE, LockWait: 300}},
+		{"web-2", "git.pull", proto.LockMode_UNSPECIFIED, &proto.TaskResponse{}}, // node not reporting its lock wait
+	} {
+		locks.done(resp.node, resp.task, resp.mode, resp.resp)
+	}
+
+	expected := map[string]NodeLockStats{
+		"web-1": {
+			Conflicts: 1,
+			Modes: map[proto.LockMode]LockWaitStats{
+				proto.LockMode_EXCLUSIVE: {Tasks: 1, TotalWait: 2 * time.Millisecond, MaxWait: 2 * time.Millisecond},
+				proto.LockMode_NO_LOCK:   {Tasks: 1, Contended: 1, TotalWait: contended, MaxWait: contended},
+				proto.LockMode_WRITE:     {Tasks: 1, TotalWait: 300 * time.Millisecond, MaxWait: 300 * time.Millisecond},
+			},
+		},
+		"web-2": {
+			Conflicts: 1,
+			Modes: map[proto.LockMode]LockWaitStats{
+				proto.LockMode_EXCLUSIVE: {Tasks: 1},
+				proto.LockMode_NO_LOCK:   {Tasks: 1, TotalWait: 10 * time.Millisecond, MaxWait: 10 * time.Millisecond},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, locks.Stats()); diff != "" {
+		t.Errorf("stats mismatch (-want +got):\n%s", diff)
+	}
+
+	// the default lock mode of the task is learned from its responses
+	if mode := locks.lockMode(&proto.TaskRequest{Plugin: "git", Task: "pull"}); mode != proto.LockMode_WRITE {
+		t.Errorf("expected the learned WRITE lock mode, got %s", mode)
+	}
+	if mode := locks.lockMode(&proto.TaskRequest{Plugin: "git", Task: "pull", LockMode: proto.LockMode_NO_LOCK}); mode != proto.LockMode_NO_LOCK {
+		t.Errorf("expected the lock mode of the request, got %s", mode)
+	}
+}
+
+func TestLockTrackerNil(t *testing.T) {
+	var locks *LockTracker
+	if conflicts := locks.start([]string{"web-1"}, proto.LockMode_EXCLUSIVE); conflicts != nil {
+		t.Errorf("unexpected conflicts: %v", conflicts)
+	}
+	locks.done("web-1", "cmd.run", proto.LockMode_EXCLUSIVE, nil)
+	if stats := locks.Stats(); stats != nil {
+		t.Errorf("unexpected stats: %v", stats)
+	}
+}
@@ -0,0 +1,177 @@
+package forwarder
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/logs"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ScheduleTask stores the request in the database until the given time, then RunPending runs it.
+//
+// The targets are resolved at once to refuse a request without matching node, and again when the request runs.
+func (f *GRPCForwarder) ScheduleTask(ctx context.Context, req *proto.ScheduleTaskRequest) (*proto.PendingTask, error) {
+	if req.GetRequest() == nil || req.GetAt() == nil {
+		return nil, errors.New("a request and a time are required")
+	}
+	at := req.GetAt().AsTime()
+	if !at.After(f.clock.Now()) {
+		return nil, fmt.Errorf("the time %s is passed", at.Format(time.RFC3339))
+	}
+	if _, err := f.targetedNodes(req.GetRequest()); err != nil {
+		return nil, err
+	}
+
+	pending := &proto.PendingTask{
+		Id:      f.groupIDs.Next(f.clock.Now()),
+		At:      req.GetAt(),
+		Request: req.GetRequest(),
+	}
+	data, err := protobuf.Marshal(pending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize the scheduled request: %w", err)
+	}
+	err = f.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(database.GeneratePendingKey(pending.GetId()), data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store the scheduled request: %w", err)
+	}
+
+	logs.FromContext(ctx).Info("request scheduled", "id", pending.GetId(), "task", pending.GetRequest().FullTask(), "at", at)
+	return pending, nil
+}
+
+// ListPending returns the scheduled requests not run yet, the earliest first.
+func (f *GRPCForwarder) ListPending(_ context.Context, _ *emptypb.Empty) (*proto.ListPendingResponse, error) {
+	var tasks []*proto.PendingTask
+	err := f.db.View(func(txn *badger.Txn) error {
+		var err error
+		tasks, err = pendingTasks(txn)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the scheduled requests: %w", err)
+	}
+
+	slices.SortFunc(tasks, func(a, b *proto.PendingTask) int {
+		if c := a.GetAt().AsTime().Compare(b.GetAt().AsTime()); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.GetId(), b.GetId())
+	})
+	return &proto.ListPendingResponse{Tasks: tasks}, nil
+}
+
+// CancelPending removes scheduled requests before they run.
+func (f *GRPCForwarder) CancelPending(ctx context.Context, req *proto.CancelPendingRequest) (*proto.CancelPendingResponse, error) {
+	resp := &proto.CancelPendingResponse{}
+	err := f.db.Update(func(txn *badger.Txn) error {
+		resp.Cancelled = nil
+		for _, id := range req.GetIds() {
+			key := database.GeneratePendingKey(id)
+			switch _, err := txn.Get(key); {
+			case errors.Is(err, badger.ErrKeyNotFound):
+				continue
+			case err != nil:
+				return err
+			}
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+			resp.Cancelled = append(resp.Cancelled, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel the scheduled requests: %w", err)
+	}
+
+	if len(resp.GetCancelled()) > 0 {
+		logs.FromContext(ctx).Info("scheduled requests cancelled", "ids", resp.GetCancelled())
+	}
+	return resp, nil
+}
+
+// RunPending runs the scheduled requests once due, until the context is cancelled.
+//
+// The requests due while the manager was stopped run when it starts.
+func (f *GRPCForwarder) RunPending(ctx context.Context) {
+	ticker := time.NewTicker(config.PendingCheckInterval)
+	defer ticker.Stop()
+	for {
+		f.dispatchPending(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// dispatchPending removes the due requests from the database and runs them, without waiting for their responses.
+func (f *GRPCForwarder) dispatchPending(ctx context.Context) {
+	now := f.clock.Now()
+	var due []*proto.PendingTask
+	err := f.db.Update(func(txn *badger.Txn) error {
+		tasks, err := pendingTasks(txn)
+		if err != nil {
+			return err
+		}
+		for _, p := range tasks {
+			if p.GetAt().AsTime().After(now) {
+				continue
+			}
+			if err := txn.Delete(database.GeneratePendingKey(p.GetId())); err != nil {
+				return err
+			}
+			due = append(due, p)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Warn("failed to read the scheduled requests due", "error", err)
+		return
+	}
+
+	for _, p := range due {
+		slog.Info("running scheduled request", "id", p.GetId(), "task", p.GetRequest().FullTask(), "at", p.GetAt().AsTime())
+		go func() {
+			if _, err := f.exec(ctx, p.GetRequest(), nil); err != nil {
+				slog.Warn("scheduled request not run", "id", p.GetId(), "task", p.GetRequest().FullTask(), "error", err)
+			}
+		}()
+	}
+}
+
+// pendingTasks returns the scheduled requests stored in the database.
+func pendingTasks(txn *badger.Txn) ([]*proto.PendingTask, error) {
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = fmt.Appendf(nil, "%s:", database.PendingKeyPrefix)
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	var tasks []*proto.PendingTask
+	for it.Rewind(); it.Valid(); it.Next() {
+		var p proto.PendingTask
+		err := it.Item().Value(func(val []byte) error {
+			return protobuf.Unmarshal(val, &p)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid scheduled request %s: %w", it.Item().Key(), err)
+		}
+		tasks = append(tasks, &p)
+	}
+	return tasks, nil
+}
@@ -0,0 +1,157 @@
+package forwarder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackadi-io/jackadi/internal/clock"
+	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newPendingForwarder returns a forwarder with a fake clock, and the channel of the tasks received by its node
+// web-1.
+func newPendingForwarder(t *testing.T) (*GRPCForwarder, *clock.Fake, <-chan string) {
+	t.Helper()
+	inv := inventory.New()
+	inv.DisableRegistryFile()
+	dispatcher := NewDispatcher[*proto.TaskRequest, *proto.TaskResponse](&inv)
+	_ = dispatcher.RegisterNode("web-1")
+	inv.MarkNodeStateChange("web-1", true)
+
+	tasks, _ := dispatcher.GetTasksChannel("web-1")
+	received := make(chan string, 10)
+	go func() {
+		for task := range tasks {
+			received <- task.Request.FullTask()
+			task.ResponseCh <- &proto.TaskResponse{GroupID: task.Request.GroupID, Output: []byte(`true`)}
+		}
+	}()
+	t.Cleanup(func() { dispatcher.Close("web-1") })
+
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	fake := clock.NewFake(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	fwd := New(dispatcher, db)
+	fwd.clock = fake
+	return &fwd, fake, received
+}
+
+func schedule(t *testing.T, fwd *GRPCForwarder, task string, at time.Time) int64 {
+	t.Helper()
+	pending, err := fwd.ScheduleTask(context.Background(), &proto.ScheduleTaskRequest{
+		Request: &proto.TaskRequest{Target: "web-1", TargetMode: proto.TargetMode_EXACT, Plugin: task, Task: task},
+		At:      timestamppb.New(at),
+	})
+	if err != nil {
+		t.Fatalf("ScheduleTask() error = %v", err)
+	}
+	return pending.GetId()
+}
+
+// pendingIDs returns the IDs of the listed scheduled requests.
+func pendingIDs(t *testing.T, fwd *GRPCForwarder) []int64 {
+	t.Helper()
+	resp, err := fwd.ListPending(context.Background(), &emptypb.Empty{})
+	if err != nil {
+		t.Fatalf("ListPending() error = %v", err)
+	}
+	var ids []int64
+	for _, p := range resp.GetTasks() {
+		ids = append(ids, p.GetId())
+	}
+	return ids
+}
+
+func TestScheduleTask(t *testing.T) {
+	fwd, fake, received := newPendingForwarder(t)
+	window := fake.Now().Add(2 * time.Hour)
+
+	late := schedule(t, fwd, "late", window.Add(time.Hour))
+	early := schedule(t, fwd, "early", window)
+	if diff := cmp.Diff([]int64{early, late}, pendingIDs(t, fwd)); diff != "" {
+		t.Errorf("unexpected pending requests, the earliest first (-want +got):\n%s", diff)
+	}
+
+	for name, req := range map[string]*proto.ScheduleTaskRequest{
+		"passed time":      {Request: &proto.TaskRequest{Target: "web-1", Task: "ping"}, At: timestamppb.New(fake.Now().Add(-time.Minute))},
+		"no matching node": {Request: &proto.TaskRequest{Target: "db-*", TargetMode: proto.TargetMode_GLOB, Task: "ping"}, At: timestamppb.New(window)},
+		"no time":          {Request: &proto.TaskRequest{Target: "web-1", Task: "ping"}},
+	} {
+		if _, err := fwd.ScheduleTask(context.Background(), req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	fwd.dispatchPending(context.Background())
+	select {
+	case task := <-received:
+		t.Fatalf("%s run before its time", task)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	fake.Set(window)
+	fwd.dispatchPending(context.Background())
+	select {
+	case task := <-received:
+		if task != "early.early" {
+			t.Errorf("expected early.early to run, got %s", task)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the request due did not run")
+	}
+	if diff := cmp.Diff([]int64{late}, pendingIDs(t, fwd)); diff != "" {
+		t.Errorf("the request run must not be pending anymore (-want +got):\n%s", diff)
+	}
+
+	fwd.dispatchPending(context.Background())
+	select {
+	case task := <-received:
+		t.Fatalf("%s run twice", task)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCancelPending(t *testing.T) {
+	fwd, fake, received := newPendingForwarder(t)
+	window := fake.Now().Add(time.Hour)
+
+	cancelled := schedule(t, fwd, "cancelled", window)
+	kept := schedule(t, fwd, "kept", window)
+
+	resp, err := fwd.CancelPending(context.Background(), &proto.CancelPendingRequest{Ids: []int64{cancelled, 42}})
+	if err != nil {
+		t.Fatalf("CancelPending() error = %v", err)
+	}
+	if diff := cmp.Diff([]int64{cancelled}, resp.GetCancelled()); diff != "" {
+		t.Errorf("unexpected cancelled requests, the unknown ones ignored (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int64{kept}, pendingIDs(t, fwd)); diff != "" {
+		t.Errorf("unexpected pending requests (-want +got):\n%s", diff)
+	}
+
+	fake.Set(window)
+	fwd.dispatchPending(context.Background())
+	select {
+	case task := <-received:
+		if task != "kept.kept" {
+			t.Errorf("expected kept.kept to run, got %s", task)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the request due did not run")
+	}
+	select {
+	case task := <-received:
+		t.Errorf("%s run after its cancellation", task)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,309 @@
+package forwarder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// query is the AST of a filter expression: a condition, or the AND/OR of queries.
+//
+// The grammar of the filter expressions, "and" taking precedence over "or":
+//
+//	expr      := and ("or" and)*
+//	and       := primary ("and" primary)*
+//	primary   := "(" expr ")" | condition
+//	condition := field ("==" | "=~") value | field "in" list
+//	list      := "[" value ("," value)* "]"
+//
+// The field is id or specs.<path>, the in operator matching the nodes whose field equals any value of the
+// list. The value is a literal quoted with " or ' which can contain spaces, keywords and parentheses (e.g.
+// specs.owner=="ops and dev"), or the words up to the next keyword, operator or delimiter (e.g. specs.os==Red Hat).
+// The closing parentheses ending a word are not part of the value, unless they close a parenthesis of the value
+// (e.g. id=~/(web|db)-1/).
+type query interface {
+	String() string
+}
+
+type andQuery []query
+
+type orQuery []query
+
+// condition is a condition of a query, e.g. specs.os==linux.
+type condition struct {
+	field    string
+	operator string
+	value    string
+	values   []string // Values of the in operator.
+}
+
+func (q andQuery) String() string { return joinQueries(q, " and ") }
+
+func (q orQuery) String() string { return joinQueries(q, " or ") }
+
+func (c condition) String() string {
+	if c.operator == "in" {
+		return c.field + " in [" + strings.Join(c.values, ", ") + "]"
+	}
+	return c.field + c.operator + c.value
+}
+
+func joinQueries(queries []query, sep string) string {
+	parts := make([]string, len(queries))
+	for i, q := range queries {
+		parts[i] = "(" + q.String() + ")"
+	}
+	return strings.Join(parts, sep)
+}
+
+// queryParser is a recursive descent parser of the filter expressions.
+type queryParser struct {
+	expr string
+	pos  int
+}
+
+// parseQuery parses a filter expression like "(id=~web-* or id==db-1) and specs.os==linux".
+func parseQuery(expr string) (query, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, errors.New("empty filter expression")
+	}
+
+	p := &queryParser{expr: expr}
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", p.expr[p.pos:])
+	}
+	return q, nil
+}
+
+func (p *queryParser) parseOr() (query, error) {
+	var operands orQuery
+	for {
+		q, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, q)
+		if !p.keyword("or") {
+			break
+		}
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (p *queryParser) parseAnd() (query, error) {
+	var operands andQuery
+	for {
+		q, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, q)
+		if !p.keyword("and") {
+			break
+		}
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (p *queryParser) parsePrimary() (query, error) {
+	p.skipSpaces()
+	if p.done() {
+		return nil, p.errorf("condition expected")
+	}
+	if p.expr[p.pos] != '(' {
+		return p.parseCondition()
+	}
+
+	p.pos++
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.done() || p.expr[p.pos] != ')' {
+		return nil, p.errorf("missing closing parenthesis")
+	}
+	p.pos++
+	return q, nil
+}
+
+func (p *queryParser) parseCondition() (query, error) {
+	start := p.pos
+	for !p.done() && !unicode.IsSpace(rune(p.expr[p.pos])) && !strings.ContainsRune("=!<>()", rune(p.expr[p.pos])) {
+		p.pos++
+	}
+	c := condition{field: p.expr[start:p.pos]}
+	if c.field == "" {
+		return nil, p.errorf("field expected")
+	}
+	if c.field != "id" && !strings.HasPrefix(c.field, "specs.") {
+		return nil, p.errorf("unsupported field: %q", c.field)
+	}
+
+	if p.keyword("in") {
+		c.operator = "in"
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		c.values = values
+		return c, nil
+	}
+
+	p.skipSpaces()
+	switch {
+	case strings.HasPrefix(p.expr[p.pos:], "=="):
+		c.operator = "=="
+	case strings.HasPrefix(p.expr[p.pos:], "=~"):
+		c.operator = "=~"
+	default:
+		return nil, p.errorf("unsupported operator in condition %q", p.expr[start:])
+	}
+	p.pos += len(c.operator)
+
+	p.skipSpaces()
+	value, err := p.parseValue("")
+	if err != nil {
+		return nil, err
+	}
+	c.value = value
+	return c, nil
+}
+
+// parseList parses a bracketed list of values, e.g. [us-east, "us west"].
+func (p *queryParser) parseList() ([]string, error) {
+	p.skipSpaces()
+	if p.done() || p.expr[p.pos] != '[' {
+		return nil, p.errorf("list expected after in")
+	}
+	p.pos++
+
+	p.skipSpaces()
+	if !p.done() && p.expr[p.pos] == ']' {
+		return nil, p.errorf("empty list")
+	}
+
+	var values []string
+	for {
+		p.skipSpaces()
+		value, err := p.parseValue(",]")
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		p.skipSpaces()
+		switch {
+		case p.done():
+			return nil, p.errorf("missing closing bracket")
+		case p.expr[p.pos] == ',':
+			p.pos++
+		case p.expr[p.pos] == ']':
+			p.pos++
+			return values, nil
+		default:
+			return nil, p.errorf("unexpected %q in list", p.expr[p.pos])
+		}
+	}
+}
+
+// parseValue parses a quoted literal, or the unquoted words ending before a keyword, a word with an operator, an
+// unbalanced closing parenthesis or one of the delimiters.
+func (p *queryParser) parseValue(delimiters string) (string, error) {
+	if p.done() {
+		return "", p.errorf("value expected")
+	}
+
+	if quote := p.expr[p.pos]; quote == '"' || quote == '\'' {
+		var value strings.Builder
+		for p.pos++; !p.done(); p.pos++ {
+			switch c := p.expr[p.pos]; {
+			case c == quote:
+				p.pos++
+				return value.String(), nil
+			case c == '\\' && p.pos+1 < len(p.expr):
+				p.pos++
+				value.WriteByte(p.expr[p.pos])
+			default:
+				value.WriteByte(c)
+			}
+		}
+		return "", p.errorf("unterminated quoted value")
+	}
+
+	start := p.pos
+	unbalanced := func() bool {
+		return strings.Count(p.expr[start:p.pos], ")") > strings.Count(p.expr[start:p.pos], "(")
+	}
+	for {
+		for !p.done() && !unicode.IsSpace(rune(p.expr[p.pos])) && !strings.ContainsRune(delimiters, rune(p.expr[p.pos])) {
+			p.pos++
+		}
+
+		// the spaces are part of the value as long as the next word does not end it, e.g. specs.os==Red Hat
+		next := p.pos
+		for next < len(p.expr) && unicode.IsSpace(rune(p.expr[next])) {
+			next++
+		}
+		end := next
+		for end < len(p.expr) && !unicode.IsSpace(rune(p.expr[end])) {
+			end++
+		}
+		word := p.expr[next:end]
+		if next == p.pos || word == "" || unbalanced() || strings.ContainsAny(word[:1], delimiters+")") ||
+			p.isKeyword(next, "and") || p.isKeyword(next, "or") || strings.Contains(word, "==") || strings.Contains(word, "=~") {
+			break
+		}
+		p.pos = next
+	}
+	// the unbalanced closing parentheses close the groups of the expression
+	for p.pos > start && p.expr[p.pos-1] == ')' && unbalanced() {
+		p.pos--
+	}
+	if p.pos == start {
+		return "", p.errorf("value expected")
+	}
+	return p.expr[start:p.pos], nil
+}
+
+// keyword consumes the keyword if it is the next word.
+func (p *queryParser) keyword(kw string) bool {
+	p.skipSpaces()
+	if !p.isKeyword(p.pos, kw) {
+		return false
+	}
+	p.pos += len(kw)
+	return true
+}
+
+// isKeyword reports whether the keyword starts at pos, followed by a space, a parenthesis, a bracket or the end.
+func (p *queryParser) isKeyword(pos int, kw string) bool {
+	end := pos + len(kw)
+	return strings.HasPrefix(p.expr[pos:], kw) && (end == len(p.expr) || unicode.IsSpace(rune(p.expr[end])) || strings.ContainsRune("([", rune(p.expr[end])))
+}
+
+func (p *queryParser) skipSpaces() {
+	for !p.done() && unicode.IsSpace(rune(p.expr[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *queryParser) done() bool {
+	return p.pos >= len(p.expr)
+}
+
+func (p *queryParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid filter at position %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,277 @@
+package forwarder
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/node"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		expr        string
+		expected    string
+		expectError bool
+	}{
+		{expr: "specs.os==linux", expected: "specs.os==linux"},
+		{expr: "specs.os == linux", expected: "specs.os==linux"},
+		{expr: "id==a or id==b and id==c", expected: "(id==a) or ((id==b) and (id==c))"},
+		{expr: "(id==a or id==b) and id==c", expected: "((id==a) or (id==b)) and (id==c)"},
+		{expr: "id==a and (id==b or (id==c))", expected: "(id==a) and ((id==b) or (id==c))"},
+		{expr: `specs.owner=="ops and dev" or id==x`, expected: "(specs.owner==ops and dev) or (id==x)"},
+		{expr: `specs.owner=='it''s'`, expectError: true},
+		{expr: `specs.owner=='it\'s (or not)'`, expected: "specs.owner==it's (or not)"},
+		{expr: "(id=~/(web|db)-1/)", expected: "id=~/(web|db)-1/"},
+		{expr: "id==android", expected: "id==android"},
+		// unquoted values with spaces, accepted before the quoted literals
+		{expr: "specs.os==Red Hat", expected: "specs.os==Red Hat"},
+		{expr: "specs.os==Red Hat Enterprise Linux and id==a", expected: "(specs.os==Red Hat Enterprise Linux) and (id==a)"},
+		{expr: "(specs.os == Red Hat ) or specs.os==CentOS  Stream", expected: "(specs.os==Red Hat) or (specs.os==CentOS  Stream)"},
+		{expr: "specs.region in [us east, eu west]", expected: "specs.region in [us east, eu west]"},
+		{expr: "id==a id==b", expectError: true},
+		{expr: "id==a andid==b", expectError: true},
+		{expr: "", expectError: true},
+		{expr: "(id==a", expectError: true},
+		{expr: "id==a)", expectError: true},
+		{expr: "id==a and", expectError: true},
+		{expr: `id=="a`, expectError: true},
+		{expr: "hostname!=web-1", expectError: true},
+		{expr: "id!=web-1", expectError: true},
+		{expr: "specs.region in [us-east, us-west]", expected: "specs.region in [us-east, us-west]"},
+		{expr: `id in[a,"b, c" , 'd]'] and id==a`, expected: "(id in [a, b, c, d]]) and (id==a)"},
+		{expr: "(id in [a]) or id==b", expected: "(id in [a]) or (id==b)"},
+		{expr: "id in []", expectError: true},
+		{expr: "id in [ ]", expectError: true},
+		{expr: "id in [a,]", expectError: true},
+		{expr: "id in [a", expectError: true},
+		{expr: "id in a", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			q, err := parseQuery(tt.expr)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got %s", q)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := q.String(); got != tt.expected {
+				t.Errorf("parseQuery(%q) = %s, want %s", tt.expr, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTargetedNodesQueryGrammar(t *testing.T) {
+	inv := inventory.New()
+	inv.DisableRegistryFile()
+	dispatcher := NewDispatcher[string, string](&inv)
+
+	specs := map[node.ID]map[string]any{
+		"web-1": {"owner": "ops and dev", "os": "linux"},
+		"web-2": {"owner": "ops", "os": "linux"},
+		"db-1":  {"owner": "dba or ops", "os": "linux"},
+		"win-1": {"owner": "ops", "os": "windows"},
+	}
+	for id, s := range specs {
+		_ = dispatcher.RegisterNode(id)
+		inv.MarkNodeStateChange(id, true)
+		_ = inv.SetSpec(id, s)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected map[string]bool
+	}{
+		{
+			name:     "quoted value with and",
+			query:    `specs.owner=="ops and dev"`,
+			expected: map[string]bool{"web-1": true},
+		},
+		{
+			name:     "quoted value with or",
+			query:    `specs.owner=='dba or ops' or id==web-2`,
+			expected: map[string]bool{"db-1": true, "web-2": true},
+		},
+		{
+			name:     "and before or",
+			query:    "id==win-1 or specs.os==linux and specs.owner==ops",
+			expected: map[string]bool{"win-1": true, "web-2": true},
+		},
+		{
+			name:     "parentheses",
+			query:    "(id==win-1 or specs.os==linux) and specs.owner==ops",
+			expected: map[string]bool{"win-1": true, "web-2": true},
+		},
+		{
+			name:     "parentheses and regex",
+			query:    "(id=~/(web|db)-1/) and specs.os==linux",
+			expected: map[string]bool{"web-1": true, "db-1": true},
+		},
+		{
+			name:     "operand matching no node",
+			query:    "id=~cache-* or id==web-2",
+			expected: map[string]bool{"web-2": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := dispatcher.TargetedNodes(tt.query, proto.TargetMode_QUERY)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(result, tt.expected); diff != "" {
+				t.Errorf("Mismatch for query %q (-got +want):\n%s", tt.query, diff)
+			}
+		})
+	}
+}
+
+func TestTargetedNodesQueryPrecedence(t *testing.T) {
+	inv := inventory.New()
+	inv.DisableRegistryFile()
+	dispatcher := NewDispatcher[string, string](&inv)
+
+	specs := map[node.ID]map[string]any{
+		"linux-prod":  {"os": "linux", "env": "prod"},
+		"linux-dev":   {"os": "linux", "env": "dev"},
+		"darwin-prod": {"os": "darwin", "env": "prod"},
+		"darwin-dev":  {"os": "darwin", "env": "dev"},
+	}
+	for id, s := range specs {
+		_ = dispatcher.RegisterNode(id)
+		inv.MarkNodeStateChange(id, true)
+		_ = inv.SetSpec(id, s)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected map[string]bool
+	}{
+		{
+			name:  "unparenthesized, and binds tighter",
+			query: "specs.os==linux or specs.os==darwin and specs.env==prod",
+			// specs.os==linux or (specs.os==darwin and specs.env==prod)
+			expected: map[string]bool{"linux-prod": true, "linux-dev": true, "darwin-prod": true},
+		},
+		{
+			name:     "explicit and grouping",
+			query:    "specs.os==linux or (specs.os==darwin and specs.env==prod)",
+			expected: map[string]bool{"linux-prod": true, "linux-dev": true, "darwin-prod": true},
+		},
+		{
+			name:     "or grouping",
+			query:    "(specs.os==linux or specs.os==darwin) and specs.env==prod",
+			expected: map[string]bool{"linux-prod": true, "darwin-prod": true},
+		},
+		{
+			name:     "and first, unparenthesized",
+			query:    "specs.env==prod and specs.os==linux or specs.os==darwin",
+			expected: map[string]bool{"linux-prod": true, "darwin-prod": true, "darwin-dev": true},
+		},
+		{
+			name:     "and first, or grouping",
+			query:    "specs.env==prod and (specs.os==linux or specs.os==darwin)",
+			expected: map[string]bool{"linux-prod": true, "darwin-prod": true},
+		},
+		{
+			name:     "nested groups",
+			query:    "((specs.os==linux and specs.env==dev) or (specs.os==darwin and specs.env==prod))",
+			expected: map[string]bool{"linux-dev": true, "darwin-prod": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := dispatcher.TargetedNodes(tt.query, proto.TargetMode_QUERY)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(result, tt.expected); diff != "" {
+				t.Errorf("Mismatch for query %q (-got +want):\n%s", tt.query, diff)
+			}
+		})
+	}
+}
+
+func TestTargetedNodesQueryIn(t *testing.T) {
+	inv := inventory.New()
+	inv.DisableRegistryFile()
+	dispatcher := NewDispatcher[string, string](&inv)
+
+	specs := map[node.ID]map[string]any{
+		"web-1": {"region": "us-east", "cpu": 4},
+		"web-2": {"region": "us-west", "cpu": 8},
+		"web-3": {"region": "eu-west", "cpu": 8},
+	}
+	for id, s := range specs {
+		_ = dispatcher.RegisterNode(id)
+		inv.MarkNodeStateChange(id, true)
+		_ = inv.SetSpec(id, s)
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		expected    map[string]bool
+		expectError bool
+	}{
+		{
+			name:     "spec membership",
+			query:    "specs.region in [us-east, us-west]",
+			expected: map[string]bool{"web-1": true, "web-2": true},
+		},
+		{
+			name:     "numeric spec membership",
+			query:    "specs.cpu in [8, 16]",
+			expected: map[string]bool{"web-2": true, "web-3": true},
+		},
+		{
+			name:     "id membership",
+			query:    "id in [web-1, web-3, db-1]",
+			expected: map[string]bool{"web-1": true, "web-3": true},
+		},
+		{
+			name:     "membership combined",
+			query:    "id in [web-1, web-2] and specs.region in ['us-west', 'eu-west']",
+			expected: map[string]bool{"web-2": true},
+		},
+		{
+			name:        "empty list",
+			query:       "specs.region in []",
+			expectError: true,
+		},
+		{
+			name:        "no member",
+			query:       "specs.region in [ap-south]",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := dispatcher.TargetedNodes(tt.query, proto.TargetMode_QUERY)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got %v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(result, tt.expected); diff != "" {
+				t.Errorf("Mismatch for query %q (-got +want):\n%s", tt.query, diff)
+			}
+		})
+	}
+}
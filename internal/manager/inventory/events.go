@@ -0,0 +1,175 @@
+package inventory
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/node"
+)
+
+type NodeEventType string
+
+const (
+	NodeStale  NodeEventType = "node_stale"  // An accepted node is disconnected, or sent no message within the active threshold.
+	NodeActive NodeEventType = "node_active" // A stale node is connected and active again.
+
+	NodeSpecsDrift NodeEventType = "specs_drift" // The specs of a node changed between two collections.
+
+	NodeAutoAccepted NodeEventType = "node_auto_accepted" // A new node was accepted without operator approval.
+	NodeCandidate    NodeEventType = "node_candidate"     // A new node is waiting for its acceptance.
+
+	NodeConnected    NodeEventType = "node_connected"    // A node opened its connection, only sent to the watchers.
+	NodeDisconnected NodeEventType = "node_disconnected" // A node closed its connection, only sent to the watchers.
+)
+
+// NodeEvent describes an activity transition of an accepted node, a drift of its specs, its auto-acceptance, or a new
+// candidate node.
+type NodeEvent struct {
+	Type        NodeEventType `json:"event"`
+	Node        node.ID       `json:"node"`
+	Time        time.Time     `json:"time"`
+	LastMsg     time.Time     `json:"last_msg"`
+	Changes     []SpecChange  `json:"changes,omitempty"`     // Changed specs of a specs_drift event.
+	Address     string        `json:"address,omitempty"`     // Address of an auto-accepted or candidate node.
+	Fingerprint string        `json:"fingerprint,omitempty"` // Certificate fingerprint of an auto-accepted node, empty without mTLS.
+}
+
+// eventBus broadcasts the node events to the subscribers.
+type eventBus struct {
+	mutex       sync.Mutex
+	subscribers []chan NodeEvent
+}
+
+func (b *eventBus) subscribe(size int) <-chan NodeEvent {
+	ch := make(chan NodeEvent, size)
+	b.add(ch)
+	return ch
+}
+
+func (b *eventBus) add(ch chan NodeEvent) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.subscribers = append(b.subscribers, ch)
+}
+
+func (b *eventBus) remove(ch chan NodeEvent) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.subscribers = slices.DeleteFunc(b.subscribers, func(c chan NodeEvent) bool { return c == ch })
+}
+
+// publish sends the event without blocking, a subscriber too slow to consume its events loses them.
+func (b *eventBus) publish(e NodeEvent) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			slog.Warn("node event dropped", "error", "subscriber queue full", "event", e.Type, "node", e.Node)
+		}
+	}
+}
+
+// activity is the last reported activity state of a node.
+type activity struct {
+	active  bool
+	pending bool      // a transition is waiting for the debounce delay
+	since   time.Time // start of the pending transition
+}
+
+// Subscribe returns a channel receiving the node events, buffered with the given size.
+func (n *Nodes) Subscribe(size int) <-chan NodeEvent {
+	return n.events.subscribe(size)
+}
+
+// Watch returns a channel receiving the node events and the connection changes, buffered with the given size,
+// and the function to stop watching.
+func (n *Nodes) Watch(size int) (<-chan NodeEvent, func()) {
+	ch := make(chan NodeEvent, size)
+	n.events.add(ch)
+	n.states.add(ch)
+	return ch, func() {
+		n.events.remove(ch)
+		n.states.remove(ch)
+	}
+}
+
+// SetEventDebounce sets the delay during which a transition must last before its event is emitted.
+//
+// It prevents a flapping node from flooding the subscribers.
+func (n *Nodes) SetEventDebounce(debounce time.Duration) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.eventDebounce = debounce
+}
+
+// WatchActivity checks the activity of the accepted nodes every interval until the context is cancelled.
+//
+// Messages and connection changes are evaluated as they happen, but a node becoming stale sends
+// nothing: it is only detected by this periodic check.
+func (n *Nodes) WatchActivity(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			n.CheckActivity()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// CheckActivity emits the events of the accepted nodes whose activity changed.
+func (n *Nodes) CheckActivity() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	for id := range n.registry.Accepted {
+		n.updateActivity(id)
+	}
+}
+
+// updateActivity emits an event if the activity of an accepted node changed for longer than the debounce delay.
+//
+// Nothing is tracked until the node sends its first message, which only records its state: no event
+// is emitted on startup, nor for nodes which have not reconnected since.
+func (n *Nodes) updateActivity(id node.ID) {
+	if _, ok := n.registry.Accepted[id]; !ok {
+		return
+	}
+
+	now := n.clock.Now()
+	state := n.registry.States[id]
+	active := state.Connected && n.isActive(id, now)
+
+	a, known := n.activity[id]
+	switch {
+	case !known:
+		if !state.LastMsg.IsZero() {
+			n.activity[id] = activity{active: active}
+		}
+		return
+	case a.active == active:
+		n.activity[id] = activity{active: active} // back to the reported state, cancels a pending transition
+		return
+	case !a.pending:
+		a.pending = true
+		a.since = now
+	}
+
+	if now.Sub(a.since) < n.eventDebounce {
+		n.activity[id] = a
+		return
+	}
+
+	n.activity[id] = activity{active: active}
+	e := NodeEvent{Type: NodeStale, Node: id, Time: now, LastMsg: state.LastMsg}
+	if active {
+		e.Type = NodeActive
+	}
+	n.events.publish(e)
+}
@@ -0,0 +1,168 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackadi-io/jackadi/internal/clock"
+	"github.com/jackadi-io/jackadi/internal/node"
+)
+
+// newWatchedNodes returns an inventory with an accepted and connected node1, and a subscription to its events.
+func newWatchedNodes(t *testing.T, debounce time.Duration) (*Nodes, *clock.Fake, <-chan NodeEvent) {
+	t.Helper()
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	nodes := New()
+	nodes.DisableRegistryFile()
+	nodes.SetClock(fake)
+	nodes.SetActiveThreshold(10 * time.Second)
+	nodes.SetEventDebounce(debounce)
+	events := nodes.Subscribe(10)
+
+	nd := NodeIdentity{ID: node.ID("node1")}
+	_ = nodes.AddCandidate(nd)
+	if err := nodes.Register(nd, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	nodes.MarkNodeStateChange(nd.ID, true)
+	nodes.MarkNodeActive(nd.ID)
+
+	return &nodes, fake, events
+}
+
+func receivedEvents(events <-chan NodeEvent) []NodeEvent {
+	var out []NodeEvent
+	for {
+		select {
+		case e := <-events:
+			out = append(out, e)
+		default:
+			return out
+		}
+	}
+}
+
+func TestNodeEventsTransitions(t *testing.T) {
+	nodes, fake, events := newWatchedNodes(t, 30*time.Second)
+	lastMsg := fake.Now()
+
+	nodes.CheckActivity()
+	if got := receivedEvents(events); len(got) != 0 {
+		t.Fatalf("no event expected for an active node, got %v", got)
+	}
+
+	fake.Advance(11 * time.Second)
+	nodes.CheckActivity()
+	if got := receivedEvents(events); len(got) != 0 {
+		t.Fatalf("no event expected before the debounce delay, got %v", got)
+	}
+
+	fake.Advance(30 * time.Second)
+	nodes.CheckActivity()
+	nodes.CheckActivity()
+	got := receivedEvents(events)
+	if len(got) != 1 {
+		t.Fatalf("expected a single stale event, got %v", got)
+	}
+	want := NodeEvent{Type: NodeStale, Node: "node1", Time: fake.Now(), LastMsg: lastMsg}
+	if diff := cmp.Diff(want, got[0]); diff != "" {
+		t.Errorf("unexpected event (-want +got):\n%s", diff)
+	}
+
+	nodes.MarkNodeActive("node1")
+	fake.Advance(30 * time.Second)
+	nodes.MarkNodeActive("node1")
+	got = receivedEvents(events)
+	if len(got) != 1 || got[0].Type != NodeActive {
+		t.Fatalf("expected a single active event, got %v", got)
+	}
+}
+
+func TestNodeEventsDisconnection(t *testing.T) {
+	nodes, _, events := newWatchedNodes(t, 0)
+
+	nodes.MarkNodeStateChange("node1", false)
+	got := receivedEvents(events)
+	if len(got) != 1 || got[0].Type != NodeStale {
+		t.Fatalf("expected a stale event on disconnection, got %v", got)
+	}
+
+	nodes.MarkNodeStateChange("node1", true)
+	got = receivedEvents(events)
+	if len(got) != 1 || got[0].Type != NodeActive {
+		t.Fatalf("expected an active event on reconnection, got %v", got)
+	}
+}
+
+func TestNodeEventsFlappingDebounced(t *testing.T) {
+	nodes, fake, events := newWatchedNodes(t, 30*time.Second)
+
+	for range 5 {
+		nodes.MarkNodeStateChange("node1", false)
+		fake.Advance(10 * time.Second)
+		nodes.MarkNodeStateChange("node1", true)
+		nodes.MarkNodeActive("node1")
+		fake.Advance(10 * time.Second)
+		nodes.CheckActivity()
+	}
+
+	if got := receivedEvents(events); len(got) != 0 {
+		t.Errorf("no event expected for a flapping node, got %v", got)
+	}
+}
+
+func TestNodeEventsIgnoredNodes(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	nodes := New()
+	nodes.DisableRegistryFile()
+	nodes.SetClock(fake)
+	nodes.SetEventDebounce(0)
+	events := nodes.Subscribe(10)
+
+	// candidate
+	_ = nodes.AddCandidate(NodeIdentity{ID: "candidate"})
+	nodes.MarkNodeStateChange("candidate", true)
+	nodes.MarkNodeActive("candidate")
+	nodes.MarkNodeStateChange("candidate", false)
+
+	// accepted but never seen since startup
+	nd := NodeIdentity{ID: "node1"}
+	_ = nodes.AddCandidate(nd)
+	_ = nodes.Register(nd, false)
+	fake.Advance(time.Hour)
+	nodes.CheckActivity()
+
+	if got := receivedEvents(events); len(got) != 0 {
+		t.Errorf("no event expected, got %v", got)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	nodes, fake, subscribed := newWatchedNodes(t, 0)
+	watched, stop := nodes.Watch(10)
+
+	nodes.MarkNodeStateChange("node1", false)
+	fake.Advance(11 * time.Second)
+	nodes.CheckActivity()
+
+	var types []NodeEventType
+	for _, e := range receivedEvents(watched) {
+		types = append(types, e.Type)
+	}
+	if diff := cmp.Diff([]NodeEventType{NodeDisconnected, NodeStale}, types); diff != "" {
+		t.Errorf("unexpected watched events (-want +got):\n%s", diff)
+	}
+	for _, e := range receivedEvents(subscribed) {
+		if e.Type == NodeDisconnected {
+			t.Errorf("the connection changes must only be sent to the watchers")
+		}
+	}
+
+	stop()
+	nodes.MarkNodeStateChange("node1", true)
+	if got := receivedEvents(watched); len(got) != 0 {
+		t.Errorf("no event expected once stopped, got %v", got)
+	}
+}
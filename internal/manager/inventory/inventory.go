@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/jackadi-io/jackadi/internal/clock"
 	"github.com/jackadi-io/jackadi/internal/config"
 	"github.com/jackadi-io/jackadi/internal/node"
 	"github.com/jackadi-io/jackadi/internal/serializer"
@@ -28,11 +29,29 @@ func (e *RogueNodeError) Error() string {
 	return fmt.Sprintf("potential rogue node detected: diff between rogue and existing node: %+v", e.diffs)
 }
 
+// NewRogueNodeError returns an error describing the differences between an existing node and a node claiming its ID.
+func NewRogueNodeError(existing, rogue NodeIdentity) *RogueNodeError {
+	return &RogueNodeError{Compare(existing, rogue)}
+}
+
 type NodeState struct {
 	Connected bool
 	Since     time.Time
 	LastMsg   time.Time
-	specs     map[string]any
+	Version   string // Build version sent during the handshake.
+	Protocol  uint32 // Protocol version sent during the handshake, 0 for the nodes older than the version check.
+
+	// Connections counts the connections of the node, a node reconnecting often is flapping.
+	Connections int
+	specs       map[string]any
+}
+
+// Uptime returns the duration since the node connected, zero if it is disconnected.
+func (s NodeState) Uptime(now time.Time) time.Duration {
+	if !s.Connected || s.Since.IsZero() {
+		return 0
+	}
+	return now.Sub(s.Since)
 }
 
 func NewNodeState() NodeState {
@@ -54,6 +73,9 @@ type registry struct {
 	// Nodes which have been rejected manually
 	Rejected []NodeIdentity
 
+	// Drifts are the recent changes of the specs of the nodes.
+	Drifts map[node.ID][]SpecsDrift
+
 	// candidates are nodes which have not been registered yet.
 	candidates []NodeIdentity
 }
@@ -63,6 +85,14 @@ type Nodes struct {
 	registry             registry
 	registryPath         string
 	registryFileDisabled bool
+	clock                clock.Clock
+	activeThreshold      time.Duration
+	activity             map[node.ID]activity
+	events               *eventBus
+	states               *eventBus // connection changes, only sent to the watchers
+	eventDebounce        time.Duration
+	driftIgnore          []string // Globs of the volatile spec paths, their changes are not drifts.
+	pluginSyncs          map[node.ID][]PluginSync
 }
 
 func New() Nodes {
@@ -70,9 +100,17 @@ func New() Nodes {
 		mutex:                &sync.Mutex{},
 		registryPath:         config.RegistryFileName,
 		registryFileDisabled: false,
+		clock:                clock.Real{},
+		activeThreshold:      config.NodeActiveThreshold,
+		activity:             make(map[node.ID]activity),
+		events:               &eventBus{},
+		states:               &eventBus{},
+		eventDebounce:        config.NodeEventDebounce,
+		pluginSyncs:          make(map[node.ID][]PluginSync),
 		registry: registry{
 			Accepted: make(map[node.ID]NodeIdentity),
 			States:   make(map[node.ID]NodeState),
+			Drifts:   make(map[node.ID][]SpecsDrift),
 		},
 	}
 }
@@ -203,10 +241,40 @@ func (n *Nodes) Register(nd NodeIdentity, allowRejected bool) error {
 	return nil
 }
 
+// AutoAccept registers a new node without operator approval, and emits a NodeAutoAccepted event with its identity
+// and the fingerprint of its certificate so that the unexpected joins are noticed.
+func (n *Nodes) AutoAccept(nd NodeIdentity, fingerprint string) error {
+	if err := n.Register(nd, false); err != nil {
+		return err
+	}
+
+	n.mutex.Lock()
+	now := n.clock.Now()
+	n.mutex.Unlock()
+	n.events.publish(NodeEvent{
+		Type:        NodeAutoAccepted,
+		Node:        nd.ID,
+		Time:        now,
+		Address:     nd.Address,
+		Fingerprint: fingerprint,
+	})
+	return nil
+}
+
+// NotifyCandidate emits a NodeCandidate event for a new node waiting for its acceptance, so that the operators know
+// they have to accept it.
+func (n *Nodes) NotifyCandidate(nd NodeIdentity) {
+	n.mutex.Lock()
+	now := n.clock.Now()
+	n.mutex.Unlock()
+	n.events.publish(NodeEvent{Type: NodeCandidate, Node: nd.ID, Time: now, Address: nd.Address})
+}
+
 func (n *Nodes) unregister(nd NodeIdentity) error {
 	for name, registered := range n.registry.Accepted {
 		if registered == nd {
 			delete(n.registry.Accepted, name)
+			delete(n.activity, name)
 			if err := n.saveRegistryFile(); err != nil {
 				return fmt.Errorf("unable to permanently remove node: %w", err)
 			}
@@ -224,6 +292,8 @@ func (n *Nodes) unregister(nd NodeIdentity) error {
 }
 
 func (n *Nodes) removeStats(id node.ID) {
+	delete(n.registry.Drifts, id)
+	delete(n.pluginSyncs, id)
 	for name := range n.registry.States {
 		if name == id {
 			delete(n.registry.States, name)
@@ -290,6 +360,36 @@ func (n *Nodes) Remove(nd NodeIdentity) error {
 	return nil
 }
 
+// SetClock replaces the time source, mainly for testing purpose.
+func (n *Nodes) SetClock(c clock.Clock) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.clock = c
+}
+
+// SetActiveThreshold sets the delay without message after which a node is considered inactive.
+func (n *Nodes) SetActiveThreshold(threshold time.Duration) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.activeThreshold = threshold
+}
+
+// IsActive returns true if the node sent a message within the active threshold.
+func (n *Nodes) IsActive(id node.ID) bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	return n.isActive(id, n.clock.Now())
+}
+
+func (n *Nodes) isActive(id node.ID, now time.Time) bool {
+	state, ok := n.registry.States[id]
+	if !ok || state.LastMsg.IsZero() {
+		return false
+	}
+	return now.Sub(state.LastMsg) <= n.activeThreshold
+}
+
 func (n *Nodes) MarkNodeActive(id node.ID) {
 	n.mutex.Lock()
 	defer n.mutex.Unlock()
@@ -299,8 +399,9 @@ func (n *Nodes) MarkNodeActive(id node.ID) {
 		n.registry.States[id] = NewNodeState()
 	}
 
-	state.LastMsg = time.Now()
+	state.LastMsg = n.clock.Now()
 	n.registry.States[id] = state
+	n.updateActivity(id)
 }
 
 func (n *Nodes) MarkNodeStateChange(id node.ID, connected bool) {
@@ -312,11 +413,46 @@ func (n *Nodes) MarkNodeStateChange(id node.ID, connected bool) {
 		n.registry.States[id] = NewNodeState()
 	}
 
+	changed := state.Connected != connected
+	if changed && connected {
+		state.Connections++
+	}
 	state.Connected = connected
-	state.Since = time.Now()
+	state.Since = n.clock.Now()
+	n.registry.States[id] = state
+
+	if changed {
+		e := NodeEvent{Type: NodeDisconnected, Node: id, Time: state.Since, LastMsg: state.LastMsg}
+		if connected {
+			e.Type = NodeConnected
+		}
+		n.states.publish(e)
+	}
+	n.updateActivity(id)
+}
+
+// SetVersion records the build and protocol versions sent by the node during the handshake.
+func (n *Nodes) SetVersion(id node.ID, version string, protocol uint32) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	state, ok := n.registry.States[id]
+	if !ok {
+		state = NewNodeState()
+	}
+
+	state.Version = version
+	state.Protocol = protocol
 	n.registry.States[id] = state
 }
 
+// Protocol returns the protocol version sent by the node during the handshake, 0 if unknown.
+func (n *Nodes) Protocol(id node.ID) uint32 {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.registry.States[id].Protocol
+}
+
 func (n *Nodes) GetSpec(id node.ID) map[string]any {
 	n.mutex.Lock()
 	defer n.mutex.Unlock()
@@ -353,6 +489,7 @@ func (n *Nodes) SetSpec(id node.ID, specs map[string]any) error {
 		return fmt.Errorf("node not connected: %s %p", string(id), n)
 	}
 
+	n.recordDrift(id, state.specs, specs)
 	state.specs = specs
 	n.registry.States[id] = state
 
@@ -3,8 +3,11 @@ package inventory
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/jackadi-io/jackadi/internal/clock"
+	"github.com/jackadi-io/jackadi/internal/config"
 	"github.com/jackadi-io/jackadi/internal/node"
 )
 
@@ -351,6 +354,116 @@ func TestMarkNodeStateChange(t *testing.T) {
 	}
 }
 
+func TestMarkNodeStateChangeSince(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+
+	nodes := New()
+	nodes.DisableRegistryFile()
+	nodes.SetClock(fake)
+
+	nodes.MarkNodeStateChange("node1", true)
+	fake.Advance(time.Hour)
+	nodes.MarkNodeStateChange("node1", false)
+
+	_, _, _, states := nodes.List()
+	if got := states["node1"].Since; !got.Equal(start.Add(time.Hour)) {
+		t.Errorf("Since = %v, want %v", got, start.Add(time.Hour))
+	}
+}
+
+func TestConnectionsAndUptime(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+
+	nodes := New()
+	nodes.DisableRegistryFile()
+	nodes.SetClock(fake)
+
+	nodes.MarkNodeStateChange("node1", true)
+	nodes.MarkNodeStateChange("node1", true) // already connected: not a reconnection
+	fake.Advance(time.Hour)
+	nodes.MarkNodeStateChange("node1", false)
+
+	_, _, _, states := nodes.List()
+	if got := states["node1"].Connections; got != 1 {
+		t.Errorf("Connections = %d, want 1", got)
+	}
+	if got := states["node1"].Uptime(fake.Now()); got != 0 {
+		t.Errorf("Uptime of a disconnected node = %v, want 0", got)
+	}
+
+	fake.Advance(time.Minute)
+	nodes.MarkNodeStateChange("node1", true)
+	fake.Advance(10 * time.Minute)
+
+	_, _, _, states = nodes.List()
+	if got := states["node1"].Connections; got != 2 {
+		t.Errorf("Connections = %d, want 2", got)
+	}
+	if got := states["node1"].Uptime(fake.Now()); got != 10*time.Minute {
+		t.Errorf("Uptime = %v, want %v since the reconnection", got, 10*time.Minute)
+	}
+}
+
+func TestIsActive(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	nodes := New()
+	nodes.DisableRegistryFile()
+	nodes.SetClock(fake)
+
+	if nodes.IsActive("node1") {
+		t.Error("node without any message should not be active")
+	}
+
+	nodes.MarkNodeActive("node1")
+	if !nodes.IsActive("node1") {
+		t.Error("node should be active right after a message")
+	}
+
+	fake.Advance(config.NodeActiveThreshold)
+	if !nodes.IsActive("node1") {
+		t.Error("node should still be active at the threshold")
+	}
+
+	fake.Advance(time.Second)
+	if nodes.IsActive("node1") {
+		t.Error("node should be inactive after the threshold")
+	}
+
+	nodes.MarkNodeActive("node1")
+	if !nodes.IsActive("node1") {
+		t.Error("node should be active again after a new message")
+	}
+}
+
+func TestIsActiveCustomThreshold(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	nodes := New()
+	nodes.DisableRegistryFile()
+	nodes.SetClock(fake)
+
+	nodes.MarkNodeActive("node1")
+	fake.Advance(2 * config.NodeActiveThreshold)
+	if nodes.IsActive("node1") {
+		t.Error("node should be inactive with the default threshold")
+	}
+
+	nodes.SetActiveThreshold(5 * config.NodeActiveThreshold)
+	if !nodes.IsActive("node1") {
+		t.Error("node should be active with a larger threshold")
+	}
+
+	nodes.SetActiveThreshold(10 * time.Second)
+	nodes.MarkNodeActive("node1")
+	fake.Advance(11 * time.Second)
+	if nodes.IsActive("node1") {
+		t.Error("node should be inactive with a smaller threshold")
+	}
+}
+
 func TestCompare(t *testing.T) {
 	tests := []struct {
 		name  string
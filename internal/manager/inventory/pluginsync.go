@@ -0,0 +1,42 @@
+package inventory
+
+import (
+	"slices"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/node"
+)
+
+// PluginChange is a plugin added, updated, deleted or rejected by a plugin sync of a node.
+type PluginChange struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	File string `json:"file"`
+}
+
+// PluginSync is a plugin sync of a node which changed its plugins or failed.
+type PluginSync struct {
+	Time    time.Time      `json:"time"`
+	Changes []PluginChange `json:"changes"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// RecordPluginSync keeps the result of a plugin sync reported by a node, timestamped on reception.
+func (n *Nodes) RecordPluginSync(id node.ID, changes []PluginChange, syncErr string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	syncs := append(n.pluginSyncs[id], PluginSync{Time: n.clock.Now(), Changes: changes, Error: syncErr})
+	if len(syncs) > config.PluginSyncHistory {
+		syncs = syncs[len(syncs)-config.PluginSyncHistory:]
+	}
+	n.pluginSyncs[id] = syncs
+}
+
+// GetPluginSyncs returns the recent plugin syncs of a node, the oldest first.
+func (n *Nodes) GetPluginSyncs(id node.ID) []PluginSync {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return slices.Clone(n.pluginSyncs[id])
+}
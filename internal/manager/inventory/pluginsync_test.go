@@ -0,0 +1,37 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackadi-io/jackadi/internal/config"
+)
+
+func TestPluginSyncs(t *testing.T) {
+	nodes, fake, _ := newWatchedNodes(t, 30*time.Second)
+
+	if syncs := nodes.GetPluginSyncs("node1"); len(syncs) != 0 {
+		t.Fatalf("no plugin sync expected before any report, got %v", syncs)
+	}
+
+	changes := []PluginChange{{Type: "updated", Name: "docker", File: "docker"}}
+	nodes.RecordPluginSync("node1", changes, "")
+	fake.Advance(time.Minute)
+	nodes.RecordPluginSync("node1", nil, "failed to download the plugin")
+
+	want := []PluginSync{
+		{Time: fake.Now().Add(-time.Minute), Changes: changes},
+		{Time: fake.Now(), Error: "failed to download the plugin"},
+	}
+	if diff := cmp.Diff(want, nodes.GetPluginSyncs("node1")); diff != "" {
+		t.Errorf("unexpected plugin syncs (-want +got):\n%s", diff)
+	}
+
+	for range config.PluginSyncHistory + 5 {
+		nodes.RecordPluginSync("node1", changes, "")
+	}
+	if got := len(nodes.GetPluginSyncs("node1")); got != config.PluginSyncHistory {
+		t.Errorf("expected the last %d plugin syncs to be kept, got %d", config.PluginSyncHistory, got)
+	}
+}
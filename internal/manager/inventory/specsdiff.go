@@ -0,0 +1,182 @@
+package inventory
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/node"
+)
+
+type SpecChangeType string
+
+const (
+	SpecAdded   SpecChangeType = "added"
+	SpecRemoved SpecChangeType = "removed"
+	SpecChanged SpecChangeType = "changed"
+)
+
+// SpecChange describes the change of a spec leaf, identified by its dot path (e.g. software.nginx.version).
+type SpecChange struct {
+	Type SpecChangeType `json:"type"`
+	Path string         `json:"path"`
+	Old  any            `json:"old,omitempty"`
+	New  any            `json:"new,omitempty"`
+}
+
+func (c SpecChange) String() string {
+	switch c.Type {
+	case SpecAdded:
+		return fmt.Sprintf("%s added: %v", c.Path, c.New)
+	case SpecRemoved:
+		return fmt.Sprintf("%s removed: %v", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+	}
+}
+
+// SpecsDrift is a change of the specs of a node between two collections.
+type SpecsDrift struct {
+	Time    time.Time    `json:"time"`
+	Changes []SpecChange `json:"changes"`
+}
+
+// DiffSpecs returns the changes of the leaves between two specs maps, sorted by path.
+//
+// The specs of a failed collector are replaced by an error marker: they are unknown rather than removed, so
+// they are not compared.
+func DiffSpecs(before, after map[string]any) []SpecChange {
+	changes := diffSpecs("", before, after)
+	slices.SortFunc(changes, func(a, b SpecChange) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return changes
+}
+
+func diffSpecs(prefix string, before, after map[string]any) []SpecChange {
+	var changes []SpecChange
+	for _, key := range slices.Sorted(maps.Keys(before)) {
+		if _, ok := after[key]; !ok {
+			changes = append(changes, leaves(SpecRemoved, prefix+key, before[key])...)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(after)) {
+		path := prefix + key
+		oldVal, ok := before[key]
+		newVal := after[key]
+		if !ok {
+			changes = append(changes, leaves(SpecAdded, path, newVal)...)
+			continue
+		}
+		if failedSpec(oldVal) || failedSpec(newVal) {
+			continue
+		}
+
+		oldMap, oldIsMap := oldVal.(map[string]any)
+		newMap, newIsMap := newVal.(map[string]any)
+		switch {
+		case oldIsMap && newIsMap:
+			changes = append(changes, diffSpecs(path+".", oldMap, newMap)...)
+		case !reflect.DeepEqual(oldVal, newVal):
+			changes = append(changes, SpecChange{Type: SpecChanged, Path: path, Old: oldVal, New: newVal})
+		}
+	}
+	return changes
+}
+
+// leaves returns a change for each leaf of an added or removed value.
+func leaves(t SpecChangeType, path string, val any) []SpecChange {
+	if failedSpec(val) {
+		return nil
+	}
+	m, ok := val.(map[string]any)
+	if !ok {
+		if t == SpecAdded {
+			return []SpecChange{{Type: t, Path: path, New: val}}
+		}
+		return []SpecChange{{Type: t, Path: path, Old: val}}
+	}
+
+	var changes []SpecChange
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		changes = append(changes, leaves(t, path+"."+key, m[key])...)
+	}
+	return changes
+}
+
+// failedSpec returns true if the value is the error marker of a failed collector.
+func failedSpec(val any) bool {
+	m, ok := val.(map[string]any)
+	if !ok {
+		return false
+	}
+	_, failed := m[config.SpecErrorKey]
+	return failed
+}
+
+// GetSpecsDrifts returns the recent changes of the specs of a node, the oldest first.
+func (n *Nodes) GetSpecsDrifts(id node.ID) []SpecsDrift {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return slices.Clone(n.registry.Drifts[id])
+}
+
+// SetSpecsDriftIgnore sets the globs of the volatile spec paths (e.g. hardware.memory.free), their changes and the
+// changes of their sub-paths are not drifts.
+func (n *Nodes) SetSpecsDriftIgnore(globs []string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.driftIgnore = globs
+}
+
+// ignoredSpec returns true if the spec path, or one of its parents, matches a glob of the volatile specs.
+func (n *Nodes) ignoredSpec(specPath string) bool {
+	for {
+		for _, pattern := range n.driftIgnore {
+			if ok, _ := path.Match(pattern, specPath); ok {
+				return true
+			}
+		}
+		i := strings.LastIndex(specPath, ".")
+		if i < 0 {
+			return false
+		}
+		specPath = specPath[:i]
+	}
+}
+
+// recordDrift keeps the changes of the specs of a node in the registry, and notifies them to the subscribers.
+//
+// The first collection of a node is not a drift, nor the changes of the volatile specs.
+func (n *Nodes) recordDrift(id node.ID, before, after map[string]any) {
+	if len(before) == 0 {
+		return
+	}
+	changes := slices.DeleteFunc(DiffSpecs(before, after), func(c SpecChange) bool {
+		return n.ignoredSpec(c.Path)
+	})
+	if len(changes) == 0 {
+		return
+	}
+
+	now := n.clock.Now()
+	if n.registry.Drifts == nil { // registry file written before the drifts
+		n.registry.Drifts = make(map[node.ID][]SpecsDrift)
+	}
+	drifts := append(n.registry.Drifts[id], SpecsDrift{Time: now, Changes: changes})
+	if len(drifts) > config.SpecsDriftHistory {
+		drifts = drifts[len(drifts)-config.SpecsDriftHistory:]
+	}
+	n.registry.Drifts[id] = drifts
+	if err := n.saveRegistryFile(); err != nil {
+		slog.Error("failed to save the specs drift in the registry", "node", id, "error", err)
+	}
+
+	n.events.publish(NodeEvent{Type: NodeSpecsDrift, Node: id, Time: now, LastMsg: n.registry.States[id].LastMsg, Changes: changes})
+}
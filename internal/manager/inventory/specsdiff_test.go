@@ -0,0 +1,155 @@
+package inventory
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackadi-io/jackadi/internal/config"
+)
+
+func TestDiffSpecs(t *testing.T) {
+	before := map[string]any{
+		"os": map[string]any{"family": "debian", "release": json.Number("12")},
+		"software": map[string]any{
+			"nginx": map[string]any{"version": "1.26"},
+			"redis": map[string]any{"version": "7.0", "port": json.Number("6379")},
+		},
+		"network": map[string]any{"ips": []any{"10.0.0.1"}},
+		"disks":   map[string]any{"sda": "512G"},
+		"mounts":  map[string]any{config.SpecErrorKey: "timeout"},
+	}
+	after := map[string]any{
+		"os": map[string]any{"family": "debian", "release": json.Number("12")},
+		"software": map[string]any{
+			"nginx":    map[string]any{"version": "1.27"},
+			"postgres": map[string]any{"version": "16"},
+		},
+		"network": map[string]any{"ips": []any{"10.0.0.1", "10.0.0.2"}},
+		"disks":   map[string]any{config.SpecErrorKey: "timeout"}, // failed collector: unknown, not removed
+		"mounts":  map[string]any{"/": "ext4"},                    // collector back: not added
+		"kernel":  "6.1",
+	}
+
+	want := []SpecChange{
+		{Type: SpecAdded, Path: "kernel", New: "6.1"},
+		{Type: SpecChanged, Path: "network.ips", Old: []any{"10.0.0.1"}, New: []any{"10.0.0.1", "10.0.0.2"}},
+		{Type: SpecChanged, Path: "software.nginx.version", Old: "1.26", New: "1.27"},
+		{Type: SpecAdded, Path: "software.postgres.version", New: "16"},
+		{Type: SpecRemoved, Path: "software.redis.port", Old: json.Number("6379")},
+		{Type: SpecRemoved, Path: "software.redis.version", Old: "7.0"},
+	}
+	if diff := cmp.Diff(want, DiffSpecs(before, after)); diff != "" {
+		t.Errorf("unexpected changes (-want +got):\n%s", diff)
+	}
+
+	if changes := DiffSpecs(before, before); len(changes) != 0 {
+		t.Errorf("no change expected between identical specs, got %v", changes)
+	}
+}
+
+func TestSpecsDrift(t *testing.T) {
+	nodes, fake, events := newWatchedNodes(t, 30*time.Second)
+
+	v1 := map[string]any{"software": map[string]any{"nginx": "1.26"}}
+	v2 := map[string]any{"software": map[string]any{"nginx": "1.27"}}
+
+	if err := nodes.SetSpec("node1", v1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := nodes.SetSpec("node1", v1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := receivedEvents(events); len(got) != 0 {
+		t.Fatalf("no event expected for the first collection nor for unchanged specs, got %v", got)
+	}
+
+	fake.Advance(time.Minute)
+	if err := nodes.SetSpec("node1", v2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	changes := []SpecChange{{Type: SpecChanged, Path: "software.nginx", Old: "1.26", New: "1.27"}}
+	if diff := cmp.Diff([]SpecsDrift{{Time: fake.Now(), Changes: changes}}, nodes.GetSpecsDrifts("node1")); diff != "" {
+		t.Errorf("unexpected drifts (-want +got):\n%s", diff)
+	}
+	got := receivedEvents(events)
+	if len(got) != 1 || got[0].Type != NodeSpecsDrift {
+		t.Fatalf("expected a single specs drift event, got %v", got)
+	}
+	if diff := cmp.Diff(changes, got[0].Changes); diff != "" {
+		t.Errorf("unexpected changes of the event (-want +got):\n%s", diff)
+	}
+
+	for i := range config.SpecsDriftHistory + 5 {
+		specs := v1
+		if i%2 == 0 {
+			specs = v2
+		}
+		_ = nodes.SetSpec("node1", specs)
+	}
+	if got := len(nodes.GetSpecsDrifts("node1")); got != config.SpecsDriftHistory {
+		t.Errorf("expected the last %d drifts to be kept, got %d", config.SpecsDriftHistory, got)
+	}
+}
+
+func TestSpecsDriftIgnore(t *testing.T) {
+	nodes, _, events := newWatchedNodes(t, 30*time.Second)
+	nodes.SetSpecsDriftIgnore([]string{"hardware.memory", "*.uptime"})
+
+	v1 := map[string]any{
+		"hardware": map[string]any{"memory": map[string]any{"free": "1G", "total": "8G"}},
+		"host":     map[string]any{"uptime": "10s"},
+		"kernel":   "6.1",
+	}
+	v2 := map[string]any{
+		"hardware": map[string]any{"memory": map[string]any{"free": "2G", "total": "8G"}},
+		"host":     map[string]any{"uptime": "20s"},
+		"kernel":   "6.1",
+	}
+	v3 := map[string]any{
+		"hardware": map[string]any{"memory": map[string]any{"free": "3G", "total": "8G"}},
+		"host":     map[string]any{"uptime": "30s"},
+		"kernel":   "6.2",
+	}
+
+	_ = nodes.SetSpec("node1", v1)
+	_ = nodes.SetSpec("node1", v2)
+	if got := receivedEvents(events); len(got) != 0 {
+		t.Fatalf("no event expected for the changes of the volatile specs, got %v", got)
+	}
+	if got := nodes.GetSpecsDrifts("node1"); len(got) != 0 {
+		t.Fatalf("no drift expected for the changes of the volatile specs, got %v", got)
+	}
+
+	_ = nodes.SetSpec("node1", v3)
+	changes := []SpecChange{{Type: SpecChanged, Path: "kernel", Old: "6.1", New: "6.2"}}
+	got := receivedEvents(events)
+	if len(got) != 1 {
+		t.Fatalf("expected a single specs drift event, got %v", got)
+	}
+	if diff := cmp.Diff(changes, got[0].Changes); diff != "" {
+		t.Errorf("unexpected changes of the event (-want +got):\n%s", diff)
+	}
+}
+
+func TestSpecsDriftPersisted(t *testing.T) {
+	nodes, fake, _ := newWatchedNodes(t, 30*time.Second)
+	nodes.registryFileDisabled = false
+	nodes.registryPath = filepath.Join(t.TempDir(), config.RegistryFileName)
+
+	_ = nodes.SetSpec("node1", map[string]any{"kernel": "6.1"})
+	_ = nodes.SetSpec("node1", map[string]any{"kernel": "6.2"})
+
+	reloaded := New()
+	reloaded.registryPath = nodes.registryPath
+	if err := reloaded.LoadRegistry(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changes := []SpecChange{{Type: SpecChanged, Path: "kernel", Old: "6.1", New: "6.2"}}
+	if diff := cmp.Diff([]SpecsDrift{{Time: fake.Now(), Changes: changes}}, reloaded.GetSpecsDrifts("node1")); diff != "" {
+		t.Errorf("unexpected drifts after reload (-want +got):\n%s", diff)
+	}
+}
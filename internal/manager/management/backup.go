@@ -0,0 +1,119 @@
+package management
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// backupWriter sends the data written by Badger as backup chunks of BackupChunkSize at most.
+type backupWriter struct {
+	stream grpc.ServerStreamingServer[proto.BackupChunk]
+}
+
+func (w backupWriter) Write(p []byte) (int, error) {
+	written := 0
+	for chunk := range slices.Chunk(p, config.BackupChunkSize) {
+		if err := w.stream.Send(&proto.BackupChunk{Data: chunk}); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+	}
+	return written, nil
+}
+
+// restoreReader reads the backup from the received chunks.
+type restoreReader struct {
+	stream grpc.ClientStreamingServer[proto.RestoreChunk, proto.RestoreResponse]
+	buf    []byte
+}
+
+func (r *restoreReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk, err := r.stream.Recv()
+		if err != nil {
+			return 0, err // io.EOF at the end of the stream
+		}
+		r.buf = chunk.GetData()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Backup streams a backup of the database, in Badger backup format.
+//
+// The backup is sent by chunks, it is never fully loaded in memory.
+func (a *apiServer) Backup(req *proto.BackupRequest, stream grpc.ServerStreamingServer[proto.BackupChunk]) error {
+	w := bufio.NewWriterSize(backupWriter{stream}, config.BackupChunkSize)
+	version, err := a.db.Backup(w, req.GetSince())
+	if err != nil {
+		return status.Errorf(codes.Internal, "backup failed: %s", err)
+	}
+	if err := w.Flush(); err != nil {
+		return status.Errorf(codes.Internal, "backup failed: %s", err)
+	}
+
+	slog.Info("database backup done", "since", req.GetSince(), "version", version)
+	return nil
+}
+
+// Restore loads a backup streamed by chunks into the database.
+//
+// It refuses to restore into a non-empty database, unless force is set in the first chunk: the
+// database is then emptied before the restoration.
+func (a *apiServer) Restore(stream grpc.ClientStreamingServer[proto.RestoreChunk, proto.RestoreResponse]) error {
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "empty backup")
+	}
+	if err != nil {
+		return err
+	}
+
+	empty, err := a.isEmpty()
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to check the database: %s", err)
+	}
+	if !empty {
+		if !first.GetForce() {
+			return status.Error(codes.FailedPrecondition, "database is not empty, force is required to replace its content")
+		}
+		slog.Warn("dropping database content before restoration")
+		if err := a.db.DropAll(); err != nil {
+			return status.Errorf(codes.Internal, "unable to empty the database: %s", err)
+		}
+	}
+
+	r := &restoreReader{stream: stream, buf: first.GetData()}
+	if err := a.db.Load(r, config.RestoreMaxPendingWrites); err != nil {
+		return status.Errorf(codes.Internal, "restore failed: %s", err)
+	}
+
+	slog.Info("database restored")
+	return stream.SendAndClose(&proto.RestoreResponse{})
+}
+
+func (a *apiServer) isEmpty() (bool, error) {
+	empty := true
+	err := a.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		it.Rewind()
+		empty = !it.Valid()
+		return nil
+	})
+	return empty, err
+}
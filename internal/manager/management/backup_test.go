@@ -0,0 +1,126 @@
+package management
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/node"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves the API on top of db, and returns a client connected to it.
+func newTestClient(t *testing.T, db *badger.DB) proto.APIClient {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	api := New(nil, db)
+	proto.RegisterAPIServer(srv, &api)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return proto.NewAPIClient(conn)
+}
+
+func backupTo(t *testing.T, client proto.APIClient) []byte {
+	t.Helper()
+	stream, err := client.Backup(context.Background(), &proto.BackupRequest{})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return buf.Bytes()
+		}
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(chunk.GetData()), config.BackupChunkSize)
+		buf.Write(chunk.GetData())
+	}
+}
+
+func restoreFrom(t *testing.T, client proto.APIClient, data []byte, force bool) error {
+	t.Helper()
+	stream, err := client.Restore(context.Background())
+	require.NoError(t, err)
+
+	first := true
+	for len(data) > 0 {
+		n := min(len(data), 1000) // small chunks to exercise the reassembly
+		if err := stream.Send(&proto.RestoreChunk{Data: data[:n], Force: first && force}); err != nil {
+			break
+		}
+		data = data[n:]
+		first = false
+	}
+	_, err = stream.CloseAndRecv()
+	return err
+}
+
+func TestBackupRestore(t *testing.T) {
+	src := newTestDB(t)
+	storeGroup(t, src, 100, map[int64]node.ID{101: "web-1", 102: "web-2"})
+	for i := range int64(200) {
+		storeGroup(t, src, 1000+i*10, map[int64]node.ID{1000 + i*10 + 1: "db-1"})
+	}
+
+	backup := backupTo(t, newTestClient(t, src))
+	require.NotEmpty(t, backup)
+
+	dst := newTestDB(t)
+	require.NoError(t, restoreFrom(t, newTestClient(t, dst), backup, false))
+
+	for _, id := range []int64{100, 101, 102, 1000, 1001, 2991} {
+		want, ok := get(t, src, id)
+		require.True(t, ok, "%d missing in the source", id)
+		got, ok := get(t, dst, id)
+		require.True(t, ok, "%d not restored", id)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestRestoreNonEmptyDatabase(t *testing.T) {
+	src := newTestDB(t)
+	storeGroup(t, src, 100, map[int64]node.ID{101: "web-1"})
+	backup := backupTo(t, newTestClient(t, src))
+
+	dst := newTestDB(t)
+	storeGroup(t, dst, 200, map[int64]node.ID{201: "web-2"})
+	client := newTestClient(t, dst)
+
+	err := restoreFrom(t, client, backup, false)
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, ok := get(t, dst, 101)
+	assert.False(t, ok, "nothing must be restored without force")
+
+	require.NoError(t, restoreFrom(t, client, backup, true))
+	_, ok = get(t, dst, 101)
+	assert.True(t, ok)
+	_, ok = get(t, dst, 201)
+	assert.False(t, ok, "forced restoration replaces the database content")
+}
+
+func TestRestoreEmptyBackup(t *testing.T) {
+	client := newTestClient(t, newTestDB(t))
+	err := restoreFrom(t, client, nil, false)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
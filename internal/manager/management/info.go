@@ -0,0 +1,33 @@
+package management
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// BuildInfo describes the build of the running manager.
+type BuildInfo struct {
+	Version string
+	Commit  string
+	Date    string
+}
+
+// SetBuildInfo sets the build information returned by ServerInfo.
+func (a *apiServer) SetBuildInfo(info BuildInfo) {
+	a.build = info
+}
+
+// ServerInfo returns the build information and the uptime of the manager.
+func (a *apiServer) ServerInfo(ctx context.Context, _ *emptypb.Empty) (*proto.ServerInfoResponse, error) {
+	return &proto.ServerInfoResponse{
+		Version:   a.build.Version,
+		Commit:    a.build.Commit,
+		BuildDate: a.build.Date,
+		StartedAt: timestamppb.New(a.startedAt),
+		Uptime:    int64(time.Since(a.startedAt).Seconds()),
+	}, nil
+}
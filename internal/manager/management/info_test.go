@@ -0,0 +1,25 @@
+package management
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestServerInfo(t *testing.T) {
+	api := New(nil, newTestDB(t))
+	api.SetBuildInfo(BuildInfo{Version: "1.2.3", Commit: "abc1234", Date: "2026-01-02T03:04:05Z"})
+	api.startedAt = time.Now().Add(-90 * time.Second)
+
+	resp, err := api.ServerInfo(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", resp.GetVersion())
+	assert.Equal(t, "abc1234", resp.GetCommit())
+	assert.Equal(t, "2026-01-02T03:04:05Z", resp.GetBuildDate())
+	assert.True(t, resp.GetStartedAt().AsTime().Equal(api.startedAt))
+	assert.InDelta(t, 90, resp.GetUptime(), 1)
+}
@@ -5,13 +5,20 @@ import (
 	"errors"
 	"log/slog"
 	"slices"
+	"time"
 
-	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
+	"github.com/jackadi-io/jackadi/internal/manager/forwarder"
 	"github.com/jackadi-io/jackadi/internal/manager/inventory"
 	"github.com/jackadi-io/jackadi/internal/node"
 	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -20,22 +27,40 @@ import (
 type ServerInterface interface {
 	RequestShutdown(nodeID node.ID) error
 	GetInventory() *inventory.Nodes
+	Version() string
+	RunningTasks(task string) []*proto.RunningTask
 }
 
 type apiServer struct {
 	proto.UnimplementedAPIServer
-	server ServerInterface
-	db     *badger.DB
+	server    ServerInterface
+	db        database.ResultStore
+	gc        *database.GarbageCollector
+	locks     *forwarder.LockTracker
+	build     BuildInfo
+	startedAt time.Time
 }
 
-func New(server ServerInterface, db *badger.DB) apiServer {
+func New(server ServerInterface, db database.ResultStore) apiServer {
 	return apiServer{
-		server: server,
-		db:     db,
+		server:    server,
+		db:        db,
+		startedAt: time.Now(),
 	}
 }
 
+// SetGarbageCollector sets the garbage collector whose last outcome is reported by DatabaseStats.
+func (a *apiServer) SetGarbageCollector(gc *database.GarbageCollector) {
+	a.gc = gc
+}
+
+// SetLockTracker sets the lock tracker whose stats are reported by LockStats.
+func (a *apiServer) SetLockTracker(locks *forwarder.LockTracker) {
+	a.locks = locks
+}
+
 func toProtoNodeSlice(nodes []inventory.NodeIdentity, nodesState map[node.ID]inventory.NodeState) []*proto.NodeInfo {
+	now := time.Now()
 	resp := make([]*proto.NodeInfo, 0, len(nodes))
 	for _, nd := range nodes {
 		addr, cert := nd.Address, nd.Certificate
@@ -49,6 +74,13 @@ func toProtoNodeSlice(nodes []inventory.NodeIdentity, nodesState map[node.ID]inv
 			info.IsConnected = &state.Connected
 			info.Since = timestamppb.New(state.Since)
 			info.LastMsg = timestamppb.New(state.LastMsg)
+			if state.Version != "" {
+				info.Version = &state.Version
+			}
+			connections := int64(state.Connections)
+			uptime := int64(state.Uptime(now).Seconds())
+			info.Connections = &connections
+			info.Uptime = &uptime
 		}
 
 		resp = append(resp, &info)
@@ -64,9 +96,10 @@ func toProtoNodeSlice(nodes []inventory.NodeIdentity, nodesState map[node.ID]inv
 func (a *apiServer) ListNodes(ctx context.Context, req *proto.ListNodesRequest) (*proto.ListNodesResponse, error) {
 	accepted, candidates, rejected, states := a.server.GetInventory().List()
 	return &proto.ListNodesResponse{
-		Accepted:   toProtoNodeSlice(accepted, states),
-		Candidates: toProtoNodeSlice(candidates, states),
-		Rejected:   toProtoNodeSlice(rejected, states),
+		Accepted:       toProtoNodeSlice(accepted, states),
+		Candidates:     toProtoNodeSlice(candidates, states),
+		Rejected:       toProtoNodeSlice(rejected, states),
+		ManagerVersion: a.server.Version(),
 	}, nil
 }
 
@@ -128,6 +161,89 @@ func (a *apiServer) RemoveNode(ctx context.Context, req *proto.NodeRequest) (*pr
 	}, errs
 }
 
+// WatchNodes streams the connection changes, the activity changes and the specs drifts of the nodes, until the
+// client cancels it.
+//
+// The events are dropped if the client is too slow to receive them.
+func (a *apiServer) WatchNodes(_ *emptypb.Empty, stream grpc.ServerStreamingServer[proto.NodeStateEvent]) error {
+	events, stop := a.server.GetInventory().Watch(config.NotificationQueueSize)
+	defer stop()
+
+	// the headers tell the client that the events are watched
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case e := <-events:
+			err := stream.Send(&proto.NodeStateEvent{
+				Type: string(e.Type),
+				Node: string(e.Node),
+				Time: timestamppb.New(e.Time),
+			})
+			if err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// SpecsDrifts returns the recent changes of the specs of an accepted node, the oldest first.
+//
+// They are kept in memory: the changes before the manager start are unknown.
+func (a *apiServer) SpecsDrifts(ctx context.Context, req *proto.SpecsDriftsRequest) (*proto.SpecsDriftsResponse, error) {
+	id := node.ID(req.GetNode())
+	if len(a.server.GetInventory().GetMatchingAccepted(id, nil, nil)) == 0 {
+		return nil, status.Error(codes.NotFound, inventory.ErrNodeNotFound.Error())
+	}
+
+	resp := &proto.SpecsDriftsResponse{}
+	for _, drift := range a.server.GetInventory().GetSpecsDrifts(id) {
+		d := &proto.SpecsDrift{Time: timestamppb.New(drift.Time)}
+		for _, c := range drift.Changes {
+			change := &proto.SpecChange{Type: string(c.Type), Path: c.Path}
+			var err error
+			if c.Type != inventory.SpecAdded {
+				if change.Old, err = serializer.JSON.Marshal(c.Old); err != nil {
+					return nil, status.Errorf(codes.Internal, "failed to serialize the spec %s: %s", c.Path, err)
+				}
+			}
+			if c.Type != inventory.SpecRemoved {
+				if change.New, err = serializer.JSON.Marshal(c.New); err != nil {
+					return nil, status.Errorf(codes.Internal, "failed to serialize the spec %s: %s", c.Path, err)
+				}
+			}
+			d.Changes = append(d.Changes, change)
+		}
+		resp.Drifts = append(resp.Drifts, d)
+	}
+	return resp, nil
+}
+
+// PluginSyncs returns the recent plugin syncs of an accepted node which changed its plugins or failed, the oldest
+// first.
+//
+// They are kept in memory: the syncs before the manager start are unknown.
+func (a *apiServer) PluginSyncs(ctx context.Context, req *proto.PluginSyncsRequest) (*proto.PluginSyncsResponse, error) {
+	id := node.ID(req.GetNode())
+	if len(a.server.GetInventory().GetMatchingAccepted(id, nil, nil)) == 0 {
+		return nil, status.Error(codes.NotFound, inventory.ErrNodeNotFound.Error())
+	}
+
+	resp := &proto.PluginSyncsResponse{}
+	for _, sync := range a.server.GetInventory().GetPluginSyncs(id) {
+		s := &proto.PluginSync{Time: timestamppb.New(sync.Time), Error: sync.Error}
+		for _, c := range sync.Changes {
+			s.Changes = append(s.Changes, &proto.PluginChange{Type: c.Type, Name: c.Name, File: c.File})
+		}
+		resp.Syncs = append(resp.Syncs, s)
+	}
+	return resp, nil
+}
+
 // RejectNode places a node in the rejected list.
 //
 // If the node is registered, it disconnects the node.
@@ -0,0 +1,97 @@
+package management
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/node"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type fakeServer struct {
+	inventory *inventory.Nodes
+}
+
+func (s *fakeServer) RequestShutdown(node.ID) error                 { return nil }
+func (s *fakeServer) GetInventory() *inventory.Nodes                { return s.inventory }
+func (s *fakeServer) Version() string                               { return "test" }
+func (s *fakeServer) RunningTasks(task string) []*proto.RunningTask { return nil }
+
+// newInventoryClient returns a client of an API server using the inventory.
+func newInventoryClient(t *testing.T, inv *inventory.Nodes) proto.APIClient {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	api := New(&fakeServer{inventory: inv}, nil)
+	proto.RegisterAPIServer(srv, &api)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return proto.NewAPIClient(conn)
+}
+
+func TestWatchNodes(t *testing.T) {
+	inv := inventory.New()
+	inv.DisableRegistryFile()
+	client := newInventoryClient(t, &inv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.WatchNodes(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+
+	// the stream is watching once its headers are received
+	_, err = stream.Header()
+	require.NoError(t, err)
+	inv.MarkNodeStateChange("web-1", true)
+
+	e, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, string(inventory.NodeConnected), e.GetType())
+	assert.Equal(t, "web-1", e.GetNode())
+
+	inv.MarkNodeStateChange("web-1", true) // unchanged: no event
+	inv.MarkNodeStateChange("web-1", false)
+	e, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, string(inventory.NodeDisconnected), e.GetType())
+	assert.Equal(t, "web-1", e.GetNode())
+}
+
+func TestPluginSyncs(t *testing.T) {
+	inv := inventory.New()
+	inv.DisableRegistryFile()
+	client := newInventoryClient(t, &inv)
+
+	_, err := client.PluginSyncs(context.Background(), &proto.PluginSyncsRequest{Node: "web-1"})
+	assert.Equal(t, codes.NotFound, status.Code(err), "expected an unknown node to be not found")
+
+	nd := inventory.NodeIdentity{ID: "web-1"}
+	_ = inv.AddCandidate(nd)
+	require.NoError(t, inv.Register(nd, false))
+	inv.RecordPluginSync("web-1", []inventory.PluginChange{{Type: "added", Name: "docker", File: "docker"}}, "")
+
+	resp, err := client.PluginSyncs(context.Background(), &proto.PluginSyncsRequest{Node: "web-1"})
+	require.NoError(t, err)
+	require.Len(t, resp.GetSyncs(), 1)
+	assert.False(t, resp.GetSyncs()[0].GetTime().AsTime().IsZero())
+	require.Len(t, resp.GetSyncs()[0].GetChanges(), 1)
+	change := resp.GetSyncs()[0].GetChanges()[0]
+	assert.Equal(t, []string{"added", "docker", "docker"}, []string{change.GetType(), change.GetName(), change.GetFile()})
+}
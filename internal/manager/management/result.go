@@ -3,6 +3,7 @@ package management
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -12,6 +13,9 @@ import (
 	"github.com/jackadi-io/jackadi/internal/node"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"github.com/jackadi-io/jackadi/internal/serializer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // GetRequest searches for a request ID in the local KV store, and returns the request.
@@ -72,6 +76,8 @@ func (a *apiServer) GetResults(ctx context.Context, req *proto.ResultsRequest) (
 // - Pagination through offset and limit parameters.
 // - Date range filtering through from_date and to_date parameters.
 // - Node filtering through targets parameter.
+// - Request metadata filtering through metadata parameter.
+// - Outcome filtering through statuses and retcode parameters.
 func (a *apiServer) ListResults(ctx context.Context, req *proto.ListResultsRequest) (*proto.ListResultsResponse, error) {
 	resultEntries := []*proto.ResultEntry{}
 
@@ -80,7 +86,41 @@ func (a *apiServer) ListResults(ctx context.Context, req *proto.ListResultsReque
 		limit = min(req.Limit, config.ResultsPageLimit)
 	}
 
-	err := a.db.View(func(txn *badger.Txn) error {
+	err := a.listResults(req, limit, func(entry *proto.ResultEntry) error {
+		resultEntries = append(resultEntries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.ListResultsResponse{
+		Results: resultEntries,
+	}, nil
+}
+
+// StreamResults is ListResults sending each result as soon as it is read, so that the memory used does not
+// depend on the limit, up to config.MaxResultsLimit results.
+func (a *apiServer) StreamResults(req *proto.ListResultsRequest, stream grpc.ServerStreamingServer[proto.ResultEntry]) error {
+	limit := int32(config.ResultsLimit)
+	if req.Limit > 0 {
+		limit = min(req.Limit, config.MaxResultsLimit)
+	}
+	return a.listResults(req, limit, stream.Send)
+}
+
+// listResults passes the results matching the filters of the request to emit, the most recent first, and stops
+// at the first error of emit.
+func (a *apiServer) listResults(req *proto.ListResultsRequest, limit int32, emit func(*proto.ResultEntry) error) error {
+	statuses := make(map[string]bool)
+	for _, outcome := range req.GetStatuses() {
+		if !slices.Contains(resultOutcomes, outcome) {
+			return status.Errorf(codes.InvalidArgument, "unknown status '%s': %s expected", outcome, strings.Join(resultOutcomes, ", "))
+		}
+		statuses[outcome] = true
+	}
+
+	return a.db.View(func(txn *badger.Txn) error {
 		// Set up the iterator options
 		opts := badger.DefaultIteratorOptions
 		opts.PrefetchValues = true
@@ -119,6 +159,7 @@ func (a *apiServer) ListResults(ctx context.Context, req *proto.ListResultsReque
 		}
 
 		var count, skipped int32
+		requests := make(map[int64]*database.Request) // avoids re-reading the same request
 
 		for ; it.Valid(); it.Next() {
 			if count >= limit {
@@ -163,6 +204,16 @@ func (a *apiServer) ListResults(ctx context.Context, req *proto.ListResultsReque
 				}
 			}
 
+			// filter by request metadata
+			if len(req.GetMetadata()) > 0 && !matchMetadata(txn, requests, id, val, req.GetMetadata()) {
+				continue
+			}
+
+			// filter by outcome
+			if (len(statuses) > 0 || req.Retcode != nil) && !matchOutcome(val, statuses, req.Retcode) {
+				continue
+			}
+
 			if skipped < req.Offset {
 				skipped++
 				continue
@@ -178,7 +229,9 @@ func (a *apiServer) ListResults(ctx context.Context, req *proto.ListResultsReque
 					Node:   string(val),
 					Status: "success",
 				}
-				resultEntries = append(resultEntries, resultEntry)
+				if err := emit(resultEntry); err != nil {
+					return err
+				}
 				continue
 			}
 
@@ -190,26 +243,26 @@ func (a *apiServer) ListResults(ctx context.Context, req *proto.ListResultsReque
 					Id:     id,
 					Status: "unknown",
 				}
-				resultEntries = append(resultEntries, resultEntry)
+				if err := emit(resultEntry); err != nil {
+					return err
+				}
 				continue
 			}
 
-			status := "unknown"
+			status := database.ResultStatus(dbTask.Result)
 			internalError := proto.InternalError_UNKNOWN_ERROR
 			var errorMsg string
+			var retcode int32
+			var task string
+			var metadata map[string]string
 
 			if dbTask.Result != nil {
 				internalError = dbTask.Result.GetInternalError()
 				errorMsg = dbTask.Result.GetError()
-
-				switch {
-				case internalError == proto.InternalError_OK && errorMsg == "":
-					status = "success"
-				case errorMsg != "":
-					status = "error"
-				case internalError != proto.InternalError_OK:
-					status = "internal error"
-				}
+				retcode = dbTask.Result.GetRetcode()
+				request := requestOf(txn, requests, resultRequestID(dbTask.Result))
+				task = request.Task
+				metadata = request.Metadata
 			}
 
 			resultEntry = &proto.ResultEntry{
@@ -218,20 +271,273 @@ func (a *apiServer) ListResults(ctx context.Context, req *proto.ListResultsReque
 				Status:        status,
 				InternalError: internalError,
 				Error:         errorMsg,
+				Retcode:       retcode,
+				Task:          task,
+				Metadata:      metadata,
 			}
 
-			resultEntries = append(resultEntries, resultEntry)
+			if err := emit(resultEntry); err != nil {
+				return err
+			}
 			count++
 		}
 
 		return nil
 	})
+}
+
+// ResultsStats returns the numbers of results by status, in total and by plugin, with the filters of ListResults.
+//
+// The groups are not counted, only the results of their nodes.
+func (a *apiServer) ResultsStats(ctx context.Context, req *proto.ResultsStatsRequest) (*proto.ResultsStatsResponse, error) {
+	resp := &proto.ResultsStatsResponse{All: &proto.ResultsCounts{}, Plugins: make(map[string]*proto.ResultsCounts)}
+	targetMap := make(map[string]bool)
+	for _, target := range req.GetTargets() {
+		targetMap[target] = true
+	}
+
+	err := a.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = database.GenerateResultKey("")
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
 
+		requests := make(map[int64]*database.Request) // avoids re-reading the same request
+		it.Rewind()
+		if req.GetFromDate() > 0 {
+			it.Seek(database.GenerateResultKey(strconv.FormatInt(req.GetFromDate(), 10)))
+		}
+		for ; it.Valid(); it.Next() {
+			item := it.Item()
+			dbKey, err := database.StringToKey(string(item.Key()))
+			if err != nil {
+				continue
+			}
+			id, err := strconv.ParseInt(dbKey.ID, 10, 64)
+			if err != nil {
+				continue
+			}
+			if req.GetToDate() > 0 && id > req.GetToDate() {
+				break // keys are ordered chronologically
+			}
+
+			val, err := item.ValueCopy(nil)
+			if err != nil {
+				continue
+			}
+			if _, isGroup := database.CutGroupPrefix(string(val)); isGroup {
+				continue
+			}
+
+			var dbTask database.Task
+			if err := serializer.JSON.Unmarshal(val, &dbTask); err != nil {
+				dbTask = database.Task{} // unreadable result, counted unknown
+			}
+			if len(targetMap) > 0 && !targetMap[string(dbTask.Node)] {
+				continue
+			}
+
+			request := &database.Request{}
+			if dbTask.Result != nil {
+				request = requestOf(txn, requests, resultRequestID(dbTask.Result))
+			}
+			if len(req.GetMetadata()) > 0 && !request.MatchMetadata(req.GetMetadata()) {
+				continue
+			}
+
+			plugin, _, _ := strings.Cut(request.Task, config.PluginSeparator)
+			countResult(resp.GetAll(), dbTask.Result)
+			countResult(pluginCounts(resp, plugin), dbTask.Result)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &proto.ListResultsResponse{
-		Results: resultEntries,
-	}, nil
+	return resp, nil
+}
+
+// pluginCounts returns the counts of the plugin, created if missing.
+func pluginCounts(resp *proto.ResultsStatsResponse, plugin string) *proto.ResultsCounts {
+	counts, ok := resp.GetPlugins()[plugin]
+	if !ok {
+		counts = &proto.ResultsCounts{}
+		resp.Plugins[plugin] = counts
+	}
+	return counts
+}
+
+// resultOutcomes are the outcomes of resultOutcome.
+var resultOutcomes = []string{"success", "error", "timeout", "internal error", "unknown"}
+
+// resultOutcome returns the status of database.ResultStatus, with the timeouts apart from the other internal
+// errors: success, error, timeout, internal error or unknown.
+func resultOutcome(result *proto.TaskResponse) string {
+	status := database.ResultStatus(result)
+	if status != "internal error" {
+		return status
+	}
+	switch result.GetInternalError() {
+	case proto.InternalError_TIMEOUT, proto.InternalError_STARTED_TIMEOUT:
+		return "timeout"
+	default:
+		return status
+	}
+}
+
+// countResult adds the result to the counts of its outcome.
+func countResult(counts *proto.ResultsCounts, result *proto.TaskResponse) {
+	counts.Total++
+	switch resultOutcome(result) {
+	case "success":
+		counts.Success++
+	case "error":
+		counts.Error++
+	case "timeout":
+		counts.Timeout++
+	case "internal error":
+		counts.InternalError++
+	default:
+		counts.Unknown++
+	}
+}
+
+// matchOutcome returns true if the result has one of the wanted statuses (outcomes of resultOutcome) if any, and
+// the wanted return code if set. A group never matches.
+func matchOutcome(val []byte, statuses map[string]bool, retcode *int32) bool {
+	if _, isGroup := database.CutGroupPrefix(string(val)); isGroup {
+		return false
+	}
+
+	var result *proto.TaskResponse
+	if task, err := database.UnmarshalTask(val); err == nil {
+		result = task.Result
+	}
+	if len(statuses) > 0 && !statuses[resultOutcome(result)] {
+		return false
+	}
+	return retcode == nil || (result != nil && result.GetRetcode() == *retcode)
+}
+
+// DeleteResults deletes the results matching the filters, and removes them from their group.
+//
+// Results are selected either by IDs, or by date range and targets (same filters as ListResults).
+// At least one filter is required to avoid deleting the whole history by mistake.
+func (a *apiServer) DeleteResults(ctx context.Context, req *proto.DeleteResultsRequest) (*proto.DeleteResultsResponse, error) {
+	ids := req.GetIds()
+	if len(ids) == 0 {
+		if req.GetFromDate() == 0 && req.GetToDate() == 0 && len(req.GetTargets()) == 0 {
+			return nil, status.Error(codes.InvalidArgument, "at least one filter is required")
+		}
+
+		var err error
+		ids, err = a.matchResults(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deleted, err := database.DeleteResults(a.db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.DeleteResultsResponse{Deleted: deleted}, nil
+}
+
+// matchResults returns the IDs of the results matching the date range and the targets.
+//
+// Groups are not matched, they are cleaned up when their results are deleted.
+func (a *apiServer) matchResults(req *proto.DeleteResultsRequest) ([]int64, error) {
+	ids := []int64{}
+	targetMap := make(map[string]bool)
+	for _, target := range req.GetTargets() {
+		targetMap[target] = true
+	}
+
+	err := a.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = database.GenerateResultKey("")
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			dbKey, err := database.StringToKey(string(item.Key()))
+			if err != nil {
+				continue
+			}
+
+			id, err := strconv.ParseInt(dbKey.ID, 10, 64)
+			if err != nil {
+				continue
+			}
+			if req.GetFromDate() > 0 && id < req.GetFromDate() {
+				continue
+			}
+			if req.GetToDate() > 0 && id > req.GetToDate() {
+				break // keys are ordered by ID
+			}
+
+			val, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if _, isGroup := database.CutGroupPrefix(string(val)); isGroup {
+				continue
+			}
+
+			if len(targetMap) > 0 {
+				var dbTask struct{ Node node.ID } // partial deserialisation
+				if err := serializer.JSON.Unmarshal(val, &dbTask); err != nil || !targetMap[string(dbTask.Node)] {
+					continue
+				}
+			}
+
+			ids = append(ids, id)
+		}
+		return nil
+	})
+
+	return ids, err
+}
+
+// requestOf returns the request which produced the results, empty if unknown.
+//
+// Lookups are cached in requests since all the results of a group share the same request.
+func requestOf(txn *badger.Txn, requests map[int64]*database.Request, requestID int64) *database.Request {
+	if request, ok := requests[requestID]; ok {
+		return request
+	}
+
+	request, err := database.GetRequest(txn, requestID)
+	if err != nil {
+		request = &database.Request{} // unknown request: empty task
+	}
+	requests[requestID] = request
+	return request
+}
+
+// resultRequestID returns the ID of the request which produced the result.
+func resultRequestID(result *proto.TaskResponse) int64 {
+	if result.GetGroupID() != 0 {
+		return result.GetGroupID()
+	}
+	return result.GetId()
+}
+
+// matchMetadata returns true if the request of the result or group has the wanted metadata.
+func matchMetadata(txn *badger.Txn, requests map[int64]*database.Request, id int64, val []byte, wanted map[string]string) bool {
+	requestID := id // a group ID is the ID of its request
+	if _, isGroup := database.CutGroupPrefix(string(val)); !isGroup {
+		task, err := database.UnmarshalTask(val)
+		if err != nil || task.Result == nil {
+			return false
+		}
+		requestID = resultRequestID(task.Result)
+	}
+	return requestOf(txn, requests, requestID).MatchMetadata(wanted)
 }
@@ -0,0 +1,395 @@
+package management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
+	"github.com/jackadi-io/jackadi/internal/node"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+func newTestDB(t *testing.T) *badger.DB {
+	t.Helper()
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// storeGroup stores the results of a request, as the server does, one result per node.
+func storeGroup(t *testing.T, db *badger.DB, groupID int64, results map[int64]node.ID) {
+	t.Helper()
+	err := db.Update(func(txn *badger.Txn) error {
+		members := ""
+		for id, nd := range results {
+			data, err := database.MarshalTask(nd, &proto.TaskResponse{Id: id, GroupID: &groupID})
+			if err != nil {
+				return err
+			}
+			if err := txn.Set(database.GenerateResultKey(strconv.FormatInt(id, 10)), data); err != nil {
+				return err
+			}
+			if members != "" {
+				members += ","
+			}
+			members += strconv.FormatInt(id, 10)
+		}
+		return txn.Set(database.GenerateResultKey(strconv.FormatInt(groupID, 10)), []byte("grouped:"+members))
+	})
+	require.NoError(t, err)
+}
+
+// get returns the stored value of a result or group, and false if it does not exist.
+func get(t *testing.T, db *badger.DB, id int64) (string, bool) {
+	t.Helper()
+	var val []byte
+	err := db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(database.GenerateResultKey(strconv.FormatInt(id, 10)))
+		if err != nil {
+			return err
+		}
+		val, err = item.ValueCopy(nil)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return "", false
+	}
+	require.NoError(t, err)
+	return string(val), true
+}
+
+func TestDeleteResultsByID(t *testing.T) {
+	db := newTestDB(t)
+	api := New(nil, db)
+	storeGroup(t, db, 100, map[int64]node.ID{101: "web-1", 102: "web-2"})
+
+	resp, err := api.DeleteResults(context.Background(), &proto.DeleteResultsRequest{Ids: []int64{101}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{101}, resp.GetDeleted())
+
+	_, ok := get(t, db, 101)
+	assert.False(t, ok)
+	_, ok = get(t, db, 102)
+	assert.True(t, ok)
+	group, _ := get(t, db, 100)
+	assert.Equal(t, "grouped:102", group, "the group must not reference the deleted result")
+
+	// deleting the last result of the group deletes the group
+	_, err = api.DeleteResults(context.Background(), &proto.DeleteResultsRequest{Ids: []int64{102}})
+	require.NoError(t, err)
+	_, ok = get(t, db, 100)
+	assert.False(t, ok)
+
+	// unknown IDs are ignored
+	resp, err = api.DeleteResults(context.Background(), &proto.DeleteResultsRequest{Ids: []int64{999}})
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetDeleted())
+}
+
+func TestDeleteResultsByGroupID(t *testing.T) {
+	db := newTestDB(t)
+	api := New(nil, db)
+	storeGroup(t, db, 100, map[int64]node.ID{101: "web-1", 102: "web-2"})
+
+	resp, err := api.DeleteResults(context.Background(), &proto.DeleteResultsRequest{Ids: []int64{100}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int64{101, 102}, resp.GetDeleted())
+
+	for _, id := range []int64{100, 101, 102} {
+		_, ok := get(t, db, id)
+		assert.False(t, ok, "%d should be deleted", id)
+	}
+}
+
+func TestDeleteResultsByFilters(t *testing.T) {
+	db := newTestDB(t)
+	api := New(nil, db)
+	storeGroup(t, db, 100, map[int64]node.ID{101: "web-1", 102: "db-1"})
+	storeGroup(t, db, 200, map[int64]node.ID{201: "web-1", 202: "web-2"})
+	storeGroup(t, db, 300, map[int64]node.ID{301: "web-1"})
+
+	from := int64(150)
+	to := int64(250)
+	resp, err := api.DeleteResults(context.Background(), &proto.DeleteResultsRequest{
+		FromDate: &from,
+		ToDate:   &to,
+		Targets:  []string{"web-1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{201}, resp.GetDeleted())
+
+	group, _ := get(t, db, 200)
+	assert.Equal(t, "grouped:202", group)
+
+	// only targets: all the results of web-1, and its groups are cleaned up
+	resp, err = api.DeleteResults(context.Background(), &proto.DeleteResultsRequest{Targets: []string{"web-1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{101, 301}, resp.GetDeleted())
+
+	group, _ = get(t, db, 100)
+	assert.Equal(t, "grouped:102", group)
+	_, ok := get(t, db, 300)
+	assert.False(t, ok, "empty group should be deleted")
+	for _, id := range []int64{102, 202} {
+		_, ok := get(t, db, id)
+		assert.True(t, ok, "%d should be kept", id)
+	}
+}
+
+func TestDeleteResultsRequiresFilter(t *testing.T) {
+	db := newTestDB(t)
+	api := New(nil, db)
+	storeGroup(t, db, 100, map[int64]node.ID{101: "web-1"})
+
+	_, err := api.DeleteResults(context.Background(), &proto.DeleteResultsRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, ok := get(t, db, 101)
+	assert.True(t, ok)
+}
+
+// storeRequest stores a request, as the forwarder does.
+func storeRequest(t *testing.T, db *badger.DB, id int64, request database.Request) {
+	t.Helper()
+	data, err := database.MarshalRequest(&request)
+	require.NoError(t, err)
+	require.NoError(t, db.Update(func(txn *badger.Txn) error {
+		return txn.Set(database.GenerateRequestKey(id), data)
+	}))
+}
+
+func TestListResultsByMetadata(t *testing.T) {
+	db := newTestDB(t)
+	api := New(nil, db)
+	storeRequest(t, db, 100, database.Request{Task: "cmd.run", Metadata: map[string]string{"build": "1", "ticket": "CHG-1"}})
+	storeGroup(t, db, 100, map[int64]node.ID{101: "web-1", 102: "web-2"})
+	storeRequest(t, db, 200, database.Request{Task: "cmd.run", Metadata: map[string]string{"build": "2"}})
+	storeGroup(t, db, 200, map[int64]node.ID{201: "web-1"})
+	storeRequest(t, db, 300, database.Request{Task: "cmd.run"})
+	storeGroup(t, db, 300, map[int64]node.ID{301: "web-1"})
+
+	ids := func(resp *proto.ListResultsResponse) []int64 {
+		ids := []int64{}
+		for _, res := range resp.GetResults() {
+			ids = append(ids, res.GetId())
+		}
+		return ids
+	}
+
+	resp, err := api.ListResults(context.Background(), &proto.ListResultsRequest{Metadata: map[string]string{"build": "1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{102, 101, 100}, ids(resp))
+	for _, res := range resp.GetResults()[:2] {
+		assert.Equal(t, "cmd.run", res.GetTask())
+		assert.Equal(t, map[string]string{"build": "1", "ticket": "CHG-1"}, res.GetMetadata())
+	}
+
+	// all the pairs must match
+	resp, err = api.ListResults(context.Background(), &proto.ListResultsRequest{Metadata: map[string]string{"build": "2", "ticket": "CHG-1"}})
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetResults())
+
+	resp, err = api.ListResults(context.Background(), &proto.ListResultsRequest{Metadata: map[string]string{"build": "2"}, Targets: []string{"web-1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{201}, ids(resp))
+
+	// without filter, the results without metadata are listed too
+	resp, err = api.ListResults(context.Background(), &proto.ListResultsRequest{})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetResults(), 7)
+}
+
+// storeResults stores the results of a request with their responses, as the server does, without the group.
+func storeResults(t *testing.T, db *badger.DB, groupID int64, results map[int64]*proto.TaskResponse) {
+	t.Helper()
+	require.NoError(t, db.Update(func(txn *badger.Txn) error {
+		for id, r := range results {
+			r.Id, r.GroupID = id, &groupID
+			data, err := database.MarshalTask(node.ID(fmt.Sprintf("node-%d", id%10)), r)
+			if err != nil {
+				return err
+			}
+			if err := txn.Set(database.GenerateResultKey(strconv.FormatInt(id, 10)), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func TestResultsStats(t *testing.T) {
+	db := newTestDB(t)
+	api := New(nil, db)
+	storeRequest(t, db, 100, database.Request{Task: "cmd.run", Metadata: map[string]string{"build": "1"}})
+	storeResults(t, db, 100, map[int64]*proto.TaskResponse{
+		101: {},
+		102: {Error: "exit status 1", Retcode: 1},
+		103: {InternalError: proto.InternalError_TIMEOUT},
+	})
+	storeRequest(t, db, 200, database.Request{Task: "pkg.install"})
+	storeResults(t, db, 200, map[int64]*proto.TaskResponse{
+		201: {},
+		202: {InternalError: proto.InternalError_DISCONNECTED},
+	})
+	storeGroup(t, db, 300, map[int64]node.ID{301: "node-1"}) // request unknown, group not counted
+	require.NoError(t, db.Update(func(txn *badger.Txn) error {
+		return txn.Set(database.GenerateResultKey("400"), []byte("not json"))
+	}))
+
+	resp, err := api.ResultsStats(context.Background(), &proto.ResultsStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, &proto.ResultsCounts{Total: 7, Success: 3, Error: 1, Timeout: 1, InternalError: 1, Unknown: 1}, resp.GetAll())
+	assert.Equal(t, &proto.ResultsCounts{Total: 3, Success: 1, Error: 1, Timeout: 1}, resp.GetPlugins()["cmd"])
+	assert.Equal(t, &proto.ResultsCounts{Total: 2, Success: 1, InternalError: 1}, resp.GetPlugins()["pkg"])
+	assert.Equal(t, &proto.ResultsCounts{Total: 2, Success: 1, Unknown: 1}, resp.GetPlugins()[""])
+	assert.InDelta(t, 4.0/7, resp.GetAll().FailureRate(), 1e-9)
+
+	// time window
+	from, to := int64(150), int64(250)
+	resp, err = api.ResultsStats(context.Background(), &proto.ResultsStatsRequest{FromDate: &from, ToDate: &to})
+	require.NoError(t, err)
+	assert.Equal(t, &proto.ResultsCounts{Total: 2, Success: 1, InternalError: 1}, resp.GetAll())
+	assert.Len(t, resp.GetPlugins(), 1)
+
+	resp, err = api.ResultsStats(context.Background(), &proto.ResultsStatsRequest{Targets: []string{"node-2"}})
+	require.NoError(t, err)
+	assert.Equal(t, &proto.ResultsCounts{Total: 2, Error: 1, InternalError: 1}, resp.GetAll())
+
+	resp, err = api.ResultsStats(context.Background(), &proto.ResultsStatsRequest{Metadata: map[string]string{"build": "1"}})
+	require.NoError(t, err)
+	assert.Equal(t, &proto.ResultsCounts{Total: 3, Success: 1, Error: 1, Timeout: 1}, resp.GetAll())
+	assert.Zero(t, (&proto.ResultsCounts{}).FailureRate())
+}
+
+func TestListResultsByOutcome(t *testing.T) {
+	db := newTestDB(t)
+	api := New(nil, db)
+	storeResults(t, db, 100, map[int64]*proto.TaskResponse{
+		101: {},
+		102: {Error: "exit status 1", Retcode: 1},
+		103: {InternalError: proto.InternalError_TIMEOUT},
+		104: {InternalError: proto.InternalError_DISCONNECTED},
+		105: {Error: "exit status 2", Retcode: 2},
+	})
+	storeGroup(t, db, 200, map[int64]node.ID{201: "web-1"})
+	require.NoError(t, db.Update(func(txn *badger.Txn) error {
+		return txn.Set(database.GenerateResultKey("300"), []byte("not json"))
+	}))
+
+	ids := func(req *proto.ListResultsRequest) []int64 {
+		t.Helper()
+		resp, err := api.ListResults(context.Background(), req)
+		require.NoError(t, err)
+		ids := []int64{}
+		for _, res := range resp.GetResults() {
+			ids = append(ids, res.GetId())
+		}
+		return ids
+	}
+	retcode := func(code int32) *int32 { return &code }
+
+	assert.Equal(t, []int64{105, 102}, ids(&proto.ListResultsRequest{Statuses: []string{"error"}}))
+	assert.Equal(t, []int64{103}, ids(&proto.ListResultsRequest{Statuses: []string{"timeout"}}))
+	assert.Equal(t, []int64{104}, ids(&proto.ListResultsRequest{Statuses: []string{"internal error"}}))
+	assert.Equal(t, []int64{105, 104, 103, 102}, ids(&proto.ListResultsRequest{Statuses: []string{"error", "timeout", "internal error"}}))
+	assert.Equal(t, []int64{201, 101}, ids(&proto.ListResultsRequest{Statuses: []string{"success"}}), "the groups must not be listed")
+	assert.Equal(t, []int64{300}, ids(&proto.ListResultsRequest{Statuses: []string{"unknown"}}))
+
+	assert.Equal(t, []int64{105}, ids(&proto.ListResultsRequest{Retcode: retcode(2)}))
+	assert.Equal(t, []int64{201, 104, 103, 101}, ids(&proto.ListResultsRequest{Retcode: retcode(0)}), "the invalid data and the groups must not be listed")
+	assert.Empty(t, ids(&proto.ListResultsRequest{Statuses: []string{"success"}, Retcode: retcode(1)}))
+
+	_, err := api.ListResults(context.Background(), &proto.ListResultsRequest{Statuses: []string{"failed"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// streamResults returns the results sent by StreamResults.
+func streamResults(t *testing.T, client proto.APIClient, req *proto.ListResultsRequest) ([]*proto.ResultEntry, error) {
+	t.Helper()
+	stream, err := client.StreamResults(context.Background(), req)
+	require.NoError(t, err)
+
+	var results []*proto.ResultEntry
+	for {
+		entry, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, entry)
+	}
+}
+
+func TestStreamResults(t *testing.T) {
+	db := newTestDB(t)
+	client := newTestClient(t, db)
+	storeRequest(t, db, 100, database.Request{Task: "cmd.run", Metadata: map[string]string{"build": "1"}})
+	storeResults(t, db, 100, map[int64]*proto.TaskResponse{
+		101: {},
+		102: {Error: "exit status 1", Retcode: 1},
+		103: {InternalError: proto.InternalError_TIMEOUT},
+	})
+	storeGroup(t, db, 200, map[int64]node.ID{201: "web-1", 202: "web-2"})
+	require.NoError(t, db.Update(func(txn *badger.Txn) error {
+		return txn.Set(database.GenerateResultKey("300"), []byte("not json"))
+	}))
+
+	from, to := int64(150), int64(250)
+	for name, req := range map[string]*proto.ListResultsRequest{
+		"all":        {},
+		"paginated":  {Limit: 2, Offset: 1},
+		"time range": {FromDate: &from, ToDate: &to},
+		"targets":    {Targets: []string{"web-1", "node-2"}},
+		"metadata":   {Metadata: map[string]string{"build": "1"}},
+		"failed":     {Statuses: []string{"error", "timeout", "unknown"}},
+	} {
+		unary, err := client.ListResults(context.Background(), req)
+		require.NoError(t, err, name)
+		streamed, err := streamResults(t, client, req)
+		require.NoError(t, err, name)
+
+		require.Len(t, streamed, len(unary.GetResults()), name)
+		for i, entry := range unary.GetResults() {
+			assert.True(t, protobuf.Equal(entry, streamed[i]), "%s: result %d: %v, streamed %v", name, i, entry, streamed[i])
+		}
+	}
+
+	_, err := streamResults(t, client, &proto.ListResultsRequest{Statuses: []string{"failed"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestStreamResultsLimit(t *testing.T) {
+	db := newTestDB(t)
+	client := newTestClient(t, db)
+	results := make(map[int64]node.ID)
+	for id := range int64(config.ResultsPageLimit + 50) {
+		results[1000+id] = "web-1"
+	}
+	storeGroup(t, db, 2000, results)
+
+	unary, err := client.ListResults(context.Background(), &proto.ListResultsRequest{Limit: config.ResultsPageLimit + 50})
+	require.NoError(t, err)
+	assert.Len(t, unary.GetResults(), config.ResultsPageLimit+1, "the page must be limited, the group not counted")
+
+	streamed, err := streamResults(t, client, &proto.ListResultsRequest{Limit: config.ResultsPageLimit + 50})
+	require.NoError(t, err)
+	assert.Len(t, streamed, config.ResultsPageLimit+50+1)
+	for i := 1; i < len(streamed); i++ {
+		assert.Greater(t, streamed[i-1].GetId(), streamed[i].GetId(), "the most recent results must be sent first")
+	}
+}
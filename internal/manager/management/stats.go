@@ -0,0 +1,66 @@
+package management
+
+import (
+	"context"
+
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DatabaseStats returns the size of the database and the outcome of the last garbage collection.
+//
+// Sizes are refreshed by Badger every minute, and the key count only includes the entries already
+// flushed to disk: the result is an estimate.
+func (a *apiServer) DatabaseStats(ctx context.Context, _ *emptypb.Empty) (*proto.DatabaseStatsResponse, error) {
+	lsm, vlog := a.db.Size()
+	resp := &proto.DatabaseStatsResponse{
+		LsmSize:  lsm,
+		VlogSize: vlog,
+	}
+
+	for _, table := range a.db.Tables() {
+		resp.Keys += uint64(table.KeyCount)
+	}
+
+	if a.gc == nil {
+		return resp, nil
+	}
+	if last := a.gc.Last(); !last.Time.IsZero() {
+		resp.LastGc = timestamppb.New(last.Time)
+		resp.LastGcReclaimed = last.Reclaimed
+		resp.LastGcRewrites = uint32(last.Rewrites) //nolint:gosec // a few files per run
+		if last.Err != nil {
+			resp.LastGcError = last.Err.Error()
+		}
+	}
+	return resp, nil
+}
+
+// LockStats returns the time the tasks waited for their lock on each node, and the number of tasks sent while
+// a task with a conflicting lock mode was in flight.
+func (a *apiServer) LockStats(ctx context.Context, _ *emptypb.Empty) (*proto.LockStatsResponse, error) {
+	resp := &proto.LockStatsResponse{Nodes: make(map[string]*proto.NodeLockStats)}
+	for nd, stats := range a.locks.Stats() {
+		nodeStats := &proto.NodeLockStats{
+			Modes:     make(map[string]*proto.LockWaitStats, len(stats.Modes)),
+			Conflicts: stats.Conflicts,
+		}
+		for mode, wait := range stats.Modes {
+			nodeStats.Modes[mode.String()] = &proto.LockWaitStats{
+				Tasks:     wait.Tasks,
+				Contended: wait.Contended,
+				TotalWait: wait.TotalWait.Milliseconds(),
+				MaxWait:   wait.MaxWait.Milliseconds(),
+			}
+		}
+		resp.Nodes[nd] = nodeStats
+	}
+	return resp, nil
+}
+
+// RunningTasks returns the tasks sent to the nodes and not answered yet, with the long-running tasks reported
+// by the nodes the manager stopped waiting for.
+func (a *apiServer) RunningTasks(ctx context.Context, req *proto.RunningTasksRequest) (*proto.RunningTasksResponse, error) {
+	return &proto.RunningTasksResponse{Tasks: a.server.RunningTasks(req.GetTask())}, nil
+}
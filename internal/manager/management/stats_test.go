@@ -0,0 +1,55 @@
+package management
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestDatabaseStats(t *testing.T) {
+	dir := t.TempDir()
+	opts := badger.DefaultOptions(dir).WithLogger(nil)
+
+	db, err := badger.Open(opts)
+	require.NoError(t, err)
+	err = db.Update(func(txn *badger.Txn) error {
+		for i := range 100 {
+			if err := txn.Set(fmt.Appendf(nil, "res:%d", i), []byte("result")); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Close()) // flushes the memtable, so that keys are counted in tables
+
+	db, err = badger.Open(opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	api := New(nil, db)
+	gc := database.NewGarbageCollector(db)
+	api.SetGarbageCollector(gc)
+
+	resp, err := api.DatabaseStats(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Positive(t, resp.GetLsmSize())
+	assert.Positive(t, resp.GetVlogSize())
+	assert.EqualValues(t, 100, resp.GetKeys())
+	assert.Nil(t, resp.GetLastGc(), "no GC ran yet")
+
+	outcome := gc.Collect(context.Background())
+	resp, err = api.DatabaseStats(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	require.NotNil(t, resp.GetLastGc())
+	assert.True(t, resp.GetLastGc().AsTime().Equal(outcome.Time))
+	assert.Equal(t, outcome.Reclaimed, resp.GetLastGcReclaimed())
+	assert.EqualValues(t, outcome.Rewrites, resp.GetLastGcRewrites())
+	assert.Empty(t, resp.GetLastGcError())
+}
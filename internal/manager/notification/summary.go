@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
+	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+// Summary renders the responses of a run as a compact markdown message, suitable for chat webhooks
+// (e.g. Slack or Mattermost).
+//
+// Only the failures are detailed, with the first line of their error.
+func Summary(task string, responses map[string]*proto.TaskResponse) string {
+	failed := []string{}
+	for _, nd := range slices.Sorted(maps.Keys(responses)) {
+		if database.ResultStatus(responses[nd]) != "success" {
+			failed = append(failed, nd)
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**`%s`**: %d/%d succeeded", task, len(responses)-len(failed), len(responses))
+	if len(failed) == 0 {
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, ", %d failed\n", len(failed))
+
+	for i, nd := range failed {
+		if i == config.SummaryMaxFailures {
+			fmt.Fprintf(&sb, "- _... and %d more_\n", len(failed)-i)
+			break
+		}
+
+		res := responses[nd]
+		fmt.Fprintf(&sb, "- `%s`: %s", nd, database.ResultStatus(res))
+		if res.GetRetcode() != 0 {
+			fmt.Fprintf(&sb, " (retcode %d)", res.GetRetcode())
+		}
+		if snippet := errorSnippet(res); snippet != "" {
+			fmt.Fprintf(&sb, ": `%s`", snippet)
+		}
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// errorSnippet returns the first line of the most relevant error of a response, truncated to
+// SummaryMaxErrorLength characters.
+func errorSnippet(res *proto.TaskResponse) string {
+	msg := res.GetError()
+	if msg == "" {
+		msg = res.GetModuleError()
+	}
+	if msg == "" && res.GetInternalError() != proto.InternalError_OK {
+		msg = res.GetInternalError().String()
+	}
+
+	msg, _, _ = strings.Cut(strings.TrimSpace(msg), "\n")
+	msg = strings.ReplaceAll(msg, "`", "'")
+
+	if runes := []rune(msg); len(runes) > config.SummaryMaxErrorLength {
+		msg = string(runes[:config.SummaryMaxErrorLength-3]) + "..."
+	}
+	return msg
+}
+
+// NodeSummary renders a node activity change as a markdown message, suitable for chat webhooks.
+func NodeSummary(e inventory.NodeEvent) string {
+	if e.Type == inventory.NodeSpecsDrift {
+		return specsDriftSummary(e)
+	}
+	if e.Type == inventory.NodeAutoAccepted {
+		return autoAcceptedSummary(e)
+	}
+	if e.Type == inventory.NodeCandidate {
+		return fmt.Sprintf("**`%s`** from `%s` is waiting for its acceptance: `jack nodes accept %s`", e.Node, e.Address, e.Node)
+	}
+	if e.Type == inventory.NodeActive {
+		return fmt.Sprintf("**`%s`** is active again", e.Node)
+	}
+	if e.LastMsg.IsZero() {
+		return fmt.Sprintf("**`%s`** is stale: no message received", e.Node)
+	}
+	return fmt.Sprintf("**`%s`** is stale: no message since %s", e.Node, e.LastMsg.UTC().Format(time.DateTime+" UTC"))
+}
+
+// specsDriftSummary renders the changed specs of a node, at most SummaryMaxChanges of them.
+func specsDriftSummary(e inventory.NodeEvent) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**`%s`** specs changed: %d change(s)\n", e.Node, len(e.Changes))
+	for i, c := range e.Changes {
+		if i == config.SummaryMaxChanges {
+			fmt.Fprintf(&sb, "- _... and %d more_\n", len(e.Changes)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "- `%s`\n", strings.ReplaceAll(c.String(), "`", "'"))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// autoAcceptedSummary renders the identity of an auto-accepted node.
+func autoAcceptedSummary(e inventory.NodeEvent) string {
+	fingerprint := "no certificate"
+	if e.Fingerprint != "" {
+		fingerprint = "certificate `" + e.Fingerprint + "`"
+	}
+	return fmt.Sprintf("**`%s`** auto-accepted from `%s`, %s", e.Node, e.Address, fingerprint)
+}
@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummaryMixedRun(t *testing.T) {
+	responses := map[string]*proto.TaskResponse{
+		"node1": {Id: 1, Output: []byte(`"ok"`)},
+		"node2": {Id: 2, Error: "exit status 1\nfull stack trace", Retcode: 1},
+		"node3": {InternalError: proto.InternalError_TIMEOUT},
+		"node4": {Id: 4, Output: []byte(`"ok"`)},
+		"node5": {Id: 5, InternalError: proto.InternalError_MODULE_ERROR, ModuleError: "plugin crashed"},
+	}
+
+	expected := "**`cmd.run`**: 2/5 succeeded, 3 failed\n" +
+		"- `node2`: error (retcode 1): `exit status 1`\n" +
+		"- `node3`: internal error: `TIMEOUT`\n" +
+		"- `node5`: internal error: `plugin crashed`"
+
+	assert.Equal(t, expected, Summary("cmd.run", responses))
+}
+
+func TestSummaryAllSucceeded(t *testing.T) {
+	responses := map[string]*proto.TaskResponse{
+		"node1": {Id: 1},
+		"node2": {Id: 2},
+	}
+
+	assert.Equal(t, "**`health.ping`**: 2/2 succeeded", Summary("health.ping", responses))
+}
+
+func TestSummaryTruncation(t *testing.T) {
+	responses := map[string]*proto.TaskResponse{}
+	for i := range config.SummaryMaxFailures + 3 {
+		responses[fmt.Sprintf("node%02d", i)] = &proto.TaskResponse{Error: strings.Repeat("x", 500)}
+	}
+
+	summary := Summary("cmd.run", responses)
+	lines := strings.Split(summary, "\n")
+
+	assert.Len(t, lines, config.SummaryMaxFailures+2) // header + detailed failures + "more" line
+	assert.Equal(t, "- _... and 3 more_", lines[len(lines)-1])
+
+	snippet := strings.Repeat("x", config.SummaryMaxErrorLength-3) + "..."
+	assert.Equal(t, "- `node00`: error: `"+snippet+"`", lines[1])
+}
+
+func TestErrorSnippetEscapesBackquotes(t *testing.T) {
+	res := &proto.TaskResponse{Error: "command `foo` not found"}
+	assert.Equal(t, "command 'foo' not found", errorSnippet(res))
+}
+
+func TestNodeSummary(t *testing.T) {
+	lastMsg := time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, "**`node1`** is stale: no message since 2025-01-01 12:30:00 UTC",
+		NodeSummary(inventory.NodeEvent{Type: inventory.NodeStale, Node: "node1", LastMsg: lastMsg}))
+	assert.Equal(t, "**`node1`** is stale: no message received",
+		NodeSummary(inventory.NodeEvent{Type: inventory.NodeStale, Node: "node1"}))
+	assert.Equal(t, "**`node1`** is active again",
+		NodeSummary(inventory.NodeEvent{Type: inventory.NodeActive, Node: "node1", LastMsg: lastMsg}))
+	assert.Equal(t, "**`node1`** specs changed: 2 change(s)\n- `software.nginx: 1.26 -> 1.27`\n- `software.redis added: 7.2`",
+		NodeSummary(inventory.NodeEvent{Type: inventory.NodeSpecsDrift, Node: "node1", Changes: []inventory.SpecChange{
+			{Type: inventory.SpecChanged, Path: "software.nginx", Old: "1.26", New: "1.27"},
+			{Type: inventory.SpecAdded, Path: "software.redis", New: "7.2"},
+		}}))
+	assert.Equal(t, "**`node1`** auto-accepted from `10.0.0.1`, certificate `SHA256:ab12`",
+		NodeSummary(inventory.NodeEvent{Type: inventory.NodeAutoAccepted, Node: "node1", Address: "10.0.0.1", Fingerprint: "SHA256:ab12"}))
+	assert.Equal(t, "**`node1`** auto-accepted from `10.0.0.1`, no certificate",
+		NodeSummary(inventory.NodeEvent{Type: inventory.NodeAutoAccepted, Node: "node1", Address: "10.0.0.1"}))
+	assert.Equal(t, "**`node1`** from `10.0.0.1` is waiting for its acceptance: `jack nodes accept node1`",
+		NodeSummary(inventory.NodeEvent{Type: inventory.NodeCandidate, Node: "node1", Address: "10.0.0.1"}))
+}
@@ -0,0 +1,320 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
+	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+// Event describes a completed task, it is the payload sent to the webhooks.
+type Event struct {
+	ID      int64  `json:"id"` // Result ID, `jack results get <id>` returns the full output.
+	GroupID int64  `json:"group_id"`
+	Node    string `json:"node"`
+	Task    string `json:"task"`
+	Status  string `json:"status"`
+	Retcode int32  `json:"retcode"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Failed returns true if the task did not succeed.
+func (e Event) Failed() bool {
+	return e.Status != "success"
+}
+
+// Plugin returns the plugin part of the task name.
+func (e Event) Plugin() string {
+	plugin, _, _ := strings.Cut(e.Task, config.PluginSeparator)
+	return plugin
+}
+
+// Run describes a completed request targeting one or multiple nodes.
+type Run struct {
+	Task      string
+	Responses map[string]*proto.TaskResponse // Key is the node name.
+}
+
+// Failed returns true if at least one task of the run did not succeed.
+func (r Run) Failed() bool {
+	for _, res := range r.Responses {
+		if database.ResultStatus(res) != "success" {
+			return true
+		}
+	}
+	return false
+}
+
+// summaryPayload is the payload sent to the webhooks using the summary format.
+//
+// The "text" field is understood by most chat incoming webhooks (Slack, Mattermost...).
+type summaryPayload struct {
+	Text string `json:"text"`
+}
+
+type webhook struct {
+	url           string
+	onFailureOnly bool
+	plugins       []string
+	timeout       time.Duration
+	summary       bool
+	nodeEvents    bool
+}
+
+// match returns true if the event passes the webhook filters.
+func (w webhook) match(e Event) bool {
+	if w.summary {
+		return false
+	}
+	return w.filter(e.Task, e.Failed())
+}
+
+// matchRun returns true if the run passes the webhook filters.
+func (w webhook) matchRun(r Run) bool {
+	if !w.summary {
+		return false
+	}
+	return w.filter(r.Task, r.Failed())
+}
+
+func (w webhook) filter(task string, failed bool) bool {
+	if w.onFailureOnly && !failed {
+		return false
+	}
+
+	if len(w.plugins) == 0 {
+		return true
+	}
+
+	plugin, _, _ := strings.Cut(task, config.PluginSeparator)
+	for _, pattern := range w.plugins {
+		if ok, err := filepath.Match(pattern, plugin); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Dispatcher sends events to the configured webhooks.
+//
+// Events are queued and delivered by Run, so that a slow webhook never blocks the task processing.
+type Dispatcher struct {
+	webhooks   []webhook
+	client     *http.Client
+	events     chan Event
+	runs       chan Run
+	nodes      chan inventory.NodeEvent
+	retries    int
+	retryDelay time.Duration
+}
+
+func NewDispatcher(webhooks []config.WebhookConfig) *Dispatcher {
+	d := &Dispatcher{
+		client:     &http.Client{},
+		events:     make(chan Event, config.NotificationQueueSize),
+		runs:       make(chan Run, config.NotificationQueueSize),
+		nodes:      make(chan inventory.NodeEvent, config.NotificationQueueSize),
+		retries:    config.WebhookRetries,
+		retryDelay: config.WebhookRetryDelay,
+	}
+
+	for _, w := range webhooks {
+		timeout := config.DefaultWebhookTimeout
+		if w.Timeout > 0 {
+			timeout = time.Duration(w.Timeout) * time.Second
+		}
+		d.webhooks = append(d.webhooks, webhook{
+			url:           w.URL,
+			onFailureOnly: w.OnFailureOnly,
+			plugins:       w.Plugins,
+			timeout:       timeout,
+			summary:       w.Format == config.WebhookFormatSummary,
+			nodeEvents:    w.NodeEvents,
+		})
+	}
+
+	return d
+}
+
+// Notify queues an event without blocking. The event is dropped if the queue is full.
+func (d *Dispatcher) Notify(e Event) {
+	if d == nil || len(d.webhooks) == 0 {
+		return
+	}
+
+	select {
+	case d.events <- e:
+	default:
+		slog.Warn("notification dropped", "error", "queue full", "id", e.ID, "node", e.Node)
+	}
+}
+
+// NotifyRun queues a completed run without blocking. The run is dropped if the queue is full.
+func (d *Dispatcher) NotifyRun(r Run) {
+	if d == nil || len(d.webhooks) == 0 {
+		return
+	}
+
+	select {
+	case d.runs <- r:
+	default:
+		slog.Warn("notification dropped", "error", "queue full", "task", r.Task)
+	}
+}
+
+// NotifyNode queues a node activity change without blocking. The event is dropped if the queue is full.
+func (d *Dispatcher) NotifyNode(e inventory.NodeEvent) {
+	if d == nil || len(d.webhooks) == 0 {
+		return
+	}
+
+	select {
+	case d.nodes <- e:
+	default:
+		slog.Warn("notification dropped", "error", "queue full", "event", e.Type, "node", e.Node)
+	}
+}
+
+// Run delivers the queued events and runs until the context is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		select {
+		case e := <-d.events:
+			d.deliver(ctx, e)
+		case r := <-d.runs:
+			d.deliverRun(ctx, r)
+		case e := <-d.nodes:
+			d.deliverNode(ctx, e)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, e Event) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		slog.Error("unable to marshal notification", "error", err, "id", e.ID)
+		return
+	}
+
+	for _, w := range d.webhooks {
+		if !w.match(e) {
+			continue
+		}
+		if err := d.send(ctx, w, payload); err != nil {
+			slog.Warn("webhook notification failed", "url", w.url, "id", e.ID, "error", err)
+		}
+	}
+}
+
+func (d *Dispatcher) deliverRun(ctx context.Context, r Run) {
+	var payload []byte
+	for _, w := range d.webhooks {
+		if !w.matchRun(r) {
+			continue
+		}
+
+		if payload == nil {
+			var err error
+			payload, err = json.Marshal(summaryPayload{Text: Summary(r.Task, r.Responses)})
+			if err != nil {
+				slog.Error("unable to marshal notification", "error", err, "task", r.Task)
+				return
+			}
+		}
+		if err := d.send(ctx, w, payload); err != nil {
+			slog.Warn("webhook notification failed", "url", w.url, "task", r.Task, "error", err)
+		}
+	}
+}
+
+// deliverNode sends a node activity change to the webhooks which enabled node events.
+func (d *Dispatcher) deliverNode(ctx context.Context, e inventory.NodeEvent) {
+	for _, w := range d.webhooks {
+		if !w.nodeEvents {
+			continue
+		}
+
+		var payload []byte
+		var err error
+		if w.summary {
+			payload, err = json.Marshal(summaryPayload{Text: NodeSummary(e)})
+		} else {
+			payload, err = json.Marshal(e)
+		}
+		if err != nil {
+			slog.Error("unable to marshal notification", "error", err, "node", e.Node)
+			return
+		}
+
+		if err := d.send(ctx, w, payload); err != nil {
+			slog.Warn("webhook notification failed", "url", w.url, "event", e.Type, "node", e.Node, "error", err)
+		}
+	}
+}
+
+// send posts the payload to the webhook, retrying on failure.
+func (d *Dispatcher) send(ctx context.Context, w webhook, payload []byte) error {
+	var err error
+	for attempt := range d.retries {
+		if attempt > 0 {
+			select {
+			case <-time.After(d.retryDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err = d.post(ctx, w, payload); err == nil {
+			return nil
+		}
+		slog.Debug("webhook delivery attempt failed", "url", w.url, "attempt", attempt+1, "error", err)
+	}
+	return err
+}
+
+func (d *Dispatcher) post(ctx context.Context, w webhook, payload []byte) error {
+	return post(ctx, d.client, w.url, w.timeout, payload)
+}
+
+// PostSummary sends the summary of a run to a chat webhook, without retry.
+func PostSummary(ctx context.Context, url string, r Run) error {
+	payload, err := json.Marshal(summaryPayload{Text: Summary(r.Task, r.Responses)})
+	if err != nil {
+		return err
+	}
+	return post(ctx, &http.Client{}, url, config.DefaultWebhookTimeout, payload)
+}
+
+func post(ctx context.Context, client *http.Client, url string, timeout time.Duration, payload []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
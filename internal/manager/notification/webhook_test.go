@@ -0,0 +1,298 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a webhook endpoint recording the received events.
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+	server *httptest.Server
+}
+
+func newRecorder(t *testing.T) *recorder {
+	r := &recorder{}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+		var e Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.mu.Lock()
+		r.events = append(r.events, e)
+		r.mu.Unlock()
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *recorder) received() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+func TestWebhookMatch(t *testing.T) {
+	success := Event{Task: "cmd.run", Status: "success"}
+	failure := Event{Task: "pkg.install", Status: "error"}
+	internal := Event{Task: "cmd.run", Status: "internal error"}
+
+	tests := []struct {
+		name     string
+		webhook  webhook
+		event    Event
+		expected bool
+	}{
+		{"no filter success", webhook{}, success, true},
+		{"no filter failure", webhook{}, failure, true},
+		{"failure only on success", webhook{onFailureOnly: true}, success, false},
+		{"failure only on failure", webhook{onFailureOnly: true}, failure, true},
+		{"failure only on internal error", webhook{onFailureOnly: true}, internal, true},
+		{"plugin exact match", webhook{plugins: []string{"cmd"}}, success, true},
+		{"plugin no match", webhook{plugins: []string{"cmd"}}, failure, false},
+		{"plugin glob match", webhook{plugins: []string{"cmd", "pk*"}}, failure, true},
+		{"plugin and failure filters", webhook{onFailureOnly: true, plugins: []string{"cmd"}}, success, false},
+		{"summary webhook", webhook{summary: true}, failure, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.webhook.match(tt.event))
+		})
+	}
+}
+
+func TestDispatcherPayloadAndFiltering(t *testing.T) {
+	all := newRecorder(t)
+	failures := newRecorder(t)
+	pkg := newRecorder(t)
+
+	d := NewDispatcher([]config.WebhookConfig{
+		{URL: all.server.URL},
+		{URL: failures.server.URL, OnFailureOnly: true},
+		{URL: pkg.server.URL, Plugins: []string{"pkg*"}},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	success := Event{ID: 2, GroupID: 1, Node: "node1", Task: "cmd.run", Status: "success"}
+	failure := Event{ID: 3, GroupID: 1, Node: "node2", Task: "cmd.run", Status: "error", Retcode: 1, Error: "exit status 1"}
+	d.Notify(success)
+	d.Notify(failure)
+
+	require.Eventually(t, func() bool {
+		return len(all.received()) == 2 && len(failures.received()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []Event{success, failure}, all.received())
+	assert.Equal(t, []Event{failure}, failures.received())
+	assert.Empty(t, pkg.received())
+}
+
+func TestDispatcherRunSummary(t *testing.T) {
+	var mu sync.Mutex
+	var received []summaryPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var p summaryPayload
+		if err := json.NewDecoder(req.Body).Decode(&p); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, p)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	events := newRecorder(t)
+	d := NewDispatcher([]config.WebhookConfig{
+		{URL: server.URL, Format: config.WebhookFormatSummary, OnFailureOnly: true},
+		{URL: events.server.URL},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	responses := map[string]*proto.TaskResponse{
+		"node1": {Id: 1},
+		"node2": {Id: 2, Error: "exit status 1", Retcode: 1},
+	}
+	d.NotifyRun(Run{Task: "cmd.run", Responses: map[string]*proto.TaskResponse{"node1": {Id: 1}}})
+	d.NotifyRun(Run{Task: "cmd.run", Responses: responses})
+	d.Notify(Event{ID: 2, Node: "node2", Task: "cmd.run", Status: "error"})
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 1 && len(events.received()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, Summary("cmd.run", responses), received[0].Text)
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	d := NewDispatcher([]config.WebhookConfig{{URL: server.URL}})
+	d.retryDelay = time.Millisecond
+
+	err := d.send(context.Background(), d.webhooks[0], []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestDispatcherRetriesExhausted(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher([]config.WebhookConfig{{URL: server.URL}})
+	d.retryDelay = time.Millisecond
+
+	err := d.send(context.Background(), d.webhooks[0], []byte(`{}`))
+	require.Error(t, err)
+	assert.Equal(t, int32(config.WebhookRetries), calls.Load())
+}
+
+func TestDispatcherTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	d := NewDispatcher([]config.WebhookConfig{{URL: server.URL}})
+	d.webhooks[0].timeout = 20 * time.Millisecond
+
+	start := time.Now()
+	err := d.post(context.Background(), d.webhooks[0], []byte(`{}`))
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestNotifyDoesNotBlock(t *testing.T) {
+	d := NewDispatcher([]config.WebhookConfig{{URL: "http://127.0.0.1:0"}})
+
+	done := make(chan struct{})
+	go func() {
+		// nothing consumes the queue: once full, events must be dropped
+		for i := range config.NotificationQueueSize + 10 {
+			d.Notify(Event{ID: int64(i)})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Notify blocked on a full queue")
+	}
+	assert.Len(t, d.events, config.NotificationQueueSize)
+}
+
+func TestNotifyWithoutWebhooks(t *testing.T) {
+	var d *Dispatcher
+	d.Notify(Event{ID: 1}) // must not panic
+
+	d = NewDispatcher(nil)
+	d.Notify(Event{ID: 1})
+	assert.Empty(t, d.events)
+}
+
+func TestDispatcherNodeEvents(t *testing.T) {
+	var mu sync.Mutex
+	var events []inventory.NodeEvent
+	var summaries []summaryPayload
+	record := func(into func(body []byte) error) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			body, _ := io.ReadAll(req.Body)
+			mu.Lock()
+			defer mu.Unlock()
+			if err := into(body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	eventServer := record(func(body []byte) error {
+		var e inventory.NodeEvent
+		err := json.Unmarshal(body, &e)
+		events = append(events, e)
+		return err
+	})
+	summaryServer := record(func(body []byte) error {
+		var p summaryPayload
+		err := json.Unmarshal(body, &p)
+		summaries = append(summaries, p)
+		return err
+	})
+	disabled := newRecorder(t)
+
+	d := NewDispatcher([]config.WebhookConfig{
+		{URL: eventServer.URL, NodeEvents: true},
+		{URL: summaryServer.URL, Format: config.WebhookFormatSummary, NodeEvents: true},
+		{URL: disabled.server.URL},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	lastMsg := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	stale := inventory.NodeEvent{Type: inventory.NodeStale, Node: "node1", Time: lastMsg.Add(time.Minute), LastMsg: lastMsg}
+	d.NotifyNode(stale)
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events) == 1 && len(summaries) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, stale, events[0])
+	assert.Equal(t, "**`node1`** is stale: no message since 2025-01-01 00:00:00 UTC", summaries[0].Text)
+	assert.Empty(t, disabled.received())
+}
@@ -2,22 +2,36 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net"
+	"slices"
 
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/helper"
 	"github.com/jackadi-io/jackadi/internal/manager/inventory"
 	"github.com/jackadi-io/jackadi/internal/node"
 	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/version"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
+// errNoCertificate is returned when mTLS is expected but the node did not present a certificate.
+var errNoCertificate = errors.New("node certificate required")
+
+// errIdentityMismatch is returned when the certificate of the node does not match its node ID.
+var errIdentityMismatch = errors.New("certificate identity does not match the node ID")
+
 // signatureFromContext extracts metadata from gRPC context.
 //
 // The main information is the node ID.
@@ -29,6 +43,9 @@ func signatureFromContext(ctx context.Context, mTLSEnabled bool) (inventory.Node
 	if err != nil {
 		return signature, errors.New("unspecified node_id")
 	}
+	if err := config.ValidateNodeID(nodeID); err != nil {
+		return signature, err
+	}
 
 	p, ok := peer.FromContext(ctx)
 	if !ok {
@@ -45,10 +62,10 @@ func signatureFromContext(ctx context.Context, mTLSEnabled bool) (inventory.Node
 	if mTLSEnabled {
 		tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
 		if !ok {
-			return signature, fmt.Errorf("unexpected node credentials")
+			return signature, fmt.Errorf("%w: unexpected node credentials", errNoCertificate)
 		}
 		if len(tlsInfo.State.PeerCertificates) < 1 {
-			return signature, fmt.Errorf("no node certificate found")
+			return signature, fmt.Errorf("%w: no node certificate found", errNoCertificate)
 		}
 
 		data, err := x509.MarshalPKIXPublicKey(tlsInfo.State.PeerCertificates[0].PublicKey)
@@ -61,35 +78,220 @@ func signatureFromContext(ctx context.Context, mTLSEnabled bool) (inventory.Node
 	return signature, nil
 }
 
+// peerCertificate returns the TLS client certificate of the node.
+func peerCertificate(ctx context.Context) (*x509.Certificate, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("failed to get node info")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) < 1 {
+		return nil, fmt.Errorf("%w: no node certificate found", errNoCertificate)
+	}
+	return tlsInfo.State.PeerCertificates[0], nil
+}
+
+// certificateFingerprint returns the SHA-256 fingerprint of the TLS client certificate of the node, empty without
+// certificate.
+func certificateFingerprint(ctx context.Context) string {
+	cert, err := peerCertificate(ctx)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(cert.Raw)
+	return "SHA256:" + hex.EncodeToString(sum[:])
+}
+
+// certificateNodeIDs returns the node IDs the certificate is issued for: its common name, its DNS names and
+// the node ID of its SPIFFE ID.
+func certificateNodeIDs(cert *x509.Certificate) []string {
+	var ids []string
+	if cert.Subject.CommonName != "" {
+		ids = append(ids, cert.Subject.CommonName)
+	}
+	ids = append(ids, cert.DNSNames...)
+	if id, err := x509svid.IDFromCert(cert); err == nil {
+		if nodeID, err := config.NodeIDFromSPIFFEID(id); err == nil {
+			ids = append(ids, nodeID)
+		}
+	}
+	return ids
+}
+
+// checkCertificateNodeID ensures the certificate of the node is issued for its node ID, so that a node with a
+// valid certificate cannot impersonate another one.
+func checkCertificateNodeID(ctx context.Context, nodeID node.ID) error {
+	cert, err := peerCertificate(ctx)
+	if err != nil {
+		return err
+	}
+	ids := certificateNodeIDs(cert)
+	if !slices.Contains(ids, string(nodeID)) {
+		return fmt.Errorf("%w: certificate '%s' (names: %v) is not issued for the node '%s'", errIdentityMismatch, cert.Subject, ids, nodeID)
+	}
+	return nil
+}
+
+// spiffeSignature replaces the certificate of the signature by the SPIFFE ID of the node SVID.
+//
+// The SVIDs are short-lived and rotated with new keys: the SPIFFE ID is the stable identity of the node, and
+// must match its node ID.
+func spiffeSignature(ctx context.Context, signature inventory.NodeIdentity, trustDomain string) (inventory.NodeIdentity, error) {
+	td, err := spiffeid.TrustDomainFromString(trustDomain)
+	if err != nil {
+		return signature, fmt.Errorf("invalid trust domain: %w", err)
+	}
+
+	cert, err := peerCertificate(ctx)
+	if err != nil {
+		return signature, err
+	}
+
+	id, err := x509svid.IDFromCert(cert)
+	if err != nil {
+		return signature, fmt.Errorf("%w: %w", errNoCertificate, err)
+	}
+	if !id.MemberOf(td) {
+		return signature, fmt.Errorf("%w: '%s' is not a member of '%s'", errIdentityMismatch, id, td)
+	}
+	nodeID, err := config.NodeIDFromSPIFFEID(id)
+	if err != nil {
+		return signature, fmt.Errorf("%w: %w", errIdentityMismatch, err)
+	}
+	if node.ID(nodeID) != signature.ID {
+		return signature, fmt.Errorf("%w: '%s' is not the node '%s'", errIdentityMismatch, id, signature.ID)
+	}
+
+	signature.Certificate = id.String()
+	return signature, nil
+}
+
+// nodeSignature returns the signature of the node calling the server, errors are gRPC status.
+//
+// The nodes without a TLS client certificate are refused when mTLS is enabled or required, and the nodes whose
+// certificate is not issued for their node ID when the match is enabled.
+func (s *Server) nodeSignature(ctx context.Context) (inventory.NodeIdentity, error) {
+	nd, err := signatureFromContext(ctx, s.config.MTLSEnabled || s.config.MTLSRequired)
+	switch {
+	case err != nil:
+	case s.config.SPIFFETrustDomain != "":
+		nd, err = spiffeSignature(ctx, nd, s.config.SPIFFETrustDomain)
+	case s.config.MTLSMatchNodeID && (s.config.MTLSEnabled || s.config.MTLSRequired):
+		err = checkCertificateNodeID(ctx, nd.ID)
+	}
+	switch {
+	case errors.Is(err, errNoCertificate):
+		slog.Warn("node refused: mTLS is required", "node", nd.ID, "address", nd.Address, "error", err)
+		return nd, status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, errIdentityMismatch):
+		slog.Warn("node refused: identity mismatch", "node", nd.ID, "address", nd.Address, "error", err)
+		return nd, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		return nd, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nd, nil
+}
+
 // Handshake handles node registration.
 //
 // It checks if the node changed to detect potential rogue.
 func (s *Server) Handshake(ctx context.Context, req *proto.HandshakeRequest) (*proto.HandshakeResponse, error) {
-	resp := &proto.HandshakeResponse{Id: req.GetId()}
-	nd, err := signatureFromContext(ctx, s.config.MTLSEnabled)
+	resp := &proto.HandshakeResponse{
+		Id:                req.GetId(),
+		Version:           s.config.Version,
+		Protocol:          config.ProtocolVersion,
+		HeartbeatInterval: helper.DurationToUint32(s.config.HeartbeatInterval),
+	}
+	nd, err := s.nodeSignature(ctx)
 	if err != nil {
-		return resp, status.Error(codes.InvalidArgument, err.Error())
+		return resp, err
+	}
+	resp.MaxConcurrentTasks, resp.MaxWaitingRequests = s.nodeLimits(nd.ID)
+
+	if err := version.CheckProtocol(req.GetProtocol()); err != nil {
+		slog.Error("node refused: incompatible protocol", "node", nd.ID, "node_version", req.GetVersion(), "manager_version", s.config.Version, "error", err)
+		return resp, status.Error(codes.FailedPrecondition, fmt.Sprintf("manager refused the node: %s", err))
+	}
+	if !version.SameMajor(s.config.Version, req.GetVersion()) {
+		slog.Warn("node and manager major versions differ", "node", nd.ID, "node_version", req.GetVersion(), "manager_version", s.config.Version)
 	}
 
 	if s.Inventory.IsRegistered(nd) {
+		s.Inventory.SetVersion(nd.ID, req.GetVersion(), req.GetProtocol())
 		return resp, nil
 	}
 
 	err = s.Inventory.AddCandidate(nd)
+	newCandidate := err == nil
 	if err != nil {
 		slog.Debug("node not added to candidates", "error", err, "node", nd.ID, "address", nd.Address)
 	} else {
 		slog.Debug("new node discovered", "node", nd.ID, "address", nd.Address)
 	}
 
+	// the new candidates left waiting are notified once, not on each retry of their handshake
 	if !s.config.AutoAccept {
+		if newCandidate {
+			s.Inventory.NotifyCandidate(nd)
+		}
+		return resp, status.Error(codes.PermissionDenied, "node not registered")
+	}
+	if err := s.autoAcceptable(ctx, nd.ID); err != nil {
+		slog.Info("node not auto-accepted, waiting for its acceptance", "node", nd.ID, "address", nd.Address, "reason", err)
+		if newCandidate {
+			s.Inventory.NotifyCandidate(nd)
+		}
 		return resp, status.Error(codes.PermissionDenied, "node not registered")
 	}
 
-	if err := s.Inventory.Register(nd, false); err != nil {
+	if err := s.Inventory.AutoAccept(nd, certificateFingerprint(ctx)); err != nil {
 		slog.Debug("node not auto-registered", "error", err)
 		return resp, status.Error(codes.Unknown, fmt.Sprintf("failed to auto-register node: %s", err))
 	}
+	s.Inventory.SetVersion(nd.ID, req.GetVersion(), req.GetProtocol())
 
 	return resp, err
 }
+
+// autoAcceptable returns why a new node cannot be auto-accepted: its ID does not match the allowed globs, or its
+// certificate is not issued by one of the allowed CAs.
+func (s *Server) autoAcceptable(ctx context.Context, id node.ID) error {
+	if len(s.config.AutoAcceptNodes) > 0 && !config.MatchNodeGlobs(s.config.AutoAcceptNodes, string(id)) {
+		return errors.New("node ID not allowed")
+	}
+	if s.config.AutoAcceptCAs == nil {
+		return nil
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return errors.New("failed to get node info")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) < 1 {
+		return errNoCertificate
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range tlsInfo.State.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := tlsInfo.State.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         s.config.AutoAcceptCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("certificate not issued by an allowed CA: %w", err)
+	}
+	return nil
+}
+
+// nodeLimits returns the queue limits overriding the configuration of the node, 0 if not overridden.
+func (s *Server) nodeLimits(id node.ID) (maxConcurrentTasks, maxWaitingRequests uint32) {
+	for _, l := range s.config.NodeLimits {
+		if l.Match(string(id)) {
+			return helper.IntToUint32(l.MaxConcurrentTasks), helper.IntToUint32(l.MaxWaitingRequests)
+		}
+	}
+	return 0, 0
+}
@@ -3,19 +3,40 @@ package server_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"net"
+	"net/url"
 	"testing"
+	"time"
 
 	badger "github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/config"
 	"github.com/jackadi-io/jackadi/internal/manager/forwarder"
 	"github.com/jackadi-io/jackadi/internal/manager/inventory"
 	"github.com/jackadi-io/jackadi/internal/manager/server"
 	"github.com/jackadi-io/jackadi/internal/node"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
 func newHandshakeServer(t *testing.T, autoAccept bool) (*server.Server, *inventory.Nodes) {
+	t.Helper()
+	return newHandshakeServerWithConfig(t, server.ServerConfig{AutoAccept: autoAccept, MTLSEnabled: false})
+}
+
+func newHandshakeServerWithConfig(t *testing.T, cfg server.ServerConfig) (*server.Server, *inventory.Nodes) {
 	t.Helper()
 	inv := inventory.New()
 	inv.DisableRegistryFile()
@@ -26,12 +47,12 @@ func newHandshakeServer(t *testing.T, autoAccept bool) (*server.Server, *invento
 		t.Fatalf("failed to open badger: %v", err)
 	}
 	t.Cleanup(func() { _ = db.Close() })
-	srv := server.New(server.ServerConfig{AutoAccept: autoAccept, MTLSEnabled: false}, &inv, dispatcher, db)
+	srv := server.New(cfg, &inv, dispatcher, db)
 	return &srv, &inv
 }
 
 // handshakeCtx reuses the execStream context builder to get a properly formed incoming gRPC context.
-func handshakeCtx(nodeID string) context.Context { //nolint:unparam // expected
+func handshakeCtx(nodeID string) context.Context {
 	return newExecStream(context.Background(), nodeID).ctx
 }
 
@@ -47,6 +68,21 @@ func TestHandshake_MissingNodeID(t *testing.T) {
 	}
 }
 
+func TestHandshake_InvalidNodeID(t *testing.T) {
+	srv, inv := newHandshakeServer(t, true)
+
+	for _, id := range []string{"node:1", "node1,node2", "web*", "my node"} {
+		_, err := srv.Handshake(handshakeCtx(id), &proto.HandshakeRequest{})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%q: expected InvalidArgument, got %v", id, err)
+		}
+	}
+
+	if accepted, candidates, _, _ := inv.List(); len(accepted) != 0 || len(candidates) != 0 {
+		t.Errorf("no node expected in the inventory, got accepted=%v candidates=%v", accepted, candidates)
+	}
+}
+
 func TestHandshake_UnknownNode_AutoAcceptFalse(t *testing.T) {
 	srv, inv := newHandshakeServer(t, false)
 
@@ -82,6 +118,187 @@ func TestHandshake_UnknownNode_AutoAcceptTrue(t *testing.T) {
 	}
 }
 
+func TestHandshake_AutoAcceptEvent(t *testing.T) {
+	srv, inv := newHandshakeServerWithConfig(t, server.ServerConfig{AutoAccept: true, MTLSEnabled: true})
+	events := inv.Subscribe(10)
+
+	ctx := certCtx(t, "node1", &x509.Certificate{Subject: pkix.Name{CommonName: "node1"}})
+	for range 2 { // the second handshake is the one of an accepted node
+		if _, err := srv.Handshake(ctx, &proto.HandshakeRequest{Protocol: config.ProtocolVersion}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	p, _ := peer.FromContext(ctx)
+	sum := sha256.Sum256(p.AuthInfo.(credentials.TLSInfo).State.PeerCertificates[0].Raw)
+	select {
+	case e := <-events:
+		if e.Type != inventory.NodeAutoAccepted || e.Node != "node1" || e.Address != "127.0.0.1" {
+			t.Errorf("unexpected event: %+v", e)
+		}
+		if want := "SHA256:" + hex.EncodeToString(sum[:]); e.Fingerprint != want {
+			t.Errorf("expected the fingerprint %s, got %s", want, e.Fingerprint)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no auto-accept event")
+	}
+	select {
+	case e := <-events:
+		t.Errorf("a single event expected, got %+v", e)
+	default:
+	}
+}
+
+func TestHandshake_CandidateEvent(t *testing.T) {
+	tests := map[string]server.ServerConfig{
+		"auto-accept disabled":      {},
+		"node ID not auto-accepted": {AutoAccept: true, AutoAcceptNodes: []string{"web-*"}},
+	}
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv, inv := newHandshakeServerWithConfig(t, cfg)
+			events := inv.Subscribe(10)
+
+			for range 3 { // the node retries its handshake until it is accepted
+				_, err := srv.Handshake(handshakeCtx("node1"), &proto.HandshakeRequest{Protocol: config.ProtocolVersion})
+				if status.Code(err) != codes.PermissionDenied {
+					t.Fatalf("expected PermissionDenied, got %v", err)
+				}
+			}
+
+			select {
+			case e := <-events:
+				if e.Type != inventory.NodeCandidate || e.Node != "node1" || e.Address != "127.0.0.1" {
+					t.Errorf("unexpected event: %+v", e)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("no candidate event")
+			}
+			select {
+			case e := <-events:
+				t.Errorf("a single event expected, got %+v", e)
+			default:
+			}
+		})
+	}
+}
+
+func TestHandshake_AutoAcceptNodes(t *testing.T) {
+	srv, inv := newHandshakeServerWithConfig(t, server.ServerConfig{AutoAccept: true, AutoAcceptNodes: []string{"web-*"}})
+
+	if _, err := srv.Handshake(handshakeCtx("web-1"), &proto.HandshakeRequest{Protocol: config.ProtocolVersion}); err != nil {
+		t.Fatalf("matching node refused: %v", err)
+	}
+	_, err := srv.Handshake(handshakeCtx("db-1"), &proto.HandshakeRequest{Protocol: config.ProtocolVersion})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for a node not allowed, got %v", err)
+	}
+
+	accepted, candidates, _, _ := inv.List()
+	if len(accepted) != 1 || accepted[0].ID != "web-1" {
+		t.Errorf("expected web-1 accepted, got %v", accepted)
+	}
+	if len(candidates) != 1 || candidates[0].ID != "db-1" {
+		t.Errorf("expected db-1 candidate, got %v", candidates)
+	}
+}
+
+// newCA returns a CA certificate and its key.
+func newCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+	return ca, key
+}
+
+// issuedCertCtx returns the context of a node presenting a certificate issued by the CA.
+func issuedCertCtx(t *testing.T, nodeID string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) context.Context {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: nodeID},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("node_id", nodeID))
+	return peer.NewContext(ctx, &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 9999},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}},
+	})
+}
+
+func TestHandshake_AutoAcceptCA(t *testing.T) {
+	allowed, allowedKey := newCA(t)
+	other, otherKey := newCA(t)
+	cas := x509.NewCertPool()
+	cas.AddCert(allowed)
+	cfg := server.ServerConfig{AutoAccept: true, MTLSEnabled: true, AutoAcceptNodes: []string{"web-*"}, AutoAcceptCAs: cas}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		accept bool
+	}{
+		{name: "allowed CA and node", ctx: issuedCertCtx(t, "web-1", allowed, allowedKey), accept: true},
+		{name: "other CA", ctx: issuedCertCtx(t, "web-1", other, otherKey)},
+		{name: "node not allowed", ctx: issuedCertCtx(t, "db-1", allowed, allowedKey)},
+		{name: "self-signed", ctx: certCtx(t, "web-1", &x509.Certificate{Subject: pkix.Name{CommonName: "web-1"}})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, inv := newHandshakeServerWithConfig(t, cfg)
+
+			_, err := srv.Handshake(tt.ctx, &proto.HandshakeRequest{Protocol: config.ProtocolVersion})
+			accepted, candidates, _, _ := inv.List()
+			if tt.accept {
+				if err != nil || len(accepted) != 1 {
+					t.Fatalf("expected the node auto-accepted, got %v, accepted=%v", err, accepted)
+				}
+				return
+			}
+			if status.Code(err) != codes.PermissionDenied {
+				t.Fatalf("expected PermissionDenied, got %v", err)
+			}
+			if len(accepted) != 0 || len(candidates) != 1 {
+				t.Errorf("expected the node left as candidate, got accepted=%v candidates=%v", accepted, candidates)
+			}
+		})
+	}
+}
+
 func TestHandshake_AlreadyRegistered(t *testing.T) {
 	srv, inv := newHandshakeServer(t, false)
 
@@ -116,3 +333,232 @@ func TestHandshake_SameNodeSecondCall(t *testing.T) {
 		t.Fatalf("second handshake failed: %v", err)
 	}
 }
+
+func TestHandshake_VersionExchange(t *testing.T) {
+	tests := []struct {
+		name        string
+		nodeVersion string
+		protocol    uint32
+	}{
+		{name: "same version", nodeVersion: "1.2.0", protocol: config.ProtocolVersion},
+		{name: "major version mismatch is only logged", nodeVersion: "2.0.0", protocol: config.ProtocolVersion},
+		{name: "node older than the version check", nodeVersion: "", protocol: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, inv := newHandshakeServerWithConfig(t, server.ServerConfig{AutoAccept: true, Version: "1.4.0"})
+
+			resp, err := srv.Handshake(handshakeCtx("node1"), &proto.HandshakeRequest{Version: tt.nodeVersion, Protocol: tt.protocol})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.GetVersion() != "1.4.0" || resp.GetProtocol() != config.ProtocolVersion {
+				t.Errorf("expected manager version 1.4.0 and protocol %d, got %s and %d", config.ProtocolVersion, resp.GetVersion(), resp.GetProtocol())
+			}
+
+			_, _, _, states := inv.List()
+			if states[node.ID("node1")].Version != tt.nodeVersion {
+				t.Errorf("expected node version %q to be recorded, got %q", tt.nodeVersion, states[node.ID("node1")].Version)
+			}
+			if inv.Protocol("node1") != tt.protocol {
+				t.Errorf("expected node protocol %d to be recorded, got %d", tt.protocol, inv.Protocol("node1"))
+			}
+		})
+	}
+}
+
+func TestHandshake_NodeLimits(t *testing.T) {
+	srv, _ := newHandshakeServerWithConfig(t, server.ServerConfig{AutoAccept: true, NodeLimits: []config.NodeLimitsConfig{
+		{Nodes: []string{"edge-*"}, MaxConcurrentTasks: 1, MaxWaitingRequests: 2},
+		{Nodes: []string{"edge-1", "db-*"}, MaxConcurrentTasks: 8},
+	}})
+
+	tests := map[string]struct {
+		concurrent, waiting uint32
+	}{
+		"edge-1": {1, 2}, // the first matching entry applies
+		"db-1":   {8, 0},
+		"web-1":  {0, 0},
+	}
+	for nodeID, want := range tests {
+		resp, err := srv.Handshake(handshakeCtx(nodeID), &proto.HandshakeRequest{Protocol: config.ProtocolVersion})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", nodeID, err)
+		}
+		if resp.GetMaxConcurrentTasks() != want.concurrent || resp.GetMaxWaitingRequests() != want.waiting {
+			t.Errorf("%s: expected limits %d/%d, got %d/%d", nodeID, want.concurrent, want.waiting, resp.GetMaxConcurrentTasks(), resp.GetMaxWaitingRequests())
+		}
+	}
+}
+
+func TestHandshake_IncompatibleProtocol(t *testing.T) {
+	srv, inv := newHandshakeServerWithConfig(t, server.ServerConfig{AutoAccept: true, Version: "1.4.0"})
+
+	resp, err := srv.Handshake(handshakeCtx("node1"), &proto.HandshakeRequest{Version: "1.4.0", Protocol: config.ProtocolVersion + 1})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+	if resp.GetProtocol() != config.ProtocolVersion {
+		t.Errorf("the manager protocol must be sent back even on failure, got %d", resp.GetProtocol())
+	}
+
+	accepted, candidates, _, _ := inv.List()
+	if len(accepted) != 0 || len(candidates) != 0 {
+		t.Errorf("incompatible node must not be registered nor candidate, got accepted=%v candidates=%v", accepted, candidates)
+	}
+}
+
+func TestHandshake_MTLSRequired(t *testing.T) {
+	srv, inv := newHandshakeServerWithConfig(t, server.ServerConfig{AutoAccept: true, MTLSRequired: true})
+
+	_, err := srv.Handshake(handshakeCtx("node1"), &proto.HandshakeRequest{Protocol: config.ProtocolVersion})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for a node without certificate, got %v", err)
+	}
+
+	accepted, candidates, _, _ := inv.List()
+	if len(accepted) != 0 || len(candidates) != 0 {
+		t.Errorf("node without certificate must not be registered nor candidate, got accepted=%v candidates=%v", accepted, candidates)
+	}
+}
+
+// certCtx returns the context of a node presenting a certificate created from the template.
+func certCtx(t *testing.T, nodeID string, tmpl *x509.Certificate) context.Context {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	tmpl.SerialNumber = big.NewInt(1)
+	tmpl.NotBefore = time.Now().Add(-time.Minute)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("node_id", nodeID))
+	return peer.NewContext(ctx, &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 9999},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}},
+	})
+}
+
+// svidCtx returns the context of a node presenting an SVID with the given SPIFFE ID.
+func svidCtx(t *testing.T, nodeID, spiffeID string) context.Context {
+	t.Helper()
+	id, err := url.Parse(spiffeID)
+	if err != nil {
+		t.Fatalf("invalid SPIFFE ID: %v", err)
+	}
+	return certCtx(t, nodeID, &x509.Certificate{URIs: []*url.URL{id}})
+}
+
+func TestHandshake_SPIFFE(t *testing.T) {
+	cfg := server.ServerConfig{AutoAccept: true, MTLSEnabled: true, SPIFFETrustDomain: "example.org"}
+
+	tests := []struct {
+		name     string
+		nodeID   string
+		spiffeID string
+		want     codes.Code
+	}{
+		{name: "matching node ID", nodeID: "web-1", spiffeID: "spiffe://example.org/jackadi/node/web-1", want: codes.OK},
+		{name: "other node ID", nodeID: "web-2", spiffeID: "spiffe://example.org/jackadi/node/web-1", want: codes.PermissionDenied},
+		{name: "other trust domain", nodeID: "web-1", spiffeID: "spiffe://other.org/jackadi/node/web-1", want: codes.PermissionDenied},
+		{name: "no path", nodeID: "web-1", spiffeID: "spiffe://example.org", want: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, inv := newHandshakeServerWithConfig(t, cfg)
+
+			_, err := srv.Handshake(svidCtx(t, tt.nodeID, tt.spiffeID), &proto.HandshakeRequest{Protocol: config.ProtocolVersion})
+			if status.Code(err) != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if tt.want != codes.OK {
+				return
+			}
+
+			accepted, _, _, _ := inv.List()
+			if len(accepted) != 1 || accepted[0].ID != node.ID(tt.nodeID) {
+				t.Fatalf("expected %s accepted, got %v", tt.nodeID, accepted)
+			}
+			if accepted[0].Certificate != tt.spiffeID {
+				t.Errorf("expected the SPIFFE ID as node identity, got %q", accepted[0].Certificate)
+			}
+		})
+	}
+}
+
+func TestHandshake_SPIFFERotation(t *testing.T) {
+	srv, _ := newHandshakeServerWithConfig(t, server.ServerConfig{AutoAccept: true, MTLSEnabled: true, SPIFFETrustDomain: "example.org"})
+	id := "spiffe://example.org/node1"
+
+	if _, err := srv.Handshake(svidCtx(t, "node1", id), &proto.HandshakeRequest{Protocol: config.ProtocolVersion}); err != nil {
+		t.Fatalf("first handshake failed: %v", err)
+	}
+	// a rotated SVID has a new key but the same SPIFFE ID
+	if _, err := srv.Handshake(svidCtx(t, "node1", id), &proto.HandshakeRequest{Protocol: config.ProtocolVersion}); err != nil {
+		t.Fatalf("handshake with a rotated SVID failed: %v", err)
+	}
+}
+
+func TestHandshake_MTLSMatchNodeID(t *testing.T) {
+	cfg := server.ServerConfig{AutoAccept: true, MTLSEnabled: true, MTLSMatchNodeID: true}
+
+	tests := []struct {
+		name   string
+		nodeID string
+		cert   *x509.Certificate
+		want   codes.Code
+	}{
+		{name: "common name", nodeID: "web-1", cert: &x509.Certificate{Subject: pkix.Name{CommonName: "web-1"}}, want: codes.OK},
+		{name: "DNS name", nodeID: "web-1", cert: &x509.Certificate{Subject: pkix.Name{CommonName: "web"}, DNSNames: []string{"web-0", "web-1"}}, want: codes.OK},
+		{name: "SPIFFE ID", nodeID: "web-1", cert: &x509.Certificate{URIs: []*url.URL{{Scheme: "spiffe", Host: "example.org", Path: "/node/web-1"}}}, want: codes.OK},
+		{name: "impersonation", nodeID: "web-2", cert: &x509.Certificate{Subject: pkix.Name{CommonName: "web-1"}, DNSNames: []string{"web-1"}}, want: codes.PermissionDenied},
+		{name: "no name", nodeID: "web-1", cert: &x509.Certificate{}, want: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, inv := newHandshakeServerWithConfig(t, cfg)
+
+			_, err := srv.Handshake(certCtx(t, tt.nodeID, tt.cert), &proto.HandshakeRequest{Protocol: config.ProtocolVersion})
+			if status.Code(err) != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+
+			accepted, candidates, _, _ := inv.List()
+			registered := len(accepted) + len(candidates)
+			if tt.want == codes.OK && registered != 1 {
+				t.Errorf("expected %s registered, got accepted=%v candidates=%v", tt.nodeID, accepted, candidates)
+			}
+			if tt.want != codes.OK && registered != 0 {
+				t.Errorf("mismatching node must not be registered, got accepted=%v candidates=%v", accepted, candidates)
+			}
+		})
+	}
+}
+
+func TestHandshake_MTLSMatchNodeIDDisabled(t *testing.T) {
+	srv, _ := newHandshakeServerWithConfig(t, server.ServerConfig{AutoAccept: true, MTLSEnabled: true})
+
+	ctx := certCtx(t, "web-2", &x509.Certificate{Subject: pkix.Name{CommonName: "web-1"}})
+	if _, err := srv.Handshake(ctx, &proto.HandshakeRequest{Protocol: config.ProtocolVersion}); err != nil {
+		t.Fatalf("the certificate names must not be checked when the match is disabled: %v", err)
+	}
+}
+
+func TestHandshake_MTLSMatchNodeIDWithoutMTLS(t *testing.T) {
+	srv, _ := newHandshakeServerWithConfig(t, server.ServerConfig{AutoAccept: true, MTLSMatchNodeID: true})
+
+	ctx := certCtx(t, "web-2", &x509.Certificate{Subject: pkix.Name{CommonName: "web-1"}})
+	if _, err := srv.Handshake(ctx, &proto.HandshakeRequest{Protocol: config.ProtocolVersion}); err != nil {
+		t.Fatalf("the certificate names must not be checked without mTLS: %v", err)
+	}
+}
@@ -25,7 +25,7 @@ type pluginInfo struct {
 func (s *Server) loadPluginsPolicies() (map[string][]pluginInfo, error) {
 	s.pluginPolicies.lock.Lock()
 	defer s.pluginPolicies.lock.Unlock()
-	if time.Since(s.pluginPolicies.lastUpdate) < 10*time.Second {
+	if s.clock.Now().Sub(s.pluginPolicies.lastUpdate) < 10*time.Second {
 		slog.Debug("get plugin list from cache")
 		return s.pluginPolicies.cache, nil
 	}
@@ -49,7 +49,11 @@ func (s *Server) loadPluginsPolicies() (map[string][]pluginInfo, error) {
 	for pattern, pluginList := range cfg {
 		for _, filename := range pluginList {
 			if checksum, ok := checksums[filename]; !ok || checksum == "" {
-				file := filepath.Join(s.config.PluginDir, filename)
+				file, err := hcplugin.Find(s.config.PluginDirs, filename)
+				if err != nil {
+					slog.Error("plugin not found", "error", err)
+					continue
+				}
 				chksum, err := hcplugin.CalculateChecksum(file)
 				if err != nil {
 					slog.Error("failed to calculate checksum", "file", file)
@@ -66,16 +70,16 @@ func (s *Server) loadPluginsPolicies() (map[string][]pluginInfo, error) {
 	}
 
 	s.pluginPolicies.cache = pluginsPerPattern
-	s.pluginPolicies.lastUpdate = time.Now()
+	s.pluginPolicies.lastUpdate = s.clock.Now()
 
 	return pluginsPerPattern, nil
 }
 
 func (s *Server) ListNodePlugins(ctx context.Context, req *emptypb.Empty) (*proto.ListNodePluginsResponse, error) {
 	resp := &proto.ListNodePluginsResponse{}
-	nd, err := signatureFromContext(ctx, s.config.MTLSEnabled)
+	nd, err := s.nodeSignature(ctx)
 	if err != nil {
-		return resp, status.Error(codes.InvalidArgument, err.Error())
+		return resp, err
 	}
 
 	pluginsPerPattern, err := s.loadPluginsPolicies()
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ReportPluginSync records the plugin sync of a node, reported when it changed its plugins or failed.
+func (s *Server) ReportPluginSync(ctx context.Context, req *proto.PluginSync) (*emptypb.Empty, error) {
+	nd, err := s.nodeSignature(ctx)
+	if err != nil {
+		return &emptypb.Empty{}, err
+	}
+	if !s.Inventory.IsRegistered(nd) {
+		return &emptypb.Empty{}, status.Error(codes.PermissionDenied, "node not registered")
+	}
+
+	changes := make([]inventory.PluginChange, 0, len(req.GetChanges()))
+	for _, c := range req.GetChanges() {
+		changes = append(changes, inventory.PluginChange{Type: c.GetType(), Name: c.GetName(), File: c.GetFile()})
+	}
+	s.Inventory.RecordPluginSync(nd.ID, changes, req.GetError())
+	slog.Debug("plugin sync reported", "node", nd.ID, "changes", len(changes), "error", req.GetError())
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,47 @@
+package server_test
+
+import (
+	"testing"
+
+	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReportPluginSync(t *testing.T) {
+	srv, inv := newHandshakeServer(t, true)
+
+	report := &proto.PluginSync{
+		Changes: []*proto.PluginChange{{Type: "updated", Name: "docker", File: "docker"}},
+		Error:   "failed to download the plugin git",
+	}
+	if _, err := srv.ReportPluginSync(handshakeCtx("node1"), report); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for an unregistered node, got %v", err)
+	}
+	if syncs := inv.GetPluginSyncs("node1"); len(syncs) != 0 {
+		t.Fatalf("no plugin sync expected from an unregistered node, got %v", syncs)
+	}
+
+	if _, err := srv.Handshake(handshakeCtx("node1"), &proto.HandshakeRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := srv.ReportPluginSync(handshakeCtx("node1"), report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	syncs := inv.GetPluginSyncs("node1")
+	if len(syncs) != 1 {
+		t.Fatalf("expected a single plugin sync, got %v", syncs)
+	}
+	want := inventory.PluginChange{Type: "updated", Name: "docker", File: "docker"}
+	if len(syncs[0].Changes) != 1 || syncs[0].Changes[0] != want {
+		t.Errorf("expected the changes %v, got %v", want, syncs[0].Changes)
+	}
+	if syncs[0].Error != report.GetError() {
+		t.Errorf("expected the error %q, got %q", report.GetError(), syncs[0].Error)
+	}
+	if syncs[0].Time.IsZero() {
+		t.Error("expected the plugin sync to be timestamped by the manager")
+	}
+}
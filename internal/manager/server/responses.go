@@ -0,0 +1,266 @@
+package server
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/clock"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+var ErrTooManyInflight = errors.New("too many in-flight requests")
+
+// responseRouter routes the responses of a node to the requesters waiting for them.
+//
+// Each dispatched request registers the channel of its requester with a deadline. The channels of
+// requests never answered are removed by a single reaper (see run) once their deadline is reached.
+type responseRouter struct {
+	limit      int // 0 means unlimited
+	clock      clock.Clock
+	mu         sync.Mutex
+	channels   map[int64]chan *proto.TaskResponse // key: request ID
+	heartbeats map[int64]time.Duration            // IDs of the heartbeats, whose responses are not results, and their timeout
+	tombstones map[int64]struct{}                 // IDs of the heartbeats expired, whose late responses are not results either
+	sent       map[int64]sentTask                 // key: request ID, without the heartbeats
+	transient  map[int64]struct{}                 // IDs of the transient requests, whose results are not stored
+	reported   reportedTasks
+	deadlines  deadlineHeap
+	wake       chan struct{}
+}
+
+type sentTask struct {
+	groupID *int64
+	task    string
+	at      time.Time
+}
+
+// reportedTasks are the long-running tasks reported by the node in its last heartbeat response.
+type reportedTasks struct {
+	tasks []*proto.RunningTask
+	at    time.Time
+}
+
+func newResponseRouter(limit int, c clock.Clock) *responseRouter {
+	return &responseRouter{
+		limit:      limit,
+		clock:      c,
+		channels:   make(map[int64]chan *proto.TaskResponse),
+		heartbeats: make(map[int64]time.Duration),
+		tombstones: make(map[int64]struct{}),
+		sent:       make(map[int64]sentTask),
+		transient:  make(map[int64]struct{}),
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// add registers the response channel of a request, it is removed after the timeout if no response is received.
+//
+// It returns ErrTooManyInflight if the limit of requests awaiting a response is reached.
+func (r *responseRouter) add(id int64, ch chan *proto.TaskResponse, timeout time.Duration) error {
+	r.mu.Lock()
+	if r.limit > 0 && len(r.channels)-len(r.heartbeats) >= r.limit {
+		r.mu.Unlock()
+		return ErrTooManyInflight
+	}
+	r.channels[id] = ch
+	heap.Push(&r.deadlines, deadline{id: id, at: r.clock.Now().Add(timeout)})
+	first := r.deadlines[0].id == id
+	r.mu.Unlock()
+
+	if first {
+		// the reaper must be woken up as it may be waiting for a later deadline
+		select {
+		case r.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// addHeartbeat registers the response channel of a heartbeat, not counted in the limit of requests.
+func (r *responseRouter) addHeartbeat(id int64, ch chan *proto.TaskResponse, timeout time.Duration) {
+	r.mu.Lock()
+	r.heartbeats[id] = timeout
+	r.mu.Unlock()
+	_ = r.add(id, ch, timeout) // cannot fail, the heartbeat is not counted
+}
+
+// isHeartbeat reports whether a registered request is a heartbeat, expired for one more timeout at most.
+func (r *responseRouter) isHeartbeat(id int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.heartbeats[id]
+	_, expired := r.tombstones[id]
+	return ok || expired
+}
+
+// markTransient records the registered request as transient, its response is not stored.
+func (r *responseRouter) markTransient(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transient[id] = struct{}{}
+}
+
+// isTransient reports whether a registered request is transient.
+func (r *responseRouter) isTransient(id int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.transient[id]
+	return ok
+}
+
+// markSent records the request as sent to the node, it is running until its response is taken.
+func (r *responseRouter) markSent(id int64, req *proto.TaskRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.channels[id]; ok {
+		r.sent[id] = sentTask{groupID: req.GroupID, task: req.FullTask(), at: r.clock.Now()}
+	}
+}
+
+// report records the long-running tasks reported by the node in a heartbeat response.
+func (r *responseRouter) report(tasks []*proto.RunningTask) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reported = reportedTasks{tasks: tasks, at: r.clock.Now()}
+}
+
+// running returns the requests sent and not answered yet, and the tasks reported by the node the manager
+// stopped waiting for, flagged orphaned.
+func (r *responseRouter) running() []*proto.RunningTask {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	tasks := make([]*proto.RunningTask, 0, len(r.sent))
+	for id, t := range r.sent {
+		tasks = append(tasks, &proto.RunningTask{
+			Id:      id,
+			GroupID: t.groupID,
+			Task:    t.task,
+			Elapsed: now.Sub(t.at).Milliseconds(),
+		})
+	}
+	for _, t := range r.reported.tasks {
+		if _, ok := r.sent[t.GetId()]; ok {
+			continue
+		}
+		tasks = append(tasks, &proto.RunningTask{
+			Id:       t.GetId(),
+			GroupID:  t.GroupID,
+			Task:     t.GetTask(),
+			Elapsed:  t.GetElapsed() + now.Sub(r.reported.at).Milliseconds(),
+			Orphaned: true,
+		})
+	}
+	return tasks
+}
+
+// take returns and unregisters the response channel of a request.
+func (r *responseRouter) take(id int64) (chan *proto.TaskResponse, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ch, ok := r.channels[id]
+	delete(r.channels, id)
+	delete(r.heartbeats, id)
+	delete(r.tombstones, id)
+	delete(r.sent, id)
+	delete(r.transient, id)
+	// answered since the last heartbeat, it is not running anymore
+	r.reported.tasks = slices.DeleteFunc(r.reported.tasks, func(t *proto.RunningTask) bool { return t.GetId() == id })
+	return ch, ok
+}
+
+// get returns the response channel of a request, without unregistering it.
+func (r *responseRouter) get(id int64) (chan *proto.TaskResponse, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ch, ok := r.channels[id]
+	return ch, ok
+}
+
+// len returns the number of requests waiting for a response.
+func (r *responseRouter) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.channels)
+}
+
+// run removes the expired response channels until the context is cancelled.
+func (r *responseRouter) run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		if next, ok := r.reap(r.clock.Now()); ok {
+			timer.Reset(next)
+		} else {
+			timer.Stop()
+		}
+
+		select {
+		case <-timer.C:
+		case <-r.wake:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reap removes the channels whose deadline is reached, and returns the delay until the next deadline.
+func (r *responseRouter) reap(now time.Time) (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for len(r.deadlines) > 0 {
+		next := r.deadlines[0]
+		if next.at.After(now) {
+			return next.at.Sub(now), true
+		}
+		heap.Pop(&r.deadlines)
+		if next.tombstone {
+			delete(r.tombstones, next.id)
+			continue
+		}
+		if timeout, ok := r.heartbeats[next.id]; ok {
+			// a late response of the heartbeat must not be taken for a result, it is recognized during
+			// another timeout
+			r.tombstones[next.id] = struct{}{}
+			heap.Push(&r.deadlines, deadline{id: next.id, at: next.at.Add(timeout), tombstone: true})
+		}
+		delete(r.channels, next.id)
+		delete(r.heartbeats, next.id)
+		delete(r.sent, next.id)
+		delete(r.transient, next.id)
+	}
+	return 0, false
+}
+
+type deadline struct {
+	id        int64
+	at        time.Time
+	tombstone bool // The end of the tombstone of a heartbeat, not of a request.
+}
+
+// deadlineHeap is a min-heap of deadlines, implementing heap.Interface.
+type deadlineHeap []deadline
+
+func (h deadlineHeap) Len() int           { return len(h) }
+func (h deadlineHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+func (h deadlineHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *deadlineHeap) Push(x any)        { *h = append(*h, x.(deadline)) }
+func (h *deadlineHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/clock"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseRouterTake(t *testing.T) {
+	r := newResponseRouter(0, clock.Real{})
+	ch := make(chan *proto.TaskResponse, 1)
+	require.NoError(t, r.add(1, ch, time.Minute))
+
+	got, ok := r.take(1)
+	require.True(t, ok)
+	assert.Equal(t, ch, got)
+
+	_, ok = r.take(1)
+	assert.False(t, ok, "a response channel must only be taken once")
+	assert.Equal(t, 0, r.len())
+}
+
+func TestResponseRouterReap(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	r := newResponseRouter(0, fake)
+	require.NoError(t, r.add(1, make(chan *proto.TaskResponse), time.Second))
+	require.NoError(t, r.add(2, make(chan *proto.TaskResponse), time.Hour))
+	require.NoError(t, r.add(3, make(chan *proto.TaskResponse), 2*time.Second))
+
+	next, ok := r.reap(fake.Now())
+	require.True(t, ok)
+	assert.Equal(t, time.Second, next)
+	assert.Equal(t, 3, r.len())
+
+	fake.Advance(3 * time.Second)
+	next, ok = r.reap(fake.Now())
+	require.True(t, ok)
+	assert.Equal(t, time.Hour-3*time.Second, next)
+	assert.Equal(t, 1, r.len())
+
+	_, ok = r.take(2)
+	assert.True(t, ok)
+
+	fake.Advance(2 * time.Hour)
+	_, ok = r.reap(fake.Now())
+	assert.False(t, ok)
+	assert.Empty(t, r.deadlines)
+}
+
+func TestResponseRouterHeartbeatTombstone(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	r := newResponseRouter(0, fake)
+	r.addHeartbeat(1, make(chan *proto.TaskResponse, 1), time.Second)
+	require.NoError(t, r.add(2, make(chan *proto.TaskResponse), time.Second))
+
+	// the heartbeat expired, its late response is still recognized as a heartbeat response
+	fake.Advance(time.Second)
+	next, ok := r.reap(fake.Now())
+	require.True(t, ok)
+	assert.Equal(t, time.Second, next)
+	assert.Equal(t, 0, r.len())
+	assert.True(t, r.isHeartbeat(1))
+	assert.False(t, r.isHeartbeat(2))
+	_, ok = r.take(1)
+	assert.False(t, ok, "the channel of an expired heartbeat must be removed")
+
+	r.addHeartbeat(3, make(chan *proto.TaskResponse, 1), time.Second)
+	fake.Advance(time.Second)
+	r.reap(fake.Now())
+	require.True(t, r.isHeartbeat(3))
+	fake.Advance(time.Second)
+	_, ok = r.reap(fake.Now())
+	assert.False(t, ok)
+	assert.False(t, r.isHeartbeat(3), "the tombstone of a heartbeat must be removed after another timeout")
+	assert.Empty(t, r.tombstones)
+}
+
+func TestResponseRouterBoundedGoroutines(t *testing.T) {
+	r := newResponseRouter(0, clock.Real{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.run(ctx)
+
+	before := runtime.NumGoroutine()
+	for i := range 10000 {
+		require.NoError(t, r.add(int64(i), make(chan *proto.TaskResponse, 1), 50*time.Millisecond))
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before+1)
+
+	require.Eventually(t, func() bool {
+		return r.len() == 0
+	}, 5*time.Second, 10*time.Millisecond, "expired response channels were not removed")
+}
+
+func BenchmarkResponseRouterDispatch(b *testing.B) {
+	r := newResponseRouter(0, clock.Real{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.run(ctx)
+
+	ch := make(chan *proto.TaskResponse, 1)
+	b.ResetTimer()
+	for i := range b.N {
+		_ = r.add(int64(i), ch, time.Second)
+		if i%2 == 0 {
+			r.take(int64(i))
+		}
+	}
+	b.ReportMetric(float64(runtime.NumGoroutine()), "goroutines")
+}
+
+func TestResponseRouterLimit(t *testing.T) {
+	r := newResponseRouter(3, clock.Real{})
+	for i := range 3 {
+		require.NoError(t, r.add(int64(i), make(chan *proto.TaskResponse), time.Minute))
+	}
+
+	err := r.add(3, make(chan *proto.TaskResponse), time.Minute)
+	require.ErrorIs(t, err, ErrTooManyInflight)
+	assert.Equal(t, 3, r.len(), "a rejected request must not be registered")
+	_, ok := r.take(3)
+	assert.False(t, ok)
+
+	// a response frees a slot
+	_, ok = r.take(0)
+	require.True(t, ok)
+	assert.NoError(t, r.add(3, make(chan *proto.TaskResponse), time.Minute))
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/jackadi-io/jackadi/internal/node"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+// registerRouter records the response router of the task stream of a node, to list its running tasks.
+func (s *Server) registerRouter(nodeID node.ID, r *responseRouter) {
+	s.routersMu.Lock()
+	defer s.routersMu.Unlock()
+	s.routers[nodeID] = r
+}
+
+// unregisterRouter removes the response router of a closing task stream, unless it belongs to a newer stream.
+func (s *Server) unregisterRouter(nodeID node.ID, r *responseRouter) {
+	s.routersMu.Lock()
+	defer s.routersMu.Unlock()
+	if s.routers[nodeID] == r {
+		delete(s.routers, nodeID)
+	}
+}
+
+// RunningTasks returns the tasks sent to the connected nodes and not answered yet, the longest running first.
+//
+// The tasks the manager stopped waiting for are only known once reported by their node in a heartbeat
+// response, if running for longer than config.LongRunningTask. If task is set, only its runs are returned.
+func (s *Server) RunningTasks(task string) []*proto.RunningTask {
+	s.routersMu.Lock()
+	routers := maps.Clone(s.routers)
+	s.routersMu.Unlock()
+
+	var tasks []*proto.RunningTask
+	for id, r := range routers {
+		for _, t := range r.running() {
+			if task != "" && t.GetTask() != task {
+				continue
+			}
+			t.Node = string(id)
+			tasks = append(tasks, t)
+		}
+	}
+	slices.SortFunc(tasks, func(a, b *proto.RunningTask) int {
+		return cmp.Or(cmp.Compare(b.GetElapsed(), a.GetElapsed()), cmp.Compare(a.GetNode(), b.GetNode()), cmp.Compare(a.GetId(), b.GetId()))
+	})
+	return tasks
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/clock"
+	"github.com/jackadi-io/jackadi/internal/node"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunningTasks(t *testing.T) {
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	groupID := int64(42)
+	run := &proto.TaskRequest{GroupID: &groupID, Plugin: "cmd", Task: "run"}
+
+	web := newResponseRouter(0, fake)
+	require.NoError(t, web.add(1, make(chan *proto.TaskResponse, 1), time.Hour))
+	web.markSent(1, run)
+	fake.Advance(time.Minute)
+
+	db := newResponseRouter(0, fake)
+	require.NoError(t, db.add(2, make(chan *proto.TaskResponse, 1), time.Hour))
+	db.markSent(2, run)
+	require.NoError(t, db.add(3, make(chan *proto.TaskResponse, 1), time.Hour))
+	db.markSent(3, &proto.TaskRequest{GroupID: &groupID, Plugin: "pkg", Task: "install"})
+	// registered but not sent yet
+	require.NoError(t, db.add(4, make(chan *proto.TaskResponse, 1), time.Hour))
+	// the manager stopped waiting for 5, the node still runs it
+	db.report([]*proto.RunningTask{
+		{Id: 2, GroupID: &groupID, Task: "cmd.run", Elapsed: 1000},
+		{Id: 5, Task: "cmd.run", Elapsed: 90000},
+	})
+	fake.Advance(time.Second)
+
+	s := Server{routers: map[node.ID]*responseRouter{"web": web, "db": db}}
+
+	tasks := s.RunningTasks("cmd.run")
+	require.Len(t, tasks, 3)
+	assert.Equal(t, &proto.RunningTask{Node: "db", Id: 5, Task: "cmd.run", Elapsed: 91000, Orphaned: true}, tasks[0])
+	assert.Equal(t, &proto.RunningTask{Node: "web", Id: 1, GroupID: &groupID, Task: "cmd.run", Elapsed: 61000}, tasks[1])
+	assert.Equal(t, &proto.RunningTask{Node: "db", Id: 2, GroupID: &groupID, Task: "cmd.run", Elapsed: 1000}, tasks[2])
+
+	assert.Len(t, s.RunningTasks(""), 4)
+
+	// answered
+	_, ok := db.take(2)
+	require.True(t, ok)
+	assert.Len(t, s.RunningTasks("cmd.run"), 2)
+
+	// a closing stream does not unregister the router of a newer stream
+	s.unregisterRouter("web", newResponseRouter(0, fake))
+	assert.Len(t, s.RunningTasks("cmd.run"), 2)
+	s.unregisterRouter("web", web)
+	assert.Len(t, s.RunningTasks("cmd.run"), 1)
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
@@ -11,22 +12,49 @@ import (
 	"time"
 
 	"github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/clock"
 	"github.com/jackadi-io/jackadi/internal/config"
 	"github.com/jackadi-io/jackadi/internal/helper"
+	"github.com/jackadi-io/jackadi/internal/logs"
 	"github.com/jackadi-io/jackadi/internal/manager/database"
 	"github.com/jackadi-io/jackadi/internal/manager/forwarder"
 	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/manager/notification"
 	"github.com/jackadi-io/jackadi/internal/node"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"github.com/jackadi-io/jackadi/internal/serializer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type ServerConfig struct {
-	AutoAccept  bool
-	MTLSEnabled bool
-	ConfigDir   string
-	PluginDir   string
+	AutoAccept bool
+	// AutoAcceptNodes restricts the auto-acceptance to the node IDs matching one of the globs, all if empty.
+	AutoAcceptNodes []string
+	// AutoAcceptCAs restricts the auto-acceptance to the nodes whose certificate is issued by one of the CAs, any
+	// if nil.
+	AutoAcceptCAs *x509.CertPool
+	MTLSEnabled   bool
+	MTLSRequired  bool // Refuse the nodes without a TLS client certificate, even if MTLSEnabled is false.
+	// MTLSMatchNodeID refuses the nodes whose certificate common name, DNS names or SPIFFE ID do not match
+	// their node ID, when mTLS is enabled or required.
+	MTLSMatchNodeID bool
+	// SPIFFETrustDomain identifies the nodes by the SPIFFE ID of their SVID, which must match their node ID.
+	SPIFFETrustDomain string
+	ConfigDir         string
+	PluginDirs        []string
+	MaxInflight       int    // Maximum number of requests awaiting a response, per node. Unlimited if 0.
+	Version           string // Build version of the manager, sent to the nodes during the handshake.
+	// HeartbeatInterval is the delay between two health:instant-ping sent on each task stream, to detect the
+	// half-open connections. The heartbeats are disabled if 0.
+	HeartbeatInterval time.Duration
+	HeartbeatTimeout  time.Duration // Wait for the answer to a heartbeat before closing the task stream.
+	ResponseGrace     time.Duration // Wait after the timeout of a task for the response of the node.
+	AcceptanceWait    time.Duration // Wait for a node to be accepted before closing its task stream, unlimited if 0.
+	// NodeLimits override the queue limits of the matching nodes, the first matching entry applies.
+	NodeLimits []config.NodeLimitsConfig
 }
 
 type Server struct {
@@ -34,11 +62,18 @@ type Server struct {
 	config          ServerConfig
 	Inventory       *inventory.Nodes
 	taskDispatcher  forwarder.Dispatcher[*proto.TaskRequest, *proto.TaskResponse]
-	db              *badger.DB
+	db              database.ResultStore
 	dbMutex         *sync.Mutex
 	shutdownRequest map[node.ID]chan struct{}
 	shutdownMu      sync.RWMutex
+	streams         map[node.ID]inventory.NodeIdentity // identity of the node owning the task stream
+	streamsMu       sync.Mutex
+	routers         map[node.ID]*responseRouter // requests awaiting a response on the task stream of each node
+	routersMu       sync.Mutex
 	pluginPolicies  pluginPolicies
+	notifier        *notification.Dispatcher
+	clock           clock.Clock
+	ids             *database.Sequence // IDs of the requests sent to the nodes, reused as result IDs.
 }
 
 type pluginPolicies struct {
@@ -47,7 +82,7 @@ type pluginPolicies struct {
 	lock       *sync.Mutex
 }
 
-func New(config ServerConfig, nodesInventory *inventory.Nodes, taskDispatcher forwarder.Dispatcher[*proto.TaskRequest, *proto.TaskResponse], jobDatabase *badger.DB) Server {
+func New(config ServerConfig, nodesInventory *inventory.Nodes, taskDispatcher forwarder.Dispatcher[*proto.TaskRequest, *proto.TaskResponse], jobDatabase database.ResultStore) Server {
 	return Server{
 		config:          config,
 		Inventory:       nodesInventory,
@@ -55,10 +90,22 @@ func New(config ServerConfig, nodesInventory *inventory.Nodes, taskDispatcher fo
 		db:              jobDatabase,
 		dbMutex:         &sync.Mutex{},
 		shutdownRequest: make(map[node.ID]chan struct{}),
+		streams:         make(map[node.ID]inventory.NodeIdentity),
+		routers:         make(map[node.ID]*responseRouter),
 		pluginPolicies:  pluginPolicies{lock: &sync.Mutex{}},
+		clock:           clock.Real{},
+		ids:             &database.Sequence{},
 	}
 }
 
+// Version returns the build version of the manager.
+func (s *Server) Version() string {
+	return s.config.Version
+}
+
+// RequestShutdown closes the task stream of a node.
+//
+// It is safe to call it multiple times or concurrently: only the first call closes the stream.
 func (s *Server) RequestShutdown(nodeID node.ID) error {
 	s.shutdownMu.Lock()
 	defer s.shutdownMu.Unlock()
@@ -67,18 +114,89 @@ func (s *Server) RequestShutdown(nodeID node.ID) error {
 	if !ok {
 		return errors.New("shutdownRequest channel not found")
 	}
-	if ch == nil {
-		return errors.New("shutdownRequest channel not initialized")
+	// the closed channel stays registered until its stream ends, it is only removed by the stream owning it
+	select {
+	case <-ch:
+		return errors.New("shutdown already requested")
+	default:
 	}
 
 	close(ch)
-	s.shutdownRequest[nodeID] = nil
 	if err := s.taskDispatcher.Forget(nodeID); err != nil {
 		slog.Error("fail to forget a node", "error", err)
 	}
 	return nil
 }
 
+// claimStream records the identity of the node opening a task stream.
+//
+// It returns a RogueNodeError if another node with the same ID but a different identity (address or
+// certificate) already has a stream. The returned bool is false if the stream is already owned by
+// the same identity: the new stream is a duplicate and must not release the claim.
+func (s *Server) claimStream(nd inventory.NodeIdentity) (bool, error) {
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+
+	existing, ok := s.streams[nd.ID]
+	if !ok {
+		s.streams[nd.ID] = nd
+		return true, nil
+	}
+	if existing != nd {
+		err := inventory.NewRogueNodeError(existing, nd)
+		slog.Error("concurrent connections with the same node ID", "error", err, "node", nd.ID, "connected_peer", existing.Address, "rejected_peer", nd.Address)
+		return false, err
+	}
+	return false, nil
+}
+
+// releaseStream removes the identity recorded by claimStream.
+func (s *Server) releaseStream(id node.ID) {
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+	delete(s.streams, id)
+}
+
+// registerShutdownChannel creates the shutdown channel of a new task stream.
+//
+// If the node already had a stream, the new one replaces it: the previous channel is kept open and
+// the previous stream stops by itself once the node reconnected.
+func (s *Server) registerShutdownChannel(nodeID node.ID) chan struct{} {
+	ch := make(chan struct{})
+	s.shutdownMu.Lock()
+	s.shutdownRequest[nodeID] = ch
+	s.shutdownMu.Unlock()
+	return ch
+}
+
+// unregisterShutdownChannel removes the shutdown channel of a closing task stream.
+//
+// The channel is not removed if it belongs to a newer stream of the same node, even if a shutdown was requested.
+func (s *Server) unregisterShutdownChannel(nodeID node.ID, ch chan struct{}) {
+	s.shutdownMu.Lock()
+	defer s.shutdownMu.Unlock()
+
+	if s.shutdownRequest[nodeID] == ch {
+		delete(s.shutdownRequest, nodeID)
+	}
+}
+
+// SetClock replaces the time source, mainly for testing purpose.
+func (s *Server) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// SetIDSequence sets the sequence generating the request and result IDs, shared with the forwarder generating the
+// group IDs stored in the same key space.
+func (s *Server) SetIDSequence(ids *database.Sequence) {
+	s.ids = ids
+}
+
+// SetNotifier enables the notifications of completed tasks.
+func (s *Server) SetNotifier(notifier *notification.Dispatcher) {
+	s.notifier = notifier
+}
+
 // GetInventory returns the server's inventory.
 func (s *Server) GetInventory() *inventory.Nodes {
 	return s.Inventory
@@ -88,32 +206,48 @@ func (s *Server) GetInventory() *inventory.Nodes {
 //
 // It stores the result itself by task ID. It also stores a mapping between a group ID and task IDs.
 // Group ID are grouping tasks response from a same request, i.e. when the request was targeting multiple nodes.
-func (s *Server) storeResult(nodeID node.ID, msg *proto.TaskResponse) {
+//
+// The task ID is the one of the response, echoed by the node: if a result of another node is already stored with
+// this ID, the result is stored with a new ID instead of overwriting it. It returns the response as stored, with the
+// new ID if any, to be sent to the requester.
+func (s *Server) storeResult(ctx context.Context, nodeID node.ID, msg *proto.TaskResponse) *proto.TaskResponse {
+	logger := logs.FromContext(ctx)
 	s.dbMutex.Lock()
 	defer s.dbMutex.Unlock()
 
+	if msg.GetId() == 0 {
+		return msg
+	}
+
+	stored := msg
+
 	dbDerr := s.db.Update(func(txn *badger.Txn) error {
-		data, err := database.MarshalTask(nodeID, msg)
-		if err != nil {
-			slog.Error("unable to record result", "error", "marshal error")
-			return err
+		id := strconv.FormatInt(msg.GetId(), 10)
+		key := database.GenerateResultKey(id)
+		if owner, err := database.ResultOwner(txn, key); !errors.Is(err, badger.ErrKeyNotFound) && (err != nil || owner != nodeID) {
+			newID := s.ids.Next(s.clock.Now())
+			logger.Warn("result ID already used by another node, result stored with a new ID", "owner", owner, "new_id", newID)
+			stored = protobuf.Clone(msg).(*proto.TaskResponse)
+			stored.Id = newID
+			id = strconv.FormatInt(newID, 10)
+			key = database.GenerateResultKey(id)
 		}
 
-		id := strconv.FormatInt(msg.GetId(), 10)
-		if msg.GetId() == 0 {
-			return nil
+		data, err := database.MarshalTask(nodeID, stored)
+		if err != nil {
+			logger.Error("unable to record result", "error", "marshal error")
+			return err
 		}
 
-		key := database.GenerateResultKey(id)
 		singleEntry := badger.NewEntry(key, data).WithTTL(config.DBTaskResultTTL)
 		if err := txn.SetEntry(singleEntry); err != nil {
-			slog.Error("unable to record result", "error", err)
+			logger.Error("unable to record result", "error", err)
 			return err
 		}
 
 		// map the result to the matching groupID
 		if msg.GetGroupID() == 0 {
-			slog.Debug("no need to group the result", "msg", "no group ID defined")
+			logger.Debug("no need to group the result", "msg", "no group ID defined")
 			return nil
 		}
 		groupID := strconv.FormatInt(msg.GetGroupID(), 10)
@@ -121,69 +255,133 @@ func (s *Server) storeResult(nodeID node.ID, msg *proto.TaskResponse) {
 		item, err := txn.Get(groupKey)
 		if err != nil {
 			if !errors.Is(err, badger.ErrKeyNotFound) {
-				slog.Error("unable to group the result", "error", "marshal error")
+				logger.Error("unable to group the result", "error", "marshal error")
 				return err
 			}
 			groupEntry := badger.NewEntry(groupKey, []byte("grouped:"+id)).WithTTL(config.DBTaskResultTTL)
 			if err := txn.SetEntry(groupEntry); err != nil {
-				slog.Error("unable to record the new group", "error", err)
+				logger.Error("unable to record the new group", "error", err)
 				return err
 			}
 			return nil
 		}
 		groupEntry, err := item.ValueCopy(nil)
 		if err != nil {
-			slog.Error("unable to get value of existing group", "error", err)
+			logger.Error("unable to get value of existing group", "error", err)
 			return err
 		}
 
 		groupEntry = append(groupEntry, []byte(","+id)...)
 		if err := txn.Set(groupKey, groupEntry); err != nil {
-			slog.Error("unable to get update the existing group", "error", err)
+			logger.Error("unable to get update the existing group", "error", err)
 			return err
 		}
 
-		slog.Debug("updating group", "value", string(groupEntry))
+		logger.Debug("updating group", "value", string(groupEntry))
 		return nil
 	})
 	if dbDerr != nil {
-		slog.Warn("failed to store task result", "error", dbDerr)
+		logger.Warn("failed to store task result", "error", dbDerr)
+		return msg
+	}
+	return stored
+}
+
+// notify sends a notification for a completed task.
+//
+// Only the tasks requested through the forwarder are notified, internal tasks (e.g. specs collection)
+// are not recorded as requests.
+func (s *Server) notify(ctx context.Context, nodeID node.ID, msg *proto.TaskResponse) {
+	if s.notifier == nil || msg.GetGroupID() == 0 {
+		return
+	}
+
+	var task string
+	err := s.db.View(func(txn *badger.Txn) error {
+		var err error
+		task, err = database.GetRequestTask(txn, msg.GetGroupID())
+		return err
+	})
+	if err != nil {
+		logs.FromContext(ctx).Debug("no notification sent", "error", err)
+		return
 	}
+
+	s.notifier.Notify(notification.Event{
+		ID:      msg.GetId(),
+		GroupID: msg.GetGroupID(),
+		Node:    string(nodeID),
+		Task:    task,
+		Status:  database.ResultStatus(msg),
+		Retcode: msg.GetRetcode(),
+		Error:   msg.GetError(),
+	})
 }
 
 // dispatchRequestsToNode waits for requests and sends them to the linked node.
-func (s *Server) dispatchRequestsToNode(nodeID node.ID, stream proto.Cluster_ExecTaskServer, responsesCh map[int64]chan *proto.TaskResponse, responsesChLock *sync.Mutex) error {
+func (s *Server) dispatchRequestsToNode(nodeID node.ID, stream proto.Cluster_ExecTaskServer, responses *responseRouter) error {
 	tasksCh, err := s.taskDispatcher.GetTasksChannel(nodeID)
 	if err != nil {
 		return err
 	}
 
+	// the nodes older than the version check only know the deprecated plugin.task form of the task
+	legacy := s.Inventory.Protocol(nodeID) == 0
+
 	for d := range tasksCh {
-		ID := time.Now().UnixNano()
-		err := stream.Send(
-			&proto.TaskRequest{
-				Id:      ID,
-				GroupID: d.Request.GroupID,
-				Task:    d.Request.Task,
-				Input:   d.Request.GetInput(),
-				Timeout: d.Request.Timeout,
-			},
-		)
+		ID := s.ids.Next(s.clock.Now())
+		logger := logs.FromContext(logs.With(stream.Context(), "request_id", ID, "group_id", d.Request.GetGroupID(), "node", nodeID))
+
+		// the response channel is registered before sending the request to not miss a fast response.
+		// It is removed after the timeout and the grace, as long as the requester waits for the response,
+		// to avoid memory leak when responses are never received.
+		timeout := forwarder.TaskTimeout(d.Request) + s.config.ResponseGrace
+		if isHeartbeat(d.Request) {
+			responses.addHeartbeat(ID, d.ResponseCh, s.config.HeartbeatTimeout)
+		} else if err := responses.add(ID, d.ResponseCh, timeout); err != nil {
+			logger.Warn("task rejected", "error", err, "task", d.Request.FullTask())
+			select {
+			case d.ResponseCh <- &proto.TaskResponse{
+				Id:            ID,
+				GroupID:       d.Request.GroupID,
+				InternalError: proto.InternalError_FULL_QUEUE,
+				ModuleError:   err.Error(),
+			}:
+			default:
+			}
+			continue
+		}
+		if d.Request.GetTransient() {
+			responses.markTransient(ID)
+		}
+
+		req := &proto.TaskRequest{
+			Id:      ID,
+			GroupID: d.Request.GroupID,
+			Plugin:  d.Request.GetPlugin(),
+			Task:    d.Request.GetTask(),
+			Input:   d.Request.GetInput(),
+			Timeout: d.Request.Timeout,
+
+			LockMode: d.Request.GetLockMode(),
+			Priority: d.Request.GetPriority(),
+
+			DownstreamTargets:  d.Request.GetDownstreamTargets(),
+			DownstreamExcludes: d.Request.GetDownstreamExcludes(),
+		}
+		if legacy {
+			req.Plugin, req.Task = "", d.Request.FullTask()
+		}
+		err := stream.Send(req)
 		if err != nil {
-			slog.Error("failed to send task", "err", err, "node", nodeID)
+			responses.take(ID)
+			logger.Error("failed to send task", "err", err)
 			return err
 		}
-		responsesChLock.Lock()
-		responsesCh[ID] = d.ResponseCh
-		responsesChLock.Unlock()
-		d := d
-		go func() {
-			// ensure cleaning to avoid memory leak when responses never received
-			time.Sleep(time.Duration(d.Request.Timeout) * time.Second)
-			responsesChLock.Lock()
-			delete(responsesCh, ID)
-			responsesChLock.Unlock()
-		}()
+		if !isHeartbeat(d.Request) {
+			responses.markSent(ID, d.Request)
+		}
+		logger.Debug("task sent", "task", d.Request.FullTask())
 	}
 	return nil
 }
@@ -191,13 +389,9 @@ func (s *Server) dispatchRequestsToNode(nodeID node.ID, stream proto.Cluster_Exe
 // dispatchNodeResponse waits for a node's response and sends it back to the requester.
 //
 // It stores all received responses to the job database.
-func (s *Server) dispatchNodeResponse(stream proto.Cluster_ExecTaskServer, nodeID node.ID, responsesCh map[int64]chan *proto.TaskResponse, responsesChLock *sync.Mutex) error {
+func (s *Server) dispatchNodeResponse(stream proto.Cluster_ExecTaskServer, nodeID node.ID, shutdownCh <-chan struct{}, responses *responseRouter) error {
 	ctx := stream.Context()
 
-	s.shutdownMu.RLock()
-	shutdownCh := s.shutdownRequest[nodeID]
-	s.shutdownMu.RUnlock()
-
 	for {
 		msg, err := stream.Recv()
 		select {
@@ -219,33 +413,55 @@ func (s *Server) dispatchNodeResponse(stream proto.Cluster_ExecTaskServer, nodeI
 			return err
 		}
 
-		slog.Debug("received task response", "id", msg.GetId(), "node", nodeID, "group", msg.GetGroupID())
-		if msg.GetInternalError() != proto.InternalError_STARTED_TIMEOUT {
+		msgCtx := logs.With(ctx, "request_id", msg.GetId(), "group_id", msg.GetGroupID(), "node", nodeID)
+		logger := logs.FromContext(msgCtx)
+
+		if msg.Progress != nil {
+			// a progress update is not a result: it is neither stored nor notified, and the requester
+			// keeps waiting for the final response.
+			s.Inventory.MarkNodeActive(nodeID)
+			if ch, ok := responses.get(msg.GetId()); ok {
+				select {
+				case ch <- msg:
+				default:
+					logger.Debug("progress update dropped", "error", "requester busy")
+				}
+			}
+			continue
+		}
+
+		logger.Debug("received task response")
+		if responses.isHeartbeat(msg.GetId()) {
+			s.Inventory.MarkNodeActive(nodeID)
+			if ch, ok := responses.take(msg.GetId()); ok {
+				ch <- msg // buffered by the heartbeat
+			}
+			continue
+		}
+
+		// the response is routed by the ID of the request, the requester gets the ID of the stored result
+		requestID := msg.GetId()
+		if msg.GetInternalError() != proto.InternalError_STARTED_TIMEOUT && !responses.isTransient(requestID) {
 			// we don't store the message if the task has started to avoid duplicate entries if the task finishes after the timeout
-			s.storeResult(nodeID, msg)
+			msg = s.storeResult(msgCtx, nodeID, msg)
+			s.notify(msgCtx, nodeID, msg)
 		}
 
 		if msg.GetInternalError() == proto.InternalError_OK {
 			s.Inventory.MarkNodeActive(nodeID)
 		}
 
-		responsesChLock.Lock()
-		ch, ok := responsesCh[msg.GetId()]
-		responsesChLock.Unlock()
-		if ok {
+		if ch, ok := responses.take(requestID); ok {
 			select {
 			case ch <- msg:
 			case <-time.After(config.ResponseChannelTimeout):
 			}
-			responsesChLock.Lock()
-			delete(responsesCh, msg.GetId())
-			responsesChLock.Unlock()
 		} else {
-			slog.Info("response not sent to the caller", "err", "response channel not found", "node", nodeID, "id", msg.GetId())
+			logger.Info("response not sent to the caller", "err", "response channel not found")
 		}
 
 		if msg.GetInternalError() > 0 {
-			slog.Error("the node rejected the request", "error", msg.GetInternalError(), "message", msg.GetModuleError(), "request_id", msg.GetId(), "node", nodeID)
+			logger.Error("the node rejected the request", "error", msg.GetInternalError(), "message", msg.GetModuleError())
 		}
 	}
 }
@@ -255,23 +471,25 @@ func (s *Server) dispatchNodeResponse(stream proto.Cluster_ExecTaskServer, nodeI
 // It handles the sending of requests and the routing of the response.
 // The responses are routed to the dispatcher (usually the forwarder).
 func (s *Server) ExecTask(stream proto.Cluster_ExecTaskServer) error {
-	nd, err := signatureFromContext(stream.Context(), s.config.MTLSEnabled)
+	nd, err := s.nodeSignature(stream.Context())
 	if err != nil {
 		return err
 	}
 	slog.Debug("new 'task' stream with node", "node", nd.ID)
-	s.shutdownMu.Lock()
-	s.shutdownRequest[nd.ID] = make(chan struct{})
-	s.shutdownMu.Unlock()
-	defer func() {
-		s.shutdownMu.Lock()
-		delete(s.shutdownRequest, nd.ID)
-		s.shutdownMu.Unlock()
-	}()
 
-	for !s.Inventory.IsRegistered(nd) {
-		slog.Debug("cannot start 'Task' stream", "error", "node not registered", "retry_in", fmt.Sprintf("%d", int(config.NodeRetryDelay.Seconds())))
-		time.Sleep(config.NodeRetryDelay)
+	owner, err := s.claimStream(nd)
+	if err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	if owner {
+		defer s.releaseStream(nd.ID)
+	}
+
+	shutdownCh := s.registerShutdownChannel(nd.ID)
+	defer s.unregisterShutdownChannel(nd.ID, shutdownCh)
+
+	if err := s.waitAccepted(stream.Context(), nd); err != nil {
+		return err
 	}
 
 	if err := s.taskDispatcher.RegisterNode(nd.ID); err != nil {
@@ -286,23 +504,125 @@ func (s *Server) ExecTask(stream proto.Cluster_ExecTaskServer) error {
 		s.Inventory.MarkNodeStateChange(nd.ID, false)
 	}()
 
-	responsesCh := make(map[int64]chan *proto.TaskResponse)
-	lock := &sync.Mutex{} // TODO: replace map[]chan by a single thread channel manager
-	errCh := make(chan error)
+	responses := newResponseRouter(s.config.MaxInflight, s.clock)
+	reaperCtx, stopReaper := context.WithCancel(context.Background())
+	defer stopReaper()
+	go responses.run(reaperCtx)
+	s.registerRouter(nd.ID, responses)
+	defer s.unregisterRouter(nd.ID, responses)
+
+	// the stream ends when the node stops it, or when it does not answer the heartbeats: the stream of a
+	// half-open connection never ends by itself.
+	heartbeatCtx, stopHeartbeat := context.WithCancel(stream.Context())
+	defer stopHeartbeat()
+	streamErrCh := make(chan error, 2)
+	go func() {
+		streamErrCh <- s.dispatchNodeResponse(stream, nd.ID, shutdownCh, responses)
+	}()
+	go func() {
+		if err := s.heartbeat(heartbeatCtx, nd.ID, responses); err != nil {
+			slog.Warn("closing half-open task stream", "node", nd.ID, "peer", nd.Address, "error", err)
+			streamErrCh <- status.Error(codes.Unavailable, err.Error())
+		}
+	}()
 
+	errCh := make(chan error)
 	go func() {
-		err := s.dispatchNodeResponse(stream, nd.ID, responsesCh, lock)
+		err := <-streamErrCh
 		slog.Debug("closing node dispatcher", "node", nd.ID)
 		s.taskDispatcher.Close(nd.ID)
 		slog.Debug("node dispatcher closed", "node", nd.ID)
 		errCh <- err
 	}()
 
-	err = s.dispatchRequestsToNode(nd.ID, stream, responsesCh, lock)
+	err = s.dispatchRequestsToNode(nd.ID, stream, responses)
 
 	return errors.Join(err, <-errCh)
 }
 
+// isHeartbeat reports whether a request is a heartbeat sent by the manager, and not a run.
+func isHeartbeat(req *proto.TaskRequest) bool {
+	plugin, task := req.PluginTask()
+	return req.GroupID == nil && plugin == "health" && task == config.InstantPingName
+}
+
+// waitAccepted waits for the node to be accepted, during AcceptanceWait at most.
+//
+// The task stream of a node not accepted in time is closed with FailedPrecondition, the node opens a new one after
+// its retry delay: the pending nodes are reported in the logs instead of holding a stream indefinitely.
+func (s *Server) waitAccepted(ctx context.Context, nd inventory.NodeIdentity) error {
+	if s.Inventory.IsRegistered(nd) {
+		return nil
+	}
+
+	var deadline <-chan time.Time
+	if s.config.AcceptanceWait > 0 {
+		timer := time.NewTimer(s.config.AcceptanceWait)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	tick := time.NewTicker(config.NodeRetryDelay)
+	defer tick.Stop()
+
+	slog.Info("node waiting to be accepted", "node", nd.ID, "peer", nd.Address, "wait", s.config.AcceptanceWait)
+	for !s.Inventory.IsRegistered(nd) {
+		select {
+		case <-tick.C:
+			slog.Debug("cannot start 'Task' stream", "error", "node not registered", "node", nd.ID)
+		case <-deadline:
+			slog.Warn("closing task stream of a node not accepted", "node", nd.ID, "peer", nd.Address, "wait", s.config.AcceptanceWait)
+			return status.Errorf(codes.FailedPrecondition, "node not accepted within %s, accept it with 'jack nodes accept %s'", s.config.AcceptanceWait, nd.ID)
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	return nil
+}
+
+// heartbeat sends a health:instant-ping to the node at each heartbeat interval until the context is cancelled,
+// and records the long-running tasks reported in the responses.
+//
+// It returns an error if the node does not answer in time: the task stream is half-open.
+func (s *Server) heartbeat(ctx context.Context, nodeID node.ID, responses *responseRouter) error {
+	if s.config.HeartbeatInterval <= 0 {
+		return nil
+	}
+	timeout := s.config.HeartbeatTimeout
+
+	tick := time.NewTicker(s.config.HeartbeatInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+		case <-ctx.Done():
+			return nil
+		}
+
+		req := &proto.TaskRequest{
+			Plugin:  "health",
+			Task:    config.InstantPingName,
+			Timeout: helper.DurationToUint32(timeout),
+		}
+		resp := make(chan *proto.TaskResponse, 1)
+		task := forwarder.Task[*proto.TaskRequest, *proto.TaskResponse]{Request: req, ResponseCh: resp}
+		if err := s.taskDispatcher.Send(nodeID, task, timeout); err != nil {
+			if errors.Is(err, forwarder.ErrTimeout) {
+				return fmt.Errorf("heartbeat not sent in %s", timeout)
+			}
+			return nil // the stream is closing
+		}
+
+		select {
+		case r := <-resp:
+			responses.report(r.GetRunning())
+		case <-time.After(timeout):
+			return fmt.Errorf("heartbeat not answered in %s", timeout)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
 func (s *Server) CollectNodesSpecs(ctx context.Context) {
 	timeout := config.TaskTimeout
 	tick := time.NewTicker(config.SpecCollectionInterval)
@@ -328,7 +648,8 @@ func (s *Server) CollectNodesSpecs(ctx context.Context) {
 				defer wg.Done()
 
 				req := &proto.TaskRequest{
-					Task:    config.SpecManagerPrefix + config.PluginSeparator + "all",
+					Plugin:  config.SpecManagerPrefix,
+					Task:    "all",
 					Input:   &proto.Input{Args: &structpb.ListValue{}},
 					Timeout: helper.DurationToUint32(timeout),
 				}
@@ -346,11 +667,14 @@ func (s *Server) CollectNodesSpecs(ctx context.Context) {
 				}
 
 				var res *proto.TaskResponse
-				select {
-				case res = <-resp:
-				case <-time.After(timeout + 30*time.Second):
-					slog.Warn("timeout waiting for specs", "node", nd)
-					return
+				deadline := time.After(timeout + 30*time.Second)
+				for res == nil || res.Progress != nil {
+					select {
+					case res = <-resp:
+					case <-deadline:
+						slog.Warn("timeout waiting for specs", "node", nd)
+						return
+					}
 				}
 				if res.GetError() != "" {
 					slog.Warn("failed to collect specs", "node", nd, "error", res.GetError())
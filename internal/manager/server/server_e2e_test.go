@@ -2,23 +2,50 @@
 package server_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"log/slog"
+	"maps"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	badger "github.com/dgraph-io/badger/v4"
+	"github.com/jackadi-io/jackadi/internal/clock"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
 	"github.com/jackadi-io/jackadi/internal/manager/forwarder"
 	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/manager/notification"
 	"github.com/jackadi-io/jackadi/internal/manager/server"
+	"github.com/jackadi-io/jackadi/internal/manager/syndic"
 	"github.com/jackadi-io/jackadi/internal/node"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
 )
 
 // execStream is a mock bidirectional gRPC stream connecting the manager server to a simulated node.
@@ -121,6 +148,7 @@ func (s *execStream) nodeDisconnect() {
 
 // harness wires up all components for end-to-end testing without a real network.
 type harness struct {
+	db         *badger.DB
 	inv        *inventory.Nodes
 	dispatcher forwarder.Dispatcher[*proto.TaskRequest, *proto.TaskResponse]
 	srv        *server.Server
@@ -129,19 +157,39 @@ type harness struct {
 
 func newHarness(t *testing.T) *harness {
 	t.Helper()
+	return newHarnessWithConfig(t, server.ServerConfig{AutoAccept: false, MTLSEnabled: false})
+}
 
-	inv := inventory.New()
-	inv.DisableRegistryFile()
-
-	dispatcher := forwarder.NewDispatcher[*proto.TaskRequest, *proto.TaskResponse](&inv)
+func newHarnessWithConfig(t *testing.T, cfg server.ServerConfig) *harness {
+	t.Helper()
 
 	opts := badger.DefaultOptions(t.TempDir()).WithLogger(nil)
 	db, err := badger.Open(opts)
 	require.NoError(t, err)
 	t.Cleanup(func() { _ = db.Close() })
 
-	srv := server.New(server.ServerConfig{AutoAccept: false, MTLSEnabled: false}, &inv, dispatcher, db)
-	fwd := forwarder.New(dispatcher, db)
+	h := newHarnessWithStore(t, cfg, db)
+	h.db = db
+	return h
+}
+
+// newHarnessWithStore wires up the components storing the results in store, the database of the harness is unset.
+func newHarnessWithStore(t *testing.T, cfg server.ServerConfig, store database.ResultStore) *harness {
+	t.Helper()
+
+	inv := inventory.New()
+	inv.DisableRegistryFile()
+
+	dispatcher := forwarder.NewDispatcher[*proto.TaskRequest, *proto.TaskResponse](&inv)
+
+	// a short grace for the tests of the nodes never answering
+	cfg.ResponseGrace = 500 * time.Millisecond
+	srv := server.New(cfg, &inv, dispatcher, store)
+	fwd := forwarder.New(dispatcher, store)
+	fwd.SetResponseGrace(cfg.ResponseGrace)
+	ids := &database.Sequence{}
+	srv.SetIDSequence(ids)
+	fwd.SetIDSequence(ids)
 
 	return &harness{
 		inv:        &inv,
@@ -155,10 +203,17 @@ func newHarness(t *testing.T) *harness {
 // Returns the stream (for the test to drive node behaviour) and an error channel that receives
 // the return value of ExecTask when the stream ends.
 func (h *harness) connectNode(t *testing.T, nodeID string) (*execStream, chan error) {
+	t.Helper()
+	return h.connectNodeProtocol(t, nodeID, config.ProtocolVersion)
+}
+
+// connectNodeProtocol connects a node which sent the protocol version during its handshake.
+func (h *harness) connectNodeProtocol(t *testing.T, nodeID string, protocol uint32) (*execStream, chan error) {
 	t.Helper()
 	nd := inventory.NodeIdentity{ID: node.ID(nodeID), Address: "127.0.0.1"}
 	require.NoError(t, h.inv.AddCandidate(nd))
 	require.NoError(t, h.inv.Register(nd, false))
+	h.inv.SetVersion(nd.ID, "", protocol)
 
 	stream := newExecStream(context.Background(), nodeID)
 	errCh := make(chan error, 1)
@@ -182,6 +237,45 @@ func (h *harness) execTask(ctx context.Context, nodeID, task string, timeoutSec
 	})
 }
 
+// fwdStream is a mock server stream recording the messages sent by the forwarder's StreamTask.
+type fwdStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	msgs    chan *proto.FwdStreamResponse
+	header  metadata.MD
+	trailer metadata.MD
+}
+
+func newFwdStream() *fwdStream {
+	return &fwdStream{ctx: context.Background(), msgs: make(chan *proto.FwdStreamResponse, 10)}
+}
+
+func (s *fwdStream) Send(msg *proto.FwdStreamResponse) error {
+	s.msgs <- msg
+	return nil
+}
+
+func (s *fwdStream) Context() context.Context { return s.ctx }
+
+func (s *fwdStream) SendHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *fwdStream) SetTrailer(md metadata.MD) { s.trailer = metadata.Join(s.trailer, md) }
+
+// next returns the next message sent by the forwarder.
+func (s *fwdStream) next(t *testing.T) *proto.FwdStreamResponse {
+	t.Helper()
+	select {
+	case msg := <-s.msgs:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message streamed")
+		return nil
+	}
+}
+
 // --- Tests ---
 
 // TestE2E_AllOK verifies the happy path: forwarder sends a task, the node processes it,
@@ -211,6 +305,281 @@ func TestE2E_AllOK(t *testing.T) {
 	<-srvErrCh
 }
 
+// TestE2E_DegradedStore verifies that the tasks are still dispatched and answered when the results cannot be
+// stored.
+// TestE2E_NodeNotAccepted verifies that the task stream of a node not accepted is closed after the acceptance wait,
+// without registering the node in the dispatcher.
+func TestE2E_NodeNotAccepted(t *testing.T) {
+	h := newHarnessWithConfig(t, server.ServerConfig{AcceptanceWait: 200 * time.Millisecond})
+	nd := inventory.NodeIdentity{ID: "pending", Address: "127.0.0.1"}
+	require.NoError(t, h.inv.AddCandidate(nd))
+
+	stream := newExecStream(context.Background(), "pending")
+	defer stream.cancel()
+	errCh := make(chan error, 1)
+	go func() { errCh <- h.srv.ExecTask(stream) }()
+
+	select {
+	case err := <-errCh:
+		require.Error(t, err)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Contains(t, status.Convert(err).Message(), "not accepted within 200ms")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the task stream of the node not accepted was not closed")
+	}
+
+	nodes, err := h.dispatcher.TargetedNodes("pending", proto.TargetMode_EXACT)
+	require.NoError(t, err)
+	assert.False(t, nodes["pending"], "the node not accepted must not be registered in the dispatcher")
+}
+
+func TestE2E_DegradedStore(t *testing.T) {
+	h := newHarnessWithStore(t, server.ServerConfig{}, database.NewDegradedStore(errors.New("no space left on device")))
+	stream, srvErrCh := h.connectNode(t, "node1")
+
+	go func() {
+		req, err := stream.nodeRecv(2 * time.Second)
+		if err != nil {
+			return
+		}
+		stream.nodeReply(req, []byte(`"hello"`))
+	}()
+
+	resp, err := h.execTask(context.Background(), "node1", "cmd.run", 5)
+	require.NoError(t, err)
+
+	nodeResp := resp.GetResponses()["node1"]
+	require.NotNil(t, nodeResp)
+	assert.Equal(t, proto.InternalError_OK, nodeResp.GetInternalError())
+	assert.Equal(t, []byte(`"hello"`), nodeResp.GetOutput())
+
+	stream.cancel()
+	<-srvErrCh
+}
+
+// TestE2E_StructuredTask verifies that the plugin, the task, the lock mode and the priority are forwarded to
+// the node as they are requested, a task name containing the separator included.
+func TestE2E_StructuredTask(t *testing.T) {
+	h := newHarness(t)
+	stream, srvErrCh := h.connectNode(t, "node1")
+
+	received := make(chan *proto.TaskRequest, 1)
+	go func() {
+		req, err := stream.nodeRecv(2 * time.Second)
+		if err != nil {
+			return
+		}
+		received <- req
+		stream.nodeReply(req, []byte(`"hello"`))
+	}()
+
+	resp, err := h.fwd.ExecTask(context.Background(), &proto.TaskRequest{
+		Target:     "node1",
+		TargetMode: proto.TargetMode_EXACT,
+		Plugin:     "cmd",
+		Task:       "v2.run",
+		Timeout:    5,
+		LockMode:   proto.LockMode_EXCLUSIVE,
+		Priority:   proto.Priority_PRIORITY_HIGH,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.GetResponses()["node1"])
+
+	req := <-received
+	assert.Equal(t, "cmd", req.GetPlugin())
+	assert.Equal(t, "v2.run", req.GetTask())
+	assert.Equal(t, proto.LockMode_EXCLUSIVE, req.GetLockMode())
+	assert.Equal(t, proto.Priority_PRIORITY_HIGH, req.GetPriority())
+
+	stream.cancel()
+	<-srvErrCh
+}
+
+// TestE2E_LegacyNodeTask verifies that the nodes older than the version check receive the plugin.task form of
+// the task, the only one they know.
+func TestE2E_LegacyNodeTask(t *testing.T) {
+	h := newHarness(t)
+	stream, srvErrCh := h.connectNodeProtocol(t, "node1", 0)
+
+	received := make(chan *proto.TaskRequest, 1)
+	go func() {
+		req, err := stream.nodeRecv(2 * time.Second)
+		if err != nil {
+			return
+		}
+		received <- req
+		stream.nodeReply(req, []byte(`"hello"`))
+	}()
+
+	resp, err := h.fwd.ExecTask(context.Background(), &proto.TaskRequest{
+		Target:     "node1",
+		TargetMode: proto.TargetMode_EXACT,
+		Plugin:     "cmd",
+		Task:       "run",
+		Timeout:    5,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.GetResponses()["node1"])
+
+	req := <-received
+	assert.Empty(t, req.GetPlugin())
+	assert.Equal(t, "cmd.run", req.GetTask())
+
+	stream.cancel()
+	<-srvErrCh
+}
+
+// TestE2E_RequestMetadata verifies that the metadata of a request are stored with it.
+func TestE2E_RequestMetadata(t *testing.T) {
+	h := newHarness(t)
+	stream, srvErrCh := h.connectNode(t, "node1")
+
+	go func() {
+		req, err := stream.nodeRecv(2 * time.Second)
+		if err != nil {
+			return
+		}
+		stream.nodeReply(req, []byte(`"hello"`))
+	}()
+
+	resp, err := h.fwd.ExecTask(context.Background(), &proto.TaskRequest{
+		Target:     "node1",
+		TargetMode: proto.TargetMode_EXACT,
+		Plugin:     "cmd",
+		Task:       "run",
+		Timeout:    5,
+		Metadata:   map[string]string{"build": "1234"},
+	})
+	require.NoError(t, err)
+	nodeResp := resp.GetResponses()["node1"]
+	require.NotNil(t, nodeResp)
+
+	var request *database.Request
+	require.NoError(t, h.db.View(func(txn *badger.Txn) error {
+		request, err = database.GetRequest(txn, nodeResp.GetGroupID())
+		return err
+	}))
+	assert.Equal(t, "cmd.run", request.Task)
+	assert.Equal(t, map[string]string{"build": "1234"}, request.Metadata)
+
+	stream.cancel()
+	<-srvErrCh
+}
+
+// TestE2E_TransientRequest verifies that a transient request and its result are not stored.
+func TestE2E_TransientRequest(t *testing.T) {
+	h := newHarness(t)
+	stream, srvErrCh := h.connectNode(t, "node1")
+
+	go func() {
+		req, err := stream.nodeRecv(2 * time.Second)
+		if err != nil {
+			return
+		}
+		stream.nodeReply(req, []byte(`[]`))
+	}()
+
+	resp, err := h.fwd.ExecTask(context.Background(), &proto.TaskRequest{
+		Target:     "node1",
+		TargetMode: proto.TargetMode_EXACT,
+		Plugin:     "logs",
+		Task:       "tail",
+		Timeout:    5,
+		Transient:  true,
+	})
+	require.NoError(t, err)
+	nodeResp := resp.GetResponses()["node1"]
+	require.NotNil(t, nodeResp)
+	assert.Equal(t, []byte(`[]`), nodeResp.GetOutput(), "the response must still reach the requester")
+
+	err = h.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(database.GenerateResultKey(strconv.FormatInt(nodeResp.GetId(), 10)))
+		return err
+	})
+	assert.ErrorIs(t, err, badger.ErrKeyNotFound, "the result must not be stored")
+	err = h.db.View(func(txn *badger.Txn) error {
+		_, err := database.GetRequest(txn, nodeResp.GetGroupID())
+		return err
+	})
+	assert.Error(t, err, "the request must not be stored")
+
+	stream.cancel()
+	<-srvErrCh
+}
+
+// logBuffer captures the JSON logs of the default logger, it is safe for concurrent use.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// lines returns the captured lines of the message.
+func (b *logBuffer) lines(msg string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var found []string
+	for line := range strings.Lines(b.buf.String()) {
+		if strings.Contains(line, fmt.Sprintf(`"msg":%q`, msg)) {
+			found = append(found, line)
+		}
+	}
+	return found
+}
+
+func captureLogs(t *testing.T) *logBuffer {
+	t.Helper()
+	prev := slog.Default()
+	b := &logBuffer{}
+	slog.SetDefault(slog.New(slog.NewJSONHandler(b, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return b
+}
+
+// TestE2E_LogsCorrelation verifies that the request and group IDs are logged all along the dispatch
+// of a task, so that its whole lifecycle can be found with a single grep.
+func TestE2E_LogsCorrelation(t *testing.T) {
+	captured := captureLogs(t)
+	h := newHarness(t)
+	stream, srvErrCh := h.connectNode(t, "node1")
+
+	go func() {
+		req, err := stream.nodeRecv(2 * time.Second)
+		if err != nil {
+			return
+		}
+		stream.nodeReply(req, []byte(`"hello"`))
+	}()
+
+	resp, err := h.execTask(context.Background(), "node1", "cmd.run", 5)
+	require.NoError(t, err)
+	nodeResp := resp.GetResponses()["node1"]
+	require.NotNil(t, nodeResp)
+
+	stream.cancel()
+	<-srvErrCh
+
+	groupID := fmt.Sprintf(`"group_id":%d`, nodeResp.GetGroupID())
+	requestID := fmt.Sprintf(`"request_id":%d`, nodeResp.GetId())
+
+	lines := captured.lines("dispatching request")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], groupID)
+
+	for _, msg := range []string{"task sent", "received task response"} {
+		lines := captured.lines(msg)
+		require.Len(t, lines, 1, msg)
+		assert.Contains(t, lines[0], groupID, msg)
+		assert.Contains(t, lines[0], requestID, msg)
+		assert.Contains(t, lines[0], `"node":"node1"`, msg)
+	}
+}
+
 // TestE2E_NodeDisconnected verifies that when the target node is not connected,
 // the forwarder immediately returns DISCONNECTED without blocking.
 func TestE2E_NodeDisconnected(t *testing.T) {
@@ -244,14 +613,14 @@ func TestE2E_NodeDisconnectsDuringExec(t *testing.T) {
 
 	stream.nodeDisconnect()
 
-	// The forwarder has no response channel to read from — it must wait for the task timeout.
+	// The forwarder has no response channel to read from — it must wait for the task timeout and the grace.
 	select {
 	case resp := <-resultCh:
 		nodeResp := resp.GetResponses()["node1"]
 		require.NotNil(t, nodeResp)
-		assert.Equal(t, proto.InternalError_TIMEOUT, nodeResp.GetInternalError())
+		assert.Equal(t, proto.InternalError_NODE_UNRESPONSIVE, nodeResp.GetInternalError())
 	case <-time.After(5 * time.Second):
-		t.Fatal("forwarder did not return after node disconnect + task timeout")
+		t.Fatal("forwarder did not return after node disconnect + task timeout + grace")
 	}
 
 	<-srvErrCh
@@ -307,6 +676,138 @@ func TestE2E_MultinodeAllOK(t *testing.T) {
 	<-srvErrCh2
 }
 
+// TestE2E_DuplicateResultID verifies that a node answering with the ID of the request of another node does not
+// overwrite the result of the other node: both results are stored in the group of the request.
+func TestE2E_DuplicateResultID(t *testing.T) {
+	h := newHarness(t)
+	stream1, srvErrCh1 := h.connectNode(t, "node1")
+	stream2, srvErrCh2 := h.connectNode(t, "node2")
+
+	resultCh := make(chan *proto.FwdResponse, 1)
+	go func() {
+		resp, _ := h.fwd.ExecTask(context.Background(), &proto.TaskRequest{
+			Target:     "node1,node2",
+			TargetMode: proto.TargetMode_LIST,
+			Task:       "cmd.run",
+			Timeout:    1,
+		})
+		resultCh <- resp
+	}()
+
+	req1, err := stream1.nodeRecv(2 * time.Second)
+	require.NoError(t, err)
+	_, err = stream2.nodeRecv(2 * time.Second)
+	require.NoError(t, err)
+
+	stream1.nodeReply(req1, []byte(`"from node1"`))
+	key1 := database.GenerateResultKey(strconv.FormatInt(req1.GetId(), 10))
+	require.Eventually(t, func() bool {
+		return h.db.View(func(txn *badger.Txn) error {
+			_, err := txn.Get(key1)
+			return err
+		}) == nil
+	}, 2*time.Second, 10*time.Millisecond, "result of node1 not stored")
+
+	// node2 echoes the request ID of node1
+	stream2.nodeReply(req1, []byte(`"from node2"`))
+
+	select {
+	case <-resultCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("forwarder did not return")
+	}
+
+	outputs := map[node.ID]string{}
+	require.NoError(t, h.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(database.GenerateResultKey(strconv.FormatInt(req1.GetGroupID(), 10)))
+		if err != nil {
+			return err
+		}
+		group, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		ids, _ := database.CutGroupPrefix(string(group))
+		for id := range strings.SplitSeq(ids, ",") {
+			item, err := txn.Get(database.GenerateResultKey(id))
+			if err != nil {
+				return err
+			}
+			val, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			task, err := database.UnmarshalTask(val)
+			if err != nil {
+				return err
+			}
+			outputs[task.Node] = string(task.Result.GetOutput())
+		}
+		return nil
+	}))
+	assert.Equal(t, map[node.ID]string{"node1": `"from node1"`, "node2": `"from node2"`}, outputs)
+
+	stream1.cancel()
+	stream2.cancel()
+	<-srvErrCh1
+	<-srvErrCh2
+}
+
+// TestE2E_DuplicateResultIDRequester verifies that the requester gets the new ID of a result stored with a new ID,
+// which resolves to the result of its node.
+func TestE2E_DuplicateResultIDRequester(t *testing.T) {
+	h := newHarness(t)
+	stream, srvErrCh := h.connectNode(t, "node2")
+
+	resultCh := make(chan *proto.FwdResponse, 1)
+	go func() {
+		resp, _ := h.execTask(context.Background(), "node2", "cmd.run", 2)
+		resultCh <- resp
+	}()
+
+	req, err := stream.nodeRecv(2 * time.Second)
+	require.NoError(t, err)
+
+	// a result of node1 is already stored with the ID of the request
+	key := database.GenerateResultKey(strconv.FormatInt(req.GetId(), 10))
+	data, err := database.MarshalTask("node1", &proto.TaskResponse{Id: req.GetId(), Output: []byte(`"from node1"`)})
+	require.NoError(t, err)
+	require.NoError(t, h.db.Update(func(txn *badger.Txn) error { return txn.Set(key, data) }))
+
+	stream.nodeReply(req, []byte(`"from node2"`))
+
+	var resp *proto.FwdResponse
+	select {
+	case resp = <-resultCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("forwarder did not return")
+	}
+	nodeResp := resp.GetResponses()["node2"]
+	require.NotNil(t, nodeResp)
+	require.NotEqual(t, req.GetId(), nodeResp.GetId(), "expected the new ID of the result")
+
+	require.NoError(t, h.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(database.GenerateResultKey(strconv.FormatInt(nodeResp.GetId(), 10)))
+		if err != nil {
+			return err
+		}
+		val, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		task, err := database.UnmarshalTask(val)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, node.ID("node2"), task.Node)
+		assert.Equal(t, `"from node2"`, string(task.Result.GetOutput()))
+		return nil
+	}))
+
+	stream.cancel()
+	<-srvErrCh
+}
+
 // TestE2E_MultinodeMixedResults verifies that when targeting multiple nodes, each node's
 // result is independent: one can succeed while another is disconnected.
 func TestE2E_MultinodeMixedResults(t *testing.T) {
@@ -332,11 +833,192 @@ func TestE2E_MultinodeMixedResults(t *testing.T) {
 
 	assert.Equal(t, proto.InternalError_OK, resp.GetResponses()["node1"].GetInternalError())
 	assert.Equal(t, proto.InternalError_DISCONNECTED, resp.GetResponses()["node2"].GetInternalError())
+	assert.Equal(t, map[string]proto.DispatchStatus{
+		"node1": proto.DispatchStatus_DISPATCH_SENT,
+		"node2": proto.DispatchStatus_DISPATCH_DISCONNECTED,
+	}, resp.GetTargets())
 
 	stream1.cancel()
 	<-srvErrCh1
 }
 
+// connectFailingNodes connects the nodes, each of them answering the first task with the error of the map
+// (no error if empty), and returns the channel receiving the IDs of the nodes as they get the task.
+func (h *harness) connectFailingNodes(t *testing.T, outcomes map[string]string) <-chan string {
+	t.Helper()
+	received := make(chan string, len(outcomes))
+	for _, nd := range slices.Sorted(maps.Keys(outcomes)) {
+		stream, srvErrCh := h.connectNode(t, nd)
+		go func() {
+			req, err := stream.nodeRecv(5 * time.Second)
+			if err != nil {
+				return
+			}
+			received <- nd
+			stream.fromNode <- &proto.TaskResponse{Id: req.GetId(), GroupID: req.GroupID, Error: outcomes[nd]}
+		}()
+		t.Cleanup(func() {
+			stream.cancel()
+			<-srvErrCh
+		})
+	}
+	return received
+}
+
+// TestE2E_FailFast verifies that the nodes of a fail-fast run get the task concurrently, and that the first
+// failure stops the run: the node still running is cancelled, and the node not dispatched yet is skipped.
+func TestE2E_FailFast(t *testing.T) {
+	h := newHarness(t)
+
+	failing, errCh1 := h.connectNode(t, "node1")
+	running, errCh2 := h.connectNode(t, "node2")
+	busy, errCh3 := h.connectNode(t, "node3")
+	t.Cleanup(func() {
+		for _, s := range []*execStream{failing, running, busy} {
+			s.cancel()
+		}
+		<-errCh1
+		<-errCh2
+		<-errCh3
+	})
+
+	// node3 is busy: its stream is full and the server is blocked sending it a task, the task of the run cannot
+	// be dispatched to it.
+	for range cap(busy.toNode) {
+		busy.toNode <- &proto.TaskRequest{}
+	}
+	blocking := forwarder.Task[*proto.TaskRequest, *proto.TaskResponse]{
+		Request:    &proto.TaskRequest{Task: "cmd.run"},
+		ResponseCh: make(chan *proto.TaskResponse, 1),
+	}
+	require.NoError(t, h.dispatcher.Send("node3", blocking, time.Second))
+
+	// node1 fails once node2 got the task, node2 never answers.
+	go func() {
+		if _, err := running.nodeRecv(5 * time.Second); err != nil {
+			return
+		}
+		req, err := failing.nodeRecv(5 * time.Second)
+		if err != nil {
+			return
+		}
+		failing.fromNode <- &proto.TaskResponse{Id: req.GetId(), GroupID: req.GroupID, Error: "deploy failed"}
+	}()
+
+	start := time.Now()
+	resp, err := h.fwd.ExecTask(context.Background(), &proto.TaskRequest{
+		Target:     "node*",
+		TargetMode: proto.TargetMode_GLOB,
+		Task:       "cmd.run",
+		Timeout:    5,
+		FailFast:   true,
+	})
+	require.NoError(t, err)
+
+	assert.Less(t, time.Since(start), 5*time.Second, "the run must not wait for the cancelled nodes")
+	assert.Equal(t, "deploy failed", resp.GetResponses()["node1"].GetError())
+	assert.Equal(t, proto.InternalError_CANCELLED, resp.GetResponses()["node2"].GetInternalError())
+	assert.Equal(t, proto.InternalError_SKIPPED, resp.GetResponses()["node3"].GetInternalError())
+	assert.Equal(t, map[string]proto.DispatchStatus{
+		"node1": proto.DispatchStatus_DISPATCH_SENT,
+		"node2": proto.DispatchStatus_DISPATCH_SENT,
+		"node3": proto.DispatchStatus_DISPATCH_SKIPPED,
+	}, resp.GetTargets())
+	assert.Equal(t, "node1", resp.GetStoppedAt())
+}
+
+// TestE2E_FailFastSuccess verifies that a fail-fast run without failure runs the task on all the nodes.
+func TestE2E_FailFastSuccess(t *testing.T) {
+	h := newHarness(t)
+	received := h.connectFailingNodes(t, map[string]string{"node1": "", "node2": "", "node3": ""})
+
+	resp, err := h.fwd.ExecTask(context.Background(), &proto.TaskRequest{
+		Target:     "node*",
+		TargetMode: proto.TargetMode_GLOB,
+		Task:       "cmd.run",
+		Timeout:    5,
+		FailFast:   true,
+	})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"node1", "node2", "node3"}, drain(received))
+	for _, nd := range []string{"node1", "node2", "node3"} {
+		assert.Equal(t, proto.InternalError_OK, resp.GetResponses()[nd].GetInternalError(), nd)
+		assert.Equal(t, proto.DispatchStatus_DISPATCH_SENT, resp.GetTargets()[nd], nd)
+	}
+	assert.Empty(t, resp.GetStoppedAt())
+}
+
+// TestE2E_Rolling verifies that a rolling run tolerates max_failures failures, and stops at the next one.
+func TestE2E_Rolling(t *testing.T) {
+	tests := []struct {
+		name        string
+		outcomes    map[string]string // Error of the task by node.
+		maxFailures uint32
+		received    []string
+		stoppedAt   string
+	}{
+		{
+			name:     "clean roll",
+			outcomes: map[string]string{"node1": "", "node2": "", "node3": "", "node4": ""},
+			received: []string{"node1", "node2", "node3", "node4"},
+		},
+		{
+			name:        "failures tolerated",
+			outcomes:    map[string]string{"node1": "", "node2": "restart failed", "node3": "", "node4": ""},
+			maxFailures: 1,
+			received:    []string{"node1", "node2", "node3", "node4"},
+		},
+		{
+			name:        "aborted",
+			outcomes:    map[string]string{"node1": "restart failed", "node2": "", "node3": "restart failed", "node4": ""},
+			maxFailures: 1,
+			received:    []string{"node1", "node2", "node3"},
+			stoppedAt:   "node3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			received := h.connectFailingNodes(t, tt.outcomes)
+
+			resp, err := h.fwd.ExecTask(context.Background(), &proto.TaskRequest{
+				Target:      "node*",
+				TargetMode:  proto.TargetMode_GLOB,
+				Task:        "cmd.run",
+				Timeout:     5,
+				Rolling:     true,
+				MaxFailures: tt.maxFailures,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.received, drain(received))
+			assert.Equal(t, tt.stoppedAt, resp.GetStoppedAt())
+			for nd := range tt.outcomes {
+				want := proto.DispatchStatus_DISPATCH_SENT
+				if !slices.Contains(tt.received, nd) {
+					want = proto.DispatchStatus_DISPATCH_SKIPPED
+				}
+				assert.Equal(t, want, resp.GetTargets()[nd], nd)
+			}
+		})
+	}
+}
+
+// drain returns the values already sent to the channel.
+func drain(ch <-chan string) []string {
+	var values []string
+	for {
+		select {
+		case v := <-ch:
+			values = append(values, v)
+		default:
+			return values
+		}
+	}
+}
+
 // TestE2E_ConcurrentRequests verifies that two simultaneous callers targeting the same node
 // each receive their own response, with no cross-contamination between in-flight tasks.
 func TestE2E_ConcurrentRequests(t *testing.T) {
@@ -422,6 +1104,99 @@ func TestE2E_NodeReconnects(t *testing.T) {
 	<-srvErrCh2
 }
 
+// TestE2E_HalfOpenStream verifies that the task stream of a node not answering the heartbeats anymore is
+// closed for the node to re-establish it, and that the answered heartbeats are not recorded as results.
+func TestE2E_HalfOpenStream(t *testing.T) {
+	h := newHarnessWithConfig(t, server.ServerConfig{HeartbeatInterval: 50 * time.Millisecond, HeartbeatTimeout: 100 * time.Millisecond})
+	stream, srvErrCh := h.connectNode(t, "node1")
+
+	ping, err := stream.nodeRecv(time.Second)
+	require.NoError(t, err, "heartbeat never reached the node")
+	assert.Equal(t, "health", ping.GetPlugin())
+	assert.Equal(t, config.InstantPingName, ping.GetTask())
+	assert.Nil(t, ping.GroupID)
+	stream.nodeReply(ping, []byte("true"))
+
+	// the stream is kept while the node answers, then the node goes silent
+	_, err = stream.nodeRecv(time.Second)
+	require.NoError(t, err, "no heartbeat after an answered one")
+	select {
+	case err := <-srvErrCh:
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	case <-time.After(2 * time.Second):
+		t.Fatal("the half-open stream was not closed")
+	}
+
+	err = h.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(database.GenerateResultKey(strconv.FormatInt(ping.GetId(), 10)))
+		return err
+	})
+	assert.ErrorIs(t, err, badger.ErrKeyNotFound, "heartbeats must not be recorded as results")
+
+	require.Eventually(t, func() bool {
+		nodes, _ := h.dispatcher.TargetedNodes("node1", proto.TargetMode_EXACT)
+		return !nodes["node1"]
+	}, 2*time.Second, 10*time.Millisecond, "node1 never unregistered after the missed heartbeat")
+	stream.cancel()
+}
+
+// TestE2E_StreamTaskProgress verifies that the progress updates of a task are streamed to the caller
+// before its final response, and that they are not recorded as results.
+func TestE2E_StreamTaskProgress(t *testing.T) {
+	h := newHarness(t)
+	stream, srvErrCh := h.connectNode(t, "node1")
+
+	fwd := newFwdStream()
+	done := make(chan error, 1)
+	go func() {
+		done <- h.fwd.StreamTask(&proto.TaskRequest{
+			Target:     "node1",
+			TargetMode: proto.TargetMode_EXACT,
+			Task:       "pkg.upgrade",
+			Timeout:    5,
+		}, fwd)
+	}()
+
+	req, err := stream.nodeRecv(2 * time.Second)
+	require.NoError(t, err, "task never reached the node")
+
+	resultExists := func() bool {
+		err := h.db.View(func(txn *badger.Txn) error {
+			_, err := txn.Get(database.GenerateResultKey(strconv.FormatInt(req.GetId(), 10)))
+			return err
+		})
+		return err == nil
+	}
+
+	for _, percent := range []int32{30, 70} {
+		stream.fromNode <- &proto.TaskResponse{Id: req.GetId(), GroupID: req.GroupID, Progress: &percent}
+		msg := fwd.next(t)
+		assert.Equal(t, "node1", msg.GetNode())
+		require.NotNil(t, msg.GetResponse().Progress)
+		assert.Equal(t, percent, msg.GetResponse().GetProgress())
+	}
+	assert.False(t, resultExists(), "progress updates must not be recorded as results")
+
+	stream.nodeReply(req, []byte(`"upgraded"`))
+	msg := fwd.next(t)
+	assert.Equal(t, "node1", msg.GetNode())
+	assert.Nil(t, msg.GetResponse().Progress)
+	assert.Equal(t, []byte(`"upgraded"`), msg.GetResponse().GetOutput())
+
+	require.NoError(t, <-done)
+	assert.True(t, resultExists(), "final response must be recorded")
+
+	trailer := fwd.trailer.Get(config.TargetsTrailerKey)
+	require.Len(t, trailer, 1, "the dispatch statuses must be sent in the trailer")
+	var targets proto.FwdResponse
+	require.NoError(t, protobuf.Unmarshal([]byte(trailer[0]), &targets))
+	assert.Equal(t, map[string]proto.DispatchStatus{"node1": proto.DispatchStatus_DISPATCH_SENT}, targets.GetTargets())
+	assert.Equal(t, []string{"500ms"}, fwd.header.Get(config.GraceHeaderKey), "the configured response grace must be sent in the header")
+
+	stream.cancel()
+	<-srvErrCh
+}
+
 // TestE2E_TaskErrorResponse verifies that a module-level error returned by the node
 // (non-zero retcode, error message) is faithfully propagated to the caller.
 func TestE2E_TaskErrorResponse(t *testing.T) {
@@ -493,3 +1268,377 @@ func TestE2E_CallerCancelledAfterSend(t *testing.T) {
 	stream.cancel()
 	<-srvErrCh
 }
+
+// TestE2E_WebhookNotification verifies that a completed task is notified to the configured webhook
+// with the node, the requested task and the status.
+func TestE2E_WebhookNotification(t *testing.T) {
+	events := make(chan notification.Event, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e notification.Event
+		if err := json.NewDecoder(r.Body).Decode(&e); err == nil {
+			events <- e
+		}
+	}))
+	defer webhook.Close()
+
+	h := newHarness(t)
+	notifier := notification.NewDispatcher([]config.WebhookConfig{{URL: webhook.URL}})
+	h.srv.SetNotifier(notifier)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go notifier.Run(ctx)
+
+	stream, srvErrCh := h.connectNode(t, "node1")
+	go func() {
+		req, err := stream.nodeRecv(2 * time.Second)
+		if err != nil {
+			return
+		}
+		stream.nodeReply(req, []byte(`"hello"`))
+	}()
+
+	resp, err := h.execTask(context.Background(), "node1", "cmd.run", 5)
+	require.NoError(t, err)
+	nodeResp := resp.GetResponses()["node1"]
+	require.NotNil(t, nodeResp)
+
+	select {
+	case e := <-events:
+		assert.Equal(t, nodeResp.GetId(), e.ID)
+		assert.Equal(t, nodeResp.GetGroupID(), e.GroupID)
+		assert.Equal(t, "node1", e.Node)
+		assert.Equal(t, "cmd.run", e.Task)
+		assert.Equal(t, "success", e.Status)
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not notified")
+	}
+
+	stream.cancel()
+	<-srvErrCh
+}
+
+// TestE2E_ConcurrentShutdownRequests verifies that nodes can connect and be shut down in parallel,
+// and that concurrent shutdown requests for the same node are only honoured once.
+// Run with -race to detect unsynchronized accesses.
+func TestE2E_ConcurrentShutdownRequests(t *testing.T) {
+	h := newHarness(t)
+	nodes := []string{"node1", "node2", "node3", "node4", "node5", "node6", "node7", "node8"}
+
+	type conn struct {
+		stream *execStream
+		errCh  chan error
+	}
+	conns := make([]conn, len(nodes))
+	var wg sync.WaitGroup
+	for i, nodeID := range nodes {
+		wg.Go(func() {
+			stream, errCh := h.connectNode(t, nodeID)
+			conns[i] = conn{stream, errCh}
+		})
+	}
+	wg.Wait()
+
+	var succeeded sync.Map
+	for _, nodeID := range nodes {
+		for range 3 {
+			wg.Go(func() {
+				if err := h.srv.RequestShutdown(node.ID(nodeID)); err == nil {
+					_, loaded := succeeded.LoadOrStore(nodeID, true)
+					assert.False(t, loaded, "shutdown of %q honoured twice", nodeID)
+				}
+			})
+		}
+	}
+	wg.Wait()
+
+	for i, nodeID := range nodes {
+		_, ok := succeeded.Load(nodeID)
+		assert.True(t, ok, "shutdown of %q never honoured", nodeID)
+
+		conns[i].stream.cancel()
+		select {
+		case <-conns[i].errCh:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("stream of %q did not stop", nodeID)
+		}
+	}
+
+	// streams are gone: a new shutdown request has nothing to close
+	for _, nodeID := range nodes {
+		assert.Error(t, h.srv.RequestShutdown(node.ID(nodeID)))
+	}
+}
+
+// TestE2E_TooManyInflight verifies that once a node has reached the maximum number of requests awaiting
+// a response, new requests are rejected instead of being sent to the node.
+func TestE2E_TooManyInflight(t *testing.T) {
+	h := newHarnessWithConfig(t, server.ServerConfig{MaxInflight: 1})
+	stream, srvErrCh := h.connectNode(t, "node1")
+
+	// the first task is never answered and fills the in-flight slots
+	firstCh := make(chan *proto.FwdResponse, 1)
+	go func() {
+		resp, _ := h.execTask(context.Background(), "node1", "cmd.run", 2)
+		firstCh <- resp
+	}()
+	_, err := stream.nodeRecv(2 * time.Second)
+	require.NoError(t, err)
+
+	resp, err := h.execTask(context.Background(), "node1", "cmd.run", 2)
+	require.NoError(t, err)
+
+	nodeResp := resp.GetResponses()["node1"]
+	require.NotNil(t, nodeResp)
+	assert.Equal(t, proto.InternalError_FULL_QUEUE, nodeResp.GetInternalError())
+	assert.Equal(t, server.ErrTooManyInflight.Error(), nodeResp.GetModuleError())
+
+	select {
+	case req := <-stream.toNode:
+		t.Fatalf("rejected request %d was sent to the node", req.GetId())
+	default:
+	}
+
+	assert.Equal(t, proto.InternalError_NODE_UNRESPONSIVE, (<-firstCh).GetResponses()["node1"].GetInternalError())
+
+	stream.cancel()
+	<-srvErrCh
+}
+
+// newMTLSExecStream creates a stream authenticated with a freshly generated certificate.
+// It returns the stream and the certificate as recorded in the inventory.
+func newMTLSExecStream(t *testing.T, nodeID, ip string) (*execStream, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("node_id", nodeID))
+	ctx = peer.NewContext(ctx, &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 9999},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{{PublicKey: &key.PublicKey}},
+		}},
+	})
+	ctx, cancel := context.WithCancel(ctx)
+	return &execStream{
+		ctx:      ctx,
+		cancel:   cancel,
+		toNode:   make(chan *proto.TaskRequest, 10),
+		fromNode: make(chan *proto.TaskResponse, 10),
+	}, base64.StdEncoding.EncodeToString(der)
+}
+
+// TestE2E_DuplicateNodeID verifies that a second stream claiming the ID of a connected node with a
+// different certificate is rejected, and does not disturb the legitimate stream.
+func TestE2E_DuplicateNodeID(t *testing.T) {
+	h := newHarnessWithConfig(t, server.ServerConfig{MTLSEnabled: true})
+
+	legit, cert := newMTLSExecStream(t, "node1", "10.0.0.1")
+	nd := inventory.NodeIdentity{ID: "node1", Address: "10.0.0.1", Certificate: cert}
+	require.NoError(t, h.inv.AddCandidate(nd))
+	require.NoError(t, h.inv.Register(nd, false))
+
+	legitErrCh := make(chan error, 1)
+	go func() { legitErrCh <- h.srv.ExecTask(legit) }()
+	require.Eventually(t, func() bool {
+		nodes, err := h.dispatcher.TargetedNodes("node1", proto.TargetMode_EXACT)
+		return err == nil && nodes["node1"]
+	}, 2*time.Second, 10*time.Millisecond)
+
+	rogue, _ := newMTLSExecStream(t, "node1", "10.0.0.2")
+	defer rogue.cancel()
+	rogueErrCh := make(chan error, 1)
+	go func() { rogueErrCh <- h.srv.ExecTask(rogue) }()
+
+	select {
+	case err := <-rogueErrCh:
+		require.Error(t, err)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Contains(t, err.Error(), "rogue")
+	case <-time.After(2 * time.Second):
+		t.Fatal("rogue stream was not rejected")
+	}
+
+	// the legitimate node still receives the tasks
+	go func() {
+		req, err := legit.nodeRecv(2 * time.Second)
+		if err != nil {
+			return
+		}
+		legit.nodeReply(req, []byte(`"legit"`))
+	}()
+	resp, err := h.execTask(context.Background(), "node1", "cmd.run", 5)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`"legit"`), resp.GetResponses()["node1"].GetOutput())
+
+	legit.cancel()
+	<-legitErrCh
+}
+
+// TestE2E_MTLSRequired verifies that the task stream of a node without certificate is refused when
+// mTLS is required, while a node presenting a certificate is accepted.
+func TestE2E_MTLSRequired(t *testing.T) {
+	h := newHarnessWithConfig(t, server.ServerConfig{MTLSRequired: true})
+
+	insecureStream := newExecStream(context.Background(), "node1")
+	defer insecureStream.cancel()
+	err := h.srv.ExecTask(insecureStream)
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	stream, cert := newMTLSExecStream(t, "node2", "10.0.0.2")
+	nd := inventory.NodeIdentity{ID: "node2", Address: "10.0.0.2", Certificate: cert}
+	require.NoError(t, h.inv.AddCandidate(nd))
+	require.NoError(t, h.inv.Register(nd, false))
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- h.srv.ExecTask(stream) }()
+	require.Eventually(t, func() bool {
+		nodes, err := h.dispatcher.TargetedNodes("node2", proto.TargetMode_EXACT)
+		return err == nil && nodes["node2"]
+	}, 2*time.Second, 10*time.Millisecond)
+
+	stream.cancel()
+	<-errCh
+}
+
+// TestE2E_ClockStepBackward verifies that the IDs assigned to the tasks keep increasing when the
+// manager's clock steps backward, so that the results stay ordered in the database.
+func TestE2E_ClockStepBackward(t *testing.T) {
+	h := newHarness(t)
+	fake := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	h.srv.SetClock(fake)
+	stream, srvErrCh := h.connectNode(t, "node1")
+
+	go func() {
+		for {
+			req, err := stream.nodeRecv(5 * time.Second)
+			if err != nil {
+				return
+			}
+			stream.nodeReply(req, []byte(`"ok"`))
+		}
+	}()
+
+	var ids []int64
+	for _, step := range []time.Duration{0, -time.Hour, 0, time.Minute} {
+		fake.Advance(step)
+		resp, err := h.execTask(context.Background(), "node1", "cmd.run", 5)
+		require.NoError(t, err)
+		ids = append(ids, resp.GetResponses()["node1"].GetId())
+	}
+
+	for i := 1; i < len(ids); i++ {
+		assert.Greater(t, ids[i], ids[i-1], "task IDs must be strictly increasing: %v", ids)
+	}
+
+	stream.cancel()
+	<-srvErrCh
+}
+
+// serveCluster serves the cluster service of the harness on a local TCP port, without TLS, and returns the port.
+func (h *harness) serveCluster(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	proto.RegisterClusterServer(grpcServer, h.srv)
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+	return strconv.Itoa(lis.Addr().(*net.TCPAddr).Port)
+}
+
+// TestE2E_Syndic verifies a two-tier setup: a run of the upper manager is dispatched by the syndic, connected to
+// it over gRPC as a node, to the leaf node targeted by the downstream targets, and the responses are grouped by
+// syndic.
+func TestE2E_Syndic(t *testing.T) {
+	upper := newHarnessWithConfig(t, server.ServerConfig{AutoAccept: true})
+	port := upper.serveCluster(t)
+
+	lower := newHarness(t)
+	leaf, leafErrCh := lower.connectNode(t, "leaf1")
+	other, otherErrCh := lower.connectNode(t, "leaf2")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	syndicDone := make(chan error, 1)
+	go func() {
+		cfg := node.Config{ManagerAddress: "127.0.0.1", ManagerPort: port, NodeID: "eu-manager", Version: "dev"}
+		syndicDone <- syndic.New(lower.fwd).Run(ctx, cfg, 100*time.Millisecond)
+	}()
+	require.Eventually(t, func() bool {
+		nodes, err := upper.dispatcher.TargetedNodes("eu-manager", proto.TargetMode_EXACT)
+		return err == nil && nodes["eu-manager"]
+	}, 5*time.Second, 10*time.Millisecond, "the syndic never connected to the upper manager")
+
+	received := make(chan *proto.TaskRequest, 1)
+	go func() {
+		req, err := leaf.nodeRecv(5 * time.Second)
+		if err != nil {
+			return
+		}
+		received <- req
+		leaf.nodeReply(req, []byte(`"hello"`))
+	}()
+
+	resp, err := upper.fwd.ExecTask(context.Background(), &proto.TaskRequest{
+		Targets:            []*proto.Target{{Target: "eu-*", Mode: proto.TargetMode_GLOB}},
+		DownstreamTargets:  []*proto.Target{{Target: "leaf*", Mode: proto.TargetMode_GLOB}},
+		DownstreamExcludes: []*proto.Target{{Target: "leaf2", Mode: proto.TargetMode_EXACT}},
+		Plugin:             "cmd",
+		Task:               "run",
+		Timeout:            10,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, resp.GetResponses(), 1)
+	leafResp := resp.GetResponses()["eu-manager"+config.SyndicSeparator+"leaf1"]
+	require.NotNil(t, leafResp, "responses: %v", resp.GetResponses())
+	assert.Equal(t, proto.InternalError_OK, leafResp.GetInternalError())
+	assert.Equal(t, []byte(`"hello"`), leafResp.GetOutput())
+
+	// the response of the leaf is stored with the syndic response by the upper manager, and by the syndic in a
+	// run labeled with the upper group ID
+	req := <-received
+	assert.Equal(t, "cmd", req.GetPlugin())
+	assert.Equal(t, "run", req.GetTask())
+	var request *database.Request
+	require.NoError(t, lower.db.View(func(txn *badger.Txn) error {
+		request, err = database.GetRequest(txn, req.GetGroupID())
+		return err
+	}))
+	assert.Equal(t, "cmd.run", request.Task)
+	assert.Equal(t, strconv.FormatInt(leafResp.GetGroupID(), 10), request.Metadata[config.UpstreamGroupKey])
+
+	select {
+	case req := <-other.toNode:
+		t.Errorf("the excluded leaf received the task: %v", req)
+	default:
+	}
+
+	// a run matching none of the nodes of the syndic is a syndic error
+	resp, err = upper.fwd.ExecTask(context.Background(), &proto.TaskRequest{
+		Target:            "eu-manager",
+		TargetMode:        proto.TargetMode_EXACT,
+		DownstreamTargets: []*proto.Target{{Target: "db-*", Mode: proto.TargetMode_GLOB}},
+		Plugin:            "cmd",
+		Task:              "run",
+		Timeout:           10,
+	})
+	require.NoError(t, err)
+	syndicResp := resp.GetResponses()["eu-manager"]
+	require.NotNil(t, syndicResp, "responses: %v", resp.GetResponses())
+	assert.Equal(t, proto.InternalError_DOWNSTREAM_ERROR, syndicResp.GetInternalError())
+
+	cancel()
+	select {
+	case err := <-syndicDone:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the syndic did not stop")
+	}
+	leaf.cancel()
+	other.cancel()
+	<-leafErrCh
+	<-otherErrCh
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/jackadi-io/jackadi/internal/manager/forwarder"
+	"github.com/jackadi-io/jackadi/internal/manager/inventory"
+	"github.com/jackadi-io/jackadi/internal/node"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestShutdownChannelOwnership verifies that a closing stream only removes its own shutdown channel, and not the
+// one of a newer stream of the node, even once its shutdown is requested.
+func TestShutdownChannelOwnership(t *testing.T) {
+	inv := inventory.New()
+	inv.DisableRegistryFile()
+	srv := New(ServerConfig{}, &inv, forwarder.NewDispatcher[*proto.TaskRequest, *proto.TaskResponse](&inv), nil)
+
+	old := srv.registerShutdownChannel("node1")
+	current := srv.registerShutdownChannel("node1")
+	require.NoError(t, srv.RequestShutdown("node1"))
+	assert.Error(t, srv.RequestShutdown("node1"), "the shutdown must only be requested once")
+
+	srv.unregisterShutdownChannel("node1", old)
+	assert.Equal(t, current, srv.shutdownRequest["node1"], "the channel of the newer stream must be kept")
+	select {
+	case <-current:
+	default:
+		t.Error("the shutdown of the newer stream was not requested")
+	}
+
+	srv.unregisterShutdownChannel("node1", current)
+	assert.NotContains(t, srv.shutdownRequest, node.ID("node1"))
+}
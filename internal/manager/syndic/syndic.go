@@ -0,0 +1,158 @@
+// Package syndic connects a manager to an upstream manager as a node, to build a hierarchy of managers.
+//
+// The targets of a run of the upstream manager select the syndics, and its downstream targets the nodes of the
+// syndics, all of them if empty. The downstream targets only apply to the tier right below the syndics: a syndic
+// connected to a syndic runs the task on all its nodes.
+//
+// A syndic dispatches a run like the runs of jack, and sends back the responses of its nodes upstream in a
+// single response. The upstream manager stores this response, and returns the responses of the nodes keyed
+// syndic/node. The results of the nodes are also stored by the syndic, in a run labeled with the group ID of
+// the upstream run (see config.UpstreamGroupKey).
+package syndic
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/logs"
+	"github.com/jackadi-io/jackadi/internal/manager/database"
+	"github.com/jackadi-io/jackadi/internal/manager/forwarder"
+	"github.com/jackadi-io/jackadi/internal/node"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+)
+
+// Syndic dispatches the runs of the upstream manager to the nodes of the manager.
+type Syndic struct {
+	fwd *forwarder.GRPCForwarder
+}
+
+func New(fwd *forwarder.GRPCForwarder) *Syndic {
+	return &Syndic{fwd: fwd}
+}
+
+// Run connects to the upstream manager as a node and dispatches its runs until the context is cancelled,
+// reconnecting after the delay when the connection fails.
+func (s *Syndic) Run(ctx context.Context, cfg node.Config, reconnectDelay time.Duration) error {
+	upstream, ctx := node.NewWithExecutor(ctx, cfg, s.Exec)
+	if err := upstream.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect the upstream manager: %w", err)
+	}
+	defer upstream.Close()
+
+	for {
+		err := func() error {
+			ctxHandshake, cancel := context.WithTimeout(ctx, time.Minute)
+			defer cancel()
+			return upstream.Handshake(ctxHandshake)
+		}()
+		if err == nil {
+			slog.Info("connected to the upstream manager", "address", cfg.ManagerAddress, "port", cfg.ManagerPort, "id", cfg.NodeID)
+			err = upstream.ListenTaskRequest(ctx)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			slog.Error("connection to the upstream manager failed", "error", err, "address", cfg.ManagerAddress)
+		}
+
+		select {
+		case <-time.After(reconnectDelay):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Exec dispatches a run of the upstream manager to the targeted nodes, and returns their responses.
+func (s *Syndic) Exec(ctx context.Context, req *proto.TaskRequest) *proto.TaskResponse {
+	logger := logs.FromContext(ctx)
+	resp := &proto.TaskResponse{Id: req.GetId(), GroupID: req.GroupID}
+
+	// the specs collection of the upstream manager is not a run, it is the only request without group ID
+	if req.GroupID == nil && req.GetPlugin() == config.SpecManagerPrefix {
+		resp.Output = s.specs(ctx)
+		return resp
+	}
+
+	out, err := s.fwd.ExecTask(ctx, downstreamRequest(req))
+	if err != nil {
+		logger.Warn("failed to dispatch the upstream run", "task", req.FullTask(), "error", err)
+		resp.InternalError = proto.InternalError_DOWNSTREAM_ERROR
+		resp.ModuleError = err.Error()
+		return resp
+	}
+
+	resp.Downstream = out.GetResponses()
+	failed := 0
+	for _, r := range resp.GetDownstream() {
+		if database.ResultStatus(r) != "success" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		resp.Error = fmt.Sprintf("%d of %d nodes failed", failed, len(resp.GetDownstream()))
+	}
+	logger.Debug("upstream run dispatched", "task", req.FullTask(), "nodes", len(resp.GetDownstream()), "failed", failed)
+	return resp
+}
+
+// specs returns the specs of the syndic: it is a syndic, and the number of its nodes.
+func (s *Syndic) specs(ctx context.Context) []byte {
+	nodes := 0
+	if resolved, err := s.fwd.ResolveTargets(ctx, &proto.TaskRequest{Target: "*", TargetMode: proto.TargetMode_GLOB}); err == nil {
+		nodes = len(resolved.GetNodes())
+	}
+	out, _ := serializer.JSON.Marshal(map[string]any{"syndic": true, "nodes": nodes})
+	return out
+}
+
+// downstreamRequest returns the request dispatched to the nodes for a run of the upstream manager.
+//
+// A rolling run is not rolling downstream: the upstream manager waits for the syndic as for a
+// single node, the nodes of the syndic must run the task concurrently.
+func downstreamRequest(req *proto.TaskRequest) *proto.TaskRequest {
+	targets := req.GetDownstreamTargets()
+	if len(targets) == 0 {
+		targets = []*proto.Target{{Target: "*", Mode: proto.TargetMode_GLOB}}
+	}
+
+	metadata := maps.Clone(req.GetMetadata())
+	if metadata == nil {
+		metadata = make(map[string]string, 1)
+	}
+	metadata[config.UpstreamGroupKey] = strconv.FormatInt(req.GetGroupID(), 10)
+
+	return &proto.TaskRequest{
+		Targets:  targets,
+		Excludes: req.GetDownstreamExcludes(),
+		LockMode: req.GetLockMode(),
+		Priority: req.GetPriority(),
+		Timeout:  downstreamTimeout(req.GetTimeout()),
+		Plugin:   req.GetPlugin(),
+		Task:     req.GetTask(),
+		Input:    req.GetInput(),
+		Metadata: metadata,
+
+		Transient: req.GetTransient(),
+	}
+}
+
+// downstreamTimeout returns the timeout of the downstream run, shorter than the upstream one for the response
+// to reach the upstream manager before it gives up.
+func downstreamTimeout(timeout uint32) uint32 {
+	if timeout == 0 {
+		timeout = uint32(config.TaskTimeout.Seconds())
+	}
+	margin := uint32(config.SyndicTimeoutMargin.Seconds())
+	if timeout > 2*margin {
+		return timeout - margin
+	}
+	return max(timeout/2, 1)
+}
@@ -0,0 +1,65 @@
+package syndic
+
+import (
+	"testing"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+func TestDownstreamRequest(t *testing.T) {
+	groupID := int64(42)
+	req := &proto.TaskRequest{
+		Id:                 7,
+		GroupID:            &groupID,
+		Targets:            []*proto.Target{{Target: "eu-*", Mode: proto.TargetMode_GLOB}},
+		DownstreamTargets:  []*proto.Target{{Target: "web-*", Mode: proto.TargetMode_GLOB}},
+		DownstreamExcludes: []*proto.Target{{Target: "web-canary", Mode: proto.TargetMode_EXACT}},
+		LockMode:           proto.LockMode_WRITE,
+		Priority:           proto.Priority_PRIORITY_HIGH,
+		Timeout:            60,
+		Plugin:             "cmd",
+		Task:               "run",
+		Metadata:           map[string]string{"build": "1234"},
+	}
+
+	down := downstreamRequest(req)
+	if got := down.TargetsString(); got != "glob:web-* - exact:web-canary" {
+		t.Errorf("unexpected targets: %s", got)
+	}
+	if down.GetId() != 0 || down.GroupID != nil {
+		t.Errorf("the IDs of the upstream request must not be reused, got %d/%v", down.GetId(), down.GroupID)
+	}
+	if down.FullTask() != "cmd.run" || down.GetLockMode() != proto.LockMode_WRITE || down.GetPriority() != proto.Priority_PRIORITY_HIGH {
+		t.Errorf("unexpected task: %s with %s and %s", down.FullTask(), down.GetLockMode(), down.GetPriority())
+	}
+	if down.GetTimeout() >= req.GetTimeout() {
+		t.Errorf("the downstream timeout must be shorter than the upstream one, got %d", down.GetTimeout())
+	}
+	if down.GetMetadata()["build"] != "1234" || down.GetMetadata()[config.UpstreamGroupKey] != "42" {
+		t.Errorf("unexpected metadata: %v", down.GetMetadata())
+	}
+	if _, ok := req.GetMetadata()[config.UpstreamGroupKey]; ok {
+		t.Error("the metadata of the upstream request must not be modified")
+	}
+
+	all := downstreamRequest(&proto.TaskRequest{Targets: req.GetTargets(), Task: "health.ping"})
+	if got := all.TargetsString(); got != "glob:*" {
+		t.Errorf("expected all the nodes without downstream targets, got %s", got)
+	}
+}
+
+func TestDownstreamTimeout(t *testing.T) {
+	tests := map[uint32]uint32{
+		0:  uint32(config.TaskTimeout.Seconds() - config.SyndicTimeoutMargin.Seconds()),
+		60: 58,
+		5:  3,
+		4:  2,
+		1:  1,
+	}
+	for timeout, want := range tests {
+		if got := downstreamTimeout(timeout); got != want {
+			t.Errorf("downstreamTimeout(%d) = %d, want %d", timeout, got, want)
+		}
+	}
+}
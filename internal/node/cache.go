@@ -0,0 +1,141 @@
+package node
+
+import (
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/clock"
+	"github.com/jackadi-io/jackadi/internal/plugin/inventory"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+// resultCache keeps the responses of the tasks having a cache TTL (see sdk Task.WithCacheTTL), by task and input.
+//
+// A task of the plugin running with a write or exclusive lock may change what its other tasks return: it clears
+// the cache of the plugin when it starts and when it finishes, and the responses of the tasks started before are
+// not cached.
+type resultCache struct {
+	mutex   sync.Mutex
+	plugins map[string]*pluginCache
+	clock   clock.Clock
+}
+
+type pluginCache struct {
+	generation uint64                // incremented by each invalidation
+	entries    map[string]cacheEntry // key=task and serialized input
+}
+
+type cacheEntry struct {
+	resp    *proto.TaskResponse
+	expires time.Time
+}
+
+func newResultCache() *resultCache {
+	return &resultCache{plugins: make(map[string]*pluginCache), clock: clock.Real{}}
+}
+
+// plugin returns the cache of the plugin, created if missing. The lock must be held.
+func (c *resultCache) plugin(name string) *pluginCache {
+	p, ok := c.plugins[name]
+	if !ok {
+		p = &pluginCache{entries: make(map[string]cacheEntry)}
+		c.plugins[name] = p
+	}
+	return p
+}
+
+// get returns a copy of the cached response to the request, if not expired.
+//
+// On a miss, the returned generation must be passed to put, so the response is not cached if the plugin cache was
+// cleared meanwhile.
+func (c *resultCache) get(req *proto.TaskRequest) (*proto.TaskResponse, uint64, bool) {
+	if c == nil {
+		return nil, 0, false
+	}
+	key, ok := cacheKey(req)
+	if !ok {
+		return nil, 0, false
+	}
+	plugin, _ := req.PluginTask()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	p := c.plugin(plugin)
+	entry, ok := p.entries[key]
+	if !ok || !c.clock.Now().Before(entry.expires) {
+		return nil, p.generation, false
+	}
+
+	resp := protobuf.Clone(entry.resp).(*proto.TaskResponse)
+	resp.Id = req.GetId()
+	resp.GroupID = req.GroupID
+	resp.LockMode = proto.LockMode_NO_LOCK
+	return resp, p.generation, true
+}
+
+// put caches the response to the request during ttl, if the task succeeded and the plugin cache was not cleared
+// since the generation returned by get.
+func (c *resultCache) put(req *proto.TaskRequest, resp *proto.TaskResponse, ttl time.Duration, generation uint64) {
+	if c == nil || resp.GetInternalError() != proto.InternalError_OK || resp.GetError() != "" {
+		return
+	}
+	key, ok := cacheKey(req)
+	if !ok {
+		return
+	}
+	plugin, _ := req.PluginTask()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	p := c.plugin(plugin)
+	if p.generation != generation {
+		return
+	}
+
+	now := c.clock.Now()
+	maps.DeleteFunc(p.entries, func(_ string, entry cacheEntry) bool { return !now.Before(entry.expires) })
+	p.entries[key] = cacheEntry{resp: protobuf.Clone(resp).(*proto.TaskResponse), expires: now.Add(ttl)}
+}
+
+// invalidate clears the cache of the plugin.
+func (c *resultCache) invalidate(plugin string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	p := c.plugin(plugin)
+	p.generation++
+	clear(p.entries)
+}
+
+// cacheKey returns the key of the request in the plugin cache: the task and its input.
+func cacheKey(req *proto.TaskRequest) (string, bool) {
+	input, err := protobuf.MarshalOptions{Deterministic: true}.Marshal(req.GetInput())
+	if err != nil {
+		slog.Debug("failed to serialize the input, not cached", "task", req.FullTask(), "error", err)
+		return "", false
+	}
+	_, task := req.PluginTask()
+	return task + "\x00" + string(input), true
+}
+
+// cacheTTL returns the cache TTL of the task, 0 if unknown.
+func cacheTTL(req *proto.TaskRequest) time.Duration {
+	plugin, task := req.PluginTask()
+
+	coll, err := inventory.Registry.Get(plugin)
+	if err != nil {
+		return 0
+	}
+
+	ttl, err := coll.GetTaskCacheTTL(task)
+	if err != nil {
+		slog.Debug("failed to get the cache TTL of the task, not cached", "task", task, "error", err)
+		return 0
+	}
+	return ttl
+}
@@ -0,0 +1,50 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/clock"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultCache(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	c := newResultCache()
+	c.clock = fake
+
+	req := &proto.TaskRequest{Id: 1, Task: "testplugin.task1"}
+	_, generation, ok := c.get(req)
+	require.False(t, ok)
+	c.put(req, &proto.TaskResponse{Id: 1, Output: []byte(`"ok"`)}, time.Minute, generation)
+
+	resp, _, ok := c.get(&proto.TaskRequest{Id: 2, Task: "testplugin.task1"})
+	require.True(t, ok, "expected a hit within the TTL")
+	assert.Equal(t, int64(2), resp.GetId(), "expected the ID of the request")
+	assert.Equal(t, `"ok"`, string(resp.GetOutput()))
+
+	fake.Advance(time.Minute)
+	_, generation, ok = c.get(req)
+	assert.False(t, ok, "expected a miss once the TTL expired")
+
+	// the failures are not cached
+	c.put(req, &proto.TaskResponse{Error: "failed"}, time.Minute, generation)
+	_, generation, ok = c.get(req)
+	assert.False(t, ok, "expected the failure not to be cached")
+
+	// a response to a request started before the invalidation is not cached
+	c.invalidate("testplugin")
+	c.put(req, &proto.TaskResponse{Output: []byte(`"stale"`)}, time.Minute, generation)
+	_, _, ok = c.get(req)
+	assert.False(t, ok, "expected the response started before the invalidation not to be cached")
+
+	// the cache of the other plugins is kept
+	other := &proto.TaskRequest{Task: "other.task1"}
+	_, generation, _ = c.get(other)
+	c.put(other, &proto.TaskResponse{Output: []byte(`"ok"`)}, time.Minute, generation)
+	c.invalidate("testplugin")
+	_, _, ok = c.get(other)
+	assert.True(t, ok, "expected the cache of the other plugin to be kept")
+}
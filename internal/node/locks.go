@@ -0,0 +1,65 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+var errLockTimeout = errors.New("timeout reached while waiting for the lock")
+
+// taskLocks are the slots and the lock a task must get before running, depending on its lock mode:
+//   - NO_LOCK tasks run concurrently, up to the max concurrent tasks.
+//   - WRITE tasks run one at a time, concurrently with the NO_LOCK tasks.
+//   - An EXCLUSIVE task runs alone: it waits for the running tasks, and the tasks received meanwhile wait for it,
+//     even the NO_LOCK ones.
+//
+// The tasks waiting for a slot get it by priority, see slots.
+type taskLocks struct {
+	running      *slots
+	runningWrite *slots
+	exclusive    *sync.RWMutex
+}
+
+func newTaskLocks(maxConcurrentTasks int) *taskLocks {
+	return &taskLocks{
+		running:      newSlots(maxConcurrentTasks),
+		runningWrite: newSlots(1), // Only one write task at a time
+		exclusive:    &sync.RWMutex{},
+	}
+}
+
+// acquire waits for the slot and the lock of the lock mode, and returns the function releasing them and the
+// time waited.
+//
+// Only the wait for the slot is bounded: errLockTimeout is returned if expired fires first, and the error of
+// ctx if it is done first.
+func (l *taskLocks) acquire(ctx context.Context, mode proto.LockMode, priority proto.Priority, expired <-chan time.Time) (func(), time.Duration, error) {
+	start := time.Now()
+
+	slot := l.running
+	if mode != proto.LockMode_NO_LOCK {
+		slot = l.runningWrite
+	}
+	if err := slot.acquire(ctx, priority, expired); err != nil {
+		return nil, time.Since(start), err
+	}
+
+	// some task must be the only one to run, like plugin sync
+	if mode == proto.LockMode_EXCLUSIVE {
+		l.exclusive.Lock()
+		return func() {
+			l.exclusive.Unlock()
+			slot.release()
+		}, time.Since(start), nil
+	}
+
+	l.exclusive.RLock()
+	return func() {
+		l.exclusive.RUnlock()
+		slot.release()
+	}, time.Since(start), nil
+}
@@ -0,0 +1,113 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const holdTime = 100 * time.Millisecond
+
+// acquireAsync acquires the lock of the mode in a goroutine, and returns the time waited once released.
+func acquireAsync(t *testing.T, locks *taskLocks, mode proto.LockMode) <-chan time.Duration {
+	t.Helper()
+	waited := make(chan time.Duration, 1)
+	go func() {
+		release, w, err := locks.acquire(context.Background(), mode, proto.Priority_PRIORITY_NORMAL, nil)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		release()
+		waited <- w
+	}()
+	return waited
+}
+
+// holdLock acquires the lock of the mode and releases it after holdTime.
+func holdLock(t *testing.T, locks *taskLocks, mode proto.LockMode) {
+	t.Helper()
+	release, waited, err := locks.acquire(context.Background(), mode, proto.Priority_PRIORITY_NORMAL, nil)
+	require.NoError(t, err)
+	assert.Less(t, waited, holdTime/2, "the first task must not wait")
+	time.AfterFunc(holdTime, release)
+}
+
+func TestTaskLocks_WaitAttribution(t *testing.T) {
+	tests := []struct {
+		name    string
+		held    proto.LockMode
+		mode    proto.LockMode
+		waiting bool
+	}{
+		{"no-lock during exclusive", proto.LockMode_EXCLUSIVE, proto.LockMode_NO_LOCK, true},
+		{"write during exclusive", proto.LockMode_EXCLUSIVE, proto.LockMode_WRITE, true},
+		{"exclusive during no-lock", proto.LockMode_NO_LOCK, proto.LockMode_EXCLUSIVE, true},
+		{"exclusive during write", proto.LockMode_WRITE, proto.LockMode_EXCLUSIVE, true},
+		{"write during write", proto.LockMode_WRITE, proto.LockMode_WRITE, true},
+		{"no-lock during write", proto.LockMode_WRITE, proto.LockMode_NO_LOCK, false},
+		{"write during no-lock", proto.LockMode_NO_LOCK, proto.LockMode_WRITE, false},
+		{"no-lock during no-lock", proto.LockMode_NO_LOCK, proto.LockMode_NO_LOCK, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locks := newTaskLocks(2)
+			holdLock(t, locks, tt.held)
+
+			waited := <-acquireAsync(t, locks, tt.mode)
+			if tt.waiting {
+				assert.GreaterOrEqual(t, waited, holdTime*8/10, "the task must wait for the held lock")
+			} else {
+				assert.Less(t, waited, holdTime/2, "the task must not wait for the held lock")
+			}
+		})
+	}
+}
+
+func TestTaskLocks_MixedLoad(t *testing.T) {
+	locks := newTaskLocks(4)
+
+	// a no-lock task is running, an exclusive task waits for it, and the no-lock tasks received meanwhile wait
+	// for the exclusive one: they are serialized behind it although they do not conflict with the running task
+	holdLock(t, locks, proto.LockMode_NO_LOCK)
+	exclusive := make(chan time.Duration, 1)
+	go func() {
+		release, w, err := locks.acquire(context.Background(), proto.LockMode_EXCLUSIVE, proto.Priority_PRIORITY_NORMAL, nil)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		time.Sleep(holdTime)
+		release()
+		exclusive <- w
+	}()
+	time.Sleep(holdTime / 4) // the exclusive task is waiting
+	noLock := acquireAsync(t, locks, proto.LockMode_NO_LOCK)
+
+	exclusiveWait := <-exclusive
+	noLockWait := <-noLock
+	assert.GreaterOrEqual(t, exclusiveWait, holdTime*8/10, "the exclusive task waits for the running task")
+	assert.Less(t, exclusiveWait, holdTime*3/2)
+	assert.GreaterOrEqual(t, noLockWait, holdTime*14/10, "the no-lock task waits for the running and the exclusive tasks")
+}
+
+func TestTaskLocks_Timeout(t *testing.T) {
+	locks := newTaskLocks(1)
+	holdLock(t, locks, proto.LockMode_WRITE)
+
+	expired := time.After(holdTime / 4)
+	_, waited, err := locks.acquire(context.Background(), proto.LockMode_WRITE, proto.Priority_PRIORITY_NORMAL, expired)
+	assert.ErrorIs(t, err, errLockTimeout)
+	assert.GreaterOrEqual(t, waited, holdTime/5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	holdLock(t, locks, proto.LockMode_NO_LOCK)
+	_, _, err = locks.acquire(ctx, proto.LockMode_NO_LOCK, proto.Priority_PRIORITY_NORMAL, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -1,23 +1,26 @@
 package node
 
 import (
+	"cmp"
 	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
-	"net"
 	"net/netip"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/helper"
+	"github.com/jackadi-io/jackadi/internal/logs"
+	"github.com/jackadi-io/jackadi/internal/plugin/core"
 	"github.com/jackadi-io/jackadi/internal/plugin/inventory"
 	"github.com/jackadi-io/jackadi/internal/plugin/loader/hcplugin"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"github.com/jackadi-io/jackadi/internal/serializer"
+	"github.com/jackadi-io/jackadi/internal/version"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
@@ -39,22 +42,45 @@ type Config struct {
 	MTLSCert           string
 	MTLSKey            string
 	MTLSManagerCA      string
-	PluginDir          string
+	MTLSSPIFFE         config.SPIFFEConfig // Replaces the certificate files when enabled.
+	PluginDirs         []string            // Plugins are synchronized from the manager in the first one.
 	PluginServerPort   string
+	PluginServerTLS    *tls.Config                       // The plugins are downloaded over HTTPS if set.
+	PluginProcesses    map[string]hcplugin.ProcessConfig // key=plugin file
+	AllowedPlugins     []string                          // Plugin files allowed to be loaded, all if empty.
+	PluginSyncInterval time.Duration                     // The plugins are only synchronized on demand (plugins.sync) if zero.
 	CustomResolvers    []string
 	MaxConcurrentTasks int
 	MaxWaitingRequests int
+	Keepalive          keepalive.ClientParameters // The defaults of config.ClientKeepaliveTime and config.ClientKeepaliveTimeout if zero.
+	Compression        bool                       // gzip the messages sent to the manager.
+	Version            string                     // Build version of the node, sent to the manager during the handshake.
 }
 
 type Node struct {
 	config               Config
 	taskClient           proto.ClusterClient
 	conn                 *grpc.ClientConn
+	svidSource           io.Closer // SPIFFE Workload API source, nil if unused
 	pluginLoader         hcplugin.Loader
 	connectedManagerAddr string
 	SpecManager          *SpecsManager
+	executor             Executor
+	heartbeatInterval    time.Duration // Announced by the manager during the handshake, 0 without heartbeats.
+	maxConcurrentTasks   int           // Set by the manager during the handshake, 0 to use the configuration.
+	maxWaitingRequests   int           // Set by the manager during the handshake, 0 to use the configuration.
+	running              *runningTasks
+	cache                *resultCache  // nil without plugins (executor).
+	exclusive            *sync.RWMutex // Exclusive lock of the tasks, shared by the task streams and the periodic plugin sync.
 }
 
+var errMissedHeartbeats = errors.New("no heartbeat received from the manager: half-open task stream")
+
+// Executor runs the task requests in place of the plugins of the node.
+//
+// A syndic (a manager connected to an upstream manager as a node) uses it to dispatch the requests to its own nodes.
+type Executor func(ctx context.Context, req *proto.TaskRequest) *proto.TaskResponse
+
 // New returns a new Node and an initialized context containing values like node_id.
 //
 // While the node is the client from GRPC perspective, it is the server from application perspective,
@@ -71,21 +97,44 @@ func New(cfg Config) (Node, context.Context, error) {
 	n := Node{
 		config:      cfg,
 		SpecManager: specsManager,
+		running:     newRunningTasks(),
+		cache:       newResultCache(),
+		exclusive:   &sync.RWMutex{},
 	}
 	return n, ctx, nil
 }
 
+// NewWithExecutor returns a new Node running the task requests with the executor, without plugins nor specs,
+// and its initialized context derived from ctx.
+//
+// The requests are not locked on the node, the executor is in charge of their lock modes.
+func NewWithExecutor(ctx context.Context, cfg Config, executor Executor) (Node, context.Context) {
+	md := metadata.Pairs("node_id", cfg.NodeID)
+	return Node{config: cfg, executor: executor, running: newRunningTasks()}, metadata.NewOutgoingContext(ctx, md)
+}
+
+// keepaliveParams returns the keepalive parameters of the connection to the manager.
+func (c Config) keepaliveParams() keepalive.ClientParameters {
+	if c.Keepalive.Time == 0 {
+		return keepalive.ClientParameters{
+			Time:                config.ClientKeepaliveTime,
+			Timeout:             config.ClientKeepaliveTimeout,
+			PermitWithoutStream: true,
+		}
+	}
+	return c.Keepalive
+}
+
 func (n *Node) Connect(ctx context.Context) error {
 	var err error
 	slog.Info("connecting to the manager", "address", n.config.ManagerAddress, "port", n.config.ManagerPort)
-	managerHost := net.JoinHostPort(n.config.ManagerAddress, n.config.ManagerPort)
+	managerHost := helper.JoinHostPort(n.config.ManagerAddress, n.config.ManagerPort)
 
 	opts := []grpc.DialOption{
-		grpc.WithKeepaliveParams(keepalive.ClientParameters{
-			Time:                config.ClientKeepaliveTime,
-			Timeout:             config.ClientKeepaliveTimeout,
-			PermitWithoutStream: true,
-		}),
+		grpc.WithKeepaliveParams(n.config.keepaliveParams()),
+	}
+	if n.config.Compression {
+		opts = append(opts, config.CompressionDialOption())
 	}
 
 	if len(n.config.CustomResolvers) > 0 {
@@ -101,7 +150,20 @@ func (n *Node) Connect(ctx context.Context) error {
 		opts = append(opts, grpc.WithResolvers(r))
 	}
 
-	if n.config.MTLSEnabled {
+	switch {
+	case n.config.MTLSEnabled && n.config.MTLSSPIFFE.Enabled:
+		source, err := config.NewSPIFFESource(ctx, n.config.MTLSSPIFFE.Socket)
+		if err != nil {
+			return err
+		}
+		tlsCfg, err := config.GetSPIFFEClientTLSConfig(source, n.config.MTLSSPIFFE.TrustDomain)
+		if err != nil {
+			_ = source.Close()
+			return err
+		}
+		n.svidSource = source
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
+	case n.config.MTLSEnabled:
 		certs, ca, err := config.GetMTLSCertificate(n.config.MTLSCert, n.config.MTLSKey, n.config.MTLSManagerCA)
 		if err != nil {
 			return err
@@ -113,9 +175,8 @@ func (n *Node) Connect(ctx context.Context) error {
 			RootCAs:      ca,
 		}
 		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
-	} else {
-		slog.Warn("unsecured connection with the manager, you should enable mTLS")
-		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	default:
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials())) // warned periodically by the caller
 	}
 
 	n.conn, err = grpc.NewClient(managerHost, opts...)
@@ -131,40 +192,77 @@ func (n *Node) Close() error {
 	if n.conn == nil {
 		return errors.New("trying to close a nil connection")
 	}
+	if n.svidSource != nil {
+		defer n.svidSource.Close()
+	}
 	return n.conn.Close()
 }
 
+// Handshake registers the node to the manager, and checks their compatibility.
+//
+// It fails if the protocol versions differ, a major build version mismatch is only logged.
 func (n *Node) Handshake(ctx context.Context) error {
-	res, err := n.taskClient.Handshake(ctx, &proto.HandshakeRequest{Id: 1})
+	res, err := n.taskClient.Handshake(ctx, &proto.HandshakeRequest{
+		Id:       1,
+		Version:  n.config.Version,
+		Protocol: config.ProtocolVersion,
+	})
 	if err != nil {
 		return fmt.Errorf("handshake failed: %w", err)
 	}
 	slog.Debug("received response to ping", "got", res)
+
+	if err := version.CheckProtocol(res.GetProtocol()); err != nil {
+		slog.Error("incompatible manager", "manager_version", res.GetVersion(), "node_version", n.config.Version, "error", err)
+		return fmt.Errorf("handshake failed: %w", err)
+	}
+	if !version.SameMajor(n.config.Version, res.GetVersion()) {
+		slog.Warn("node and manager major versions differ", "manager_version", res.GetVersion(), "node_version", n.config.Version)
+	}
+	n.heartbeatInterval = time.Duration(res.GetHeartbeatInterval()) * time.Second
+	n.maxConcurrentTasks = int(res.GetMaxConcurrentTasks())
+	n.maxWaitingRequests = int(res.GetMaxWaitingRequests())
+	if n.maxConcurrentTasks > 0 || n.maxWaitingRequests > 0 {
+		slog.Info("queue limits set by the manager", "max_concurrent_tasks", n.maxConcurrentTasks, "max_waiting_requests", n.maxWaitingRequests)
+	}
 	return nil
 }
 
+// queueLimits returns the maximum numbers of running tasks and of waiting requests: the ones set by the manager
+// during the handshake, or else the configured ones, or else the defaults.
+func (n *Node) queueLimits() (maxConcurrentTasks, maxWaitingRequests int) {
+	maxConcurrentTasks = cmp.Or(n.maxConcurrentTasks, max(n.config.MaxConcurrentTasks, 0), config.DefaultMaxConcurrentTasks)
+	maxWaitingRequests = cmp.Or(n.maxWaitingRequests, max(n.config.MaxWaitingRequests, 0), config.DefaultMaxWaitingRequests)
+	return maxConcurrentTasks, maxWaitingRequests
+}
+
 func (n *Node) ListenTaskRequest(ctx context.Context) error {
-	maxConcurrentTasks := n.config.MaxConcurrentTasks
-	if maxConcurrentTasks <= 0 {
-		maxConcurrentTasks = config.DefaultMaxConcurrentTasks
-	}
-	maxWaitingRequests := n.config.MaxWaitingRequests
-	if maxWaitingRequests <= 0 {
-		maxWaitingRequests = config.DefaultMaxWaitingRequests
-	}
+	maxConcurrentTasks, maxWaitingRequests := n.queueLimits()
 
-	runningTasks := make(chan struct{}, maxConcurrentTasks)
-	runningWriteTask := make(chan struct{}, 1) // Only one write task at a time
+	locks := newTaskLocks(maxConcurrentTasks)
+	if n.exclusive != nil {
+		locks.exclusive = n.exclusive
+	}
 	requestsQueue := make(chan struct{}, maxWaitingRequests)
-	exclusiveLock := sync.RWMutex{}
 
-	stream, err := n.taskClient.ExecTask(ctx)
+	// the stream is cancelled when the heartbeats of the manager are missing, the connection is likely half-open
+	streamCtx, cancelStream := context.WithCancelCause(ctx)
+	defer cancelStream(nil)
+
+	stream, err := n.taskClient.ExecTask(streamCtx)
 	if err != nil {
 		return fmt.Errorf("client failed: %w", err)
 	}
 
 	n.updateKnownManagerAddress(stream)
 
+	var watchdog *time.Timer
+	heartbeatLimit := config.HeartbeatTolerance * n.heartbeatInterval
+	if heartbeatLimit > 0 {
+		watchdog = time.AfterFunc(heartbeatLimit, func() { cancelStream(errMissedHeartbeats) })
+		defer watchdog.Stop()
+	}
+
 	defer slog.Debug("exiting task handler")
 
 	wg := sync.WaitGroup{}
@@ -174,6 +272,9 @@ func (n *Node) ListenTaskRequest(ctx context.Context) error {
 		if err != nil || req == nil {
 			slog.Debug("stream error", "error", err, "component", "task listener")
 			wg.Wait()
+			if errors.Is(context.Cause(streamCtx), errMissedHeartbeats) {
+				return fmt.Errorf("client failed: %w", errMissedHeartbeats)
+			}
 			if errors.Is(err, io.EOF) {
 				slog.Debug("stream closed", "component", "task listener")
 				return nil
@@ -181,14 +282,18 @@ func (n *Node) ListenTaskRequest(ctx context.Context) error {
 			slog.Debug("stream failure", "component", "task listener")
 			return fmt.Errorf("client failed: %w", err)
 		}
+		if watchdog != nil {
+			watchdog.Reset(heartbeatLimit)
+		}
 
 		// answers immediately to instant healthcheck
-		if req.Task == fmt.Sprintf("health:%s", config.InstantPingName) {
+		if plugin, task := req.PluginTask(); plugin == "health" && task == config.InstantPingName {
 			out, _ := serializer.JSON.Marshal(true)
 			resp := proto.TaskResponse{
 				Id:      req.GetId(),
 				GroupID: req.GroupID,
 				Output:  out,
+				Running: n.running.reported(),
 			}
 			if err := stream.Send(&resp); err != nil {
 				slog.Error("failed to send back health:ping")
@@ -196,32 +301,63 @@ func (n *Node) ListenTaskRequest(ctx context.Context) error {
 			continue
 		}
 
+		// the request and group IDs are logged all along the execution, to correlate with the manager logs
+		reqCtx := logs.With(ctx, "request_id", req.GetId(), "group_id", req.GetGroupID())
+		logger := logs.FromContext(reqCtx)
+
 		// Resolve the effective lock mode - use CLI override or plugin default
-		lockMode := effectiveLockMode(req)
+		lockMode := proto.LockMode_NO_LOCK
+		var ttl time.Duration
+		if n.executor == nil {
+			lockMode = effectiveLockMode(req)
+			if lockMode == proto.LockMode_NO_LOCK {
+				ttl = cacheTTL(req)
+			}
+		}
 
-		var taskSlot chan struct{}
-		if lockMode == proto.LockMode_NO_LOCK {
-			taskSlot = runningTasks
-		} else {
-			taskSlot = runningWriteTask
+		// the node is shutting down
+		if !n.running.accept() {
+			resp := proto.TaskResponse{
+				Id:            req.GetId(),
+				GroupID:       req.GroupID,
+				InternalError: proto.InternalError_DISCONNECTING,
+			}
+			if err := stream.Send(&resp); err != nil {
+				logger.Error("failed to send back DISCONNECTING")
+			}
+			logger.Warn("request refused: the node is draining", "task", req.FullTask())
+			continue
 		}
 
-		// TODO: implement FIFO queue. Be careful, we will still want to send the timeout response as soon as possible,
-		// But we need to ensure the FIFO queue will discard it to and avoid channel deadlock.
+		// the read tasks with a cache TTL are answered from the cache
+		var generation uint64
+		if ttl > 0 {
+			resp, gen, ok := n.cache.get(req)
+			if ok {
+				n.running.release()
+				if err := stream.Send(resp); err != nil {
+					logger.Error("failed to send response", "err", err)
+				}
+				logger.Debug("cached response sent", "task", req.FullTask())
+				continue
+			}
+			generation = gen
+		}
 
 		// trying to reserve a spot in the queue
 		select {
 		case requestsQueue <- struct{}{}:
 		default:
+			n.running.release()
 			resp := proto.TaskResponse{
 				Id:            req.GetId(),
 				GroupID:       req.GroupID,
 				InternalError: proto.InternalError_FULL_QUEUE,
 			}
 			if err := stream.Send(&resp); err != nil {
-				slog.Error("failed to send back BUSY_QUEUE")
+				logger.Error("failed to send back BUSY_QUEUE")
 			}
-			slog.Error("too many waiting requests", "error", "BUSY_QUEUE")
+			logger.Error("too many waiting requests", "error", "BUSY_QUEUE")
 			continue
 		}
 
@@ -230,84 +366,158 @@ func (n *Node) ListenTaskRequest(ctx context.Context) error {
 		go func() {
 			defer func() {
 				<-requestsQueue
+				n.running.release()
 				wg.Done()
 			}()
 
-			slog.Debug("exec request received", "id", req.Id, "group", req.GetGroupID(), "task", req.Task, "args", req.Input)
+			// the task is aborted if still running at the end of the draining of the node
+			reqCtx, cancelReq := n.running.killable(reqCtx)
+			defer cancelReq()
+
+			logger.Debug("exec request received", "task", req.Task, "args", req.Input)
 			timeout := uint32(config.TaskTimeout.Seconds())
 			if val := req.GetTimeout(); val > 0 {
 				timeout = val
 			}
-			slog.Debug("timeout", "value_set", time.Duration(timeout)*time.Second)
+			logger.Debug("timeout", "value_set", time.Duration(timeout)*time.Second)
 
 			t := time.NewTimer(time.Duration(timeout) * time.Second)
 
+			release, waited, err := locks.acquire(reqCtx, lockMode, req.GetPriority(), t.C)
+			lockWait := waited.Milliseconds()
+			if waited >= config.LockWaitThreshold {
+				logger.Info("task waited for its lock", "task", req.FullTask(), "lock_mode", lockMode.String(), "waited", waited)
+			}
+
 			var resp *proto.TaskResponse
-			select {
-			case taskSlot <- struct{}{}:
-				defer func() { <-taskSlot }()
-
-				// some task must be the only one to run, like plugin sync
-				if lockMode == proto.LockMode_EXCLUSIVE {
-					slog.Debug("lock")
-					exclusiveLock.Lock()
-					defer exclusiveLock.Unlock()
-					defer slog.Debug("unlock")
-				} else {
-					slog.Debug("rlock")
-					exclusiveLock.RLock()
-					defer exclusiveLock.RUnlock()
-					defer slog.Debug("read unlock")
+			switch {
+			case errors.Is(err, errLockTimeout):
+				logger.Debug("task not executed: waiting timeout reached")
+				resp = &proto.TaskResponse{
+					Id:            req.GetId(),
+					GroupID:       req.GroupID,
+					InternalError: proto.InternalError_TIMEOUT,
 				}
 
+			case err != nil && n.running.isKilled():
+				logger.Warn("task not executed: killed by the shutdown of the node")
+				resp = &proto.TaskResponse{
+					Id:            req.GetId(),
+					GroupID:       req.GroupID,
+					InternalError: proto.InternalError_DISCONNECTING,
+					ModuleError:   errKilled.Error(),
+				}
+
+			case err != nil:
+				logger.Debug("task context closed")
+				return
+
+			default:
+				logger.Debug("lock acquired", "lock_mode", lockMode.String())
+				defer release()
+				defer logger.Debug("unlock")
+				if lockMode == proto.LockMode_WRITE || lockMode == proto.LockMode_EXCLUSIVE {
+					plugin, _ := req.PluginTask()
+					n.cache.invalidate(plugin)
+					defer n.cache.invalidate(plugin)
+				}
+				n.running.start(req)
+				defer n.running.done(req.GetId())
+
 				finished := make(chan struct{}, 1)
 				go func() {
 					// send the IDs back to the client for task not finished in time.
 					// even if the task finishes after the timeout, the result will still be sent.
 					select {
 					case <-finished:
-						slog.Debug("task done", "id", req.Id)
+						logger.Debug("task done")
 					case <-t.C:
-						slog.Debug("started task timeout", "id", req.Id)
+						logger.Debug("started task timeout")
 						respErrTimeout := &proto.TaskResponse{
 							Id:            req.GetId(),
 							GroupID:       req.GroupID,
 							InternalError: proto.InternalError_STARTED_TIMEOUT,
+							LockWait:      lockWait,
+							LockMode:      lockMode,
 						}
 						if err := stream.Send(respErrTimeout); err != nil {
-							slog.Error("failed to send response", "err", err)
+							logger.Error("failed to send response", "err", err)
 						}
 					}
 				}()
 
 				// We do not use the context of stream, because we don't want to cancel a maintenance
 				// in case of temporary disconnection.
-				resp = doTask(ctx, req)
+				taskCtx, cancelTask := taskContext(reqCtx, req, time.Duration(timeout)*time.Second)
+				if n.executor != nil {
+					resp = n.executor(taskCtx, req)
+				} else {
+					resp = doTask(core.WithProgress(taskCtx, progressReporter(taskCtx, stream, req)), req)
+				}
+				cancelTask()
 				t.Stop()
 				finished <- struct{}{}
 
-			case <-t.C:
-				slog.Debug("task not executed: waiting timeout reached", "id", req.Id)
-				resp = &proto.TaskResponse{
-					Id:            req.GetId(),
-					GroupID:       req.GroupID,
-					InternalError: proto.InternalError_TIMEOUT,
+				if n.running.isKilled() {
+					logger.Warn("task killed by the shutdown of the node", "task", req.FullTask())
+					resp.InternalError = proto.InternalError_DISCONNECTING
+					resp.ModuleError = errKilled.Error()
 				}
-
-			case <-ctx.Done():
-				slog.Debug("task context closed")
-				return
+				if ttl > 0 {
+					n.cache.put(req, resp, ttl, generation)
+				}
+			}
+			// the lock waits of an executor are the ones of its downstream nodes, reported in their responses
+			if n.executor == nil {
+				resp.LockWait = lockWait
+				resp.LockMode = lockMode
 			}
 
-			slog.Debug("sending response", "id", req.Id)
+			logger.Debug("sending response")
 			if err = stream.Send(resp); err != nil {
-				slog.Error("failed to send response", "err", err)
+				logger.Error("failed to send response", "err", err)
 			}
-			slog.Debug("response sent", "id", req.Id)
+			logger.Debug("response sent")
 		}()
 	}
 }
 
+// taskContext returns the context of the task execution.
+//
+// The spec collection is aborted at the request timeout since the manager gives up waiting for it, while the other
+// tasks run to completion: their result is still sent after the timeout.
+func taskContext(ctx context.Context, req *proto.TaskRequest, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if plugin, _ := req.PluginTask(); plugin != config.SpecManagerPrefix {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// progressReporter returns the function sending the progress updates of a task to the manager.
+//
+// An update is only sent if the percentage changed.
+func progressReporter(ctx context.Context, stream grpc.BidiStreamingClient[proto.TaskResponse, proto.TaskRequest], req *proto.TaskRequest) core.ProgressFunc {
+	mu := sync.Mutex{}
+	last := int32(-1)
+	return func(percent int32) {
+		mu.Lock()
+		defer mu.Unlock()
+		if percent == last {
+			return
+		}
+		last = percent
+
+		resp := proto.TaskResponse{
+			Id:       req.GetId(),
+			GroupID:  req.GroupID,
+			Progress: &percent,
+		}
+		if err := stream.Send(&resp); err != nil {
+			logs.FromContext(ctx).Debug("failed to send progress", "error", err)
+		}
+	}
+}
+
 // updateKnownManagerAddress updates the stored resolved manager address (useful for plugin sync for instance).
 func (n *Node) updateKnownManagerAddress(stream grpc.BidiStreamingClient[proto.TaskResponse, proto.TaskRequest]) {
 	p, ok := peer.FromContext(stream.Context())
@@ -317,77 +527,70 @@ func (n *Node) updateKnownManagerAddress(stream grpc.BidiStreamingClient[proto.T
 			slog.Error("failed to resolve manager address", "addr", p.Addr.String(), "error", err)
 			return
 		}
-		n.connectedManagerAddr = socket.Addr().String()
-		slog.Info("manager address resolved", "addr", socket.Addr().String())
+		// IPv4 addresses of a dual-stack socket are kept as IPv4
+		n.connectedManagerAddr = socket.Addr().Unmap().String()
+		slog.Info("manager address resolved", "addr", n.connectedManagerAddr)
 	}
 }
 
 // effectiveLockMode determines the lock mode to use for a task.
 //
-// Precedence order: override from request, default mode set at task level.
+// Precedence order: override from request, default mode set at task level. The override is ignored if it is
+// weaker than the default of a task setting it as a minimum.
 func effectiveLockMode(req *proto.TaskRequest) proto.LockMode {
-	// request override
-	if req.GetLockMode() != proto.LockMode_UNSPECIFIED {
-		return req.GetLockMode()
-	}
-
-	// get lock mode from the task itself
-	var plugin, task string
-	parts := strings.Split(req.GetTask(), config.PluginSeparator)
+	requested := req.GetLockMode()
+	taskLockMode := defaultLockMode(req)
 
 	switch {
-	case len(parts) == 1:
-		plugin = parts[0]
-		task = parts[0]
-	case len(parts) == 2:
-		plugin = parts[0]
-		task = parts[1]
+	case requested == proto.LockMode_UNSPECIFIED:
+		slog.Debug("using plugin default lock mode", "task", req.FullTask(), "lockMode", taskLockMode.Mode.String())
+		return taskLockMode.Mode
+	case taskLockMode.Minimum && requested < taskLockMode.Mode: // the lock modes are ordered by strength
+		slog.Warn("requested lock mode weaker than the minimum of the task, ignored", "task", req.FullTask(), "requested", requested.String(), "lockMode", taskLockMode.Mode.String())
+		return taskLockMode.Mode
 	default:
-		slog.Debug("bad task name, using NO_LOCK", "task", req.GetTask())
-		return proto.LockMode_NO_LOCK
+		return requested
 	}
+}
+
+// defaultLockMode returns the default lock mode of the task, NO_LOCK if unknown.
+func defaultLockMode(req *proto.TaskRequest) core.TaskLockMode {
+	plugin, task := req.PluginTask()
 
 	coll, err := inventory.Registry.Get(plugin)
 	if err != nil {
 		slog.Debug("plugin not found, using NO_LOCK", "plugin", plugin, "error", err)
-		return proto.LockMode_NO_LOCK
+		return core.TaskLockMode{Mode: proto.LockMode_NO_LOCK}
 	}
 
-	pluginLockMode, err := coll.GetTaskLockMode(task)
+	lockMode, err := coll.GetTaskLockMode(task)
 	if err != nil {
 		slog.Debug("failed to get plugin lock mode, using NO_LOCK", "task", task, "error", err)
-		return proto.LockMode_NO_LOCK
+		return core.TaskLockMode{Mode: proto.LockMode_NO_LOCK}
 	}
-
-	slog.Debug("using plugin default lock mode", "task", req.GetTask(), "lockMode", pluginLockMode.String())
-	return pluginLockMode
+	return lockMode
 }
 
 // doTask routes the request to the plugin containing the wanted task.
 func doTask(ctx context.Context, req *proto.TaskRequest) *proto.TaskResponse {
-	slog.Debug("starting task", "id", req.Id)
-	var plugin, task string
-	parts := strings.Split(req.GetTask(), config.PluginSeparator)
+	logger := logs.FromContext(ctx)
+	logger.Debug("starting task", "task", req.FullTask())
+	plugin, task := req.PluginTask()
 
-	switch {
-	case len(parts) == 1:
-		plugin = parts[0]
-		task = parts[0]
-	case len(parts) == 2:
-		plugin = parts[0]
-		task = parts[1]
-	default:
-		slog.Error("bad task name")
+	// the downstream targets must not be ignored: the task would run on this node instead of the targeted ones
+	if len(req.GetDownstreamTargets()) > 0 || len(req.GetDownstreamExcludes()) > 0 {
+		logger.Error("bad request", "error", "downstream targets sent to a node which is not a syndic")
 		return &proto.TaskResponse{
 			Id:            req.GetId(),
 			GroupID:       req.GroupID,
-			InternalError: proto.InternalError_UNKNOWN_TASK,
+			InternalError: proto.InternalError_DOWNSTREAM_ERROR,
+			ModuleError:   "not a syndic: the node has no downstream nodes",
 		}
 	}
 
 	t, err := inventory.Registry.Get(plugin)
 	if err != nil {
-		slog.Error("bad request", "error", err)
+		logger.Error("bad request", "error", err)
 		return &proto.TaskResponse{
 			Id:            req.GetId(),
 			GroupID:       req.GroupID,
@@ -404,7 +607,14 @@ func doTask(ctx context.Context, req *proto.TaskRequest) *proto.TaskResponse {
 		Error:   response.Error,
 		Retcode: response.Retcode,
 	}
-	if err != nil {
+	if panicErr, ok := core.IsPanic(err); ok {
+		logger.Error("task panicked", "task", req.FullTask(), "error", panicErr.Value)
+		r.InternalError = proto.InternalError_MODULE_PANIC
+		r.ModuleError = panicErr.Error()
+		if logger.Enabled(ctx, slog.LevelDebug) {
+			r.ModuleError += "\n\n" + panicErr.Stack
+		}
+	} else if err != nil {
 		r.InternalError = proto.InternalError_MODULE_ERROR
 		e := status.Convert(err)
 		if e.Code() == codes.Unknown {
@@ -414,7 +624,7 @@ func doTask(ctx context.Context, req *proto.TaskRequest) *proto.TaskResponse {
 		}
 	}
 
-	slog.Debug("task finished", "id", req.Id)
+	logger.Debug("task finished", "retcode", r.GetRetcode(), "internal_error", r.GetInternalError())
 
 	return &r
 }
@@ -1,22 +1,32 @@
 package node
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/logs"
+	"github.com/jackadi-io/jackadi/internal/plugin/builtin"
 	"github.com/jackadi-io/jackadi/internal/plugin/core"
 	"github.com/jackadi-io/jackadi/internal/plugin/inventory"
 	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // mockStream implements grpc.BidiStreamingClient for testing.
@@ -97,8 +107,12 @@ func (m *mockStream) GetResponse(timeout time.Duration) (*proto.TaskResponse, er
 type mockPlugin struct {
 	name       string
 	execFunc   func(ctx context.Context, task string, input *proto.Input) (core.Response, error)
+	specsFunc  func(ctx context.Context) ([]byte, error)
 	lockMode   proto.LockMode
+	cacheTTL   time.Duration
 	taskExists bool
+
+	minimumLockMode bool
 }
 
 func (m *mockPlugin) Name() (string, error) {
@@ -128,14 +142,24 @@ func (m *mockPlugin) Do(ctx context.Context, task string, input *proto.Input) (c
 }
 
 func (m *mockPlugin) CollectSpecs(ctx context.Context) ([]byte, error) {
+	if m.specsFunc != nil {
+		return m.specsFunc(ctx)
+	}
 	return []byte("{}"), nil
 }
 
-func (m *mockPlugin) GetTaskLockMode(task string) (proto.LockMode, error) {
+func (m *mockPlugin) GetTaskLockMode(task string) (core.TaskLockMode, error) {
+	if !m.taskExists {
+		return core.TaskLockMode{Mode: proto.LockMode_NO_LOCK}, errors.New("task not found")
+	}
+	return core.TaskLockMode{Mode: m.lockMode, Minimum: m.minimumLockMode}, nil
+}
+
+func (m *mockPlugin) GetTaskCacheTTL(task string) (time.Duration, error) {
 	if !m.taskExists {
-		return proto.LockMode_NO_LOCK, errors.New("task not found")
+		return 0, errors.New("task not found")
 	}
-	return m.lockMode, nil
+	return m.cacheTTL, nil
 }
 
 func setupTest(t *testing.T) (*Node, context.Context, *mockStream, func()) {
@@ -176,7 +200,7 @@ func TestListenTaskRequest_HealthCheckFastPath(t *testing.T) {
 	// send health check request
 	stream.SendRequest(&proto.TaskRequest{
 		Id:   int64(1),
-		Task: "health:instant-ping",
+		Task: "health" + config.PluginSeparator + config.InstantPingName,
 	})
 
 	// should get immediate response
@@ -193,6 +217,36 @@ func TestListenTaskRequest_HealthCheckFastPath(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestListenTaskRequest_MissedHeartbeats(t *testing.T) {
+	nd, ctx, stream, cleanup := setupTest(t)
+	defer cleanup()
+
+	client := &mockClusterClient{stream: stream, handshake: &proto.HandshakeResponse{Protocol: config.ProtocolVersion, HeartbeatInterval: 30}}
+	nd.taskClient = client
+	require.NoError(t, nd.Handshake(ctx))
+	assert.Equal(t, 30*time.Second, nd.heartbeatInterval)
+	nd.heartbeatInterval = 20 * time.Millisecond // to speed up the test
+
+	done := make(chan error, 1)
+	go func() { done <- nd.ListenTaskRequest(ctx) }()
+
+	// the stream is kept while the manager sends its heartbeats
+	for i := range 5 {
+		stream.SendRequest(&proto.TaskRequest{Id: int64(i + 1), Plugin: "health", Task: config.InstantPingName})
+		_, err := stream.GetResponse(100 * time.Millisecond)
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// then the stream goes silent
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, errMissedHeartbeats)
+	case <-time.After(time.Second):
+		t.Fatal("the silent stream was not closed")
+	}
+}
+
 func TestListenTaskRequest_BasicTaskExecution(t *testing.T) {
 	nd, ctx, stream, cleanup := setupTest(t)
 	defer cleanup()
@@ -231,6 +285,171 @@ func TestListenTaskRequest_BasicTaskExecution(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestListenTaskRequest_Executor(t *testing.T) {
+	nd, ctx, stream, cleanup := setupTest(t)
+	defer cleanup()
+
+	executed := make(chan *proto.TaskRequest, 1)
+	nd.executor = func(ctx context.Context, req *proto.TaskRequest) *proto.TaskResponse {
+		executed <- req
+		return &proto.TaskResponse{
+			Id:         req.GetId(),
+			GroupID:    req.GroupID,
+			Downstream: map[string]*proto.TaskResponse{"leaf1": {Output: []byte(`"ok"`), LockMode: proto.LockMode_WRITE}},
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		nd.taskClient = &mockClusterClient{stream: stream}
+		done <- nd.ListenTaskRequest(ctx)
+	}()
+
+	// the task is not a plugin of the node, and its lock mode is left to the executor
+	stream.SendRequest(&proto.TaskRequest{Id: 3, Task: "unknown.task", LockMode: proto.LockMode_EXCLUSIVE})
+
+	resp, err := stream.GetResponse(200 * time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), resp.GetId())
+	assert.Equal(t, proto.InternalError_OK, resp.GetInternalError())
+	assert.Equal(t, proto.LockMode_UNSPECIFIED, resp.GetLockMode())
+	assert.Equal(t, []byte(`"ok"`), resp.GetDownstream()["leaf1"].GetOutput())
+	assert.Equal(t, "unknown.task", (<-executed).GetTask())
+
+	stream.CloseStream()
+	require.NoError(t, <-done)
+}
+
+func TestListenTaskRequest_Progress(t *testing.T) {
+	nd, ctx, stream, cleanup := setupTest(t)
+	defer cleanup()
+
+	mockPlug := &mockPlugin{
+		name:       "testplugin",
+		taskExists: true,
+		execFunc: func(ctx context.Context, task string, input *proto.Input) (core.Response, error) {
+			progress, ok := core.ProgressFromContext(ctx)
+			if !ok {
+				return core.Response{}, errors.New("no progress reporter")
+			}
+			for _, percent := range []int32{20, 20, 60, 100} {
+				progress(percent)
+			}
+			return core.Response{Output: []byte(`"done"`)}, nil
+		},
+	}
+	_ = inventory.Registry.Register(mockPlug)
+	defer func() { _ = inventory.Registry.Unregister("testplugin") }()
+
+	done := make(chan error, 1)
+	go func() {
+		nd.taskClient = &mockClusterClient{stream: stream}
+		done <- nd.ListenTaskRequest(ctx)
+	}()
+
+	groupID := int64(7)
+	stream.SendRequest(&proto.TaskRequest{
+		Id:      int64(3),
+		GroupID: &groupID,
+		Task:    "testplugin.task1",
+	})
+
+	// duplicated updates are not sent
+	for _, want := range []int32{20, 60, 100} {
+		resp, err := stream.GetResponse(200 * time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), resp.GetId())
+		assert.Equal(t, groupID, resp.GetGroupID())
+		require.NotNil(t, resp.Progress)
+		assert.Equal(t, want, resp.GetProgress())
+		assert.Nil(t, resp.Output)
+	}
+
+	resp, err := stream.GetResponse(200 * time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), resp.GetId())
+	assert.Nil(t, resp.Progress, "final response must not be a progress update")
+	assert.Equal(t, []byte(`"done"`), resp.GetOutput())
+	assert.Equal(t, proto.InternalError_OK, resp.GetInternalError())
+
+	stream.CloseStream()
+	err = <-done
+	assert.NoError(t, err)
+}
+
+// logBuffer captures the JSON logs of the default logger, it is safe for concurrent use.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// lines returns the captured lines of the message.
+func (b *logBuffer) lines(msg string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var found []string
+	for line := range strings.Lines(b.buf.String()) {
+		if strings.Contains(line, fmt.Sprintf(`"msg":%q`, msg)) {
+			found = append(found, line)
+		}
+	}
+	return found
+}
+
+func captureLogs(t *testing.T) *logBuffer {
+	t.Helper()
+	prev := slog.Default()
+	b := &logBuffer{}
+	slog.SetDefault(slog.New(slog.NewJSONHandler(b, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return b
+}
+
+func TestListenTaskRequest_LogsCorrelation(t *testing.T) {
+	captured := captureLogs(t)
+	nd, ctx, stream, cleanup := setupTest(t)
+	defer cleanup()
+
+	mockPlug := &mockPlugin{
+		name:       "testplugin",
+		taskExists: true,
+		lockMode:   proto.LockMode_NO_LOCK,
+	}
+	_ = inventory.Registry.Register(mockPlug)
+	defer func() { _ = inventory.Registry.Unregister("testplugin") }()
+
+	done := make(chan error, 1)
+	go func() {
+		nd.taskClient = &mockClusterClient{stream: stream}
+		done <- nd.ListenTaskRequest(ctx)
+	}()
+
+	groupID := int64(42)
+	stream.SendRequest(&proto.TaskRequest{
+		Id:      int64(4),
+		GroupID: &groupID,
+		Task:    "testplugin.task1",
+	})
+	_, err := stream.GetResponse(200 * time.Millisecond)
+	require.NoError(t, err)
+
+	stream.CloseStream()
+	require.NoError(t, <-done)
+
+	for _, msg := range []string{"exec request received", "starting task", "task finished", "response sent"} {
+		lines := captured.lines(msg)
+		require.Len(t, lines, 1, msg)
+		assert.Contains(t, lines[0], `"request_id":4`, msg)
+		assert.Contains(t, lines[0], `"group_id":42`, msg)
+	}
+}
+
 func TestListenTaskRequest_TimeoutBeforeSlot(t *testing.T) {
 	nd, ctx, stream, cleanup := setupTest(t)
 	defer cleanup()
@@ -238,13 +457,16 @@ func TestListenTaskRequest_TimeoutBeforeSlot(t *testing.T) {
 	nd.config.MaxConcurrentTasks = 2
 	nd.config.MaxWaitingRequests = 10
 
-	// register slow plugin
+	// register a plugin holding its slot until released
+	started := make(chan struct{}, nd.config.MaxConcurrentTasks)
+	release := make(chan struct{})
 	mockPlug := &mockPlugin{
 		name:       "testplugin",
 		taskExists: true,
 		lockMode:   proto.LockMode_NO_LOCK,
 		execFunc: func(ctx context.Context, task string, input *proto.Input) (core.Response, error) {
-			time.Sleep(100 * time.Millisecond)
+			started <- struct{}{}
+			<-release
 			return core.Response{Output: []byte("done"), Retcode: 0}, nil
 		},
 	}
@@ -265,53 +487,36 @@ func TestListenTaskRequest_TimeoutBeforeSlot(t *testing.T) {
 			Timeout: 10, // 10 seconds
 		})
 	}
+	for range nd.config.MaxConcurrentTasks {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("the task slots were not filled")
+		}
+	}
 
-	// send request with very short timeout - should timeout before getting slot
+	// the shortest timeout expires while the slots are held
 	stream.SendRequest(&proto.TaskRequest{
 		Id:      int64(999),
 		Task:    "testplugin.task1",
-		Timeout: 0, // Immediate timeout
+		Timeout: 1,
 	})
 
-	// should get timeout response quickly
-	// we need to collect multiple responses since slots are occupied
-	var timeoutResp *proto.TaskResponse
-	timeout := time.After(1 * time.Second)
-	collected := 0
-
-checkResponses:
-	for {
-		select {
-		case <-timeout:
-			t.Log("Timeout waiting for responses")
-			break checkResponses
-		default:
-			resp, err := stream.GetResponse(150 * time.Millisecond)
-			if err != nil {
-				continue
-			}
-			collected++
-			t.Logf("Got response ID=%d, error=%v", resp.Id, resp.InternalError)
-			if resp.Id == 999 && resp.InternalError == proto.InternalError_TIMEOUT {
-				timeoutResp = resp
-				break checkResponses
-			}
-			if collected > 10 {
-				break checkResponses
-			}
-		}
-	}
-
-	if timeoutResp != nil {
-		assert.Equal(t, proto.InternalError_TIMEOUT, timeoutResp.InternalError)
-	} else {
-		t.Skip("Timeout test is timing-sensitive and may not always trigger - skipping assertion")
+	resp, err := stream.GetResponse(3 * time.Second)
+	require.NoError(t, err, "no response before the slots are released")
+	assert.Equal(t, int64(999), resp.GetId())
+	assert.Equal(t, proto.InternalError_TIMEOUT, resp.InternalError)
+
+	// the tasks holding the slots complete once released
+	close(release)
+	for range nd.config.MaxConcurrentTasks {
+		resp, err := stream.GetResponse(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, proto.InternalError_OK, resp.InternalError)
 	}
 
-	time.Sleep(10 * time.Millisecond)
 	stream.CloseStream()
-	err := <-done
-	assert.NoError(t, err)
+	assert.NoError(t, <-done)
 }
 
 func TestListenTaskRequest_QueueFull(t *testing.T) {
@@ -369,6 +574,69 @@ func TestListenTaskRequest_QueueFull(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestListenTaskRequest_ManagerQueueLimits(t *testing.T) {
+	nd, ctx, stream, cleanup := setupTest(t)
+	defer cleanup()
+
+	nd.config.MaxConcurrentTasks = 10
+	nd.config.MaxWaitingRequests = 10
+
+	release := make(chan struct{})
+	mockPlug := &mockPlugin{
+		name:       "testplugin",
+		taskExists: true,
+		lockMode:   proto.LockMode_NO_LOCK,
+		execFunc: func(ctx context.Context, task string, input *proto.Input) (core.Response, error) {
+			<-release
+			return core.Response{Output: []byte("done"), Retcode: 0}, nil
+		},
+	}
+	_ = inventory.Registry.Register(mockPlug)
+	defer func() { _ = inventory.Registry.Unregister("testplugin") }()
+
+	// the manager shrinks the queue of the node
+	nd.taskClient = &mockClusterClient{stream: stream, handshake: &proto.HandshakeResponse{
+		Protocol:           config.ProtocolVersion,
+		MaxConcurrentTasks: 1,
+		MaxWaitingRequests: 2,
+	}}
+	require.NoError(t, nd.Handshake(ctx))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- nd.ListenTaskRequest(ctx)
+	}()
+
+	for i := range 5 {
+		stream.SendRequest(&proto.TaskRequest{
+			Id:   int64(i),
+			Task: "testplugin.task1",
+		})
+	}
+
+	// the requests beyond the 2 waiting ones are refused, despite the 10 of the node configuration
+	fullQueueCount := 0
+	for range 3 {
+		resp, err := stream.GetResponse(time.Second)
+		require.NoError(t, err)
+		if resp.InternalError == proto.InternalError_FULL_QUEUE {
+			fullQueueCount++
+		}
+	}
+	assert.Equal(t, 3, fullQueueCount)
+
+	close(release)
+	for range 2 {
+		resp, err := stream.GetResponse(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, proto.InternalError_OK, resp.InternalError)
+	}
+
+	stream.CloseStream()
+	err := <-done
+	assert.NoError(t, err)
+}
+
 func TestListenTaskRequest_ExclusiveLock(t *testing.T) {
 	nd, ctx, stream, cleanup := setupTest(t)
 	defer cleanup()
@@ -425,6 +693,105 @@ func TestListenTaskRequest_ExclusiveLock(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestListenTaskRequest_LockWait(t *testing.T) {
+	nd, ctx, stream, cleanup := setupTest(t)
+	defer cleanup()
+
+	mockPlug := &mockPlugin{
+		name:       "testplugin",
+		taskExists: true,
+		lockMode:   proto.LockMode_NO_LOCK,
+		execFunc: func(ctx context.Context, task string, input *proto.Input) (core.Response, error) {
+			time.Sleep(100 * time.Millisecond)
+			return core.Response{Output: []byte("done"), Retcode: 0}, nil
+		},
+	}
+	_ = inventory.Registry.Register(mockPlug)
+	defer func() { _ = inventory.Registry.Unregister("testplugin") }()
+
+	done := make(chan error, 1)
+	go func() {
+		nd.taskClient = &mockClusterClient{stream: stream}
+		done <- nd.ListenTaskRequest(ctx)
+	}()
+
+	// the no-lock task, running with the default lock mode of the task, waits for the exclusive one
+	stream.SendRequest(&proto.TaskRequest{Id: 1, Task: "testplugin.task1", LockMode: proto.LockMode_EXCLUSIVE})
+	time.Sleep(20 * time.Millisecond)
+	stream.SendRequest(&proto.TaskRequest{Id: 2, Task: "testplugin.task1"})
+
+	responses := make(map[int64]*proto.TaskResponse)
+	for range 2 {
+		resp, err := stream.GetResponse(time.Second)
+		require.NoError(t, err)
+		responses[resp.GetId()] = resp
+	}
+
+	require.Contains(t, responses, int64(1))
+	require.Contains(t, responses, int64(2))
+	assert.Equal(t, proto.LockMode_EXCLUSIVE, responses[1].GetLockMode())
+	assert.Less(t, responses[1].GetLockWait(), int64(50))
+	assert.Equal(t, proto.LockMode_NO_LOCK, responses[2].GetLockMode())
+	assert.GreaterOrEqual(t, responses[2].GetLockWait(), int64(60), "the wait for the exclusive task must be reported")
+
+	stream.CloseStream()
+	err := <-done
+	assert.NoError(t, err)
+}
+
+func TestListenTaskRequest_Cache(t *testing.T) {
+	nd, ctx, stream, cleanup := setupTest(t)
+	defer cleanup()
+	nd.cache = newResultCache()
+
+	var mu sync.Mutex
+	runs := 0
+	mockPlug := &mockPlugin{
+		name:       "testplugin",
+		taskExists: true,
+		lockMode:   proto.LockMode_NO_LOCK,
+		cacheTTL:   time.Minute,
+		execFunc: func(ctx context.Context, task string, input *proto.Input) (core.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			runs++
+			return core.Response{Output: fmt.Appendf(nil, "%d", runs)}, nil
+		},
+	}
+	_ = inventory.Registry.Register(mockPlug)
+	defer func() { _ = inventory.Registry.Unregister("testplugin") }()
+
+	done := make(chan error, 1)
+	go func() {
+		nd.taskClient = &mockClusterClient{stream: stream}
+		done <- nd.ListenTaskRequest(ctx)
+	}()
+
+	args := func(arg string) *proto.Input {
+		return &proto.Input{Args: &structpb.ListValue{Values: []*structpb.Value{structpb.NewStringValue(arg)}}}
+	}
+	exec := func(id int64, input *proto.Input, lockMode proto.LockMode) *proto.TaskResponse {
+		stream.SendRequest(&proto.TaskRequest{Id: id, Task: "testplugin.task1", Input: input, LockMode: lockMode})
+		resp, err := stream.GetResponse(time.Second)
+		require.NoError(t, err)
+		require.Equal(t, id, resp.GetId())
+		return resp
+	}
+
+	assert.Equal(t, "1", string(exec(1, args("a"), proto.LockMode_UNSPECIFIED).GetOutput()))
+	assert.Equal(t, "1", string(exec(2, args("a"), proto.LockMode_UNSPECIFIED).GetOutput()), "expected the cached response within the TTL")
+	assert.Equal(t, "2", string(exec(3, args("b"), proto.LockMode_UNSPECIFIED).GetOutput()), "expected another input to run the task")
+
+	// a task of the plugin running with a write lock clears the cache, its own response is not cached
+	assert.Equal(t, "3", string(exec(4, args("a"), proto.LockMode_WRITE).GetOutput()))
+	assert.Equal(t, "4", string(exec(5, args("a"), proto.LockMode_UNSPECIFIED).GetOutput()), "expected the cache cleared by the write task")
+	assert.Equal(t, "4", string(exec(6, args("a"), proto.LockMode_UNSPECIFIED).GetOutput()))
+
+	stream.CloseStream()
+	err := <-done
+	assert.NoError(t, err)
+}
+
 func TestListenTaskRequest_ContextCancellation(t *testing.T) {
 	nd, ctx, stream, cleanup := setupTest(t)
 	defer cleanup()
@@ -471,6 +838,141 @@ func TestListenTaskRequest_ContextCancellation(t *testing.T) {
 	}
 }
 
+func TestListenTaskRequest_SpecsDeadline(t *testing.T) {
+	nd, ctx, stream, cleanup := setupTest(t)
+	defer cleanup()
+
+	// hung specs collector, honoring the context
+	mockPlug := &mockPlugin{
+		name:       config.SpecManagerPrefix,
+		taskExists: true,
+		lockMode:   proto.LockMode_NO_LOCK,
+		execFunc: func(ctx context.Context, task string, input *proto.Input) (core.Response, error) {
+			select {
+			case <-ctx.Done():
+				return core.Response{}, ctx.Err()
+			case <-time.After(5 * time.Second):
+				return core.Response{Output: []byte("{}")}, nil
+			}
+		},
+	}
+	require.NoError(t, inventory.Registry.RegisterBuiltin(mockPlug))
+	defer func() { _ = inventory.Registry.Unregister(config.SpecManagerPrefix) }()
+
+	done := make(chan error, 1)
+	go func() {
+		nd.taskClient = &mockClusterClient{stream: stream}
+		done <- nd.ListenTaskRequest(ctx)
+	}()
+
+	stream.SendRequest(&proto.TaskRequest{
+		Id:      int64(1),
+		Plugin:  config.SpecManagerPrefix,
+		Task:    "all",
+		Timeout: 1,
+	})
+
+	// the collector is cancelled at the request timeout instead of running until its end, the STARTED_TIMEOUT
+	// response racing with its result
+	resp, err := stream.GetResponse(2 * time.Second)
+	require.NoError(t, err)
+	if resp.InternalError == proto.InternalError_STARTED_TIMEOUT {
+		resp, err = stream.GetResponse(2 * time.Second)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, proto.InternalError_MODULE_ERROR, resp.InternalError)
+	assert.Contains(t, resp.ModuleError, context.DeadlineExceeded.Error())
+
+	stream.CloseStream()
+	err = <-done
+	assert.NoError(t, err)
+}
+
+func TestShutdown_Drain(t *testing.T) {
+	nd, ctx, stream, cleanup := setupTest(t)
+	defer cleanup()
+	nd.running = newRunningTasks()
+
+	// task outliving the shutdown, aborted when killed
+	mockPlug := &mockPlugin{
+		name:       "testplugin",
+		taskExists: true,
+		lockMode:   proto.LockMode_NO_LOCK,
+		execFunc: func(ctx context.Context, task string, input *proto.Input) (core.Response, error) {
+			select {
+			case <-ctx.Done():
+				return core.Response{}, ctx.Err()
+			case <-time.After(5 * time.Second):
+				return core.Response{Output: []byte("done")}, nil
+			}
+		},
+	}
+	_ = inventory.Registry.Register(mockPlug)
+	defer func() { _ = inventory.Registry.Unregister("testplugin") }()
+
+	done := make(chan error, 1)
+	go func() {
+		nd.taskClient = &mockClusterClient{stream: stream}
+		done <- nd.ListenTaskRequest(ctx)
+	}()
+
+	stream.SendRequest(&proto.TaskRequest{Id: int64(1), Plugin: "testplugin", Task: "task1"})
+	require.Eventually(t, func() bool { return len(nd.running.longerThan(0)) == 1 }, time.Second, 10*time.Millisecond)
+
+	cutOff := make(chan []*proto.RunningTask, 1)
+	go func() { cutOff <- nd.Shutdown(300 * time.Millisecond) }()
+	require.Eventually(t, func() bool {
+		nd.running.mutex.Lock()
+		defer nd.running.mutex.Unlock()
+		return nd.running.draining
+	}, time.Second, 10*time.Millisecond)
+
+	// new requests are refused
+	stream.SendRequest(&proto.TaskRequest{Id: int64(2), Plugin: "testplugin", Task: "task1"})
+	resp, err := stream.GetResponse(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.Id)
+	assert.Equal(t, proto.InternalError_DISCONNECTING, resp.InternalError)
+
+	// the running task is reported to the manager, even if not long-running
+	stream.SendRequest(&proto.TaskRequest{Id: int64(3), Plugin: "health", Task: config.InstantPingName})
+	resp, err = stream.GetResponse(time.Second)
+	require.NoError(t, err)
+	require.Len(t, resp.Running, 1)
+	assert.Equal(t, int64(1), resp.Running[0].GetId())
+
+	// the task is killed at the timeout and answered as such
+	resp, err = stream.GetResponse(2 * time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.Id)
+	assert.Equal(t, proto.InternalError_DISCONNECTING, resp.InternalError)
+	assert.Equal(t, errKilled.Error(), resp.ModuleError)
+
+	select {
+	case tasks := <-cutOff:
+		require.Len(t, tasks, 1)
+		assert.Equal(t, int64(1), tasks[0].GetId())
+		assert.Equal(t, "testplugin.task1", tasks[0].GetTask())
+	case <-time.After(2 * time.Second):
+		t.Fatal("the shutdown did not return")
+	}
+
+	stream.CloseStream()
+	err = <-done
+	assert.NoError(t, err)
+}
+
+func TestShutdown_Idle(t *testing.T) {
+	nd, _, _, cleanup := setupTest(t)
+	defer cleanup()
+	nd.running = newRunningTasks()
+
+	start := time.Now()
+	assert.Empty(t, nd.Shutdown(time.Minute))
+	assert.Less(t, time.Since(start), time.Second, "no task to wait for")
+	assert.False(t, nd.running.isKilled())
+}
+
 func TestListenTaskRequest_UnknownTask(t *testing.T) {
 	nd, ctx, stream, cleanup := setupTest(t)
 	defer cleanup()
@@ -641,17 +1143,261 @@ func TestListenTaskRequest_SlotRelease(t *testing.T) {
 
 // mockClusterClient implements proto.ClusterClient for testing.
 type mockClusterClient struct {
-	stream *mockStream
+	stream    *mockStream
+	handshake *proto.HandshakeResponse // default: compatible manager
+	received  *proto.HandshakeRequest
+	plugins   map[string]string      // plugins of the node, key=file, value=checksum
+	listed    atomic.Int32           // number of plugin listings
+	reported  chan *proto.PluginSync // plugin sync reports, dropped if full
 }
 
 func (m *mockClusterClient) Handshake(ctx context.Context, in *proto.HandshakeRequest, opts ...grpc.CallOption) (*proto.HandshakeResponse, error) {
-	return &proto.HandshakeResponse{Id: 1}, nil
+	m.received = in
+	if m.handshake != nil {
+		return m.handshake, nil
+	}
+	return &proto.HandshakeResponse{Id: 1, Protocol: config.ProtocolVersion}, nil
 }
 
 func (m *mockClusterClient) ExecTask(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[proto.TaskResponse, proto.TaskRequest], error) {
+	context.AfterFunc(ctx, m.stream.CloseStream) // like gRPC, the stream ends with its context
 	return m.stream, nil
 }
 
 func (m *mockClusterClient) ListNodePlugins(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*proto.ListNodePluginsResponse, error) {
-	return &proto.ListNodePluginsResponse{}, nil
+	m.listed.Add(1)
+	return &proto.ListNodePluginsResponse{Plugin: m.plugins}, nil
+}
+
+func (m *mockClusterClient) ReportPluginSync(ctx context.Context, in *proto.PluginSync, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	select {
+	case m.reported <- in:
+	default: // not captured, like a manager not keeping them
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func TestHandshake_Versions(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    *proto.HandshakeResponse
+		wantErr error
+	}{
+		{name: "compatible", resp: &proto.HandshakeResponse{Version: "1.2.0", Protocol: config.ProtocolVersion}},
+		{name: "major version mismatch", resp: &proto.HandshakeResponse{Version: "2.0.0", Protocol: config.ProtocolVersion}},
+		{name: "manager older than the version check", resp: &proto.HandshakeResponse{}},
+		{name: "incompatible protocol", resp: &proto.HandshakeResponse{Version: "1.2.0", Protocol: config.ProtocolVersion + 1}, wantErr: version.ErrIncompatibleProtocol},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockClusterClient{handshake: tt.resp}
+			nd := &Node{config: Config{NodeID: "test-node", Version: "1.0.0"}, taskClient: client}
+
+			err := nd.Handshake(context.Background())
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, "1.0.0", client.received.GetVersion())
+			assert.Equal(t, uint32(config.ProtocolVersion), client.received.GetProtocol())
+		})
+	}
+}
+
+// diagRequest returns a request for a task of the builtin diag plugin, with CLI-like string inputs.
+func diagRequest(t *testing.T, task string, args []any, options map[string]any) *proto.TaskRequest {
+	t.Helper()
+	argList, err := structpb.NewList(args)
+	require.NoError(t, err)
+	opts, err := structpb.NewStruct(options)
+	require.NoError(t, err)
+	return &proto.TaskRequest{
+		Id:    1,
+		Task:  "diag" + config.PluginSeparator + task,
+		Input: &proto.Input{Args: argList, Options: opts},
+	}
+}
+
+func loadDiag(t *testing.T) {
+	t.Helper()
+	builtin.MustLoadDiag()
+	t.Cleanup(func() { _ = inventory.Registry.Unregister("diag") })
+}
+
+func TestDoTask_DiagEcho(t *testing.T) {
+	loadDiag(t)
+
+	resp := doTask(context.Background(), diagRequest(t, "echo", []any{"hello"}, nil))
+	assert.Equal(t, proto.InternalError_OK, resp.GetInternalError())
+	assert.Equal(t, `"hello"`, string(resp.GetOutput()))
+	assert.Empty(t, resp.GetError())
+}
+
+func TestDoTask_DiagSleep(t *testing.T) {
+	loadDiag(t)
+
+	t.Run("with progress", func(t *testing.T) {
+		var updates []int32
+		ctx := core.WithProgress(context.Background(), func(percent int32) {
+			updates = append(updates, percent)
+		})
+
+		resp := doTask(ctx, diagRequest(t, "sleep", []any{"1"}, nil))
+		assert.Equal(t, proto.InternalError_OK, resp.GetInternalError())
+		assert.Equal(t, "1", string(resp.GetOutput()))
+		assert.Equal(t, []int32{100}, updates)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		resp := doTask(ctx, diagRequest(t, "sleep", []any{"10"}, nil))
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Equal(t, "0", string(resp.GetOutput()))
+		assert.Equal(t, context.DeadlineExceeded.Error(), resp.GetError())
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		resp := doTask(context.Background(), diagRequest(t, "sleep", []any{"-1"}, nil))
+		assert.NotEmpty(t, resp.GetError())
+	})
+}
+
+func TestDoTask_DiagFail(t *testing.T) {
+	loadDiag(t)
+
+	resp := doTask(context.Background(), diagRequest(t, "fail", nil, nil))
+	assert.Equal(t, proto.InternalError_OK, resp.GetInternalError())
+	assert.Equal(t, int32(1), resp.GetRetcode())
+	assert.Equal(t, "failure requested", resp.GetError())
+
+	resp = doTask(context.Background(), diagRequest(t, "fail", nil, map[string]any{"retcode": "3", "error": "disk full"}))
+	assert.Equal(t, proto.InternalError_OK, resp.GetInternalError())
+	assert.Equal(t, int32(3), resp.GetRetcode())
+	assert.Equal(t, "disk full", resp.GetError())
+}
+
+func TestDoTask_Panic(t *testing.T) {
+	mockPlug := &mockPlugin{
+		name:       "testplugin",
+		taskExists: true,
+		execFunc: func(ctx context.Context, task string, input *proto.Input) (core.Response, error) {
+			return core.Response{}, &core.PanicError{Value: "assignment to entry in nil map", Stack: "goroutine 7 [running]:"}
+		},
+	}
+	require.NoError(t, inventory.Registry.Register(mockPlug))
+	t.Cleanup(func() { _ = inventory.Registry.Unregister("testplugin") })
+
+	req := &proto.TaskRequest{Id: 1, Task: "testplugin" + config.PluginSeparator + "task1"}
+	infoLogger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	resp := doTask(logs.WithLogger(context.Background(), infoLogger), req)
+	assert.Equal(t, proto.InternalError_MODULE_PANIC, resp.GetInternalError())
+	assert.Equal(t, "task panicked: assignment to entry in nil map", resp.GetModuleError())
+
+	// the stack trace is only sent in debug
+	debugLogger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	resp = doTask(logs.WithLogger(context.Background(), debugLogger), req)
+	assert.Equal(t, proto.InternalError_MODULE_PANIC, resp.GetInternalError())
+	assert.Contains(t, resp.GetModuleError(), "goroutine 7 [running]:")
+}
+
+func TestDoTask_DownstreamTargets(t *testing.T) {
+	loadDiag(t)
+
+	req := diagRequest(t, "echo", []any{"hello"}, nil)
+	req.DownstreamTargets = []*proto.Target{{Target: "web-*", Mode: proto.TargetMode_GLOB}}
+	resp := doTask(context.Background(), req)
+	assert.Equal(t, proto.InternalError_DOWNSTREAM_ERROR, resp.GetInternalError())
+	assert.Empty(t, resp.GetOutput(), "the task must not run on a node which is not a syndic")
+}
+
+func TestDoTask_ReservedPluginName(t *testing.T) {
+	err := inventory.Registry.Register(&mockPlugin{name: "health", taskExists: true})
+	require.ErrorIs(t, err, inventory.ErrReservedName)
+
+	builtin.MustLoadHealth()
+	t.Cleanup(func() { _ = inventory.Registry.Unregister("health") })
+
+	args, err := structpb.NewList(nil)
+	require.NoError(t, err)
+	resp := doTask(context.Background(), &proto.TaskRequest{Id: 1, Task: "health" + config.PluginSeparator + "ping", Input: &proto.Input{Args: args}})
+	assert.Equal(t, proto.InternalError_OK, resp.GetInternalError())
+	assert.Equal(t, "true", string(resp.GetOutput()), resp.GetModuleError())
+	assert.Equal(t, proto.LockMode_NO_LOCK, effectiveLockMode(&proto.TaskRequest{Task: "health" + config.PluginSeparator + "ping"}))
+}
+
+func TestDoTask_TaskNameWithSeparator(t *testing.T) {
+	var received string
+	mockPlug := &mockPlugin{
+		name:       "testplugin",
+		taskExists: true,
+		lockMode:   proto.LockMode_WRITE,
+		execFunc: func(ctx context.Context, task string, input *proto.Input) (core.Response, error) {
+			received = task
+			return core.Response{Output: []byte(`"ok"`)}, nil
+		},
+	}
+	require.NoError(t, inventory.Registry.Register(mockPlug))
+	t.Cleanup(func() { _ = inventory.Registry.Unregister("testplugin") })
+
+	tests := []struct {
+		name string
+		req  *proto.TaskRequest
+	}{
+		{name: "plugin field", req: &proto.TaskRequest{Plugin: "testplugin", Task: "v1.run"}},
+		{name: "joined form", req: &proto.TaskRequest{Task: "testplugin" + config.PluginSeparator + "v1.run"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			resp := doTask(context.Background(), tt.req)
+			assert.Equal(t, proto.InternalError_OK, resp.GetInternalError())
+			assert.Equal(t, "v1.run", received)
+			assert.Equal(t, proto.LockMode_WRITE, effectiveLockMode(tt.req))
+		})
+	}
+}
+
+func TestEffectiveLockMode_Minimum(t *testing.T) {
+	for _, plug := range []*mockPlugin{
+		{name: "minimum", taskExists: true, lockMode: proto.LockMode_WRITE, minimumLockMode: true},
+		{name: "default", taskExists: true, lockMode: proto.LockMode_WRITE},
+	} {
+		require.NoError(t, inventory.Registry.Register(plug))
+		t.Cleanup(func() { _ = inventory.Registry.Unregister(plug.name) })
+	}
+
+	tests := []struct {
+		plugin    string
+		requested proto.LockMode
+		want      proto.LockMode
+	}{
+		{plugin: "minimum", requested: proto.LockMode_UNSPECIFIED, want: proto.LockMode_WRITE},
+		{plugin: "minimum", requested: proto.LockMode_NO_LOCK, want: proto.LockMode_WRITE},
+		{plugin: "minimum", requested: proto.LockMode_WRITE, want: proto.LockMode_WRITE},
+		{plugin: "minimum", requested: proto.LockMode_EXCLUSIVE, want: proto.LockMode_EXCLUSIVE},
+		{plugin: "default", requested: proto.LockMode_NO_LOCK, want: proto.LockMode_NO_LOCK},
+		{plugin: "unknown", requested: proto.LockMode_NO_LOCK, want: proto.LockMode_NO_LOCK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.plugin+"/"+tt.requested.String(), func(t *testing.T) {
+			req := &proto.TaskRequest{Plugin: tt.plugin, Task: "task1", LockMode: tt.requested}
+			assert.Equal(t, tt.want, effectiveLockMode(req))
+		})
+	}
+}
+
+func TestConfigKeepaliveParams(t *testing.T) {
+	assert.Equal(t, keepalive.ClientParameters{
+		Time:                config.ClientKeepaliveTime,
+		Timeout:             config.ClientKeepaliveTimeout,
+		PermitWithoutStream: true,
+	}, Config{}.keepaliveParams(), "the defaults are expected without keepalive configuration")
+
+	configured := keepalive.ClientParameters{Time: 5 * time.Minute, Timeout: time.Minute}
+	assert.Equal(t, configured, Config{Keepalive: configured}.keepaliveParams())
 }
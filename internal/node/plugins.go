@@ -5,16 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"net"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/helper"
 	"github.com/jackadi-io/jackadi/internal/plugin/builtin"
 	"github.com/jackadi-io/jackadi/internal/plugin/inventory"
 	"github.com/jackadi-io/jackadi/internal/plugin/loader/hcplugin"
 	"github.com/jackadi-io/jackadi/internal/plugin/loader/stdplugin"
 	"github.com/jackadi-io/jackadi/internal/plugin/types"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -32,6 +36,8 @@ func (n *Node) NodePlugins(ctx context.Context) (map[string]string, error) {
 func LoadBuiltins(syncReq chan struct{}) chan types.PluginUpdateResponse {
 	builtin.MustLoadCmd()
 	builtin.MustLoadHealth()
+	builtin.MustLoadDiag()
+	builtin.MustLoadLogs()
 	return builtin.MustLoadPluginMgmt(syncReq)
 }
 
@@ -39,11 +45,16 @@ func (n *Node) KeepPluginsUpToDate(ctxMetadata context.Context, specsSync chan s
 	defer slog.Info("plugin manager closed")
 
 	// Load Go standard plugin (not preferred)
-	stdplugin.Load(n.config.PluginDir)
+	for _, dir := range n.config.PluginDirs {
+		stdplugin.Load(dir)
+	}
 
 	// Load hashicorp type plugins
 	hcplugins := hcplugin.New()
-	hcplugins.Load(n.config.PluginDir)
+	hcplugins.SetProcessConfig(n.config.PluginProcesses)
+	hcplugins.SetAllowed(n.config.AllowedPlugins)
+	hcplugins.SetTLSConfig(n.config.PluginServerTLS)
+	hcplugins.Load(n.config.PluginDirs)
 	slog.Info("loaded plugins", "plugins", inventory.Registry.Names())
 
 	mu.Lock()
@@ -52,22 +63,92 @@ func (n *Node) KeepPluginsUpToDate(ctxMetadata context.Context, specsSync chan s
 
 	syncReq := make(chan struct{})
 	resp := LoadBuiltins(syncReq)
+	n.syncPlugins(ctxMetadata, syncReq, resp, specsSync)
+}
+
+// syncPlugins synchronizes the plugins with the manager on request of the plugins.sync task, answered with the
+// changes, and every PluginSyncInterval if set, until ctx is done.
+//
+// The periodic sync takes the exclusive lock of the tasks, like plugins.sync, so the plugins are not reloaded while
+// running: it is skipped until the next interval if tasks are running.
+func (n *Node) syncPlugins(ctx context.Context, syncReq <-chan struct{}, resp chan<- types.PluginUpdateResponse, specsSync chan<- struct{}) {
+	var tick <-chan time.Time
+	if n.config.PluginSyncInterval > 0 {
+		ticker := time.NewTicker(n.config.PluginSyncInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
 
 	for {
 		select {
 		case <-syncReq:
-			changes, changed, err := n.updatePlugins(ctxMetadata)
-			ret := types.PluginUpdateResponse{Changes: changes, Error: err}
-			resp <- ret
+			changes, changed, err := n.updatePlugins(ctx)
+			n.reportPluginSync(ctx, changes, changed, err)
+			resp <- types.PluginUpdateResponse{Changes: changes, Error: err}
+			if changed {
+				specsSync <- struct{}{}
+			}
+		case <-tick:
+			if !n.exclusive.TryLock() {
+				slog.Debug("periodic plugin sync skipped: tasks running")
+				continue
+			}
+			changes, changed, err := n.updatePlugins(ctx)
+			n.exclusive.Unlock()
+			n.reportPluginSync(ctx, changes, changed, err)
+			if err != nil {
+				slog.Error("periodic plugin sync failed", "error", err)
+			}
 			if changed {
+				slog.Info("plugins synchronized", "changes", changes)
 				specsSync <- struct{}{}
 			}
-		case <-ctxMetadata.Done():
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// reportPluginSync reports the changes of a plugin sync to the manager, if it changed the plugins or failed.
+//
+// The unchanged plugins are not reported. A manager not supporting the report is ignored.
+func (n *Node) reportPluginSync(ctxMetadata context.Context, changes []types.PluginChanges, changed bool, syncErr error) {
+	if !changed && syncErr == nil {
+		return
+	}
+
+	report := &proto.PluginSync{}
+	if syncErr != nil {
+		report.Error = syncErr.Error()
+	}
+	for _, c := range changes {
+		change := &proto.PluginChange{Name: c.Name, File: c.FileName}
+		switch {
+		case c.New:
+			change.Type = "added"
+		case c.Updated:
+			change.Type = "updated"
+		case c.Deleted:
+			change.Type = "deleted"
+		case c.Rejected:
+			change.Type = "rejected"
+		default:
+			continue
+		}
+		report.Changes = append(report.Changes, change)
+	}
+
+	ctx, cancel := context.WithTimeout(ctxMetadata, config.PluginUpdateTimeout)
+	defer cancel()
+	_, err := n.taskClient.ReportPluginSync(ctx, report)
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		slog.Debug("plugin sync not reported: not supported by the manager")
+	case err != nil:
+		slog.Warn("failed to report the plugin sync to the manager", "error", err)
+	}
+}
+
 func (n *Node) updatePlugins(ctxMetadata context.Context) ([]types.PluginChanges, bool, error) {
 	mu.Lock()
 	defer mu.Unlock()
@@ -91,14 +172,16 @@ func (n *Node) updatePlugins(ctxMetadata context.Context) ([]types.PluginChanges
 	}()
 
 	// if for some reason we failed to resolve the manager address during stream connection, we fallback on the configured manager address
-	managerHost := net.JoinHostPort(n.connectedManagerAddr, n.config.PluginServerPort)
-	if managerHost == "" {
-		managerHost = net.JoinHostPort(n.config.ManagerAddress, n.config.PluginServerPort)
+	managerAddr := n.connectedManagerAddr
+	if managerAddr == "" {
+		managerAddr = n.config.ManagerAddress
 	}
+	managerHost := helper.JoinHostPort(managerAddr, n.config.PluginServerPort)
 
-	upToDate, err1 := n.pluginLoader.DownloadPlugins(nodePlugins, managerHost, n.config.PluginDir, tmpDir)
+	syncDir := n.config.PluginDirs[0]
+	upToDate, err1 := n.pluginLoader.DownloadPlugins(nodePlugins, managerHost, syncDir, tmpDir)
 	slog.Debug("updating plugins")
-	changes, changed, err2 := n.pluginLoader.Update(n.config.PluginDir, tmpDir, upToDate)
+	changes, changed, err2 := n.pluginLoader.Update(syncDir, tmpDir, upToDate)
 	return changes, changed, errors.Join(err1, err2)
 }
 
@@ -0,0 +1,105 @@
+package node
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/plugin/loader/hcplugin"
+	"github.com/jackadi-io/jackadi/internal/plugin/types"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPluginSyncTest returns a node synchronizing its plugins from a manager listing and serving the backdoor
+// plugin, which the node does not allow: the sync goes through the download and the update without running it.
+func newPluginSyncTest(t *testing.T, interval time.Duration) (*Node, *mockClusterClient) {
+	t.Helper()
+
+	content := []byte("backdoor")
+	sum := sha256.Sum256(content)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == config.PluginServerPath+"backdoor" {
+			_, _ = w.Write(content)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	require.NoError(t, err)
+
+	client := &mockClusterClient{
+		plugins:  map[string]string{"backdoor": hex.EncodeToString(sum[:])},
+		reported: make(chan *proto.PluginSync, 10),
+	}
+	loader := hcplugin.New()
+	loader.SetAllowed([]string{"git"})
+	nd := &Node{
+		config: Config{
+			PluginDirs:         []string{t.TempDir()},
+			PluginServerPort:   port,
+			PluginSyncInterval: interval,
+		},
+		taskClient:           client,
+		pluginLoader:         loader,
+		connectedManagerAddr: host,
+		exclusive:            &sync.RWMutex{},
+	}
+	return nd, client
+}
+
+func TestSyncPlugins_OnDemand(t *testing.T) {
+	nd, client := newPluginSyncTest(t, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	syncReq := make(chan struct{})
+	resp := make(chan types.PluginUpdateResponse)
+	go nd.syncPlugins(ctx, syncReq, resp, make(chan struct{}))
+
+	syncReq <- struct{}{}
+	select {
+	case r := <-resp:
+		require.NoError(t, r.Error, "expected a plugin not allowed not to fail the sync")
+		assert.Equal(t, []types.PluginChanges{{Name: "backdoor", FileName: "backdoor", Rejected: true}}, r.Changes)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no response to the sync request")
+	}
+	assert.Equal(t, int32(1), client.listed.Load(), "expected a single sync, none periodic")
+
+	// the sync changed nothing and did not fail, the manager is not notified of each rejection
+	select {
+	case report := <-client.reported:
+		t.Fatalf("unexpected report of the plugin sync: %v", report)
+	default:
+	}
+}
+
+func TestSyncPlugins_Periodic(t *testing.T) {
+	nd, client := newPluginSyncTest(t, 20*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go nd.syncPlugins(ctx, nil, nil, make(chan struct{}))
+
+	require.Eventually(t, func() bool { return client.listed.Load() >= 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// the sync waits for the running tasks
+	nd.exclusive.RLock()
+	time.Sleep(30 * time.Millisecond) // a sync may be in progress
+	listed := client.listed.Load()
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, listed, client.listed.Load(), "expected no sync while a task is running")
+	nd.exclusive.RUnlock()
+
+	require.Eventually(t, func() bool { return client.listed.Load() > listed }, 5*time.Second, 10*time.Millisecond)
+}
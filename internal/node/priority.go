@@ -0,0 +1,135 @@
+package node
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/clock"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+// slots are the slots of the running tasks, given to the waiting tasks by priority.
+//
+// A waiting task is only overtaken by the tasks of higher priority received less than config.PriorityAging
+// after it, per level of difference: the wait of the low priority tasks is bounded.
+type slots struct {
+	mutex   sync.Mutex
+	free    int
+	waiting waitQueue
+	seq     uint64
+	clock   clock.Clock
+}
+
+func newSlots(size int) *slots {
+	return &slots{free: size, clock: clock.Real{}}
+}
+
+// waiter is a task waiting for a slot.
+type waiter struct {
+	rank  time.Time // Arrival time, moved back by config.PriorityAging per level of priority.
+	seq   uint64    // Arrival order of the tasks with the same rank.
+	ready chan struct{}
+	index int // Index in the queue, -1 once given a slot.
+}
+
+// priorityLevel returns the level of a priority, the low priority being the lowest level.
+func priorityLevel(priority proto.Priority) int {
+	switch priority {
+	case proto.Priority_PRIORITY_HIGH:
+		return 2
+	case proto.Priority_PRIORITY_LOW:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// acquire waits for a slot.
+//
+// errLockTimeout is returned if expired fires first, and the error of ctx if it is done first.
+func (s *slots) acquire(ctx context.Context, priority proto.Priority, expired <-chan time.Time) error {
+	s.mutex.Lock()
+	if s.free > 0 && s.waiting.Len() == 0 {
+		s.free--
+		s.mutex.Unlock()
+		return nil
+	}
+	s.seq++
+	w := &waiter{
+		rank:  s.clock.Now().Add(-time.Duration(priorityLevel(priority)) * config.PriorityAging),
+		seq:   s.seq,
+		ready: make(chan struct{}),
+	}
+	heap.Push(&s.waiting, w)
+	s.mutex.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-expired:
+		return s.abandon(w, errLockTimeout)
+	case <-ctx.Done():
+		return s.abandon(w, ctx.Err())
+	}
+}
+
+// abandon removes the waiter from the queue, and returns err. The slot is released if it was given meanwhile.
+func (s *slots) abandon(w *waiter, err error) error {
+	s.mutex.Lock()
+	if w.index >= 0 {
+		heap.Remove(&s.waiting, w.index)
+		s.mutex.Unlock()
+		return err
+	}
+	s.mutex.Unlock()
+	s.release()
+	return err
+}
+
+// release gives the slot to the first waiting task, or frees it.
+func (s *slots) release() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.waiting.Len() == 0 {
+		s.free++
+		return
+	}
+	w, _ := heap.Pop(&s.waiting).(*waiter)
+	close(w.ready)
+}
+
+// waitQueue is a heap of the waiting tasks, the first to get a slot first.
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int { return len(q) }
+
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].rank.Equal(q[j].rank) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].rank.Before(q[j].rank)
+}
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x any) {
+	w, _ := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() any {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	w.index = -1
+	*q = old[:len(old)-1]
+	return w
+}
@@ -0,0 +1,105 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/clock"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queue makes a task of the priority wait for a slot, and sends its name to order once it gets it.
+func queue(t *testing.T, s *slots, name string, priority proto.Priority, order chan<- string) {
+	t.Helper()
+	s.mutex.Lock()
+	waiting := s.waiting.Len()
+	s.mutex.Unlock()
+
+	go func() {
+		if err := s.acquire(context.Background(), priority, nil); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		order <- name
+	}()
+
+	require.Eventually(t, func() bool {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		return s.waiting.Len() == waiting+1
+	}, time.Second, time.Millisecond, "the task must wait")
+}
+
+// serve releases the slot once per waiting task, and returns the order in which they got it.
+func serve(t *testing.T, s *slots, order <-chan string, tasks int) []string {
+	t.Helper()
+	var got []string
+	for range tasks {
+		s.release()
+		select {
+		case name := <-order:
+			got = append(got, name)
+		case <-time.After(time.Second):
+			t.Fatal("no task got the slot")
+		}
+	}
+	return got
+}
+
+func TestSlots_Priority(t *testing.T) {
+	s := newSlots(1)
+	s.clock = clock.NewFake(time.Now())
+	require.NoError(t, s.acquire(context.Background(), proto.Priority_PRIORITY_NORMAL, nil))
+
+	order := make(chan string, 4)
+	queue(t, s, "normal-1", proto.Priority_PRIORITY_NORMAL, order)
+	queue(t, s, "low", proto.Priority_PRIORITY_LOW, order)
+	queue(t, s, "normal-2", proto.Priority_PRIORITY_NORMAL, order)
+	queue(t, s, "high", proto.Priority_PRIORITY_HIGH, order)
+
+	assert.Equal(t, []string{"high", "normal-1", "normal-2", "low"}, serve(t, s, order, 4))
+}
+
+func TestSlots_Aging(t *testing.T) {
+	tests := []struct {
+		name   string
+		waited time.Duration // By the low priority task before the high priority one is received.
+		want   []string
+	}{
+		{name: "overtaken", waited: 2*config.PriorityAging - time.Second, want: []string{"high", "low"}},
+		{name: "aged", waited: 2*config.PriorityAging + time.Second, want: []string{"low", "high"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := clock.NewFake(time.Now())
+			s := newSlots(1)
+			s.clock = fake
+			require.NoError(t, s.acquire(context.Background(), proto.Priority_PRIORITY_NORMAL, nil))
+
+			order := make(chan string, 2)
+			queue(t, s, "low", proto.Priority_PRIORITY_LOW, order)
+			fake.Advance(tt.waited)
+			queue(t, s, "high", proto.Priority_PRIORITY_HIGH, order)
+
+			assert.Equal(t, tt.want, serve(t, s, order, 2))
+		})
+	}
+}
+
+func TestSlots_Abandon(t *testing.T) {
+	s := newSlots(1)
+	require.NoError(t, s.acquire(context.Background(), proto.Priority_PRIORITY_NORMAL, nil))
+
+	err := s.acquire(context.Background(), proto.Priority_PRIORITY_HIGH, time.After(10*time.Millisecond))
+	require.ErrorIs(t, err, errLockTimeout)
+	assert.Equal(t, 0, s.waiting.Len(), "the expired task must leave the queue")
+
+	s.release()
+	require.NoError(t, s.acquire(context.Background(), proto.Priority_PRIORITY_LOW, nil), "the slot must be free")
+	assert.Equal(t, 0, s.free)
+}
@@ -0,0 +1,176 @@
+package node
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+// runningTasks are the tasks executed by the node, the long-running ones are reported to the manager in the
+// heartbeat responses: the manager does not track them anymore once it stopped waiting for their response.
+//
+// It also tracks the accepted requests for the draining of the node at shutdown. A nil runningTasks tracks nothing.
+type runningTasks struct {
+	mutex    sync.Mutex
+	tasks    map[int64]runningTask // key=request ID
+	accepted int                   // Requests accepted and not answered yet, waiting for their lock or running.
+	draining bool                  // The new requests are refused.
+	idle     chan struct{}         // Closed once draining without accepted request.
+	killed   context.Context       // Cancelled when the tasks still running at the end of the draining are killed.
+	kill     context.CancelFunc
+}
+
+type runningTask struct {
+	groupID *int64
+	task    string
+	start   time.Time
+}
+
+func newRunningTasks() *runningTasks {
+	killed, kill := context.WithCancel(context.Background())
+	return &runningTasks{
+		tasks:  make(map[int64]runningTask),
+		idle:   make(chan struct{}),
+		killed: killed,
+		kill:   kill,
+	}
+}
+
+// accept records a new request, it returns false if the node is draining: the request must be refused.
+func (r *runningTasks) accept() bool {
+	if r == nil {
+		return true
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.draining {
+		return false
+	}
+	r.accepted++
+	return true
+}
+
+// release records the end of an accepted request, answered or not.
+func (r *runningTasks) release() {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.accepted--
+	r.closeIdle()
+}
+
+// drain refuses the new requests, it returns a channel closed once all the accepted requests are released.
+func (r *runningTasks) drain() <-chan struct{} {
+	if r == nil {
+		idle := make(chan struct{})
+		close(idle)
+		return idle
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.draining = true
+	r.closeIdle()
+	return r.idle
+}
+
+// closeIdle closes the idle channel if draining without accepted request. The mutex must be held.
+func (r *runningTasks) closeIdle() {
+	if !r.draining || r.accepted > 0 {
+		return
+	}
+	select {
+	case <-r.idle:
+	default:
+		close(r.idle)
+	}
+}
+
+// killAll cancels the contexts of the tasks, see killable.
+func (r *runningTasks) killAll() {
+	if r != nil {
+		r.kill()
+	}
+}
+
+// isKilled reports whether the tasks were killed.
+func (r *runningTasks) isKilled() bool {
+	return r != nil && r.killed.Err() != nil
+}
+
+// killable returns a copy of ctx cancelled when the tasks are killed.
+func (r *runningTasks) killable(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r == nil {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(r.killed, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// start records the start of the execution of a request.
+func (r *runningTasks) start(req *proto.TaskRequest) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.tasks[req.GetId()] = runningTask{groupID: req.GroupID, task: req.FullTask(), start: time.Now()}
+}
+
+// done records the end of the execution of a request.
+func (r *runningTasks) done(id int64) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.tasks, id)
+}
+
+// reported returns the tasks reported to the manager in the heartbeat responses: the long-running ones, or all of
+// them while draining so the manager knows what the shutdown of the node is waiting for.
+func (r *runningTasks) reported() []*proto.RunningTask {
+	if r == nil {
+		return nil
+	}
+	r.mutex.Lock()
+	draining := r.draining
+	r.mutex.Unlock()
+
+	if draining {
+		return r.longerThan(0)
+	}
+	return r.longerThan(config.LongRunningTask)
+}
+
+// longerThan returns the tasks running for longer than d.
+func (r *runningTasks) longerThan(d time.Duration) []*proto.RunningTask {
+	if r == nil {
+		return nil
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var tasks []*proto.RunningTask
+	for id, t := range r.tasks {
+		elapsed := time.Since(t.start)
+		if elapsed < d {
+			continue
+		}
+		tasks = append(tasks, &proto.RunningTask{
+			Id:      id,
+			GroupID: t.groupID,
+			Task:    t.task,
+			Elapsed: elapsed.Milliseconds(),
+		})
+	}
+	return tasks
+}
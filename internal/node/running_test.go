@@ -0,0 +1,67 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunningTasks(t *testing.T) {
+	groupID := int64(42)
+	running := newRunningTasks()
+	running.start(&proto.TaskRequest{Id: 1, GroupID: &groupID, Plugin: "cmd", Task: "run"})
+	running.start(&proto.TaskRequest{Id: 2, Plugin: "pkg", Task: "install"})
+
+	assert.Empty(t, running.longerThan(time.Hour))
+
+	tasks := running.longerThan(0)
+	require.Len(t, tasks, 2)
+
+	running.done(2)
+	tasks = running.longerThan(0)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, int64(1), tasks[0].GetId())
+	assert.Equal(t, groupID, tasks[0].GetGroupID())
+	assert.Equal(t, "cmd.run", tasks[0].GetTask())
+
+	var none *runningTasks
+	none.start(&proto.TaskRequest{Id: 3})
+	assert.Empty(t, none.longerThan(0))
+}
+
+func TestRunningTasks_Drain(t *testing.T) {
+	running := newRunningTasks()
+	require.True(t, running.accept())
+	require.True(t, running.accept())
+
+	idle := running.drain()
+	assert.False(t, running.accept(), "new requests are refused while draining")
+
+	running.release()
+	select {
+	case <-idle:
+		t.Fatal("idle while a request is still accepted")
+	default:
+	}
+	running.release()
+	select {
+	case <-idle:
+	default:
+		t.Fatal("not idle once all the requests are released")
+	}
+
+	ctx, cancel := running.killable(context.Background())
+	defer cancel()
+	assert.False(t, running.isKilled())
+	running.killAll()
+	assert.True(t, running.isKilled())
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the task context was not cancelled")
+	}
+}
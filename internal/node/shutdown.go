@@ -0,0 +1,54 @@
+package node
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/proto"
+)
+
+var errKilled = errors.New("task killed by the shutdown of the node")
+
+// Shutdown drains the node before its connection to the manager is closed: the new requests are refused, while the
+// accepted ones are given until the timeout to finish. The tasks still running are reported to the manager in the
+// heartbeat responses meanwhile.
+//
+// At the timeout, the remaining tasks are killed with the plugins and answered as such. They are returned.
+func (n *Node) Shutdown(timeout time.Duration) []*proto.RunningTask {
+	idle := n.running.drain()
+	for _, t := range n.running.longerThan(0) {
+		slog.Info("waiting for task", "request_id", t.GetId(), "task", t.GetTask(), "elapsed", elapsed(t))
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	select {
+	case <-idle:
+		return nil
+	case <-deadline.C:
+	}
+
+	cutOff := n.running.longerThan(0)
+	for _, t := range cutOff {
+		slog.Warn("task cut off by the shutdown", "request_id", t.GetId(), "task", t.GetTask(), "elapsed", elapsed(t))
+	}
+	n.running.killAll()
+	if n.executor == nil {
+		n.KillPlugins()
+	}
+
+	// the responses of the killed tasks are sent before the connection is closed
+	select {
+	case <-idle:
+	case <-time.After(config.KilledTasksGrace):
+		slog.Warn("the killed tasks did not answer in time")
+	}
+	return cutOff
+}
+
+// elapsed returns the execution time of a running task.
+func elapsed(t *proto.RunningTask) time.Duration {
+	return time.Duration(t.GetElapsed()) * time.Millisecond
+}
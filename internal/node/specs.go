@@ -27,7 +27,7 @@ func NewSpecsManager() (*SpecsManager, error) {
 	c.MustRegisterTask("all", s.All)
 	c.MustRegisterTask("get", s.Get)
 
-	if err := inventory.Registry.Register(c); err != nil {
+	if err := inventory.Registry.RegisterBuiltin(c); err != nil {
 		return nil, fmt.Errorf("cannot register as a plugin: %w", err)
 	}
 
@@ -70,37 +70,13 @@ func (s *SpecsManager) StartSpecCollector(ctx context.Context, syncReq chan stru
 	t := time.NewTicker(config.SpecCollectionInterval)
 	for {
 		slog.Debug("collecting specs")
-		plugins := inventory.Registry.Names()
-		newSpecs := make(map[string]any)
-
-		for _, name := range plugins {
-			c, err := inventory.Registry.Get(name)
-			if err != nil {
-				slog.Error("failed to get specs tasks", "plugin", name, "error", err)
-				continue
-			}
-			out, err := c.CollectSpecs(ctx)
-			if err != nil {
-				slog.Error("failed to fetch specs", "plugin", name, "error", err)
-				continue
-			}
-
-			var specs map[string]any
-			if err := serializer.JSON.Unmarshal(out, &specs); err != nil {
-				slog.Error("failed to unmarshal specs", "plugin", name, "error", err)
-				continue
-			}
-
-			if len(specs) > 0 {
-				newSpecs[name] = specs
-			}
-		}
+		newSpecs := collectSpecs(ctx)
 
 		s.mutex.Lock()
 		s.specs = newSpecs
 		s.mutex.Unlock()
 
-		slog.Debug("specs collected", "count", len(s.specs))
+		slog.Debug("specs collected", "count", len(newSpecs))
 		select {
 		case <-t.C:
 		case <-syncReq:
@@ -109,3 +85,55 @@ func (s *SpecsManager) StartSpecCollector(ctx context.Context, syncReq chan stru
 		}
 	}
 }
+
+// collectSpecs collects the specs of all the plugins concurrently, each one within config.SpecCollectionTimeout.
+//
+// The specs of a failed plugin are replaced by an error marker ({"_error": "..."}), unless it returned the specs
+// of its successful collectors.
+func collectSpecs(ctx context.Context) map[string]any {
+	plugins := inventory.Registry.Names()
+	newSpecs := make(map[string]any)
+	mutex := sync.Mutex{}
+
+	wg := sync.WaitGroup{}
+	for _, name := range plugins {
+		wg.Go(func() {
+			specs := collectPluginSpecs(ctx, name)
+			if len(specs) == 0 {
+				return
+			}
+			mutex.Lock()
+			newSpecs[name] = specs
+			mutex.Unlock()
+		})
+	}
+	wg.Wait()
+
+	return newSpecs
+}
+
+func collectPluginSpecs(ctx context.Context, name string) map[string]any {
+	c, err := inventory.Registry.Get(name)
+	if err != nil {
+		slog.Error("failed to get specs tasks", "plugin", name, "error", err)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.SpecCollectionTimeout)
+	defer cancel()
+	out, collectErr := c.CollectSpecs(ctx)
+	if collectErr != nil {
+		slog.Error("failed to fetch specs", "plugin", name, "error", collectErr)
+	}
+
+	var specs map[string]any
+	if err := serializer.JSON.Unmarshal(out, &specs); err != nil {
+		if collectErr != nil {
+			return map[string]any{config.SpecErrorKey: collectErr.Error()}
+		}
+		slog.Error("failed to unmarshal specs", "plugin", name, "error", err)
+		return nil
+	}
+
+	return specs
+}
@@ -0,0 +1,48 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/plugin/inventory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectSpecs(t *testing.T) {
+	plugins := []*mockPlugin{
+		{name: "os", specsFunc: func(ctx context.Context) ([]byte, error) {
+			return []byte(`{"release": {"family": "debian"}}`), nil
+		}},
+		{name: "network", specsFunc: func(ctx context.Context) ([]byte, error) {
+			return nil, errors.New("plugin unreachable")
+		}},
+		{name: "software", specsFunc: func(ctx context.Context) ([]byte, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}},
+		{name: "disks", specsFunc: func(ctx context.Context) ([]byte, error) {
+			// partial specs, with the marker of the failed collector
+			return []byte(`{"sda": {"model": "ssd"}, "nvme": {"_error": "timeout"}}`), errors.New("nvme: timeout")
+		}},
+	}
+	for _, p := range plugins {
+		require.NoError(t, inventory.Registry.Register(p))
+		defer func() { _ = inventory.Registry.Unregister(p.name) }()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	specs := collectSpecs(ctx)
+
+	assert.Equal(t, map[string]any{"release": map[string]any{"family": "debian"}}, specs["os"])
+	assert.Equal(t, map[string]any{config.SpecErrorKey: "plugin unreachable"}, specs["network"])
+	assert.Equal(t, map[string]any{config.SpecErrorKey: context.DeadlineExceeded.Error()}, specs["software"])
+	assert.Equal(t, map[string]any{
+		"sda":  map[string]any{"model": "ssd"},
+		"nvme": map[string]any{config.SpecErrorKey: "timeout"},
+	}, specs["disks"])
+}
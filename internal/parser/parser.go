@@ -21,15 +21,21 @@ func NewArguments() Arguments {
 	return args
 }
 
+var keyValueRegexp = regexp.MustCompile("^(?P<key>[a-zA-Z0-9_-]+)=(?P<value>.+)$")
+
+// IsKeyValue reports whether the argument is an option: key=value or key="value".
+func IsKeyValue(arg string) bool {
+	return keyValueRegexp.MatchString(arg)
+}
+
 // ParseArgs extract positional args and optional args from a list of arguments.
 //
 // Key value are following the pattern: key=value or key="value".
 func ParseArgs(args []string) (Arguments, error) {
 	a := NewArguments()
-	re := regexp.MustCompile("^(?P<key>[a-zA-Z0-9_-]+)=(?P<value>.+)$")
 
 	for _, arg := range args {
-		groups := re.FindAllStringSubmatch(arg, -1)
+		groups := keyValueRegexp.FindAllStringSubmatch(arg, -1)
 		if groups == nil {
 			if len(a.Options) > 0 {
 				return Arguments{}, errors.New("positional arguments cannot be after key values")
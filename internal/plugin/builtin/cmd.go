@@ -33,7 +33,7 @@ func MustLoadCmd() {
 		WithDescription("The executed command is not canceled when the node is closed.\nIt leverages exec.Command.").
 		WithArg("cmd", "string", "ls -l")
 
-	if err := inventory.Registry.Register(cmd); err != nil {
+	if err := inventory.Registry.RegisterBuiltin(cmd); err != nil {
 		name, _ := cmd.Name()
 		slog.Error("could not load builtin task", "error", err, "task", name)
 		log.Fatal(err)
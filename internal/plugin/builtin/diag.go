@@ -0,0 +1,74 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/plugin/inventory"
+	"github.com/jackadi-io/jackadi/sdk"
+)
+
+type FailOptions struct {
+	Retcode int32  `jackadi:"retcode"`
+	Error   string `jackadi:"error"`
+}
+
+func (o *FailOptions) SetDefaults() {
+	o.Retcode = 1
+	o.Error = "failure requested"
+}
+
+func echo(message string) (string, error) {
+	return message, nil
+}
+
+func sleep(ctx context.Context, progress sdk.Progress, seconds int) (int, error) {
+	if seconds < 0 {
+		return 0, errors.New("the duration must be positive")
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for elapsed := 0; elapsed < seconds; {
+		select {
+		case <-ctx.Done():
+			return elapsed, ctx.Err()
+		case <-ticker.C:
+			elapsed++
+			progress.Report(elapsed * 100 / seconds)
+		}
+	}
+	return seconds, nil
+}
+
+func fail(options *FailOptions) (any, error) {
+	return nil, sdk.WithRetcode(errors.New(options.Error), options.Retcode)
+}
+
+func MustLoadDiag() {
+	diag := sdk.New("diag")
+
+	diag.MustRegisterTask("echo", echo).
+		WithSummary("Return the message.").
+		WithDescription("Diagnostic task to check the connectivity and the targeting.").
+		WithArg("message", "string", "hello")
+
+	diag.MustRegisterTask("sleep", sleep).
+		WithSummary("Sleep for the given number of seconds.").
+		WithDescription("Diagnostic task to test timeouts and lock modes, the progress is reported every second.").
+		WithArg("seconds", "int", "10")
+
+	diag.MustRegisterTask("fail", fail).
+		WithSummary("Fail with the chosen retcode and error.").
+		WithDescription("Diagnostic task to test the error handling.\nOptions: retcode (default: 1), error (default: \"failure requested\").")
+
+	if err := inventory.Registry.RegisterBuiltin(diag); err != nil {
+		name, _ := diag.Name()
+		slog.Error("could not load builtin task", "error", err, "task", name)
+		log.Fatal(err)
+	}
+}
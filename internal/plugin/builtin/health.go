@@ -25,7 +25,7 @@ func MustLoadHealth() {
 		WithSummary("Ping.").
 		WithDescription("Normal healthcheck using the task queue.")
 
-	if err := inventory.Registry.Register(cmd); err != nil {
+	if err := inventory.Registry.RegisterBuiltin(cmd); err != nil {
 		name, _ := cmd.Name()
 		slog.Error("could not load builtin task", "error", err, "task", name)
 		log.Fatal(err)
@@ -0,0 +1,45 @@
+package builtin
+
+import (
+	"errors"
+	"log"
+	"log/slog"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/logs"
+	"github.com/jackadi-io/jackadi/internal/plugin/inventory"
+	"github.com/jackadi-io/jackadi/sdk"
+)
+
+type TailOptions struct {
+	Lines int    `jackadi:"lines"`
+	Since uint64 `jackadi:"since"`
+}
+
+func (o *TailOptions) SetDefaults() {
+	o.Lines = config.LogTailLines
+}
+
+// tail returns the recent lines logged by the node, after the since sequence number.
+func tail(options *TailOptions) ([]logs.Line, error) {
+	if options.Lines < 0 {
+		return nil, errors.New("the number of lines must be positive")
+	}
+	return logs.Recent.Since(options.Since, options.Lines), nil
+}
+
+func MustLoadLogs() {
+	l := sdk.New("logs")
+
+	l.MustRegisterTask("tail", tail).
+		WithSummary("Return the recent lines logged by the node.").
+		WithDescription("The last lines logged are kept in memory, in text format, whatever the log format of the node.\n" +
+			"Options: lines (default: 100, 0 for all the lines kept), since (sequence number of the last line already fetched).").
+		WithLockMode(sdk.NoLock)
+
+	if err := inventory.Registry.RegisterBuiltin(l); err != nil {
+		name, _ := l.Name()
+		slog.Error("could not load builtin task", "error", err, "task", name)
+		log.Fatal(err)
+	}
+}
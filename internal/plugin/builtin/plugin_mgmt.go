@@ -87,6 +87,7 @@ type diff struct {
 	Unchanged []pluginInfo `jackadi:"Unchanged,omitempty"`
 	Deleted   []pluginInfo `jackadi:"Deleted,omitempty"`
 	Updated   []pluginInfo `jackadi:"Updated,omitempty"`
+	Rejected  []pluginInfo `jackadi:"Rejected,omitempty"`
 }
 
 func (s pluginMgmt) sync() (*diff, error) {
@@ -107,12 +108,14 @@ func (s pluginMgmt) sync() (*diff, error) {
 			out.Updated = append(out.Updated, info)
 		case p.Deleted:
 			out.Deleted = append(out.Deleted, info)
+		case p.Rejected:
+			out.Rejected = append(out.Rejected, info)
 		default:
 			out.Unchanged = append(out.Unchanged, info)
 		}
 	}
 
-	return &out, nil
+	return &out, changes.Error
 }
 
 func MustLoadPluginMgmt(req chan struct{}) chan types.PluginUpdateResponse {
@@ -133,10 +136,10 @@ func MustLoadPluginMgmt(req chan struct{}) chan types.PluginUpdateResponse {
 		WithArg("name", "plugin", "cmd")
 	c.MustRegisterTask("sync", plugingMgmt.sync).
 		WithSummary("Sync plugin with the manager.").
-		WithDescription("The node sync its plugins with the manager.\nIt adds, updates and removes the plugins following the manager configuration.").
+		WithDescription("The node sync its plugins with the manager.\nIt adds, updates and removes the plugins following the manager configuration.\nThe plugins not allowed by the node (plugins.allowed) are reported as rejected.").
 		WithLockMode(sdk.ExclusiveLock)
 
-	if err := inventory.Registry.Register(c); err != nil {
+	if err := inventory.Registry.RegisterBuiltin(c); err != nil {
 		name, _ := c.Name()
 		slog.Error("could not load builtin task", "error", err, "task", name)
 		log.Fatal(err)
@@ -3,7 +3,6 @@ package core
 import (
 	"fmt"
 	"reflect"
-	"strconv"
 
 	"github.com/jackadi-io/jackadi/internal/serializer"
 	"github.com/spf13/cast"
@@ -12,8 +11,11 @@ import (
 func StructpbValueToInput(value any, targetType reflect.Type) (any, error) {
 	val := reflect.ValueOf(value)
 
-	// Handle pointers
+	// Handle pointers: null is a nil pointer, to distinguish an argument not provided from a zero value
 	if targetType.Kind() == reflect.Pointer {
+		if value == nil {
+			return reflect.Zero(targetType).Interface(), nil
+		}
 		elemType := targetType.Elem()
 		converted, err := StructpbValueToInput(value, elemType)
 		if err != nil {
@@ -54,20 +56,20 @@ func StructpbValueToInput(value any, targetType reflect.Type) (any, error) {
 	case reflect.String:
 		return cast.ToStringE(value)
 	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
-		// first mashalled to JSON then unquoted
-		// then unmarshal to the expected struct in the plugin definition
+		// the value is either a JSON string (e.g. key='{"a": 1}' from the CLI), or already decoded
+		// (e.g. a nested option), which is marshalled back to JSON.
+		// Then it is unmarshalled to the expected type of the plugin definition.
 		out := reflect.New(targetType).Interface() // we cannot do Elem() here because it would create a map[string]any
-		js, err := serializer.JSON.Marshal(value)
-		if err != nil {
-			return nil, fmt.Errorf("unable to marshal argument %v to %v", value, targetType)
-		}
-
-		unquoted, err := strconv.Unquote(string(js)) // TODO: is it needed???
-		if err != nil {
-			return nil, fmt.Errorf("unable to unquote argument %v", value)
+		data, isString := value.(string)
+		if !isString {
+			js, err := serializer.JSON.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("unable to marshal argument %v to %v", value, targetType)
+			}
+			data = string(js)
 		}
 
-		if err := serializer.JSON.Unmarshal([]byte(unquoted), &out); err != nil {
+		if err := serializer.JSONInput.Unmarshal([]byte(data), &out); err != nil {
 			return nil, fmt.Errorf("unable to unmarshal argument %v to %v", value, targetType)
 		}
 
@@ -48,3 +48,56 @@ func TestConvertToType(t *testing.T) {
 		}
 	}
 }
+
+type taggedUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"user_name,omitempty"`
+	Email    string `jackadi:"mail" json:"email"`
+	Password string `json:"-"`
+	Team     string
+}
+
+func TestConvertToStruct(t *testing.T) {
+	tests := map[string]any{
+		"JSON string":   `{"id": 42, "user_name": "alice", "mail": "alice@jackadi.io", "Password": "secret", "team": "ops"}`,
+		"decoded value": map[string]any{"id": 42, "user_name": "alice", "mail": "alice@jackadi.io", "Password": "secret", "team": "ops"},
+	}
+
+	want := taggedUser{ID: 42, Username: "alice", Email: "alice@jackadi.io", Team: "ops"}
+	for name, value := range tests {
+		result, err := StructpbValueToInput(value, reflect.TypeFor[taggedUser]())
+		if err != nil {
+			t.Fatalf("[%s], unexpected error: %s", name, err)
+		}
+		if result != want {
+			t.Errorf("[%s], conversion failed, got: %+v, want: %+v", name, result, want)
+		}
+	}
+
+	// the jackadi tag takes precedence over the json tag
+	result, err := StructpbValueToInput(`{"email": "alice@jackadi.io"}`, reflect.TypeFor[taggedUser]())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result != (taggedUser{}) {
+		t.Errorf("expected the json tag to be ignored when a jackadi tag is set, got: %+v", result)
+	}
+}
+
+func TestConvertToPointer(t *testing.T) {
+	result, err := StructpbValueToInput(nil, reflect.TypeFor[*taggedUser]())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if user, ok := result.(*taggedUser); !ok || user != nil {
+		t.Errorf("expected a nil *taggedUser, got: %#v", result)
+	}
+
+	result, err = StructpbValueToInput("{}", reflect.TypeFor[*taggedUser]())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if user, ok := result.(*taggedUser); !ok || user == nil {
+		t.Errorf("expected a non-nil *taggedUser, got: %#v", result)
+	}
+}
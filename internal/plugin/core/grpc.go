@@ -2,9 +2,16 @@ package core
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
 
 	"github.com/jackadi-io/jackadi/internal/plugin/core/protoplugin"
 	"github.com/jackadi-io/jackadi/internal/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	empty "google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -40,14 +47,24 @@ func (c *GRPCClient) Version() (Version, error) {
 	}, nil
 }
 
+// Do executes the task. The progress updates are forwarded if the context carries a ProgressFunc.
 func (c *GRPCClient) Do(ctx context.Context, task string, input *proto.Input) (Response, error) {
-	result, err := c.client.Do(ctx, &protoplugin.DoRequest{
+	req := &protoplugin.DoRequest{
 		Task:  task,
 		Input: input,
-	})
+	}
 
+	if progress, ok := ProgressFromContext(ctx); ok {
+		resp, err := c.doWithProgress(ctx, req, progress)
+		if status.Code(err) != codes.Unimplemented {
+			return resp, err
+		}
+		// the plugin is built with an SDK which does not support progress updates
+	}
+
+	result, err := c.client.Do(ctx, req)
 	if err != nil {
-		return Response{}, err
+		return Response{}, fromStatus(err)
 	}
 
 	return Response{
@@ -57,17 +74,64 @@ func (c *GRPCClient) Do(ctx context.Context, task string, input *proto.Input) (R
 	}, nil
 }
 
+// doWithProgress executes the task and forwards its progress updates until the final response.
+func (c *GRPCClient) doWithProgress(ctx context.Context, req *protoplugin.DoRequest, progress ProgressFunc) (Response, error) {
+	stream, err := c.client.DoWithProgress(ctx, req)
+	if err != nil {
+		return Response{}, err
+	}
+
+	for {
+		result, err := stream.Recv()
+		if err != nil {
+			return Response{}, fromStatus(err)
+		}
+
+		if result.Progress != nil {
+			progress(result.GetProgress())
+			continue
+		}
+
+		return Response{
+			Output:  result.Output,
+			Error:   result.Error,
+			Retcode: result.Retcode,
+		}, nil
+	}
+}
+
+// CollectSpecs returns the specs collected by the plugin. The failures of some collectors are returned with the
+// specs of the others.
 func (c *GRPCClient) CollectSpecs(ctx context.Context) ([]byte, error) {
 	r, err := c.client.CollectSpecs(ctx, nil)
-	return r.GetOutput(), err
+	if err != nil {
+		return nil, err
+	}
+	if r.GetError() != "" {
+		return r.GetOutput(), errors.New(r.GetError())
+	}
+	return r.GetOutput(), nil
 }
 
-func (c *GRPCClient) GetTaskLockMode(task string) (proto.LockMode, error) {
+func (c *GRPCClient) GetTaskLockMode(task string) (TaskLockMode, error) {
 	result, err := c.client.GetTaskLockMode(context.Background(), &protoplugin.TaskLockModeRequest{Task: task})
 	if err != nil {
-		return proto.LockMode_NO_LOCK, err
+		return TaskLockMode{Mode: proto.LockMode_NO_LOCK}, err
+	}
+	return TaskLockMode{Mode: result.GetLockMode(), Minimum: result.GetMinimum()}, nil
+}
+
+// GetTaskCacheTTL returns the cache TTL of the task, 0 if the plugin is built with an SDK which does not support
+// the caching.
+func (c *GRPCClient) GetTaskCacheTTL(task string) (time.Duration, error) {
+	result, err := c.client.GetTaskCacheTTL(context.Background(), &protoplugin.TaskCacheTTLRequest{Task: task})
+	if status.Code(err) == codes.Unimplemented {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
 	}
-	return result.GetLockMode(), nil
+	return result.GetTtl().AsDuration(), nil
 }
 
 type GRPCServer struct {
@@ -86,9 +150,41 @@ func (s *GRPCServer) Do(ctx context.Context, req *protoplugin.DoRequest) (*proto
 	return &resp, err
 }
 
+func (s *GRPCServer) DoWithProgress(req *protoplugin.DoRequest, stream grpc.ServerStreamingServer[protoplugin.DoResponse]) error {
+	// the task may report its progress from several goroutines, but a stream does not support concurrent sends
+	mu := sync.Mutex{}
+	ctx := WithProgress(stream.Context(), func(percent int32) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = stream.Send(&protoplugin.DoResponse{Progress: &percent})
+	})
+
+	result, err := s.Impl.Do(ctx, req.GetTask(), req.GetInput())
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return stream.Send(&protoplugin.DoResponse{
+		Output:  result.Output,
+		Error:   result.Error,
+		Retcode: result.Retcode,
+	})
+}
+
+// CollectSpecs returns the failures of the collectors in the response, gRPC dropping the response of a failed call
+// with the specs of the other collectors.
 func (s *GRPCServer) CollectSpecs(ctx context.Context, req *empty.Empty) (*protoplugin.CollectSpecsResponse, error) {
 	result, err := s.Impl.CollectSpecs(ctx)
-	return &protoplugin.CollectSpecsResponse{Output: result}, err
+	if err != nil && result == nil {
+		return nil, err
+	}
+	resp := &protoplugin.CollectSpecsResponse{Output: result}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp, nil
 }
 
 func (s *GRPCServer) Name(ctx context.Context, req *empty.Empty) (*protoplugin.NameResponse, error) {
@@ -127,5 +223,13 @@ func (s *GRPCServer) GetTaskLockMode(ctx context.Context, req *protoplugin.TaskL
 	if err != nil {
 		return &protoplugin.TaskLockModeResponse{LockMode: proto.LockMode_NO_LOCK}, err
 	}
-	return &protoplugin.TaskLockModeResponse{LockMode: lockMode}, nil
+	return &protoplugin.TaskLockModeResponse{LockMode: lockMode.Mode, Minimum: lockMode.Minimum}, nil
+}
+
+func (s *GRPCServer) GetTaskCacheTTL(ctx context.Context, req *protoplugin.TaskCacheTTLRequest) (*protoplugin.TaskCacheTTLResponse, error) {
+	ttl, err := s.Impl.GetTaskCacheTTL(req.GetTask())
+	if err != nil {
+		return &protoplugin.TaskCacheTTLResponse{}, err
+	}
+	return &protoplugin.TaskCacheTTLResponse{Ttl: durationpb.New(ttl)}, nil
 }
@@ -0,0 +1,234 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/plugin/core/protoplugin"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// progressPlugin reports several progress updates before returning its result.
+type progressPlugin struct {
+	Plugin
+}
+
+func (progressPlugin) Do(ctx context.Context, task string, input *proto.Input) (Response, error) {
+	if progress, ok := ProgressFromContext(ctx); ok {
+		for _, percent := range []int32{10, 50, 90} {
+			progress(percent)
+		}
+	}
+	return Response{Output: []byte(`"done"`)}, nil
+}
+
+// failingPlugin returns the output produced before its failure, along with its error.
+type failingPlugin struct {
+	Plugin
+}
+
+func (failingPlugin) Do(ctx context.Context, task string, input *proto.Input) (Response, error) {
+	return Response{Output: []byte(`{"done":["pull","build"]}`), Error: "step migrate failed", Retcode: 4}, nil
+}
+
+// panicPlugin is a plugin whose task panicked.
+type panicPlugin struct {
+	Plugin
+}
+
+func (panicPlugin) Do(ctx context.Context, task string, input *proto.Input) (Response, error) {
+	return Response{}, &PanicError{Value: "runtime error: index out of range [3] with length 3", Stack: "goroutine 7 [running]:\nmain.task()"}
+}
+
+// lockedPlugin is a plugin whose tasks require at least a write lock, and are cached 30s.
+type lockedPlugin struct {
+	Plugin
+}
+
+func (lockedPlugin) GetTaskLockMode(task string) (TaskLockMode, error) {
+	return TaskLockMode{Mode: proto.LockMode_WRITE, Minimum: true}, nil
+}
+
+func (lockedPlugin) GetTaskCacheTTL(task string) (time.Duration, error) {
+	return 30 * time.Second, nil
+}
+
+// partialSpecsPlugin is a plugin whose os collector succeeded and whose network collector failed.
+type partialSpecsPlugin struct {
+	Plugin
+}
+
+func (partialSpecsPlugin) CollectSpecs(ctx context.Context) ([]byte, error) {
+	return []byte(`{"os":{"family":"linux"},"network":{"_error":"timeout"}}`), errors.New("network: timeout")
+}
+
+// legacyServer is a plugin built with an SDK which does not support progress updates.
+type legacyServer struct {
+	protoplugin.UnimplementedJackadiPluginServer
+}
+
+func (legacyServer) Do(ctx context.Context, req *protoplugin.DoRequest) (*protoplugin.DoResponse, error) {
+	return &protoplugin.DoResponse{Output: []byte(`"legacy"`)}, nil
+}
+
+func newTestClient(t *testing.T, srv protoplugin.JackadiPluginServer) *GRPCClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	protoplugin.RegisterJackadiPluginServer(s, srv)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("unable to connect: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &GRPCClient{client: protoplugin.NewJackadiPluginClient(conn)}
+}
+
+func TestGRPCDoProgress(t *testing.T) {
+	client := newTestClient(t, &GRPCServer{Impl: progressPlugin{}})
+
+	var updates []int32
+	ctx := WithProgress(context.Background(), func(percent int32) {
+		updates = append(updates, percent)
+	})
+
+	resp, err := client.Do(ctx, "task", &proto.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int32{10, 50, 90}; !reflect.DeepEqual(updates, want) {
+		t.Errorf("expected progress updates %v, got %v", want, updates)
+	}
+	if string(resp.Output) != `"done"` {
+		t.Errorf("unexpected output: %s", resp.Output)
+	}
+
+	resp, err = client.Do(context.Background(), "task", &proto.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error without progress: %v", err)
+	}
+	if string(resp.Output) != `"done"` {
+		t.Errorf("unexpected output without progress: %s", resp.Output)
+	}
+}
+
+func TestGRPCDoProgressUnsupported(t *testing.T) {
+	client := newTestClient(t, legacyServer{})
+
+	ctx := WithProgress(context.Background(), func(percent int32) {
+		t.Errorf("unexpected progress update: %d", percent)
+	})
+
+	resp, err := client.Do(ctx, "task", &proto.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(resp.Output) != `"legacy"` {
+		t.Errorf("unexpected output: %s", resp.Output)
+	}
+}
+
+func TestGRPCDoPanic(t *testing.T) {
+	client := newTestClient(t, &GRPCServer{Impl: panicPlugin{}})
+
+	for name, ctx := range map[string]context.Context{
+		"unary":         context.Background(),
+		"with progress": WithProgress(context.Background(), func(int32) {}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.Do(ctx, "task", &proto.Input{})
+			panicErr, ok := IsPanic(err)
+			if !ok {
+				t.Fatalf("expected a panic error, got: %v", err)
+			}
+			if panicErr.Value != "runtime error: index out of range [3] with length 3" {
+				t.Errorf("unexpected recovered value: %s", panicErr.Value)
+			}
+			if panicErr.Stack != "goroutine 7 [running]:\nmain.task()" {
+				t.Errorf("unexpected stack trace: %s", panicErr.Stack)
+			}
+		})
+	}
+}
+
+func TestGRPCDoPartialOutput(t *testing.T) {
+	client := newTestClient(t, &GRPCServer{Impl: failingPlugin{}})
+
+	for name, ctx := range map[string]context.Context{
+		"unary":         context.Background(),
+		"with progress": WithProgress(context.Background(), func(int32) {}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := client.Do(ctx, "task", &proto.Input{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(resp.Output) != `{"done":["pull","build"]}` {
+				t.Errorf("expected the partial output, got: %s", resp.Output)
+			}
+			if resp.Error != "step migrate failed" || resp.Retcode != 4 {
+				t.Errorf("expected the error of the task, got: %q (retcode %d)", resp.Error, resp.Retcode)
+			}
+		})
+	}
+}
+
+func TestGRPCGetTaskLockMode(t *testing.T) {
+	client := newTestClient(t, &GRPCServer{Impl: lockedPlugin{}})
+
+	lockMode, err := client.GetTaskLockMode("configure")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (TaskLockMode{Mode: proto.LockMode_WRITE, Minimum: true}); lockMode != want {
+		t.Errorf("expected %+v, got %+v", want, lockMode)
+	}
+}
+
+func TestGRPCGetTaskCacheTTL(t *testing.T) {
+	client := newTestClient(t, &GRPCServer{Impl: lockedPlugin{}})
+
+	ttl, err := client.GetTaskCacheTTL("configure")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ttl != 30*time.Second {
+		t.Errorf("expected 30s, got %s", ttl)
+	}
+
+	// the plugins built with an older SDK do not cache
+	ttl, err = newTestClient(t, legacyServer{}).GetTaskCacheTTL("task")
+	if err != nil {
+		t.Fatalf("unexpected error for a plugin not supporting the cache: %v", err)
+	}
+	if ttl != 0 {
+		t.Errorf("expected no cache, got %s", ttl)
+	}
+}
+
+func TestGRPCCollectSpecsPartial(t *testing.T) {
+	client := newTestClient(t, &GRPCServer{Impl: partialSpecsPlugin{}})
+
+	out, err := client.CollectSpecs(context.Background())
+	if err == nil || err.Error() != "network: timeout" {
+		t.Errorf("expected the error of the failed collector, got: %v", err)
+	}
+	if string(out) != `{"os":{"family":"linux"},"network":{"_error":"timeout"}}` {
+		t.Errorf("expected the specs of the other collectors, got: %s", out)
+	}
+}
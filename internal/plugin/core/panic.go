@@ -0,0 +1,56 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const panicReason = "TASK_PANIC"
+
+// PanicError is returned by Do when the task panicked.
+type PanicError struct {
+	Value string // The recovered value.
+	Stack string // Stack trace of the panicking goroutine.
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %s", e.Value)
+}
+
+// GRPCStatus converts the panic to a gRPC status, to be sent by the plugins to the node.
+func (e *PanicError) GRPCStatus() *status.Status {
+	st := status.New(codes.Aborted, e.Error())
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   panicReason,
+		Metadata: map[string]string{"value": e.Value, "stack": e.Stack},
+	})
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+// fromStatus returns the PanicError of an error received from a plugin, err itself otherwise.
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetReason() == panicReason {
+			return &PanicError{Value: info.GetMetadata()["value"], Stack: info.GetMetadata()["stack"]}
+		}
+	}
+	return err
+}
+
+// IsPanic returns the PanicError of err, if any.
+func IsPanic(err error) (*PanicError, bool) {
+	var panicErr *PanicError
+	ok := errors.As(err, &panicErr)
+	return panicErr, ok
+}
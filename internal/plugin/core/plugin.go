@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jackadi-io/jackadi/internal/proto"
 )
@@ -14,7 +15,14 @@ type Plugin interface {
 	Version() (Version, error)
 	Do(ctx context.Context, task string, input *proto.Input) (Response, error)
 	CollectSpecs(ctx context.Context) ([]byte, error)
-	GetTaskLockMode(task string) (proto.LockMode, error)
+	GetTaskLockMode(task string) (TaskLockMode, error)
+	GetTaskCacheTTL(task string) (time.Duration, error) // 0 if the results of the task are not cached.
+}
+
+// TaskLockMode is the default lock mode of a task.
+type TaskLockMode struct {
+	Mode    proto.LockMode
+	Minimum bool // The requests can only strengthen the lock mode, a weaker one is ignored.
 }
 
 type Response struct {
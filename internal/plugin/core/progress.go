@@ -0,0 +1,19 @@
+package core
+
+import "context"
+
+// ProgressFunc receives the completion percentage (0-100) reported by a running task.
+type ProgressFunc func(percent int32)
+
+type progressKey struct{}
+
+// WithProgress returns a context carrying the function receiving the progress of the task.
+func WithProgress(ctx context.Context, f ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, f)
+}
+
+// ProgressFromContext returns the function receiving the progress of the task, if any.
+func ProgressFromContext(ctx context.Context) (ProgressFunc, bool) {
+	f, ok := ctx.Value(progressKey{}).(ProgressFunc)
+	return f, ok && f != nil
+}
@@ -10,6 +10,7 @@ import (
 	proto "github.com/jackadi-io/jackadi/internal/proto"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	_ "google.golang.org/protobuf/types/known/structpb"
 	reflect "reflect"
@@ -325,6 +326,7 @@ type DoResponse struct {
 	Output        []byte                 `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
 	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	Retcode       int32                  `protobuf:"varint,3,opt,name=retcode,proto3" json:"retcode,omitempty"`
+	Progress      *int32                 `protobuf:"varint,4,opt,name=progress,proto3,oneof" json:"progress,omitempty"` // Completion percentage (0-100), only set on progress updates
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -380,6 +382,13 @@ func (x *DoResponse) GetRetcode() int32 {
 	return 0
 }
 
+func (x *DoResponse) GetProgress() int32 {
+	if x != nil && x.Progress != nil {
+		return *x.Progress
+	}
+	return 0
+}
+
 type CollectSpecsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Output        []byte                 `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
@@ -479,6 +488,7 @@ func (x *TaskLockModeRequest) GetTask() string {
 type TaskLockModeResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	LockMode      proto.LockMode         `protobuf:"varint,1,opt,name=lock_mode,json=lockMode,proto3,enum=proto.LockMode" json:"lock_mode,omitempty"`
+	Minimum       bool                   `protobuf:"varint,2,opt,name=minimum,proto3" json:"minimum,omitempty"` // The requests can only strengthen the lock mode, a weaker one is ignored
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -520,11 +530,106 @@ func (x *TaskLockModeResponse) GetLockMode() proto.LockMode {
 	return proto.LockMode(0)
 }
 
+func (x *TaskLockModeResponse) GetMinimum() bool {
+	if x != nil {
+		return x.Minimum
+	}
+	return false
+}
+
+type TaskCacheTTLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          string                 `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskCacheTTLRequest) Reset() {
+	*x = TaskCacheTTLRequest{}
+	mi := &file_internal_plugin_core_protoplugin_plugin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskCacheTTLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskCacheTTLRequest) ProtoMessage() {}
+
+func (x *TaskCacheTTLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_plugin_core_protoplugin_plugin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskCacheTTLRequest.ProtoReflect.Descriptor instead.
+func (*TaskCacheTTLRequest) Descriptor() ([]byte, []int) {
+	return file_internal_plugin_core_protoplugin_plugin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TaskCacheTTLRequest) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+type TaskCacheTTLResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ttl           *durationpb.Duration   `protobuf:"bytes,1,opt,name=ttl,proto3" json:"ttl,omitempty"` // The results of the task are cached by the node during the TTL, not cached if zero
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskCacheTTLResponse) Reset() {
+	*x = TaskCacheTTLResponse{}
+	mi := &file_internal_plugin_core_protoplugin_plugin_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskCacheTTLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskCacheTTLResponse) ProtoMessage() {}
+
+func (x *TaskCacheTTLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_plugin_core_protoplugin_plugin_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskCacheTTLResponse.ProtoReflect.Descriptor instead.
+func (*TaskCacheTTLResponse) Descriptor() ([]byte, []int) {
+	return file_internal_plugin_core_protoplugin_plugin_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TaskCacheTTLResponse) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
 var File_internal_plugin_core_protoplugin_plugin_proto protoreflect.FileDescriptor
 
 const file_internal_plugin_core_protoplugin_plugin_proto_rawDesc = "" +
 	"\n" +
-	"-internal/plugin/core/protoplugin/plugin.proto\x12\vprotoplugin\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1cinternal/proto/cluster.proto\"\"\n" +
+	"-internal/plugin/core/protoplugin/plugin.proto\x12\vprotoplugin\x1a\x1egoogle/protobuf/duration.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1cinternal/proto/cluster.proto\"\"\n" +
 	"\fNameResponse\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\"%\n" +
 	"\rTasksResponse\x12\x14\n" +
@@ -543,27 +648,36 @@ const file_internal_plugin_core_protoplugin_plugin_proto_rawDesc = "" +
 	"\tGoVersion\x18\x04 \x01(\tR\tGoVersion\"C\n" +
 	"\tDoRequest\x12\x12\n" +
 	"\x04task\x18\x01 \x01(\tR\x04task\x12\"\n" +
-	"\x05input\x18\x02 \x01(\v2\f.proto.InputR\x05input\"T\n" +
+	"\x05input\x18\x02 \x01(\v2\f.proto.InputR\x05input\"\x82\x01\n" +
 	"\n" +
 	"DoResponse\x12\x16\n" +
 	"\x06output\x18\x01 \x01(\fR\x06output\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\x12\x18\n" +
-	"\aretcode\x18\x03 \x01(\x05R\aretcode\"D\n" +
+	"\aretcode\x18\x03 \x01(\x05R\aretcode\x12\x1f\n" +
+	"\bprogress\x18\x04 \x01(\x05H\x00R\bprogress\x88\x01\x01B\v\n" +
+	"\t_progress\"D\n" +
 	"\x14CollectSpecsResponse\x12\x16\n" +
 	"\x06output\x18\x01 \x01(\fR\x06output\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\")\n" +
 	"\x13TaskLockModeRequest\x12\x12\n" +
-	"\x04task\x18\x01 \x01(\tR\x04task\"D\n" +
+	"\x04task\x18\x01 \x01(\tR\x04task\"^\n" +
 	"\x14TaskLockModeResponse\x12,\n" +
-	"\tlock_mode\x18\x01 \x01(\x0e2\x0f.proto.LockModeR\blockMode2\xdf\x03\n" +
+	"\tlock_mode\x18\x01 \x01(\x0e2\x0f.proto.LockModeR\blockMode\x12\x18\n" +
+	"\aminimum\x18\x02 \x01(\bR\aminimum\")\n" +
+	"\x13TaskCacheTTLRequest\x12\x12\n" +
+	"\x04task\x18\x01 \x01(\tR\x04task\"C\n" +
+	"\x14TaskCacheTTLResponse\x12+\n" +
+	"\x03ttl\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\x03ttl2\xfc\x04\n" +
 	"\rJackadiPlugin\x129\n" +
 	"\x04Name\x12\x16.google.protobuf.Empty\x1a\x19.protoplugin.NameResponse\x12;\n" +
 	"\x05Tasks\x12\x16.google.protobuf.Empty\x1a\x1a.protoplugin.TasksResponse\x12;\n" +
 	"\x04Help\x12\x18.protoplugin.HelpRequest\x1a\x19.protoplugin.HelpResponse\x12?\n" +
 	"\aVersion\x12\x16.google.protobuf.Empty\x1a\x1c.protoplugin.VersionResponse\x125\n" +
-	"\x02Do\x12\x16.protoplugin.DoRequest\x1a\x17.protoplugin.DoResponse\x12I\n" +
+	"\x02Do\x12\x16.protoplugin.DoRequest\x1a\x17.protoplugin.DoResponse\x12C\n" +
+	"\x0eDoWithProgress\x12\x16.protoplugin.DoRequest\x1a\x17.protoplugin.DoResponse0\x01\x12I\n" +
 	"\fCollectSpecs\x12\x16.google.protobuf.Empty\x1a!.protoplugin.CollectSpecsResponse\x12V\n" +
-	"\x0fGetTaskLockMode\x12 .protoplugin.TaskLockModeRequest\x1a!.protoplugin.TaskLockModeResponseB6Z4github.com/jackadi-io/jackadi/pkg/plugin/protopluginb\x06proto3"
+	"\x0fGetTaskLockMode\x12 .protoplugin.TaskLockModeRequest\x1a!.protoplugin.TaskLockModeResponse\x12V\n" +
+	"\x0fGetTaskCacheTTL\x12 .protoplugin.TaskCacheTTLRequest\x1a!.protoplugin.TaskCacheTTLResponseB6Z4github.com/jackadi-io/jackadi/pkg/plugin/protopluginb\x06proto3"
 
 var (
 	file_internal_plugin_core_protoplugin_plugin_proto_rawDescOnce sync.Once
@@ -577,7 +691,7 @@ func file_internal_plugin_core_protoplugin_plugin_proto_rawDescGZIP() []byte {
 	return file_internal_plugin_core_protoplugin_plugin_proto_rawDescData
 }
 
-var file_internal_plugin_core_protoplugin_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_internal_plugin_core_protoplugin_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_internal_plugin_core_protoplugin_plugin_proto_goTypes = []any{
 	(*NameResponse)(nil),         // 0: protoplugin.NameResponse
 	(*TasksResponse)(nil),        // 1: protoplugin.TasksResponse
@@ -589,34 +703,42 @@ var file_internal_plugin_core_protoplugin_plugin_proto_goTypes = []any{
 	(*CollectSpecsResponse)(nil), // 7: protoplugin.CollectSpecsResponse
 	(*TaskLockModeRequest)(nil),  // 8: protoplugin.TaskLockModeRequest
 	(*TaskLockModeResponse)(nil), // 9: protoplugin.TaskLockModeResponse
-	nil,                          // 10: protoplugin.HelpResponse.OutputEntry
-	(*proto.Input)(nil),          // 11: proto.Input
-	(proto.LockMode)(0),          // 12: proto.LockMode
-	(*emptypb.Empty)(nil),        // 13: google.protobuf.Empty
+	(*TaskCacheTTLRequest)(nil),  // 10: protoplugin.TaskCacheTTLRequest
+	(*TaskCacheTTLResponse)(nil), // 11: protoplugin.TaskCacheTTLResponse
+	nil,                          // 12: protoplugin.HelpResponse.OutputEntry
+	(*proto.Input)(nil),          // 13: proto.Input
+	(proto.LockMode)(0),          // 14: proto.LockMode
+	(*durationpb.Duration)(nil),  // 15: google.protobuf.Duration
+	(*emptypb.Empty)(nil),        // 16: google.protobuf.Empty
 }
 var file_internal_plugin_core_protoplugin_plugin_proto_depIdxs = []int32{
-	10, // 0: protoplugin.HelpResponse.output:type_name -> protoplugin.HelpResponse.OutputEntry
-	11, // 1: protoplugin.DoRequest.input:type_name -> proto.Input
-	12, // 2: protoplugin.TaskLockModeResponse.lock_mode:type_name -> proto.LockMode
-	13, // 3: protoplugin.JackadiPlugin.Name:input_type -> google.protobuf.Empty
-	13, // 4: protoplugin.JackadiPlugin.Tasks:input_type -> google.protobuf.Empty
-	2,  // 5: protoplugin.JackadiPlugin.Help:input_type -> protoplugin.HelpRequest
-	13, // 6: protoplugin.JackadiPlugin.Version:input_type -> google.protobuf.Empty
-	5,  // 7: protoplugin.JackadiPlugin.Do:input_type -> protoplugin.DoRequest
-	13, // 8: protoplugin.JackadiPlugin.CollectSpecs:input_type -> google.protobuf.Empty
-	8,  // 9: protoplugin.JackadiPlugin.GetTaskLockMode:input_type -> protoplugin.TaskLockModeRequest
-	0,  // 10: protoplugin.JackadiPlugin.Name:output_type -> protoplugin.NameResponse
-	1,  // 11: protoplugin.JackadiPlugin.Tasks:output_type -> protoplugin.TasksResponse
-	3,  // 12: protoplugin.JackadiPlugin.Help:output_type -> protoplugin.HelpResponse
-	4,  // 13: protoplugin.JackadiPlugin.Version:output_type -> protoplugin.VersionResponse
-	6,  // 14: protoplugin.JackadiPlugin.Do:output_type -> protoplugin.DoResponse
-	7,  // 15: protoplugin.JackadiPlugin.CollectSpecs:output_type -> protoplugin.CollectSpecsResponse
-	9,  // 16: protoplugin.JackadiPlugin.GetTaskLockMode:output_type -> protoplugin.TaskLockModeResponse
-	10, // [10:17] is the sub-list for method output_type
-	3,  // [3:10] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	12, // 0: protoplugin.HelpResponse.output:type_name -> protoplugin.HelpResponse.OutputEntry
+	13, // 1: protoplugin.DoRequest.input:type_name -> proto.Input
+	14, // 2: protoplugin.TaskLockModeResponse.lock_mode:type_name -> proto.LockMode
+	15, // 3: protoplugin.TaskCacheTTLResponse.ttl:type_name -> google.protobuf.Duration
+	16, // 4: protoplugin.JackadiPlugin.Name:input_type -> google.protobuf.Empty
+	16, // 5: protoplugin.JackadiPlugin.Tasks:input_type -> google.protobuf.Empty
+	2,  // 6: protoplugin.JackadiPlugin.Help:input_type -> protoplugin.HelpRequest
+	16, // 7: protoplugin.JackadiPlugin.Version:input_type -> google.protobuf.Empty
+	5,  // 8: protoplugin.JackadiPlugin.Do:input_type -> protoplugin.DoRequest
+	5,  // 9: protoplugin.JackadiPlugin.DoWithProgress:input_type -> protoplugin.DoRequest
+	16, // 10: protoplugin.JackadiPlugin.CollectSpecs:input_type -> google.protobuf.Empty
+	8,  // 11: protoplugin.JackadiPlugin.GetTaskLockMode:input_type -> protoplugin.TaskLockModeRequest
+	10, // 12: protoplugin.JackadiPlugin.GetTaskCacheTTL:input_type -> protoplugin.TaskCacheTTLRequest
+	0,  // 13: protoplugin.JackadiPlugin.Name:output_type -> protoplugin.NameResponse
+	1,  // 14: protoplugin.JackadiPlugin.Tasks:output_type -> protoplugin.TasksResponse
+	3,  // 15: protoplugin.JackadiPlugin.Help:output_type -> protoplugin.HelpResponse
+	4,  // 16: protoplugin.JackadiPlugin.Version:output_type -> protoplugin.VersionResponse
+	6,  // 17: protoplugin.JackadiPlugin.Do:output_type -> protoplugin.DoResponse
+	6,  // 18: protoplugin.JackadiPlugin.DoWithProgress:output_type -> protoplugin.DoResponse
+	7,  // 19: protoplugin.JackadiPlugin.CollectSpecs:output_type -> protoplugin.CollectSpecsResponse
+	9,  // 20: protoplugin.JackadiPlugin.GetTaskLockMode:output_type -> protoplugin.TaskLockModeResponse
+	11, // 21: protoplugin.JackadiPlugin.GetTaskCacheTTL:output_type -> protoplugin.TaskCacheTTLResponse
+	13, // [13:22] is the sub-list for method output_type
+	4,  // [4:13] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_internal_plugin_core_protoplugin_plugin_proto_init() }
@@ -624,13 +746,14 @@ func file_internal_plugin_core_protoplugin_plugin_proto_init() {
 	if File_internal_plugin_core_protoplugin_plugin_proto != nil {
 		return
 	}
+	file_internal_plugin_core_protoplugin_plugin_proto_msgTypes[6].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_plugin_core_protoplugin_plugin_proto_rawDesc), len(file_internal_plugin_core_protoplugin_plugin_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
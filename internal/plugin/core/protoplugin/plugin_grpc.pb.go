@@ -25,8 +25,10 @@ const (
 	JackadiPlugin_Help_FullMethodName            = "/protoplugin.JackadiPlugin/Help"
 	JackadiPlugin_Version_FullMethodName         = "/protoplugin.JackadiPlugin/Version"
 	JackadiPlugin_Do_FullMethodName              = "/protoplugin.JackadiPlugin/Do"
+	JackadiPlugin_DoWithProgress_FullMethodName  = "/protoplugin.JackadiPlugin/DoWithProgress"
 	JackadiPlugin_CollectSpecs_FullMethodName    = "/protoplugin.JackadiPlugin/CollectSpecs"
 	JackadiPlugin_GetTaskLockMode_FullMethodName = "/protoplugin.JackadiPlugin/GetTaskLockMode"
+	JackadiPlugin_GetTaskCacheTTL_FullMethodName = "/protoplugin.JackadiPlugin/GetTaskCacheTTL"
 )
 
 // JackadiPluginClient is the client API for JackadiPlugin service.
@@ -38,8 +40,10 @@ type JackadiPluginClient interface {
 	Help(ctx context.Context, in *HelpRequest, opts ...grpc.CallOption) (*HelpResponse, error)
 	Version(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*VersionResponse, error)
 	Do(ctx context.Context, in *DoRequest, opts ...grpc.CallOption) (*DoResponse, error)
+	DoWithProgress(ctx context.Context, in *DoRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DoResponse], error)
 	CollectSpecs(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CollectSpecsResponse, error)
 	GetTaskLockMode(ctx context.Context, in *TaskLockModeRequest, opts ...grpc.CallOption) (*TaskLockModeResponse, error)
+	GetTaskCacheTTL(ctx context.Context, in *TaskCacheTTLRequest, opts ...grpc.CallOption) (*TaskCacheTTLResponse, error)
 }
 
 type jackadiPluginClient struct {
@@ -100,6 +104,25 @@ func (c *jackadiPluginClient) Do(ctx context.Context, in *DoRequest, opts ...grp
 	return out, nil
 }
 
+func (c *jackadiPluginClient) DoWithProgress(ctx context.Context, in *DoRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DoResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &JackadiPlugin_ServiceDesc.Streams[0], JackadiPlugin_DoWithProgress_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DoRequest, DoResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type JackadiPlugin_DoWithProgressClient = grpc.ServerStreamingClient[DoResponse]
+
 func (c *jackadiPluginClient) CollectSpecs(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*CollectSpecsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CollectSpecsResponse)
@@ -120,6 +143,16 @@ func (c *jackadiPluginClient) GetTaskLockMode(ctx context.Context, in *TaskLockM
 	return out, nil
 }
 
+func (c *jackadiPluginClient) GetTaskCacheTTL(ctx context.Context, in *TaskCacheTTLRequest, opts ...grpc.CallOption) (*TaskCacheTTLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskCacheTTLResponse)
+	err := c.cc.Invoke(ctx, JackadiPlugin_GetTaskCacheTTL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // JackadiPluginServer is the server API for JackadiPlugin service.
 // All implementations should embed UnimplementedJackadiPluginServer
 // for forward compatibility.
@@ -129,8 +162,10 @@ type JackadiPluginServer interface {
 	Help(context.Context, *HelpRequest) (*HelpResponse, error)
 	Version(context.Context, *emptypb.Empty) (*VersionResponse, error)
 	Do(context.Context, *DoRequest) (*DoResponse, error)
+	DoWithProgress(*DoRequest, grpc.ServerStreamingServer[DoResponse]) error
 	CollectSpecs(context.Context, *emptypb.Empty) (*CollectSpecsResponse, error)
 	GetTaskLockMode(context.Context, *TaskLockModeRequest) (*TaskLockModeResponse, error)
+	GetTaskCacheTTL(context.Context, *TaskCacheTTLRequest) (*TaskCacheTTLResponse, error)
 }
 
 // UnimplementedJackadiPluginServer should be embedded to have
@@ -155,12 +190,18 @@ func (UnimplementedJackadiPluginServer) Version(context.Context, *emptypb.Empty)
 func (UnimplementedJackadiPluginServer) Do(context.Context, *DoRequest) (*DoResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Do not implemented")
 }
+func (UnimplementedJackadiPluginServer) DoWithProgress(*DoRequest, grpc.ServerStreamingServer[DoResponse]) error {
+	return status.Error(codes.Unimplemented, "method DoWithProgress not implemented")
+}
 func (UnimplementedJackadiPluginServer) CollectSpecs(context.Context, *emptypb.Empty) (*CollectSpecsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CollectSpecs not implemented")
 }
 func (UnimplementedJackadiPluginServer) GetTaskLockMode(context.Context, *TaskLockModeRequest) (*TaskLockModeResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetTaskLockMode not implemented")
 }
+func (UnimplementedJackadiPluginServer) GetTaskCacheTTL(context.Context, *TaskCacheTTLRequest) (*TaskCacheTTLResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTaskCacheTTL not implemented")
+}
 func (UnimplementedJackadiPluginServer) testEmbeddedByValue() {}
 
 // UnsafeJackadiPluginServer may be embedded to opt out of forward compatibility for this service.
@@ -271,6 +312,17 @@ func _JackadiPlugin_Do_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _JackadiPlugin_DoWithProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DoRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JackadiPluginServer).DoWithProgress(m, &grpc.GenericServerStream[DoRequest, DoResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type JackadiPlugin_DoWithProgressServer = grpc.ServerStreamingServer[DoResponse]
+
 func _JackadiPlugin_CollectSpecs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -307,6 +359,24 @@ func _JackadiPlugin_GetTaskLockMode_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _JackadiPlugin_GetTaskCacheTTL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskCacheTTLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JackadiPluginServer).GetTaskCacheTTL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JackadiPlugin_GetTaskCacheTTL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JackadiPluginServer).GetTaskCacheTTL(ctx, req.(*TaskCacheTTLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // JackadiPlugin_ServiceDesc is the grpc.ServiceDesc for JackadiPlugin service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -342,7 +412,17 @@ var JackadiPlugin_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTaskLockMode",
 			Handler:    _JackadiPlugin_GetTaskLockMode_Handler,
 		},
+		{
+			MethodName: "GetTaskCacheTTL",
+			Handler:    _JackadiPlugin_GetTaskCacheTTL_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DoWithProgress",
+			Handler:       _JackadiPlugin_DoWithProgress_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/plugin/core/protoplugin/plugin.proto",
 }
@@ -1,14 +1,24 @@
 package inventory
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
 
+	"github.com/jackadi-io/jackadi/internal/config"
 	"github.com/jackadi-io/jackadi/internal/plugin/core"
 )
 
 var Registry = New()
 
+// ErrReservedName is returned when a plugin which is not builtin uses a reserved name.
+var ErrReservedName = errors.New("reserved plugin name")
+
+// ReservedNames are the names of the builtin plugins. Some of their tasks are routed by the node
+// itself (e.g. health:instant-ping), and a builtin plugin failing to load stops the node.
+var ReservedNames = []string{"health", config.SpecManagerPrefix, "cmd", "plugins", "diag"}
+
 type registry struct {
 	plugins map[string]core.Plugin
 	lock    *sync.Mutex
@@ -30,7 +40,17 @@ func (r *registry) Get(name string) (core.Plugin, error) {
 	return nil, fmt.Errorf("'%s' not registered", name)
 }
 
+// Register registers a loaded plugin, the reserved names are rejected.
 func (r *registry) Register(m core.Plugin) error {
+	return r.register(m, false)
+}
+
+// RegisterBuiltin registers a builtin plugin, which can use a reserved name.
+func (r *registry) RegisterBuiltin(m core.Plugin) error {
+	return r.register(m, true)
+}
+
+func (r *registry) register(m core.Plugin, builtin bool) error {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
@@ -38,6 +58,9 @@ func (r *registry) Register(m core.Plugin) error {
 	if err != nil {
 		return err
 	}
+	if !builtin && slices.Contains(ReservedNames, name) {
+		return fmt.Errorf("%w: '%s' is used by a builtin plugin", ErrReservedName, name)
+	}
 	if _, exists := r.plugins[name]; exists {
 		return fmt.Errorf("%s already exists", name)
 	}
@@ -0,0 +1,28 @@
+package inventory
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackadi-io/jackadi/sdk"
+)
+
+func TestRegisterReservedNames(t *testing.T) {
+	r := New()
+
+	for _, name := range ReservedNames {
+		if err := r.Register(sdk.New(name)); !errors.Is(err, ErrReservedName) {
+			t.Errorf("Register(%q) = %v, want ErrReservedName", name, err)
+		}
+		if err := r.RegisterBuiltin(sdk.New(name)); err != nil {
+			t.Errorf("RegisterBuiltin(%q) unexpected error: %v", name, err)
+		}
+	}
+
+	if err := r.Register(sdk.New("healthcheck")); err != nil {
+		t.Errorf("unexpected error for a non reserved name: %v", err)
+	}
+	if err := r.Register(sdk.New("healthcheck")); err == nil {
+		t.Error("expected an error for an already registered plugin")
+	}
+}
@@ -0,0 +1,39 @@
+package hcplugin
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+)
+
+var ErrNotAllowed = errors.New("plugin not allowed")
+
+// SetAllowed restricts the plugins loaded to the plugin files listed, all the plugins are allowed if empty.
+//
+// The other plugins found in the plugin directories or synchronized from the manager are not run, they are
+// reported as rejected.
+func (l *Loader) SetAllowed(files []string) {
+	l.allowed = files
+}
+
+// checkAllowed returns ErrNotAllowed if the plugin file is not in the allow list, and records it as rejected.
+func (l *Loader) checkAllowed(path, file string) error {
+	if len(l.allowed) == 0 || slices.Contains(l.allowed, file) {
+		return nil
+	}
+	if l.rejected == nil {
+		l.rejected = make(map[string]string)
+	}
+	l.rejected[path] = file
+	slog.Warn("plugin rejected: not in the allowed plugins (plugins.allowed)", "plugin", path)
+	return fmt.Errorf("%w: '%s' is not in the allowed plugins", ErrNotAllowed, file)
+}
+
+// Rejected returns the files of the plugins not loaded because they are not allowed, sorted.
+func (l *Loader) Rejected() []string {
+	files := slices.Collect(maps.Values(l.rejected))
+	slices.Sort(files)
+	return slices.Compact(files)
+}
@@ -0,0 +1,49 @@
+package hcplugin
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jackadi-io/jackadi/internal/plugin/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_NotAllowed(t *testing.T) {
+	syncDir := newPluginDir(t, "docker")
+	localDir := newPluginDir(t, "collector")
+
+	l := New()
+	l.SetAllowed([]string{"git"})
+	l.Load([]string{syncDir, localDir})
+
+	assert.Empty(t, l.files(), "expected the plugins not allowed to be skipped")
+	assert.Equal(t, []string{"collector", "docker"}, l.Rejected())
+}
+
+func TestUpdate_NotAllowed(t *testing.T) {
+	syncDir := newPluginDir(t, "docker")
+	localDir := newPluginDir(t, "collector")
+	tmpDir := newPluginDir(t, "backdoor")
+
+	l := New()
+	l.SetAllowed([]string{"git"})
+	l.Load([]string{syncDir, localDir})
+
+	// docker is not synchronized anymore, backdoor is pushed by the manager
+	changes, changed, err := l.Update(syncDir, tmpDir, nil)
+	require.NoError(t, err, "expected the plugins not allowed to be reported as rejected, not as a failed sync")
+	assert.False(t, changed)
+	assert.NoFileExists(t, filepath.Join(syncDir, "backdoor"), "expected the plugin not allowed not to be installed")
+	assert.Equal(t, []types.PluginChanges{
+		{Name: "backdoor", FileName: "backdoor", Rejected: true},
+		{Name: "collector", FileName: "collector", Rejected: true},
+	}, changes)
+}
+
+func TestLoad_AllAllowed(t *testing.T) {
+	l := New()
+	l.Load([]string{newPluginDir(t, "docker")})
+
+	assert.Empty(t, l.Rejected(), "expected all the plugins to be allowed without allow list")
+}
@@ -0,0 +1,45 @@
+package hcplugin
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// sensitiveEnvKeywords are the parts of a variable name indicating its value must not be logged.
+var sensitiveEnvKeywords = []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "KEY", "CREDENTIAL", "AUTH"}
+
+// mergeEnv merges lists of KEY=value variables, a variable overriding the ones with the same key of
+// the previous lists.
+func mergeEnv(lists ...[]string) ([]string, error) {
+	var env []string
+	for _, list := range lists {
+		for _, kv := range list {
+			key, _, ok := strings.Cut(kv, "=")
+			if !ok || key == "" {
+				return nil, errors.New("variables must be formatted as KEY=value") // the variable is not printed, it may be a secret
+			}
+			i := slices.IndexFunc(env, func(prev string) bool { return strings.HasPrefix(prev, key+"=") })
+			if i >= 0 {
+				env[i] = kv
+				continue
+			}
+			env = append(env, kv)
+		}
+	}
+	return env, nil
+}
+
+// redactEnv returns the variables with the value of the sensitive ones masked, to be logged.
+func redactEnv(env []string) []string {
+	redacted := make([]string, 0, len(env))
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		upper := strings.ToUpper(key)
+		if slices.ContainsFunc(sensitiveEnvKeywords, func(kw string) bool { return strings.Contains(upper, kw) }) {
+			kv = key + "=***"
+		}
+		redacted = append(redacted, kv)
+	}
+	return redacted
+}
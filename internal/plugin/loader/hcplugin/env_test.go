@@ -0,0 +1,70 @@
+package hcplugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeEnv(t *testing.T) {
+	env, err := mergeEnv([]string{"REGION=eu", "PROFILE=dev"}, []string{"PROFILE=prod", "EMPTY="}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"REGION=eu", "PROFILE=prod", "EMPTY="}, env)
+
+	env, err = mergeEnv(nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, env, "no variable: the environment of the node is inherited")
+
+	_, err = mergeEnv([]string{"s3cret"})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "s3cret")
+
+	_, err = mergeEnv([]string{"=value"})
+	assert.Error(t, err)
+}
+
+func TestRedactEnv(t *testing.T) {
+	env := []string{"AWS_PROFILE=prod", "AWS_SECRET_ACCESS_KEY=abc", "db_password=abc", "API_TOKEN=abc", "CACHE_DIR=/var/cache"}
+	assert.Equal(t,
+		[]string{"AWS_PROFILE=prod", "AWS_SECRET_ACCESS_KEY=***", "db_password=***", "API_TOKEN=***", "CACHE_DIR=/var/cache"},
+		redactEnv(env),
+	)
+}
+
+func TestLoadPluginEnv(t *testing.T) {
+	dir := buildTestPlugin(t, t.TempDir())
+	t.Setenv("JACKADI_TEST_NODE_ONLY", "leaked")
+
+	manifest := "env:\n  - PLUGIN_REGION=eu\n  - PLUGIN_TOKEN=from-manifest\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "procplugin.manifest.yaml"), []byte(manifest), 0600))
+
+	loader := New()
+	loader.SetProcessConfig(map[string]ProcessConfig{"procplugin": {Env: []string{"PLUGIN_TOKEN=s3cret"}}})
+	loadTestPlugin(t, &loader, dir)
+
+	output, errMsg := callTestPlugin(t, "getenv", "PLUGIN_REGION")
+	assert.Empty(t, errMsg)
+	assert.Equal(t, `"eu"`, output)
+
+	output, errMsg = callTestPlugin(t, "getenv", "PLUGIN_TOKEN")
+	assert.Empty(t, errMsg)
+	assert.Equal(t, `"s3cret"`, output, "the node configuration overrides the manifest")
+
+	_, errMsg = callTestPlugin(t, "getenv", "JACKADI_TEST_NODE_ONLY")
+	assert.Contains(t, errMsg, "not set", "the environment of the node must not be inherited")
+}
+
+func TestLoadPluginEnvInherited(t *testing.T) {
+	dir := buildTestPlugin(t, t.TempDir())
+	t.Setenv("JACKADI_TEST_NODE_ONLY", "inherited")
+
+	loader := New()
+	loadTestPlugin(t, &loader, dir)
+
+	output, errMsg := callTestPlugin(t, "getenv", "JACKADI_TEST_NODE_ONLY")
+	assert.Empty(t, errMsg)
+	assert.Equal(t, `"inherited"`, output, "without configured variables, the environment of the node is inherited")
+}
@@ -2,15 +2,17 @@ package hcplugin
 
 import (
 	"crypto/sha256"
+	"crypto/tls"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"log/slog"
+	"maps"
 	"net/http"
+	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"reflect"
 	"slices"
@@ -19,6 +21,7 @@ import (
 
 	"github.com/hashicorp/go-hclog"
 	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/jackadi-io/jackadi/internal/config"
 	"github.com/jackadi-io/jackadi/internal/plugin/core"
 	"github.com/jackadi-io/jackadi/internal/plugin/inventory"
 	"github.com/jackadi-io/jackadi/internal/plugin/types"
@@ -28,6 +31,8 @@ var PluginMap = map[string]goplugin.Plugin{
 	"plugin": &core.HCPlugin{},
 }
 
+var ErrPluginConflict = errors.New("plugin conflict")
+
 type PluginInfo struct {
 	name    string
 	file    string
@@ -37,11 +42,31 @@ type PluginInfo struct {
 }
 
 type Loader struct {
-	logger  hclog.Logger
-	plugins map[string]PluginInfo // key=filepath
+	logger    hclog.Logger
+	plugins   map[string]PluginInfo    // key=filepath
+	dirs      []string                 // Plugin directories, ordered by precedence.
+	procs     map[string]ProcessConfig // key=plugin file
+	tlsConfig *tls.Config              // The plugins are downloaded over HTTPS if set.
+	allowed   []string                 // Plugin files allowed to be loaded, all if empty.
+	rejected  map[string]string        // Plugins not allowed, key=filepath, value=file.
+}
+
+// SetTLSConfig enables the download of the plugins over HTTPS with the TLS configuration.
+func (l *Loader) SetTLSConfig(tlsConfig *tls.Config) {
+	l.tlsConfig = tlsConfig
 }
 
-// discover all non .so plugins in plugins/
+// httpClient returns the client downloading the plugins, and the scheme of their URL.
+func (l *Loader) httpClient() (*http.Client, string) {
+	if l.tlsConfig == nil {
+		return &http.Client{Timeout: time.Minute}, "http"
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = l.tlsConfig
+	return &http.Client{Timeout: time.Minute, Transport: transport}, "https"
+}
+
+// discover all non .so plugins in plugins/, manifests excluded.
 //
 // This is only for hashicorp/go-plugin plugins.
 func discover(pluginDir string) []string {
@@ -53,7 +78,7 @@ func discover(pluginDir string) []string {
 
 	pluginFiles := []string{}
 	for _, file := range files {
-		if !strings.HasSuffix(file.Name(), ".so") {
+		if !strings.HasSuffix(file.Name(), ".so") && !strings.HasSuffix(file.Name(), config.PluginManifestSuffix) {
 			pluginFiles = append(pluginFiles, file.Name())
 		}
 	}
@@ -61,6 +86,38 @@ func discover(pluginDir string) []string {
 	return pluginFiles
 }
 
+// resolve returns the path of the plugins found in the plugin directories.
+//
+// Directories are ordered by precedence: when a plugin file exists in several directories, the
+// first one wins and the other ones are ignored.
+func resolve(pluginDirs []string) []string {
+	paths := []string{}
+	found := make(map[string]string) // key=file, value=path
+	for _, dir := range pluginDirs {
+		for _, file := range discover(dir) {
+			path := filepath.Join(dir, file)
+			if winner, ok := found[file]; ok {
+				slog.Warn("plugin conflict: shadowed by a directory with higher precedence", "plugin_file", file, "ignored", path, "loaded", winner)
+				continue
+			}
+			found[file] = path
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// Find returns the path of a plugin file in the plugin directories, following their precedence.
+func Find(pluginDirs []string, file string) (string, error) {
+	for _, dir := range pluginDirs {
+		path := filepath.Join(dir, file)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("'%s': %w", file, os.ErrNotExist)
+}
+
 func CalculateChecksum(file string) (string, error) {
 	fd, err := os.Open(file)
 	if err != nil {
@@ -94,10 +151,20 @@ func (l *Loader) load(path string) error {
 		return fmt.Errorf("plugin checksum failed: %w", err)
 	}
 
+	m, err := readManifest(path)
+	if err != nil {
+		return err
+	}
+	cmd, err := l.command(path, m)
+	if err != nil {
+		return err
+	}
+
 	cfg := goplugin.ClientConfig{
 		HandshakeConfig:  core.Handshake,
 		Plugins:          PluginMap,
-		Cmd:              exec.Command(path),
+		Cmd:              cmd,
+		SkipHostEnv:      cmd.Env != nil,
 		AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolNetRPC, goplugin.ProtocolGRPC},
 		Logger:           l.logger,
 	}
@@ -134,6 +201,16 @@ func (l *Loader) load(path string) error {
 		return fmt.Errorf("bad plugin name: %w", err)
 	}
 
+	// two plugin files registering the same plugin: the directory with the higher precedence wins
+	if other, ok := l.pathOf(name); ok && other != path {
+		if l.precedence(other) <= l.precedence(path) {
+			client.Kill()
+			return fmt.Errorf("%w: '%s' is already loaded from '%s'", ErrPluginConflict, name, other)
+		}
+		slog.Warn("plugin conflict: unloading plugin shadowed by a directory with higher precedence", "plugin", name, "unloaded", other, "loaded", path)
+		l.unload(other)
+	}
+
 	// register the loaded plugin publicly
 	if err := inventory.Registry.Register(coll); err != nil {
 		client.Kill()
@@ -146,6 +223,35 @@ func (l *Loader) load(path string) error {
 	return nil
 }
 
+// pathOf returns the path of the loaded plugin registered as name.
+func (l *Loader) pathOf(name string) (string, bool) {
+	for path, p := range l.plugins {
+		if p.name == name {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// precedence returns the rank of the directory of path in the plugin directories, the lowest wins. The directories
+// not listed come last.
+func (l *Loader) precedence(path string) int {
+	if i := slices.Index(l.dirs, filepath.Dir(path)); i >= 0 {
+		return i
+	}
+	return len(l.dirs)
+}
+
+// unload stops and unregisters the plugin loaded from path, its file is kept.
+func (l *Loader) unload(path string) {
+	p := l.plugins[path]
+	p.client.Kill()
+	if err := inventory.Registry.Unregister(p.name); err != nil {
+		slog.Error("failed to unload plugin", "error", err, "plugin_file", p.file)
+	}
+	delete(l.plugins, path)
+}
+
 func describeInterface(raw any, unreference bool) []string {
 	t := reflect.TypeOf(raw)
 
@@ -163,21 +269,72 @@ func describeInterface(raw any, unreference bool) []string {
 	return methods
 }
 
-// Load loads all plugins.
-func (l *Loader) Load(pluginDir string) {
-	plugins := discover(pluginDir)
-	for _, file := range plugins {
-		path := filepath.Join(pluginDir, file)
-		if err := l.load(path); err != nil {
-			slog.Error("failed to load plugin", "error", err, "plugin", file)
+// Load loads all plugins of the plugin directories, ordered by precedence.
+//
+// Plugins are loaded after the plugins they require (see manifest). When several files register the same plugin
+// name, the one of the directory with the higher precedence is kept.
+func (l *Loader) Load(pluginDirs []string) {
+	l.dirs = make([]string, 0, len(pluginDirs))
+	for _, dir := range pluginDirs {
+		l.dirs = append(l.dirs, filepath.Clean(dir))
+	}
+
+	paths := make(map[string]string) // key=file
+	files := []string{}
+	requires := make(map[string][]string)
+	for _, path := range resolve(pluginDirs) {
+		if err := l.checkAllowed(path, filepath.Base(path)); err != nil {
+			continue
+		}
+		m, err := readManifest(path)
+		if err != nil {
+			slog.Error("failed to load plugin", "error", err, "plugin", path)
+			continue
+		}
+		file := filepath.Base(path)
+		paths[file] = path
+		files = append(files, file)
+		requires[file] = m.Requires
+	}
+
+	sorted, errs := sortByDependencies(files, requires, nil)
+	for file, err := range errs {
+		slog.Error("failed to load plugin", "error", err, "plugin", paths[file])
+	}
+
+	for _, file := range sorted {
+		err := l.checkDependencies(file, requires[file])
+		if err == nil {
+			err = l.load(paths[file])
+		}
+		if err != nil {
+			slog.Error("failed to load plugin", "error", err, "plugin", paths[file])
 		}
 	}
 }
 
+// checkDependencies returns an error if a plugin required by file is not loaded.
+func (l *Loader) checkDependencies(file string, requires []string) error {
+	for _, dep := range requires {
+		if !slices.Contains(l.files(), dep) {
+			return fmt.Errorf("%w: '%s' requires '%s' which is not loaded", ErrMissingDependency, file, dep)
+		}
+	}
+	return nil
+}
+
+// files returns the files of the loaded plugins.
+func (l *Loader) files() []string {
+	files := make([]string, 0, len(l.plugins))
+	for _, p := range l.plugins {
+		files = append(files, p.file)
+	}
+	return files
+}
+
 // download the plugin from the provided URL.
 // The name is only the identifier of the plugin.
-func download(name, url, tmpDir string) error {
-	client := http.Client{Timeout: time.Minute}
+func download(client *http.Client, name, url, tmpDir string) error {
 	resp, err := client.Get(url)
 	if err != nil {
 		return fmt.Errorf("'%s': %w", url, err)
@@ -187,6 +344,9 @@ func download(name, url, tmpDir string) error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("'%s': %w", url, os.ErrNotExist)
+	}
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		return fmt.Errorf("'%s' http error %d", url, resp.StatusCode)
 	}
@@ -209,11 +369,46 @@ func download(name, url, tmpDir string) error {
 	return nil
 }
 
+// pluginURL returns the URL of a plugin file served by the manager at managerHost, a "host:port" address
+// with a bracketed IPv6 host (see helper.JoinHostPort).
+func pluginURL(scheme, managerHost, file string) string {
+	u := url.URL{Scheme: scheme, Host: managerHost, Path: config.PluginServerPath + file}
+	return u.String()
+}
+
+// downloadChecksum downloads the plugin file with the checksum listed by the manager, to not get another version
+// if the file is replaced on the manager meanwhile.
+//
+// The file is downloaded by name from the managers not serving the plugins by checksum, its checksum is then
+// verified.
+func downloadChecksum(client *http.Client, file, checksum, scheme, managerHost, tmpDir string) error {
+	err := download(client, file, pluginURL(scheme, managerHost, config.PluginByHashPath+checksum), tmpDir)
+	if errors.Is(err, os.ErrNotExist) {
+		err = download(client, file, pluginURL(scheme, managerHost, file), tmpDir)
+	}
+	if err != nil {
+		return err
+	}
+
+	localPath := filepath.Join(tmpDir, file)
+	got, err := CalculateChecksum(localPath)
+	if err != nil {
+		_ = os.Remove(localPath)
+		return err
+	}
+	if got != checksum {
+		_ = os.Remove(localPath)
+		return fmt.Errorf("checksum mismatch: expected %s, got %s (plugin updated on the manager during the sync?)", checksum, got)
+	}
+	return nil
+}
+
 // DownloadPlugins downloads plugins from the manager, excluding already up to date plugins (same checksum).
 func (l *Loader) DownloadPlugins(nodePlugins map[string]string, managerHost, pluginDir, tmpDir string) ([]string, error) {
 	slog.Debug("sync", "values", nodePlugins)
 	upToDate := []string{}
 	var errs error
+	client, scheme := l.httpClient()
 	for file, checksum := range nodePlugins {
 		path := filepath.Join(pluginDir, file)
 		if p, ok := l.plugins[path]; ok && p.version == checksum {
@@ -222,36 +417,86 @@ func (l *Loader) DownloadPlugins(nodePlugins map[string]string, managerHost, plu
 			continue
 		}
 
-		url := fmt.Sprintf("http://%s/plugin/%s", managerHost, file)
+		url := pluginURL(scheme, managerHost, file)
 		slog.Debug("starting plugin sync", "plugin_file", file, "url", url)
-		if err := download(file, url, tmpDir); err != nil {
+		if err := downloadChecksum(client, file, checksum, scheme, managerHost, tmpDir); err != nil {
 			errs = errors.Join(errs, fmt.Errorf("new plugin not installed: '%s' file not downloaded: %w", file, err))
 			slog.Error("failed to download", "plugin_file", file, "url", url, "error", err)
 			continue
 		}
 		slog.Debug("plugin downloaded", "plugin_file", file, "url", url)
+
+		// the manifest is optional
+		manifest := file + config.PluginManifestSuffix
+		if err := download(client, manifest, url+config.PluginManifestSuffix, tmpDir); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = errors.Join(errs, fmt.Errorf("new plugin not installed: '%s' manifest not downloaded: %w", file, err))
+			slog.Error("failed to download manifest", "plugin_file", file, "url", url, "error", err)
+			_ = os.Remove(filepath.Join(tmpDir, file))
+		}
 	}
 
 	return upToDate, errs
 }
 
+// Update installs the downloaded plugins in pluginDir, and unloads the plugins of pluginDir not synchronized anymore.
+//
+// Plugins loaded from other directories are left untouched, unless shadowed by a synchronized plugin. The plugins
+// not allowed are not installed, and only reported as rejected along with the ones rejected by Load.
 func (l *Loader) Update(pluginDir, tmpDir string, upToDate []string) ([]types.PluginChanges, bool, error) {
-	pluginsFile := discover(tmpDir)
 	newPluginNameList := []string{}
 	changes := []types.PluginChanges{}
 
 	var errs error
 	changed := false
 
-	for _, file := range pluginsFile {
+	// the plugins of pluginDir are checked again from the synchronized ones
+	maps.DeleteFunc(l.rejected, func(path, _ string) bool { return filepath.Dir(path) == filepath.Clean(pluginDir) })
+
+	pluginsFile := []string{}
+	requires := make(map[string][]string)
+	for _, file := range discover(tmpDir) {
+		// a plugin not allowed is refused by the node on purpose, not a failure of the sync
+		if err := l.checkAllowed(filepath.Join(pluginDir, file), file); err != nil {
+			continue
+		}
+		m, err := readManifest(filepath.Join(tmpDir, file))
+		if err != nil {
+			slog.Error("new plugin not installed", "error", err, "plugin_file", file)
+			errs = errors.Join(errs, fmt.Errorf("plugin not installed: '%s': %w", file, err))
+			continue
+		}
+		pluginsFile = append(pluginsFile, file)
+		requires[file] = m.Requires
+	}
+
+	sorted, depErrs := sortByDependencies(pluginsFile, requires, l.files())
+	for file, err := range depErrs {
+		slog.Error("plugin not installed", "error", err, "plugin_file", file)
+		errs = errors.Join(errs, fmt.Errorf("plugin not installed: '%s': %w", file, err))
+	}
+
+	for _, file := range sorted {
 		candidatePluginPath := filepath.Join(tmpDir, file)
 		path := filepath.Join(pluginDir, file)
 
 		slog.Debug("syncing plugin", "plugin_file", file)
 
+		if err := l.checkDependencies(file, requires[file]); err != nil {
+			slog.Error("plugin not installed", "error", err, "plugin_file", file)
+			errs = errors.Join(errs, fmt.Errorf("plugin not installed: '%s': %w", file, err))
+			continue
+		}
+		if err := installManifest(candidatePluginPath, path); err != nil {
+			slog.Error("plugin not installed: failed to replace manifest", "error", err, "plugin_file", file)
+			errs = errors.Join(errs, fmt.Errorf("plugin not installed: failed to replace '%s' manifest: %w", file, err))
+			continue
+		}
+
 		// handle new plugin
 		p, ok := l.plugins[path]
 		if !ok {
+			l.unloadShadowed(file, path)
+
 			slog.Debug("new plugin to install", "plugin_file", file)
 			if err := os.Rename(candidatePluginPath, path); err != nil {
 				// TODO: the error is not sent back to the user CLI
@@ -299,7 +544,13 @@ func (l *Loader) Update(pluginDir, tmpDir string, upToDate []string) ([]types.Pl
 
 	// unload plugins which should be removed
 	slog.Debug("new list of plugins", "list", newPluginNameList)
-	for _, p := range l.plugins {
+	for path, p := range l.plugins {
+		// ignore plugins not synchronized from the manager
+		if filepath.Dir(path) != filepath.Clean(pluginDir) {
+			changes = append(changes, types.PluginChanges{Name: p.name, FileName: p.file})
+			continue
+		}
+
 		// ignore up to date plugin
 		if slices.Contains(upToDate, p.file) {
 			slog.Debug("plugin checksum unchanged", "plugin_file", p.file)
@@ -316,22 +567,41 @@ func (l *Loader) Update(pluginDir, tmpDir string, upToDate []string) ([]types.Pl
 				continue
 			}
 
-			if err := os.Remove(p.file); err != nil {
+			if err := os.Remove(path); err != nil {
 				slog.Error("plugin removal partially failed: failed to delete file", "error", err, "plugin_file", p.file)
 				errs = errors.Join(errs, fmt.Errorf("plugin removal partially failed: failed to delete '%s' file: %w", p.file, err))
 			}
+			if err := os.Remove(manifestPath(path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				slog.Error("plugin removal partially failed: failed to delete manifest", "error", err, "plugin_file", p.file)
+			}
 
-			l.plugins[p.name].client.Kill()
+			p.client.Kill()
 			changes = append(changes, types.PluginChanges{Name: p.name, Deleted: true})
 			changed = true
-			delete(l.plugins, p.name)
+			delete(l.plugins, path)
 			slog.Debug("plugin removed", "name", p.name)
 		}
 	}
 
+	for _, file := range l.Rejected() {
+		changes = append(changes, types.PluginChanges{Name: file, FileName: file, Rejected: true})
+	}
+
 	return changes, changed, errs
 }
 
+// unloadShadowed unloads the plugin loaded from another directory with the same file name, as the
+// plugin installed in path takes precedence.
+func (l *Loader) unloadShadowed(file, path string) {
+	for shadowedPath, p := range l.plugins {
+		if p.file != file || shadowedPath == path {
+			continue
+		}
+		slog.Warn("plugin conflict: unloading plugin shadowed by a directory with higher precedence", "plugin_file", file, "unloaded", shadowedPath, "loaded", path)
+		l.unload(shadowedPath)
+	}
+}
+
 func (l *Loader) Kill(name string) {
 	if p, ok := l.plugins[name]; ok {
 		p.client.Kill()
@@ -0,0 +1,155 @@
+package hcplugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPluginDir(t *testing.T, files ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, file := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(file), 0755))
+	}
+	return dir
+}
+
+func TestResolve(t *testing.T) {
+	local := newPluginDir(t, "cmd", "cmd.manifest.yaml", "collector", "legacy.so")
+	vendor := newPluginDir(t, "collector", "pkg")
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	tests := []struct {
+		name string
+		dirs []string
+		want []string
+	}{
+		{
+			name: "single directory",
+			dirs: []string{vendor},
+			want: []string{filepath.Join(vendor, "collector"), filepath.Join(vendor, "pkg")},
+		},
+		{
+			name: "first directory wins",
+			dirs: []string{local, vendor},
+			want: []string{filepath.Join(local, "cmd"), filepath.Join(local, "collector"), filepath.Join(vendor, "pkg")},
+		},
+		{
+			name: "precedence follows order",
+			dirs: []string{vendor, local},
+			want: []string{filepath.Join(vendor, "collector"), filepath.Join(vendor, "pkg"), filepath.Join(local, "cmd")},
+		},
+		{
+			name: "missing directory ignored",
+			dirs: []string{missing, vendor},
+			want: []string{filepath.Join(vendor, "collector"), filepath.Join(vendor, "pkg")},
+		},
+		{
+			name: "no directory",
+			dirs: nil,
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resolve(tt.dirs))
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	local := newPluginDir(t, "collector")
+	vendor := newPluginDir(t, "collector", "pkg")
+
+	path, err := Find([]string{local, vendor}, "collector")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(local, "collector"), path)
+
+	path, err = Find([]string{local, vendor}, "pkg")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(vendor, "pkg"), path)
+
+	_, err = Find([]string{local, vendor}, "unknown")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestPluginURL(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		want string
+	}{
+		{name: "IPv4", host: "192.168.1.10", want: "http://192.168.1.10:40081/plugin/cmd"},
+		{name: "IPv6", host: "2001:db8::10", want: "http://[2001:db8::10]:40081/plugin/cmd"},
+		{name: "bracketed IPv6", host: "[2001:db8::10]", want: "http://[2001:db8::10]:40081/plugin/cmd"},
+		{name: "IPv6 with zone", host: "fe80::1%eth0", want: "http://[fe80::1%25eth0]:40081/plugin/cmd"},
+		{name: "hostname", host: "manager.example.com", want: "http://manager.example.com:40081/plugin/cmd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pluginURL("http", helper.JoinHostPort(tt.host, "40081"), "cmd")
+			assert.Equal(t, tt.want, got)
+
+			// the URL must be usable by the HTTP client
+			u, err := url.Parse(got)
+			require.NoError(t, err)
+			assert.Equal(t, "40081", u.Port())
+		})
+	}
+}
+
+func TestDownloadChecksum(t *testing.T) {
+	pluginDir := newPluginDir(t, "collector")
+	checksum, err := CalculateChecksum(filepath.Join(pluginDir, "collector"))
+	require.NoError(t, err)
+
+	byHash := map[string]string{checksum: "collector"}
+	byName := map[string]string{"collector": "collector"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sum, ok := strings.CutPrefix(r.URL.Path, config.PluginServerPath+config.PluginByHashPath); ok {
+			if data, ok := byHash[sum]; ok {
+				_, _ = w.Write([]byte(data))
+				return
+			}
+			http.NotFound(w, r)
+			return
+		}
+		if data, ok := byName[strings.TrimPrefix(r.URL.Path, config.PluginServerPath)]; ok {
+			_, _ = w.Write([]byte(data))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	tmpDir := t.TempDir()
+	require.NoError(t, downloadChecksum(srv.Client(), "collector", checksum, "http", host, tmpDir))
+	data, err := os.ReadFile(filepath.Join(tmpDir, "collector"))
+	require.NoError(t, err)
+	assert.Equal(t, "collector", string(data))
+
+	// managers not serving by checksum
+	clear(byHash)
+	tmpDir = t.TempDir()
+	require.NoError(t, downloadChecksum(srv.Client(), "collector", checksum, "http", host, tmpDir))
+
+	// plugin replaced on the manager since it was listed
+	byName["collector"] = "collector v2"
+	tmpDir = t.TempDir()
+	err = downloadChecksum(srv.Client(), "collector", checksum, "http", host, tmpDir)
+	assert.ErrorContains(t, err, "checksum mismatch")
+	assert.NoFileExists(t, filepath.Join(tmpDir, "collector"))
+}
@@ -0,0 +1,110 @@
+package hcplugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/clock"
+	"github.com/jackadi-io/jackadi/internal/config"
+)
+
+// Index maps the checksums of the plugin files to their paths, to serve the plugins by checksum.
+//
+// The content returned always matches the requested checksum, even if the file is replaced while it is
+// downloaded: a node never gets a half-written plugin, or another version than the one it expects.
+//
+// The plugin files are scanned again for an unknown checksum at most once per config.PluginIndexCooldown: the
+// requests of unknown checksums cannot make the manager hash the plugin directories over and over. A file found
+// replaced is scanned again at once.
+type Index struct {
+	dirs      []string
+	mu        sync.Mutex
+	files     map[string]indexedFile // key=path
+	refreshed time.Time              // Last scan of the plugin files.
+	clock     clock.Clock
+}
+
+type indexedFile struct {
+	modTime  time.Time
+	size     int64
+	checksum string
+}
+
+func NewIndex(pluginDirs []string) *Index {
+	return &Index{dirs: pluginDirs, files: make(map[string]indexedFile), clock: clock.Real{}}
+}
+
+// Read returns the content of the plugin file with the checksum, os.ErrNotExist if there is none.
+func (i *Index) Read(checksum string) ([]byte, error) {
+	path, ok := i.lookup(checksum)
+	if !ok {
+		// the plugin files changed since the last refresh
+		if !i.refresh(false) {
+			return nil, fmt.Errorf("checksum '%s': %w", checksum, os.ErrNotExist)
+		}
+		if path, ok = i.lookup(checksum); !ok {
+			return nil, fmt.Errorf("checksum '%s': %w", checksum, os.ErrNotExist)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != checksum {
+		// replaced since the last refresh, the version expected by the node is gone
+		i.refresh(true)
+		return nil, fmt.Errorf("checksum '%s': %w", checksum, os.ErrNotExist)
+	}
+	return data, nil
+}
+
+func (i *Index) lookup(checksum string) (string, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for path, f := range i.files {
+		if f.checksum == checksum {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// refresh indexes the plugin files of the plugin directories, the checksum of a file is only calculated
+// again if its size or modification time changed. Unless forced, it returns false without scanning them during the
+// cooldown.
+func (i *Index) refresh(force bool) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	now := i.clock.Now()
+	if !force && !i.refreshed.IsZero() && now.Sub(i.refreshed) < config.PluginIndexCooldown {
+		return false
+	}
+	i.refreshed = now
+
+	files := make(map[string]indexedFile, len(i.files))
+	for _, path := range resolve(i.dirs) {
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		f, ok := i.files[path]
+		if !ok || f.size != info.Size() || !f.modTime.Equal(info.ModTime()) {
+			checksum, err := CalculateChecksum(path)
+			if err != nil {
+				continue
+			}
+			f = indexedFile{modTime: info.ModTime(), size: info.Size(), checksum: checksum}
+		}
+		files[path] = f
+	}
+	i.files = files
+	return true
+}
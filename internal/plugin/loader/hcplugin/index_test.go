@@ -0,0 +1,75 @@
+package hcplugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/clock"
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexRead(t *testing.T) {
+	local := newPluginDir(t, "collector")
+	vendor := newPluginDir(t, "collector", "pkg")
+	index := NewIndex([]string{local, vendor})
+	fake := clock.NewFake(time.Now())
+	index.clock = fake
+
+	collector, err := CalculateChecksum(filepath.Join(local, "collector"))
+	require.NoError(t, err)
+	data, err := index.Read(collector)
+	require.NoError(t, err)
+	assert.Equal(t, "collector", string(data))
+
+	pkg, err := CalculateChecksum(filepath.Join(vendor, "pkg"))
+	require.NoError(t, err)
+	data, err = index.Read(pkg)
+	require.NoError(t, err)
+	assert.Equal(t, "pkg", string(data))
+
+	_, err = index.Read("unknown")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// the replaced version is not served anymore, the new one is
+	path := filepath.Join(local, "collector")
+	require.NoError(t, os.WriteFile(path, []byte("collector v2"), 0755))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	_, err = index.Read(collector)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	v2, err := CalculateChecksum(path)
+	require.NoError(t, err)
+	data, err = index.Read(v2)
+	require.NoError(t, err)
+	assert.Equal(t, "collector v2", string(data))
+}
+
+func TestIndexReadCooldown(t *testing.T) {
+	dir := newPluginDir(t, "collector")
+	index := NewIndex([]string{dir})
+	fake := clock.NewFake(time.Now())
+	index.clock = fake
+
+	_, err := index.Read("unknown")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// a plugin added after the scan is not found by the requests during the cooldown
+	path := filepath.Join(dir, "pkg")
+	require.NoError(t, os.WriteFile(path, []byte("pkg"), 0755))
+	pkg, err := CalculateChecksum(path)
+	require.NoError(t, err)
+	for range 3 {
+		_, err = index.Read(pkg)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		fake.Advance(config.PluginIndexCooldown / 4)
+	}
+
+	fake.Advance(config.PluginIndexCooldown / 4)
+	data, err := index.Read(pkg)
+	require.NoError(t, err)
+	assert.Equal(t, "pkg", string(data))
+}
@@ -0,0 +1,118 @@
+package hcplugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/jackadi-io/jackadi/internal/config"
+)
+
+var ErrMissingDependency = errors.New("missing plugin dependency")
+var ErrDependencyCycle = errors.New("plugin dependency cycle")
+
+// manifest is the optional file describing a plugin, stored next to it (see config.PluginManifestSuffix).
+type manifest struct {
+	Requires []string `yaml:"requires"` // Plugin files which must be loaded before this plugin.
+	Env      []string `yaml:"env"`      // Environment variables (KEY=value) of the plugin process.
+}
+
+func manifestPath(path string) string {
+	return path + config.PluginManifestSuffix
+}
+
+// readManifest reads the manifest of the plugin stored in path, an empty manifest is returned if there is none.
+func readManifest(path string) (manifest, error) {
+	m := manifest{}
+	data, err := os.ReadFile(manifestPath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return m, err
+	}
+
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return m, nil
+}
+
+// installManifest moves the manifest of the candidate plugin next to the plugin path, or removes the
+// existing manifest if the candidate has none.
+func installManifest(candidatePath, path string) error {
+	err := os.Rename(manifestPath(candidatePath), manifestPath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		err = os.Remove(manifestPath(path))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+	}
+	return err
+}
+
+// sortByDependencies orders the plugin files so that each plugin comes after the plugins it requires.
+//
+// The dependencies can also be satisfied by the available plugin files, which are already loaded.
+// Plugins with a missing dependency, or part of a dependency cycle, are not returned but listed in
+// the errors, as well as the plugins depending on them.
+func sortByDependencies(files []string, requires map[string][]string, available []string) ([]string, map[string]error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	state := make(map[string]int, len(files))
+	errs := make(map[string]error)
+	sorted := make([]string, 0, len(files))
+
+	var visit func(file string, path []string) error
+	visit = func(file string, path []string) error {
+		switch state[file] {
+		case visited:
+			return errs[file]
+		case visiting:
+			cycle := append(slices.Clone(path[slices.Index(path, file):]), file)
+			err := fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " -> "))
+			for _, member := range cycle {
+				errs[member] = err
+			}
+			return err
+		}
+
+		state[file] = visiting
+		var err error
+		for _, dep := range requires[file] {
+			if !slices.Contains(files, dep) {
+				if slices.Contains(available, dep) {
+					continue
+				}
+				err = fmt.Errorf("%w: '%s' requires '%s'", ErrMissingDependency, file, dep)
+				break
+			}
+			if depErr := visit(dep, append(path, file)); depErr != nil {
+				err = fmt.Errorf("dependency '%s' not loadable: %w", dep, depErr)
+				break
+			}
+		}
+		state[file] = visited
+		if cycleErr, ok := errs[file]; ok {
+			err = cycleErr // member of a cycle
+		}
+
+		if err != nil {
+			errs[file] = err
+			return err
+		}
+		sorted = append(sorted, file)
+		return nil
+	}
+
+	for _, file := range files {
+		_ = visit(file, nil)
+	}
+	return sorted, errs
+}
@@ -0,0 +1,105 @@
+package hcplugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := readManifest(filepath.Join(dir, "cmd"))
+	require.NoError(t, err, "the manifest is optional")
+	assert.Empty(t, m.Requires)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "disk.manifest.yaml"), []byte("requires: [collector, cmd]\n"), 0600))
+	m, err = readManifest(filepath.Join(dir, "disk"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"collector", "cmd"}, m.Requires)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.manifest.yaml"), []byte("requires: {"), 0600))
+	_, err = readManifest(filepath.Join(dir, "bad"))
+	assert.Error(t, err)
+}
+
+func TestInstallManifest(t *testing.T) {
+	tmpDir := t.TempDir()
+	pluginDir := t.TempDir()
+	path := filepath.Join(pluginDir, "disk")
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "disk.manifest.yaml"), []byte("requires: [collector]\n"), 0600))
+	require.NoError(t, installManifest(filepath.Join(tmpDir, "disk"), path))
+	m, err := readManifest(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"collector"}, m.Requires)
+
+	// the new version has no manifest anymore
+	require.NoError(t, installManifest(filepath.Join(tmpDir, "disk"), path))
+	assert.NoFileExists(t, manifestPath(path))
+}
+
+func TestSortByDependencies(t *testing.T) {
+	t.Run("dependencies first", func(t *testing.T) {
+		files := []string{"disk", "cmd", "collector", "net"}
+		requires := map[string][]string{
+			"disk": {"collector"},
+			"net":  {"collector", "cmd"},
+			"cmd":  {"collector"},
+		}
+
+		sorted, errs := sortByDependencies(files, requires, nil)
+		assert.Empty(t, errs)
+		assert.Equal(t, []string{"collector", "disk", "cmd", "net"}, sorted)
+	})
+
+	t.Run("missing dependency", func(t *testing.T) {
+		files := []string{"disk", "net", "cmd"}
+		requires := map[string][]string{
+			"disk": {"collector"},
+			"net":  {"disk"},
+		}
+
+		sorted, errs := sortByDependencies(files, requires, nil)
+		assert.Equal(t, []string{"cmd"}, sorted)
+		require.Len(t, errs, 2)
+		assert.ErrorIs(t, errs["disk"], ErrMissingDependency)
+		assert.ErrorContains(t, errs["disk"], "'disk' requires 'collector'")
+		assert.ErrorIs(t, errs["net"], ErrMissingDependency, "plugins depending on a failed plugin must fail")
+	})
+
+	t.Run("dependency already loaded", func(t *testing.T) {
+		sorted, errs := sortByDependencies([]string{"disk"}, map[string][]string{"disk": {"collector"}}, []string{"collector"})
+		assert.Empty(t, errs)
+		assert.Equal(t, []string{"disk"}, sorted)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		files := []string{"a", "b", "c", "d", "cmd"}
+		requires := map[string][]string{
+			"a": {"b"},
+			"b": {"c"},
+			"c": {"a"},
+			"d": {"b"},
+		}
+
+		sorted, errs := sortByDependencies(files, requires, nil)
+		assert.Equal(t, []string{"cmd"}, sorted)
+		require.Len(t, errs, 4)
+		for _, file := range []string{"a", "b", "c"} {
+			assert.ErrorIs(t, errs[file], ErrDependencyCycle)
+			assert.ErrorContains(t, errs[file], "a -> b -> c -> a")
+		}
+		assert.ErrorIs(t, errs["d"], ErrDependencyCycle)
+		assert.ErrorContains(t, errs["d"], "dependency 'b' not loadable")
+	})
+
+	t.Run("self dependency", func(t *testing.T) {
+		sorted, errs := sortByDependencies([]string{"a"}, map[string][]string{"a": {"a"}}, nil)
+		assert.Empty(t, sorted)
+		assert.ErrorIs(t, errs["a"], ErrDependencyCycle)
+	})
+}
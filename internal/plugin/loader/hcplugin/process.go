@@ -0,0 +1,60 @@
+package hcplugin
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// ProcessConfig is the node configuration of a plugin process.
+type ProcessConfig struct {
+	Env     []string // KEY=value, overriding the variables of the plugin manifest.
+	Workdir string   // Working directory, the one of the node if empty.
+	RunAs   string   // User name or uid (Linux only), the user of the node if empty.
+}
+
+// SetProcessConfig sets the configuration of the plugin processes, by plugin file.
+func (l *Loader) SetProcessConfig(procs map[string]ProcessConfig) {
+	l.procs = procs
+}
+
+// command returns the command starting the plugin stored in path, configured by its manifest and
+// the process configuration of the node.
+//
+// A plugin with environment variables does not inherit the environment of the node, which may hold
+// credentials the plugin is not supposed to access: cmd.Env is nil otherwise.
+func (l *Loader) command(path string, m manifest) (*exec.Cmd, error) {
+	file := filepath.Base(path)
+	proc := l.procs[file]
+	cmd := exec.Command(path)
+
+	env, err := mergeEnv(m.Env, proc.Env)
+	if err != nil {
+		return nil, fmt.Errorf("invalid environment of '%s': %w", file, err)
+	}
+	cmd.Env = env
+
+	if proc.Workdir != "" {
+		fi, err := os.Stat(proc.Workdir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid workdir of '%s': %w", file, err)
+		}
+		if !fi.IsDir() {
+			return nil, fmt.Errorf("invalid workdir of '%s': '%s' is not a directory", file, proc.Workdir)
+		}
+		cmd.Dir = proc.Workdir
+	}
+
+	if proc.RunAs != "" {
+		if err := runAs(cmd, proc.RunAs); err != nil {
+			return nil, fmt.Errorf("invalid run-as of '%s': %w", file, err)
+		}
+	}
+
+	if env != nil || proc.Workdir != "" || proc.RunAs != "" {
+		slog.Info("plugin process configured", "plugin", path, "env", redactEnv(env), "workdir", proc.Workdir, "run_as", proc.RunAs)
+	}
+	return cmd, nil
+}
@@ -0,0 +1,60 @@
+package hcplugin
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// runAs makes the command run as the user, given by name or uid, with its primary and supplementary groups.
+func runAs(cmd *exec.Cmd, username string) error {
+	u, err := lookupUser(username)
+	if err != nil {
+		return err
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid uid of '%s': %w", username, err)
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid gid of '%s': %w", username, err)
+	}
+	if int(uid) == os.Geteuid() && int(gid) == os.Getegid() {
+		return nil // already the user of the node, changing the groups would require privileges
+	}
+
+	groupIDs, err := u.GroupIds()
+	if err != nil {
+		return fmt.Errorf("groups of '%s' not found: %w", username, err)
+	}
+	groups := make([]uint32, 0, len(groupIDs))
+	for _, id := range groupIDs {
+		g, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid group of '%s': %w", username, err)
+		}
+		groups = append(groups, uint32(g))
+	}
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid), Groups: groups},
+	}
+	return nil
+}
+
+// lookupUser returns the user by name, or by uid if no user has this name.
+func lookupUser(username string) (*user.User, error) {
+	u, err := user.Lookup(username)
+	if err == nil {
+		return u, nil
+	}
+	if _, convErr := strconv.Atoi(username); convErr == nil {
+		return user.LookupId(username)
+	}
+	return nil, err
+}
@@ -0,0 +1,53 @@
+package hcplugin
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPluginWorkdir(t *testing.T) {
+	dir := buildTestPlugin(t, t.TempDir())
+	workdir := t.TempDir()
+
+	loader := New()
+	loader.SetProcessConfig(map[string]ProcessConfig{"procplugin": {Workdir: workdir}})
+	loadTestPlugin(t, &loader, dir)
+
+	output, errMsg := callTestPlugin(t, "getwd")
+	assert.Empty(t, errMsg)
+	assert.Equal(t, fmt.Sprintf("%q", workdir), output)
+}
+
+func TestLoadPluginRunAs(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("running as another user requires root")
+	}
+	nobody, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skip("no 'nobody' user")
+	}
+
+	// the plugin must be reachable by nobody, unlike t.TempDir()
+	dir, err := os.MkdirTemp("", "jackadi-run-as-")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	require.NoError(t, os.Chmod(dir, 0755))
+	buildTestPlugin(t, dir)
+
+	for _, runAs := range []string{"nobody", nobody.Uid} {
+		t.Run(runAs, func(t *testing.T) {
+			loader := New()
+			loader.SetProcessConfig(map[string]ProcessConfig{"procplugin": {RunAs: runAs, Workdir: dir}})
+			loadTestPlugin(t, &loader, dir)
+
+			output, errMsg := callTestPlugin(t, "getuid")
+			assert.Empty(t, errMsg)
+			assert.Equal(t, nobody.Uid, output)
+		})
+	}
+}
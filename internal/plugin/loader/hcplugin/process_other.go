@@ -0,0 +1,12 @@
+//go:build !linux
+
+package hcplugin
+
+import (
+	"errors"
+	"os/exec"
+)
+
+func runAs(cmd *exec.Cmd, username string) error {
+	return errors.New("only supported on Linux")
+}
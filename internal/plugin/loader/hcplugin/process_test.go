@@ -0,0 +1,103 @@
+package hcplugin
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackadi-io/jackadi/internal/plugin/inventory"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// buildTestPlugin builds testdata/procplugin in dir.
+func buildTestPlugin(t *testing.T, dir string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("plugin build skipped in short mode")
+	}
+
+	out, err := exec.Command("go", "build", "-o", filepath.Join(dir, "procplugin"), "./testdata/procplugin").CombinedOutput()
+	require.NoError(t, err, "failed to build the test plugin: %s", out)
+	return dir
+}
+
+// loadTestPlugin loads the test plugin built in dir, it is unloaded at the end of the test.
+func loadTestPlugin(t *testing.T, loader *Loader, dir string) {
+	t.Helper()
+	require.NoError(t, loader.load(filepath.Join(dir, "procplugin")))
+	t.Cleanup(func() {
+		loader.KillAll()
+		_ = inventory.Registry.Unregister("proctest")
+	})
+}
+
+// callTestPlugin runs a task of the test plugin, and returns its output and error.
+func callTestPlugin(t *testing.T, task string, args ...any) (string, string) {
+	t.Helper()
+	p, err := inventory.Registry.Get("proctest")
+	require.NoError(t, err)
+
+	list, err := structpb.NewList(args)
+	require.NoError(t, err)
+	resp, err := p.Do(context.Background(), task, &proto.Input{Args: list})
+	require.NoError(t, err)
+	return string(resp.Output), resp.Error
+}
+
+func TestCommandInvalidConfig(t *testing.T) {
+	loader := New()
+	loader.SetProcessConfig(map[string]ProcessConfig{
+		"missing-dir": {Workdir: filepath.Join(t.TempDir(), "missing")},
+		"file-dir":    {Workdir: filepath.Join(newPluginDir(t, "file"), "file")},
+		"bad-user":    {RunAs: "jackadi-no-such-user"},
+		"bad-env":     {Env: []string{"NO_VALUE"}},
+	})
+
+	for _, file := range []string{"missing-dir", "file-dir", "bad-user", "bad-env"} {
+		_, err := loader.command(filepath.Join("/plugins", file), manifest{})
+		assert.Error(t, err, file)
+	}
+
+	cmd, err := loader.command("/plugins/default", manifest{})
+	require.NoError(t, err)
+	assert.Nil(t, cmd.Env)
+	assert.Empty(t, cmd.Dir)
+	assert.Nil(t, cmd.SysProcAttr)
+}
+
+func TestLoadConflictByName(t *testing.T) {
+	built := filepath.Join(buildTestPlugin(t, t.TempDir()), "procplugin")
+	binary, err := os.ReadFile(built)
+	require.NoError(t, err)
+
+	// the same plugin under two file names
+	local, vendor := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(local, "zeta"), binary, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(vendor, "alpha"), binary, 0o755))
+
+	loader := New()
+	loader.Load([]string{local, vendor})
+	t.Cleanup(func() {
+		loader.KillAll()
+		_ = inventory.Registry.Unregister("proctest")
+	})
+	assert.Equal(t, []string{"zeta"}, loader.files(), "expected the plugin of the directory with the higher precedence")
+
+	// a plugin of a directory with a lower precedence does not replace it
+	err = loader.load(filepath.Join(vendor, "alpha"))
+	require.ErrorIs(t, err, ErrPluginConflict)
+	assert.Equal(t, []string{"zeta"}, loader.files())
+
+	// a plugin of a directory with a higher precedence replaces it, e.g. loaded after as a dependency
+	loader.unload(filepath.Join(local, "zeta"))
+	require.NoError(t, loader.load(filepath.Join(vendor, "alpha")))
+	require.NoError(t, loader.load(filepath.Join(local, "zeta")))
+	assert.Equal(t, []string{"zeta"}, loader.files())
+	out, _ := callTestPlugin(t, "getwd")
+	assert.NotEmpty(t, out, "expected the plugin to stay registered")
+}
@@ -0,0 +1,33 @@
+// procplugin exposes the properties of its process, to test the plugin process configuration.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jackadi-io/jackadi/sdk"
+)
+
+func getenv(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("'%s' not set", name)
+	}
+	return value, nil
+}
+
+func getwd() (string, error) {
+	return os.Getwd()
+}
+
+func getuid() (int, error) {
+	return os.Getuid(), nil
+}
+
+func main() {
+	plugin := sdk.New("proctest")
+	plugin.MustRegisterTask("getenv", getenv)
+	plugin.MustRegisterTask("getwd", getwd)
+	plugin.MustRegisterTask("getuid", getuid)
+	sdk.MustServe(plugin)
+}
@@ -6,6 +6,7 @@ type PluginChanges struct {
 	New      bool
 	Updated  bool
 	Deleted  bool
+	Rejected bool // Not loaded: not in the allowed plugins of the node.
 }
 
 type PluginUpdateResponse struct {
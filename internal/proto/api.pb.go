@@ -10,7 +10,7 @@ import (
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
-	_ "google.golang.org/protobuf/types/known/emptypb"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -121,12 +121,13 @@ func (x *ListNodesRequest) GetFilter() Filter {
 }
 
 type ListNodesResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Accepted      []*NodeInfo            `protobuf:"bytes,1,rep,name=accepted,proto3" json:"accepted,omitempty"`
-	Candidates    []*NodeInfo            `protobuf:"bytes,2,rep,name=candidates,proto3" json:"candidates,omitempty"`
-	Rejected      []*NodeInfo            `protobuf:"bytes,3,rep,name=rejected,proto3" json:"rejected,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Accepted       []*NodeInfo            `protobuf:"bytes,1,rep,name=accepted,proto3" json:"accepted,omitempty"`
+	Candidates     []*NodeInfo            `protobuf:"bytes,2,rep,name=candidates,proto3" json:"candidates,omitempty"`
+	Rejected       []*NodeInfo            `protobuf:"bytes,3,rep,name=rejected,proto3" json:"rejected,omitempty"`
+	ManagerVersion string                 `protobuf:"bytes,4,opt,name=manager_version,json=managerVersion,proto3" json:"manager_version,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *ListNodesResponse) Reset() {
@@ -180,6 +181,13 @@ func (x *ListNodesResponse) GetRejected() []*NodeInfo {
 	return nil
 }
 
+func (x *ListNodesResponse) GetManagerVersion() string {
+	if x != nil {
+		return x.ManagerVersion
+	}
+	return ""
+}
+
 type NodeInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -188,6 +196,9 @@ type NodeInfo struct {
 	IsConnected   *bool                  `protobuf:"varint,4,opt,name=isConnected,proto3,oneof" json:"isConnected,omitempty"`
 	Since         *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=since,proto3,oneof" json:"since,omitempty"`
 	LastMsg       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=lastMsg,proto3,oneof" json:"lastMsg,omitempty"`
+	Version       *string                `protobuf:"bytes,8,opt,name=version,proto3,oneof" json:"version,omitempty"`          // Build version sent by the node during the handshake
+	Connections   *int64                 `protobuf:"varint,9,opt,name=connections,proto3,oneof" json:"connections,omitempty"` // Connections since the manager start, a node reconnecting often is flapping
+	Uptime        *int64                 `protobuf:"varint,10,opt,name=uptime,proto3,oneof" json:"uptime,omitempty"`          // Seconds since the node connected, zero if disconnected
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -264,6 +275,27 @@ func (x *NodeInfo) GetLastMsg() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *NodeInfo) GetVersion() string {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return ""
+}
+
+func (x *NodeInfo) GetConnections() int64 {
+	if x != nil && x.Connections != nil {
+		return *x.Connections
+	}
+	return 0
+}
+
+func (x *NodeInfo) GetUptime() int64 {
+	if x != nil && x.Uptime != nil {
+		return *x.Uptime
+	}
+	return 0
+}
+
 type NodeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Node          *NodeInfo              `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
@@ -396,27 +428,29 @@ func (x *NodesResponse) GetNodes() []*NodeInfo {
 	return nil
 }
 
-type ResultsRequest struct {
+type NodeStateEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ResultID      string                 `protobuf:"bytes,1,opt,name=resultID,proto3" json:"resultID,omitempty"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // node_connected, node_disconnected, node_stale, node_active, specs_drift, node_auto_accepted or node_candidate
+	Node          string                 `protobuf:"bytes,2,opt,name=node,proto3" json:"node,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ResultsRequest) Reset() {
-	*x = ResultsRequest{}
+func (x *NodeStateEvent) Reset() {
+	*x = NodeStateEvent{}
 	mi := &file_internal_proto_api_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ResultsRequest) String() string {
+func (x *NodeStateEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ResultsRequest) ProtoMessage() {}
+func (*NodeStateEvent) ProtoMessage() {}
 
-func (x *ResultsRequest) ProtoReflect() protoreflect.Message {
+func (x *NodeStateEvent) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_api_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -428,39 +462,53 @@ func (x *ResultsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ResultsRequest.ProtoReflect.Descriptor instead.
-func (*ResultsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use NodeStateEvent.ProtoReflect.Descriptor instead.
+func (*NodeStateEvent) Descriptor() ([]byte, []int) {
 	return file_internal_proto_api_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *ResultsRequest) GetResultID() string {
+func (x *NodeStateEvent) GetType() string {
 	if x != nil {
-		return x.ResultID
+		return x.Type
 	}
 	return ""
 }
 
-type ResultsResponse struct {
+func (x *NodeStateEvent) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *NodeStateEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+type SpecsDriftsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"` // raw result, as stored in the DB (should be a serialized JSON)
+	Node          string                 `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ResultsResponse) Reset() {
-	*x = ResultsResponse{}
+func (x *SpecsDriftsRequest) Reset() {
+	*x = SpecsDriftsRequest{}
 	mi := &file_internal_proto_api_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ResultsResponse) String() string {
+func (x *SpecsDriftsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ResultsResponse) ProtoMessage() {}
+func (*SpecsDriftsRequest) ProtoMessage() {}
 
-func (x *ResultsResponse) ProtoReflect() protoreflect.Message {
+func (x *SpecsDriftsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_api_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -472,39 +520,42 @@ func (x *ResultsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ResultsResponse.ProtoReflect.Descriptor instead.
-func (*ResultsResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use SpecsDriftsRequest.ProtoReflect.Descriptor instead.
+func (*SpecsDriftsRequest) Descriptor() ([]byte, []int) {
 	return file_internal_proto_api_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ResultsResponse) GetResult() string {
+func (x *SpecsDriftsRequest) GetNode() string {
 	if x != nil {
-		return x.Result
+		return x.Node
 	}
 	return ""
 }
 
-type RequestRequest struct {
+type SpecChange struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	RequestID     string                 `protobuf:"bytes,1,opt,name=requestID,proto3" json:"requestID,omitempty"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // added, removed or changed
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"` // Dot path of the spec leaf (e.g. software.nginx.version)
+	Old           []byte                 `protobuf:"bytes,3,opt,name=old,proto3" json:"old,omitempty"`   // JSON, unset if added
+	New           []byte                 `protobuf:"bytes,4,opt,name=new,proto3" json:"new,omitempty"`   // JSON, unset if removed
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RequestRequest) Reset() {
-	*x = RequestRequest{}
+func (x *SpecChange) Reset() {
+	*x = SpecChange{}
 	mi := &file_internal_proto_api_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RequestRequest) String() string {
+func (x *SpecChange) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RequestRequest) ProtoMessage() {}
+func (*SpecChange) ProtoMessage() {}
 
-func (x *RequestRequest) ProtoReflect() protoreflect.Message {
+func (x *SpecChange) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_api_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -516,39 +567,61 @@ func (x *RequestRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RequestRequest.ProtoReflect.Descriptor instead.
-func (*RequestRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SpecChange.ProtoReflect.Descriptor instead.
+func (*SpecChange) Descriptor() ([]byte, []int) {
 	return file_internal_proto_api_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *RequestRequest) GetRequestID() string {
+func (x *SpecChange) GetType() string {
 	if x != nil {
-		return x.RequestID
+		return x.Type
 	}
 	return ""
 }
 
-type RequestResponse struct {
+func (x *SpecChange) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SpecChange) GetOld() []byte {
+	if x != nil {
+		return x.Old
+	}
+	return nil
+}
+
+func (x *SpecChange) GetNew() []byte {
+	if x != nil {
+		return x.New
+	}
+	return nil
+}
+
+type SpecsDrift struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Request       string                 `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"` // raw request, as stored in the DB (should be a serialized JSON)
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Changes       []*SpecChange          `protobuf:"bytes,2,rep,name=changes,proto3" json:"changes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RequestResponse) Reset() {
-	*x = RequestResponse{}
+func (x *SpecsDrift) Reset() {
+	*x = SpecsDrift{}
 	mi := &file_internal_proto_api_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RequestResponse) String() string {
+func (x *SpecsDrift) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RequestResponse) ProtoMessage() {}
+func (*SpecsDrift) ProtoMessage() {}
 
-func (x *RequestResponse) ProtoReflect() protoreflect.Message {
+func (x *SpecsDrift) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_api_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -560,43 +633,46 @@ func (x *RequestResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RequestResponse.ProtoReflect.Descriptor instead.
-func (*RequestResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use SpecsDrift.ProtoReflect.Descriptor instead.
+func (*SpecsDrift) Descriptor() ([]byte, []int) {
 	return file_internal_proto_api_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *RequestResponse) GetRequest() string {
+func (x *SpecsDrift) GetTime() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Request
+		return x.Time
 	}
-	return ""
+	return nil
 }
 
-type ListResultsRequest struct {
+func (x *SpecsDrift) GetChanges() []*SpecChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type SpecsDriftsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Offset        int32                  `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`                           // Starting position for pagination (0-based)
-	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                             // Maximum number of results to return, defaults to 100 if not specified
-	FromDate      *int64                 `protobuf:"varint,3,opt,name=from_date,json=fromDate,proto3,oneof" json:"from_date,omitempty"` // Optional Unix timestamp to filter results from this date
-	ToDate        *int64                 `protobuf:"varint,4,opt,name=to_date,json=toDate,proto3,oneof" json:"to_date,omitempty"`       // Optional Unix timestamp to filter results up to this date
-	Targets       []string               `protobuf:"bytes,5,rep,name=targets,proto3" json:"targets,omitempty"`                          // Optional list of node IDs to filter results by targets
+	Drifts        []*SpecsDrift          `protobuf:"bytes,1,rep,name=drifts,proto3" json:"drifts,omitempty"` // The oldest first
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListResultsRequest) Reset() {
-	*x = ListResultsRequest{}
+func (x *SpecsDriftsResponse) Reset() {
+	*x = SpecsDriftsResponse{}
 	mi := &file_internal_proto_api_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListResultsRequest) String() string {
+func (x *SpecsDriftsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListResultsRequest) ProtoMessage() {}
+func (*SpecsDriftsResponse) ProtoMessage() {}
 
-func (x *ListResultsRequest) ProtoReflect() protoreflect.Message {
+func (x *SpecsDriftsResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_api_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -608,72 +684,84 @@ func (x *ListResultsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListResultsRequest.ProtoReflect.Descriptor instead.
-func (*ListResultsRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SpecsDriftsResponse.ProtoReflect.Descriptor instead.
+func (*SpecsDriftsResponse) Descriptor() ([]byte, []int) {
 	return file_internal_proto_api_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *ListResultsRequest) GetOffset() int32 {
+func (x *SpecsDriftsResponse) GetDrifts() []*SpecsDrift {
 	if x != nil {
-		return x.Offset
+		return x.Drifts
 	}
-	return 0
+	return nil
 }
 
-func (x *ListResultsRequest) GetLimit() int32 {
-	if x != nil {
-		return x.Limit
-	}
-	return 0
+type PluginSyncsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          string                 `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListResultsRequest) GetFromDate() int64 {
-	if x != nil && x.FromDate != nil {
-		return *x.FromDate
-	}
-	return 0
+func (x *PluginSyncsRequest) Reset() {
+	*x = PluginSyncsRequest{}
+	mi := &file_internal_proto_api_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListResultsRequest) GetToDate() int64 {
-	if x != nil && x.ToDate != nil {
-		return *x.ToDate
+func (x *PluginSyncsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PluginSyncsRequest) ProtoMessage() {}
+
+func (x *PluginSyncsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *ListResultsRequest) GetTargets() []string {
+// Deprecated: Use PluginSyncsRequest.ProtoReflect.Descriptor instead.
+func (*PluginSyncsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PluginSyncsRequest) GetNode() string {
 	if x != nil {
-		return x.Targets
+		return x.Node
 	}
-	return nil
+	return ""
 }
 
-type ResultEntry struct {
+type PluginSyncsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Node          string                 `protobuf:"bytes,2,opt,name=node,proto3" json:"node,omitempty"`
-	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"` // Status of the task (success, failed, error)
-	InternalError InternalError          `protobuf:"varint,4,opt,name=internal_error,json=internalError,proto3,enum=proto.InternalError" json:"internal_error,omitempty"`
-	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Syncs         []*PluginSync          `protobuf:"bytes,1,rep,name=syncs,proto3" json:"syncs,omitempty"` // The oldest first
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ResultEntry) Reset() {
-	*x = ResultEntry{}
-	mi := &file_internal_proto_api_proto_msgTypes[11]
+func (x *PluginSyncsResponse) Reset() {
+	*x = PluginSyncsResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ResultEntry) String() string {
+func (x *PluginSyncsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ResultEntry) ProtoMessage() {}
+func (*PluginSyncsResponse) ProtoMessage() {}
 
-func (x *ResultEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_api_proto_msgTypes[11]
+func (x *PluginSyncsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -684,68 +772,84 @@ func (x *ResultEntry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ResultEntry.ProtoReflect.Descriptor instead.
-func (*ResultEntry) Descriptor() ([]byte, []int) {
-	return file_internal_proto_api_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use PluginSyncsResponse.ProtoReflect.Descriptor instead.
+func (*PluginSyncsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *ResultEntry) GetId() int64 {
+func (x *PluginSyncsResponse) GetSyncs() []*PluginSync {
 	if x != nil {
-		return x.Id
+		return x.Syncs
 	}
-	return 0
+	return nil
 }
 
-func (x *ResultEntry) GetNode() string {
-	if x != nil {
-		return x.Node
-	}
-	return ""
+type ResultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ResultID      string                 `protobuf:"bytes,1,opt,name=resultID,proto3" json:"resultID,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ResultEntry) GetStatus() string {
-	if x != nil {
-		return x.Status
-	}
-	return ""
+func (x *ResultsRequest) Reset() {
+	*x = ResultsRequest{}
+	mi := &file_internal_proto_api_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *ResultEntry) GetInternalError() InternalError {
+func (x *ResultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultsRequest) ProtoMessage() {}
+
+func (x *ResultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[13]
 	if x != nil {
-		return x.InternalError
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return InternalError_OK
+	return mi.MessageOf(x)
 }
 
-func (x *ResultEntry) GetError() string {
+// Deprecated: Use ResultsRequest.ProtoReflect.Descriptor instead.
+func (*ResultsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ResultsRequest) GetResultID() string {
 	if x != nil {
-		return x.Error
+		return x.ResultID
 	}
 	return ""
 }
 
-type ListResultsResponse struct {
+type ResultsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Results       []*ResultEntry         `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"` // raw result, as stored in the DB (should be a serialized JSON)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListResultsResponse) Reset() {
-	*x = ListResultsResponse{}
-	mi := &file_internal_proto_api_proto_msgTypes[12]
+func (x *ResultsResponse) Reset() {
+	*x = ResultsResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListResultsResponse) String() string {
+func (x *ResultsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListResultsResponse) ProtoMessage() {}
+func (*ResultsResponse) ProtoMessage() {}
 
-func (x *ListResultsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_api_proto_msgTypes[12]
+func (x *ResultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -756,51 +860,1327 @@ func (x *ListResultsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListResultsResponse.ProtoReflect.Descriptor instead.
-func (*ListResultsResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_api_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use ResultsResponse.ProtoReflect.Descriptor instead.
+func (*ResultsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *ListResultsResponse) GetResults() []*ResultEntry {
+func (x *ResultsResponse) GetResult() string {
 	if x != nil {
-		return x.Results
+		return x.Result
 	}
-	return nil
+	return ""
 }
 
-var File_internal_proto_api_proto protoreflect.FileDescriptor
+type RequestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestID     string                 `protobuf:"bytes,1,opt,name=requestID,proto3" json:"requestID,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_internal_proto_api_proto_rawDesc = "" +
-	"\n" +
-	"\x18internal/proto/api.proto\x12\x05proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cinternal/proto/cluster.proto\"9\n" +
-	"\x10ListNodesRequest\x12%\n" +
-	"\x06filter\x18\x01 \x01(\x0e2\r.proto.FilterR\x06filter\"\x9e\x01\n" +
-	"\x11ListNodesResponse\x12+\n" +
+func (x *RequestRequest) Reset() {
+	*x = RequestRequest{}
+	mi := &file_internal_proto_api_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestRequest) ProtoMessage() {}
+
+func (x *RequestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestRequest.ProtoReflect.Descriptor instead.
+func (*RequestRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RequestRequest) GetRequestID() string {
+	if x != nil {
+		return x.RequestID
+	}
+	return ""
+}
+
+type RequestResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Request       string                 `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"` // raw request, as stored in the DB (should be a serialized JSON)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestResponse) Reset() {
+	*x = RequestResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestResponse) ProtoMessage() {}
+
+func (x *RequestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestResponse.ProtoReflect.Descriptor instead.
+func (*RequestResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RequestResponse) GetRequest() string {
+	if x != nil {
+		return x.Request
+	}
+	return ""
+}
+
+type ListResultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offset        int32                  `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`                                                                              // Starting position for pagination (0-based)
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                                                                                // Maximum number of results to return, defaults to 100 if not specified
+	FromDate      *int64                 `protobuf:"varint,3,opt,name=from_date,json=fromDate,proto3,oneof" json:"from_date,omitempty"`                                                    // Optional Unix timestamp to filter results from this date
+	ToDate        *int64                 `protobuf:"varint,4,opt,name=to_date,json=toDate,proto3,oneof" json:"to_date,omitempty"`                                                          // Optional Unix timestamp to filter results up to this date
+	Targets       []string               `protobuf:"bytes,5,rep,name=targets,proto3" json:"targets,omitempty"`                                                                             // Optional list of node IDs to filter results by targets
+	Metadata      map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Optional metadata the requests of the results must have
+	Statuses      []string               `protobuf:"bytes,7,rep,name=statuses,proto3" json:"statuses,omitempty"`                                                                           // Optional statuses of the results (success, error, timeout, internal error or unknown), the groups are not listed then
+	Retcode       *int32                 `protobuf:"varint,8,opt,name=retcode,proto3,oneof" json:"retcode,omitempty"`                                                                      // Optional return code of the results, the groups are not listed then
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResultsRequest) Reset() {
+	*x = ListResultsRequest{}
+	mi := &file_internal_proto_api_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResultsRequest) ProtoMessage() {}
+
+func (x *ListResultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResultsRequest.ProtoReflect.Descriptor instead.
+func (*ListResultsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListResultsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListResultsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListResultsRequest) GetFromDate() int64 {
+	if x != nil && x.FromDate != nil {
+		return *x.FromDate
+	}
+	return 0
+}
+
+func (x *ListResultsRequest) GetToDate() int64 {
+	if x != nil && x.ToDate != nil {
+		return *x.ToDate
+	}
+	return 0
+}
+
+func (x *ListResultsRequest) GetTargets() []string {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *ListResultsRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *ListResultsRequest) GetStatuses() []string {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListResultsRequest) GetRetcode() int32 {
+	if x != nil && x.Retcode != nil {
+		return *x.Retcode
+	}
+	return 0
+}
+
+type ResultEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Node          string                 `protobuf:"bytes,2,opt,name=node,proto3" json:"node,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"` // Status of the task (success, failed, error)
+	InternalError InternalError          `protobuf:"varint,4,opt,name=internal_error,json=internalError,proto3,enum=proto.InternalError" json:"internal_error,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Retcode       int32                  `protobuf:"varint,6,opt,name=retcode,proto3" json:"retcode,omitempty"`
+	Task          string                 `protobuf:"bytes,7,opt,name=task,proto3" json:"task,omitempty"`                                                                                   // Full name of the requested task, e.g. cmd.run, empty if the request is unknown
+	Metadata      map[string]string      `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Metadata of the request
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResultEntry) Reset() {
+	*x = ResultEntry{}
+	mi := &file_internal_proto_api_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResultEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultEntry) ProtoMessage() {}
+
+func (x *ResultEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultEntry.ProtoReflect.Descriptor instead.
+func (*ResultEntry) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ResultEntry) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ResultEntry) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *ResultEntry) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ResultEntry) GetInternalError() InternalError {
+	if x != nil {
+		return x.InternalError
+	}
+	return InternalError_OK
+}
+
+func (x *ResultEntry) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ResultEntry) GetRetcode() int32 {
+	if x != nil {
+		return x.Retcode
+	}
+	return 0
+}
+
+func (x *ResultEntry) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+func (x *ResultEntry) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ListResultsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*ResultEntry         `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResultsResponse) Reset() {
+	*x = ListResultsResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResultsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResultsResponse) ProtoMessage() {}
+
+func (x *ListResultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResultsResponse.ProtoReflect.Descriptor instead.
+func (*ListResultsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListResultsResponse) GetResults() []*ResultEntry {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ResultsStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromDate      *int64                 `protobuf:"varint,1,opt,name=from_date,json=fromDate,proto3,oneof" json:"from_date,omitempty"`                                                    // Optional Unix timestamp to count results from this date
+	ToDate        *int64                 `protobuf:"varint,2,opt,name=to_date,json=toDate,proto3,oneof" json:"to_date,omitempty"`                                                          // Optional Unix timestamp to count results up to this date
+	Targets       []string               `protobuf:"bytes,3,rep,name=targets,proto3" json:"targets,omitempty"`                                                                             // Optional list of node IDs to count results of
+	Metadata      map[string]string      `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Optional metadata the requests of the results must have
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResultsStatsRequest) Reset() {
+	*x = ResultsStatsRequest{}
+	mi := &file_internal_proto_api_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResultsStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultsStatsRequest) ProtoMessage() {}
+
+func (x *ResultsStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultsStatsRequest.ProtoReflect.Descriptor instead.
+func (*ResultsStatsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ResultsStatsRequest) GetFromDate() int64 {
+	if x != nil && x.FromDate != nil {
+		return *x.FromDate
+	}
+	return 0
+}
+
+func (x *ResultsStatsRequest) GetToDate() int64 {
+	if x != nil && x.ToDate != nil {
+		return *x.ToDate
+	}
+	return 0
+}
+
+func (x *ResultsStatsRequest) GetTargets() []string {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *ResultsStatsRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// ResultsCounts are the numbers of results by status, the statuses being exclusive.
+type ResultsCounts struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         int64                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Success       int64                  `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Error         int64                  `protobuf:"varint,3,opt,name=error,proto3" json:"error,omitempty"` // Error returned by the task
+	Timeout       int64                  `protobuf:"varint,4,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	InternalError int64                  `protobuf:"varint,5,opt,name=internal_error,json=internalError,proto3" json:"internal_error,omitempty"` // Failure of Jackadi to run the task, besides the timeouts
+	Unknown       int64                  `protobuf:"varint,6,opt,name=unknown,proto3" json:"unknown,omitempty"`                                  // Unreadable result
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResultsCounts) Reset() {
+	*x = ResultsCounts{}
+	mi := &file_internal_proto_api_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResultsCounts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultsCounts) ProtoMessage() {}
+
+func (x *ResultsCounts) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultsCounts.ProtoReflect.Descriptor instead.
+func (*ResultsCounts) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ResultsCounts) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ResultsCounts) GetSuccess() int64 {
+	if x != nil {
+		return x.Success
+	}
+	return 0
+}
+
+func (x *ResultsCounts) GetError() int64 {
+	if x != nil {
+		return x.Error
+	}
+	return 0
+}
+
+func (x *ResultsCounts) GetTimeout() int64 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *ResultsCounts) GetInternalError() int64 {
+	if x != nil {
+		return x.InternalError
+	}
+	return 0
+}
+
+func (x *ResultsCounts) GetUnknown() int64 {
+	if x != nil {
+		return x.Unknown
+	}
+	return 0
+}
+
+type ResultsStatsResponse struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	All           *ResultsCounts            `protobuf:"bytes,1,opt,name=all,proto3" json:"all,omitempty"`
+	Plugins       map[string]*ResultsCounts `protobuf:"bytes,2,rep,name=plugins,proto3" json:"plugins,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // key=plugin name, empty if the request is unknown
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResultsStatsResponse) Reset() {
+	*x = ResultsStatsResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResultsStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultsStatsResponse) ProtoMessage() {}
+
+func (x *ResultsStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultsStatsResponse.ProtoReflect.Descriptor instead.
+func (*ResultsStatsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ResultsStatsResponse) GetAll() *ResultsCounts {
+	if x != nil {
+		return x.All
+	}
+	return nil
+}
+
+func (x *ResultsStatsResponse) GetPlugins() map[string]*ResultsCounts {
+	if x != nil {
+		return x.Plugins
+	}
+	return nil
+}
+
+type DeleteResultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []int64                `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`                          // Results to delete (a group ID deletes the whole group), other filters are ignored if set
+	FromDate      *int64                 `protobuf:"varint,2,opt,name=from_date,json=fromDate,proto3,oneof" json:"from_date,omitempty"` // Optional Unix timestamp to delete results from this date
+	ToDate        *int64                 `protobuf:"varint,3,opt,name=to_date,json=toDate,proto3,oneof" json:"to_date,omitempty"`       // Optional Unix timestamp to delete results up to this date
+	Targets       []string               `protobuf:"bytes,4,rep,name=targets,proto3" json:"targets,omitempty"`                          // Optional list of node IDs to delete results of
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResultsRequest) Reset() {
+	*x = DeleteResultsRequest{}
+	mi := &file_internal_proto_api_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResultsRequest) ProtoMessage() {}
+
+func (x *DeleteResultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResultsRequest.ProtoReflect.Descriptor instead.
+func (*DeleteResultsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DeleteResultsRequest) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *DeleteResultsRequest) GetFromDate() int64 {
+	if x != nil && x.FromDate != nil {
+		return *x.FromDate
+	}
+	return 0
+}
+
+func (x *DeleteResultsRequest) GetToDate() int64 {
+	if x != nil && x.ToDate != nil {
+		return *x.ToDate
+	}
+	return 0
+}
+
+func (x *DeleteResultsRequest) GetTargets() []string {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+type DeleteResultsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deleted       []int64                `protobuf:"varint,1,rep,packed,name=deleted,proto3" json:"deleted,omitempty"` // IDs of the deleted results
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResultsResponse) Reset() {
+	*x = DeleteResultsResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResultsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResultsResponse) ProtoMessage() {}
+
+func (x *DeleteResultsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResultsResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResultsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *DeleteResultsResponse) GetDeleted() []int64 {
+	if x != nil {
+		return x.Deleted
+	}
+	return nil
+}
+
+type BackupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Since         uint64                 `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"` // Only entries more recent than this version are backed up (incremental backup), full backup if 0
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupRequest) Reset() {
+	*x = BackupRequest{}
+	mi := &file_internal_proto_api_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupRequest) ProtoMessage() {}
+
+func (x *BackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupRequest.ProtoReflect.Descriptor instead.
+func (*BackupRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *BackupRequest) GetSince() uint64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+type BackupChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"` // Part of the backup, in Badger backup format
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupChunk) Reset() {
+	*x = BackupChunk{}
+	mi := &file_internal_proto_api_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupChunk) ProtoMessage() {}
+
+func (x *BackupChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupChunk.ProtoReflect.Descriptor instead.
+func (*BackupChunk) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *BackupChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type RestoreChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`    // Part of the backup, in Badger backup format
+	Force         bool                   `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"` // Replace the content of a non-empty database, only read from the first chunk
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreChunk) Reset() {
+	*x = RestoreChunk{}
+	mi := &file_internal_proto_api_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreChunk) ProtoMessage() {}
+
+func (x *RestoreChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreChunk.ProtoReflect.Descriptor instead.
+func (*RestoreChunk) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *RestoreChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *RestoreChunk) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type RestoreResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreResponse) Reset() {
+	*x = RestoreResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreResponse) ProtoMessage() {}
+
+func (x *RestoreResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreResponse.ProtoReflect.Descriptor instead.
+func (*RestoreResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{28}
+}
+
+type DatabaseStatsResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	LsmSize         int64                  `protobuf:"varint,1,opt,name=lsm_size,json=lsmSize,proto3" json:"lsm_size,omitempty"`                           // Size of the LSM tree, in bytes
+	VlogSize        int64                  `protobuf:"varint,2,opt,name=vlog_size,json=vlogSize,proto3" json:"vlog_size,omitempty"`                        // Size of the value log, in bytes
+	Keys            uint64                 `protobuf:"varint,3,opt,name=keys,proto3" json:"keys,omitempty"`                                                // Estimated number of keys, entries not yet flushed to disk are not counted
+	LastGc          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_gc,json=lastGc,proto3,oneof" json:"last_gc,omitempty"`                         // Time of the last value log garbage collection, unset if none ran yet
+	LastGcReclaimed int64                  `protobuf:"varint,5,opt,name=last_gc_reclaimed,json=lastGcReclaimed,proto3" json:"last_gc_reclaimed,omitempty"` // Bytes reclaimed by the last garbage collection, over all its passes
+	LastGcError     string                 `protobuf:"bytes,6,opt,name=last_gc_error,json=lastGcError,proto3" json:"last_gc_error,omitempty"`              // Error of the last garbage collection, empty if it completed
+	LastGcRewrites  uint32                 `protobuf:"varint,7,opt,name=last_gc_rewrites,json=lastGcRewrites,proto3" json:"last_gc_rewrites,omitempty"`    // Value log files rewritten by the last garbage collection
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DatabaseStatsResponse) Reset() {
+	*x = DatabaseStatsResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DatabaseStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DatabaseStatsResponse) ProtoMessage() {}
+
+func (x *DatabaseStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DatabaseStatsResponse.ProtoReflect.Descriptor instead.
+func (*DatabaseStatsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *DatabaseStatsResponse) GetLsmSize() int64 {
+	if x != nil {
+		return x.LsmSize
+	}
+	return 0
+}
+
+func (x *DatabaseStatsResponse) GetVlogSize() int64 {
+	if x != nil {
+		return x.VlogSize
+	}
+	return 0
+}
+
+func (x *DatabaseStatsResponse) GetKeys() uint64 {
+	if x != nil {
+		return x.Keys
+	}
+	return 0
+}
+
+func (x *DatabaseStatsResponse) GetLastGc() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastGc
+	}
+	return nil
+}
+
+func (x *DatabaseStatsResponse) GetLastGcReclaimed() int64 {
+	if x != nil {
+		return x.LastGcReclaimed
+	}
+	return 0
+}
+
+func (x *DatabaseStatsResponse) GetLastGcError() string {
+	if x != nil {
+		return x.LastGcError
+	}
+	return ""
+}
+
+func (x *DatabaseStatsResponse) GetLastGcRewrites() uint32 {
+	if x != nil {
+		return x.LastGcRewrites
+	}
+	return 0
+}
+
+type ServerInfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"` // Build version of the manager
+	Commit        string                 `protobuf:"bytes,2,opt,name=commit,proto3" json:"commit,omitempty"`
+	BuildDate     string                 `protobuf:"bytes,3,opt,name=build_date,json=buildDate,proto3" json:"build_date,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	Uptime        int64                  `protobuf:"varint,5,opt,name=uptime,proto3" json:"uptime,omitempty"` // In seconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerInfoResponse) Reset() {
+	*x = ServerInfoResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerInfoResponse) ProtoMessage() {}
+
+func (x *ServerInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerInfoResponse.ProtoReflect.Descriptor instead.
+func (*ServerInfoResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ServerInfoResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ServerInfoResponse) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *ServerInfoResponse) GetBuildDate() string {
+	if x != nil {
+		return x.BuildDate
+	}
+	return ""
+}
+
+func (x *ServerInfoResponse) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *ServerInfoResponse) GetUptime() int64 {
+	if x != nil {
+		return x.Uptime
+	}
+	return 0
+}
+
+type LockStatsResponse struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Nodes         map[string]*NodeLockStats `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Lock contention of each node since the manager started
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockStatsResponse) Reset() {
+	*x = LockStatsResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockStatsResponse) ProtoMessage() {}
+
+func (x *LockStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockStatsResponse.ProtoReflect.Descriptor instead.
+func (*LockStatsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *LockStatsResponse) GetNodes() map[string]*NodeLockStats {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type NodeLockStats struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Modes         map[string]*LockWaitStats `protobuf:"bytes,1,rep,name=modes,proto3" json:"modes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Time waited by the tasks for their lock, by lock mode (NO_LOCK, WRITE, EXCLUSIVE)
+	Conflicts     uint64                    `protobuf:"varint,2,opt,name=conflicts,proto3" json:"conflicts,omitempty"`                                                                  // Tasks sent while a task with a conflicting lock mode was in flight on the node
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeLockStats) Reset() {
+	*x = NodeLockStats{}
+	mi := &file_internal_proto_api_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeLockStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeLockStats) ProtoMessage() {}
+
+func (x *NodeLockStats) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeLockStats.ProtoReflect.Descriptor instead.
+func (*NodeLockStats) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *NodeLockStats) GetModes() map[string]*LockWaitStats {
+	if x != nil {
+		return x.Modes
+	}
+	return nil
+}
+
+func (x *NodeLockStats) GetConflicts() uint64 {
+	if x != nil {
+		return x.Conflicts
+	}
+	return 0
+}
+
+type LockWaitStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         uint64                 `protobuf:"varint,1,opt,name=tasks,proto3" json:"tasks,omitempty"`                          // Tasks which reported their lock wait
+	Contended     uint64                 `protobuf:"varint,2,opt,name=contended,proto3" json:"contended,omitempty"`                  // Tasks which waited for their lock longer than the contention threshold
+	TotalWait     int64                  `protobuf:"varint,3,opt,name=total_wait,json=totalWait,proto3" json:"total_wait,omitempty"` // In milliseconds
+	MaxWait       int64                  `protobuf:"varint,4,opt,name=max_wait,json=maxWait,proto3" json:"max_wait,omitempty"`       // In milliseconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LockWaitStats) Reset() {
+	*x = LockWaitStats{}
+	mi := &file_internal_proto_api_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LockWaitStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LockWaitStats) ProtoMessage() {}
+
+func (x *LockWaitStats) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LockWaitStats.ProtoReflect.Descriptor instead.
+func (*LockWaitStats) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *LockWaitStats) GetTasks() uint64 {
+	if x != nil {
+		return x.Tasks
+	}
+	return 0
+}
+
+func (x *LockWaitStats) GetContended() uint64 {
+	if x != nil {
+		return x.Contended
+	}
+	return 0
+}
+
+func (x *LockWaitStats) GetTotalWait() int64 {
+	if x != nil {
+		return x.TotalWait
+	}
+	return 0
+}
+
+func (x *LockWaitStats) GetMaxWait() int64 {
+	if x != nil {
+		return x.MaxWait
+	}
+	return 0
+}
+
+type RunningTasksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          string                 `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"` // Only the tasks of this plugin.task if set
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunningTasksRequest) Reset() {
+	*x = RunningTasksRequest{}
+	mi := &file_internal_proto_api_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunningTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunningTasksRequest) ProtoMessage() {}
+
+func (x *RunningTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunningTasksRequest.ProtoReflect.Descriptor instead.
+func (*RunningTasksRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RunningTasksRequest) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+type RunningTasksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*RunningTask         `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"` // Sorted by elapsed time, the longest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunningTasksResponse) Reset() {
+	*x = RunningTasksResponse{}
+	mi := &file_internal_proto_api_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunningTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunningTasksResponse) ProtoMessage() {}
+
+func (x *RunningTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_api_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunningTasksResponse.ProtoReflect.Descriptor instead.
+func (*RunningTasksResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_api_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *RunningTasksResponse) GetTasks() []*RunningTask {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+var File_internal_proto_api_proto protoreflect.FileDescriptor
+
+const file_internal_proto_api_proto_rawDesc = "" +
+	"\n" +
+	"\x18internal/proto/api.proto\x12\x05proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1cinternal/proto/cluster.proto\"9\n" +
+	"\x10ListNodesRequest\x12%\n" +
+	"\x06filter\x18\x01 \x01(\x0e2\r.proto.FilterR\x06filter\"\xc7\x01\n" +
+	"\x11ListNodesResponse\x12+\n" +
 	"\baccepted\x18\x01 \x03(\v2\x0f.proto.NodeInfoR\baccepted\x12/\n" +
 	"\n" +
 	"candidates\x18\x02 \x03(\v2\x0f.proto.NodeInfoR\n" +
 	"candidates\x12+\n" +
-	"\brejected\x18\x03 \x03(\v2\x0f.proto.NodeInfoR\brejected\"\xbb\x02\n" +
+	"\brejected\x18\x03 \x03(\v2\x0f.proto.NodeInfoR\brejected\x12'\n" +
+	"\x0fmanager_version\x18\x04 \x01(\tR\x0emanagerVersion\"\xc5\x03\n" +
 	"\bNodeInfo\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
 	"\aaddress\x18\x02 \x01(\tH\x00R\aaddress\x88\x01\x01\x12%\n" +
 	"\vcertificate\x18\x03 \x01(\tH\x01R\vcertificate\x88\x01\x01\x12%\n" +
 	"\visConnected\x18\x04 \x01(\bH\x02R\visConnected\x88\x01\x01\x125\n" +
 	"\x05since\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampH\x03R\x05since\x88\x01\x01\x129\n" +
-	"\alastMsg\x18\a \x01(\v2\x1a.google.protobuf.TimestampH\x04R\alastMsg\x88\x01\x01B\n" +
+	"\alastMsg\x18\a \x01(\v2\x1a.google.protobuf.TimestampH\x04R\alastMsg\x88\x01\x01\x12\x1d\n" +
+	"\aversion\x18\b \x01(\tH\x05R\aversion\x88\x01\x01\x12%\n" +
+	"\vconnections\x18\t \x01(\x03H\x06R\vconnections\x88\x01\x01\x12\x1b\n" +
+	"\x06uptime\x18\n" +
+	" \x01(\x03H\aR\x06uptime\x88\x01\x01B\n" +
 	"\n" +
 	"\b_addressB\x0e\n" +
 	"\f_certificateB\x0e\n" +
 	"\f_isConnectedB\b\n" +
 	"\x06_sinceB\n" +
 	"\n" +
-	"\b_lastMsg\"2\n" +
+	"\b_lastMsgB\n" +
+	"\n" +
+	"\b_versionB\x0e\n" +
+	"\f_connectionsB\t\n" +
+	"\a_uptime\"2\n" +
 	"\vNodeRequest\x12#\n" +
 	"\x04node\x18\x01 \x01(\v2\x0f.proto.NodeInfoR\x04node\"3\n" +
 	"\fNodeResponse\x12#\n" +
 	"\x04node\x18\x01 \x01(\v2\x0f.proto.NodeInfoR\x04node\"6\n" +
 	"\rNodesResponse\x12%\n" +
-	"\x05nodes\x18\x01 \x03(\v2\x0f.proto.NodeInfoR\x05nodes\",\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x0f.proto.NodeInfoR\x05nodes\"h\n" +
+	"\x0eNodeStateEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04node\x18\x02 \x01(\tR\x04node\x12.\n" +
+	"\x04time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"(\n" +
+	"\x12SpecsDriftsRequest\x12\x12\n" +
+	"\x04node\x18\x01 \x01(\tR\x04node\"X\n" +
+	"\n" +
+	"SpecChange\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x10\n" +
+	"\x03old\x18\x03 \x01(\fR\x03old\x12\x10\n" +
+	"\x03new\x18\x04 \x01(\fR\x03new\"i\n" +
+	"\n" +
+	"SpecsDrift\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12+\n" +
+	"\achanges\x18\x02 \x03(\v2\x11.proto.SpecChangeR\achanges\"@\n" +
+	"\x13SpecsDriftsResponse\x12)\n" +
+	"\x06drifts\x18\x01 \x03(\v2\x11.proto.SpecsDriftR\x06drifts\"(\n" +
+	"\x12PluginSyncsRequest\x12\x12\n" +
+	"\x04node\x18\x01 \x01(\tR\x04node\">\n" +
+	"\x13PluginSyncsResponse\x12'\n" +
+	"\x05syncs\x18\x01 \x03(\v2\x11.proto.PluginSyncR\x05syncs\",\n" +
 	"\x0eResultsRequest\x12\x1a\n" +
 	"\bresultID\x18\x01 \x01(\tR\bresultID\")\n" +
 	"\x0fResultsResponse\x12\x16\n" +
@@ -808,30 +2188,129 @@ const file_internal_proto_api_proto_rawDesc = "" +
 	"\x0eRequestRequest\x12\x1c\n" +
 	"\trequestID\x18\x01 \x01(\tR\trequestID\"+\n" +
 	"\x0fRequestResponse\x12\x18\n" +
-	"\arequest\x18\x01 \x01(\tR\arequest\"\xb6\x01\n" +
+	"\arequest\x18\x01 \x01(\tR\arequest\"\xff\x02\n" +
 	"\x12ListResultsRequest\x12\x16\n" +
 	"\x06offset\x18\x01 \x01(\x05R\x06offset\x12\x14\n" +
 	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12 \n" +
 	"\tfrom_date\x18\x03 \x01(\x03H\x00R\bfromDate\x88\x01\x01\x12\x1c\n" +
 	"\ato_date\x18\x04 \x01(\x03H\x01R\x06toDate\x88\x01\x01\x12\x18\n" +
-	"\atargets\x18\x05 \x03(\tR\atargetsB\f\n" +
+	"\atargets\x18\x05 \x03(\tR\atargets\x12C\n" +
+	"\bmetadata\x18\x06 \x03(\v2'.proto.ListResultsRequest.MetadataEntryR\bmetadata\x12\x1a\n" +
+	"\bstatuses\x18\a \x03(\tR\bstatuses\x12\x1d\n" +
+	"\aretcode\x18\b \x01(\x05H\x02R\aretcode\x88\x01\x01\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\f\n" +
 	"\n" +
 	"_from_dateB\n" +
 	"\n" +
-	"\b_to_date\"\x9c\x01\n" +
+	"\b_to_dateB\n" +
+	"\n" +
+	"\b_retcode\"\xc5\x02\n" +
 	"\vResultEntry\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04node\x18\x02 \x01(\tR\x04node\x12\x16\n" +
 	"\x06status\x18\x03 \x01(\tR\x06status\x12;\n" +
 	"\x0einternal_error\x18\x04 \x01(\x0e2\x14.proto.InternalErrorR\rinternalError\x12\x14\n" +
-	"\x05error\x18\x05 \x01(\tR\x05error\"C\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12\x18\n" +
+	"\aretcode\x18\x06 \x01(\x05R\aretcode\x12\x12\n" +
+	"\x04task\x18\a \x01(\tR\x04task\x12<\n" +
+	"\bmetadata\x18\b \x03(\v2 .proto.ResultEntry.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"C\n" +
 	"\x13ListResultsResponse\x12,\n" +
-	"\aresults\x18\x01 \x03(\v2\x12.proto.ResultEntryR\aresults*M\n" +
+	"\aresults\x18\x01 \x03(\v2\x12.proto.ResultEntryR\aresults\"\x8c\x02\n" +
+	"\x13ResultsStatsRequest\x12 \n" +
+	"\tfrom_date\x18\x01 \x01(\x03H\x00R\bfromDate\x88\x01\x01\x12\x1c\n" +
+	"\ato_date\x18\x02 \x01(\x03H\x01R\x06toDate\x88\x01\x01\x12\x18\n" +
+	"\atargets\x18\x03 \x03(\tR\atargets\x12D\n" +
+	"\bmetadata\x18\x04 \x03(\v2(.proto.ResultsStatsRequest.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\f\n" +
+	"\n" +
+	"_from_dateB\n" +
+	"\n" +
+	"\b_to_date\"\xb0\x01\n" +
+	"\rResultsCounts\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x03R\x05total\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\x03R\asuccess\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\x03R\x05error\x12\x18\n" +
+	"\atimeout\x18\x04 \x01(\x03R\atimeout\x12%\n" +
+	"\x0einternal_error\x18\x05 \x01(\x03R\rinternalError\x12\x18\n" +
+	"\aunknown\x18\x06 \x01(\x03R\aunknown\"\xd4\x01\n" +
+	"\x14ResultsStatsResponse\x12&\n" +
+	"\x03all\x18\x01 \x01(\v2\x14.proto.ResultsCountsR\x03all\x12B\n" +
+	"\aplugins\x18\x02 \x03(\v2(.proto.ResultsStatsResponse.PluginsEntryR\aplugins\x1aP\n" +
+	"\fPluginsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.proto.ResultsCountsR\x05value:\x028\x01\"\x9c\x01\n" +
+	"\x14DeleteResultsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x03R\x03ids\x12 \n" +
+	"\tfrom_date\x18\x02 \x01(\x03H\x00R\bfromDate\x88\x01\x01\x12\x1c\n" +
+	"\ato_date\x18\x03 \x01(\x03H\x01R\x06toDate\x88\x01\x01\x12\x18\n" +
+	"\atargets\x18\x04 \x03(\tR\atargetsB\f\n" +
+	"\n" +
+	"_from_dateB\n" +
+	"\n" +
+	"\b_to_date\"1\n" +
+	"\x15DeleteResultsResponse\x12\x18\n" +
+	"\adeleted\x18\x01 \x03(\x03R\adeleted\"%\n" +
+	"\rBackupRequest\x12\x14\n" +
+	"\x05since\x18\x01 \x01(\x04R\x05since\"!\n" +
+	"\vBackupChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"8\n" +
+	"\fRestoreChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x14\n" +
+	"\x05force\x18\x02 \x01(\bR\x05force\"\x11\n" +
+	"\x0fRestoreResponse\"\xa3\x02\n" +
+	"\x15DatabaseStatsResponse\x12\x19\n" +
+	"\blsm_size\x18\x01 \x01(\x03R\alsmSize\x12\x1b\n" +
+	"\tvlog_size\x18\x02 \x01(\x03R\bvlogSize\x12\x12\n" +
+	"\x04keys\x18\x03 \x01(\x04R\x04keys\x128\n" +
+	"\alast_gc\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampH\x00R\x06lastGc\x88\x01\x01\x12*\n" +
+	"\x11last_gc_reclaimed\x18\x05 \x01(\x03R\x0flastGcReclaimed\x12\"\n" +
+	"\rlast_gc_error\x18\x06 \x01(\tR\vlastGcError\x12(\n" +
+	"\x10last_gc_rewrites\x18\a \x01(\rR\x0elastGcRewritesB\n" +
+	"\n" +
+	"\b_last_gc\"\xb8\x01\n" +
+	"\x12ServerInfoResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x16\n" +
+	"\x06commit\x18\x02 \x01(\tR\x06commit\x12\x1d\n" +
+	"\n" +
+	"build_date\x18\x03 \x01(\tR\tbuildDate\x129\n" +
+	"\n" +
+	"started_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12\x16\n" +
+	"\x06uptime\x18\x05 \x01(\x03R\x06uptime\"\x9e\x01\n" +
+	"\x11LockStatsResponse\x129\n" +
+	"\x05nodes\x18\x01 \x03(\v2#.proto.LockStatsResponse.NodesEntryR\x05nodes\x1aN\n" +
+	"\n" +
+	"NodesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.proto.NodeLockStatsR\x05value:\x028\x01\"\xb4\x01\n" +
+	"\rNodeLockStats\x125\n" +
+	"\x05modes\x18\x01 \x03(\v2\x1f.proto.NodeLockStats.ModesEntryR\x05modes\x12\x1c\n" +
+	"\tconflicts\x18\x02 \x01(\x04R\tconflicts\x1aN\n" +
+	"\n" +
+	"ModesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.proto.LockWaitStatsR\x05value:\x028\x01\"}\n" +
+	"\rLockWaitStats\x12\x14\n" +
+	"\x05tasks\x18\x01 \x01(\x04R\x05tasks\x12\x1c\n" +
+	"\tcontended\x18\x02 \x01(\x04R\tcontended\x12\x1d\n" +
+	"\n" +
+	"total_wait\x18\x03 \x01(\x03R\ttotalWait\x12\x19\n" +
+	"\bmax_wait\x18\x04 \x01(\x03R\amaxWait\")\n" +
+	"\x13RunningTasksRequest\x12\x12\n" +
+	"\x04task\x18\x01 \x01(\tR\x04task\"@\n" +
+	"\x14RunningTasksResponse\x12(\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x12.proto.RunningTaskR\x05tasks*M\n" +
 	"\x06Filter\x12\b\n" +
 	"\x04NONE\x10\x00\x12\x11\n" +
 	"\rONLY_ACCEPTED\x10\x01\x12\x13\n" +
 	"\x0fONLY_CANDIDATES\x10\x02\x12\x11\n" +
-	"\rONLY_REJECTED\x10\x032\xeb\x04\n" +
+	"\rONLY_REJECTED\x10\x032\xde\r\n" +
 	"\x03API\x12V\n" +
 	"\tListNodes\x12\x17.proto.ListNodesRequest\x1a\x18.proto.ListNodesResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/nodes/list\x12R\n" +
 	"\n" +
@@ -839,12 +2318,26 @@ const file_internal_proto_api_proto_rawDesc = "" +
 	"\n" +
 	"RemoveNode\x12\x12.proto.NodeRequest\x1a\x14.proto.NodesResponse\"\x18\x82\xd3\xe4\x93\x02\x12*\x10/v1/nodes/remove\x12S\n" +
 	"\n" +
-	"RejectNode\x12\x12.proto.NodeRequest\x1a\x14.proto.NodesResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/nodes/reject\x12W\n" +
+	"RejectNode\x12\x12.proto.NodeRequest\x1a\x14.proto.NodesResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/nodes/reject\x12d\n" +
+	"\vSpecsDrifts\x12\x19.proto.SpecsDriftsRequest\x1a\x1a.proto.SpecsDriftsResponse\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/v1/nodes/specs-drifts\x12d\n" +
+	"\vPluginSyncs\x12\x19.proto.PluginSyncsRequest\x1a\x1a.proto.PluginSyncsResponse\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/v1/nodes/plugin-syncs\x12V\n" +
+	"\n" +
+	"WatchNodes\x12\x16.google.protobuf.Empty\x1a\x15.proto.NodeStateEvent\"\x17\x82\xd3\xe4\x93\x02\x11\x12\x0f/v1/nodes/watch0\x01\x12W\n" +
 	"\n" +
 	"GetResults\x12\x15.proto.ResultsRequest\x1a\x16.proto.ResultsResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/v1/results/result\x12^\n" +
-	"\vListResults\x12\x19.proto.ListResultsRequest\x1a\x1a.proto.ListResultsResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/results/list\x12X\n" +
+	"\vListResults\x12\x19.proto.ListResultsRequest\x1a\x1a.proto.ListResultsResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/results/list\x12\\\n" +
+	"\rStreamResults\x12\x19.proto.ListResultsRequest\x1a\x12.proto.ResultEntry\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/v1/results/stream0\x01\x12b\n" +
+	"\fResultsStats\x12\x1a.proto.ResultsStatsRequest\x1a\x1b.proto.ResultsStatsResponse\"\x19\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/results/stats\x12X\n" +
+	"\n" +
+	"GetRequest\x12\x15.proto.RequestRequest\x1a\x16.proto.RequestResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/results/request\x12f\n" +
+	"\rDeleteResults\x12\x1b.proto.DeleteResultsRequest\x1a\x1c.proto.DeleteResultsResponse\"\x1a\x82\xd3\xe4\x93\x02\x14*\x12/v1/results/delete\x12N\n" +
+	"\x06Backup\x12\x14.proto.BackupRequest\x1a\x12.proto.BackupChunk\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/admin/backup0\x01\x12V\n" +
+	"\aRestore\x12\x13.proto.RestoreChunk\x1a\x16.proto.RestoreResponse\"\x1c\x82\xd3\xe4\x93\x02\x16:\x01*\"\x11/v1/admin/restore(\x01\x12`\n" +
+	"\rDatabaseStats\x12\x16.google.protobuf.Empty\x1a\x1c.proto.DatabaseStatsResponse\"\x19\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/admin/dbstats\x12W\n" +
 	"\n" +
-	"GetRequest\x12\x15.proto.RequestRequest\x1a\x16.proto.RequestResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/results/requestB.Z,github.com/jackadi-io/jackadi/internal/protob\x06proto3"
+	"ServerInfo\x12\x16.google.protobuf.Empty\x1a\x19.proto.ServerInfoResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/admin/info\x12Z\n" +
+	"\tLockStats\x12\x16.google.protobuf.Empty\x1a\x18.proto.LockStatsResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/admin/lockstats\x12d\n" +
+	"\fRunningTasks\x12\x1a.proto.RunningTasksRequest\x1a\x1b.proto.RunningTasksResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/results/runningB.Z,github.com/jackadi-io/jackadi/internal/protob\x06proto3"
 
 var (
 	file_internal_proto_api_proto_rawDescOnce sync.Once
@@ -859,7 +2352,7 @@ func file_internal_proto_api_proto_rawDescGZIP() []byte {
 }
 
 var file_internal_proto_api_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_internal_proto_api_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_internal_proto_api_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
 var file_internal_proto_api_proto_goTypes = []any{
 	(Filter)(0),                   // 0: proto.Filter
 	(*ListNodesRequest)(nil),      // 1: proto.ListNodesRequest
@@ -868,47 +2361,121 @@ var file_internal_proto_api_proto_goTypes = []any{
 	(*NodeRequest)(nil),           // 4: proto.NodeRequest
 	(*NodeResponse)(nil),          // 5: proto.NodeResponse
 	(*NodesResponse)(nil),         // 6: proto.NodesResponse
-	(*ResultsRequest)(nil),        // 7: proto.ResultsRequest
-	(*ResultsResponse)(nil),       // 8: proto.ResultsResponse
-	(*RequestRequest)(nil),        // 9: proto.RequestRequest
-	(*RequestResponse)(nil),       // 10: proto.RequestResponse
-	(*ListResultsRequest)(nil),    // 11: proto.ListResultsRequest
-	(*ResultEntry)(nil),           // 12: proto.ResultEntry
-	(*ListResultsResponse)(nil),   // 13: proto.ListResultsResponse
-	(*timestamppb.Timestamp)(nil), // 14: google.protobuf.Timestamp
-	(InternalError)(0),            // 15: proto.InternalError
+	(*NodeStateEvent)(nil),        // 7: proto.NodeStateEvent
+	(*SpecsDriftsRequest)(nil),    // 8: proto.SpecsDriftsRequest
+	(*SpecChange)(nil),            // 9: proto.SpecChange
+	(*SpecsDrift)(nil),            // 10: proto.SpecsDrift
+	(*SpecsDriftsResponse)(nil),   // 11: proto.SpecsDriftsResponse
+	(*PluginSyncsRequest)(nil),    // 12: proto.PluginSyncsRequest
+	(*PluginSyncsResponse)(nil),   // 13: proto.PluginSyncsResponse
+	(*ResultsRequest)(nil),        // 14: proto.ResultsRequest
+	(*ResultsResponse)(nil),       // 15: proto.ResultsResponse
+	(*RequestRequest)(nil),        // 16: proto.RequestRequest
+	(*RequestResponse)(nil),       // 17: proto.RequestResponse
+	(*ListResultsRequest)(nil),    // 18: proto.ListResultsRequest
+	(*ResultEntry)(nil),           // 19: proto.ResultEntry
+	(*ListResultsResponse)(nil),   // 20: proto.ListResultsResponse
+	(*ResultsStatsRequest)(nil),   // 21: proto.ResultsStatsRequest
+	(*ResultsCounts)(nil),         // 22: proto.ResultsCounts
+	(*ResultsStatsResponse)(nil),  // 23: proto.ResultsStatsResponse
+	(*DeleteResultsRequest)(nil),  // 24: proto.DeleteResultsRequest
+	(*DeleteResultsResponse)(nil), // 25: proto.DeleteResultsResponse
+	(*BackupRequest)(nil),         // 26: proto.BackupRequest
+	(*BackupChunk)(nil),           // 27: proto.BackupChunk
+	(*RestoreChunk)(nil),          // 28: proto.RestoreChunk
+	(*RestoreResponse)(nil),       // 29: proto.RestoreResponse
+	(*DatabaseStatsResponse)(nil), // 30: proto.DatabaseStatsResponse
+	(*ServerInfoResponse)(nil),    // 31: proto.ServerInfoResponse
+	(*LockStatsResponse)(nil),     // 32: proto.LockStatsResponse
+	(*NodeLockStats)(nil),         // 33: proto.NodeLockStats
+	(*LockWaitStats)(nil),         // 34: proto.LockWaitStats
+	(*RunningTasksRequest)(nil),   // 35: proto.RunningTasksRequest
+	(*RunningTasksResponse)(nil),  // 36: proto.RunningTasksResponse
+	nil,                           // 37: proto.ListResultsRequest.MetadataEntry
+	nil,                           // 38: proto.ResultEntry.MetadataEntry
+	nil,                           // 39: proto.ResultsStatsRequest.MetadataEntry
+	nil,                           // 40: proto.ResultsStatsResponse.PluginsEntry
+	nil,                           // 41: proto.LockStatsResponse.NodesEntry
+	nil,                           // 42: proto.NodeLockStats.ModesEntry
+	(*timestamppb.Timestamp)(nil), // 43: google.protobuf.Timestamp
+	(*PluginSync)(nil),            // 44: proto.PluginSync
+	(InternalError)(0),            // 45: proto.InternalError
+	(*RunningTask)(nil),           // 46: proto.RunningTask
+	(*emptypb.Empty)(nil),         // 47: google.protobuf.Empty
 }
 var file_internal_proto_api_proto_depIdxs = []int32{
 	0,  // 0: proto.ListNodesRequest.filter:type_name -> proto.Filter
 	3,  // 1: proto.ListNodesResponse.accepted:type_name -> proto.NodeInfo
 	3,  // 2: proto.ListNodesResponse.candidates:type_name -> proto.NodeInfo
 	3,  // 3: proto.ListNodesResponse.rejected:type_name -> proto.NodeInfo
-	14, // 4: proto.NodeInfo.since:type_name -> google.protobuf.Timestamp
-	14, // 5: proto.NodeInfo.lastMsg:type_name -> google.protobuf.Timestamp
+	43, // 4: proto.NodeInfo.since:type_name -> google.protobuf.Timestamp
+	43, // 5: proto.NodeInfo.lastMsg:type_name -> google.protobuf.Timestamp
 	3,  // 6: proto.NodeRequest.node:type_name -> proto.NodeInfo
 	3,  // 7: proto.NodeResponse.node:type_name -> proto.NodeInfo
 	3,  // 8: proto.NodesResponse.nodes:type_name -> proto.NodeInfo
-	15, // 9: proto.ResultEntry.internal_error:type_name -> proto.InternalError
-	12, // 10: proto.ListResultsResponse.results:type_name -> proto.ResultEntry
-	1,  // 11: proto.API.ListNodes:input_type -> proto.ListNodesRequest
-	4,  // 12: proto.API.AcceptNode:input_type -> proto.NodeRequest
-	4,  // 13: proto.API.RemoveNode:input_type -> proto.NodeRequest
-	4,  // 14: proto.API.RejectNode:input_type -> proto.NodeRequest
-	7,  // 15: proto.API.GetResults:input_type -> proto.ResultsRequest
-	11, // 16: proto.API.ListResults:input_type -> proto.ListResultsRequest
-	9,  // 17: proto.API.GetRequest:input_type -> proto.RequestRequest
-	2,  // 18: proto.API.ListNodes:output_type -> proto.ListNodesResponse
-	5,  // 19: proto.API.AcceptNode:output_type -> proto.NodeResponse
-	6,  // 20: proto.API.RemoveNode:output_type -> proto.NodesResponse
-	6,  // 21: proto.API.RejectNode:output_type -> proto.NodesResponse
-	8,  // 22: proto.API.GetResults:output_type -> proto.ResultsResponse
-	13, // 23: proto.API.ListResults:output_type -> proto.ListResultsResponse
-	10, // 24: proto.API.GetRequest:output_type -> proto.RequestResponse
-	18, // [18:25] is the sub-list for method output_type
-	11, // [11:18] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	43, // 9: proto.NodeStateEvent.time:type_name -> google.protobuf.Timestamp
+	43, // 10: proto.SpecsDrift.time:type_name -> google.protobuf.Timestamp
+	9,  // 11: proto.SpecsDrift.changes:type_name -> proto.SpecChange
+	10, // 12: proto.SpecsDriftsResponse.drifts:type_name -> proto.SpecsDrift
+	44, // 13: proto.PluginSyncsResponse.syncs:type_name -> proto.PluginSync
+	37, // 14: proto.ListResultsRequest.metadata:type_name -> proto.ListResultsRequest.MetadataEntry
+	45, // 15: proto.ResultEntry.internal_error:type_name -> proto.InternalError
+	38, // 16: proto.ResultEntry.metadata:type_name -> proto.ResultEntry.MetadataEntry
+	19, // 17: proto.ListResultsResponse.results:type_name -> proto.ResultEntry
+	39, // 18: proto.ResultsStatsRequest.metadata:type_name -> proto.ResultsStatsRequest.MetadataEntry
+	22, // 19: proto.ResultsStatsResponse.all:type_name -> proto.ResultsCounts
+	40, // 20: proto.ResultsStatsResponse.plugins:type_name -> proto.ResultsStatsResponse.PluginsEntry
+	43, // 21: proto.DatabaseStatsResponse.last_gc:type_name -> google.protobuf.Timestamp
+	43, // 22: proto.ServerInfoResponse.started_at:type_name -> google.protobuf.Timestamp
+	41, // 23: proto.LockStatsResponse.nodes:type_name -> proto.LockStatsResponse.NodesEntry
+	42, // 24: proto.NodeLockStats.modes:type_name -> proto.NodeLockStats.ModesEntry
+	46, // 25: proto.RunningTasksResponse.tasks:type_name -> proto.RunningTask
+	22, // 26: proto.ResultsStatsResponse.PluginsEntry.value:type_name -> proto.ResultsCounts
+	33, // 27: proto.LockStatsResponse.NodesEntry.value:type_name -> proto.NodeLockStats
+	34, // 28: proto.NodeLockStats.ModesEntry.value:type_name -> proto.LockWaitStats
+	1,  // 29: proto.API.ListNodes:input_type -> proto.ListNodesRequest
+	4,  // 30: proto.API.AcceptNode:input_type -> proto.NodeRequest
+	4,  // 31: proto.API.RemoveNode:input_type -> proto.NodeRequest
+	4,  // 32: proto.API.RejectNode:input_type -> proto.NodeRequest
+	8,  // 33: proto.API.SpecsDrifts:input_type -> proto.SpecsDriftsRequest
+	12, // 34: proto.API.PluginSyncs:input_type -> proto.PluginSyncsRequest
+	47, // 35: proto.API.WatchNodes:input_type -> google.protobuf.Empty
+	14, // 36: proto.API.GetResults:input_type -> proto.ResultsRequest
+	18, // 37: proto.API.ListResults:input_type -> proto.ListResultsRequest
+	18, // 38: proto.API.StreamResults:input_type -> proto.ListResultsRequest
+	21, // 39: proto.API.ResultsStats:input_type -> proto.ResultsStatsRequest
+	16, // 40: proto.API.GetRequest:input_type -> proto.RequestRequest
+	24, // 41: proto.API.DeleteResults:input_type -> proto.DeleteResultsRequest
+	26, // 42: proto.API.Backup:input_type -> proto.BackupRequest
+	28, // 43: proto.API.Restore:input_type -> proto.RestoreChunk
+	47, // 44: proto.API.DatabaseStats:input_type -> google.protobuf.Empty
+	47, // 45: proto.API.ServerInfo:input_type -> google.protobuf.Empty
+	47, // 46: proto.API.LockStats:input_type -> google.protobuf.Empty
+	35, // 47: proto.API.RunningTasks:input_type -> proto.RunningTasksRequest
+	2,  // 48: proto.API.ListNodes:output_type -> proto.ListNodesResponse
+	5,  // 49: proto.API.AcceptNode:output_type -> proto.NodeResponse
+	6,  // 50: proto.API.RemoveNode:output_type -> proto.NodesResponse
+	6,  // 51: proto.API.RejectNode:output_type -> proto.NodesResponse
+	11, // 52: proto.API.SpecsDrifts:output_type -> proto.SpecsDriftsResponse
+	13, // 53: proto.API.PluginSyncs:output_type -> proto.PluginSyncsResponse
+	7,  // 54: proto.API.WatchNodes:output_type -> proto.NodeStateEvent
+	15, // 55: proto.API.GetResults:output_type -> proto.ResultsResponse
+	20, // 56: proto.API.ListResults:output_type -> proto.ListResultsResponse
+	19, // 57: proto.API.StreamResults:output_type -> proto.ResultEntry
+	23, // 58: proto.API.ResultsStats:output_type -> proto.ResultsStatsResponse
+	17, // 59: proto.API.GetRequest:output_type -> proto.RequestResponse
+	25, // 60: proto.API.DeleteResults:output_type -> proto.DeleteResultsResponse
+	27, // 61: proto.API.Backup:output_type -> proto.BackupChunk
+	29, // 62: proto.API.Restore:output_type -> proto.RestoreResponse
+	30, // 63: proto.API.DatabaseStats:output_type -> proto.DatabaseStatsResponse
+	31, // 64: proto.API.ServerInfo:output_type -> proto.ServerInfoResponse
+	32, // 65: proto.API.LockStats:output_type -> proto.LockStatsResponse
+	36, // 66: proto.API.RunningTasks:output_type -> proto.RunningTasksResponse
+	48, // [48:67] is the sub-list for method output_type
+	29, // [29:48] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
 }
 
 func init() { file_internal_proto_api_proto_init() }
@@ -918,14 +2485,17 @@ func file_internal_proto_api_proto_init() {
 	}
 	file_internal_proto_cluster_proto_init()
 	file_internal_proto_api_proto_msgTypes[2].OneofWrappers = []any{}
-	file_internal_proto_api_proto_msgTypes[10].OneofWrappers = []any{}
+	file_internal_proto_api_proto_msgTypes[17].OneofWrappers = []any{}
+	file_internal_proto_api_proto_msgTypes[20].OneofWrappers = []any{}
+	file_internal_proto_api_proto_msgTypes[23].OneofWrappers = []any{}
+	file_internal_proto_api_proto_msgTypes[29].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_api_proto_rawDesc), len(file_internal_proto_api_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   13,
+			NumMessages:   42,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
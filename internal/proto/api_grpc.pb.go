@@ -11,6 +11,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -19,13 +20,25 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	API_ListNodes_FullMethodName   = "/proto.API/ListNodes"
-	API_AcceptNode_FullMethodName  = "/proto.API/AcceptNode"
-	API_RemoveNode_FullMethodName  = "/proto.API/RemoveNode"
-	API_RejectNode_FullMethodName  = "/proto.API/RejectNode"
-	API_GetResults_FullMethodName  = "/proto.API/GetResults"
-	API_ListResults_FullMethodName = "/proto.API/ListResults"
-	API_GetRequest_FullMethodName  = "/proto.API/GetRequest"
+	API_ListNodes_FullMethodName     = "/proto.API/ListNodes"
+	API_AcceptNode_FullMethodName    = "/proto.API/AcceptNode"
+	API_RemoveNode_FullMethodName    = "/proto.API/RemoveNode"
+	API_RejectNode_FullMethodName    = "/proto.API/RejectNode"
+	API_SpecsDrifts_FullMethodName   = "/proto.API/SpecsDrifts"
+	API_PluginSyncs_FullMethodName   = "/proto.API/PluginSyncs"
+	API_WatchNodes_FullMethodName    = "/proto.API/WatchNodes"
+	API_GetResults_FullMethodName    = "/proto.API/GetResults"
+	API_ListResults_FullMethodName   = "/proto.API/ListResults"
+	API_StreamResults_FullMethodName = "/proto.API/StreamResults"
+	API_ResultsStats_FullMethodName  = "/proto.API/ResultsStats"
+	API_GetRequest_FullMethodName    = "/proto.API/GetRequest"
+	API_DeleteResults_FullMethodName = "/proto.API/DeleteResults"
+	API_Backup_FullMethodName        = "/proto.API/Backup"
+	API_Restore_FullMethodName       = "/proto.API/Restore"
+	API_DatabaseStats_FullMethodName = "/proto.API/DatabaseStats"
+	API_ServerInfo_FullMethodName    = "/proto.API/ServerInfo"
+	API_LockStats_FullMethodName     = "/proto.API/LockStats"
+	API_RunningTasks_FullMethodName  = "/proto.API/RunningTasks"
 )
 
 // APIClient is the client API for API service.
@@ -36,9 +49,22 @@ type APIClient interface {
 	AcceptNode(ctx context.Context, in *NodeRequest, opts ...grpc.CallOption) (*NodeResponse, error)
 	RemoveNode(ctx context.Context, in *NodeRequest, opts ...grpc.CallOption) (*NodesResponse, error)
 	RejectNode(ctx context.Context, in *NodeRequest, opts ...grpc.CallOption) (*NodesResponse, error)
+	SpecsDrifts(ctx context.Context, in *SpecsDriftsRequest, opts ...grpc.CallOption) (*SpecsDriftsResponse, error)
+	PluginSyncs(ctx context.Context, in *PluginSyncsRequest, opts ...grpc.CallOption) (*PluginSyncsResponse, error)
+	WatchNodes(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NodeStateEvent], error)
 	GetResults(ctx context.Context, in *ResultsRequest, opts ...grpc.CallOption) (*ResultsResponse, error)
 	ListResults(ctx context.Context, in *ListResultsRequest, opts ...grpc.CallOption) (*ListResultsResponse, error)
+	// StreamResults is ListResults sending each result as soon as it is read, up to config.MaxResultsLimit results.
+	StreamResults(ctx context.Context, in *ListResultsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResultEntry], error)
+	ResultsStats(ctx context.Context, in *ResultsStatsRequest, opts ...grpc.CallOption) (*ResultsStatsResponse, error)
 	GetRequest(ctx context.Context, in *RequestRequest, opts ...grpc.CallOption) (*RequestResponse, error)
+	DeleteResults(ctx context.Context, in *DeleteResultsRequest, opts ...grpc.CallOption) (*DeleteResultsResponse, error)
+	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BackupChunk], error)
+	Restore(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[RestoreChunk, RestoreResponse], error)
+	DatabaseStats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*DatabaseStatsResponse, error)
+	ServerInfo(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ServerInfoResponse, error)
+	LockStats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*LockStatsResponse, error)
+	RunningTasks(ctx context.Context, in *RunningTasksRequest, opts ...grpc.CallOption) (*RunningTasksResponse, error)
 }
 
 type aPIClient struct {
@@ -89,6 +115,45 @@ func (c *aPIClient) RejectNode(ctx context.Context, in *NodeRequest, opts ...grp
 	return out, nil
 }
 
+func (c *aPIClient) SpecsDrifts(ctx context.Context, in *SpecsDriftsRequest, opts ...grpc.CallOption) (*SpecsDriftsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SpecsDriftsResponse)
+	err := c.cc.Invoke(ctx, API_SpecsDrifts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) PluginSyncs(ctx context.Context, in *PluginSyncsRequest, opts ...grpc.CallOption) (*PluginSyncsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PluginSyncsResponse)
+	err := c.cc.Invoke(ctx, API_PluginSyncs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) WatchNodes(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NodeStateEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[0], API_WatchNodes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[emptypb.Empty, NodeStateEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type API_WatchNodesClient = grpc.ServerStreamingClient[NodeStateEvent]
+
 func (c *aPIClient) GetResults(ctx context.Context, in *ResultsRequest, opts ...grpc.CallOption) (*ResultsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ResultsResponse)
@@ -109,6 +174,35 @@ func (c *aPIClient) ListResults(ctx context.Context, in *ListResultsRequest, opt
 	return out, nil
 }
 
+func (c *aPIClient) StreamResults(ctx context.Context, in *ListResultsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResultEntry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[1], API_StreamResults_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListResultsRequest, ResultEntry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type API_StreamResultsClient = grpc.ServerStreamingClient[ResultEntry]
+
+func (c *aPIClient) ResultsStats(ctx context.Context, in *ResultsStatsRequest, opts ...grpc.CallOption) (*ResultsStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResultsStatsResponse)
+	err := c.cc.Invoke(ctx, API_ResultsStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) GetRequest(ctx context.Context, in *RequestRequest, opts ...grpc.CallOption) (*RequestResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(RequestResponse)
@@ -119,6 +213,88 @@ func (c *aPIClient) GetRequest(ctx context.Context, in *RequestRequest, opts ...
 	return out, nil
 }
 
+func (c *aPIClient) DeleteResults(ctx context.Context, in *DeleteResultsRequest, opts ...grpc.CallOption) (*DeleteResultsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResultsResponse)
+	err := c.cc.Invoke(ctx, API_DeleteResults_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BackupChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[2], API_Backup_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BackupRequest, BackupChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type API_BackupClient = grpc.ServerStreamingClient[BackupChunk]
+
+func (c *aPIClient) Restore(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[RestoreChunk, RestoreResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[3], API_Restore_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RestoreChunk, RestoreResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type API_RestoreClient = grpc.ClientStreamingClient[RestoreChunk, RestoreResponse]
+
+func (c *aPIClient) DatabaseStats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*DatabaseStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DatabaseStatsResponse)
+	err := c.cc.Invoke(ctx, API_DatabaseStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) ServerInfo(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ServerInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServerInfoResponse)
+	err := c.cc.Invoke(ctx, API_ServerInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) LockStats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*LockStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LockStatsResponse)
+	err := c.cc.Invoke(ctx, API_LockStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) RunningTasks(ctx context.Context, in *RunningTasksRequest, opts ...grpc.CallOption) (*RunningTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunningTasksResponse)
+	err := c.cc.Invoke(ctx, API_RunningTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // APIServer is the server API for API service.
 // All implementations should embed UnimplementedAPIServer
 // for forward compatibility.
@@ -127,9 +303,22 @@ type APIServer interface {
 	AcceptNode(context.Context, *NodeRequest) (*NodeResponse, error)
 	RemoveNode(context.Context, *NodeRequest) (*NodesResponse, error)
 	RejectNode(context.Context, *NodeRequest) (*NodesResponse, error)
+	SpecsDrifts(context.Context, *SpecsDriftsRequest) (*SpecsDriftsResponse, error)
+	PluginSyncs(context.Context, *PluginSyncsRequest) (*PluginSyncsResponse, error)
+	WatchNodes(*emptypb.Empty, grpc.ServerStreamingServer[NodeStateEvent]) error
 	GetResults(context.Context, *ResultsRequest) (*ResultsResponse, error)
 	ListResults(context.Context, *ListResultsRequest) (*ListResultsResponse, error)
+	// StreamResults is ListResults sending each result as soon as it is read, up to config.MaxResultsLimit results.
+	StreamResults(*ListResultsRequest, grpc.ServerStreamingServer[ResultEntry]) error
+	ResultsStats(context.Context, *ResultsStatsRequest) (*ResultsStatsResponse, error)
 	GetRequest(context.Context, *RequestRequest) (*RequestResponse, error)
+	DeleteResults(context.Context, *DeleteResultsRequest) (*DeleteResultsResponse, error)
+	Backup(*BackupRequest, grpc.ServerStreamingServer[BackupChunk]) error
+	Restore(grpc.ClientStreamingServer[RestoreChunk, RestoreResponse]) error
+	DatabaseStats(context.Context, *emptypb.Empty) (*DatabaseStatsResponse, error)
+	ServerInfo(context.Context, *emptypb.Empty) (*ServerInfoResponse, error)
+	LockStats(context.Context, *emptypb.Empty) (*LockStatsResponse, error)
+	RunningTasks(context.Context, *RunningTasksRequest) (*RunningTasksResponse, error)
 }
 
 // UnimplementedAPIServer should be embedded to have
@@ -151,15 +340,51 @@ func (UnimplementedAPIServer) RemoveNode(context.Context, *NodeRequest) (*NodesR
 func (UnimplementedAPIServer) RejectNode(context.Context, *NodeRequest) (*NodesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method RejectNode not implemented")
 }
+func (UnimplementedAPIServer) SpecsDrifts(context.Context, *SpecsDriftsRequest) (*SpecsDriftsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SpecsDrifts not implemented")
+}
+func (UnimplementedAPIServer) PluginSyncs(context.Context, *PluginSyncsRequest) (*PluginSyncsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PluginSyncs not implemented")
+}
+func (UnimplementedAPIServer) WatchNodes(*emptypb.Empty, grpc.ServerStreamingServer[NodeStateEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchNodes not implemented")
+}
 func (UnimplementedAPIServer) GetResults(context.Context, *ResultsRequest) (*ResultsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetResults not implemented")
 }
 func (UnimplementedAPIServer) ListResults(context.Context, *ListResultsRequest) (*ListResultsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListResults not implemented")
 }
+func (UnimplementedAPIServer) StreamResults(*ListResultsRequest, grpc.ServerStreamingServer[ResultEntry]) error {
+	return status.Error(codes.Unimplemented, "method StreamResults not implemented")
+}
+func (UnimplementedAPIServer) ResultsStats(context.Context, *ResultsStatsRequest) (*ResultsStatsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResultsStats not implemented")
+}
 func (UnimplementedAPIServer) GetRequest(context.Context, *RequestRequest) (*RequestResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetRequest not implemented")
 }
+func (UnimplementedAPIServer) DeleteResults(context.Context, *DeleteResultsRequest) (*DeleteResultsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteResults not implemented")
+}
+func (UnimplementedAPIServer) Backup(*BackupRequest, grpc.ServerStreamingServer[BackupChunk]) error {
+	return status.Error(codes.Unimplemented, "method Backup not implemented")
+}
+func (UnimplementedAPIServer) Restore(grpc.ClientStreamingServer[RestoreChunk, RestoreResponse]) error {
+	return status.Error(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedAPIServer) DatabaseStats(context.Context, *emptypb.Empty) (*DatabaseStatsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DatabaseStats not implemented")
+}
+func (UnimplementedAPIServer) ServerInfo(context.Context, *emptypb.Empty) (*ServerInfoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ServerInfo not implemented")
+}
+func (UnimplementedAPIServer) LockStats(context.Context, *emptypb.Empty) (*LockStatsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method LockStats not implemented")
+}
+func (UnimplementedAPIServer) RunningTasks(context.Context, *RunningTasksRequest) (*RunningTasksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RunningTasks not implemented")
+}
 func (UnimplementedAPIServer) testEmbeddedByValue() {}
 
 // UnsafeAPIServer may be embedded to opt out of forward compatibility for this service.
@@ -252,6 +477,53 @@ func _API_RejectNode_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_SpecsDrifts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SpecsDriftsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).SpecsDrifts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: API_SpecsDrifts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).SpecsDrifts(ctx, req.(*SpecsDriftsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_PluginSyncs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PluginSyncsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).PluginSyncs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: API_PluginSyncs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).PluginSyncs(ctx, req.(*PluginSyncsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_WatchNodes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).WatchNodes(m, &grpc.GenericServerStream[emptypb.Empty, NodeStateEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type API_WatchNodesServer = grpc.ServerStreamingServer[NodeStateEvent]
+
 func _API_GetResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ResultsRequest)
 	if err := dec(in); err != nil {
@@ -288,6 +560,35 @@ func _API_ListResults_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_StreamResults_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListResultsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).StreamResults(m, &grpc.GenericServerStream[ListResultsRequest, ResultEntry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type API_StreamResultsServer = grpc.ServerStreamingServer[ResultEntry]
+
+func _API_ResultsStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResultsStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ResultsStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: API_ResultsStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ResultsStats(ctx, req.(*ResultsStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _API_GetRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RequestRequest)
 	if err := dec(in); err != nil {
@@ -306,6 +607,114 @@ func _API_GetRequest_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_DeleteResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteResultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).DeleteResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: API_DeleteResults_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).DeleteResults(ctx, req.(*DeleteResultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_Backup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BackupRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).Backup(m, &grpc.GenericServerStream[BackupRequest, BackupChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type API_BackupServer = grpc.ServerStreamingServer[BackupChunk]
+
+func _API_Restore_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(APIServer).Restore(&grpc.GenericServerStream[RestoreChunk, RestoreResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type API_RestoreServer = grpc.ClientStreamingServer[RestoreChunk, RestoreResponse]
+
+func _API_DatabaseStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).DatabaseStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: API_DatabaseStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).DatabaseStats(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_ServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ServerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: API_ServerInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ServerInfo(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_LockStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).LockStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: API_LockStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).LockStats(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_RunningTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunningTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).RunningTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: API_RunningTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).RunningTasks(ctx, req.(*RunningTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // API_ServiceDesc is the grpc.ServiceDesc for API service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -329,6 +738,14 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RejectNode",
 			Handler:    _API_RejectNode_Handler,
 		},
+		{
+			MethodName: "SpecsDrifts",
+			Handler:    _API_SpecsDrifts_Handler,
+		},
+		{
+			MethodName: "PluginSyncs",
+			Handler:    _API_PluginSyncs_Handler,
+		},
 		{
 			MethodName: "GetResults",
 			Handler:    _API_GetResults_Handler,
@@ -337,11 +754,56 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListResults",
 			Handler:    _API_ListResults_Handler,
 		},
+		{
+			MethodName: "ResultsStats",
+			Handler:    _API_ResultsStats_Handler,
+		},
 		{
 			MethodName: "GetRequest",
 			Handler:    _API_GetRequest_Handler,
 		},
+		{
+			MethodName: "DeleteResults",
+			Handler:    _API_DeleteResults_Handler,
+		},
+		{
+			MethodName: "DatabaseStats",
+			Handler:    _API_DatabaseStats_Handler,
+		},
+		{
+			MethodName: "ServerInfo",
+			Handler:    _API_ServerInfo_Handler,
+		},
+		{
+			MethodName: "LockStats",
+			Handler:    _API_LockStats_Handler,
+		},
+		{
+			MethodName: "RunningTasks",
+			Handler:    _API_RunningTasks_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchNodes",
+			Handler:       _API_WatchNodes_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamResults",
+			Handler:       _API_StreamResults_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Backup",
+			Handler:       _API_Backup_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Restore",
+			Handler:       _API_Restore_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/proto/api.proto",
 }
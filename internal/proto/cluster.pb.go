@@ -12,6 +12,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -24,46 +25,116 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type DispatchStatus int32
+
+const (
+	DispatchStatus_DISPATCH_UNKNOWN      DispatchStatus = 0
+	DispatchStatus_DISPATCH_SENT         DispatchStatus = 1
+	DispatchStatus_DISPATCH_DISCONNECTED DispatchStatus = 2 // Matched but disconnected, not sent
+	DispatchStatus_DISPATCH_REJECTED     DispatchStatus = 3 // Matched but not sent or not accepted, e.g. disconnecting node or full queue
+	DispatchStatus_DISPATCH_SKIPPED      DispatchStatus = 4 // Matched but not sent, a rolling or fail-fast run stopped before it
+)
+
+// Enum value maps for DispatchStatus.
+var (
+	DispatchStatus_name = map[int32]string{
+		0: "DISPATCH_UNKNOWN",
+		1: "DISPATCH_SENT",
+		2: "DISPATCH_DISCONNECTED",
+		3: "DISPATCH_REJECTED",
+		4: "DISPATCH_SKIPPED",
+	}
+	DispatchStatus_value = map[string]int32{
+		"DISPATCH_UNKNOWN":      0,
+		"DISPATCH_SENT":         1,
+		"DISPATCH_DISCONNECTED": 2,
+		"DISPATCH_REJECTED":     3,
+		"DISPATCH_SKIPPED":      4,
+	}
+)
+
+func (x DispatchStatus) Enum() *DispatchStatus {
+	p := new(DispatchStatus)
+	*p = x
+	return p
+}
+
+func (x DispatchStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DispatchStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_proto_cluster_proto_enumTypes[0].Descriptor()
+}
+
+func (DispatchStatus) Type() protoreflect.EnumType {
+	return &file_internal_proto_cluster_proto_enumTypes[0]
+}
+
+func (x DispatchStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DispatchStatus.Descriptor instead.
+func (DispatchStatus) EnumDescriptor() ([]byte, []int) {
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{0}
+}
+
 type InternalError int32
 
 const (
-	InternalError_OK              InternalError = 0
-	InternalError_TIMEOUT         InternalError = 1
-	InternalError_STARTED_TIMEOUT InternalError = 2
-	InternalError_BUSY_QUEUE      InternalError = 3
-	InternalError_FULL_QUEUE      InternalError = 4
-	InternalError_UNKNOWN_TASK    InternalError = 5
-	InternalError_MODULE_ERROR    InternalError = 6 // TODO: rename SDKError? PluginError? GRPCPluginError (GRPC between HC plugin and node)?
-	InternalError_DISCONNECTING   InternalError = 7
-	InternalError_DISCONNECTED    InternalError = 8
-	InternalError_UNKNOWN_ERROR   InternalError = 9
+	InternalError_OK                InternalError = 0
+	InternalError_TIMEOUT           InternalError = 1 // The task did not start before its timeout, waiting on the node for a slot or its lock
+	InternalError_STARTED_TIMEOUT   InternalError = 2 // The task is still running at its timeout, its result is sent once done
+	InternalError_BUSY_QUEUE        InternalError = 3 // The node is busy, the task was not queued
+	InternalError_FULL_QUEUE        InternalError = 4 // The waiting queue of the node is full, the task was not queued
+	InternalError_UNKNOWN_TASK      InternalError = 5 // The plugin or the task is unknown to the node
+	InternalError_MODULE_ERROR      InternalError = 6 // The task failed, the reason is in moduleError. TODO: rename SDKError? PluginError? GRPCPluginError (GRPC between HC plugin and node)?
+	InternalError_DISCONNECTING     InternalError = 7 // The node is disconnecting or shutting down, the task was refused or killed
+	InternalError_DISCONNECTED      InternalError = 8 // The node is disconnected, the task was not sent
+	InternalError_UNKNOWN_ERROR     InternalError = 9
+	InternalError_MODULE_PANIC      InternalError = 10 // The task panicked, the recovered value is in moduleError
+	InternalError_DOWNSTREAM_ERROR  InternalError = 11 // A syndic failed to dispatch the task to its nodes, the reason is in moduleError
+	InternalError_NODE_UNRESPONSIVE InternalError = 12 // No response from the node, not even a timeout one, within the timeout of the task and the manager grace
+	InternalError_SKIPPED           InternalError = 13 // Not sent, a rolling or fail-fast run stopped before the node
+	InternalError_CANCELLED         InternalError = 14 // Sent, but a fail-fast run stopped waiting for the node at the failure of another one, the result is stored once done
 )
 
 // Enum value maps for InternalError.
 var (
 	InternalError_name = map[int32]string{
-		0: "OK",
-		1: "TIMEOUT",
-		2: "STARTED_TIMEOUT",
-		3: "BUSY_QUEUE",
-		4: "FULL_QUEUE",
-		5: "UNKNOWN_TASK",
-		6: "MODULE_ERROR",
-		7: "DISCONNECTING",
-		8: "DISCONNECTED",
-		9: "UNKNOWN_ERROR",
+		0:  "OK",
+		1:  "TIMEOUT",
+		2:  "STARTED_TIMEOUT",
+		3:  "BUSY_QUEUE",
+		4:  "FULL_QUEUE",
+		5:  "UNKNOWN_TASK",
+		6:  "MODULE_ERROR",
+		7:  "DISCONNECTING",
+		8:  "DISCONNECTED",
+		9:  "UNKNOWN_ERROR",
+		10: "MODULE_PANIC",
+		11: "DOWNSTREAM_ERROR",
+		12: "NODE_UNRESPONSIVE",
+		13: "SKIPPED",
+		14: "CANCELLED",
 	}
 	InternalError_value = map[string]int32{
-		"OK":              0,
-		"TIMEOUT":         1,
-		"STARTED_TIMEOUT": 2,
-		"BUSY_QUEUE":      3,
-		"FULL_QUEUE":      4,
-		"UNKNOWN_TASK":    5,
-		"MODULE_ERROR":    6,
-		"DISCONNECTING":   7,
-		"DISCONNECTED":    8,
-		"UNKNOWN_ERROR":   9,
+		"OK":                0,
+		"TIMEOUT":           1,
+		"STARTED_TIMEOUT":   2,
+		"BUSY_QUEUE":        3,
+		"FULL_QUEUE":        4,
+		"UNKNOWN_TASK":      5,
+		"MODULE_ERROR":      6,
+		"DISCONNECTING":     7,
+		"DISCONNECTED":      8,
+		"UNKNOWN_ERROR":     9,
+		"MODULE_PANIC":      10,
+		"DOWNSTREAM_ERROR":  11,
+		"NODE_UNRESPONSIVE": 12,
+		"SKIPPED":           13,
+		"CANCELLED":         14,
 	}
 )
 
@@ -78,11 +149,11 @@ func (x InternalError) String() string {
 }
 
 func (InternalError) Descriptor() protoreflect.EnumDescriptor {
-	return file_internal_proto_cluster_proto_enumTypes[0].Descriptor()
+	return file_internal_proto_cluster_proto_enumTypes[1].Descriptor()
 }
 
 func (InternalError) Type() protoreflect.EnumType {
-	return &file_internal_proto_cluster_proto_enumTypes[0]
+	return &file_internal_proto_cluster_proto_enumTypes[1]
 }
 
 func (x InternalError) Number() protoreflect.EnumNumber {
@@ -91,7 +162,7 @@ func (x InternalError) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use InternalError.Descriptor instead.
 func (InternalError) EnumDescriptor() ([]byte, []int) {
-	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{0}
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{1}
 }
 
 type TargetMode int32
@@ -136,11 +207,11 @@ func (x TargetMode) String() string {
 }
 
 func (TargetMode) Descriptor() protoreflect.EnumDescriptor {
-	return file_internal_proto_cluster_proto_enumTypes[1].Descriptor()
+	return file_internal_proto_cluster_proto_enumTypes[2].Descriptor()
 }
 
 func (TargetMode) Type() protoreflect.EnumType {
-	return &file_internal_proto_cluster_proto_enumTypes[1]
+	return &file_internal_proto_cluster_proto_enumTypes[2]
 }
 
 func (x TargetMode) Number() protoreflect.EnumNumber {
@@ -149,7 +220,56 @@ func (x TargetMode) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use TargetMode.Descriptor instead.
 func (TargetMode) EnumDescriptor() ([]byte, []int) {
-	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{1}
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{2}
+}
+
+type Priority int32
+
+const (
+	Priority_PRIORITY_NORMAL Priority = 0
+	Priority_PRIORITY_HIGH   Priority = 1
+	Priority_PRIORITY_LOW    Priority = 2
+)
+
+// Enum value maps for Priority.
+var (
+	Priority_name = map[int32]string{
+		0: "PRIORITY_NORMAL",
+		1: "PRIORITY_HIGH",
+		2: "PRIORITY_LOW",
+	}
+	Priority_value = map[string]int32{
+		"PRIORITY_NORMAL": 0,
+		"PRIORITY_HIGH":   1,
+		"PRIORITY_LOW":    2,
+	}
+)
+
+func (x Priority) Enum() *Priority {
+	p := new(Priority)
+	*p = x
+	return p
+}
+
+func (x Priority) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Priority) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_proto_cluster_proto_enumTypes[3].Descriptor()
+}
+
+func (Priority) Type() protoreflect.EnumType {
+	return &file_internal_proto_cluster_proto_enumTypes[3]
+}
+
+func (x Priority) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Priority.Descriptor instead.
+func (Priority) EnumDescriptor() ([]byte, []int) {
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{3}
 }
 
 type LockMode int32
@@ -188,11 +308,11 @@ func (x LockMode) String() string {
 }
 
 func (LockMode) Descriptor() protoreflect.EnumDescriptor {
-	return file_internal_proto_cluster_proto_enumTypes[2].Descriptor()
+	return file_internal_proto_cluster_proto_enumTypes[4].Descriptor()
 }
 
 func (LockMode) Type() protoreflect.EnumType {
-	return &file_internal_proto_cluster_proto_enumTypes[2]
+	return &file_internal_proto_cluster_proto_enumTypes[4]
 }
 
 func (x LockMode) Number() protoreflect.EnumNumber {
@@ -201,12 +321,14 @@ func (x LockMode) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use LockMode.Descriptor instead.
 func (LockMode) EnumDescriptor() ([]byte, []int) {
-	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{2}
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{4}
 }
 
 type HandshakeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`    // Build version of the node
+	Protocol      uint32                 `protobuf:"varint,3,opt,name=protocol,proto3" json:"protocol,omitempty"` // Protocol version of the node, 0 if older than the version check
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -248,11 +370,30 @@ func (x *HandshakeRequest) GetId() int64 {
 	return 0
 }
 
+func (x *HandshakeRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HandshakeRequest) GetProtocol() uint32 {
+	if x != nil {
+		return x.Protocol
+	}
+	return 0
+}
+
 type HandshakeResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version            string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`                                                    // Build version of the manager
+	Protocol           uint32                 `protobuf:"varint,3,opt,name=protocol,proto3" json:"protocol,omitempty"`                                                 // Protocol version of the manager
+	HeartbeatInterval  uint32                 `protobuf:"varint,4,opt,name=heartbeat_interval,json=heartbeatInterval,proto3" json:"heartbeat_interval,omitempty"`      // Interval of the health:instant-ping sent by the manager on the task stream, in seconds, 0 if disabled
+	MaxConcurrentTasks uint32                 `protobuf:"varint,5,opt,name=max_concurrent_tasks,json=maxConcurrentTasks,proto3" json:"max_concurrent_tasks,omitempty"` // Overrides the node configuration if set
+	MaxWaitingRequests uint32                 `protobuf:"varint,6,opt,name=max_waiting_requests,json=maxWaitingRequests,proto3" json:"max_waiting_requests,omitempty"` // Overrides the node configuration if set
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *HandshakeResponse) Reset() {
@@ -292,18 +433,64 @@ func (x *HandshakeResponse) GetId() int64 {
 	return 0
 }
 
+func (x *HandshakeResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HandshakeResponse) GetProtocol() uint32 {
+	if x != nil {
+		return x.Protocol
+	}
+	return 0
+}
+
+func (x *HandshakeResponse) GetHeartbeatInterval() uint32 {
+	if x != nil {
+		return x.HeartbeatInterval
+	}
+	return 0
+}
+
+func (x *HandshakeResponse) GetMaxConcurrentTasks() uint32 {
+	if x != nil {
+		return x.MaxConcurrentTasks
+	}
+	return 0
+}
+
+func (x *HandshakeResponse) GetMaxWaitingRequests() uint32 {
+	if x != nil {
+		return x.MaxWaitingRequests
+	}
+	return 0
+}
+
 type TaskRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	GroupID       *int64                 `protobuf:"varint,2,opt,name=groupID,proto3,oneof" json:"groupID,omitempty"`
-	Target        string                 `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
-	TargetMode    TargetMode             `protobuf:"varint,4,opt,name=target_mode,json=targetMode,proto3,enum=proto.TargetMode" json:"target_mode,omitempty"`
-	LockMode      LockMode               `protobuf:"varint,5,opt,name=lock_mode,json=lockMode,proto3,enum=proto.LockMode" json:"lock_mode,omitempty"`
-	Timeout       uint32                 `protobuf:"varint,6,opt,name=timeout,proto3" json:"timeout,omitempty"`
-	Task          string                 `protobuf:"bytes,7,opt,name=task,proto3" json:"task,omitempty"`
-	Input         *Input                 `protobuf:"bytes,8,opt,name=input,proto3" json:"input,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	GroupID            *int64                 `protobuf:"varint,2,opt,name=groupID,proto3,oneof" json:"groupID,omitempty"`
+	Target             string                 `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	TargetMode         TargetMode             `protobuf:"varint,4,opt,name=target_mode,json=targetMode,proto3,enum=proto.TargetMode" json:"target_mode,omitempty"`
+	LockMode           LockMode               `protobuf:"varint,5,opt,name=lock_mode,json=lockMode,proto3,enum=proto.LockMode" json:"lock_mode,omitempty"`
+	Timeout            uint32                 `protobuf:"varint,6,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Task               string                 `protobuf:"bytes,7,opt,name=task,proto3" json:"task,omitempty"` // Task name, or deprecated plugin.task form when plugin is empty
+	Input              *Input                 `protobuf:"bytes,8,opt,name=input,proto3" json:"input,omitempty"`
+	Plugin             string                 `protobuf:"bytes,9,opt,name=plugin,proto3" json:"plugin,omitempty"`                                                                                // Plugin containing the task
+	Metadata           map[string]string      `protobuf:"bytes,10,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Labels of the request (e.g. CI build number), stored with its results
+	Targets            []*Target              `protobuf:"bytes,11,rep,name=targets,proto3" json:"targets,omitempty"`                                                                             // The request is sent to the union of these targets and of target if set
+	Excludes           []*Target              `protobuf:"bytes,12,rep,name=excludes,proto3" json:"excludes,omitempty"`                                                                           // Nodes removed from the targeted ones
+	DownstreamTargets  []*Target              `protobuf:"bytes,13,rep,name=downstream_targets,json=downstreamTargets,proto3" json:"downstream_targets,omitempty"`                                // Nodes of the targeted syndics (managers connected as nodes) running the task, all of them if empty
+	DownstreamExcludes []*Target              `protobuf:"bytes,14,rep,name=downstream_excludes,json=downstreamExcludes,proto3" json:"downstream_excludes,omitempty"`                             // Nodes of the targeted syndics removed from the downstream targets
+	Priority           Priority               `protobuf:"varint,15,opt,name=priority,proto3,enum=proto.Priority" json:"priority,omitempty"`                                                      // The requests waiting for a slot on a node run by priority, see config.PriorityAging
+	FailFast           bool                   `protobuf:"varint,16,opt,name=fail_fast,json=failFast,proto3" json:"fail_fast,omitempty"`                                                          // The nodes run the task concurrently, the ones not done are cancelled at the first failure, no failure tolerated if rolling
+	Rolling            bool                   `protobuf:"varint,17,opt,name=rolling,proto3" json:"rolling,omitempty"`                                                                            // The nodes run the task one at a time in the order of their IDs, the remaining ones are skipped once the failures exceed max_failures
+	MaxFailures        uint32                 `protobuf:"varint,18,opt,name=max_failures,json=maxFailures,proto3" json:"max_failures,omitempty"`                                                 // Failures tolerated by a rolling run
+	Transient          bool                   `protobuf:"varint,19,opt,name=transient,proto3" json:"transient,omitempty"`                                                                        // The request and its results are neither stored nor notified, e.g. the polls of jack nodes logs --follow
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *TaskRequest) Reset() {
@@ -392,28 +579,105 @@ func (x *TaskRequest) GetInput() *Input {
 	return nil
 }
 
-type Input struct {
+func (x *TaskRequest) GetPlugin() string {
+	if x != nil {
+		return x.Plugin
+	}
+	return ""
+}
+
+func (x *TaskRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *TaskRequest) GetTargets() []*Target {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *TaskRequest) GetExcludes() []*Target {
+	if x != nil {
+		return x.Excludes
+	}
+	return nil
+}
+
+func (x *TaskRequest) GetDownstreamTargets() []*Target {
+	if x != nil {
+		return x.DownstreamTargets
+	}
+	return nil
+}
+
+func (x *TaskRequest) GetDownstreamExcludes() []*Target {
+	if x != nil {
+		return x.DownstreamExcludes
+	}
+	return nil
+}
+
+func (x *TaskRequest) GetPriority() Priority {
+	if x != nil {
+		return x.Priority
+	}
+	return Priority_PRIORITY_NORMAL
+}
+
+func (x *TaskRequest) GetFailFast() bool {
+	if x != nil {
+		return x.FailFast
+	}
+	return false
+}
+
+func (x *TaskRequest) GetRolling() bool {
+	if x != nil {
+		return x.Rolling
+	}
+	return false
+}
+
+func (x *TaskRequest) GetMaxFailures() uint32 {
+	if x != nil {
+		return x.MaxFailures
+	}
+	return 0
+}
+
+func (x *TaskRequest) GetTransient() bool {
+	if x != nil {
+		return x.Transient
+	}
+	return false
+}
+
+type Target struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Args          *structpb.ListValue    `protobuf:"bytes,1,opt,name=args,proto3" json:"args,omitempty"`
-	Options       *structpb.Struct       `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	Target        string                 `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	Mode          TargetMode             `protobuf:"varint,2,opt,name=mode,proto3,enum=proto.TargetMode" json:"mode,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Input) Reset() {
-	*x = Input{}
+func (x *Target) Reset() {
+	*x = Target{}
 	mi := &file_internal_proto_cluster_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Input) String() string {
+func (x *Target) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Input) ProtoMessage() {}
+func (*Target) ProtoMessage() {}
 
-func (x *Input) ProtoReflect() protoreflect.Message {
+func (x *Target) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_cluster_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -425,52 +689,47 @@ func (x *Input) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Input.ProtoReflect.Descriptor instead.
-func (*Input) Descriptor() ([]byte, []int) {
+// Deprecated: Use Target.ProtoReflect.Descriptor instead.
+func (*Target) Descriptor() ([]byte, []int) {
 	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *Input) GetArgs() *structpb.ListValue {
+func (x *Target) GetTarget() string {
 	if x != nil {
-		return x.Args
+		return x.Target
 	}
-	return nil
+	return ""
 }
 
-func (x *Input) GetOptions() *structpb.Struct {
+func (x *Target) GetMode() TargetMode {
 	if x != nil {
-		return x.Options
+		return x.Mode
 	}
-	return nil
+	return TargetMode_UNKNOWN
 }
 
-type TaskResponse struct {
+type ScheduleTaskRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	GroupID       *int64                 `protobuf:"varint,2,opt,name=groupID,proto3,oneof" json:"groupID,omitempty"`
-	Output        []byte                 `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`
-	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
-	Retcode       int32                  `protobuf:"varint,5,opt,name=retcode,proto3" json:"retcode,omitempty"`                                      // TODO: remove
-	InternalError InternalError          `protobuf:"varint,6,opt,name=internalError,proto3,enum=proto.InternalError" json:"internalError,omitempty"` // Could be the global error type ( != OK when the task returned an error)
-	ModuleError   string                 `protobuf:"bytes,7,opt,name=moduleError,proto3" json:"moduleError,omitempty"`                               // TODO: rename SDKError? PluginError? GRPCPluginError (GRPC between HC plugin and node)?, or InternalErrorMsg. Can it be merged with error?
+	Request       *TaskRequest           `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
+	At            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskResponse) Reset() {
-	*x = TaskResponse{}
+func (x *ScheduleTaskRequest) Reset() {
+	*x = ScheduleTaskRequest{}
 	mi := &file_internal_proto_cluster_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskResponse) String() string {
+func (x *ScheduleTaskRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskResponse) ProtoMessage() {}
+func (*ScheduleTaskRequest) ProtoMessage() {}
 
-func (x *TaskResponse) ProtoReflect() protoreflect.Message {
+func (x *ScheduleTaskRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_cluster_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -482,81 +741,49 @@ func (x *TaskResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskResponse.ProtoReflect.Descriptor instead.
-func (*TaskResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ScheduleTaskRequest.ProtoReflect.Descriptor instead.
+func (*ScheduleTaskRequest) Descriptor() ([]byte, []int) {
 	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *TaskResponse) GetId() int64 {
-	if x != nil {
-		return x.Id
-	}
-	return 0
-}
-
-func (x *TaskResponse) GetGroupID() int64 {
-	if x != nil && x.GroupID != nil {
-		return *x.GroupID
-	}
-	return 0
-}
-
-func (x *TaskResponse) GetOutput() []byte {
+func (x *ScheduleTaskRequest) GetRequest() *TaskRequest {
 	if x != nil {
-		return x.Output
+		return x.Request
 	}
 	return nil
 }
 
-func (x *TaskResponse) GetError() string {
-	if x != nil {
-		return x.Error
-	}
-	return ""
-}
-
-func (x *TaskResponse) GetRetcode() int32 {
-	if x != nil {
-		return x.Retcode
-	}
-	return 0
-}
-
-func (x *TaskResponse) GetInternalError() InternalError {
-	if x != nil {
-		return x.InternalError
-	}
-	return InternalError_OK
-}
-
-func (x *TaskResponse) GetModuleError() string {
+func (x *ScheduleTaskRequest) GetAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.ModuleError
+		return x.At
 	}
-	return ""
+	return nil
 }
 
-type FwdResponse struct {
-	state         protoimpl.MessageState   `protogen:"open.v1"`
-	Responses     map[string]*TaskResponse `protobuf:"bytes,1,rep,name=responses,proto3" json:"responses,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+// PendingTask is a request scheduled for a later time.
+type PendingTask struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	At            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`
+	Request       *TaskRequest           `protobuf:"bytes,3,opt,name=request,proto3" json:"request,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FwdResponse) Reset() {
-	*x = FwdResponse{}
+func (x *PendingTask) Reset() {
+	*x = PendingTask{}
 	mi := &file_internal_proto_cluster_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FwdResponse) String() string {
+func (x *PendingTask) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FwdResponse) ProtoMessage() {}
+func (*PendingTask) ProtoMessage() {}
 
-func (x *FwdResponse) ProtoReflect() protoreflect.Message {
+func (x *PendingTask) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_proto_cluster_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -568,28 +795,599 @@ func (x *FwdResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FwdResponse.ProtoReflect.Descriptor instead.
-func (*FwdResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use PendingTask.ProtoReflect.Descriptor instead.
+func (*PendingTask) Descriptor() ([]byte, []int) {
 	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *FwdResponse) GetResponses() map[string]*TaskResponse {
+func (x *PendingTask) GetId() int64 {
 	if x != nil {
-		return x.Responses
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PendingTask) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
 	}
 	return nil
 }
 
-type ListNodePluginsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Plugin        map[string]string      `protobuf:"bytes,1,rep,name=plugin,proto3" json:"plugin,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // key=filename, value=checksum
+func (x *PendingTask) GetRequest() *TaskRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+type ListPendingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*PendingTask         `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPendingResponse) Reset() {
+	*x = ListPendingResponse{}
+	mi := &file_internal_proto_cluster_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPendingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPendingResponse) ProtoMessage() {}
+
+func (x *ListPendingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_cluster_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPendingResponse.ProtoReflect.Descriptor instead.
+func (*ListPendingResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListPendingResponse) GetTasks() []*PendingTask {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type CancelPendingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []int64                `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelPendingRequest) Reset() {
+	*x = CancelPendingRequest{}
+	mi := &file_internal_proto_cluster_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelPendingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelPendingRequest) ProtoMessage() {}
+
+func (x *CancelPendingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_cluster_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelPendingRequest.ProtoReflect.Descriptor instead.
+func (*CancelPendingRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CancelPendingRequest) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type CancelPendingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cancelled     []int64                `protobuf:"varint,1,rep,packed,name=cancelled,proto3" json:"cancelled,omitempty"` // The unknown IDs, e.g. already run, are ignored
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelPendingResponse) Reset() {
+	*x = CancelPendingResponse{}
+	mi := &file_internal_proto_cluster_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelPendingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelPendingResponse) ProtoMessage() {}
+
+func (x *CancelPendingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_cluster_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelPendingResponse.ProtoReflect.Descriptor instead.
+func (*CancelPendingResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CancelPendingResponse) GetCancelled() []int64 {
+	if x != nil {
+		return x.Cancelled
+	}
+	return nil
+}
+
+type ResolveTargetsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         map[string]bool        `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // key=node ID, value=connected
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveTargetsResponse) Reset() {
+	*x = ResolveTargetsResponse{}
+	mi := &file_internal_proto_cluster_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveTargetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveTargetsResponse) ProtoMessage() {}
+
+func (x *ResolveTargetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_cluster_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveTargetsResponse.ProtoReflect.Descriptor instead.
+func (*ResolveTargetsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ResolveTargetsResponse) GetNodes() map[string]bool {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type Input struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Args          *structpb.ListValue    `protobuf:"bytes,1,opt,name=args,proto3" json:"args,omitempty"`
+	Options       *structpb.Struct       `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Input) Reset() {
+	*x = Input{}
+	mi := &file_internal_proto_cluster_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Input) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Input) ProtoMessage() {}
+
+func (x *Input) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_cluster_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Input.ProtoReflect.Descriptor instead.
+func (*Input) Descriptor() ([]byte, []int) {
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Input) GetArgs() *structpb.ListValue {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *Input) GetOptions() *structpb.Struct {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type TaskResponse struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Id            int64                    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	GroupID       *int64                   `protobuf:"varint,2,opt,name=groupID,proto3,oneof" json:"groupID,omitempty"`
+	Output        []byte                   `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`
+	Error         string                   `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Retcode       int32                    `protobuf:"varint,5,opt,name=retcode,proto3" json:"retcode,omitempty"`                                                                                 // TODO: remove
+	InternalError InternalError            `protobuf:"varint,6,opt,name=internalError,proto3,enum=proto.InternalError" json:"internalError,omitempty"`                                            // Could be the global error type ( != OK when the task returned an error)
+	ModuleError   string                   `protobuf:"bytes,7,opt,name=moduleError,proto3" json:"moduleError,omitempty"`                                                                          // TODO: rename SDKError? PluginError? GRPCPluginError (GRPC between HC plugin and node)?, or InternalErrorMsg. Can it be merged with error?
+	Progress      *int32                   `protobuf:"varint,8,opt,name=progress,proto3,oneof" json:"progress,omitempty"`                                                                         // Completion percentage (0-100) of a running task, only set on intermediate responses which are not results
+	LockWait      int64                    `protobuf:"varint,9,opt,name=lockWait,proto3" json:"lockWait,omitempty"`                                                                               // Time the task waited on the node for its slot and its lock, in milliseconds
+	LockMode      LockMode                 `protobuf:"varint,10,opt,name=lockMode,proto3,enum=proto.LockMode" json:"lockMode,omitempty"`                                                          // Lock mode the task ran with on the node, the default of the task if the request did not set one
+	Downstream    map[string]*TaskResponse `protobuf:"bytes,11,rep,name=downstream,proto3" json:"downstream,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Responses of the nodes of a syndic (manager connected as a node), key=node ID
+	Running       []*RunningTask           `protobuf:"bytes,12,rep,name=running,proto3" json:"running,omitempty"`                                                                                 // Tasks running on the node for longer than config.LongRunningTask, all of them while it drains at shutdown, only set on the heartbeat responses
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskResponse) Reset() {
+	*x = TaskResponse{}
+	mi := &file_internal_proto_cluster_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskResponse) ProtoMessage() {}
+
+func (x *TaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_cluster_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskResponse.ProtoReflect.Descriptor instead.
+func (*TaskResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TaskResponse) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TaskResponse) GetGroupID() int64 {
+	if x != nil && x.GroupID != nil {
+		return *x.GroupID
+	}
+	return 0
+}
+
+func (x *TaskResponse) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *TaskResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *TaskResponse) GetRetcode() int32 {
+	if x != nil {
+		return x.Retcode
+	}
+	return 0
+}
+
+func (x *TaskResponse) GetInternalError() InternalError {
+	if x != nil {
+		return x.InternalError
+	}
+	return InternalError_OK
+}
+
+func (x *TaskResponse) GetModuleError() string {
+	if x != nil {
+		return x.ModuleError
+	}
+	return ""
+}
+
+func (x *TaskResponse) GetProgress() int32 {
+	if x != nil && x.Progress != nil {
+		return *x.Progress
+	}
+	return 0
+}
+
+func (x *TaskResponse) GetLockWait() int64 {
+	if x != nil {
+		return x.LockWait
+	}
+	return 0
+}
+
+func (x *TaskResponse) GetLockMode() LockMode {
+	if x != nil {
+		return x.LockMode
+	}
+	return LockMode_UNSPECIFIED
+}
+
+func (x *TaskResponse) GetDownstream() map[string]*TaskResponse {
+	if x != nil {
+		return x.Downstream
+	}
+	return nil
+}
+
+func (x *TaskResponse) GetRunning() []*RunningTask {
+	if x != nil {
+		return x.Running
+	}
+	return nil
+}
+
+// RunningTask is a task sent to a node and not answered yet.
+type RunningTask struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          string                 `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"` // Not set by the nodes
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	GroupID       *int64                 `protobuf:"varint,3,opt,name=groupID,proto3,oneof" json:"groupID,omitempty"`
+	Task          string                 `protobuf:"bytes,4,opt,name=task,proto3" json:"task,omitempty"`          // plugin.task
+	Elapsed       int64                  `protobuf:"varint,5,opt,name=elapsed,proto3" json:"elapsed,omitempty"`   // Since the task was sent by the manager, or started by the node, in milliseconds
+	Orphaned      bool                   `protobuf:"varint,6,opt,name=orphaned,proto3" json:"orphaned,omitempty"` // Still running on the node, but the manager stopped waiting for its response
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunningTask) Reset() {
+	*x = RunningTask{}
+	mi := &file_internal_proto_cluster_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunningTask) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunningTask) ProtoMessage() {}
+
+func (x *RunningTask) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_cluster_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunningTask.ProtoReflect.Descriptor instead.
+func (*RunningTask) Descriptor() ([]byte, []int) {
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RunningTask) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *RunningTask) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RunningTask) GetGroupID() int64 {
+	if x != nil && x.GroupID != nil {
+		return *x.GroupID
+	}
+	return 0
+}
+
+func (x *RunningTask) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+func (x *RunningTask) GetElapsed() int64 {
+	if x != nil {
+		return x.Elapsed
+	}
+	return 0
+}
+
+func (x *RunningTask) GetOrphaned() bool {
+	if x != nil {
+		return x.Orphaned
+	}
+	return false
+}
+
+type FwdResponse struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Responses     map[string]*TaskResponse  `protobuf:"bytes,1,rep,name=responses,proto3" json:"responses,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Targets       map[string]DispatchStatus `protobuf:"bytes,2,rep,name=targets,proto3" json:"targets,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value,enum=proto.DispatchStatus"` // Nodes matched by the targets of the run, key=node ID (the syndics, not their nodes)
+	StoppedAt     string                    `protobuf:"bytes,3,opt,name=stopped_at,json=stoppedAt,proto3" json:"stopped_at,omitempty"`                                                                                 // Node whose failure stopped a rolling or fail-fast run, the nodes not done being skipped or cancelled
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FwdResponse) Reset() {
+	*x = FwdResponse{}
+	mi := &file_internal_proto_cluster_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FwdResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FwdResponse) ProtoMessage() {}
+
+func (x *FwdResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_cluster_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FwdResponse.ProtoReflect.Descriptor instead.
+func (*FwdResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FwdResponse) GetResponses() map[string]*TaskResponse {
+	if x != nil {
+		return x.Responses
+	}
+	return nil
+}
+
+func (x *FwdResponse) GetTargets() map[string]DispatchStatus {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *FwdResponse) GetStoppedAt() string {
+	if x != nil {
+		return x.StoppedAt
+	}
+	return ""
+}
+
+type FwdStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          string                 `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Response      *TaskResponse          `protobuf:"bytes,2,opt,name=response,proto3" json:"response,omitempty"` // Progress update if progress is set, final response of the node otherwise
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FwdStreamResponse) Reset() {
+	*x = FwdStreamResponse{}
+	mi := &file_internal_proto_cluster_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FwdStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FwdStreamResponse) ProtoMessage() {}
+
+func (x *FwdStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_cluster_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FwdStreamResponse.ProtoReflect.Descriptor instead.
+func (*FwdStreamResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *FwdStreamResponse) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *FwdStreamResponse) GetResponse() *TaskResponse {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+type ListNodePluginsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Plugin        map[string]string      `protobuf:"bytes,1,rep,name=plugin,proto3" json:"plugin,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // key=filename, value=checksum
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ListNodePluginsResponse) Reset() {
 	*x = ListNodePluginsResponse{}
-	mi := &file_internal_proto_cluster_proto_msgTypes[6]
+	mi := &file_internal_proto_cluster_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -601,7 +1399,7 @@ func (x *ListNodePluginsResponse) String() string {
 func (*ListNodePluginsResponse) ProtoMessage() {}
 
 func (x *ListNodePluginsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_cluster_proto_msgTypes[6]
+	mi := &file_internal_proto_cluster_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -614,7 +1412,7 @@ func (x *ListNodePluginsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListNodePluginsResponse.ProtoReflect.Descriptor instead.
 func (*ListNodePluginsResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{6}
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *ListNodePluginsResponse) GetPlugin() map[string]string {
@@ -624,15 +1422,143 @@ func (x *ListNodePluginsResponse) GetPlugin() map[string]string {
 	return nil
 }
 
+type PluginChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // added, updated, deleted or rejected
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	File          string                 `protobuf:"bytes,3,opt,name=file,proto3" json:"file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PluginChange) Reset() {
+	*x = PluginChange{}
+	mi := &file_internal_proto_cluster_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PluginChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PluginChange) ProtoMessage() {}
+
+func (x *PluginChange) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_cluster_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PluginChange.ProtoReflect.Descriptor instead.
+func (*PluginChange) Descriptor() ([]byte, []int) {
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *PluginChange) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *PluginChange) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PluginChange) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+// PluginSync is a plugin synchronization of a node which changed its plugins or failed.
+type PluginSync struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`       // Set by the manager on reception
+	Changes       []*PluginChange        `protobuf:"bytes,2,rep,name=changes,proto3" json:"changes,omitempty"` // The unchanged plugins are not reported
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PluginSync) Reset() {
+	*x = PluginSync{}
+	mi := &file_internal_proto_cluster_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PluginSync) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PluginSync) ProtoMessage() {}
+
+func (x *PluginSync) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_cluster_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PluginSync.ProtoReflect.Descriptor instead.
+func (*PluginSync) Descriptor() ([]byte, []int) {
+	return file_internal_proto_cluster_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PluginSync) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *PluginSync) GetChanges() []*PluginChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *PluginSync) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 var File_internal_proto_cluster_proto protoreflect.FileDescriptor
 
 const file_internal_proto_cluster_proto_rawDesc = "" +
 	"\n" +
-	"\x1cinternal/proto/cluster.proto\x12\x05proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1cgoogle/protobuf/struct.proto\"\"\n" +
+	"\x1cinternal/proto/cluster.proto\x12\x05proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"X\n" +
 	"\x10HandshakeRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"#\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1a\n" +
+	"\bprotocol\x18\x03 \x01(\rR\bprotocol\"\xec\x01\n" +
 	"\x11HandshakeResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"\x94\x02\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1a\n" +
+	"\bprotocol\x18\x03 \x01(\rR\bprotocol\x12-\n" +
+	"\x12heartbeat_interval\x18\x04 \x01(\rR\x11heartbeatInterval\x120\n" +
+	"\x14max_concurrent_tasks\x18\x05 \x01(\rR\x12maxConcurrentTasks\x120\n" +
+	"\x14max_waiting_requests\x18\x06 \x01(\rR\x12maxWaitingRequests\"\x9e\x06\n" +
 	"\vTaskRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
 	"\agroupID\x18\x02 \x01(\x03H\x00R\agroupID\x88\x01\x01\x12\x16\n" +
@@ -642,12 +1568,49 @@ const file_internal_proto_cluster_proto_rawDesc = "" +
 	"\tlock_mode\x18\x05 \x01(\x0e2\x0f.proto.LockModeR\blockMode\x12\x18\n" +
 	"\atimeout\x18\x06 \x01(\rR\atimeout\x12\x12\n" +
 	"\x04task\x18\a \x01(\tR\x04task\x12\"\n" +
-	"\x05input\x18\b \x01(\v2\f.proto.InputR\x05inputB\n" +
+	"\x05input\x18\b \x01(\v2\f.proto.InputR\x05input\x12\x16\n" +
+	"\x06plugin\x18\t \x01(\tR\x06plugin\x12<\n" +
+	"\bmetadata\x18\n" +
+	" \x03(\v2 .proto.TaskRequest.MetadataEntryR\bmetadata\x12'\n" +
+	"\atargets\x18\v \x03(\v2\r.proto.TargetR\atargets\x12)\n" +
+	"\bexcludes\x18\f \x03(\v2\r.proto.TargetR\bexcludes\x12<\n" +
+	"\x12downstream_targets\x18\r \x03(\v2\r.proto.TargetR\x11downstreamTargets\x12>\n" +
+	"\x13downstream_excludes\x18\x0e \x03(\v2\r.proto.TargetR\x12downstreamExcludes\x12+\n" +
+	"\bpriority\x18\x0f \x01(\x0e2\x0f.proto.PriorityR\bpriority\x12\x1b\n" +
+	"\tfail_fast\x18\x10 \x01(\bR\bfailFast\x12\x18\n" +
+	"\arolling\x18\x11 \x01(\bR\arolling\x12!\n" +
+	"\fmax_failures\x18\x12 \x01(\rR\vmaxFailures\x12\x1c\n" +
+	"\ttransient\x18\x13 \x01(\bR\ttransient\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\n" +
 	"\n" +
-	"\b_groupID\"j\n" +
+	"\b_groupID\"G\n" +
+	"\x06Target\x12\x16\n" +
+	"\x06target\x18\x01 \x01(\tR\x06target\x12%\n" +
+	"\x04mode\x18\x02 \x01(\x0e2\x11.proto.TargetModeR\x04mode\"o\n" +
+	"\x13ScheduleTaskRequest\x12,\n" +
+	"\arequest\x18\x01 \x01(\v2\x12.proto.TaskRequestR\arequest\x12*\n" +
+	"\x02at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\"w\n" +
+	"\vPendingTask\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12*\n" +
+	"\x02at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\x12,\n" +
+	"\arequest\x18\x03 \x01(\v2\x12.proto.TaskRequestR\arequest\"?\n" +
+	"\x13ListPendingResponse\x12(\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x12.proto.PendingTaskR\x05tasks\"(\n" +
+	"\x14CancelPendingRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x03R\x03ids\"5\n" +
+	"\x15CancelPendingResponse\x12\x1c\n" +
+	"\tcancelled\x18\x01 \x03(\x03R\tcancelled\"\x92\x01\n" +
+	"\x16ResolveTargetsResponse\x12>\n" +
+	"\x05nodes\x18\x01 \x03(\v2(.proto.ResolveTargetsResponse.NodesEntryR\x05nodes\x1a8\n" +
+	"\n" +
+	"NodesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\bR\x05value:\x028\x01\"j\n" +
 	"\x05Input\x12.\n" +
 	"\x04args\x18\x01 \x01(\v2\x1a.google.protobuf.ListValueR\x04args\x121\n" +
-	"\aoptions\x18\x02 \x01(\v2\x17.google.protobuf.StructR\aoptions\"\xef\x01\n" +
+	"\aoptions\x18\x02 \x01(\v2\x17.google.protobuf.StructR\aoptions\"\xad\x04\n" +
 	"\fTaskResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
 	"\agroupID\x18\x02 \x01(\x03H\x00R\agroupID\x88\x01\x01\x12\x16\n" +
@@ -655,19 +1618,64 @@ const file_internal_proto_cluster_proto_rawDesc = "" +
 	"\x05error\x18\x04 \x01(\tR\x05error\x12\x18\n" +
 	"\aretcode\x18\x05 \x01(\x05R\aretcode\x12:\n" +
 	"\rinternalError\x18\x06 \x01(\x0e2\x14.proto.InternalErrorR\rinternalError\x12 \n" +
-	"\vmoduleError\x18\a \x01(\tR\vmoduleErrorB\n" +
+	"\vmoduleError\x18\a \x01(\tR\vmoduleError\x12\x1f\n" +
+	"\bprogress\x18\b \x01(\x05H\x01R\bprogress\x88\x01\x01\x12\x1a\n" +
+	"\blockWait\x18\t \x01(\x03R\blockWait\x12+\n" +
+	"\blockMode\x18\n" +
+	" \x01(\x0e2\x0f.proto.LockModeR\blockMode\x12C\n" +
+	"\n" +
+	"downstream\x18\v \x03(\v2#.proto.TaskResponse.DownstreamEntryR\n" +
+	"downstream\x12,\n" +
+	"\arunning\x18\f \x03(\v2\x12.proto.RunningTaskR\arunning\x1aR\n" +
+	"\x0fDownstreamEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12)\n" +
+	"\x05value\x18\x02 \x01(\v2\x13.proto.TaskResponseR\x05value:\x028\x01B\n" +
 	"\n" +
-	"\b_groupID\"\xa1\x01\n" +
+	"\b_groupIDB\v\n" +
+	"\t_progress\"\xa6\x01\n" +
+	"\vRunningTask\x12\x12\n" +
+	"\x04node\x18\x01 \x01(\tR\x04node\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x1d\n" +
+	"\agroupID\x18\x03 \x01(\x03H\x00R\agroupID\x88\x01\x01\x12\x12\n" +
+	"\x04task\x18\x04 \x01(\tR\x04task\x12\x18\n" +
+	"\aelapsed\x18\x05 \x01(\x03R\aelapsed\x12\x1a\n" +
+	"\borphaned\x18\x06 \x01(\bR\borphanedB\n" +
+	"\n" +
+	"\b_groupID\"\xce\x02\n" +
 	"\vFwdResponse\x12?\n" +
-	"\tresponses\x18\x01 \x03(\v2!.proto.FwdResponse.ResponsesEntryR\tresponses\x1aQ\n" +
+	"\tresponses\x18\x01 \x03(\v2!.proto.FwdResponse.ResponsesEntryR\tresponses\x129\n" +
+	"\atargets\x18\x02 \x03(\v2\x1f.proto.FwdResponse.TargetsEntryR\atargets\x12\x1d\n" +
+	"\n" +
+	"stopped_at\x18\x03 \x01(\tR\tstoppedAt\x1aQ\n" +
 	"\x0eResponsesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12)\n" +
-	"\x05value\x18\x02 \x01(\v2\x13.proto.TaskResponseR\x05value:\x028\x01\"\x98\x01\n" +
+	"\x05value\x18\x02 \x01(\v2\x13.proto.TaskResponseR\x05value:\x028\x01\x1aQ\n" +
+	"\fTargetsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12+\n" +
+	"\x05value\x18\x02 \x01(\x0e2\x15.proto.DispatchStatusR\x05value:\x028\x01\"X\n" +
+	"\x11FwdStreamResponse\x12\x12\n" +
+	"\x04node\x18\x01 \x01(\tR\x04node\x12/\n" +
+	"\bresponse\x18\x02 \x01(\v2\x13.proto.TaskResponseR\bresponse\"\x98\x01\n" +
 	"\x17ListNodePluginsResponse\x12B\n" +
 	"\x06plugin\x18\x01 \x03(\v2*.proto.ListNodePluginsResponse.PluginEntryR\x06plugin\x1a9\n" +
 	"\vPluginEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01*\xb5\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"J\n" +
+	"\fPluginChange\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04file\x18\x03 \x01(\tR\x04file\"\x81\x01\n" +
+	"\n" +
+	"PluginSync\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12-\n" +
+	"\achanges\x18\x02 \x03(\v2\x13.proto.PluginChangeR\achanges\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error*\x81\x01\n" +
+	"\x0eDispatchStatus\x12\x14\n" +
+	"\x10DISPATCH_UNKNOWN\x10\x00\x12\x11\n" +
+	"\rDISPATCH_SENT\x10\x01\x12\x19\n" +
+	"\x15DISPATCH_DISCONNECTED\x10\x02\x12\x15\n" +
+	"\x11DISPATCH_REJECTED\x10\x03\x12\x14\n" +
+	"\x10DISPATCH_SKIPPED\x10\x04*\x90\x02\n" +
 	"\rInternalError\x12\x06\n" +
 	"\x02OK\x10\x00\x12\v\n" +
 	"\aTIMEOUT\x10\x01\x12\x13\n" +
@@ -680,7 +1688,13 @@ const file_internal_proto_cluster_proto_rawDesc = "" +
 	"\fMODULE_ERROR\x10\x06\x12\x11\n" +
 	"\rDISCONNECTING\x10\a\x12\x10\n" +
 	"\fDISCONNECTED\x10\b\x12\x11\n" +
-	"\rUNKNOWN_ERROR\x10\t*N\n" +
+	"\rUNKNOWN_ERROR\x10\t\x12\x10\n" +
+	"\fMODULE_PANIC\x10\n" +
+	"\x12\x14\n" +
+	"\x10DOWNSTREAM_ERROR\x10\v\x12\x15\n" +
+	"\x11NODE_UNRESPONSIVE\x10\f\x12\v\n" +
+	"\aSKIPPED\x10\r\x12\r\n" +
+	"\tCANCELLED\x10\x0e*N\n" +
 	"\n" +
 	"TargetMode\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\t\n" +
@@ -688,18 +1702,29 @@ const file_internal_proto_cluster_proto_rawDesc = "" +
 	"\x04LIST\x10\x02\x12\b\n" +
 	"\x04GLOB\x10\x03\x12\t\n" +
 	"\x05REGEX\x10\x04\x12\t\n" +
-	"\x05QUERY\x10\x05*B\n" +
+	"\x05QUERY\x10\x05*D\n" +
+	"\bPriority\x12\x13\n" +
+	"\x0fPRIORITY_NORMAL\x10\x00\x12\x11\n" +
+	"\rPRIORITY_HIGH\x10\x01\x12\x10\n" +
+	"\fPRIORITY_LOW\x10\x02*B\n" +
 	"\bLockMode\x12\x0f\n" +
 	"\vUNSPECIFIED\x10\x00\x12\v\n" +
 	"\aNO_LOCK\x10\x01\x12\t\n" +
 	"\x05WRITE\x10\x02\x12\r\n" +
-	"\tEXCLUSIVE\x10\x032\xcd\x01\n" +
+	"\tEXCLUSIVE\x10\x032\x8c\x02\n" +
 	"\aCluster\x12>\n" +
 	"\tHandshake\x12\x17.proto.HandshakeRequest\x1a\x18.proto.HandshakeResponse\x127\n" +
 	"\bExecTask\x12\x13.proto.TaskResponse\x1a\x12.proto.TaskRequest(\x010\x01\x12I\n" +
-	"\x0fListNodePlugins\x12\x16.google.protobuf.Empty\x1a\x1e.proto.ListNodePluginsResponse2Y\n" +
+	"\x0fListNodePlugins\x12\x16.google.protobuf.Empty\x1a\x1e.proto.ListNodePluginsResponse\x12=\n" +
+	"\x10ReportPluginSync\x12\x11.proto.PluginSync\x1a\x16.google.protobuf.Empty2\xab\x03\n" +
 	"\tForwarder\x12L\n" +
-	"\bExecTask\x12\x12.proto.TaskRequest\x1a\x12.proto.FwdResponse\"\x18\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/v1/task/execB.Z,github.com/jackadi-io/jackadi/internal/protob\x06proto3"
+	"\bExecTask\x12\x12.proto.TaskRequest\x1a\x12.proto.FwdResponse\"\x18\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/v1/task/exec\x12<\n" +
+	"\n" +
+	"StreamTask\x12\x12.proto.TaskRequest\x1a\x18.proto.FwdStreamResponse0\x01\x12C\n" +
+	"\x0eResolveTargets\x12\x12.proto.TaskRequest\x1a\x1d.proto.ResolveTargetsResponse\x12>\n" +
+	"\fScheduleTask\x12\x1a.proto.ScheduleTaskRequest\x1a\x12.proto.PendingTask\x12A\n" +
+	"\vListPending\x12\x16.google.protobuf.Empty\x1a\x1a.proto.ListPendingResponse\x12J\n" +
+	"\rCancelPending\x12\x1b.proto.CancelPendingRequest\x1a\x1c.proto.CancelPendingResponseB.Z,github.com/jackadi-io/jackadi/internal/protob\x06proto3"
 
 var (
 	file_internal_proto_cluster_proto_rawDescOnce sync.Once
@@ -713,48 +1738,100 @@ func file_internal_proto_cluster_proto_rawDescGZIP() []byte {
 	return file_internal_proto_cluster_proto_rawDescData
 }
 
-var file_internal_proto_cluster_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_internal_proto_cluster_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_internal_proto_cluster_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_internal_proto_cluster_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_internal_proto_cluster_proto_goTypes = []any{
-	(InternalError)(0),              // 0: proto.InternalError
-	(TargetMode)(0),                 // 1: proto.TargetMode
-	(LockMode)(0),                   // 2: proto.LockMode
-	(*HandshakeRequest)(nil),        // 3: proto.HandshakeRequest
-	(*HandshakeResponse)(nil),       // 4: proto.HandshakeResponse
-	(*TaskRequest)(nil),             // 5: proto.TaskRequest
-	(*Input)(nil),                   // 6: proto.Input
-	(*TaskResponse)(nil),            // 7: proto.TaskResponse
-	(*FwdResponse)(nil),             // 8: proto.FwdResponse
-	(*ListNodePluginsResponse)(nil), // 9: proto.ListNodePluginsResponse
-	nil,                             // 10: proto.FwdResponse.ResponsesEntry
-	nil,                             // 11: proto.ListNodePluginsResponse.PluginEntry
-	(*structpb.ListValue)(nil),      // 12: google.protobuf.ListValue
-	(*structpb.Struct)(nil),         // 13: google.protobuf.Struct
-	(*emptypb.Empty)(nil),           // 14: google.protobuf.Empty
+	(DispatchStatus)(0),             // 0: proto.DispatchStatus
+	(InternalError)(0),              // 1: proto.InternalError
+	(TargetMode)(0),                 // 2: proto.TargetMode
+	(Priority)(0),                   // 3: proto.Priority
+	(LockMode)(0),                   // 4: proto.LockMode
+	(*HandshakeRequest)(nil),        // 5: proto.HandshakeRequest
+	(*HandshakeResponse)(nil),       // 6: proto.HandshakeResponse
+	(*TaskRequest)(nil),             // 7: proto.TaskRequest
+	(*Target)(nil),                  // 8: proto.Target
+	(*ScheduleTaskRequest)(nil),     // 9: proto.ScheduleTaskRequest
+	(*PendingTask)(nil),             // 10: proto.PendingTask
+	(*ListPendingResponse)(nil),     // 11: proto.ListPendingResponse
+	(*CancelPendingRequest)(nil),    // 12: proto.CancelPendingRequest
+	(*CancelPendingResponse)(nil),   // 13: proto.CancelPendingResponse
+	(*ResolveTargetsResponse)(nil),  // 14: proto.ResolveTargetsResponse
+	(*Input)(nil),                   // 15: proto.Input
+	(*TaskResponse)(nil),            // 16: proto.TaskResponse
+	(*RunningTask)(nil),             // 17: proto.RunningTask
+	(*FwdResponse)(nil),             // 18: proto.FwdResponse
+	(*FwdStreamResponse)(nil),       // 19: proto.FwdStreamResponse
+	(*ListNodePluginsResponse)(nil), // 20: proto.ListNodePluginsResponse
+	(*PluginChange)(nil),            // 21: proto.PluginChange
+	(*PluginSync)(nil),              // 22: proto.PluginSync
+	nil,                             // 23: proto.TaskRequest.MetadataEntry
+	nil,                             // 24: proto.ResolveTargetsResponse.NodesEntry
+	nil,                             // 25: proto.TaskResponse.DownstreamEntry
+	nil,                             // 26: proto.FwdResponse.ResponsesEntry
+	nil,                             // 27: proto.FwdResponse.TargetsEntry
+	nil,                             // 28: proto.ListNodePluginsResponse.PluginEntry
+	(*timestamppb.Timestamp)(nil),   // 29: google.protobuf.Timestamp
+	(*structpb.ListValue)(nil),      // 30: google.protobuf.ListValue
+	(*structpb.Struct)(nil),         // 31: google.protobuf.Struct
+	(*emptypb.Empty)(nil),           // 32: google.protobuf.Empty
 }
 var file_internal_proto_cluster_proto_depIdxs = []int32{
-	1,  // 0: proto.TaskRequest.target_mode:type_name -> proto.TargetMode
-	2,  // 1: proto.TaskRequest.lock_mode:type_name -> proto.LockMode
-	6,  // 2: proto.TaskRequest.input:type_name -> proto.Input
-	12, // 3: proto.Input.args:type_name -> google.protobuf.ListValue
-	13, // 4: proto.Input.options:type_name -> google.protobuf.Struct
-	0,  // 5: proto.TaskResponse.internalError:type_name -> proto.InternalError
-	10, // 6: proto.FwdResponse.responses:type_name -> proto.FwdResponse.ResponsesEntry
-	11, // 7: proto.ListNodePluginsResponse.plugin:type_name -> proto.ListNodePluginsResponse.PluginEntry
-	7,  // 8: proto.FwdResponse.ResponsesEntry.value:type_name -> proto.TaskResponse
-	3,  // 9: proto.Cluster.Handshake:input_type -> proto.HandshakeRequest
-	7,  // 10: proto.Cluster.ExecTask:input_type -> proto.TaskResponse
-	14, // 11: proto.Cluster.ListNodePlugins:input_type -> google.protobuf.Empty
-	5,  // 12: proto.Forwarder.ExecTask:input_type -> proto.TaskRequest
-	4,  // 13: proto.Cluster.Handshake:output_type -> proto.HandshakeResponse
-	5,  // 14: proto.Cluster.ExecTask:output_type -> proto.TaskRequest
-	9,  // 15: proto.Cluster.ListNodePlugins:output_type -> proto.ListNodePluginsResponse
-	8,  // 16: proto.Forwarder.ExecTask:output_type -> proto.FwdResponse
-	13, // [13:17] is the sub-list for method output_type
-	9,  // [9:13] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+	2,  // 0: proto.TaskRequest.target_mode:type_name -> proto.TargetMode
+	4,  // 1: proto.TaskRequest.lock_mode:type_name -> proto.LockMode
+	15, // 2: proto.TaskRequest.input:type_name -> proto.Input
+	23, // 3: proto.TaskRequest.metadata:type_name -> proto.TaskRequest.MetadataEntry
+	8,  // 4: proto.TaskRequest.targets:type_name -> proto.Target
+	8,  // 5: proto.TaskRequest.excludes:type_name -> proto.Target
+	8,  // 6: proto.TaskRequest.downstream_targets:type_name -> proto.Target
+	8,  // 7: proto.TaskRequest.downstream_excludes:type_name -> proto.Target
+	3,  // 8: proto.TaskRequest.priority:type_name -> proto.Priority
+	2,  // 9: proto.Target.mode:type_name -> proto.TargetMode
+	7,  // 10: proto.ScheduleTaskRequest.request:type_name -> proto.TaskRequest
+	29, // 11: proto.ScheduleTaskRequest.at:type_name -> google.protobuf.Timestamp
+	29, // 12: proto.PendingTask.at:type_name -> google.protobuf.Timestamp
+	7,  // 13: proto.PendingTask.request:type_name -> proto.TaskRequest
+	10, // 14: proto.ListPendingResponse.tasks:type_name -> proto.PendingTask
+	24, // 15: proto.ResolveTargetsResponse.nodes:type_name -> proto.ResolveTargetsResponse.NodesEntry
+	30, // 16: proto.Input.args:type_name -> google.protobuf.ListValue
+	31, // 17: proto.Input.options:type_name -> google.protobuf.Struct
+	1,  // 18: proto.TaskResponse.internalError:type_name -> proto.InternalError
+	4,  // 19: proto.TaskResponse.lockMode:type_name -> proto.LockMode
+	25, // 20: proto.TaskResponse.downstream:type_name -> proto.TaskResponse.DownstreamEntry
+	17, // 21: proto.TaskResponse.running:type_name -> proto.RunningTask
+	26, // 22: proto.FwdResponse.responses:type_name -> proto.FwdResponse.ResponsesEntry
+	27, // 23: proto.FwdResponse.targets:type_name -> proto.FwdResponse.TargetsEntry
+	16, // 24: proto.FwdStreamResponse.response:type_name -> proto.TaskResponse
+	28, // 25: proto.ListNodePluginsResponse.plugin:type_name -> proto.ListNodePluginsResponse.PluginEntry
+	29, // 26: proto.PluginSync.time:type_name -> google.protobuf.Timestamp
+	21, // 27: proto.PluginSync.changes:type_name -> proto.PluginChange
+	16, // 28: proto.TaskResponse.DownstreamEntry.value:type_name -> proto.TaskResponse
+	16, // 29: proto.FwdResponse.ResponsesEntry.value:type_name -> proto.TaskResponse
+	0,  // 30: proto.FwdResponse.TargetsEntry.value:type_name -> proto.DispatchStatus
+	5,  // 31: proto.Cluster.Handshake:input_type -> proto.HandshakeRequest
+	16, // 32: proto.Cluster.ExecTask:input_type -> proto.TaskResponse
+	32, // 33: proto.Cluster.ListNodePlugins:input_type -> google.protobuf.Empty
+	22, // 34: proto.Cluster.ReportPluginSync:input_type -> proto.PluginSync
+	7,  // 35: proto.Forwarder.ExecTask:input_type -> proto.TaskRequest
+	7,  // 36: proto.Forwarder.StreamTask:input_type -> proto.TaskRequest
+	7,  // 37: proto.Forwarder.ResolveTargets:input_type -> proto.TaskRequest
+	9,  // 38: proto.Forwarder.ScheduleTask:input_type -> proto.ScheduleTaskRequest
+	32, // 39: proto.Forwarder.ListPending:input_type -> google.protobuf.Empty
+	12, // 40: proto.Forwarder.CancelPending:input_type -> proto.CancelPendingRequest
+	6,  // 41: proto.Cluster.Handshake:output_type -> proto.HandshakeResponse
+	7,  // 42: proto.Cluster.ExecTask:output_type -> proto.TaskRequest
+	20, // 43: proto.Cluster.ListNodePlugins:output_type -> proto.ListNodePluginsResponse
+	32, // 44: proto.Cluster.ReportPluginSync:output_type -> google.protobuf.Empty
+	18, // 45: proto.Forwarder.ExecTask:output_type -> proto.FwdResponse
+	19, // 46: proto.Forwarder.StreamTask:output_type -> proto.FwdStreamResponse
+	14, // 47: proto.Forwarder.ResolveTargets:output_type -> proto.ResolveTargetsResponse
+	10, // 48: proto.Forwarder.ScheduleTask:output_type -> proto.PendingTask
+	11, // 49: proto.Forwarder.ListPending:output_type -> proto.ListPendingResponse
+	13, // 50: proto.Forwarder.CancelPending:output_type -> proto.CancelPendingResponse
+	41, // [41:51] is the sub-list for method output_type
+	31, // [31:41] is the sub-list for method input_type
+	31, // [31:31] is the sub-list for extension type_name
+	31, // [31:31] is the sub-list for extension extendee
+	0,  // [0:31] is the sub-list for field type_name
 }
 
 func init() { file_internal_proto_cluster_proto_init() }
@@ -763,14 +1840,15 @@ func file_internal_proto_cluster_proto_init() {
 		return
 	}
 	file_internal_proto_cluster_proto_msgTypes[2].OneofWrappers = []any{}
-	file_internal_proto_cluster_proto_msgTypes[4].OneofWrappers = []any{}
+	file_internal_proto_cluster_proto_msgTypes[11].OneofWrappers = []any{}
+	file_internal_proto_cluster_proto_msgTypes[12].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_cluster_proto_rawDesc), len(file_internal_proto_cluster_proto_rawDesc)),
-			NumEnums:      3,
-			NumMessages:   9,
+			NumEnums:      5,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
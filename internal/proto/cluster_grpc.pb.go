@@ -20,9 +20,10 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Cluster_Handshake_FullMethodName       = "/proto.Cluster/Handshake"
-	Cluster_ExecTask_FullMethodName        = "/proto.Cluster/ExecTask"
-	Cluster_ListNodePlugins_FullMethodName = "/proto.Cluster/ListNodePlugins"
+	Cluster_Handshake_FullMethodName        = "/proto.Cluster/Handshake"
+	Cluster_ExecTask_FullMethodName         = "/proto.Cluster/ExecTask"
+	Cluster_ListNodePlugins_FullMethodName  = "/proto.Cluster/ListNodePlugins"
+	Cluster_ReportPluginSync_FullMethodName = "/proto.Cluster/ReportPluginSync"
 )
 
 // ClusterClient is the client API for Cluster service.
@@ -32,6 +33,7 @@ type ClusterClient interface {
 	Handshake(ctx context.Context, in *HandshakeRequest, opts ...grpc.CallOption) (*HandshakeResponse, error)
 	ExecTask(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[TaskResponse, TaskRequest], error)
 	ListNodePlugins(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListNodePluginsResponse, error)
+	ReportPluginSync(ctx context.Context, in *PluginSync, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type clusterClient struct {
@@ -75,6 +77,16 @@ func (c *clusterClient) ListNodePlugins(ctx context.Context, in *emptypb.Empty,
 	return out, nil
 }
 
+func (c *clusterClient) ReportPluginSync(ctx context.Context, in *PluginSync, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Cluster_ReportPluginSync_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ClusterServer is the server API for Cluster service.
 // All implementations should embed UnimplementedClusterServer
 // for forward compatibility.
@@ -82,6 +94,7 @@ type ClusterServer interface {
 	Handshake(context.Context, *HandshakeRequest) (*HandshakeResponse, error)
 	ExecTask(grpc.BidiStreamingServer[TaskResponse, TaskRequest]) error
 	ListNodePlugins(context.Context, *emptypb.Empty) (*ListNodePluginsResponse, error)
+	ReportPluginSync(context.Context, *PluginSync) (*emptypb.Empty, error)
 }
 
 // UnimplementedClusterServer should be embedded to have
@@ -100,6 +113,9 @@ func (UnimplementedClusterServer) ExecTask(grpc.BidiStreamingServer[TaskResponse
 func (UnimplementedClusterServer) ListNodePlugins(context.Context, *emptypb.Empty) (*ListNodePluginsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListNodePlugins not implemented")
 }
+func (UnimplementedClusterServer) ReportPluginSync(context.Context, *PluginSync) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReportPluginSync not implemented")
+}
 func (UnimplementedClusterServer) testEmbeddedByValue() {}
 
 // UnsafeClusterServer may be embedded to opt out of forward compatibility for this service.
@@ -163,6 +179,24 @@ func _Cluster_ListNodePlugins_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Cluster_ReportPluginSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PluginSync)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServer).ReportPluginSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cluster_ReportPluginSync_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServer).ReportPluginSync(ctx, req.(*PluginSync))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Cluster_ServiceDesc is the grpc.ServiceDesc for Cluster service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -178,6 +212,10 @@ var Cluster_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListNodePlugins",
 			Handler:    _Cluster_ListNodePlugins_Handler,
 		},
+		{
+			MethodName: "ReportPluginSync",
+			Handler:    _Cluster_ReportPluginSync_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -191,7 +229,12 @@ var Cluster_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	Forwarder_ExecTask_FullMethodName = "/proto.Forwarder/ExecTask"
+	Forwarder_ExecTask_FullMethodName       = "/proto.Forwarder/ExecTask"
+	Forwarder_StreamTask_FullMethodName     = "/proto.Forwarder/StreamTask"
+	Forwarder_ResolveTargets_FullMethodName = "/proto.Forwarder/ResolveTargets"
+	Forwarder_ScheduleTask_FullMethodName   = "/proto.Forwarder/ScheduleTask"
+	Forwarder_ListPending_FullMethodName    = "/proto.Forwarder/ListPending"
+	Forwarder_CancelPending_FullMethodName  = "/proto.Forwarder/CancelPending"
 )
 
 // ForwarderClient is the client API for Forwarder service.
@@ -199,6 +242,19 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ForwarderClient interface {
 	ExecTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*FwdResponse, error)
+	// StreamTask is ExecTask streaming the progress updates and each response as soon as it is received.
+	// The dispatch status of the targeted nodes is sent in the trailer (see config.TargetsTrailerKey), as a
+	// serialized FwdResponse without responses: the older versions of jack fail on a message without response.
+	StreamTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FwdStreamResponse], error)
+	// ResolveTargets returns the nodes targeted by the request, without sending it.
+	ResolveTargets(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*ResolveTargetsResponse, error)
+	// ScheduleTask stores the request until the given time (e.g. the opening of a maintenance window), then the
+	// manager runs it and stores its results like any other run.
+	ScheduleTask(ctx context.Context, in *ScheduleTaskRequest, opts ...grpc.CallOption) (*PendingTask, error)
+	// ListPending returns the scheduled requests not run yet, the earliest first.
+	ListPending(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListPendingResponse, error)
+	// CancelPending removes scheduled requests before they run.
+	CancelPending(ctx context.Context, in *CancelPendingRequest, opts ...grpc.CallOption) (*CancelPendingResponse, error)
 }
 
 type forwarderClient struct {
@@ -219,11 +275,83 @@ func (c *forwarderClient) ExecTask(ctx context.Context, in *TaskRequest, opts ..
 	return out, nil
 }
 
+func (c *forwarderClient) StreamTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FwdStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Forwarder_ServiceDesc.Streams[0], Forwarder_StreamTask_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TaskRequest, FwdStreamResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Forwarder_StreamTaskClient = grpc.ServerStreamingClient[FwdStreamResponse]
+
+func (c *forwarderClient) ResolveTargets(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*ResolveTargetsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolveTargetsResponse)
+	err := c.cc.Invoke(ctx, Forwarder_ResolveTargets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forwarderClient) ScheduleTask(ctx context.Context, in *ScheduleTaskRequest, opts ...grpc.CallOption) (*PendingTask, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PendingTask)
+	err := c.cc.Invoke(ctx, Forwarder_ScheduleTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forwarderClient) ListPending(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListPendingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPendingResponse)
+	err := c.cc.Invoke(ctx, Forwarder_ListPending_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forwarderClient) CancelPending(ctx context.Context, in *CancelPendingRequest, opts ...grpc.CallOption) (*CancelPendingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelPendingResponse)
+	err := c.cc.Invoke(ctx, Forwarder_CancelPending_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ForwarderServer is the server API for Forwarder service.
 // All implementations should embed UnimplementedForwarderServer
 // for forward compatibility.
 type ForwarderServer interface {
 	ExecTask(context.Context, *TaskRequest) (*FwdResponse, error)
+	// StreamTask is ExecTask streaming the progress updates and each response as soon as it is received.
+	// The dispatch status of the targeted nodes is sent in the trailer (see config.TargetsTrailerKey), as a
+	// serialized FwdResponse without responses: the older versions of jack fail on a message without response.
+	StreamTask(*TaskRequest, grpc.ServerStreamingServer[FwdStreamResponse]) error
+	// ResolveTargets returns the nodes targeted by the request, without sending it.
+	ResolveTargets(context.Context, *TaskRequest) (*ResolveTargetsResponse, error)
+	// ScheduleTask stores the request until the given time (e.g. the opening of a maintenance window), then the
+	// manager runs it and stores its results like any other run.
+	ScheduleTask(context.Context, *ScheduleTaskRequest) (*PendingTask, error)
+	// ListPending returns the scheduled requests not run yet, the earliest first.
+	ListPending(context.Context, *emptypb.Empty) (*ListPendingResponse, error)
+	// CancelPending removes scheduled requests before they run.
+	CancelPending(context.Context, *CancelPendingRequest) (*CancelPendingResponse, error)
 }
 
 // UnimplementedForwarderServer should be embedded to have
@@ -236,6 +364,21 @@ type UnimplementedForwarderServer struct{}
 func (UnimplementedForwarderServer) ExecTask(context.Context, *TaskRequest) (*FwdResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ExecTask not implemented")
 }
+func (UnimplementedForwarderServer) StreamTask(*TaskRequest, grpc.ServerStreamingServer[FwdStreamResponse]) error {
+	return status.Error(codes.Unimplemented, "method StreamTask not implemented")
+}
+func (UnimplementedForwarderServer) ResolveTargets(context.Context, *TaskRequest) (*ResolveTargetsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResolveTargets not implemented")
+}
+func (UnimplementedForwarderServer) ScheduleTask(context.Context, *ScheduleTaskRequest) (*PendingTask, error) {
+	return nil, status.Error(codes.Unimplemented, "method ScheduleTask not implemented")
+}
+func (UnimplementedForwarderServer) ListPending(context.Context, *emptypb.Empty) (*ListPendingResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListPending not implemented")
+}
+func (UnimplementedForwarderServer) CancelPending(context.Context, *CancelPendingRequest) (*CancelPendingResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelPending not implemented")
+}
 func (UnimplementedForwarderServer) testEmbeddedByValue() {}
 
 // UnsafeForwarderServer may be embedded to opt out of forward compatibility for this service.
@@ -274,6 +417,89 @@ func _Forwarder_ExecTask_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Forwarder_StreamTask_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TaskRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ForwarderServer).StreamTask(m, &grpc.GenericServerStream[TaskRequest, FwdStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Forwarder_StreamTaskServer = grpc.ServerStreamingServer[FwdStreamResponse]
+
+func _Forwarder_ResolveTargets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForwarderServer).ResolveTargets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Forwarder_ResolveTargets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForwarderServer).ResolveTargets(ctx, req.(*TaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Forwarder_ScheduleTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForwarderServer).ScheduleTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Forwarder_ScheduleTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForwarderServer).ScheduleTask(ctx, req.(*ScheduleTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Forwarder_ListPending_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForwarderServer).ListPending(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Forwarder_ListPending_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForwarderServer).ListPending(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Forwarder_CancelPending_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelPendingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForwarderServer).CancelPending(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Forwarder_CancelPending_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForwarderServer).CancelPending(ctx, req.(*CancelPendingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Forwarder_ServiceDesc is the grpc.ServiceDesc for Forwarder service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -285,7 +511,29 @@ var Forwarder_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ExecTask",
 			Handler:    _Forwarder_ExecTask_Handler,
 		},
+		{
+			MethodName: "ResolveTargets",
+			Handler:    _Forwarder_ResolveTargets_Handler,
+		},
+		{
+			MethodName: "ScheduleTask",
+			Handler:    _Forwarder_ScheduleTask_Handler,
+		},
+		{
+			MethodName: "ListPending",
+			Handler:    _Forwarder_ListPending_Handler,
+		},
+		{
+			MethodName: "CancelPending",
+			Handler:    _Forwarder_CancelPending_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTask",
+			Handler:       _Forwarder_StreamTask_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/proto/cluster.proto",
 }
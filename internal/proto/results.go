@@ -0,0 +1,9 @@
+package proto
+
+// FailureRate returns the share of the results which are not a success, 0 without result.
+func (x *ResultsCounts) FailureRate() float64 {
+	if x.GetTotal() == 0 {
+		return 0
+	}
+	return float64(x.GetTotal()-x.GetSuccess()) / float64(x.GetTotal())
+}
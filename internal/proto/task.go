@@ -0,0 +1,80 @@
+package proto
+
+import (
+	"strings"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+)
+
+// PluginTask returns the plugin and the task requested.
+//
+// The plugin field is used when set. Otherwise, the deprecated plugin.task form of the task field is split on
+// the first separator, and a task without separator names both the plugin and the task.
+func (x *TaskRequest) PluginTask() (plugin, task string) {
+	if x.GetPlugin() != "" {
+		return x.GetPlugin(), x.GetTask()
+	}
+	plugin, task, ok := strings.Cut(x.GetTask(), config.PluginSeparator)
+	if !ok {
+		return plugin, plugin
+	}
+	return plugin, task
+}
+
+// FullTask returns the requested task in the plugin.task form, as displayed to the users.
+func (x *TaskRequest) FullTask() string {
+	if x.GetPlugin() != "" {
+		return x.GetPlugin() + config.PluginSeparator + x.GetTask()
+	}
+	return x.GetTask()
+}
+
+// AllTargets returns the targets of the request: the target field, unless empty while several targets are set,
+// and the targets field.
+func (x *TaskRequest) AllTargets() []*Target {
+	targets := x.GetTargets()
+	if x.GetTarget() == "" && len(targets) > 0 {
+		return targets
+	}
+	return append([]*Target{{Target: x.GetTarget(), Mode: x.GetTargetMode()}}, targets...)
+}
+
+// TargetsString returns the targets of the request, and its exclusions, as displayed to the users.
+//
+// The targets of the nodes of the syndics follow a syndic separator, e.g. "glob:eu-* / glob:web-*".
+func (x *TaskRequest) TargetsString() string {
+	var b strings.Builder
+	for i, t := range x.AllTargets() {
+		if i > 0 {
+			b.WriteString(" + ")
+		}
+		b.WriteString(targetString(t))
+	}
+	for _, t := range x.GetExcludes() {
+		b.WriteString(" - ")
+		b.WriteString(targetString(t))
+	}
+	if len(x.GetDownstreamTargets()) == 0 && len(x.GetDownstreamExcludes()) == 0 {
+		return b.String()
+	}
+
+	b.WriteString(" " + config.SyndicSeparator + " ")
+	if len(x.GetDownstreamTargets()) == 0 {
+		b.WriteString("all")
+	}
+	for i, t := range x.GetDownstreamTargets() {
+		if i > 0 {
+			b.WriteString(" + ")
+		}
+		b.WriteString(targetString(t))
+	}
+	for _, t := range x.GetDownstreamExcludes() {
+		b.WriteString(" - ")
+		b.WriteString(targetString(t))
+	}
+	return b.String()
+}
+
+func targetString(t *Target) string {
+	return strings.ToLower(t.GetMode().String()) + ":" + t.GetTarget()
+}
@@ -1,6 +1,8 @@
 package serializer
 
 import (
+	"strings"
+
 	jsoniter "github.com/json-iterator/go"
 )
 
@@ -14,3 +16,40 @@ var JSON = jsoniter.Config{
 	TagKey:    "jackadi",
 	UseNumber: true,
 }.Froze()
+
+// JSONInput decodes the arguments and the options of the tasks.
+//
+// Unlike JSON, the "json" struct tag is used by the fields without "jackadi" tag, so that the structs already tagged
+// for JSON can be reused as input. The precedence is: jackadi tag, json tag, field name.
+var JSONInput = func() jsoniter.API {
+	api := jsoniter.Config{
+		TagKey:    "jackadi",
+		UseNumber: true,
+	}.Froze()
+	api.RegisterExtension(&jsonTagFallback{})
+	return api
+}()
+
+// jsonTagFallback names the fields without "jackadi" tag after their "json" tag.
+type jsonTagFallback struct {
+	jsoniter.DummyExtension
+}
+
+func (*jsonTagFallback) UpdateStructDescriptor(structDescriptor *jsoniter.StructDescriptor) {
+	for _, binding := range structDescriptor.Fields {
+		if _, ok := binding.Field.Tag().Lookup("jackadi"); ok {
+			continue
+		}
+		tag, ok := binding.Field.Tag().Lookup("json")
+		if !ok {
+			continue
+		}
+		if tag == "-" {
+			binding.FromNames, binding.ToNames = []string{}, []string{}
+			continue
+		}
+		if name, _, _ := strings.Cut(tag, ","); name != "" {
+			binding.FromNames, binding.ToNames = []string{name}, []string{name}
+		}
+	}
+}
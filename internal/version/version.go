@@ -0,0 +1,46 @@
+// Package version checks the compatibility between the manager and the nodes.
+package version
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+)
+
+// Dev is the build version of binaries built without release version.
+const Dev = "dev"
+
+var ErrIncompatibleProtocol = errors.New("incompatible protocol version")
+
+// CheckProtocol returns ErrIncompatibleProtocol if the remote protocol version is not the local one.
+//
+// A remote protocol version of 0 is tolerated: it is sent by peers older than the version check.
+func CheckProtocol(remote uint32) error {
+	if remote == 0 || remote == config.ProtocolVersion {
+		return nil
+	}
+	return fmt.Errorf("%w: local=%d remote=%d", ErrIncompatibleProtocol, config.ProtocolVersion, remote)
+}
+
+// SameMajor returns false if both build versions are releases with different major versions.
+//
+// Dev and unknown versions are considered compatible with any version.
+func SameMajor(a, b string) bool {
+	majorA, okA := major(a)
+	majorB, okB := major(b)
+	if !okA || !okB {
+		return true
+	}
+	return majorA == majorB
+}
+
+// major returns the major version of a release version like "1.2.3" or "v1.2.3".
+func major(v string) (string, bool) {
+	if v == "" || v == Dev {
+		return "", false
+	}
+	m, _, _ := strings.Cut(strings.TrimPrefix(v, "v"), ".")
+	return m, m != ""
+}
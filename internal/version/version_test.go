@@ -0,0 +1,33 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckProtocol(t *testing.T) {
+	assert.NoError(t, CheckProtocol(config.ProtocolVersion))
+	assert.NoError(t, CheckProtocol(0), "peers older than the version check must be tolerated")
+	assert.ErrorIs(t, CheckProtocol(config.ProtocolVersion+1), ErrIncompatibleProtocol)
+}
+
+func TestSameMajor(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1.2.3", "1.0.0", true},
+		{"v1.2.3", "1.4.0", true},
+		{"1.2.3", "2.0.0", false},
+		{"v0.9.0", "v1.0.0", false},
+		{Dev, "2.0.0", true},
+		{"1.2.3", Dev, true},
+		{"", "1.0.0", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, SameMajor(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+	}
+}
@@ -0,0 +1,88 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/jackadi-io/jackadi/internal/plugin/core"
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// TestResult is the result of a task run by TestInvoke.
+type TestResult struct {
+	Output   []byte // Serialized output, as sent to the manager. See Decode.
+	Error    string // Error returned by the task, empty if none.
+	Retcode  int32
+	Progress []int // Percentages reported by the task, in order.
+}
+
+// Decode deserializes the output of the task into v, as the manager does.
+func (r TestResult) Decode(v any) error {
+	return serializer.JSON.Unmarshal(r.Output, v)
+}
+
+// TestInvoke runs a task of the plugin as a node does, to unit-test the tasks without node nor manager.
+//
+// The options and the arguments go through the conversions of a request sent to the API: the structs are passed by
+// their serialized fields (jackadi tags), the numbers become float64. Like jack does, pass the large integers as
+// strings to keep their precision. The returned error is the failure of the invocation (unknown task, invalid
+// arguments, panic...), the error of the task itself is in the result:
+//
+//	res, err := sdk.TestInvoke(ctx, plugin, "configure", map[string]any{"verbose": true}, "nginx")
+func TestInvoke(ctx context.Context, p *Plugin, task string, opts map[string]any, args ...any) (TestResult, error) {
+	positional := make([]any, 0, len(args))
+	for i, arg := range args {
+		v, err := requestValue(arg)
+		if err != nil {
+			return TestResult{}, fmt.Errorf("invalid argument n°%d: %w", i, err)
+		}
+		positional = append(positional, v)
+	}
+	argList, err := structpb.NewList(positional)
+	if err != nil {
+		return TestResult{}, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	input := &proto.Input{Args: argList}
+	if opts != nil {
+		v, err := requestValue(opts)
+		if err != nil {
+			return TestResult{}, fmt.Errorf("invalid options: %w", err)
+		}
+		options, _ := v.(map[string]any)
+		if input.Options, err = structpb.NewStruct(options); err != nil {
+			return TestResult{}, fmt.Errorf("invalid options: %w", err)
+		}
+	}
+
+	var mu sync.Mutex
+	var progress []int
+	ctx = core.WithProgress(ctx, func(percent int32) {
+		mu.Lock()
+		defer mu.Unlock()
+		progress = append(progress, int(percent))
+	})
+
+	resp, err := p.Do(ctx, task, input)
+	mu.Lock()
+	defer mu.Unlock()
+	return TestResult{Output: resp.Output, Error: resp.Error, Retcode: resp.Retcode, Progress: progress}, err
+}
+
+// requestValue returns the value as received by a node: serialized like the task outputs, and decoded like the
+// arguments parsed by jack.
+func requestValue(v any) (any, error) {
+	raw, err := serializer.JSONInput.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
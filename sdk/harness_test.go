@@ -0,0 +1,129 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jackadi-io/jackadi/internal/plugin/core"
+)
+
+type harnessUser struct {
+	ID       int64             `jackadi:"id"`
+	Username string            `jackadi:"username"`
+	Metadata map[string]string `jackadi:"metadata,omitempty"`
+}
+
+type harnessOptions struct {
+	Verbose bool   `jackadi:"verbose"`
+	Region  string `jackadi:"region"`
+}
+
+func (o *harnessOptions) SetDefaults() {
+	o.Region = "us-east-1"
+}
+
+func newHarnessPlugin() *Plugin {
+	p := New("demo")
+	p.MustRegisterTask("hello", func() (string, error) {
+		return "Hello, World!", nil
+	})
+	p.MustRegisterTask("configure", func(ctx context.Context, opts *harnessOptions, service string) (map[string]any, error) {
+		return map[string]any{"service": service, "region": opts.Region, "verbose": opts.Verbose}, nil
+	})
+	p.MustRegisterTask("create-user", func(u harnessUser, groups []string) (harnessUser, error) {
+		u.Metadata = map[string]string{"groups": strings.Join(groups, ",")}
+		return u, nil
+	})
+	p.MustRegisterTask("fail", func() (string, error) {
+		return "partial", WithRetcode(errors.New("disk full"), 28)
+	})
+	p.MustRegisterTask("upgrade", func(ctx context.Context, progress Progress) (bool, error) {
+		progress.Report(50)
+		progress.Report(100)
+		return true, nil
+	})
+	p.MustRegisterTask("panic", func() (string, error) {
+		panic("boom")
+	})
+	return p
+}
+
+func TestTestInvoke(t *testing.T) {
+	p := newHarnessPlugin()
+	ctx := context.Background()
+
+	res, err := TestInvoke(ctx, p, "hello", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var hello string
+	if err := res.Decode(&hello); err != nil || hello != "Hello, World!" {
+		t.Errorf("unexpected output: %q (%v)", hello, err)
+	}
+
+	res, err = TestInvoke(ctx, p, "configure", map[string]any{"verbose": true}, "nginx")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var configured map[string]any
+	if err := res.Decode(&configured); err != nil {
+		t.Fatalf("invalid output: %v", err)
+	}
+	want := map[string]any{"service": "nginx", "region": "us-east-1", "verbose": true}
+	if diff := cmp.Diff(want, configured); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+
+	res, err = TestInvoke(ctx, p, "create-user", nil, harnessUser{ID: 42, Username: "alice"}, []string{"ops", "dev"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var user harnessUser
+	if err := res.Decode(&user); err != nil {
+		t.Fatalf("invalid output: %v", err)
+	}
+	wantUser := harnessUser{ID: 42, Username: "alice", Metadata: map[string]string{"groups": "ops,dev"}}
+	if diff := cmp.Diff(wantUser, user); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+
+	res, err = TestInvoke(ctx, p, "fail", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Error != "disk full" || res.Retcode != 28 || string(res.Output) != `"partial"` {
+		t.Errorf("expected the error, the retcode and the partial output of the task, got %+v", res)
+	}
+
+	res, err = TestInvoke(ctx, p, "upgrade", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]int{50, 100}, res.Progress); diff != "" {
+		t.Errorf("unexpected progress (-want +got):\n%s", diff)
+	}
+}
+
+func TestTestInvoke_Failures(t *testing.T) {
+	p := newHarnessPlugin()
+	ctx := context.Background()
+
+	if _, err := TestInvoke(ctx, p, "unknown", nil); err == nil {
+		t.Error("expected an error for an unknown task")
+	}
+	if _, err := TestInvoke(ctx, p, "configure", map[string]any{"unknown": 1}, "nginx"); err == nil {
+		t.Error("expected an error for an invalid option")
+	}
+	if _, err := TestInvoke(ctx, p, "configure", nil); err == nil {
+		t.Error("expected an error for a missing argument")
+	}
+
+	_, err := TestInvoke(ctx, p, "panic", nil)
+	var panicErr *core.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("expected the panic of the task, got %v", err)
+	}
+}
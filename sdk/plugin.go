@@ -9,6 +9,7 @@ import (
 	"os"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	goplugin "github.com/hashicorp/go-plugin"
 	"google.golang.org/protobuf/types/known/structpb"
@@ -139,12 +140,20 @@ func (t Plugin) Version() (core.Version, error) {
 	return getVersion(), nil
 }
 
-func (t Plugin) GetTaskLockMode(taskName string) (proto.LockMode, error) {
+func (t Plugin) GetTaskLockMode(taskName string) (core.TaskLockMode, error) {
 	task, ok := t.tasks[taskName]
 	if !ok {
-		return proto.LockMode_NO_LOCK, fmt.Errorf("unknown task: %s", taskName)
+		return core.TaskLockMode{Mode: proto.LockMode_NO_LOCK}, fmt.Errorf("unknown task: %s", taskName)
 	}
-	return task.getLockMode().toProtoLockMode(), nil
+	return core.TaskLockMode{Mode: task.getLockMode().toProtoLockMode(), Minimum: task.minimumLockMode}, nil
+}
+
+func (t Plugin) GetTaskCacheTTL(taskName string) (time.Duration, error) {
+	task, ok := t.tasks[taskName]
+	if !ok {
+		return 0, fmt.Errorf("unknown task: %s", taskName)
+	}
+	return task.cacheTTL, nil
 }
 
 func MustServe(plugin *Plugin) {
@@ -260,6 +269,7 @@ func handleFlags(plugin *Plugin) bool {
 	helpFlag := flag.Bool("help", false, "print command usage and available flags")
 	versionFlag := flag.Bool("version", false, "print plugin information")
 	describeFlag := flag.Bool("describe", false, "decribe plugin")
+	schemaFlag := flag.Bool("schema", false, "print the JSON Schema of the inputs of the tasks")
 	flag.Parse()
 
 	if *helpFlag {
@@ -280,5 +290,16 @@ func handleFlags(plugin *Plugin) bool {
 		return true
 	}
 
+	if *schemaFlag {
+		schemas, _ := plugin.InputSchemas()
+		out, err := serializer.JSON.MarshalIndent(schemas, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to serialize the schemas: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+		return true
+	}
+
 	return false
 }
@@ -0,0 +1,21 @@
+package sdk
+
+import "github.com/jackadi-io/jackadi/internal/plugin/core"
+
+// Progress reports the completion of a long running task. It is injected if the task declares it
+// right after the context (or first if there is no context), before the options:
+//
+//	func Upgrade(ctx context.Context, progress sdk.Progress, opts *UpgradeOptions) (Result, error)
+type Progress struct {
+	report core.ProgressFunc
+}
+
+// Report sends the completion percentage of the task, values are clamped between 0 and 100.
+//
+// Updates are shown to the requester while the task runs, they are not recorded as results.
+func (p Progress) Report(percent int) {
+	if p.report == nil {
+		return
+	}
+	p.report(int32(min(max(percent, 0), 100))) //nolint:gosec // clamped
+}
@@ -0,0 +1,123 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+var ErrRequirementNotMet = errors.New("requirement of the task not met")
+
+// capabilityBits are the bits of the Linux capabilities in the capability sets, see capabilities(7).
+var capabilityBits = map[string]uint{
+	"CAP_CHOWN":              0,
+	"CAP_DAC_OVERRIDE":       1,
+	"CAP_DAC_READ_SEARCH":    2,
+	"CAP_FOWNER":             3,
+	"CAP_FSETID":             4,
+	"CAP_KILL":               5,
+	"CAP_SETGID":             6,
+	"CAP_SETUID":             7,
+	"CAP_SETPCAP":            8,
+	"CAP_LINUX_IMMUTABLE":    9,
+	"CAP_NET_BIND_SERVICE":   10,
+	"CAP_NET_BROADCAST":      11,
+	"CAP_NET_ADMIN":          12,
+	"CAP_NET_RAW":            13,
+	"CAP_IPC_LOCK":           14,
+	"CAP_IPC_OWNER":          15,
+	"CAP_SYS_MODULE":         16,
+	"CAP_SYS_RAWIO":          17,
+	"CAP_SYS_CHROOT":         18,
+	"CAP_SYS_PTRACE":         19,
+	"CAP_SYS_PACCT":          20,
+	"CAP_SYS_ADMIN":          21,
+	"CAP_SYS_BOOT":           22,
+	"CAP_SYS_NICE":           23,
+	"CAP_SYS_RESOURCE":       24,
+	"CAP_SYS_TIME":           25,
+	"CAP_SYS_TTY_CONFIG":     26,
+	"CAP_MKNOD":              27,
+	"CAP_LEASE":              28,
+	"CAP_AUDIT_WRITE":        29,
+	"CAP_AUDIT_CONTROL":      30,
+	"CAP_SETFCAP":            31,
+	"CAP_MAC_OVERRIDE":       32,
+	"CAP_MAC_ADMIN":          33,
+	"CAP_SYSLOG":             34,
+	"CAP_WAKE_ALARM":         35,
+	"CAP_BLOCK_SUSPEND":      36,
+	"CAP_AUDIT_READ":         37,
+	"CAP_PERFMON":            38,
+	"CAP_BPF":                39,
+	"CAP_CHECKPOINT_RESTORE": 40,
+}
+
+// WithRequiresRoot makes the task run only as root.
+//
+// The node returns an error instead of running the task if the process running it, the node or the plugin
+// process, does not run as root.
+func (t *Task) WithRequiresRoot() *Task {
+	t.requiresRoot = true
+	return t
+}
+
+// WithRequiredCapability makes the task run only if the process running it has the Linux capabilities in its
+// effective set, e.g. WithRequiredCapability("CAP_SYS_ADMIN"). The capabilities are only supported on Linux.
+//
+// An unknown capability is fatal, like the other errors of the registration of the task.
+func (t *Task) WithRequiredCapability(capabilities ...string) *Task {
+	for _, capability := range capabilities {
+		name, err := capabilityName(capability)
+		if err != nil {
+			log.Fatalf("task %s: %v", t.name, err)
+		}
+		t.capabilities = append(t.capabilities, name)
+	}
+	return t
+}
+
+// capabilityName returns the name of the capability in capabilityBits, e.g. CAP_NET_ADMIN for net_admin.
+func capabilityName(capability string) (string, error) {
+	name := strings.ToUpper(capability)
+	if !strings.HasPrefix(name, "CAP_") {
+		name = "CAP_" + name
+	}
+	if _, ok := capabilityBits[name]; !ok {
+		return "", fmt.Errorf("unknown capability: %s", capability)
+	}
+	return name, nil
+}
+
+// requirementsString returns the requirements of the task, e.g. "root, CAP_SYS_ADMIN".
+func (t *Task) requirementsString() string {
+	var requirements []string
+	if t.requiresRoot {
+		requirements = append(requirements, "root")
+	}
+	requirements = append(requirements, t.capabilities...)
+	return strings.Join(requirements, ", ")
+}
+
+// checkRequirements returns an error wrapping ErrRequirementNotMet if the process cannot run the task.
+func (t *Task) checkRequirements() error {
+	if t.requiresRoot {
+		if uid := os.Geteuid(); uid != 0 {
+			return fmt.Errorf("%w: must run as root, running as uid %d", ErrRequirementNotMet, uid)
+		}
+	}
+	if len(t.capabilities) == 0 {
+		return nil
+	}
+
+	missing, err := missingCapabilities(t.capabilities)
+	if err != nil {
+		return fmt.Errorf("failed to check the required capabilities: %w", err)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing capabilities %s", ErrRequirementNotMet, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package sdk
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// procStatusPath is read for the effective capabilities of the process.
+var procStatusPath = "/proc/self/status"
+
+// missingCapabilities returns the capabilities not in the effective set of the process.
+func missingCapabilities(capabilities []string) ([]string, error) {
+	effective, err := effectiveCapabilities()
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, capability := range capabilities {
+		bit, ok := capabilityBits[capability]
+		if !ok {
+			return nil, fmt.Errorf("unknown capability: %s", capability)
+		}
+		if effective&(1<<bit) == 0 {
+			missing = append(missing, capability)
+		}
+	}
+	return missing, nil
+}
+
+// effectiveCapabilities returns the effective capability set of the process (CapEff).
+func effectiveCapabilities() (uint64, error) {
+	f, err := os.Open(procStatusPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "CapEff:")
+		if !ok {
+			continue
+		}
+		effective, err := strconv.ParseUint(strings.TrimSpace(value), 16, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid effective capabilities: %w", err)
+		}
+		return effective, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("effective capabilities not found in " + procStatusPath)
+}
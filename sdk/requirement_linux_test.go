@@ -0,0 +1,134 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jackadi-io/jackadi/internal/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var noArgs = &proto.Input{Args: &structpb.ListValue{}}
+
+// withEffectiveCapabilities makes the process status report the effective capabilities (CapEff) during the test.
+func withEffectiveCapabilities(t *testing.T, capEff string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "status")
+	status := "Name:\tjackadi\nCapInh:\t0000000000000000\nCapPrm:\t" + capEff + "\nCapEff:\t" + capEff + "\n"
+	if err := os.WriteFile(path, []byte(status), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	original := procStatusPath
+	procStatusPath = path
+	t.Cleanup(func() { procStatusPath = original })
+}
+
+func TestDoRequiredCapability(t *testing.T) {
+	called := false
+	p := New("test")
+	p.MustRegisterTask("mount", func() (string, error) {
+		called = true
+		return "mounted", nil
+	}).WithRequiredCapability("CAP_SYS_ADMIN", "net_admin")
+
+	tests := map[string]struct {
+		capEff  string
+		wantErr string
+	}{
+		"capabilities held": {
+			capEff: "0000000000201000", // CAP_NET_ADMIN and CAP_SYS_ADMIN
+		},
+		"capability missing": {
+			capEff:  "0000000000001000", // CAP_NET_ADMIN only
+			wantErr: "missing capabilities CAP_SYS_ADMIN",
+		},
+		"no capability": {
+			capEff:  "0000000000000000",
+			wantErr: "missing capabilities CAP_SYS_ADMIN, CAP_NET_ADMIN",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			called = false
+			withEffectiveCapabilities(t, tt.capEff)
+
+			resp, err := p.Do(context.Background(), "mount", noArgs)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrRequirementNotMet) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error %q, got: %v", tt.wantErr, err)
+				}
+				if called {
+					t.Error("the task must not be called when its requirements are not met")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !called || string(resp.Output) != `"mounted"` {
+				t.Errorf("expected the task to run, got output: %s", resp.Output)
+			}
+		})
+	}
+}
+
+func TestCapabilityName(t *testing.T) {
+	tests := map[string]string{
+		"CAP_SYS_ADMIN": "CAP_SYS_ADMIN",
+		"net_admin":     "CAP_NET_ADMIN",
+		"cap_bpf":       "CAP_BPF",
+	}
+	for capability, want := range tests {
+		if got, err := capabilityName(capability); err != nil || got != want {
+			t.Errorf("capabilityName(%q) = %q, %v, want %q", capability, got, err, want)
+		}
+	}
+
+	// a typo is refused at the registration, not when the task runs
+	for _, capability := range []string{"CAP_SYS_ADMN", "", "CAP_"} {
+		if _, err := capabilityName(capability); err == nil {
+			t.Errorf("expected an error for the capability %q", capability)
+		}
+	}
+}
+
+func TestDoRequiresRoot(t *testing.T) {
+	p := New("test")
+	p.MustRegisterTask("reboot", func() (string, error) {
+		return "rebooted", nil
+	}).WithRequiresRoot()
+
+	_, err := p.Do(context.Background(), "reboot", noArgs)
+	if os.Geteuid() == 0 {
+		if err != nil {
+			t.Errorf("unexpected error as root: %v", err)
+		}
+		return
+	}
+	if !errors.Is(err, ErrRequirementNotMet) || !strings.Contains(err.Error(), "must run as root") {
+		t.Errorf("expected a root requirement error, got: %v", err)
+	}
+}
+
+func TestHelpRequirements(t *testing.T) {
+	p := New("test")
+	p.MustRegisterTask("upgrade", func() (string, error) {
+		return "", nil
+	}).WithRequiresRoot().WithRequiredCapability("CAP_SYS_ADMIN")
+
+	help, err := p.Help("upgrade")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(help["upgrade"], "Requires: root, CAP_SYS_ADMIN\n") {
+		t.Errorf("expected the requirements in the help, got:\n%s", help["upgrade"])
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package sdk
+
+import "errors"
+
+func missingCapabilities(capabilities []string) ([]string, error) {
+	return nil, errors.New("capabilities only supported on Linux")
+}
@@ -0,0 +1,43 @@
+package sdk
+
+import (
+	"errors"
+	"reflect"
+)
+
+// RetcodeError is an error setting the retcode of the task response, see WithRetcode.
+type RetcodeError struct {
+	Retcode int32
+	Err     error
+}
+
+func (e *RetcodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetcodeError) Unwrap() error {
+	return e.Err
+}
+
+// WithRetcode wraps the error returned by a task to set the retcode of its response.
+//
+// e.g. return nil, sdk.WithRetcode(err, 2)
+func WithRetcode(err error, retcode int32) error {
+	if err == nil {
+		return nil
+	}
+	return &RetcodeError{Retcode: retcode, Err: err}
+}
+
+// returnedRetcode returns the retcode set by the error returned by a task, 0 if none.
+func returnedRetcode(ret []reflect.Value) int32 {
+	if len(ret) < 2 || !ret[1].IsValid() {
+		return 0
+	}
+
+	var rcErr *RetcodeError
+	if err, ok := ret[1].Interface().(error); ok && errors.As(err, &rcErr) {
+		return rcErr.Retcode
+	}
+	return 0
+}
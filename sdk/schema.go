@@ -0,0 +1,171 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// InputSchema returns the JSON Schema of the inputs of the task, derived from the types of its parameters.
+//
+// The inputs are an object with the positional arguments (args) and the options (options), as sent by
+// jack run. The struct fields are named after their jackadi tag, like the options and the arguments are
+// matched, and the options have the default values set by SetDefaults.
+func (t Plugin) InputSchema(taskName string) (map[string]any, error) {
+	task, ok := t.tasks[taskName]
+	if !ok {
+		return nil, fmt.Errorf("unknown task: %s", taskName)
+	}
+	return task.inputSchema(), nil
+}
+
+// InputSchemas returns the JSON Schema of the inputs of every task, by task name.
+func (t Plugin) InputSchemas() (map[string]any, error) {
+	schemas := make(map[string]any, len(t.taskNames))
+	for _, name := range t.taskNames {
+		schema, err := t.InputSchema(name)
+		if err != nil {
+			return nil, err
+		}
+		schemas[name] = schema
+	}
+	return schemas, nil
+}
+
+func (t *Task) inputSchema() map[string]any {
+	funcType := reflect.TypeOf(t.function)
+	properties := map[string]any{}
+
+	// same offset as handleInputs: context, progress and options are before the args
+	offset := 0
+	if offset < funcType.NumIn() && funcType.In(offset).Implements(reflect.TypeFor[context.Context]()) {
+		offset++
+	}
+	if offset < funcType.NumIn() && funcType.In(offset) == reflect.TypeFor[Progress]() {
+		offset++
+	}
+	if offset < funcType.NumIn() && funcType.In(offset).Implements(reflect.TypeFor[Options]()) {
+		properties["options"] = optionsSchema(funcType.In(offset).Elem())
+		offset++
+	}
+
+	if params := funcType.NumIn() - offset; params > 0 {
+		required := params
+		for required > 0 && funcType.In(offset+required-1).Kind() == reflect.Pointer {
+			required--
+		}
+
+		items := make([]any, 0, params)
+		for i := range params {
+			item := typeSchema(funcType.In(offset+i), map[reflect.Type]bool{})
+			if i < len(t.args) {
+				item["title"] = t.args[i].Name
+			}
+			items = append(items, item)
+		}
+		properties["args"] = map[string]any{
+			"type":        "array",
+			"prefixItems": items,
+			"minItems":    required,
+			"maxItems":    params,
+		}
+	}
+
+	return map[string]any{
+		"$schema":              jsonSchemaDialect,
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// optionsSchema returns the schema of the options struct, with the non-zero defaults set by SetDefaults.
+func optionsSchema(optionElemType reflect.Type) map[string]any {
+	schema := typeSchema(optionElemType, map[reflect.Type]bool{})
+	schema["additionalProperties"] = false
+
+	opts := reflect.New(optionElemType)
+	op, ok := opts.Interface().(Options)
+	if !ok {
+		return schema
+	}
+	op.SetDefaults()
+
+	properties, _ := schema["properties"].(map[string]any)
+	for _, field := range schemaFields(optionElemType) {
+		value := fieldByIndex(opts.Elem(), field.Index)
+		if !value.IsValid() || value.IsZero() {
+			continue
+		}
+		if property, ok := properties[fieldName(field)].(map[string]any); ok {
+			property["default"] = value.Interface()
+		}
+	}
+	return schema
+}
+
+// typeSchema returns the JSON Schema of the type. The recursive structs are described as objects without
+// properties where they recur.
+func typeSchema(typ reflect.Type, seen map[reflect.Type]bool) map[string]any {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() { //nolint:exhaustive // the other types are not supported by the tasks
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(typ.Elem(), seen)}
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(typ.Elem(), seen), "minItems": typ.Len(), "maxItems": typ.Len()}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(typ.Elem(), seen)}
+	case reflect.Struct:
+		if seen[typ] {
+			return map[string]any{"type": "object"}
+		}
+		seen[typ] = true
+		defer delete(seen, typ)
+
+		properties := map[string]any{}
+		for _, field := range schemaFields(typ) {
+			properties[fieldName(field)] = typeSchema(field.Type, seen)
+		}
+		return map[string]any{"type": "object", "properties": properties}
+	default:
+		return map[string]any{} // any value
+	}
+}
+
+// schemaFields returns the exported fields of the struct and of its embedded structs, the outer ones winning over
+// the embedded ones of the same name.
+func schemaFields(structType reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	names := map[string]bool{}
+	for _, field := range visibleFields(structType) {
+		if field.Anonymous || !field.IsExported() || names[fieldName(field)] {
+			continue
+		}
+		names[fieldName(field)] = true
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// fieldName returns the name of the field in the inputs: its jackadi tag, or its field name if it has no tag.
+func fieldName(field reflect.StructField) string {
+	if names := tagNames(field); len(names) > 0 {
+		return names[0]
+	}
+	return field.Name
+}
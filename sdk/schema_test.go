@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type schemaBase struct {
+	Owner  string `jackadi:"owner"`
+	Shadow int    `jackadi:"name"`
+}
+
+type schemaNode struct {
+	schemaBase
+	Name     string         `jackadi:"name,aliases=title"`
+	Port     uint16         `json:"port"`
+	Children []*schemaNode  `jackadi:"children"`
+	Extra    any            `jackadi:"extra"`
+	Ignored  string         `jackadi:"-"`
+	internal string         //nolint:unused // not an input
+	Labels   map[string]int `jackadi:"labels"`
+}
+
+func TestInputSchema(t *testing.T) {
+	p := New("test")
+	p.MustRegisterTask("patch", func(ctx context.Context, opts *TestOptions, name string, node *schemaNode) (string, error) {
+		return name, nil
+	}).WithArg("name", "string", "web-1")
+
+	got, err := p.InputSchema("patch")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	node := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"owner":    map[string]any{"type": "string"},
+			"name":     map[string]any{"type": "string"},
+			"port":     map[string]any{"type": "integer", "minimum": 0},
+			"children": map[string]any{"type": "array", "items": map[string]any{"type": "object"}},
+			"extra":    map[string]any{},
+			"labels":   map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "integer"}},
+		},
+	}
+	want := map[string]any{
+		"$schema":              jsonSchemaDialect,
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties": map[string]any{
+			"options": map[string]any{
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties": map[string]any{
+					"verbose":     map[string]any{"type": "boolean"},
+					"output_file": map[string]any{"type": "string"},
+					"timeout":     map[string]any{"type": "integer", "default": 30},
+					"Region":      map[string]any{"type": "string", "default": "us-east-1"},
+				},
+			},
+			"args": map[string]any{
+				"type":        "array",
+				"prefixItems": []any{map[string]any{"type": "string", "title": "name"}, node},
+				"minItems":    1, // the trailing pointer is optional
+				"maxItems":    2,
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected schema (-want +got):\n%s", diff)
+	}
+
+	if _, err := p.InputSchema("unknown"); err == nil {
+		t.Error("expected an error for an unknown task")
+	}
+}
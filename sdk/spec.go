@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"reflect"
 
+	"github.com/jackadi-io/jackadi/internal/config"
 	"github.com/jackadi-io/jackadi/internal/serializer"
 )
 
@@ -69,49 +70,91 @@ func (t *Plugin) MustRegisterSpecCollector(name string, function any) *SpecColle
 	return t.specs[name]
 }
 
+// CollectSpecs runs the spec collectors concurrently, each one within config.SpecCollectorTimeout.
+//
+// A failed collector does not prevent the others from being collected: its specs are replaced by an error marker
+// ({"_error": "..."}), and its error is joined to the returned one.
 func (t *Plugin) CollectSpecs(ctx context.Context) ([]byte, error) {
-	defer func() {
-		if r := recover(); r != nil {
-			slog.Error("recovered from panic", "plugin", t.name, "context", "CollectSpecs", "error", r)
-		}
-	}()
+	type result struct {
+		name  string
+		value any
+		err   error
+	}
 
-	res := make(map[string]any)
+	results := make(chan result, len(t.specs))
+	for name, spec := range t.specs {
+		go func() {
+			ctx, cancel := context.WithTimeout(ctx, config.SpecCollectorTimeout)
+			defer cancel()
+
+			// a collector ignoring the context is abandoned at the timeout
+			done := make(chan result, 1)
+			go func() {
+				value, err := spec.collect(ctx)
+				done <- result{name: name, value: value, err: err}
+			}()
+			select {
+			case r := <-done:
+				results <- r
+			case <-ctx.Done():
+				results <- result{name: name, err: ctx.Err()}
+			}
+		}()
+	}
 
+	res := make(map[string]any)
 	var specErrs error
-	for name, spec := range t.specs {
-		funcValue := reflect.ValueOf(spec.function)
-		input := []reflect.Value{}
-		if funcValue.Type().NumIn() == 1 {
-			input = append(input, reflect.ValueOf(ctx))
+	for range len(t.specs) {
+		r := <-results
+		if r.err != nil {
+			slog.Error("failed to collect spec", "plugin", t.name, "spec", r.name, "error", r.err)
+			res[r.name] = map[string]any{config.SpecErrorKey: r.err.Error()}
+			specErrs = errors.Join(specErrs, fmt.Errorf("%s: %w", r.name, r.err))
+			continue
 		}
-		ret := funcValue.Call(input)
+		res[r.name] = r.value
+	}
 
-		if len(ret) < 2 {
-			return nil, fmt.Errorf("function %s returned insufficient values", name)
-		}
+	out, err := serializer.JSON.Marshal(res)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize spec result: %w", err)
+	}
 
-		if !ret[0].IsValid() {
-			return nil, fmt.Errorf("function %s returned invalid first value", name)
+	return out, specErrs
+}
+
+// collect runs the collector, a panic being returned as an error.
+func (p *SpecCollector) collect(ctx context.Context) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
 		}
+	}()
 
-		res[name] = ret[0].Interface()
+	funcValue := reflect.ValueOf(p.function)
+	input := []reflect.Value{}
+	if funcValue.Type().NumIn() == 1 {
+		input = append(input, reflect.ValueOf(ctx))
+	}
+	ret := funcValue.Call(input)
 
-		if ret[1].IsValid() && ret[1].Interface() != nil {
-			err, ok := ret[1].Interface().(error)
-			if !ok {
-				return nil, errors.New("function returned invalid error")
-			}
-			if err != nil {
-				specErrs = errors.Join(specErrs, err)
-			}
-		}
+	if len(ret) < 2 {
+		return nil, errors.New("insufficient returned values")
 	}
 
-	out, err := serializer.JSON.Marshal(res)
-	if err != nil {
-		return nil, fmt.Errorf("unable to serialize spec result: %w", err)
+	if !ret[0].IsValid() {
+		return nil, errors.New("invalid first returned value")
 	}
 
-	return out, specErrs
+	if ret[1].IsValid() && ret[1].Interface() != nil {
+		err, ok := ret[1].Interface().(error)
+		if !ok {
+			return nil, errors.New("invalid returned error")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ret[0].Interface(), nil
 }
@@ -0,0 +1,63 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackadi-io/jackadi/internal/config"
+	"github.com/jackadi-io/jackadi/internal/serializer"
+)
+
+func TestCollectSpecs(t *testing.T) {
+	hung := make(chan struct{})
+	defer close(hung)
+
+	p := New("test")
+	p.MustRegisterSpecCollector("os", func() (map[string]string, error) {
+		return map[string]string{"family": "debian"}, nil
+	})
+	p.MustRegisterSpecCollector("network", func() (map[string]string, error) {
+		return nil, errors.New("no interface")
+	})
+	p.MustRegisterSpecCollector("software", func() (map[string]string, error) {
+		<-hung // ignores the context
+		return map[string]string{"nginx": "1.27"}, nil
+	})
+	p.MustRegisterSpecCollector("disks", func() (map[string]string, error) {
+		var m map[string]string
+		m["sda"] = "boom" // nil map write
+		return m, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	out, err := p.CollectSpecs(ctx)
+	if err == nil {
+		t.Fatal("expected the errors of the failed collectors")
+	}
+	for _, name := range []string{"network", "software", "disks"} {
+		if !strings.Contains(err.Error(), name+":") {
+			t.Errorf("expected the error of %s, got: %v", name, err)
+		}
+	}
+
+	var specs map[string]map[string]string
+	if err := serializer.JSON.Unmarshal(out, &specs); err != nil {
+		t.Fatalf("invalid specs: %v", err)
+	}
+	if specs["os"]["family"] != "debian" {
+		t.Errorf("expected the specs of the successful collector, got: %v", specs["os"])
+	}
+	for name, want := range map[string]string{
+		"network":  "no interface",
+		"software": context.DeadlineExceeded.Error(),
+		"disks":    "panic",
+	} {
+		if got := specs[name][config.SpecErrorKey]; !strings.Contains(got, want) {
+			t.Errorf("expected the error marker of %s to contain %q, got: %v", name, want, specs[name])
+		}
+	}
+}
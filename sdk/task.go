@@ -7,7 +7,10 @@ import (
 	"log"
 	"log/slog"
 	"reflect"
+	"runtime/debug"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/jackadi-io/jackadi/internal/plugin/core"
 	"github.com/jackadi-io/jackadi/internal/proto"
@@ -69,6 +72,12 @@ type Task struct {
 	flags       []Flag
 	args        []args
 	lockMode    LockMode
+	cacheTTL    time.Duration
+	validate    func(opts Options, args []any) error
+
+	minimumLockMode bool     // The requests cannot weaken the lock mode.
+	requiresRoot    bool     // The task must run as root.
+	capabilities    []string // The Linux capabilities the task must have.
 }
 
 // WithSummary set the short description.
@@ -101,6 +110,44 @@ func (t *Task) WithLockMode(lockMode LockMode) *Task {
 	return t
 }
 
+// WithMinimumLockMode sets the default lock mode for this task, which the requests can only strengthen.
+//
+// A weaker lock mode requested, e.g. by jack run --lock-mode none, is ignored: a task changing the system
+// cannot run concurrently with other writers by mistake.
+func (t *Task) WithMinimumLockMode(lockMode LockMode) *Task {
+	t.lockMode = lockMode
+	t.minimumLockMode = true
+	return t
+}
+
+// WithCacheTTL lets the node cache the results of the task during ttl, by options and arguments.
+//
+// It is meant for the read-only tasks polled frequently, e.g. by dashboards: a request with the same input gets
+// the cached result instead of running the task again. The results are only cached for the successful runs without
+// lock, and the cache of the plugin is cleared by any of its tasks running with a write or exclusive lock.
+func (t *Task) WithCacheTTL(ttl time.Duration) *Task {
+	t.cacheTTL = ttl
+	return t
+}
+
+// WithValidation sets a function checking the options and the arguments before the task runs.
+//
+// opts is the options of the task, nil if it has none, and args its positional arguments, converted to the
+// types of the task parameters. The task is not called if the validation fails, its error is returned instead.
+//
+// e.g.:
+//
+//	WithValidation(func(opts sdk.Options, args []any) error {
+//		if o := opts.(*UpgradeOptions); o.SecurityOnly && len(o.ExcludePackages) > 0 {
+//			return errors.New("security-only cannot be combined with exclude-packages")
+//		}
+//		return nil
+//	})
+func (t *Task) WithValidation(validate func(opts Options, args []any) error) *Task {
+	t.validate = validate
+	return t
+}
+
 // getLockMode returns the default lock mode for this task.
 func (t *Task) getLockMode() LockMode {
 	return t.lockMode
@@ -154,10 +201,20 @@ func (t *Task) helpText(pluginName string) string {
 		fmt.Fprintf(&sb, "  %s\n\n", strings.Join(flagNames, ", "))
 	}
 
-	if t.lockMode != NoLock {
+	if t.minimumLockMode {
+		fmt.Fprintf(&sb, "Lock Mode: %s (minimum)\n\n", t.lockMode.String())
+	} else if t.lockMode != NoLock {
 		fmt.Fprintf(&sb, "Lock Mode: %s\n\n", t.lockMode.String())
 	}
 
+	if t.cacheTTL > 0 {
+		fmt.Fprintf(&sb, "Cache TTL: %s\n\n", t.cacheTTL)
+	}
+
+	if requirements := t.requirementsString(); requirements != "" {
+		fmt.Fprintf(&sb, "Requires: %s\n\n", requirements)
+	}
+
 	return sb.String()
 }
 
@@ -170,6 +227,9 @@ func indentMultiline(text, prefix string) string {
 }
 
 // MustRegisterTask registers a task (function) with a string identifier.
+//
+// The function returns its output and an error. The output is sent even if the error is set: a task failing
+// partway can return what it produced before the failure.
 func (t *Plugin) MustRegisterTask(name string, function any) *Task {
 	funcValue := reflect.ValueOf(function)
 	funcType := funcValue.Type()
@@ -217,10 +277,14 @@ func (t *Plugin) MustRegisterTask(name string, function any) *Task {
 	return t.tasks[name]
 }
 
-func (t Plugin) Do(ctx context.Context, task string, input *proto.Input) (core.Response, error) {
+// Do runs the task. A panic of the task is recovered and returned as a *core.PanicError.
+func (t Plugin) Do(ctx context.Context, task string, input *proto.Input) (resp core.Response, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			slog.Error("recovered from panic", "plugin", t.name, "error", r)
+			stack := string(debug.Stack())
+			slog.Error("recovered from panic", "plugin", t.name, "task", task, "error", r)
+			slog.Debug("panic stack trace", "plugin", t.name, "task", task, "stack", stack)
+			resp, err = core.Response{}, &core.PanicError{Value: fmt.Sprint(r), Stack: stack}
 		}
 	}()
 
@@ -242,26 +306,67 @@ func (t Plugin) Do(ctx context.Context, task string, input *proto.Input) (core.R
 	funcValue := reflect.ValueOf(function)
 	funcType := funcValue.Type()
 
+	if err := selectedTask.checkRequirements(); err != nil {
+		return core.Response{}, err
+	}
+
 	inputs, err := handleInputs(ctx, funcType, input)
 	if err != nil {
 		return core.Response{}, err
 	}
 
+	if selectedTask.validate != nil {
+		opts, args := validationInputs(funcType, inputs)
+		if err := selectedTask.validate(opts, args); err != nil {
+			return core.Response{}, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
 	// call the task
 	ret := funcValue.Call(inputs)
 
 	// parse return
 	taskOut, taskErr, err := parseReturn(ret)
 	if err != nil {
-		return core.Response{}, err
+		return core.Response{Error: taskErr, Retcode: returnedRetcode(ret)}, err
 	}
 
 	return core.Response{
-		Output: taskOut,
-		Error:  taskErr,
+		Output:  taskOut,
+		Error:   taskErr,
+		Retcode: returnedRetcode(ret),
 	}, nil
 }
 
+// validationInputs splits the inputs of the task into its options, nil if none, and its positional arguments.
+//
+// The context, the progress and the options are placed in this order before the arguments, see handleInputs.
+func validationInputs(funcType reflect.Type, inputs []reflect.Value) (Options, []any) {
+	offset := 0
+	if offset < funcType.NumIn() && funcType.In(offset).Implements(reflect.TypeFor[context.Context]()) {
+		offset++
+	}
+	if offset < funcType.NumIn() && funcType.In(offset) == reflect.TypeFor[Progress]() {
+		offset++
+	}
+
+	var opts Options
+	if offset < funcType.NumIn() && funcType.In(offset).Implements(reflect.TypeFor[Options]()) {
+		opts, _ = inputs[offset].Interface().(Options)
+		offset++
+	}
+
+	args := make([]any, 0, len(inputs)-offset)
+	for _, in := range inputs[offset:] {
+		args = append(args, in.Interface())
+	}
+	return opts, args
+}
+
+// parseReturn returns the serialized output and the error returned by a task.
+//
+// The output is serialized even if the task returned an error, so that the output produced before a failure
+// is kept. The error of the task is also returned if the output cannot be serialized.
 func parseReturn(ret []reflect.Value) ([]byte, string, error) {
 	if len(ret) < 2 {
 		return nil, "", errors.New("function returned insufficient values")
@@ -302,7 +407,7 @@ func parseReturn(ret []reflect.Value) ([]byte, string, error) {
 	// if we use protobuf Value, numeric precision would be lost as they stored as a float64.
 	taskOut, err := serializer.JSON.Marshal(out)
 	if err != nil {
-		return nil, "", fmt.Errorf("unable to serialize task result: %w", err)
+		return nil, taskErr, fmt.Errorf("unable to serialize task result: %w", err)
 	}
 
 	return taskOut, taskErr, nil
@@ -312,13 +417,13 @@ func handleInputs(ctx context.Context, funcType reflect.Type, input *proto.Input
 	inputs := []reflect.Value{}
 
 	// offset indicates at which position starts the args of the targeted function
-	// as context and option are optional but must be placed in 1st and 2nd position if both exist
-	// or, 1st position if only one of them exists.
+	// as context, progress and option are optional but must be placed in this order before the args.
 	//
 	// e.g.:
 	// func F1(arg1, arg2 string) => offset == 0
 	// func F2(ctx context.Context, arg1, arg2 string) => offset == 1
-	// func F3(ctx context.Context, options Options, arg1, arg2 string) => offset == 3
+	// func F3(ctx context.Context, options Options, arg1, arg2 string) => offset == 2
+	// func F4(ctx context.Context, progress Progress, options Options, arg1, arg2 string) => offset == 3
 	offset := 0
 
 	// handle context
@@ -328,6 +433,13 @@ func handleInputs(ctx context.Context, funcType reflect.Type, input *proto.Input
 		inputs = append(inputs, reflect.ValueOf(ctx))
 	}
 
+	// handle progress
+	if offset < funcType.NumIn() && funcType.In(offset) == reflect.TypeFor[Progress]() {
+		offset++
+		report, _ := core.ProgressFromContext(ctx)
+		inputs = append(inputs, reflect.ValueOf(Progress{report: report}))
+	}
+
 	// handle options
 	optionsType := reflect.TypeFor[Options]()
 	if offset < funcType.NumIn() && funcType.In(offset).Implements(optionsType) {
@@ -340,9 +452,19 @@ func handleInputs(ctx context.Context, funcType reflect.Type, input *proto.Input
 	}
 
 	// fails if number of arguments does not match
-	// expected arguments are the number of parameters in the function minus the context and option structs
-	if len(input.Args.Values) != funcType.NumIn()-offset {
-		return nil, fmt.Errorf("expected number of arguments: %d, got: %d", funcType.NumIn()-offset, len(input.Args.Values))
+	// expected arguments are the number of parameters in the function minus the context and option structs.
+	// The trailing pointer parameters are optional: they are nil when their argument is omitted,
+	// e.g. func Patch(name string, labels *Labels) can be called with the name only.
+	params := funcType.NumIn() - offset
+	required := params
+	for required > 0 && funcType.In(offset+required-1).Kind() == reflect.Pointer {
+		required--
+	}
+	if got := len(input.Args.Values); got < required || got > params {
+		if required == params {
+			return nil, fmt.Errorf("expected number of arguments: %d, got: %d", params, got)
+		}
+		return nil, fmt.Errorf("expected number of arguments: %d to %d, got: %d", required, params, got)
 	}
 
 	// convert all arguments to expected type of task parameters
@@ -359,38 +481,128 @@ func handleInputs(ctx context.Context, funcType reflect.Type, input *proto.Input
 		}
 		inputs = append(inputs, reflect.ValueOf(out))
 	}
+
+	// omitted optional arguments
+	for i := offset + len(input.Args.Values); i < funcType.NumIn(); i++ {
+		inputs = append(inputs, reflect.Zero(funcType.In(i)))
+	}
 	return inputs, nil
 }
 
-// findField finds a struct field by its jackadi tag or field name.
+// findField finds a struct field by its jackadi tag, the aliases declared in the tag, or its field name.
+//
+// Exact matches are preferred, then the case is ignored: `jackadi:"dry_run,aliases=dryRun|dry-run"`
+// matches dry_run, dryRun, dry-run, DryRun or DRY_RUN. A field without jackadi tag is found by its json tag,
+// and the fields tagged "-" are ignored. The fields of the embedded structs are found too, the outer fields
+// winning over the embedded ones.
 func findField(structValue reflect.Value, key string) (reflect.Value, string, bool) {
-	structType := structValue.Type()
+	fields := visibleFields(structValue.Type())
 
-	// handle jackadi tag
-	for i := 0; i < structType.NumField(); i++ {
-		field := structType.Field(i)
-
-		if tag, ok := field.Tag.Lookup("jackadi"); ok {
-			parts := strings.Split(tag, ",")
-			if len(parts) == 0 {
-				continue
-			}
-			tagName := parts[0] // handle tags like "field_name,omitempty"
-			if tagName == key {
-				return structValue.Field(i), field.Name, true
-			}
+	// handle jackadi tag and its aliases
+	for _, field := range fields {
+		if slices.Contains(tagNames(field), key) {
+			return fieldByIndex(structValue, field.Index), field.Name, true
 		}
 	}
 
 	// no jackadi tag: fallback to natural field name
-	field := structValue.FieldByName(key)
-	if field.IsValid() {
-		return field, key, true
+	for _, field := range fields {
+		if field.Name == key {
+			return fieldByIndex(structValue, field.Index), key, true
+		}
+	}
+
+	// case-insensitive matching
+	for _, field := range fields {
+		names := append(tagNames(field), field.Name)
+		if slices.ContainsFunc(names, func(name string) bool { return strings.EqualFold(name, key) }) {
+			return fieldByIndex(structValue, field.Index), field.Name, true
+		}
 	}
 
 	return reflect.Value{}, "", false
 }
 
+// visibleFields returns the fields of the struct and of its embedded structs, the outer ones first.
+//
+// The ignored fields, and the fields of the ignored embedded structs, are excluded.
+func visibleFields(structType reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	var ignored [][]int
+	for _, field := range reflect.VisibleFields(structType) {
+		inIgnored := func(index []int) bool {
+			return len(field.Index) > len(index) && slices.Equal(index, field.Index[:len(index)])
+		}
+		if slices.ContainsFunc(ignored, inIgnored) {
+			continue
+		}
+		if ignoredField(field) {
+			ignored = append(ignored, field.Index)
+			continue
+		}
+		fields = append(fields, field)
+	}
+	slices.SortStableFunc(fields, func(a, b reflect.StructField) int { return len(a.Index) - len(b.Index) })
+	return fields
+}
+
+// fieldByIndex returns the nested field of the struct, allocating the nil embedded pointers on the way.
+//
+// The returned value is invalid if a nil embedded pointer cannot be set.
+func fieldByIndex(structValue reflect.Value, index []int) reflect.Value {
+	v := structValue
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// tagNames returns the name and the aliases declared in the jackadi tag of the field, or the name of its json tag
+// if it has no jackadi tag.
+func tagNames(field reflect.StructField) []string {
+	tag, ok := field.Tag.Lookup("jackadi")
+	if !ok {
+		tag, ok = field.Tag.Lookup("json")
+		if name, _, _ := strings.Cut(tag, ","); ok && name != "" && tag != "-" {
+			return []string{name}
+		}
+		return nil
+	}
+	if tag == "-" {
+		return nil
+	}
+
+	parts := strings.Split(tag, ",") // handle tags like "field_name,omitempty"
+	names := []string{}
+	if parts[0] != "" {
+		names = append(names, parts[0])
+	}
+	for _, opt := range parts[1:] {
+		if aliases, ok := strings.CutPrefix(opt, "aliases="); ok {
+			names = append(names, strings.Split(aliases, "|")...)
+		}
+	}
+	return names
+}
+
+// ignoredField reports whether the field is excluded by its tag: `jackadi:"-"`, or `json:"-"` without jackadi tag.
+func ignoredField(field reflect.StructField) bool {
+	tag, ok := field.Tag.Lookup("jackadi")
+	if !ok {
+		tag = field.Tag.Get("json")
+	}
+	return tag == "-"
+}
+
 func handleOptions(optionElemType reflect.Type, input *proto.Input) (reflect.Value, error) {
 	opts := reflect.New(optionElemType)
 	op, ok := opts.Interface().(Options)
@@ -415,8 +627,47 @@ func handleOptions(optionElemType reflect.Type, input *proto.Input) (reflect.Val
 		if err != nil {
 			return reflect.Value{}, fmt.Errorf("unable to convert '%s' option to '%s' (field: %s)", k, field.Type(), fieldName)
 		}
-		field.Set(reflect.ValueOf(res))
+
+		// a map option is merged into its default value, so that overriding one key keeps the other ones
+		value := reflect.ValueOf(res)
+		defaults, overrides := concreteMap(field), concreteMap(value)
+		if defaults.IsValid() && overrides.IsValid() {
+			value = mergeMap(defaults, overrides)
+		}
+		field.Set(value)
 	}
 
 	return opts, nil
 }
+
+// mergeMap returns a copy of defaults with the overrides deeply merged: nested maps are merged
+// instead of being replaced.
+func mergeMap(defaults, overrides reflect.Value) reflect.Value {
+	merged := reflect.MakeMapWithSize(defaults.Type(), defaults.Len()+overrides.Len())
+	iter := defaults.MapRange()
+	for iter.Next() {
+		merged.SetMapIndex(iter.Key(), iter.Value())
+	}
+
+	iter = overrides.MapRange()
+	for iter.Next() {
+		value := iter.Value()
+		prev, next := concreteMap(merged.MapIndex(iter.Key())), concreteMap(value)
+		if prev.IsValid() && next.IsValid() && prev.Type() == next.Type() {
+			value = mergeMap(prev, next)
+		}
+		merged.SetMapIndex(iter.Key(), value)
+	}
+	return merged
+}
+
+// concreteMap returns the non-nil map held by v, interfaces unwrapped, or an invalid value if there is none.
+func concreteMap(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Kind() != reflect.Map || v.IsNil() {
+		return reflect.Value{}
+	}
+	return v
+}
@@ -1,9 +1,14 @@
 package sdk
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/jackadi-io/jackadi/internal/plugin/core"
 	"github.com/jackadi-io/jackadi/internal/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 )
@@ -149,3 +154,522 @@ func TestHandleOptions(t *testing.T) {
 		})
 	}
 }
+
+type AliasOptions struct {
+	DryRun   bool   `jackadi:"dry_run,omitempty,aliases=dryRun|dry-run"`
+	Region   string // No tag
+	Priority int    `jackadi:"priority"`
+}
+
+func (o *AliasOptions) SetDefaults() {}
+
+func TestFindField(t *testing.T) {
+	tests := map[string]string{
+		"dry_run":  "DryRun",
+		"dryRun":   "DryRun",
+		"dry-run":  "DryRun",
+		"DRY_RUN":  "DryRun",
+		"DryRun":   "DryRun",
+		"DRY-RUN":  "DryRun",
+		"Region":   "Region",
+		"region":   "Region",
+		"priority": "Priority",
+		"Priority": "Priority",
+		"PRIORITY": "Priority",
+	}
+
+	for key, want := range tests {
+		t.Run(key, func(t *testing.T) {
+			_, name, found := findField(reflect.ValueOf(&AliasOptions{}).Elem(), key)
+			if !found {
+				t.Fatalf("no field found for '%s'", key)
+			}
+			if name != want {
+				t.Errorf("expected field %s, got %s", want, name)
+			}
+		})
+	}
+
+	for _, key := range []string{"dry_run_", "dry", "omitempty", "aliases=dryRun", ""} {
+		if _, name, found := findField(reflect.ValueOf(&AliasOptions{}).Elem(), key); found {
+			t.Errorf("unexpected field %s found for '%s'", name, key)
+		}
+	}
+}
+
+func TestHandleOptionsAliases(t *testing.T) {
+	options, err := structpb.NewStruct(map[string]any{"dry-run": true, "REGION": "eu-west-3"})
+	if err != nil {
+		t.Fatalf("failed to create structpb: %v", err)
+	}
+
+	result, err := handleOptions(reflect.TypeFor[AliasOptions](), &proto.Input{Options: options})
+	if err != nil {
+		t.Fatalf("handleOptions failed: %v", err)
+	}
+	opts := result.Interface().(*AliasOptions)
+	if !opts.DryRun || opts.Region != "eu-west-3" {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+
+	options, _ = structpb.NewStruct(map[string]any{"dry_runn": true})
+	if _, err := handleOptions(reflect.TypeFor[AliasOptions](), &proto.Input{Options: options}); err == nil {
+		t.Error("expected an error for an unknown option")
+	}
+}
+
+type JSONOptions struct {
+	DryRun   bool   `json:"dry_run,omitempty"`
+	Region   string `jackadi:"zone" json:"region"`
+	Token    string `json:"-"`
+	Priority int
+}
+
+func (o *JSONOptions) SetDefaults() {}
+
+func TestFindFieldJSONTag(t *testing.T) {
+	tests := map[string]string{
+		"dry_run":  "DryRun",
+		"DRY_RUN":  "DryRun",
+		"DryRun":   "DryRun",
+		"zone":     "Region",
+		"Region":   "Region",
+		"priority": "Priority",
+	}
+
+	for key, want := range tests {
+		t.Run(key, func(t *testing.T) {
+			_, name, found := findField(reflect.ValueOf(&JSONOptions{}).Elem(), key)
+			if !found {
+				t.Fatalf("no field found for '%s'", key)
+			}
+			if name != want {
+				t.Errorf("expected field %s, got %s", want, name)
+			}
+		})
+	}
+
+	for _, key := range []string{"Token", "token", "-", "omitempty"} {
+		if _, name, found := findField(reflect.ValueOf(&JSONOptions{}).Elem(), key); found {
+			t.Errorf("unexpected field %s found for '%s'", name, key)
+		}
+	}
+}
+
+func TestHandleOptionsJSONTag(t *testing.T) {
+	options, err := structpb.NewStruct(map[string]any{"dry_run": true, "zone": "eu-west-3", "priority": 2})
+	if err != nil {
+		t.Fatalf("failed to create structpb: %v", err)
+	}
+
+	result, err := handleOptions(reflect.TypeFor[JSONOptions](), &proto.Input{Options: options})
+	if err != nil {
+		t.Fatalf("handleOptions failed: %v", err)
+	}
+	want := JSONOptions{DryRun: true, Region: "eu-west-3", Priority: 2}
+	if opts := result.Interface().(*JSONOptions); *opts != want {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+
+	options, _ = structpb.NewStruct(map[string]any{"token": "secret"})
+	if _, err := handleOptions(reflect.TypeFor[JSONOptions](), &proto.Input{Options: options}); err == nil {
+		t.Error("expected an error for an ignored field")
+	}
+}
+
+type BaseOptions struct {
+	DryRun  bool   `jackadi:"dry-run"`
+	Region  string `jackadi:"region"`
+	Timeout int
+}
+
+type AuditOptions struct {
+	Audit  bool   `jackadi:"audit"`
+	Secret string `jackadi:"-"`
+}
+
+type EmbeddedOptions struct {
+	BaseOptions
+	*AuditOptions
+	Hidden  `jackadi:"-"`
+	Region  string `jackadi:"zone,aliases=region"` // shadows the region of the base
+	Timeout string // shadows the timeout of the base
+}
+
+type Hidden struct {
+	Token string
+}
+
+func (o *EmbeddedOptions) SetDefaults() {
+	o.BaseOptions.Region = "us-east-1"
+}
+
+func TestHandleOptionsEmbedded(t *testing.T) {
+	options, err := structpb.NewStruct(map[string]any{"dry-run": true, "region": "eu-west-3", "timeout": "1m", "audit": true})
+	if err != nil {
+		t.Fatalf("failed to create structpb: %v", err)
+	}
+
+	result, err := handleOptions(reflect.TypeFor[EmbeddedOptions](), &proto.Input{Options: options})
+	if err != nil {
+		t.Fatalf("handleOptions failed: %v", err)
+	}
+	opts := result.Interface().(*EmbeddedOptions)
+	if !opts.DryRun {
+		t.Error("expected the dry-run option of the embedded struct to be set")
+	}
+	if opts.Region != "eu-west-3" || opts.BaseOptions.Region != "us-east-1" {
+		t.Errorf("expected the outer region to be set, got %q (base: %q)", opts.Region, opts.BaseOptions.Region)
+	}
+	if opts.Timeout != "1m" || opts.BaseOptions.Timeout != 0 {
+		t.Errorf("expected the outer timeout to be set, got %q (base: %d)", opts.Timeout, opts.BaseOptions.Timeout)
+	}
+	if opts.AuditOptions == nil || !opts.Audit {
+		t.Error("expected the embedded pointer to be allocated and its audit option set")
+	}
+
+	for _, key := range []string{"secret", "Secret", "token", "Token"} {
+		options, _ = structpb.NewStruct(map[string]any{key: "x"})
+		if _, err := handleOptions(reflect.TypeFor[EmbeddedOptions](), &proto.Input{Options: options}); err == nil {
+			t.Errorf("expected an error for the ignored field '%s'", key)
+		}
+	}
+}
+
+func TestDoEmbeddedArgument(t *testing.T) {
+	type user struct {
+		BaseOptions
+		Name string `jackadi:"name"`
+	}
+
+	p := New("test")
+	p.MustRegisterTask("create", func(u user) (string, error) {
+		return fmt.Sprintf("%s in %s (dry-run: %t)", u.Name, u.Region, u.DryRun), nil
+	})
+
+	res, err := TestInvoke(context.Background(), p, "create", nil, `{"name": "alice", "region": "eu-west-3", "dry-run": true}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out string
+	if err := res.Decode(&out); err != nil || out != "alice in eu-west-3 (dry-run: true)" {
+		t.Errorf("unexpected output: %q (%v)", out, err)
+	}
+}
+
+type MapOptions struct {
+	Labels   map[string]string `jackadi:"labels"`
+	Settings map[string]any    `jackadi:"settings"`
+}
+
+func (o *MapOptions) SetDefaults() {
+	o.Labels = map[string]string{"env": "prod", "team": "infra"}
+	o.Settings = map[string]any{
+		"retries": 3,
+		"proxy":   map[string]any{"host": "proxy.local", "port": 3128},
+	}
+}
+
+func TestHandleOptionsMapMerge(t *testing.T) {
+	options, err := structpb.NewStruct(map[string]any{
+		"labels":   `{"team": "db"}`, // as sent by the CLI
+		"settings": map[string]any{"proxy": map[string]any{"port": 8080}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create structpb: %v", err)
+	}
+
+	result, err := handleOptions(reflect.TypeFor[MapOptions](), &proto.Input{Options: options})
+	if err != nil {
+		t.Fatalf("handleOptions failed: %v", err)
+	}
+	opts := result.Interface().(*MapOptions)
+
+	wantLabels := map[string]string{"env": "prod", "team": "db"}
+	if !reflect.DeepEqual(opts.Labels, wantLabels) {
+		t.Errorf("expected labels %v, got %v", wantLabels, opts.Labels)
+	}
+
+	if opts.Settings["retries"] != 3 {
+		t.Errorf("expected default retries to persist, got %v", opts.Settings["retries"])
+	}
+	proxy, ok := opts.Settings["proxy"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected proxy to be a map, got %T", opts.Settings["proxy"])
+	}
+	if proxy["host"] != "proxy.local" {
+		t.Errorf("expected default proxy host to persist, got %v", proxy["host"])
+	}
+	if fmt.Sprint(proxy["port"]) != "8080" {
+		t.Errorf("expected proxy port to be overridden, got %v", proxy["port"])
+	}
+
+	// the defaults are not modified by the merge
+	defaults := &MapOptions{}
+	defaults.SetDefaults()
+	if defaults.Labels["team"] != "infra" {
+		t.Errorf("defaults modified: %v", defaults.Labels)
+	}
+}
+
+func TestDoProgress(t *testing.T) {
+	p := New("test")
+	p.MustRegisterTask("upgrade", func(ctx context.Context, progress Progress, opts *TestOptions, name string) (string, error) {
+		for _, percent := range []int{-5, 25, 50, 150} {
+			progress.Report(percent)
+		}
+		return "upgraded " + name + " in " + opts.Region, nil
+	})
+
+	input := &proto.Input{Args: &structpb.ListValue{Values: []*structpb.Value{structpb.NewStringValue("pkg")}}}
+
+	t.Run("with reporter", func(t *testing.T) {
+		var updates []int32
+		ctx := core.WithProgress(context.Background(), func(percent int32) {
+			updates = append(updates, percent)
+		})
+
+		resp, err := p.Do(ctx, "upgrade", input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := []int32{0, 25, 50, 100}; !reflect.DeepEqual(updates, want) {
+			t.Errorf("expected progress updates %v, got %v", want, updates)
+		}
+		if string(resp.Output) != `"upgraded pkg in us-east-1"` {
+			t.Errorf("unexpected output: %s", resp.Output)
+		}
+	})
+
+	t.Run("without reporter", func(t *testing.T) {
+		resp, err := p.Do(context.Background(), "upgrade", input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(resp.Output) != `"upgraded pkg in us-east-1"` {
+			t.Errorf("unexpected output: %s", resp.Output)
+		}
+	})
+}
+
+func TestDoPanic(t *testing.T) {
+	p := New("test")
+	p.MustRegisterTask("crash", func(name string) (string, error) {
+		var m map[string]string
+		m[name] = "boom" // nil map write
+		return "unreachable", nil
+	})
+
+	input := &proto.Input{Args: &structpb.ListValue{Values: []*structpb.Value{structpb.NewStringValue("pkg")}}}
+	resp, err := p.Do(context.Background(), "crash", input)
+
+	panicErr, ok := core.IsPanic(err)
+	if !ok {
+		t.Fatalf("expected a panic error, got: %v", err)
+	}
+	if !strings.Contains(panicErr.Value, "assignment to entry in nil map") {
+		t.Errorf("unexpected recovered value: %s", panicErr.Value)
+	}
+	if !strings.Contains(panicErr.Stack, "sdk.TestDoPanic") {
+		t.Errorf("expected the stack trace of the task, got:\n%s", panicErr.Stack)
+	}
+	if resp.Output != nil {
+		t.Errorf("unexpected output: %s", resp.Output)
+	}
+}
+
+func TestDoPartialOutput(t *testing.T) {
+	type report struct {
+		Done   []string `jackadi:"done"`
+		Failed string   `jackadi:"failed"`
+	}
+
+	p := New("test")
+	p.MustRegisterTask("deploy", func(steps []string) (report, error) {
+		r := report{}
+		for _, step := range steps {
+			if step == "migrate" {
+				r.Failed = step
+				return r, WithRetcode(fmt.Errorf("step %s failed", step), 4)
+			}
+			r.Done = append(r.Done, step)
+		}
+		return r, nil
+	})
+	p.MustRegisterTask("unserializable", func() (any, error) {
+		return func() {}, errors.New("the task failed")
+	})
+
+	steps, err := structpb.NewList([]any{[]any{"pull", "build", "migrate", "restart"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := p.Do(context.Background(), "deploy", &proto.Input{Args: steps})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(resp.Output) != `{"done":["pull","build"],"failed":"migrate"}` {
+		t.Errorf("expected the partial output, got: %s", resp.Output)
+	}
+	if resp.Error != "step migrate failed" || resp.Retcode != 4 {
+		t.Errorf("expected the error of the task, got: %q (retcode %d)", resp.Error, resp.Retcode)
+	}
+
+	resp, err = p.Do(context.Background(), "unserializable", &proto.Input{Args: &structpb.ListValue{}})
+	if err == nil {
+		t.Fatal("expected a serialization error")
+	}
+	if resp.Error != "the task failed" {
+		t.Errorf("the error of the task must be kept when the output cannot be serialized, got: %q", resp.Error)
+	}
+}
+
+type UpgradeOptions struct {
+	SecurityOnly    bool     `jackadi:"security-only"`
+	ExcludePackages []string `jackadi:"exclude-packages"`
+}
+
+func (o *UpgradeOptions) SetDefaults() {}
+
+func TestDoValidation(t *testing.T) {
+	called := false
+	var validated []any
+
+	p := New("test")
+	p.MustRegisterTask("upgrade", func(ctx context.Context, opts *UpgradeOptions, repo string, retries int) (string, error) {
+		called = true
+		return "upgraded from " + repo, nil
+	}).WithValidation(func(opts Options, args []any) error {
+		validated = args
+		o, ok := opts.(*UpgradeOptions)
+		if !ok {
+			return fmt.Errorf("unexpected options type %T", opts)
+		}
+		if o.SecurityOnly && len(o.ExcludePackages) > 0 {
+			return errors.New("security-only cannot be combined with exclude-packages")
+		}
+		return nil
+	})
+
+	args, err := structpb.NewList([]any{"main", 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]struct {
+		options map[string]any
+		wantErr string
+	}{
+		"valid": {
+			options: map[string]any{"security-only": true},
+		},
+		"invalid combination": {
+			options: map[string]any{"security-only": true, "exclude-packages": []any{"kernel"}},
+			wantErr: "invalid arguments: security-only cannot be combined with exclude-packages",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			called, validated = false, nil
+			options, err := structpb.NewStruct(tt.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			resp, err := p.Do(context.Background(), "upgrade", &proto.Input{Args: args, Options: options})
+			if !reflect.DeepEqual(validated, []any{"main", 3}) {
+				t.Errorf("expected the converted arguments to be validated, got %#v", validated)
+			}
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("expected error %q, got: %v", tt.wantErr, err)
+				}
+				if called {
+					t.Error("the task must not be called when the validation fails")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !called || string(resp.Output) != `"upgraded from main"` {
+				t.Errorf("expected the task to run, got output: %s", resp.Output)
+			}
+		})
+	}
+}
+
+func TestDoValidationWithoutOptions(t *testing.T) {
+	p := New("test")
+	p.MustRegisterTask("echo", func(message string) (string, error) {
+		return message, nil
+	}).WithValidation(func(opts Options, args []any) error {
+		if opts != nil {
+			return fmt.Errorf("unexpected options: %v", opts)
+		}
+		if args[0] == "" {
+			return errors.New("empty message")
+		}
+		return nil
+	})
+
+	empty := &proto.Input{Args: &structpb.ListValue{Values: []*structpb.Value{structpb.NewStringValue("")}}}
+	if _, err := p.Do(context.Background(), "echo", empty); err == nil || !strings.Contains(err.Error(), "empty message") {
+		t.Errorf("expected a validation error, got: %v", err)
+	}
+
+	hello := &proto.Input{Args: &structpb.ListValue{Values: []*structpb.Value{structpb.NewStringValue("hello")}}}
+	resp, err := p.Do(context.Background(), "echo", hello)
+	if err != nil || string(resp.Output) != `"hello"` {
+		t.Errorf("unexpected response: %s, %v", resp.Output, err)
+	}
+}
+
+func TestDoOptionalPointer(t *testing.T) {
+	type labels struct {
+		Env string `jackadi:"env"`
+	}
+
+	var received *labels
+	p := New("test")
+	p.MustRegisterTask("patch", func(name string, l *labels) (bool, error) {
+		received = l
+		return l != nil, nil
+	})
+
+	tests := map[string]struct {
+		args    []any
+		wantNil bool
+		wantEnv string
+	}{
+		"omitted":  {args: []any{"web-1"}, wantNil: true},
+		"null":     {args: []any{"web-1", nil}, wantNil: true},
+		"empty":    {args: []any{"web-1", "{}"}},
+		"provided": {args: []any{"web-1", `{"env": "prod"}`}, wantEnv: "prod"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			received = nil
+			if _, err := TestInvoke(context.Background(), p, "patch", nil, tt.args...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (received == nil) != tt.wantNil {
+				t.Fatalf("expected a nil pointer: %t, got %+v", tt.wantNil, received)
+			}
+			if received != nil && received.Env != tt.wantEnv {
+				t.Errorf("expected env %q, got %q", tt.wantEnv, received.Env)
+			}
+		})
+	}
+
+	for _, args := range [][]any{{}, {"web-1", "{}", "extra"}} {
+		if _, err := TestInvoke(context.Background(), p, "patch", nil, args...); err == nil {
+			t.Errorf("expected an error for %d arguments", len(args))
+		}
+	}
+}